@@ -10,6 +10,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/cobra"
@@ -20,8 +22,9 @@ import (
 )
 
 var (
-	moduleName  string
-	minimalMode bool
+	moduleName     string
+	minimalMode    bool
+	moduleServices moduleFeatures
 )
 
 // newModuleCmd represents the new-module command
@@ -32,18 +35,22 @@ var newModuleCmd = &cobra.Command{
 and automatically registers it with the application.
 
 By default, generates a full-featured module with pubsub integration, background services,
-and topic management. Use --minimal flag to generate a simpler module with only basic dependencies.`,
+and topic management. Use --minimal flag to generate a simpler module with only basic dependencies.
+
+The --with-database, --with-livequery, --with-presence and --with-script flags add
+the matching core services to the module's Dependencies and to the generated Deps
+helper in internal/app/dependencies.go.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if moduleName == "" {
 			log.Fatal("Module name is required: --name=<module-name>")
 		}
 
-		if err := generateModule(moduleName, minimalMode); err != nil {
+		if err := generateModule(moduleName, minimalMode, moduleServices); err != nil {
 			log.Fatalf("Failed to generate module: %v", err)
 		}
 
 		errModules := updateModulesFile(moduleName)
-		errDeps := updateDependenciesFile(moduleName, minimalMode)
+		errDeps := updateDependenciesFile(moduleName, minimalMode, moduleServices)
 
 		if errModules != nil || errDeps != nil {
 			log.Println("Automatic file updates failed. Please add the following manually:")
@@ -53,9 +60,9 @@ and topic management. Use --minimal flag to generate a simpler module with only
 			if errDeps != nil {
 				log.Printf(" - dependencies.go error: %v", errDeps)
 			}
-			printNextSteps(moduleName, minimalMode) // Fallback to printing instructions
+			printNextSteps(moduleName, minimalMode, moduleServices) // Fallback to printing instructions
 		} else {
-			printSuccessMessage(moduleName, minimalMode)
+			printSuccessMessage(moduleName, minimalMode, moduleServices)
 		}
 	},
 }
@@ -64,19 +71,60 @@ func init() {
 	rootCmd.AddCommand(newModuleCmd)
 	newModuleCmd.Flags().StringVarP(&moduleName, "name", "n", "", "The name of the new module (e.g., 'inventory')")
 	newModuleCmd.Flags().BoolVar(&minimalMode, "minimal", false, "Generate a minimal module with only basic dependencies (Renderer only)")
+	newModuleCmd.Flags().BoolVar(&moduleServices.WithDatabase, "with-database", false, "Depend on the file repository (FileRepository)")
+	newModuleCmd.Flags().BoolVar(&moduleServices.WithLiveQuery, "with-livequery", false, "Depend on the live query service (LiveQueryService)")
+	newModuleCmd.Flags().BoolVar(&moduleServices.WithPresence, "with-presence", false, "Depend on the presence service (PresenceService)")
+	newModuleCmd.Flags().BoolVar(&moduleServices.WithScript, "with-script", false, "Depend on the script engine (ScriptEngine)")
+}
+
+// moduleFeatures selects the optional core services from app.Dependencies
+// that a generated module depends on.
+type moduleFeatures struct {
+	WithDatabase  bool
+	WithLiveQuery bool
+	WithPresence  bool
+	WithScript    bool
+}
+
+// dependencyFields returns the app.Dependencies fields the selected services
+// are wired from, in the order they appear in generated code.
+func (f moduleFeatures) dependencyFields() []string {
+	var fields []string
+	if f.WithDatabase {
+		fields = append(fields, "FileRepository")
+	}
+	if f.WithLiveQuery {
+		fields = append(fields, "LiveQueryService")
+	}
+	if f.WithPresence {
+		fields = append(fields, "PresenceService")
+	}
+	if f.WithScript {
+		fields = append(fields, "ScriptEngine")
+	}
+	return fields
 }
 
 type TemplateData struct {
 	Name       string
 	PascalName string
+	// EnvPrefix is the env var prefix for module-scoped config, e.g. "BLOG_".
+	EnvPrefix string
+	moduleFeatures
 }
 
-func generateModule(name string, minimal bool) error {
+func newTemplateData(name string, features moduleFeatures) TemplateData {
 	caser := cases.Title(language.English)
-	data := TemplateData{
-		Name:       name,
-		PascalName: caser.String(name),
+	return TemplateData{
+		Name:           name,
+		PascalName:     caser.String(name),
+		EnvPrefix:      strings.ToUpper(name) + "_",
+		moduleFeatures: features,
 	}
+}
+
+func generateModule(name string, minimal bool, features moduleFeatures) error {
+	data := newTemplateData(name, features)
 
 	moduleDir := filepath.Join("internal", "modules", name)
 	if err := os.MkdirAll(moduleDir, 0755); err != nil {
@@ -147,11 +195,24 @@ func generateFile(path string, tmpl string, data TemplateData) error {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return os.WriteFile(path, buf.Bytes(), 0644)
+	content := buf.Bytes()
+	if filepath.Ext(path) == ".go" {
+		// Optional sections leave uneven field alignment behind; gofmt it.
+		if content, err = format.Source(content); err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+	}
+	return os.WriteFile(path, content, 0644)
 }
 
 func updateModulesFile(name string) error {
-	modulesPath := "internal/app/modules.go"
+	return addModule("internal/app/modules.go", name)
+}
+
+// addModule registers the module by appending name.New(nameDeps(deps)) to
+// the slice NewModules returns, so modules boot in the order they were
+// created.
+func addModule(modulesPath, name string) error {
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, modulesPath, nil, parser.ParseComments)
 	if err != nil {
@@ -161,37 +222,69 @@ func updateModulesFile(name string) error {
 	newImportPath := fmt.Sprintf("github.com/nfrund/goby/internal/modules/%s", name)
 	astutil.AddImport(fset, node, newImportPath)
 
-	ast.Inspect(node, func(n ast.Node) bool {
-		fn, ok := n.(*ast.FuncDecl)
-		if !ok || fn.Name.Name != "NewModules" {
-			return true
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		return fmt.Errorf("failed to format AST: %w", err)
+	}
+	src := buf.Bytes()
+
+	// Re-parse with the import in place, and insert the new element as text
+	// before the slice's closing brace so it gets a line of its own.
+	fset = token.NewFileSet()
+	node, err = parser.ParseFile(fset, modulesPath, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", modulesPath, err)
+	}
+	modules := newModulesSlice(node)
+	if modules == nil {
+		return fmt.Errorf("%s: NewModules does not return a slice literal", modulesPath)
+	}
+	insertAt := fset.Position(modules.Rbrace).Offset
+	element := fmt.Sprintf("%s.New(%sDeps(deps)),\n", name, name)
+	if fset.Position(modules.Lbrace).Line == fset.Position(modules.Rbrace).Line {
+		// Break a one-line literal up; it has no trailing comma after its last
+		// element.
+		element = "\n" + element
+		if len(modules.Elts) > 0 {
+			element = "," + element
 		}
+	}
 
-		ast.Inspect(fn.Body, func(n ast.Node) bool {
-			ret, ok := n.(*ast.ReturnStmt)
-			if !ok {
-				return true
-			}
-			compLit, ok := ret.Results[0].(*ast.CompositeLit)
-			if !ok {
-				return false
+	var out bytes.Buffer
+	out.Write(src[:insertAt])
+	out.WriteString(element)
+	out.Write(src[insertAt:])
+	content, err := format.Source(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", modulesPath, err)
+	}
+	if err := os.WriteFile(modulesPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", modulesPath, err)
+	}
+	return nil
+}
+
+// newModulesSlice returns the slice literal NewModules returns, or nil.
+func newModulesSlice(node *ast.File) *ast.CompositeLit {
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "NewModules" || fn.Body == nil {
+			continue
+		}
+		for _, stmt := range fn.Body.List {
+			ret, ok := stmt.(*ast.ReturnStmt)
+			if !ok || len(ret.Results) == 0 {
+				continue
 			}
-			newElement := &ast.CallExpr{
-				Fun: &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent("New")},
-				Args: []ast.Expr{
-					ast.NewIdent(fmt.Sprintf("%sDeps(deps)", name)),
-				},
+			if lit, ok := ret.Results[0].(*ast.CompositeLit); ok {
+				return lit
 			}
-			compLit.Elts = append([]ast.Expr{newElement}, compLit.Elts...)
-			return false
-		})
-		return false
-	})
-
-	return writeASTToFile(fset, node, modulesPath)
+		}
+	}
+	return nil
 }
 
-func updateDependenciesFile(name string, minimal bool) error {
+func updateDependenciesFile(name string, minimal bool, features moduleFeatures) error {
 	depsPath := "internal/app/dependencies.go"
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, depsPath, nil, parser.ParseComments)
@@ -202,84 +295,62 @@ func updateDependenciesFile(name string, minimal bool) error {
 	newImportPath := fmt.Sprintf("github.com/nfrund/goby/internal/modules/%s", name)
 	astutil.AddImport(fset, node, newImportPath)
 
-	funcName := fmt.Sprintf("%sDeps", name)
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		return fmt.Errorf("failed to format AST: %w", err)
+	}
+	fmt.Fprintf(&buf, "\n// %sDeps creates the dependency struct for the %s module.\n", name, name)
+	buf.WriteString(dependencyHelperSource(name, minimal, features))
 
-	// Create dependency fields based on minimal mode
-	var depFields []ast.Expr
-	if minimal {
-		// Minimal mode: only Renderer
-		depFields = []ast.Expr{
-			&ast.KeyValueExpr{
-				Key:   ast.NewIdent("Renderer"),
-				Value: &ast.SelectorExpr{X: ast.NewIdent("deps"), Sel: ast.NewIdent("Renderer")},
-			},
-		}
-	} else {
-		// Full mode: all dependencies
-		depFields = []ast.Expr{
-			&ast.KeyValueExpr{
-				Key:   ast.NewIdent("Renderer"),
-				Value: &ast.SelectorExpr{X: ast.NewIdent("deps"), Sel: ast.NewIdent("Renderer")},
-			},
-			&ast.KeyValueExpr{
-				Key:   ast.NewIdent("Publisher"),
-				Value: &ast.SelectorExpr{X: ast.NewIdent("deps"), Sel: ast.NewIdent("Publisher")},
-			},
-			&ast.KeyValueExpr{
-				Key:   ast.NewIdent("Subscriber"),
-				Value: &ast.SelectorExpr{X: ast.NewIdent("deps"), Sel: ast.NewIdent("Subscriber")},
-			},
-			&ast.KeyValueExpr{
-				Key:   ast.NewIdent("TopicMgr"),
-				Value: &ast.SelectorExpr{X: ast.NewIdent("deps"), Sel: ast.NewIdent("TopicMgr")},
-			},
-		}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", depsPath, err)
+	}
+	if err := os.WriteFile(depsPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", depsPath, err)
 	}
+	return nil
+}
 
-	newFunc := &ast.FuncDecl{
-		Doc: &ast.CommentGroup{
-			List: []*ast.Comment{
-				{Text: fmt.Sprintf("// %s creates the dependency struct for the %s module.", funcName, name)},
-			},
-		},
-		Name: ast.NewIdent(funcName),
-		Type: &ast.FuncType{
-			Params:  &ast.FieldList{List: []*ast.Field{{Names: []*ast.Ident{ast.NewIdent("deps")}, Type: ast.NewIdent("Dependencies")}}},
-			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent("Dependencies")}}}},
-		},
-		Body: &ast.BlockStmt{
-			List: []ast.Stmt{
-				&ast.ReturnStmt{
-					Results: []ast.Expr{
-						&ast.CompositeLit{
-							Type: &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent("Dependencies")},
-							Elts: depFields,
-						},
-					},
-				},
-			},
-		},
+// dependencyHelperSource returns the <name>Deps function that maps
+// app.Dependencies onto the module's Dependencies: the Renderer in minimal
+// mode, the pubsub services and scoped Config in full mode, plus the selected
+// optional services.
+func dependencyHelperSource(name string, minimal bool, features moduleFeatures) string {
+	fields := []string{"Renderer"}
+	if !minimal {
+		fields = append(fields, "Publisher", "Subscriber", "TopicMgr")
+	}
+	fields = append(fields, features.dependencyFields()...)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %sDeps(deps Dependencies) %s.Dependencies {\n", name, name)
+	fmt.Fprintf(&b, "\treturn %s.Dependencies{\n", name)
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\t\t%s: deps.%s,\n", field, field)
+	}
+	if !minimal {
+		fmt.Fprintf(&b, "\t\tConfig: moduleConfig(deps, %s),\n", strconv.Quote(strings.ToUpper(name)+"_"))
 	}
+	b.WriteString("\t}\n}\n")
 
-	node.Decls = append(node.Decls, newFunc)
-	return writeASTToFile(fset, node, depsPath)
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(src)
 }
 
-func printSuccessMessage(name string, minimal bool) {
+func printSuccessMessage(name string, minimal bool, features moduleFeatures) {
 	data := TemplateData{Name: name}
+	helper := dependencyHelperSource(name, minimal, features)
 
 	if minimal {
 		fmt.Printf("✅ Successfully created minimal module '%s' in internal/modules/%s/\n", name, name)
 		fmt.Println("✅ Automatically updated application files:")
 		fmt.Println("-----------------------------------------------------------------")
 		fmt.Print("\n1. Added dependency helper to 'internal/app/dependencies.go':\n\n")
-		fmt.Printf(`
-func %sDeps(deps Dependencies) %s.Dependencies {
-	return %s.Dependencies{
-		Renderer: deps.Renderer,
-	}
-}
-`, data.Name, data.Name, data.Name)
+		fmt.Printf("\n%s", helper)
 		fmt.Print("\n2. Registered the new module in 'internal/app/modules.go':\n\n")
 		fmt.Printf(`
 %s.New(%sDeps(deps)),
@@ -295,16 +366,7 @@ func %sDeps(deps Dependencies) %s.Dependencies {
 		fmt.Println("✅ Automatically updated application files:")
 		fmt.Println("-----------------------------------------------------------------")
 		fmt.Print("\n1. Added dependency helper to 'internal/app/dependencies.go':\n\n")
-		fmt.Printf(`
-func %sDeps(deps Dependencies) %s.Dependencies {
-	return %s.Dependencies{
-		Renderer:   deps.Renderer,
-		Publisher:  deps.Publisher,
-		Subscriber: deps.Subscriber,
-		TopicMgr:   deps.TopicMgr,
-	}
-}
-`, data.Name, data.Name, data.Name)
+		fmt.Printf("\n%s", helper)
 		fmt.Print("\n2. Registered the new module in 'internal/app/modules.go':\n\n")
 		fmt.Printf(`
 %s.New(%sDeps(deps)),
@@ -319,8 +381,9 @@ func %sDeps(deps Dependencies) %s.Dependencies {
 	}
 }
 
-func printNextSteps(name string, minimal bool) {
+func printNextSteps(name string, minimal bool, features moduleFeatures) {
 	data := TemplateData{Name: name}
+	helper := dependencyHelperSource(name, minimal, features)
 
 	if minimal {
 		fmt.Printf("✅ Successfully created minimal module '%s' in internal/modules/%s/\n\n", name, name)
@@ -330,12 +393,7 @@ func printNextSteps(name string, minimal bool) {
 		fmt.Printf(`
 import "github.com/nfrund/goby/internal/modules/%s"
 
-func %sDeps(deps Dependencies) %s.Dependencies {
-	return %s.Dependencies{
-		Renderer: deps.Renderer,
-	}
-}
-`, data.Name, data.Name, data.Name, data.Name)
+%s`, data.Name, helper)
 		fmt.Print("\n2. Register the new module in 'internal/app/modules.go':\n\n")
 		fmt.Printf(`
 import "github.com/nfrund/goby/internal/modules/%s"
@@ -356,15 +414,7 @@ import "github.com/nfrund/goby/internal/modules/%s"
 		fmt.Printf(`
 import "github.com/nfrund/goby/internal/modules/%s"
 
-func %sDeps(deps Dependencies) %s.Dependencies {
-	return %s.Dependencies{
-		Renderer:   deps.Renderer,
-		Publisher:  deps.Publisher,
-		Subscriber: deps.Subscriber,
-		TopicMgr:   deps.TopicMgr,
-	}
-}
-`, data.Name, data.Name, data.Name, data.Name)
+%s`, data.Name, helper)
 		fmt.Print("\n2. Register the new module in 'internal/app/modules.go':\n\n")
 		fmt.Printf(`
 import "github.com/nfrund/goby/internal/modules/%s"
@@ -381,17 +431,6 @@ import "github.com/nfrund/goby/internal/modules/%s"
 	}
 }
 
-func writeASTToFile(fset *token.FileSet, node *ast.File, filename string) error {
-	var buf bytes.Buffer
-	if err := format.Node(&buf, fset, node); err != nil {
-		return fmt.Errorf("failed to format AST: %w", err)
-	}
-	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write to file %s: %w", filename, err)
-	}
-	return nil
-}
-
 const moduleTemplate = `package {{.Name}}
 
 import (
@@ -400,11 +439,21 @@ import (
 	"log/slog"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
+{{- if or .WithDatabase .WithLiveQuery}}
+	"github.com/nfrund/goby/internal/database"
+{{- end}}
 	"github.com/nfrund/goby/internal/module"
 	"github.com/nfrund/goby/internal/modules/{{.Name}}/topics"
+{{- if .WithPresence}}
+	"github.com/nfrund/goby/internal/presence"
+{{- end}}
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/registry"
 	"github.com/nfrund/goby/internal/rendering"
+{{- if .WithScript}}
+	"github.com/nfrund/goby/internal/script"
+{{- end}}
 	"github.com/nfrund/goby/internal/topicmgr"
 )
 
@@ -416,17 +465,50 @@ type {{.PascalName}}Module struct {
 	renderer   rendering.Renderer
 	topicMgr   *topicmgr.Manager
 	
+	// Module-specific settings, read from {{.EnvPrefix}}* env vars in New.
+	maxItems int
+	
+	// messageSubscriber is started in Boot and stopped in Shutdown.
+	messageSubscriber *Subscriber
+	
+{{- if or .WithDatabase .WithLiveQuery}}
+	
+	// Database integration
+{{- if .WithDatabase}}
+	fileRepository *database.FileStore
+{{- end}}
+{{- if .WithLiveQuery}}
+	liveQueries database.LiveQueryService
+{{- end}}
+{{- else}}
+	
 	// Database integration (uncomment as needed):
 	// database  database.Database
 	// itemStore stores.ItemStore
 	// userStore stores.UserStore
+{{- end}}
+	
+{{- if .WithScript}}
+	
+	// Script engine integration
+	scriptEngine script.ScriptEngine
+	// scriptHelper *script.ModuleScriptHelper
+{{- else}}
 	
 	// Script engine integration (uncomment as needed):
 	// scriptEngine script.ScriptEngine
 	// scriptHelper *script.ModuleScriptHelper
+{{- end}}
+	
+{{- if .WithPresence}}
+	
+	// Presence service integration
+	presenceService *presence.Service
+{{- else}}
 	
 	// Presence service integration (uncomment as needed):
 	// presenceService *presence.Service
+{{- end}}
 }
 
 // Dependencies contains all the dependencies required by the {{.Name}} module.
@@ -440,6 +522,32 @@ type Dependencies struct {
 	Subscriber pubsub.Subscriber
 	TopicMgr   *topicmgr.Manager
 	
+	// Config is scoped to the {{.EnvPrefix}} env prefix, so
+	// Config.GetInt("MAX_ITEMS", 100) reads {{.EnvPrefix}}MAX_ITEMS.
+	// It may be nil, e.g. in tests.
+	Config config.Provider
+	
+{{- if .WithDatabase}}
+	
+	// FileRepository stores file metadata.
+	FileRepository *database.FileStore
+{{- end}}
+{{- if .WithLiveQuery}}
+	
+	// LiveQueryService streams database changes as they happen.
+	LiveQueryService database.LiveQueryService
+{{- end}}
+{{- if .WithPresence}}
+	
+	// PresenceService tracks which users are online.
+	PresenceService *presence.Service
+{{- end}}
+{{- if .WithScript}}
+	
+	// ScriptEngine executes the module's scripts.
+	ScriptEngine script.ScriptEngine
+{{- end}}
+	
 	// Optional advanced dependencies (uncomment as needed):
 	
 	// Database integration (choose one approach):
@@ -448,31 +556,68 @@ type Dependencies struct {
 	// ItemStore stores.ItemStore                   // Store pattern for items
 	
 	// Advanced services:
+{{- if not .WithScript}}
 	// ScriptEngine script.ScriptEngine             // For script execution
+{{- end}}
+{{- if not .WithPresence}}
 	// PresenceService *presence.Service            // For user presence tracking
+{{- end}}
 	// CacheService cache.Service                   // For caching
 	// EmailService email.Service                   // For email notifications
 }
 
 // New creates a new instance of {{.PascalName}}Module with the provided dependencies.
 func New(deps Dependencies) *{{.PascalName}}Module {
+	maxItems := 100
+	if deps.Config != nil {
+		maxItems = deps.Config.GetInt("MAX_ITEMS", maxItems)
+	}
+
 	return &{{.PascalName}}Module{
 		publisher:  deps.Publisher,
 		subscriber: deps.Subscriber,
 		renderer:   deps.Renderer,
 		topicMgr:   deps.TopicMgr,
+		maxItems:   maxItems,
+		
+{{- if or .WithDatabase .WithLiveQuery}}
+		
+		// Database integration
+{{- if .WithDatabase}}
+		fileRepository: deps.FileRepository,
+{{- end}}
+{{- if .WithLiveQuery}}
+		liveQueries: deps.LiveQueryService,
+{{- end}}
+{{- else}}
 		
 		// Database integration (uncomment as needed):
 		// database:  deps.Database,
 		// itemStore: deps.ItemStore,
 		// userStore: deps.UserStore,
+{{- end}}
+		
+{{- if .WithScript}}
+		
+		// Script engine integration
+		scriptEngine: deps.ScriptEngine,
+		// scriptHelper: script.NewModuleScriptHelper(deps.ScriptEngine, "{{.Name}}", getScriptConfig()),
+{{- else}}
 		
 		// Script engine integration (uncomment as needed):
 		// scriptEngine: deps.ScriptEngine,
 		// scriptHelper: script.NewModuleScriptHelper(deps.ScriptEngine, "{{.Name}}", getScriptConfig()),
+{{- end}}
+		
+{{- if .WithPresence}}
+		
+		// Presence service integration
+		presenceService: deps.PresenceService,
+{{- else}}
 		
 		// Presence service integration (uncomment as needed):
 		// presenceService: deps.PresenceService,
+{{- end}}
 	}
 }
 
@@ -534,9 +679,9 @@ func (m *{{.PascalName}}Module) Boot(ctx context.Context, g *echo.Group, reg *re
 
 	// --- Start Background Services ---
 	
-	// Create and start the {{.Name}} subscriber in a goroutine
-	{{.Name}}Subscriber := NewSubscriber(m.subscriber, m.publisher, m.renderer)
-	go {{.Name}}Subscriber.Start(ctx)
+	// Create and start the {{.Name}} subscriber; Shutdown stops it.
+	m.messageSubscriber = NewSubscriber(m.subscriber, m.publisher, m.renderer)
+	m.messageSubscriber.Start(ctx)
 	
 	// --- Register HTTP Handlers ---
 	
@@ -574,8 +719,14 @@ func (m *{{.PascalName}}Module) Boot(ctx context.Context, g *echo.Group, reg *re
 func (m *{{.PascalName}}Module) Shutdown(ctx context.Context) error {
 	slog.Info("Shutting down {{.PascalName}}Module...")
 	
-	// TODO: Add any cleanup logic here
-	// - Cancel background goroutines (they should respect the context)
+	// Stop the subscriber and wait for messages it is still handling.
+	if m.messageSubscriber != nil {
+		if err := m.messageSubscriber.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	
+	// TODO: Add any other cleanup logic here
 	// - Close any open resources
 	// - Wait for pending operations to complete
 	
@@ -667,17 +818,17 @@ const handlerTemplate = `package {{.Name}}
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/middleware"
 	"github.com/nfrund/goby/internal/modules/{{.Name}}/topics"
 	"github.com/nfrund/goby/internal/pubsub"
@@ -690,11 +841,13 @@ import (
 // This is set by the authentication middleware.
 const UserContextKey = middleware.UserContextKey
 
-// Common errors for the {{.Name}} module
+// Common errors for the {{.Name}} module. They are the shared domain errors,
+// which the server's error handler turns into the matching status code and
+// JSON error body, so handlers can simply return them (wrapped or not).
 var (
-	ErrUnauthorized   = errors.New("authentication required")
-	ErrInvalidRequest = errors.New("invalid request data")
-	ErrNotFound       = errors.New("resource not found")
+	ErrUnauthorized   = domain.ErrUnauthorized
+	ErrInvalidRequest = domain.ErrInvalidRequest
+	ErrNotFound       = domain.ErrNotFound
 )
 
 // Handler handles HTTP requests for the {{.Name}} module.
@@ -781,29 +934,29 @@ func (h *Handler) validateRequest(c echo.Context, requiredFields ...string) erro
 	return nil
 }
 
-// handleError provides consistent error handling across handlers.
+// handleError provides consistent error handling across handlers. The shared
+// errors above are returned as they are for the server's error handler to
+// map; anything else becomes defaultStatus.
 func (h *Handler) handleError(c echo.Context, err error, defaultStatus int) error {
 	// Log the error with request context for debugging
-	slog.Error("Handler error", 
-		"error", err, 
-		"path", c.Request().URL.Path, 
+	slog.Error("Handler error",
+		"error", err,
+		"path", c.Request().URL.Path,
 		"method", c.Request().Method,
 		"status", defaultStatus)
-	
-	switch {
-	case errors.Is(err, ErrUnauthorized):
-		return echo.NewHTTPError(http.StatusUnauthorized, "Authentication required")
-	case errors.Is(err, ErrInvalidRequest):
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	case errors.Is(err, ErrNotFound):
-		return echo.NewHTTPError(http.StatusNotFound, "Resource not found")
-	default:
-		return echo.NewHTTPError(defaultStatus, err.Error())
+
+	for _, known := range []error{ErrUnauthorized, ErrInvalidRequest, ErrNotFound} {
+		if errors.Is(err, known) {
+			return err
+		}
 	}
+	return echo.NewHTTPError(defaultStatus, err.Error())
 }
 
 // Get handles GET /{{.Name}} requests.
 // This is an example of a protected route that requires authentication.
+// Browsers get the page; API clients sending Accept: application/json (or
+// ?format=json) get the same data as JSON.
 func (h *Handler) Get(c echo.Context) error {
 	// Get the current user (requires authentication)
 	user, err := h.getCurrentUser(c)
@@ -815,7 +968,10 @@ func (h *Handler) Get(c echo.Context) error {
 	displayName := getUserDisplayName(user)
 	pageContent := page("{{.Name}}", displayName)
 	finalComponent := templ.Component(layouts.Base("{{.PascalName}}", view.GetFlashData(c).Messages, pageContent))
-	return c.Render(http.StatusOK, "", finalComponent)
+	return handlers.Respond(c, finalComponent, map[string]interface{}{
+		"module": "{{.Name}}",
+		"user":   displayName,
+	})
 }
 
 // GetPublic handles GET /{{.Name}}/public requests.
@@ -832,7 +988,10 @@ func (h *Handler) GetPublic(c echo.Context) error {
 	
 	pageContent := page("Public {{.Name}}", displayName)
 	finalComponent := templ.Component(layouts.Base("Public {{.PascalName}}", view.GetFlashData(c).Messages, pageContent))
-	return c.Render(http.StatusOK, "", finalComponent)
+	return handlers.Respond(c, finalComponent, map[string]interface{}{
+		"module": "{{.Name}}",
+		"user":   displayName,
+	})
 }
 
 // PostAction handles POST /{{.Name}}/action requests.
@@ -861,17 +1020,8 @@ func (h *Handler) PostAction(c echo.Context) error {
 		"timestamp": "2024-01-01T00:00:00Z", // TODO: Use actual timestamp
 	}
 
-	payload, err := json.Marshal(event)
-	if err != nil {
-		return h.handleError(c, fmt.Errorf("failed to marshal event: %w", err), http.StatusInternalServerError)
-	}
-
 	// Publish the event
-	if err := h.publisher.Publish(c.Request().Context(), pubsub.Message{
-		Topic:   topics.TopicExampleEvent.Name(),
-		UserID:  user.Email,
-		Payload: payload,
-	}); err != nil {
+	if err := pubsub.PublishJSON(c.Request().Context(), h.publisher, topics.TopicExampleEvent, user.Email, event); err != nil {
 		return h.handleError(c, fmt.Errorf("failed to publish event: %w", err), http.StatusInternalServerError)
 	}
 
@@ -1182,6 +1332,7 @@ type Subscriber struct {
 	subscriber pubsub.Subscriber
 	publisher  pubsub.Publisher
 	renderer   rendering.Renderer
+	group      *pubsub.SubscriberGroup
 	
 	// Database integration (uncomment as needed):
 	// database  database.Database
@@ -1217,38 +1368,34 @@ func NewSubscriber(sub pubsub.Subscriber, pub pubsub.Publisher, renderer renderi
 	}
 }
 
-// Start begins listening for {{.Name}}-related messages.
-// This method blocks until the provided context is canceled.
+// Start begins listening for {{.Name}}-related messages. It returns
+// immediately; the subscriptions run until ctx is canceled or Stop is called.
 func (s *Subscriber) Start(ctx context.Context) {
 	slog.Info("Starting {{.Name}} module subscriber")
 
+	s.group = pubsub.NewSubscriberGroup(s.subscriber, "{{.Name}}")
+
 	// Listen for example events from this module
-	go func() {
-		err := s.subscriber.Subscribe(ctx, topics.TopicExampleEvent.Name(), s.handleExampleEvent)
-		if err != nil && err != context.Canceled {
-			slog.Error("{{.PascalName}} example event subscriber stopped with error", "error", err)
-		}
-	}()
+	s.group.Add(topics.TopicExampleEvent.Name(), s.handleExampleEvent)
 
 	// Listen for client-initiated messages (from WebSocket clients)
-	go func() {
-		err := s.subscriber.Subscribe(ctx, topics.TopicClientAction.Name(), s.handleClientAction)
-		if err != nil && err != context.Canceled {
-			slog.Error("{{.PascalName}} client action subscriber stopped with error", "error", err)
-		}
-	}()
+	s.group.Add(topics.TopicClientAction.Name(), s.handleClientAction)
 
 	// Listen for WebSocket client connections to send welcome messages
-	go func() {
-		err := s.subscriber.Subscribe(ctx, wsTopics.TopicClientReady.Name(), s.handleClientConnect)
-		if err != nil && err != context.Canceled {
-			slog.Error("{{.PascalName}} client connect subscriber stopped with error", "error", err)
-		}
-	}()
+	s.group.Add(wsTopics.TopicClientReady.Name(), s.handleClientConnect)
 
+	s.group.Start(ctx)
 	slog.Info("{{.PascalName}} module subscriber started successfully")
 }
 
+// Stop ends the subscriptions and waits for in-flight messages to be handled.
+func (s *Subscriber) Stop(ctx context.Context) error {
+	if s.group == nil {
+		return nil
+	}
+	return s.group.Stop(ctx)
+}
+
 // handleExampleEvent processes example events for this module.
 func (s *Subscriber) handleExampleEvent(ctx context.Context, msg pubsub.Message) error {
 	// Parse the message payload
@@ -1261,7 +1408,7 @@ func (s *Subscriber) handleExampleEvent(ctx context.Context, msg pubsub.Message)
 
 	if err := json.Unmarshal(msg.Payload, &event); err != nil {
 		slog.Error("Failed to unmarshal {{.Name}} example event", "error", err)
-		return nil // Don't stop the subscriber for a bad message
+		return pubsub.Reject(err) // Malformed payloads will never succeed; drop to the dead-letter topic
 	}
 
 	slog.Info("Processing {{.Name}} example event", 
@@ -1272,9 +1419,8 @@ func (s *Subscriber) handleExampleEvent(ctx context.Context, msg pubsub.Message)
 	// TODO: Add your event processing logic here
 	// Example: Render a component and broadcast it
 	// component := components.ExampleEvent(event.Action, event.Data, event.UserID)
-	// renderedHTML, err := s.renderer.RenderComponent(ctx, component)
-	// if err != nil {
-	// 	slog.Error("Failed to render {{.Name}} event component", "error", err)
+	// if err := pubsub.RenderAndBroadcast(ctx, s.publisher, s.renderer, component, wsTopics.TopicHTMLBroadcast); err != nil {
+	// 	slog.Error("Failed to broadcast {{.Name}} event component", "error", err)
 	// 	return err
 	// }
 
@@ -1329,7 +1475,7 @@ func (s *Subscriber) handleClientAction(ctx context.Context, msg pubsub.Message)
 
 	if err := json.Unmarshal(msg.Payload, &action); err != nil {
 		slog.Error("Failed to unmarshal {{.Name}} client action", "error", err)
-		return nil // Don't stop the subscriber for a bad message
+		return pubsub.Reject(err) // Malformed payloads will never succeed; drop to the dead-letter topic
 	}
 
 	slog.Info("Processing {{.Name}} client action", 
@@ -1377,7 +1523,7 @@ func (s *Subscriber) handleClientConnect(ctx context.Context, msg pubsub.Message
 
 	if err := json.Unmarshal(msg.Payload, &readyEvent); err != nil {
 		slog.Error("Failed to unmarshal WebSocket ready event", "error", err)
-		return nil // Don't stop the subscriber for a bad message
+		return pubsub.Reject(err) // Malformed payloads will never succeed; drop to the dead-letter topic
 	}
 
 	// Only send welcome messages to HTML clients
@@ -1401,21 +1547,9 @@ func (s *Subscriber) handleClientConnect(ctx context.Context, msg pubsub.Message
 
 		// TODO: Create and render a welcome component
 		// welcomeComponent := components.WelcomeMessage("Welcome to {{.Name}}, " + readyEvent.UserID + "!")
-		// renderedHTML, err := s.renderer.RenderComponent(ctx, welcomeComponent)
-		// if err != nil {
-		// 	slog.Error("Failed to render {{.Name}} welcome message", "error", err, "userID", readyEvent.UserID)
-		// 	return err
-		// }
-
 		// Send the welcome message directly to the user
-		// directMsg := pubsub.Message{
-		// 	Topic:   wsTopics.TopicHTMLDirect.Name(),
-		// 	Payload: renderedHTML,
-		// 	Metadata: map[string]string{
-		// 		"recipient_id": readyEvent.UserID,
-		// 	},
-		// }
-		// return s.publisher.Publish(ctx, directMsg)
+		// return pubsub.RenderAndBroadcast(ctx, s.publisher, s.renderer, welcomeComponent, wsTopics.TopicHTMLDirect,
+		// 	pubsub.WithMetadata("recipient_id", readyEvent.UserID))
 	}
 
 	return nil
@@ -1454,6 +1588,7 @@ var (
 			"action_type": "user_initiated",
 			"payload_fields": []string{"action", "data", "userID"},
 		},
+		AllowClientPublish: true,
 	})
 
 	// TopicStateUpdate represents state changes in this module
@@ -1477,24 +1612,16 @@ var (
 	// - TopicNotification for sending notifications
 )
 
-// RegisterTopics registers all {{.Name}} module topics with the topic manager
+// RegisterTopics registers all {{.Name}} module topics with the topic manager.
+// Registration is all-or-nothing: if any topic is invalid or already
+// registered, none are registered and the error lists every problem.
 func RegisterTopics() error {
-	manager := topicmgr.Default()
-	
-	topics := []topicmgr.Topic{
+	return topicmgr.Default().RegisterAll(
 		TopicExampleEvent,
 		TopicClientAction,
 		TopicStateUpdate,
 		// TODO: Add your additional topics here
-	}
-	
-	for _, topic := range topics {
-		if err := manager.Register(topic); err != nil {
-			return err
-		}
-	}
-	
-	return nil
+	)
 }
 
 // MustRegisterTopics registers all {{.Name}} module topics and panics on error
@@ -1511,15 +1638,36 @@ import (
 	"log/slog"
 
 	"github.com/labstack/echo/v4"
+{{- if or .WithDatabase .WithLiveQuery}}
+	"github.com/nfrund/goby/internal/database"
+{{- end}}
 	"github.com/nfrund/goby/internal/module"
+{{- if .WithPresence}}
+	"github.com/nfrund/goby/internal/presence"
+{{- end}}
 	"github.com/nfrund/goby/internal/registry"
 	"github.com/nfrund/goby/internal/rendering"
+{{- if .WithScript}}
+	"github.com/nfrund/goby/internal/script"
+{{- end}}
 )
 
 // {{.PascalName}}Module implements the module.Module interface for the {{.Name}} module.
 type {{.PascalName}}Module struct {
 	module.BaseModule
 	renderer rendering.Renderer
+{{- if .WithDatabase}}
+	fileRepository *database.FileStore
+{{- end}}
+{{- if .WithLiveQuery}}
+	liveQueries database.LiveQueryService
+{{- end}}
+{{- if .WithPresence}}
+	presenceService *presence.Service
+{{- end}}
+{{- if .WithScript}}
+	scriptEngine script.ScriptEngine
+{{- end}}
 }
 
 // Dependencies contains all the dependencies required by the {{.Name}} module.
@@ -1527,6 +1675,26 @@ type {{.PascalName}}Module struct {
 type Dependencies struct {
 	// Core dependencies
 	Renderer rendering.Renderer
+{{- if .WithDatabase}}
+	
+	// FileRepository stores file metadata.
+	FileRepository *database.FileStore
+{{- end}}
+{{- if .WithLiveQuery}}
+	
+	// LiveQueryService streams database changes as they happen.
+	LiveQueryService database.LiveQueryService
+{{- end}}
+{{- if .WithPresence}}
+	
+	// PresenceService tracks which users are online.
+	PresenceService *presence.Service
+{{- end}}
+{{- if .WithScript}}
+	
+	// ScriptEngine executes the module's scripts.
+	ScriptEngine script.ScriptEngine
+{{- end}}
 	
 	// To upgrade to full pubsub integration, uncomment and add:
 	// Publisher  pubsub.Publisher
@@ -1538,6 +1706,18 @@ type Dependencies struct {
 func New(deps Dependencies) *{{.PascalName}}Module {
 	return &{{.PascalName}}Module{
 		renderer: deps.Renderer,
+{{- if .WithDatabase}}
+		fileRepository: deps.FileRepository,
+{{- end}}
+{{- if .WithLiveQuery}}
+		liveQueries: deps.LiveQueryService,
+{{- end}}
+{{- if .WithPresence}}
+		presenceService: deps.PresenceService,
+{{- end}}
+{{- if .WithScript}}
+		scriptEngine: deps.ScriptEngine,
+{{- end}}
 	}
 }
 
@@ -1603,6 +1783,7 @@ import (
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/middleware"
 	"github.com/nfrund/goby/internal/rendering"
 	"github.com/nfrund/goby/internal/view"
@@ -1613,11 +1794,13 @@ import (
 // This is set by the authentication middleware.
 const UserContextKey = middleware.UserContextKey
 
-// Common errors for the {{.Name}} module
+// Common errors for the {{.Name}} module. They are the shared domain errors,
+// which the server's error handler turns into the matching status code and
+// JSON error body, so handlers can simply return them (wrapped or not).
 var (
-	ErrUnauthorized   = errors.New("authentication required")
-	ErrInvalidRequest = errors.New("invalid request data")
-	ErrNotFound       = errors.New("resource not found")
+	ErrUnauthorized   = domain.ErrUnauthorized
+	ErrInvalidRequest = domain.ErrInvalidRequest
+	ErrNotFound       = domain.ErrNotFound
 )
 
 // Handler handles HTTP requests for the {{.Name}} module.
@@ -1658,29 +1841,29 @@ func (h *Handler) getCurrentUser(c echo.Context) (*domain.User, error) {
 	return user, nil
 }
 
-// handleError provides consistent error handling across handlers.
+// handleError provides consistent error handling across handlers. The shared
+// errors above are returned as they are for the server's error handler to
+// map; anything else becomes defaultStatus.
 func (h *Handler) handleError(c echo.Context, err error, defaultStatus int) error {
 	// Log the error with request context for debugging
-	slog.Error("Handler error", 
-		"error", err, 
-		"path", c.Request().URL.Path, 
+	slog.Error("Handler error",
+		"error", err,
+		"path", c.Request().URL.Path,
 		"method", c.Request().Method,
 		"status", defaultStatus)
-	
-	switch {
-	case errors.Is(err, ErrUnauthorized):
-		return echo.NewHTTPError(http.StatusUnauthorized, "Authentication required")
-	case errors.Is(err, ErrInvalidRequest):
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	case errors.Is(err, ErrNotFound):
-		return echo.NewHTTPError(http.StatusNotFound, "Resource not found")
-	default:
-		return echo.NewHTTPError(defaultStatus, err.Error())
+
+	for _, known := range []error{ErrUnauthorized, ErrInvalidRequest, ErrNotFound} {
+		if errors.Is(err, known) {
+			return err
+		}
 	}
+	return echo.NewHTTPError(defaultStatus, err.Error())
 }
 
 // Get handles GET /{{.Name}} requests.
 // This is an example of a protected route that requires authentication.
+// Browsers get the page; API clients sending Accept: application/json (or
+// ?format=json) get the same data as JSON.
 func (h *Handler) Get(c echo.Context) error {
 	// Get the current user (requires authentication)
 	user, err := h.getCurrentUser(c)
@@ -1692,7 +1875,10 @@ func (h *Handler) Get(c echo.Context) error {
 	displayName := getUserDisplayName(user)
 	pageContent := page("{{.Name}}", displayName)
 	finalComponent := templ.Component(layouts.Base("{{.PascalName}}", view.GetFlashData(c).Messages, pageContent))
-	return c.Render(http.StatusOK, "", finalComponent)
+	return handlers.Respond(c, finalComponent, map[string]interface{}{
+		"module": "{{.Name}}",
+		"user":   displayName,
+	})
 }
 
 // GetPublic handles GET /{{.Name}}/public requests.
@@ -1709,7 +1895,10 @@ func (h *Handler) GetPublic(c echo.Context) error {
 	
 	pageContent := page("Public {{.Name}}", displayName)
 	finalComponent := templ.Component(layouts.Base("Public {{.PascalName}}", view.GetFlashData(c).Messages, pageContent))
-	return c.Render(http.StatusOK, "", finalComponent)
+	return handlers.Respond(c, finalComponent, map[string]interface{}{
+		"module": "{{.Name}}",
+		"user":   displayName,
+	})
 }
 
 // GetStatus handles GET /{{.Name}}/status requests.
@@ -1851,6 +2040,17 @@ func (h *Handler) PostCreateItem(c echo.Context) error {
 - ` + "`" + `Publisher` + "`" + ` - For publishing messages to topics
 - ` + "`" + `Subscriber` + "`" + ` - For subscribing to topic messages
 - ` + "`" + `TopicMgr` + "`" + ` - For topic registration and management
+- ` + "`" + `Config` + "`" + ` - Module-scoped settings read from ` + "`" + `{{.EnvPrefix}}*` + "`" + ` env vars
+
+## Configuration
+
+` + "`" + `Config` + "`" + ` is scoped to this module's prefix, so keys are relative:
+
+` + "```" + `go
+maxItems := deps.Config.GetInt("MAX_ITEMS", 100) // reads {{.EnvPrefix}}MAX_ITEMS
+` + "```" + `
+
+Use ` + "`" + `GetString` + "`" + `, ` + "`" + `GetInt` + "`" + `, ` + "`" + `GetBool` + "`" + ` or ` + "`" + `GetDuration` + "`" + ` with a default rather than reading global keys.
 
 ## Common Patterns
 
@@ -1881,7 +2081,7 @@ func (s *Subscriber) handleMessage(ctx context.Context, msg pubsub.Message) erro
     var event MyEvent
     if err := json.Unmarshal(msg.Payload, &event); err != nil {
         slog.Error("Failed to unmarshal event", "error", err)
-        return nil // Don't stop subscriber for bad messages
+        return pubsub.Reject(err) // Poison message: dead-letter it instead of retrying
     }
     
     // Process the event
@@ -1893,15 +2093,10 @@ func (s *Subscriber) handleMessage(ctx context.Context, msg pubsub.Message) erro
 ### Error Handling
 
 ` + "```" + `go
-func (h *Handler) handleError(c echo.Context, err error, defaultStatus int) error {
-    switch {
-    case errors.Is(err, ErrUnauthorized):
-        return echo.NewHTTPError(http.StatusUnauthorized, "Authentication required")
-    case errors.Is(err, ErrInvalidRequest):
-        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-    default:
-        return echo.NewHTTPError(defaultStatus, err.Error())
-    }
+// Return the shared domain errors (wrapped or not); the server's error
+// handler maps them to status codes and a JSON body with a code and message.
+if item == nil {
+    return fmt.Errorf("item %s: %w", id, domain.ErrNotFound) // 404 NOT_FOUND
 }
 ` + "```" + `
 
@@ -1995,7 +2190,7 @@ slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 ### Performance
 
 - Use structured logging instead of fmt.Printf
-- Handle errors gracefully in subscribers (don't stop processing)
+- Return pubsub.Reject(err) for malformed messages and pubsub.Nack(err) for transient failures
 - Consider message batching for high-volume scenarios
 - Monitor goroutine usage in background services
 
@@ -2179,15 +2374,10 @@ func (h *Handler) GetPage(c echo.Context) error {
 ### Error Handling
 
 ` + "```" + `go
-func (h *Handler) handleError(c echo.Context, err error, defaultStatus int) error {
-    switch {
-    case errors.Is(err, ErrUnauthorized):
-        return echo.NewHTTPError(http.StatusUnauthorized, "Authentication required")
-    case errors.Is(err, ErrInvalidRequest):
-        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-    default:
-        return echo.NewHTTPError(defaultStatus, err.Error())
-    }
+// Return the shared domain errors (wrapped or not); the server's error
+// handler maps them to status codes and a JSON body with a code and message.
+if item == nil {
+    return fmt.Errorf("item %s: %w", id, domain.ErrNotFound) // 404 NOT_FOUND
 }
 ` + "```" + `
 
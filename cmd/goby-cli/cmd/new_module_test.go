@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFile_ModuleFeatures(t *testing.T) {
+	all := moduleFeatures{WithDatabase: true, WithLiveQuery: true, WithPresence: true, WithScript: true}
+	for _, features := range []moduleFeatures{{}, {WithPresence: true}, all} {
+		for name, tmpl := range map[string]string{"full": moduleTemplate, "minimal": minimalModuleTemplate} {
+			path := filepath.Join(t.TempDir(), "module.go")
+			require.NoError(t, generateFile(path, tmpl, newTemplateData("inventory", features)), "%s %+v", name, features)
+
+			src, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, features.WithPresence, containsAll(string(src),
+				`"github.com/nfrund/goby/internal/presence"`,
+				"PresenceService *presence.Service",
+				"presenceService: deps.PresenceService,"), "%s %+v", name, features)
+			assert.Equal(t, features.WithDatabase, containsAll(string(src), "FileRepository *database.FileStore"), "%s %+v", name, features)
+		}
+	}
+}
+
+func TestDependencyHelperSource(t *testing.T) {
+	assert.Equal(t, `func notesDeps(deps Dependencies) notes.Dependencies {
+	return notes.Dependencies{
+		Renderer:        deps.Renderer,
+		PresenceService: deps.PresenceService,
+	}
+}
+`, dependencyHelperSource("notes", true, moduleFeatures{WithPresence: true}))
+
+	full := dependencyHelperSource("inventory", false, moduleFeatures{WithLiveQuery: true, WithScript: true})
+	assert.Contains(t, full, "\t\tTopicMgr:         deps.TopicMgr,\n\t\tLiveQueryService: deps.LiveQueryService,\n\t\tScriptEngine:     deps.ScriptEngine,\n")
+	assert.Contains(t, full, `Config:           moduleConfig(deps, "INVENTORY_"),`)
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAddModule_AppendsInCreationOrder(t *testing.T) {
+	for name, modules := range map[string]string{
+		"multi-line": "[]module.Module{\n\t\tchat.New(chatDeps(deps)),\n\t}",
+		"one-line":   "[]module.Module{chat.New(chatDeps(deps))}",
+		"empty":      "[]module.Module{}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "modules.go")
+			require.NoError(t, os.WriteFile(path, []byte(`package app
+
+import "github.com/nfrund/goby/internal/module"
+
+func NewModules(deps Dependencies) []module.Module {
+	return `+modules+`
+}
+`), 0644))
+
+			require.NoError(t, addModule(path, "inventory"))
+			require.NoError(t, addModule(path, "billing"))
+
+			src, err := os.ReadFile(path)
+			require.NoError(t, err)
+			file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+			require.NoError(t, err, "modules.go still parses")
+			formatted, err := format.Source(src)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(src), "modules.go is gofmt'd")
+
+			var got []string
+			for _, elt := range newModulesSlice(file).Elts {
+				got = append(got, elt.(*ast.CallExpr).Fun.(*ast.SelectorExpr).X.(*ast.Ident).Name)
+			}
+			want := []string{"inventory", "billing"}
+			if name != "empty" {
+				want = append([]string{"chat"}, want...)
+			}
+			assert.Equal(t, want, got, "new modules boot after existing ones, in creation order")
+			assert.Contains(t, string(src), "\t\tinventory.New(inventoryDeps(deps)),\n\t\tbilling.New(billingDeps(deps)),\n\t}")
+			assert.Contains(t, string(src), `"github.com/nfrund/goby/internal/modules/billing"`)
+		})
+	}
+}
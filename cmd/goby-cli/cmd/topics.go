@@ -15,7 +15,8 @@ between modules and framework components.
 Available subcommands:
   list      List all registered topics with optional filtering
   get       Get detailed information about a specific topic
-  validate  Validate a topic name and definition
+  validate  Validate a topic name and definition, or all topics with --all
+  graph     Export a Graphviz diagram of topics and their relationships
 
 Examples:
   # List all topics
@@ -32,6 +33,12 @@ Examples:
   
   # Validate a topic name
   goby-cli topics validate chat.message.sent
+  
+  # Validate every registered topic
+  goby-cli topics validate --all
+  
+  # Export the topic graph
+  goby-cli topics graph | dot -Tsvg -o topics.svg
 
 Use "goby-cli topics [command] --help" for more information about a specific command.`,
 }
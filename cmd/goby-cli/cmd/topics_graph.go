@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nfrund/goby/cmd/goby-cli/internal/topics"
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/spf13/cobra"
+)
+
+var graphOutputFile string
+
+// topicsGraphCmd represents the topics graph command
+var topicsGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export a diagram of topics and their relationships",
+	Long: `Export all registered topics as a Graphviz DOT diagram. Topics are grouped
+by module, and an edge is drawn for every topic listed in another topic's
+RelatedTopics. Client-publishable topics are drawn with a double border.
+
+Related topics that are not registered are drawn dashed and reported on stderr.
+
+Examples:
+  # Print the DOT diagram
+  goby-cli topics graph
+
+  # Render it to an SVG with Graphviz
+  goby-cli topics graph | dot -Tsvg -o topics.svg
+
+  # Write the diagram to a file
+  goby-cli topics graph --output topics.dot`,
+	Args: cobra.NoArgs,
+	Run:  topicsGraphHandler,
+}
+
+func topicsGraphHandler(cmd *cobra.Command, args []string) {
+	// Initialize topics system
+	if err := topics.Initialize(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to initialize topics: %v\n", err)
+		os.Exit(1)
+	}
+
+	manager := topicmgr.Default()
+
+	var out io.Writer = os.Stdout
+	if graphOutputFile != "" {
+		f, err := os.Create(graphOutputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create %s: %v\n", graphOutputFile, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := manager.ExportGraph(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to export topic graph: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, rel := range manager.DanglingRelations() {
+		fmt.Fprintf(os.Stderr, "⚠️  Topic '%s' declares unregistered related topic '%s'\n", rel.From, rel.To)
+	}
+}
+
+func init() {
+	topicsGraphCmd.Flags().StringVarP(&graphOutputFile, "output", "o", "", "Write the diagram to a file instead of stdout")
+	topicsCmd.AddCommand(topicsGraphCmd)
+}
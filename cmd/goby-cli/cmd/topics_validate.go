@@ -9,10 +9,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var validateAll bool
+
 // topicsValidateCmd represents the topics validate command
 var topicsValidateCmd = &cobra.Command{
-	Use:   "validate <topic-name>",
-	Short: "Validate a topic definition",
+	Use:   "validate [topic-name]",
+	Short: "Validate a topic definition, or every registered topic",
 	Long: `Validate a topic definition to ensure it follows proper naming conventions
 and has complete configuration. This command checks both the topic name format
 and the topic definition completeness.
@@ -22,26 +24,42 @@ The validation process includes:
 - Topic definition completeness (description, pattern, example)
 - Scope-specific validation rules (framework vs module topics)
 - Reserved prefix checking
+- Example validation (JSON examples must be well-formed, and topics with
+  payload_fields need a JSON object example)
+- Example payload validation against payload_fields (undeclared fields fail,
+  omitted fields warn)
+
+With --all, every registered topic is validated and a summary table of
+pass/warn/fail results is printed.
 
 Examples:
   # Basic validation
   goby-cli topics validate user.created          # Validate user.created topic
   goby-cli topics validate chat.message.sent     # Validate chat message topic
   
+  # Validate every registered topic
+  goby-cli topics validate --all
+  
   # Error cases
   goby-cli topics validate Invalid.Topic         # Shows name format error
   goby-cli topics validate nonexistent.topic     # Shows "topic not found" error
 
 Output:
   ✅ Success - Shows topic is valid with details
-  ❌ Error   - Shows specific validation failure with explanation`,
-	Args: cobra.ExactArgs(1),
-	Run:  topicsValidateHandler,
+  ⚠️  Warning - Shows the topic is valid but its example could be improved
+  ❌ Error   - Shows specific validation failure with explanation
+
+The command exits non-zero if any topic fails validation.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if validateAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: topicsValidateHandler,
 }
 
 func topicsValidateHandler(cmd *cobra.Command, args []string) {
-	topicName := args[0]
-
 	// Initialize topics system
 	if err := topics.Initialize(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to initialize topics: %v\n", err)
@@ -50,37 +68,44 @@ func topicsValidateHandler(cmd *cobra.Command, args []string) {
 
 	manager := topicmgr.Default()
 
-	// First validate the topic name format
-	nameErr := manager.ValidateTopicName(topicName)
-
-	// Look up the topic to validate its definition
-	topic, found := manager.Get(topicName)
-	var defErr error
-	if found {
-		// Validate the topic definition
-		defErr = manager.Validate(topic, "cli-validation")
-	} else {
-		// Topic not found - this is an error for validation
-		defErr = fmt.Errorf("topic '%s' not found", topicName)
+	if validateAll {
+		results := topics.ValidateAll(manager)
+		topics.DisplayValidationTable(results)
+		for _, r := range results {
+			if r.Status() == topics.CheckFail {
+				os.Exit(1)
+			}
+		}
+		return
 	}
 
+	result := topics.ValidateTopic(manager, args[0])
+
 	// Display validation results with appropriate formatting
-	if nameErr != nil {
-		fmt.Printf("❌ Topic name validation failed: %v\n", nameErr)
+	if check := result.Check(topics.CheckName); check.Status == topics.CheckFail {
+		fmt.Printf("❌ Topic name validation failed: %s\n", check.Message)
 		fmt.Fprintf(os.Stderr, "\nTopic names must follow the pattern: scope.module.action\n")
 		fmt.Fprintf(os.Stderr, "Examples: user.created, chat.message.sent, presence.user.online\n")
 		os.Exit(1)
 	}
 
-	if defErr != nil {
-		fmt.Printf("❌ Topic validation failed: %v\n", defErr)
-		if !found {
+	if check := result.Check(topics.CheckDefinition); check.Status == topics.CheckFail {
+		fmt.Printf("❌ Topic validation failed: %s\n", check.Message)
+		if result.Topic == nil {
 			fmt.Fprintf(os.Stderr, "\nUse 'goby-cli topics list' to see all available topics.\n")
 		}
 		os.Exit(1)
 	}
 
+	for _, check := range result.Checks {
+		if check.Status == topics.CheckFail {
+			fmt.Printf("❌ Topic %s validation failed: %s\n", check.Name, check.Message)
+			os.Exit(1)
+		}
+	}
+
 	// Success case - display topic details
+	topic := result.Topic
 	fmt.Printf("✅ Topic '%s' is valid\n", topic.Name())
 	fmt.Printf("   Scope: %s\n", topic.Scope())
 	if topic.Module() != "" {
@@ -93,8 +118,14 @@ func topicsValidateHandler(cmd *cobra.Command, args []string) {
 	if topic.Example() != "" {
 		fmt.Printf("   Example: %s\n", topic.Example())
 	}
+	for _, check := range result.Checks {
+		if check.Status == topics.CheckWarn {
+			fmt.Printf("⚠️  %s\n", check.Message)
+		}
+	}
 }
 
 func init() {
+	topicsValidateCmd.Flags().BoolVar(&validateAll, "all", false, "Validate every registered topic and print a summary")
 	topicsCmd.AddCommand(topicsValidateCmd)
 }
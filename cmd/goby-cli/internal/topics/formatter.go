@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/nfrund/goby/internal/topicmgr"
@@ -18,6 +19,10 @@ type TopicDisplay struct {
 	Pattern     string                 `json:"pattern"`
 	Example     string                 `json:"example"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	AllowClientPublish bool   `json:"allow_client_publish"`
+	EnabledAtStartup   bool   `json:"enabled_at_startup"`
+	DefinedAt          string `json:"defined_at,omitempty"`
 }
 
 // FormatConfig holds configuration for output formatting
@@ -90,6 +95,10 @@ func DisplayTopicDetails(topic topicmgr.Topic, format string) error {
 			Pattern:     topic.Pattern(),
 			Example:     topic.Example(),
 			Metadata:    topic.Metadata(),
+
+			AllowClientPublish: topic.AllowClientPublish(),
+			EnabledAtStartup:   topic.Enabled(),
+			DefinedAt:          topic.DefinedAt(),
 		}
 
 		encoder := json.NewEncoder(os.Stdout)
@@ -104,6 +113,21 @@ func DisplayTopicDetails(topic topicmgr.Topic, format string) error {
 	fmt.Printf("Description: %s\n", topic.Description())
 	fmt.Printf("Pattern:     %s\n", topic.Pattern())
 	fmt.Printf("Example:     %s\n", topic.Example())
+	fmt.Printf("Client publish: %t\n", topic.AllowClientPublish())
+	// The CLI sees the topic as registered, not a running server's state.
+	fmt.Printf("Enabled at startup: %t\n", topic.Enabled())
+	if loc := topic.DefinedAt(); loc != "" {
+		fmt.Printf("Defined at:  %s\n", loc)
+	}
+	if eventType := topic.EventType(); eventType != "" {
+		fmt.Printf("Event type:  %s\n", eventType)
+	}
+	if source := topic.Source(); source != "" {
+		fmt.Printf("Source:      %s\n", source)
+	}
+	if fields := topic.PayloadFields(); len(fields) > 0 {
+		fmt.Printf("Payload:     %s\n", strings.Join(fields, ", "))
+	}
 
 	// Show metadata if available
 	metadata := topic.Metadata()
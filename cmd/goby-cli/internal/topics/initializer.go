@@ -2,15 +2,13 @@ package topics
 
 import (
 	"fmt"
-	"io"
-	"log"
-	"log/slog"
 	"os"
 	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/nfrund/goby/internal/app"
 	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/logging"
 	"github.com/nfrund/goby/internal/registry"
 	"github.com/nfrund/goby/internal/topicmgr"
 )
@@ -20,8 +18,7 @@ import (
 // and makes it reusable for the goby-cli topics commands.
 func Initialize() error {
 	// Suppress all logging output to make CLI less chatty
-	log.SetOutput(io.Discard)
-	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	logging.NewSilent()
 
 	// Also suppress watermill logging
 	os.Setenv("WATERMILL_LOG_LEVEL", "ERROR")
@@ -34,6 +31,12 @@ func Initialize() error {
 	// Create minimal configuration
 	cfg := config.New()
 
+	// Register framework topics so they can be listed and referenced by
+	// module topics, as the server does at startup
+	if err := app.FrameworkTopics().Register(topicmgr.Default()); err != nil {
+		return fmt.Errorf("failed to register framework topics: %w", err)
+	}
+
 	// Create a minimal registry
 	reg := registry.New(cfg)
 
@@ -0,0 +1,242 @@
+package topics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// CheckStatus is the outcome of a single validation check.
+type CheckStatus int
+
+const (
+	// CheckSkipped means the check does not apply to the topic.
+	CheckSkipped CheckStatus = iota
+	CheckPass
+	CheckWarn
+	CheckFail
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckPass:
+		return "pass"
+	case CheckWarn:
+		return "warn"
+	case CheckFail:
+		return "fail"
+	default:
+		return "-"
+	}
+}
+
+// Check is the result of one validation check on a topic.
+type Check struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// Names of the checks ValidateTopic runs, in the order it runs them.
+const (
+	CheckName       = "name"
+	CheckDefinition = "definition"
+	CheckExample    = "example"
+	CheckPayload    = "payload"
+)
+
+// ValidationResult holds the checks run on one topic.
+type ValidationResult struct {
+	Name   string
+	Topic  topicmgr.Topic // nil if the topic is not registered
+	Checks []Check
+}
+
+// Status returns the worst status among the result's checks.
+func (r ValidationResult) Status() CheckStatus {
+	status := CheckPass
+	for _, c := range r.Checks {
+		if c.Status > status {
+			status = c.Status
+		}
+	}
+	return status
+}
+
+// Check returns the check with the given name.
+func (r ValidationResult) Check(name string) Check {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return Check{Name: name, Status: CheckSkipped}
+}
+
+// ValidateTopic runs every check on the topic registered as name:
+//   - name: the name follows the naming rules
+//   - definition: the topic definition is complete for its scope
+//   - example: a JSON example is well-formed, and topics declaring
+//     payload_fields have a JSON object example
+//   - payload: the example carries exactly the declared payload_fields
+//
+// A topic that isn't registered fails the definition check, and the example
+// checks are skipped.
+func ValidateTopic(m *topicmgr.Manager, name string) ValidationResult {
+	result := ValidationResult{Name: name}
+	result.Checks = append(result.Checks, checkOf(CheckName, m.ValidateTopicName(name)))
+
+	topic, found := m.Get(name)
+	if !found {
+		result.Checks = append(result.Checks, Check{
+			Name:    CheckDefinition,
+			Status:  CheckFail,
+			Message: fmt.Sprintf("topic '%s' not found", name),
+		})
+		return result
+	}
+	result.Topic = topic
+	result.Checks = append(result.Checks,
+		checkOf(CheckDefinition, m.Validate(topic, "cli-validation")),
+		checkExample(topic),
+		checkExamplePayload(topic),
+	)
+	return result
+}
+
+// ValidateAll runs ValidateTopic on every registered topic, sorted by name.
+func ValidateAll(m *topicmgr.Manager) []ValidationResult {
+	registered := m.List()
+	names := make([]string, 0, len(registered))
+	for _, topic := range registered {
+		names = append(names, topic.Name())
+	}
+	sort.Strings(names)
+
+	results := make([]ValidationResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, ValidateTopic(m, name))
+	}
+	return results
+}
+
+func checkOf(name string, err error) Check {
+	if err != nil {
+		return Check{Name: name, Status: CheckFail, Message: err.Error()}
+	}
+	return Check{Name: name, Status: CheckPass}
+}
+
+// isJSONExample reports whether example is meant as a JSON payload rather
+// than, say, a concrete topic name.
+func isJSONExample(example string) bool {
+	example = strings.TrimSpace(example)
+	return strings.HasPrefix(example, "{") || strings.HasPrefix(example, "[")
+}
+
+func checkExample(topic topicmgr.Topic) Check {
+	example := topic.Example()
+	declared := topic.PayloadFields()
+	switch {
+	case strings.TrimSpace(example) == "":
+		if len(declared) > 0 {
+			return Check{Name: CheckExample, Status: CheckWarn, Message: "no example payload to check against payload_fields"}
+		}
+		return Check{Name: CheckExample, Status: CheckSkipped}
+	case isJSONExample(example):
+		var v any
+		if err := json.Unmarshal([]byte(example), &v); err != nil {
+			return Check{Name: CheckExample, Status: CheckFail, Message: fmt.Sprintf("example is not valid JSON: %v", err)}
+		}
+		if _, ok := v.(map[string]any); !ok && len(declared) > 0 {
+			return Check{Name: CheckExample, Status: CheckFail, Message: "example is not a JSON object but the topic declares payload_fields"}
+		}
+		return Check{Name: CheckExample, Status: CheckPass}
+	case len(declared) > 0:
+		return Check{Name: CheckExample, Status: CheckFail, Message: "example is not a JSON payload but the topic declares payload_fields"}
+	default:
+		return Check{Name: CheckExample, Status: CheckSkipped}
+	}
+}
+
+// checkExamplePayload checks a JSON object example against payload_fields
+// with topicmgr.CheckPayloadStrict. Undeclared fields fail, since the bridge
+// would reject such a client publish; declared fields the example omits only
+// warn, since real payloads may omit them too.
+func checkExamplePayload(topic topicmgr.Topic) Check {
+	example := topic.Example()
+	if len(topic.PayloadFields()) == 0 || !isJSONExample(example) {
+		return Check{Name: CheckPayload, Status: CheckSkipped}
+	}
+	err := topicmgr.CheckPayloadStrict(topic, []byte(example))
+	var perr *topicmgr.PayloadError
+	switch {
+	case err == nil:
+		return Check{Name: CheckPayload, Status: CheckPass}
+	case errors.As(err, &perr) && perr.NotObject:
+		// Already reported by the example check.
+		return Check{Name: CheckPayload, Status: CheckSkipped}
+	case errors.As(err, &perr) && len(perr.Unknown) == 0:
+		return Check{Name: CheckPayload, Status: CheckWarn, Message: "example omits payload fields " + strings.Join(perr.Missing, ", ")}
+	case errors.As(err, &perr):
+		return Check{Name: CheckPayload, Status: CheckFail, Message: "example has undeclared payload fields " + strings.Join(perr.Unknown, ", ")}
+	default:
+		return checkOf(CheckPayload, err)
+	}
+}
+
+// DisplayValidationTable prints one row per topic with the status of each
+// check, followed by the problems found and a summary line.
+func DisplayValidationTable(results []ValidationResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tNAME\tDEFINITION\tEXAMPLE\tPAYLOAD\tRESULT")
+	fmt.Fprintln(w, "-----\t----\t----------\t-------\t-------\t------")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name,
+			r.Check(CheckName).Status,
+			r.Check(CheckDefinition).Status,
+			r.Check(CheckExample).Status,
+			r.Check(CheckPayload).Status,
+			r.Status())
+	}
+	w.Flush()
+
+	var passed, warned, failed int
+	var problems []string
+	for _, r := range results {
+		switch r.Status() {
+		case CheckFail:
+			failed++
+		case CheckWarn:
+			warned++
+		default:
+			passed++
+		}
+		for _, c := range r.Checks {
+			if c.Status == CheckWarn || c.Status == CheckFail {
+				problems = append(problems, fmt.Sprintf("  %s %s (%s): %s", statusIcon(c.Status), r.Name, c.Name, c.Message))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Println()
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+	}
+	fmt.Printf("\n%d topics: %d passed, %d with warnings, %d failed\n", len(results), passed, warned, failed)
+}
+
+func statusIcon(s CheckStatus) string {
+	if s == CheckFail {
+		return "❌"
+	}
+	return "⚠️ "
+}
@@ -0,0 +1,75 @@
+package topics
+
+import (
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTopic(t *testing.T) {
+	m := topicmgr.NewManager()
+	define := func(name, example string, fields ...string) {
+		cfg := topicmgr.TopicConfig{
+			Name:        name,
+			Module:      "shop",
+			Description: "A test topic",
+			Pattern:     name,
+			Example:     example,
+		}
+		if fields != nil {
+			cfg.Metadata = map[string]any{topicmgr.MetaPayloadFields: fields}
+		}
+		require.NoError(t, m.Register(topicmgr.DefineModule(cfg)))
+	}
+	define("shop.order.placed", `{"orderID":"o1","total":3}`, "orderID", "total")
+	define("shop.order.routed", "shop.order.routed")
+	define("shop.order.partial", `{"orderID":"o1"}`, "orderID", "total")
+	define("shop.order.untyped", "", "orderID")
+	define("shop.order.extra", `{"orderID":"o1","coupon":"x"}`, "orderID")
+	define("shop.order.broken", `{"orderID":`, "orderID")
+	define("shop.order.text", "an order", "orderID")
+
+	tests := []struct {
+		name    string
+		status  CheckStatus
+		check   string
+		message string
+	}{
+		{"shop.order.placed", CheckPass, "", ""},
+		{"shop.order.routed", CheckPass, "", ""},
+		{"shop.order.partial", CheckWarn, CheckPayload, "omits payload fields total"},
+		{"shop.order.untyped", CheckWarn, CheckExample, "no example payload"},
+		{"shop.order.extra", CheckFail, CheckPayload, "undeclared payload fields coupon"},
+		{"shop.order.broken", CheckFail, CheckExample, "not valid JSON"},
+		{"shop.order.text", CheckFail, CheckExample, "not a JSON payload"},
+		{"shop.order.missing", CheckFail, CheckDefinition, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateTopic(m, tt.name)
+			assert.Equal(t, tt.status, result.Status())
+			if tt.check != "" {
+				check := result.Check(tt.check)
+				assert.Equal(t, tt.status, check.Status)
+				assert.Contains(t, check.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	m := topicmgr.NewManager()
+	for _, name := range []string{"shop.b", "shop.a"} {
+		require.NoError(t, m.Register(topicmgr.DefineModule(topicmgr.TopicConfig{
+			Name: name, Module: "shop", Description: "A test topic", Pattern: name, Example: name,
+		})))
+	}
+
+	results := ValidateAll(m)
+	require.Len(t, results, 2)
+	assert.Equal(t, "shop.a", results[0].Name)
+	assert.Equal(t, "shop.b", results[1].Name)
+	assert.Equal(t, CheckPass, results[0].Status())
+}
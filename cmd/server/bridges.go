@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// lifecycleBridge is the part of *websocket.Bridge that startup drives.
+type lifecycleBridge interface {
+	Start(ctx context.Context) error
+	Shutdown(ctx context.Context)
+}
+
+// namedBridge is a bridge to start, with the name used in errors and in the
+// startup checklist ("HTML", "data").
+type namedBridge struct {
+	name   string
+	bridge lifecycleBridge
+}
+
+// startBridges starts bridges in order and returns a function that shuts
+// them all down. If a bridge fails to start, the bridges started before it
+// are shut down in reverse order before the error is returned, and so is the
+// failed one, which may already hold some of its subscriptions. A failed
+// boot therefore leaves no subscriptions or goroutines behind.
+func startBridges(ctx context.Context, check *startupCheck, bridges ...namedBridge) (shutdown func(context.Context), err error) {
+	var started []namedBridge
+	shutdown = func(ctx context.Context) {
+		for i := len(started) - 1; i >= 0; i-- {
+			started[i].bridge.Shutdown(ctx)
+		}
+	}
+
+	for _, b := range bridges {
+		started = append(started, b)
+		step := b.name + " bridge subscriptions"
+		if err := b.bridge.Start(ctx); err != nil {
+			err = check.record(step, fmt.Errorf("failed to start %s WebSocket bridge: %w", b.name, err))
+			slog.Warn("Shutting down WebSocket bridges after a failed start", "bridge", b.name, "started", len(started)-1)
+			shutdown(context.Background())
+			return nil, err
+		}
+		check.record(step, nil)
+	}
+	return shutdown, nil
+}
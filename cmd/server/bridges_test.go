@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBridge records its lifecycle calls in a shared log.
+type fakeBridge struct {
+	name     string
+	startErr error
+	log      *[]string
+}
+
+func (b *fakeBridge) Start(ctx context.Context) error {
+	*b.log = append(*b.log, "start "+b.name)
+	return b.startErr
+}
+
+func (b *fakeBridge) Shutdown(ctx context.Context) {
+	*b.log = append(*b.log, "shutdown "+b.name)
+}
+
+func TestStartBridges(t *testing.T) {
+	t.Run("starts every bridge and shuts them down in reverse", func(t *testing.T) {
+		var log []string
+		check := &startupCheck{}
+		shutdown, err := startBridges(context.Background(), check,
+			namedBridge{name: "HTML", bridge: &fakeBridge{name: "html", log: &log}},
+			namedBridge{name: "data", bridge: &fakeBridge{name: "data", log: &log}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"start html", "start data"}, log)
+		assert.False(t, check.failed())
+
+		shutdown(context.Background())
+		assert.Equal(t, []string{"start html", "start data", "shutdown data", "shutdown html"}, log)
+	})
+
+	t.Run("second bridge failing stops the first", func(t *testing.T) {
+		var log []string
+		check := &startupCheck{}
+		boom := errors.New("subscribe failed")
+		shutdown, err := startBridges(context.Background(), check,
+			namedBridge{name: "HTML", bridge: &fakeBridge{name: "html", log: &log}},
+			namedBridge{name: "data", bridge: &fakeBridge{name: "data", startErr: boom, log: &log}},
+		)
+		require.ErrorIs(t, err, boom)
+		assert.Contains(t, err.Error(), "failed to start data WebSocket bridge")
+		assert.Nil(t, shutdown)
+		assert.Equal(t, []string{"start html", "start data", "shutdown data", "shutdown html"}, log)
+
+		require.True(t, check.failed())
+		assert.Equal(t, "HTML bridge subscriptions", check.steps[0].name)
+		assert.NoError(t, check.steps[0].err)
+		assert.Equal(t, "data bridge subscriptions", check.steps[1].name)
+		assert.ErrorIs(t, check.steps[1].err, boom)
+	})
+
+	t.Run("first bridge failing never starts the second", func(t *testing.T) {
+		var log []string
+		_, err := startBridges(context.Background(), nil,
+			namedBridge{name: "HTML", bridge: &fakeBridge{name: "html", startErr: errors.New("boom"), log: &log}},
+			namedBridge{name: "data", bridge: &fakeBridge{name: "data", log: &log}},
+		)
+		require.Error(t, err)
+		assert.Equal(t, []string{"start html", "shutdown html"}, log)
+	})
+}
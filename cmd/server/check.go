@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/server"
+	"github.com/nfrund/goby/internal/storage"
+	"github.com/nfrund/goby/internal/websocket"
+)
+
+// startupCheck collects the outcome of each startup step when the server is
+// run with --check. A nil *startupCheck records nothing, so buildServer can
+// report to it unconditionally.
+type startupCheck struct {
+	steps []checkStep
+}
+
+// checkStep is a single line of the checklist.
+type checkStep struct {
+	name string
+	err  error
+}
+
+// record notes the outcome of a step and returns err unchanged.
+func (c *startupCheck) record(name string, err error) error {
+	if c != nil {
+		c.steps = append(c.steps, checkStep{name: name, err: err})
+	}
+	return err
+}
+
+// failed reports whether any recorded step failed.
+func (c *startupCheck) failed() bool {
+	for _, step := range c.steps {
+		if step.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// print writes the checklist, one step per line.
+func (c *startupCheck) print(w io.Writer) {
+	fmt.Fprintln(w, "Startup check:")
+	for _, step := range c.steps {
+		if step.err != nil {
+			fmt.Fprintf(w, "  [FAIL] %s: %v\n", step.name, step.err)
+			continue
+		}
+		fmt.Fprintf(w, "  [ok]   %s\n", step.name)
+	}
+	if c.failed() {
+		fmt.Fprintln(w, "Result: FAILED")
+		return
+	}
+	fmt.Fprintln(w, "Result: OK")
+}
+
+// runCheck builds the full application as for normal operation, without
+// serving HTTP, and prints a checklist of each startup step to w. It returns
+// the process exit code.
+func runCheck(cfg config.Provider, w io.Writer) int {
+	check := &startupCheck{}
+	check.record("configuration", validateConfig(cfg))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, cleanup, err := buildServer(ctx, cfg, check)
+	if err != nil && !check.failed() {
+		// Every failure path in buildServer should record itself; make
+		// sure one that doesn't still fails the check.
+		check.record("startup", err)
+	}
+	if cleanup != nil {
+		cleanup()
+	}
+
+	check.print(w)
+	if check.failed() {
+		return 1
+	}
+	return 0
+}
+
+// validateConfig reports configuration the server would start with but not
+// work correctly under. All problems are reported on one line.
+func validateConfig(cfg config.Provider) error {
+	var errs []string
+	if cfg.GetDBURL() == "" || cfg.GetDBNs() == "" || cfg.GetDBDb() == "" {
+		errs = append(errs, "SURREAL_URL, SURREAL_NS and SURREAL_DB are required")
+	}
+	if cfg.GetSessionSecret() == "" {
+		errs = append(errs, "SESSION_SECRET is required")
+	}
+	if backend := cfg.GetStorageBackend(); backend != "os" && backend != "mem" {
+		errs = append(errs, fmt.Sprintf("STORAGE_BACKEND %q must be 'os' or 'mem'", backend))
+	}
+	if _, err := storage.ParseSanitizeMode(cfg.GetStorageFilenamePolicy()); err != nil {
+		errs = append(errs, fmt.Sprintf("STORAGE_FILENAME_POLICY: %v", err))
+	}
+	if _, err := storage.ParsePathTemplate(cfg.GetStoragePathTemplate()); err != nil {
+		errs = append(errs, fmt.Sprintf("STORAGE_PATH_TEMPLATE: %v", err))
+	}
+	if cfg.GetStorageMaxBatchDelete() < 1 {
+		errs = append(errs, "STORAGE_MAX_BATCH_DELETE must be at least 1")
+	}
+	if format := strings.ToLower(cfg.GetLogFormat()); format != "" && format != "text" && format != "json" {
+		errs = append(errs, fmt.Sprintf("LOG_FORMAT %q must be 'text' or 'json'", format))
+	}
+	if level := cfg.GetLogLevel(); level != "" {
+		if _, err := logging.ParseLevel(level); err != nil {
+			errs = append(errs, fmt.Sprintf("LOG_LEVEL: %v", err))
+		}
+	}
+	if cfg.GetTopicMaxLength() < 0 || cfg.GetTopicMaxSegments() < 0 {
+		errs = append(errs, "TOPIC_MAX_LENGTH and TOPIC_MAX_SEGMENTS must not be negative")
+	}
+	if cfg.GetServerMaxBodySize() < 0 {
+		errs = append(errs, "SERVER_MAX_BODY_KB must not be negative")
+	}
+	if cfg.GetWebSocketMaxConnections() < 0 {
+		errs = append(errs, "WS_MAX_CONNECTIONS must not be negative")
+	}
+	if err := server.TimeoutsFromConfig(cfg).Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	wsDeps := websocket.BridgeDependencies{
+		SendBufferSize:       cfg.GetWebSocketSendBufferSize(),
+		BroadcastWorkers:     cfg.GetWebSocketBroadcastWorkers(),
+		WriteTimeout:         cfg.GetWebSocketWriteTimeout(),
+		Compression:          websocket.Compression(cfg.GetWebSocketCompression()),
+		CompressionThreshold: cfg.GetWebSocketCompressionThreshold(),
+		ReadLimit:            cfg.GetWebSocketReadLimit(),
+	}
+	if err := wsDeps.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("WebSocket settings (WS_*): %v", err))
+	}
+	for _, endpoint := range cfg.GetWebSocketGuestEndpoints() {
+		if endpoint != "html" && endpoint != "data" {
+			errs = append(errs, fmt.Sprintf("WS_GUEST_ENDPOINTS entry %q must be 'html' or 'data'", endpoint))
+		}
+	}
+	for _, pattern := range cfg.GetPresenceHiddenUsers() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("PRESENCE_HIDDEN_USERS pattern %q: %v", pattern, err))
+		}
+	}
+	if err := pubSubBackendConfig(cfg).Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("Pub/Sub settings (PUBSUB_BACKEND, PUBSUB_REDIS_URL): %v", err))
+	}
+	if cfg.GetPresenceGuestStaleThreshold() <= 0 {
+		errs = append(errs, "PRESENCE_GUEST_STALE_THRESHOLD must be positive")
+	}
+	if cfg.GetPresenceMaxStaleThreshold() <= 0 {
+		errs = append(errs, "PRESENCE_MAX_STALE_THRESHOLD must be positive")
+	}
+	if cfg.GetPresenceHistoryRetention() < 0 {
+		errs = append(errs, "PRESENCE_HISTORY_RETENTION must not be negative")
+	}
+	if cfg.GetPresenceSnapshotPath() != "" {
+		if cfg.GetPresenceSnapshotInterval() <= 0 {
+			errs = append(errs, "PRESENCE_SNAPSHOT_INTERVAL must be positive")
+		}
+		if cfg.GetPresenceSnapshotGrace() <= 0 {
+			errs = append(errs, "PRESENCE_SNAPSHOT_GRACE must be positive")
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/nfrund/goby/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Setenv("SURREAL_URL", "ws://localhost:8000/rpc")
+	t.Setenv("SURREAL_NS", "app")
+	t.Setenv("SURREAL_DB", "app")
+	t.Setenv("SESSION_SECRET", "secret")
+	require.NoError(t, validateConfig(config.New()))
+
+	t.Setenv("STORAGE_FILENAME_POLICY", "strict")
+	require.NoError(t, validateConfig(config.New()))
+	t.Setenv("STORAGE_FILENAME_POLICY", "lenient")
+	err := validateConfig(config.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'normalize', 'reject' or 'strict'")
+	t.Setenv("STORAGE_FILENAME_POLICY", "")
+
+	t.Setenv("SESSION_SECRET", "")
+	t.Setenv("STORAGE_BACKEND", "s3")
+	t.Setenv("LOG_LEVEL", "loud")
+	err = validateConfig(config.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SESSION_SECRET")
+	assert.Contains(t, err.Error(), "STORAGE_BACKEND")
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+}
+
+func TestStartupCheck(t *testing.T) {
+	var nilCheck *startupCheck
+	assert.NotPanics(t, func() { _ = nilCheck.record("ignored", nil) })
+
+	check := &startupCheck{}
+	check.record("configuration", nil)
+	assert.False(t, check.failed())
+
+	boom := errors.New("boom")
+	assert.Same(t, boom, check.record("database connection", boom))
+	assert.True(t, check.failed())
+
+	var out bytes.Buffer
+	check.print(&out)
+	assert.Equal(t, "Startup check:\n"+
+		"  [ok]   configuration\n"+
+		"  [FAIL] database connection: boom\n"+
+		"Result: FAILED\n", out.String())
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -50,6 +51,7 @@ func main() {
 	var (
 		extractScripts = flag.String("extract-scripts", "", "Extract embedded scripts to specified directory and exit")
 		forceExtract   = flag.Bool("force-extract", false, "Overwrite existing files when extracting scripts")
+		checkOnly      = flag.Bool("check", false, "Build the application, verify config, database, topics and bridges, print a checklist and exit")
 	)
 	flag.Parse()
 
@@ -58,7 +60,7 @@ func main() {
 		log.Println("No .env file found, relying on environment variables.")
 	}
 	cfg := config.New()
-	logging.New()
+	logging.New(cfg)
 
 	// 2. Handle script extraction if requested
 	if *extractScripts != "" {
@@ -70,24 +72,32 @@ func main() {
 		return // Exit after extraction
 	}
 
-	// 3. Build and Start Server (normal operation)
+	// 3. Run the startup self-check if requested
+	if *checkOnly {
+		os.Exit(runCheck(cfg, os.Stdout))
+	}
+
+	// 4. Build and Start Server (normal operation)
 	appCtx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	srv, cleanup, err := buildServer(appCtx, cfg)
+	srv, cleanup, err := buildServer(appCtx, cfg, nil)
 	if err != nil {
 		slog.Error("Failed to build server", "error", err)
 		os.Exit(1)
 	}
 	defer cleanup()
 
-	// 4. Start the server and its background processes
+	// 5. Start the server and its background processes
 	srv.Start(appCtx)
 }
 
 // buildServer is the "Composition Root" of the application. It's responsible for
 // creating and connecting all the application's components using dependency injection.
-func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Server, cleanup func(), err error) {
+//
+// When check is non-nil each startup step is recorded to it, and a database
+// that can't be reached fails the build even if degraded start is allowed.
+func buildServer(appCtx context.Context, cfg config.Provider, check *startupCheck) (srv *server.Server, cleanup func(), err error) {
 	// Set static asset loading strategy if specified
 	if AppStatic != "" {
 		os.Setenv("APP_STATIC", AppStatic)
@@ -109,6 +119,8 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 	// Provide core services
 	do.Provide(injector, provideDatabaseConnection)
 	do.Provide(injector, provideEmailService)
+	do.Provide(injector, provideEmailDispatcher)
+	do.Provide(injector, provideFailedEmailLog)
 	// Provide pubsub as both Publisher and Subscriber (WatermillBridge implements both)
 	do.Provide(injector, providePubSub)
 	do.Provide(injector, provideSubscriber)
@@ -125,14 +137,18 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 	// Provide database clients and stores
 	do.Provide(injector, provideUserStore)
 	do.Provide(injector, provideFileStore)
+	do.Provide(injector, provideEmailMessageStore)
 
 	// Provide WebSocket bridges (after pubsub and topic manager)
+	do.Provide(injector, provideConnectionLimiter)
 	do.ProvideNamed(injector, "html", provideHTMLBridge)
 	do.ProvideNamed(injector, "data", provideDataBridge)
 
 	// Provide handlers
 	do.Provide(injector, provideFileHandler)
 	do.Provide(injector, providePresenceHandler)
+	do.Provide(injector, provideEmailHandler)
+	do.Provide(injector, provideNotifier)
 
 	// Provide module dependencies
 	do.Provide(injector, provideModuleDependencies)
@@ -140,28 +156,32 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 	// Provide the server (depends on everything above)
 	do.Provide(injector, provideServer)
 
-	// Register topics (must be done before bridges start)
-	if err := websocket.RegisterTopics(); err != nil {
-		return nil, nil, fmt.Errorf("failed to register WebSocket topics: %w", err)
-	}
-	if err := presence.RegisterTopics(); err != nil {
-		return nil, nil, fmt.Errorf("failed to register presence topics: %w", err)
+	// Register framework topics (must be done before bridges start)
+	if err := app.FrameworkTopics().Register(topicmgr.Default()); err != nil {
+		return nil, nil, check.record("framework topics", fmt.Errorf("failed to register framework topics: %w", err))
 	}
+	check.record("framework topics", nil)
 
 	// Get services from DI container and initialize them
 	reg, err := do.Invoke[*registry.Registry](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get registry: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get registry: %w", err))
 	}
 
 	// Database connection needs explicit initialization
 	dbConn, err := do.Invoke[*database.Connection](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get database connection: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get database connection: %w", err))
 	}
 	if err := dbConn.Connect(context.Background()); err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		if check != nil || !cfg.GetDBAllowDegradedStart() {
+			return nil, nil, check.record("database connection", fmt.Errorf("failed to connect to database: %w", err))
+		}
+		// Degraded mode: keep serving static pages and liveness checks while the
+		// connection monitor keeps retrying in the background.
+		slog.Warn("Database unavailable at startup, starting in degraded mode", "error", err)
 	}
+	check.record("database connection", nil)
 	dbConn.StartMonitoring()
 
 	// Register the core connection manager in the registry (registry receives plain value)
@@ -170,7 +190,7 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 	// Get presence service and register in registry (registry is agnostic)
 	presenceService, err := do.Invoke[*presence.Service](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get presence service: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get presence service: %w", err))
 	}
 	registry.Set(reg, KeyPresenceService, presenceService)
 	slog.Info("Presence service initialized")
@@ -178,40 +198,70 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 	// Get script engine (provideScriptEngine already handles registry registration)
 	scriptEngine, err := do.Invoke[script.ScriptEngine](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get script engine: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get script engine: %w", err))
 	}
 	slog.Info("Script engine initialized")
 
 	// Start WebSocket bridges (they need explicit startup)
 	htmlBridge, err := do.InvokeNamed[*websocket.Bridge](injector, "html")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get HTML bridge: %w", err)
-	}
-	if err := htmlBridge.Start(appCtx); err != nil {
-		return nil, nil, fmt.Errorf("failed to start HTML WebSocket bridge: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get HTML bridge: %w", err))
 	}
-
 	dataBridge, err := do.InvokeNamed[*websocket.Bridge](injector, "data")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get data bridge: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get data bridge: %w", err))
 	}
-	if err := dataBridge.Start(appCtx); err != nil {
-		return nil, nil, fmt.Errorf("failed to start Data WebSocket bridge: %w", err)
+	shutdownBridges, err := startBridges(appCtx, check,
+		namedBridge{name: "HTML", bridge: htmlBridge},
+		namedBridge{name: "data", bridge: dataBridge},
+	)
+	if err != nil {
+		return nil, nil, err
 	}
+	// Don't leave the bridges subscribed if a later step fails the build.
+	defer func() {
+		if err != nil {
+			shutdownBridges(context.Background())
+		}
+	}()
+
+	// Relay ws.broadcast.all to both bridges' broadcast topics
+	broadcastFanout := websocket.NewBroadcastFanout(do.MustInvoke[pubsub.Publisher](injector), do.MustInvoke[pubsub.Subscriber](injector))
+	broadcastFanout.Start(appCtx)
+
+	// Send queued emails, and keep the ones that fail for inspection
+	emailDispatcher, err := do.Invoke[*email.Dispatcher](injector)
+	if err != nil {
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get email dispatcher: %w", err))
+	}
+	failedEmails := do.MustInvoke[*email.FailedLog](injector)
+	failedEmails.Start(appCtx)
+	emailDispatcher.Start(appCtx)
 
 	// Get the server
 	srv, err = do.Invoke[*server.Server](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create server: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to create server: %w", err))
 	}
 
 	// Initialize modules
 	moduleDeps, err := do.Invoke[app.Dependencies](injector)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get module dependencies: %w", err)
+		return nil, nil, check.record("dependency graph", fmt.Errorf("failed to get module dependencies: %w", err))
 	}
+	check.record("dependency graph", nil)
 	modules := app.NewModules(moduleDeps)
-	srv.InitModules(appCtx, modules, reg)
+	// Module failures are logged and the module skipped, so they only fail
+	// startup under --check or with MODULE_BOOT_STRICT.
+	if err := check.record("modules and module topics", srv.InitModules(appCtx, modules, reg)); err != nil && cfg.GetModuleBootStrict() {
+		return nil, nil, fmt.Errorf("module startup failed: %w", err)
+	}
+	// Load and compile scripts now that modules have registered their
+	// embedded ones; /ready reports not ready until this is done. Scripts
+	// that fail to compile are logged and fail when run, as before.
+	if err := check.record("script warm-up", srv.ScriptEngine.WarmUp(appCtx)); err != nil {
+		slog.Error("Script warm-up reported errors", "error", err)
+	}
 	srv.RegisterRoutes()
 
 	// Define cleanup function
@@ -230,16 +280,14 @@ func buildServer(appCtx context.Context, cfg config.Provider) (srv *server.Serve
 			slog.Error("Errors during HTTP server shutdown", "error", err)
 		}
 
-		// 2. Shut down modules, which may have background workers.
-		var errs error
-		for _, mod := range modules {
-			errs = errors.Join(errs, mod.Shutdown(shutdownCtx))
-		}
+		// 2. Shut down modules, stopping their background workers first.
+		errs := srv.ShutdownModules(shutdownCtx)
 
 		// 3. Shut down bridges
 		slog.Info("Shutting down WebSocket bridges...")
-		htmlBridge.Shutdown(context.Background())
-		dataBridge.Shutdown(context.Background())
+		errs = errors.Join(errs, broadcastFanout.Stop(shutdownCtx))
+		errs = errors.Join(errs, emailDispatcher.Stop(shutdownCtx), failedEmails.Stop(shutdownCtx))
+		shutdownBridges(context.Background())
 
 		// 4. Shut down script engine
 		slog.Info("Shutting down script engine...")
@@ -284,9 +332,37 @@ func provideDatabaseConnection(i do.Injector) (*database.Connection, error) {
 	return database.NewConnection(cfg), nil
 }
 
+// provideEmailService returns the sender the app sends email with. Emails are
+// queued and sent, with retries, by the email dispatcher.
 func provideEmailService(i do.Injector) (domain.EmailSender, error) {
+	ps := do.MustInvoke[pubsub.Publisher](i)
+	return email.NewQueueSender(ps), nil
+}
+
+func provideEmailDispatcher(i do.Injector) (*email.Dispatcher, error) {
 	cfg := do.MustInvoke[config.Provider](i)
-	return email.NewEmailService(cfg)
+	messages := do.MustInvoke[*database.EmailMessageStore](i)
+	ps := do.MustInvoke[pubsub.Publisher](i)
+	sub := do.MustInvoke[pubsub.Subscriber](i)
+	sender, err := email.NewEmailService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return email.NewDispatcher(sender, ps, sub, email.WithTracking(messages)), nil
+}
+
+func provideFailedEmailLog(i do.Injector) (*email.FailedLog, error) {
+	sub := do.MustInvoke[pubsub.Subscriber](i)
+	return email.NewFailedLog(sub), nil
+}
+
+func provideEmailMessageStore(i do.Injector) (*database.EmailMessageStore, error) {
+	dbConn := do.MustInvoke[*database.Connection](i)
+	client, err := database.NewClient[domain.EmailMessage](dbConn)
+	if err != nil {
+		return nil, err
+	}
+	return database.NewEmailMessageStore(client), nil
 }
 
 func providePubSub(i do.Injector) (pubsub.Publisher, error) {
@@ -299,11 +375,38 @@ func providePubSub(i do.Injector) (pubsub.Publisher, error) {
 		return nil, fmt.Errorf("failed to setup OpenTelemetry: %w", err)
 	}
 
+	cfg := do.MustInvoke[config.Provider](i)
+	backend, err := pubsub.NewBackend(pubSubBackendConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pub/sub backend: %w", err)
+	}
+	opts := []pubsub.BridgeOption{
+		pubsub.WithBackend(backend),
+		pubsub.WithDeduplication(pubsub.NewMemoryDedupStore(), cfg.GetPubSubDedupWindow()),
+	}
+
 	// Create pubsub bridge with or without tracing
 	if tracingConfig.Enabled {
-		return pubsub.NewWatermillBridgeWithTracer(tracer), nil
+		return pubsub.NewWatermillBridgeWithTracer(tracer, opts...), nil
 	}
-	return pubsub.NewWatermillBridge(), nil
+	return pubsub.NewWatermillBridge(opts...), nil
+}
+
+// pubSubBackendConfig reads the pub/sub backend settings.
+func pubSubBackendConfig(cfg config.Provider) pubsub.BackendConfig {
+	return pubsub.BackendConfig{
+		Name:     cfg.GetPubSubBackend(),
+		RedisURL: cfg.GetPubSubRedisURL(),
+	}
+}
+
+// sharedStore returns the pub/sub backend's cross-instance store, or nil
+// when messages stay in this process.
+func sharedStore(ps pubsub.Publisher) pubsub.DedupStore {
+	if wb, ok := ps.(*pubsub.WatermillBridge); ok {
+		return wb.SharedStore()
+	}
+	return nil
 }
 
 func provideSubscriber(i do.Injector) (pubsub.Subscriber, error) {
@@ -313,7 +416,17 @@ func provideSubscriber(i do.Injector) (pubsub.Subscriber, error) {
 }
 
 func provideTopicManager(i do.Injector) (*topicmgr.Manager, error) {
-	return topicmgr.Default(), nil
+	cfg := do.MustInvoke[config.Provider](i)
+	manager := topicmgr.Default()
+	manager.SetNameLimits(topicmgr.NameLimits{
+		MaxLength:   cfg.GetTopicMaxLength(),
+		MaxSegments: cfg.GetTopicMaxSegments(),
+	})
+	if cfg.GetTopicCaseSensitive() {
+		manager.SetCasePolicy(topicmgr.CaseStrict)
+	}
+	manager.SetAutoNamespace(cfg.GetTopicAutoNamespace())
+	return manager, nil
 }
 
 func provideRenderer(i do.Injector) (rendering.Renderer, error) {
@@ -327,10 +440,23 @@ func provideEchoRenderer(i do.Injector) (echo.Renderer, error) {
 }
 
 func providePresenceService(i do.Injector) (*presence.Service, error) {
+	appCtx := do.MustInvoke[context.Context](i)
 	ps := do.MustInvoke[pubsub.Publisher](i)
 	sub := do.MustInvoke[pubsub.Subscriber](i)
 	topicMgr := do.MustInvoke[*topicmgr.Manager](i)
-	return presence.NewService(ps, sub, topicMgr), nil
+	cfg := do.MustInvoke[config.Provider](i)
+	opts := []presence.Option{
+		presence.WithPublishBufferSize(cfg.GetPresencePublishBufferSize()),
+		presence.WithGuestStaleThreshold(cfg.GetPresenceGuestStaleThreshold()),
+		presence.WithMaxStaleThreshold(cfg.GetPresenceMaxStaleThreshold()),
+		presence.WithUserFilter(presence.MatchUsers(cfg.GetPresenceHiddenUsers())),
+		presence.WithHistoryRetention(cfg.GetPresenceHistoryRetention()),
+	}
+	if path := cfg.GetPresenceSnapshotPath(); path != "" {
+		opts = append(opts, presence.WithSnapshots(presence.NewFileSnapshotStore(path),
+			cfg.GetPresenceSnapshotInterval(), cfg.GetPresenceSnapshotGrace()))
+	}
+	return presence.NewService(appCtx, ps, sub, topicMgr, opts...), nil
 }
 
 func provideScriptEngine(i do.Injector) (script.ScriptEngine, error) {
@@ -377,15 +503,42 @@ func provideFileStore(i do.Injector) (*database.FileStore, error) {
 	return database.NewFileStore(fileClient), nil
 }
 
+// provideConnectionLimiter returns the connection cap shared by both
+// bridges, nil if WS_MAX_CONNECTIONS is unset.
+func provideConnectionLimiter(i do.Injector) (*websocket.ConnectionLimiter, error) {
+	cfg := do.MustInvoke[config.Provider](i)
+	return websocket.NewConnectionLimiter(cfg.GetWebSocketMaxConnections()), nil
+}
+
 func provideHTMLBridge(i do.Injector) (*websocket.Bridge, error) {
 	ps := do.MustInvoke[pubsub.Publisher](i)
 	sub := do.MustInvoke[pubsub.Subscriber](i)
 	topicMgr := do.MustInvoke[*topicmgr.Manager](i)
+	cfg := do.MustInvoke[config.Provider](i)
+	// Lifecycle events go to the endpoint's own topics and, for existing
+	// subscribers such as the chat example, to the shared ones.
+	ready, disconnected := websocket.ClientLifecycleTopics("html")
 	return websocket.NewBridge("html", websocket.BridgeDependencies{
-		Publisher:    ps,
-		Subscriber:   sub,
-		TopicManager: topicMgr,
-		ReadyTopic:   websocket.TopicClientReady,
+		Publisher:            ps,
+		Subscriber:           sub,
+		TopicManager:         topicMgr,
+		ReadyTopic:           ready,
+		DisconnectedTopic:    disconnected,
+		SharedLifecycle:      true,
+		HistorySize:          cfg.GetWebSocketHistorySize(),
+		ClientRateLimit:      cfg.GetWebSocketClientRateLimit(),
+		ClientRateBurst:      cfg.GetWebSocketClientRateBurst(),
+		SendBufferSize:       cfg.GetWebSocketSendBufferSize(),
+		BroadcastWorkers:     cfg.GetWebSocketBroadcastWorkers(),
+		WriteTimeout:         cfg.GetWebSocketWriteTimeout(),
+		SubscribeLimit:       cfg.GetWebSocketMaxSubscriptions(),
+		Compression:          websocket.Compression(cfg.GetWebSocketCompression()),
+		CompressionThreshold: cfg.GetWebSocketCompressionThreshold(),
+		ReadLimit:            cfg.GetWebSocketReadLimit(),
+		AllowGuests:          slices.Contains(cfg.GetWebSocketGuestEndpoints(), "html"),
+		OrderedDelivery:      cfg.GetWebSocketOrderedDelivery(),
+		ConnectionLimiter:    do.MustInvoke[*websocket.ConnectionLimiter](i),
+		DeliveryStore:        sharedStore(ps),
 	}), nil
 }
 
@@ -393,11 +546,32 @@ func provideDataBridge(i do.Injector) (*websocket.Bridge, error) {
 	ps := do.MustInvoke[pubsub.Publisher](i)
 	sub := do.MustInvoke[pubsub.Subscriber](i)
 	topicMgr := do.MustInvoke[*topicmgr.Manager](i)
+	cfg := do.MustInvoke[config.Provider](i)
+	// Lifecycle events go to the endpoint's own topics and, for existing
+	// subscribers such as the chat example, to the shared ones.
+	ready, disconnected := websocket.ClientLifecycleTopics("data")
 	return websocket.NewBridge("data", websocket.BridgeDependencies{
-		Publisher:    ps,
-		Subscriber:   sub,
-		TopicManager: topicMgr,
-		ReadyTopic:   websocket.TopicClientReady,
+		Publisher:            ps,
+		Subscriber:           sub,
+		TopicManager:         topicMgr,
+		ReadyTopic:           ready,
+		DisconnectedTopic:    disconnected,
+		SharedLifecycle:      true,
+		HistorySize:          cfg.GetWebSocketHistorySize(),
+		ClientRateLimit:      cfg.GetWebSocketClientRateLimit(),
+		ClientRateBurst:      cfg.GetWebSocketClientRateBurst(),
+		SendBufferSize:       cfg.GetWebSocketSendBufferSize(),
+		BroadcastWorkers:     cfg.GetWebSocketBroadcastWorkers(),
+		WriteTimeout:         cfg.GetWebSocketWriteTimeout(),
+		SubscribeLimit:       cfg.GetWebSocketMaxSubscriptions(),
+		Compression:          websocket.Compression(cfg.GetWebSocketCompression()),
+		CompressionThreshold: cfg.GetWebSocketCompressionThreshold(),
+		ReadLimit:            cfg.GetWebSocketReadLimit(),
+		EnableCBOR:           true,
+		AllowGuests:          slices.Contains(cfg.GetWebSocketGuestEndpoints(), "data"),
+		OrderedDelivery:      cfg.GetWebSocketOrderedDelivery(),
+		ConnectionLimiter:    do.MustInvoke[*websocket.ConnectionLimiter](i),
+		DeliveryStore:        sharedStore(ps),
 	}), nil
 }
 
@@ -405,20 +579,46 @@ func provideFileHandler(i do.Injector) (*handlers.FileHandler, error) {
 	fileStorage := do.MustInvoke[storage.Store](i)
 	fileRepo := do.MustInvoke[*database.FileStore](i)
 	cfg := do.MustInvoke[config.Provider](i)
+	ps := do.MustInvoke[pubsub.Publisher](i)
+	pathTemplate, err := storage.ParsePathTemplate(cfg.GetStoragePathTemplate())
+	if err != nil {
+		return nil, fmt.Errorf("STORAGE_PATH_TEMPLATE: %w", err)
+	}
+	filenameMode, err := storage.ParseSanitizeMode(cfg.GetStorageFilenamePolicy())
+	if err != nil {
+		return nil, fmt.Errorf("STORAGE_FILENAME_POLICY: %w", err)
+	}
 	return handlers.NewFileHandler(
 		fileStorage,
 		fileRepo,
 		cfg.GetMaxFileSize(),
 		cfg.GetAllowedMimeTypes(),
+		handlers.WithFilenameSanitization(filenameMode),
+		handlers.WithDeduplication(cfg.GetStorageDeduplicate()),
+		handlers.WithPathTemplate(pathTemplate),
+		handlers.WithUploadProgress(ps),
+		handlers.WithMaxBatchDelete(cfg.GetStorageMaxBatchDelete()),
 	), nil
 }
 
+func provideEmailHandler(i do.Injector) (*handlers.EmailHandler, error) {
+	messages := do.MustInvoke[*database.EmailMessageStore](i)
+	cfg := do.MustInvoke[config.Provider](i)
+	failed := do.MustInvoke[*email.FailedLog](i)
+	return handlers.NewEmailHandler(messages, cfg.GetEmailWebhookSecret(), handlers.WithFailedEmails(failed)), nil
+}
+
 func providePresenceHandler(i do.Injector) (*handlers.PresenceHandler, error) {
 	presenceService := do.MustInvoke[*presence.Service](i)
 	publisher := do.MustInvoke[pubsub.Publisher](i)
 	return handlers.NewPresenceHandler(presenceService, publisher), nil
 }
 
+func provideNotifier(i do.Injector) (*handlers.Notifier, error) {
+	publisher := do.MustInvoke[pubsub.Publisher](i)
+	return handlers.NewNotifier(publisher), nil
+}
+
 func provideLiveQueryService(i do.Injector) (database.LiveQueryService, error) {
 	dbConn := do.MustInvoke[*database.Connection](i)
 	return database.NewSurrealLiveQueryService(dbConn), nil
@@ -434,6 +634,8 @@ func provideModuleDependencies(i do.Injector) (app.Dependencies, error) {
 	scriptEngine := do.MustInvoke[script.ScriptEngine](i)
 	liveQueryService := do.MustInvoke[database.LiveQueryService](i)
 	fileRepo := do.MustInvoke[*database.FileStore](i)
+	notifier := do.MustInvoke[*handlers.Notifier](i)
+	cfg := do.MustInvoke[config.Provider](i)
 
 	return app.Dependencies{
 		Publisher:        publisher,
@@ -444,6 +646,8 @@ func provideModuleDependencies(i do.Injector) (app.Dependencies, error) {
 		ScriptEngine:     scriptEngine,
 		LiveQueryService: liveQueryService,
 		FileRepository:   fileRepo,
+		Notifier:         notifier,
+		Config:           cfg,
 	}, nil
 }
 
@@ -458,7 +662,9 @@ func provideServer(i do.Injector) (*server.Server, error) {
 	dataBridge := do.MustInvokeNamed[*websocket.Bridge](i, "data")
 	fileHandler := do.MustInvoke[*handlers.FileHandler](i)
 	presenceHandler := do.MustInvoke[*handlers.PresenceHandler](i)
+	emailHandler := do.MustInvoke[*handlers.EmailHandler](i)
 	scriptEngine := do.MustInvoke[script.ScriptEngine](i)
+	dbConn := do.MustInvoke[*database.Connection](i)
 	return server.New(server.Dependencies{
 		Config:          cfg,
 		Emailer:         emailer,
@@ -470,6 +676,8 @@ func provideServer(i do.Injector) (*server.Server, error) {
 		DataBridge:      dataBridge,
 		FileHandler:     fileHandler,
 		PresenceHandler: presenceHandler,
+		EmailHandler:    emailHandler,
 		ScriptEngine:    scriptEngine,
+		DBHealth:        dbConn,
 	})
 }
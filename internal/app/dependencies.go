@@ -1,7 +1,9 @@
 package app
 
 import (
+	"github.com/nfrund/goby/internal/config"
 	"github.com/nfrund/goby/internal/database"
+	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/modules/announcer"
 	"github.com/nfrund/goby/internal/modules/examples/chat"
 	"github.com/nfrund/goby/internal/modules/examples/profile"
@@ -24,6 +26,21 @@ type Dependencies struct {
 	ScriptEngine     script.ScriptEngine
 	LiveQueryService database.LiveQueryService
 	FileRepository   *database.FileStore
+	// Notifier pushes toast notifications to users' open pages. Pass it on
+	// to modules that notify users.
+	Notifier *handlers.Notifier
+	// Config is the application configuration. Modules receive a view scoped
+	// to their own env prefix via Config.Sub.
+	Config config.Provider
+}
+
+// moduleConfig returns the configuration view for a module's env prefix,
+// or nil when no configuration was provided.
+func moduleConfig(deps Dependencies, prefix string) config.Provider {
+	if deps.Config == nil {
+		return nil
+	}
+	return deps.Config.Sub(prefix)
 }
 
 // chatDeps creates the dependency struct for the chat module.
@@ -34,6 +51,7 @@ func chatDeps(deps Dependencies) chat.Dependencies {
 		Renderer:        deps.Renderer,
 		TopicMgr:        deps.TopicMgr,
 		PresenceService: deps.PresenceService,
+		Config:          moduleConfig(deps, "CHAT_"),
 	}
 }
 
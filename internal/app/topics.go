@@ -0,0 +1,18 @@
+package app
+
+import (
+	"github.com/nfrund/goby/internal/email"
+	"github.com/nfrund/goby/internal/presence"
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/nfrund/goby/internal/websocket"
+)
+
+// FrameworkTopics collects the framework topics of every core package, in
+// the order they are registered at startup. Register the result before any
+// bridge or service starts, so module topics can refer to them.
+func FrameworkTopics() *topicmgr.FrameworkRegistrar {
+	return topicmgr.NewFrameworkRegistrar().
+		Add("websocket", websocket.FrameworkTopics()...).
+		Add("presence", presence.FrameworkTopics()...).
+		Add("email", email.FrameworkTopics()...)
+}
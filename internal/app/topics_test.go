@@ -0,0 +1,19 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameworkTopics_Register(t *testing.T) {
+	m := topicmgr.NewManager()
+	require.NoError(t, FrameworkTopics().Register(m))
+
+	for _, topic := range FrameworkTopics().Topics() {
+		_, ok := m.Get(topic.Name())
+		assert.True(t, ok, "framework topic %s is registered", topic.Name())
+	}
+}
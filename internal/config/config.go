@@ -31,7 +31,13 @@ func RegisterModuleConfig(moduleName string, loader ModuleConfigLoader) {
 // This allows for dependency injection and easier testing.
 type Provider interface {
 	GetServerAddr() string
+	GetServerReadTimeout() time.Duration
+	GetServerReadHeaderTimeout() time.Duration
+	GetServerWriteTimeout() time.Duration
+	GetServerIdleTimeout() time.Duration
+	GetServerMaxBodySize() int64
 	GetDBURL() string
+	GetDBReplicaURLs() []string
 	GetDBNs() string
 	GetDBDb() string
 	GetDBUser() string
@@ -39,14 +45,63 @@ type Provider interface {
 	GetEmailProvider() string
 	GetEmailAPIKey() string
 	GetEmailSender() string
+	GetEmailWebhookSecret() string
 	GetAppBaseURL() string
 	GetSessionSecret() string
+	GetAdminEmails() []string
 	GetDBQueryTimeout() time.Duration
 	GetDBExecuteTimeout() time.Duration
+	GetDBAllowDegradedStart() bool
+	GetModuleBootStrict() bool
+	GetTopicMaxLength() int
+	GetTopicMaxSegments() int
+	GetTopicCaseSensitive() bool
+	GetTopicAutoNamespace() bool
 	GetStorageBackend() string
 	GetStoragePath() string
 	GetMaxFileSize() int64
 	GetAllowedMimeTypes() []string
+	GetStorageFilenamePolicy() string
+	GetStorageDeduplicate() bool
+	GetStoragePathTemplate() string
+	GetStorageMaxBatchDelete() int
+	GetWebSocketHistorySize() int
+	GetLogFormat() string
+	GetLogLevel() string
+	GetWebSocketClientRateLimit() float64
+	GetWebSocketClientRateBurst() int
+	GetWebSocketSendBufferSize() int
+	GetWebSocketBroadcastWorkers() int
+	GetWebSocketWriteTimeout() time.Duration
+	GetWebSocketMaxSubscriptions() int
+	GetWebSocketCompression() string
+	GetWebSocketCompressionThreshold() int
+	GetWebSocketReadLimit() int64
+	GetWebSocketGuestEndpoints() []string
+	GetWebSocketOrderedDelivery() bool
+	GetWebSocketMaxConnections() int
+	GetPresencePublishBufferSize() int
+	GetPresenceGuestStaleThreshold() time.Duration
+	GetPresenceMaxStaleThreshold() time.Duration
+	GetPresenceHiddenUsers() []string
+	GetPresenceSnapshotPath() string
+	GetPresenceSnapshotInterval() time.Duration
+	GetPresenceSnapshotGrace() time.Duration
+	GetPresenceHistoryRetention() time.Duration
+	GetPubSubDedupWindow() time.Duration
+	GetPubSubBackend() string
+	GetPubSubRedisURL() string
+	GetScriptsDir() string
+	// GetString, GetInt, GetBool and GetDuration read an arbitrary key, relative
+	// to the provider's prefix, returning fallback when unset or invalid.
+	GetString(key, fallback string) string
+	GetInt(key string, fallback int) int
+	GetBool(key string, fallback bool) bool
+	GetDuration(key string, fallback time.Duration) time.Duration
+	// Sub returns a view whose generic getters are scoped to prefix,
+	// e.g. Sub("CHAT_").GetInt("MAX_MESSAGE_LENGTH", 1000) reads
+	// CHAT_MAX_MESSAGE_LENGTH.
+	Sub(prefix string) Provider
 	// GetModuleConfig retrieves the configuration for a specific module.
 	// Returns the config and a boolean indicating if it was found.
 	GetModuleConfig(moduleName string) (interface{}, bool)
@@ -56,6 +111,7 @@ type Provider interface {
 type Config struct {
 	ServerAddr       string
 	DBURL            string
+	DBReplicaURLs    string // comma-separated read replica URLs
 	DBNs             string
 	DBDb             string
 	DBUser           string
@@ -67,12 +123,133 @@ type Config struct {
 	EmailSender      string
 	AppBaseURL       string
 	SessionSecret    string
+	// AdminEmails is a comma-separated list of the emails of users allowed
+	// to use operator endpoints such as the runtime log level; empty allows
+	// no one.
+	AdminEmails      string
 	StorageBackend   string
 	StoragePath      string
 	MaxFileSizeMB    int64
 	AllowedMimeTypes string
+
+	// Server*Timeout configure the HTTP server; zero disables a timeout.
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	// ServerMaxBodyKB caps request bodies outside of uploads; zero disables
+	// the limit.
+	ServerMaxBodyKB int64
+
+	// StorageFilenamePolicy controls how unsafe upload filenames are handled:
+	// "normalize" strips directory components, "reject" refuses them.
+	StorageFilenamePolicy string
+	// StorageDeduplicate makes repeated uploads of the same content by a user
+	// share one stored blob.
+	StorageDeduplicate bool
+	// StoragePathTemplate is the Go template for upload storage keys; empty
+	// uses storage.DefaultPathTemplate.
+	StoragePathTemplate string
+	// StorageMaxBatchDelete caps the files one batch delete request may name.
+	StorageMaxBatchDelete int
+	// DBAllowDegradedStart lets the server start even when the initial
+	// database connection fails; DB-backed routes return 503 until it recovers.
+	DBAllowDegradedStart bool
+	// TopicMaxLength and TopicMaxSegments bound topic names, including the
+	// topic.channel names WebSocket clients subscribe to; zero disables a limit.
+	TopicMaxLength   int
+	TopicMaxSegments int
+	// TopicCaseSensitive makes topic lookups require exact case instead of
+	// lowercasing names such as "Chat.Message" first.
+	TopicCaseSensitive bool
+	// TopicAutoNamespace registers module topics named without their module
+	// prefix as "<module>.<name>".
+	TopicAutoNamespace bool
+	// ModuleBootStrict aborts startup when any module fails to register or
+	// boot, including by panicking, instead of skipping it.
+	ModuleBootStrict bool
+	// WebSocketHistorySize is the number of messages retained per user for
+	// each history-enabled WebSocket topic.
+	WebSocketHistorySize int
+	// LogFormat selects the log output format: "text" or "json".
+	LogFormat string
+	// LogLevel is the minimum log level: "debug", "info", "warn" or "error".
+	LogLevel string
+	// WebSocketClientRateLimit is the sustained number of messages per second
+	// a WebSocket client may send; negative disables the limit.
+	WebSocketClientRateLimit float64
+	// WebSocketClientRateBurst is the number of messages a client may send in
+	// a burst above the sustained rate.
+	WebSocketClientRateBurst int
+	// WebSocketSendBufferSize is the number of outbound messages queued per
+	// WebSocket client before messages to it are dropped.
+	WebSocketSendBufferSize int
+	// WebSocketBroadcastWorkers is the most goroutines one WebSocket
+	// broadcast is delivered by; zero uses GOMAXPROCS.
+	WebSocketBroadcastWorkers int
+	// WebSocketWriteTimeout bounds each write to a WebSocket client.
+	WebSocketWriteTimeout time.Duration
+	// WebSocketMaxSubscriptions caps the topics one WebSocket client may be
+	// subscribed to at once; negative removes the cap.
+	WebSocketMaxSubscriptions int
+	// WebSocketCompression selects permessage-deflate negotiation:
+	// "disabled", "no_context_takeover" or "context_takeover".
+	WebSocketCompression string
+	// WebSocketCompressMinSize is the smallest message compressed; zero
+	// uses the library default.
+	WebSocketCompressMinSize int
+	// WebSocketReadLimit is the largest message a WebSocket client may send.
+	WebSocketReadLimit int64
+	// WebSocketGuests is a comma-separated list of WebSocket endpoints
+	// ("html", "data") that admit guests; empty admits none.
+	WebSocketGuests string
+	// WebSocketOrdered delivers each user's WebSocket messages in the order
+	// they were published, holding early arrivals briefly.
+	WebSocketOrdered bool
+	// WebSocketMaxConnections caps the connections open at once across both
+	// WebSocket endpoints; zero means no limit.
+	WebSocketMaxConnections int
+	// GuestStaleThreshold is how long a guest's presence lasts without a
+	// heartbeat before it is cleaned up.
+	GuestStaleThreshold time.Duration
+	// PresenceMaxStaleThreshold caps how long a user's presence lasts
+	// without a heartbeat when it follows the client's own ping cadence.
+	PresenceMaxStaleThreshold time.Duration
+	// PresenceHiddenUsers is a comma-separated list of user ID patterns
+	// (path.Match syntax) left out of the public online list.
+	PresenceHiddenUsers string
+	// PresencePublishBufferSize is the capacity of the presence service's
+	// queue of pending updates; overflow is coalesced into the newest one.
+	PresencePublishBufferSize int
+	// PresenceSnapshotPath is the file presence is snapshotted to, so it
+	// survives restarts; empty disables snapshots.
+	PresenceSnapshotPath string
+	// PresenceSnapshotInterval is how often the presence snapshot is written.
+	PresenceSnapshotInterval time.Duration
+	// PresenceSnapshotGrace is how long presence restored from a snapshot
+	// waits for clients to reconnect before it expires.
+	PresenceSnapshotGrace time.Duration
+	// PresenceHistoryRetention is how long connection history is kept for
+	// users who haven't connected since; zero keeps it forever.
+	PresenceHistoryRetention time.Duration
+	// PubSubDedupWindow is how long processed message IDs are remembered so
+	// redeliveries are skipped; zero disables deduplication.
+	PubSubDedupWindow time.Duration
+	// PubSubBackend is the pub/sub transport: "memory" (default) or "redis".
+	PubSubBackend string
+	// PubSubRedisURL is the Redis URL the redis pub/sub backend connects to.
+	PubSubRedisURL string
+	// ScriptsDir is the directory external scripts are loaded and watched
+	// from, one subdirectory per module.
+	ScriptsDir string
+	// EmailWebhookSecret is the signing secret for email provider delivery
+	// webhooks; without it the webhook endpoint rejects every request.
+	EmailWebhookSecret string
 	// ModuleConfigs holds configuration for registered modules.
 	moduleConfigs map[string]interface{}
+
+	// prefix is prepended to keys read by the generic getters; see Sub.
+	prefix string
 }
 
 // New loads configuration from environment variables.
@@ -88,24 +265,69 @@ func New() Provider {
 	}
 
 	cfg := &Config{
-		ServerAddr:       os.Getenv("SERVER_ADDR"),
-		DBURL:            os.Getenv("SURREAL_URL"),
-		DBUser:           os.Getenv("SURREAL_USER"),
-		DBPass:           os.Getenv("SURREAL_PASS"),
-		DBNs:             os.Getenv("SURREAL_NS"),
-		DBDb:             os.Getenv("SURREAL_DB"),
-		DBQueryTimeout:   queryTimeout,
-		DBExecuteTimeout: executeTimeout,
-		EmailProvider:    os.Getenv("EMAIL_PROVIDER"),
-		EmailAPIKey:      os.Getenv("EMAIL_API_KEY"),
-		EmailSender:      os.Getenv("EMAIL_SENDER"),
-		AppBaseURL:       os.Getenv("APP_BASE_URL"),
-		SessionSecret:    os.Getenv("SESSION_SECRET"),
-		StorageBackend:   os.Getenv("STORAGE_BACKEND"),
-		StoragePath:      os.Getenv("STORAGE_PATH"),
-		MaxFileSizeMB:    getInt64Env("STORAGE_MAX_FILE_SIZE_MB", 5),
-		AllowedMimeTypes: os.Getenv("STORAGE_ALLOWED_MIME_TYPES"),
-		moduleConfigs:    make(map[string]interface{}),
+		ServerAddr:                os.Getenv("SERVER_ADDR"),
+		ServerReadTimeout:         getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
+		ServerReadHeaderTimeout:   getDurationEnv("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerWriteTimeout:        getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
+		ServerIdleTimeout:         getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
+		ServerMaxBodyKB:           getInt64Env("SERVER_MAX_BODY_KB", 1024),
+		DBURL:                     os.Getenv("SURREAL_URL"),
+		DBReplicaURLs:             os.Getenv("SURREAL_REPLICA_URLS"),
+		DBUser:                    os.Getenv("SURREAL_USER"),
+		DBPass:                    os.Getenv("SURREAL_PASS"),
+		DBNs:                      os.Getenv("SURREAL_NS"),
+		DBDb:                      os.Getenv("SURREAL_DB"),
+		DBQueryTimeout:            queryTimeout,
+		DBExecuteTimeout:          executeTimeout,
+		DBAllowDegradedStart:      getBoolEnv("DB_ALLOW_DEGRADED_START", false),
+		ModuleBootStrict:          getBoolEnv("MODULE_BOOT_STRICT", false),
+		TopicMaxLength:            int(getInt64Env("TOPIC_MAX_LENGTH", 100)),
+		TopicMaxSegments:          int(getInt64Env("TOPIC_MAX_SEGMENTS", 8)),
+		TopicCaseSensitive:        getBoolEnv("TOPIC_CASE_SENSITIVE", false),
+		TopicAutoNamespace:        getBoolEnv("TOPIC_AUTO_NAMESPACE", false),
+		WebSocketHistorySize:      int(getInt64Env("WS_HISTORY_SIZE", 50)),
+		WebSocketClientRateLimit:  getFloat64Env("WS_CLIENT_RATE_LIMIT", 20),
+		WebSocketClientRateBurst:  int(getInt64Env("WS_CLIENT_RATE_BURST", 40)),
+		WebSocketSendBufferSize:   int(getInt64Env("WS_SEND_BUFFER_SIZE", 256)),
+		WebSocketBroadcastWorkers: int(getInt64Env("WS_BROADCAST_WORKERS", 0)),
+		WebSocketWriteTimeout:     getDurationEnv("WS_WRITE_TIMEOUT", 10*time.Second),
+		WebSocketMaxSubscriptions: int(getInt64Env("WS_MAX_SUBSCRIPTIONS", 100)),
+		WebSocketCompression:      os.Getenv("WS_COMPRESSION"),
+		WebSocketCompressMinSize:  int(getInt64Env("WS_COMPRESSION_THRESHOLD", 0)),
+		WebSocketReadLimit:        getInt64Env("WS_READ_LIMIT", 512),
+		WebSocketGuests:           os.Getenv("WS_GUEST_ENDPOINTS"),
+		WebSocketOrdered:          getBoolEnv("WS_ORDERED_DELIVERY", false),
+		WebSocketMaxConnections:   int(getInt64Env("WS_MAX_CONNECTIONS", 0)),
+		PresencePublishBufferSize: int(getInt64Env("PRESENCE_PUBLISH_BUFFER", 100)),
+		GuestStaleThreshold:       getDurationEnv("PRESENCE_GUEST_STALE_THRESHOLD", time.Minute),
+		PresenceMaxStaleThreshold: getDurationEnv("PRESENCE_MAX_STALE_THRESHOLD", 10*time.Minute),
+		PresenceHiddenUsers:       os.Getenv("PRESENCE_HIDDEN_USERS"),
+		PresenceSnapshotPath:      os.Getenv("PRESENCE_SNAPSHOT_PATH"),
+		PresenceSnapshotInterval:  getDurationEnv("PRESENCE_SNAPSHOT_INTERVAL", 30*time.Second),
+		PresenceSnapshotGrace:     getDurationEnv("PRESENCE_SNAPSHOT_GRACE", 2*time.Minute),
+		PresenceHistoryRetention:  getDurationEnv("PRESENCE_HISTORY_RETENTION", 24*time.Hour),
+		PubSubDedupWindow:         getDurationEnv("PUBSUB_DEDUP_WINDOW", 0),
+		PubSubBackend:             os.Getenv("PUBSUB_BACKEND"),
+		PubSubRedisURL:            os.Getenv("PUBSUB_REDIS_URL"),
+		ScriptsDir:                os.Getenv("SCRIPTS_DIR"),
+		EmailProvider:             os.Getenv("EMAIL_PROVIDER"),
+		EmailAPIKey:               os.Getenv("EMAIL_API_KEY"),
+		EmailSender:               os.Getenv("EMAIL_SENDER"),
+		EmailWebhookSecret:        os.Getenv("EMAIL_WEBHOOK_SECRET"),
+		AppBaseURL:                os.Getenv("APP_BASE_URL"),
+		SessionSecret:             os.Getenv("SESSION_SECRET"),
+		AdminEmails:               os.Getenv("ADMIN_EMAILS"),
+		StorageBackend:            os.Getenv("STORAGE_BACKEND"),
+		StoragePath:               os.Getenv("STORAGE_PATH"),
+		MaxFileSizeMB:             getInt64Env("STORAGE_MAX_FILE_SIZE_MB", 5),
+		AllowedMimeTypes:          os.Getenv("STORAGE_ALLOWED_MIME_TYPES"),
+		StorageFilenamePolicy:     os.Getenv("STORAGE_FILENAME_POLICY"),
+		StorageDeduplicate:        getBoolEnv("STORAGE_DEDUPLICATE", false),
+		StoragePathTemplate:       os.Getenv("STORAGE_PATH_TEMPLATE"),
+		StorageMaxBatchDelete:     int(getInt64Env("STORAGE_MAX_BATCH_DELETE", 100)),
+		LogFormat:                 os.Getenv("LOG_FORMAT"),
+		LogLevel:                  os.Getenv("LOG_LEVEL"),
+		moduleConfigs:             make(map[string]interface{}),
 	}
 
 	// Load all registered module configurations
@@ -120,6 +342,10 @@ func New() Provider {
 		cfg.ServerAddr = ":8080"
 	}
 
+	if cfg.ScriptsDir == "" {
+		cfg.ScriptsDir = "scripts"
+	}
+
 	if cfg.DBURL == "" || cfg.DBNs == "" || cfg.DBDb == "" {
 		// It's better for the application's entry point (main.go) to handle this.
 		log.Println("WARNING: One or more required database environment variables are not set (SURREAL_URL, SURREAL_NS, SURREAL_DB).")
@@ -162,6 +388,19 @@ func New() Provider {
 		cfg.StoragePath = "tmp/uploads" // Default local storage path
 	}
 
+	if cfg.StorageFilenamePolicy == "" {
+		cfg.StorageFilenamePolicy = "normalize"
+	}
+
+	// Default to verbose, human-readable logs for development
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "text"
+	}
+
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "debug"
+	}
+
 	return cfg
 }
 
@@ -175,16 +414,86 @@ func getInt64Env(key string, fallback int64) int64 {
 	return fallback
 }
 
+// getFloat64Env is a helper to parse a float64 from env with a default.
+func getFloat64Env(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// getDurationEnv is a helper to parse a time.Duration from env with a default.
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// getBoolEnv is a helper to parse a bool from env with a default.
+func getBoolEnv(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
 // GetServerAddr returns the server address.
 func (c *Config) GetServerAddr() string {
 	return c.ServerAddr
 }
 
+// GetServerReadTimeout returns the maximum time to read a whole request,
+// including the body.
+func (c *Config) GetServerReadTimeout() time.Duration {
+	return c.ServerReadTimeout
+}
+
+// GetServerReadHeaderTimeout returns the maximum time to read request headers.
+func (c *Config) GetServerReadHeaderTimeout() time.Duration {
+	return c.ServerReadHeaderTimeout
+}
+
+// GetServerWriteTimeout returns the maximum time to write a response.
+// WebSocket connections are exempt once upgraded.
+func (c *Config) GetServerWriteTimeout() time.Duration {
+	return c.ServerWriteTimeout
+}
+
+// GetServerIdleTimeout returns how long keep-alive connections may sit idle.
+func (c *Config) GetServerIdleTimeout() time.Duration {
+	return c.ServerIdleTimeout
+}
+
+// GetServerMaxBodySize returns the maximum request body size in bytes for
+// routes without their own limit; zero means no limit.
+func (c *Config) GetServerMaxBodySize() int64 {
+	return c.ServerMaxBodyKB * 1024
+}
+
 // GetDBURL returns the database URL.
 func (c *Config) GetDBURL() string {
 	return c.DBURL
 }
 
+// GetDBReplicaURLs returns the URLs of the read replicas, if any. They share
+// the primary's namespace, database and credentials.
+func (c *Config) GetDBReplicaURLs() []string {
+	var urls []string
+	for _, u := range strings.Split(c.DBReplicaURLs, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
 // GetDBNs returns the database namespace.
 func (c *Config) GetDBNs() string {
 	return c.DBNs
@@ -220,6 +529,11 @@ func (c *Config) GetEmailSender() string {
 	return c.EmailSender
 }
 
+// GetEmailWebhookSecret returns the signing secret for email delivery webhooks.
+func (c *Config) GetEmailWebhookSecret() string {
+	return c.EmailWebhookSecret
+}
+
 // GetAppBaseURL returns the application's base URL.
 func (c *Config) GetAppBaseURL() string {
 	return c.AppBaseURL
@@ -230,6 +544,18 @@ func (c *Config) GetSessionSecret() string {
 	return c.SessionSecret
 }
 
+// GetAdminEmails returns the lowercased emails of the users allowed to use
+// operator endpoints. It is empty unless ADMIN_EMAILS is set.
+func (c *Config) GetAdminEmails() []string {
+	var emails []string
+	for _, e := range strings.Split(c.AdminEmails, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			emails = append(emails, e)
+		}
+	}
+	return emails
+}
+
 // GetDBQueryTimeout returns the default timeout for database read queries.
 func (c *Config) GetDBQueryTimeout() time.Duration {
 	return c.DBQueryTimeout
@@ -240,6 +566,40 @@ func (c *Config) GetDBExecuteTimeout() time.Duration {
 	return c.DBExecuteTimeout
 }
 
+// GetDBAllowDegradedStart reports whether the server may start without a
+// database connection instead of failing fast.
+func (c *Config) GetDBAllowDegradedStart() bool {
+	return c.DBAllowDegradedStart
+}
+
+// GetTopicMaxLength returns the maximum topic name length; zero means no limit.
+func (c *Config) GetTopicMaxLength() int {
+	return c.TopicMaxLength
+}
+
+// GetTopicMaxSegments returns the maximum number of dot-separated segments in
+// a topic name; zero means no limit.
+func (c *Config) GetTopicMaxSegments() int {
+	return c.TopicMaxSegments
+}
+
+// GetTopicCaseSensitive reports whether topic lookups require exact case.
+func (c *Config) GetTopicCaseSensitive() bool {
+	return c.TopicCaseSensitive
+}
+
+// GetTopicAutoNamespace reports whether module topic names are prefixed with
+// their module on registration.
+func (c *Config) GetTopicAutoNamespace() bool {
+	return c.TopicAutoNamespace
+}
+
+// GetModuleBootStrict reports whether a module that fails to register or
+// boot aborts startup rather than being skipped.
+func (c *Config) GetModuleBootStrict() bool {
+	return c.ModuleBootStrict
+}
+
 // GetStorageBackend returns the configured storage backend ('os' or 'mem').
 func (c *Config) GetStorageBackend() string {
 	return c.StorageBackend
@@ -256,6 +616,7 @@ func (c *Config) GetMaxFileSize() int64 {
 }
 
 // GetAllowedMimeTypes returns a list of allowed MIME types for uploads.
+// Entries may be exact ("image/png") or wildcards ("image/*", "*/*").
 func (c *Config) GetAllowedMimeTypes() []string {
 	if c.AllowedMimeTypes == "" {
 		// Return a default list or an empty list to allow all types
@@ -264,6 +625,195 @@ func (c *Config) GetAllowedMimeTypes() []string {
 	return strings.Split(c.AllowedMimeTypes, ",")
 }
 
+// GetStorageFilenamePolicy returns how unsafe upload filenames are handled
+// ('normalize' or 'reject').
+func (c *Config) GetStorageFilenamePolicy() string {
+	return c.StorageFilenamePolicy
+}
+
+// GetStorageDeduplicate reports whether uploads are deduplicated by content hash.
+func (c *Config) GetStorageDeduplicate() bool {
+	return c.StorageDeduplicate
+}
+
+// GetStoragePathTemplate returns the template for upload storage keys, or ""
+// for the default layout.
+func (c *Config) GetStoragePathTemplate() string {
+	return c.StoragePathTemplate
+}
+
+// GetStorageMaxBatchDelete returns the most files one batch delete request
+// may name.
+func (c *Config) GetStorageMaxBatchDelete() int {
+	return c.StorageMaxBatchDelete
+}
+
+// GetWebSocketHistorySize returns how many messages are retained per user for
+// each history-enabled WebSocket topic.
+func (c *Config) GetWebSocketHistorySize() int {
+	return c.WebSocketHistorySize
+}
+
+// GetLogFormat returns the log output format ("text" or "json").
+func (c *Config) GetLogFormat() string {
+	return c.LogFormat
+}
+
+// GetLogLevel returns the minimum log level name.
+func (c *Config) GetLogLevel() string {
+	return c.LogLevel
+}
+
+// GetWebSocketClientRateLimit returns the per-client inbound message rate in
+// messages per second. A negative value disables rate limiting.
+func (c *Config) GetWebSocketClientRateLimit() float64 {
+	return c.WebSocketClientRateLimit
+}
+
+// GetWebSocketClientRateBurst returns the per-client inbound message burst size.
+func (c *Config) GetWebSocketClientRateBurst() int {
+	return c.WebSocketClientRateBurst
+}
+
+// GetWebSocketSendBufferSize returns the per-client outbound message queue size.
+func (c *Config) GetWebSocketSendBufferSize() int {
+	return c.WebSocketSendBufferSize
+}
+
+// GetWebSocketBroadcastWorkers returns the most goroutines one broadcast
+// is delivered by.
+func (c *Config) GetWebSocketBroadcastWorkers() int {
+	return c.WebSocketBroadcastWorkers
+}
+
+// GetWebSocketWriteTimeout returns the deadline for each write to a WebSocket client.
+func (c *Config) GetWebSocketWriteTimeout() time.Duration {
+	return c.WebSocketWriteTimeout
+}
+
+// GetWebSocketMaxSubscriptions returns how many topics one WebSocket client
+// may be subscribed to at once; negative means unlimited.
+func (c *Config) GetWebSocketMaxSubscriptions() int {
+	return c.WebSocketMaxSubscriptions
+}
+
+// GetWebSocketCompression returns the WebSocket compression mode; empty
+// means disabled.
+func (c *Config) GetWebSocketCompression() string {
+	return c.WebSocketCompression
+}
+
+// GetWebSocketCompressionThreshold returns the smallest WebSocket message
+// compressed; zero means the library default.
+func (c *Config) GetWebSocketCompressionThreshold() int {
+	return c.WebSocketCompressMinSize
+}
+
+// GetWebSocketReadLimit returns the largest message, in bytes, a WebSocket
+// client may send.
+func (c *Config) GetWebSocketReadLimit() int64 {
+	return c.WebSocketReadLimit
+}
+
+// GetWebSocketGuestEndpoints returns the WebSocket endpoints ("html",
+// "data") that admit guest connections. It is empty unless guests are enabled.
+func (c *Config) GetWebSocketGuestEndpoints() []string {
+	var endpoints []string
+	for _, e := range strings.Split(c.WebSocketGuests, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// GetWebSocketOrderedDelivery reports whether WebSocket messages reach each
+// client in publish order.
+func (c *Config) GetWebSocketOrderedDelivery() bool {
+	return c.WebSocketOrdered
+}
+
+// GetWebSocketMaxConnections returns the server-wide cap on open WebSocket
+// and event-stream connections, or 0 for no limit.
+func (c *Config) GetWebSocketMaxConnections() int {
+	return c.WebSocketMaxConnections
+}
+
+// GetPresencePublishBufferSize returns the capacity of the presence publish queue.
+func (c *Config) GetPresencePublishBufferSize() int {
+	return c.PresencePublishBufferSize
+}
+
+// GetPresenceGuestStaleThreshold returns how long a guest stays present
+// without a heartbeat.
+func (c *Config) GetPresenceGuestStaleThreshold() time.Duration {
+	return c.GuestStaleThreshold
+}
+
+// GetPresenceMaxStaleThreshold returns the longest a user stays present
+// without a heartbeat, whatever ping cadence the client reports.
+func (c *Config) GetPresenceMaxStaleThreshold() time.Duration {
+	return c.PresenceMaxStaleThreshold
+}
+
+// GetPresenceHiddenUsers returns the user ID patterns, such as bot accounts,
+// that presence tracks but leaves out of the online list.
+func (c *Config) GetPresenceHiddenUsers() []string {
+	var patterns []string
+	for _, p := range strings.Split(c.PresenceHiddenUsers, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// GetPresenceSnapshotPath returns the file presence is snapshotted to, or
+// "" if snapshots are disabled.
+func (c *Config) GetPresenceSnapshotPath() string {
+	return c.PresenceSnapshotPath
+}
+
+// GetPresenceSnapshotInterval returns how often presence is snapshotted.
+func (c *Config) GetPresenceSnapshotInterval() time.Duration {
+	return c.PresenceSnapshotInterval
+}
+
+// GetPresenceSnapshotGrace returns how long restored presence waits for
+// clients to reconnect.
+func (c *Config) GetPresenceSnapshotGrace() time.Duration {
+	return c.PresenceSnapshotGrace
+}
+
+// GetPresenceHistoryRetention returns how long connection history is kept
+// for absent users. Zero keeps it forever.
+func (c *Config) GetPresenceHistoryRetention() time.Duration {
+	return c.PresenceHistoryRetention
+}
+
+// GetPubSubDedupWindow returns how long processed Pub/Sub message IDs are
+// remembered. Zero disables deduplication.
+func (c *Config) GetPubSubDedupWindow() time.Duration {
+	return c.PubSubDedupWindow
+}
+
+// GetPubSubBackend returns the configured pub/sub backend name; empty means
+// the in-process default.
+func (c *Config) GetPubSubBackend() string {
+	return c.PubSubBackend
+}
+
+// GetPubSubRedisURL returns the Redis URL for the redis pub/sub backend.
+func (c *Config) GetPubSubRedisURL() string {
+	return c.PubSubRedisURL
+}
+
+// GetScriptsDir returns the external scripts directory. Relative paths are
+// resolved against the working directory when the script engine is created.
+func (c *Config) GetScriptsDir() string {
+	return c.ScriptsDir
+}
+
 // GetModuleConfig retrieves the configuration for a specific module.
 // Returns the config and a boolean indicating if it was found.
 func (c *Config) GetModuleConfig(moduleName string) (interface{}, bool) {
@@ -273,3 +823,63 @@ func (c *Config) GetModuleConfig(moduleName string) (interface{}, bool) {
 	cfg, exists := c.moduleConfigs[moduleName]
 	return cfg, exists
 }
+
+// Sub returns a copy of the configuration whose generic getters read keys
+// under prefix. Prefixes nest, so Sub("CHAT_").Sub("DM_") reads CHAT_DM_*.
+// Typed getters such as GetDBURL are unaffected.
+func (c *Config) Sub(prefix string) Provider {
+	sub := *c
+	sub.prefix = c.prefix + prefix
+	return &sub
+}
+
+// lookup reads key relative to the configured prefix.
+func (c *Config) lookup(key string) (string, bool) {
+	value, ok := os.LookupEnv(c.prefix + key)
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// GetString returns the value of key, or fallback if it is unset.
+func (c *Config) GetString(key, fallback string) string {
+	if value, ok := c.lookup(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// GetInt returns key parsed as an int, or fallback if it is unset or invalid.
+func (c *Config) GetInt(key string, fallback int) int {
+	if value, ok := c.lookup(key); ok {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+		log.Printf("Invalid integer for %s%s: %q, using default %d", c.prefix, key, value, fallback)
+	}
+	return fallback
+}
+
+// GetBool returns key parsed as a bool, or fallback if it is unset or invalid.
+func (c *Config) GetBool(key string, fallback bool) bool {
+	if value, ok := c.lookup(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("Invalid boolean for %s%s: %q, using default %t", c.prefix, key, value, fallback)
+	}
+	return fallback
+}
+
+// GetDuration returns key parsed with time.ParseDuration, or fallback if it
+// is unset or invalid.
+func (c *Config) GetDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := c.lookup(key); ok {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		log.Printf("Invalid duration for %s%s: %q, using default %s", c.prefix, key, value, fallback)
+	}
+	return fallback
+}
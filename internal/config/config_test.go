@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Sub(t *testing.T) {
+	t.Setenv("CHAT_MAX_MESSAGE_LENGTH", "250")
+	t.Setenv("CHAT_DM_ENABLED", "true")
+	t.Setenv("CHAT_IDLE_TIMEOUT", "bogus")
+	t.Setenv("MAX_MESSAGE_LENGTH", "9")
+
+	root := &Config{DBURL: "ws://db"}
+	chat := root.Sub("CHAT_")
+
+	assert.Equal(t, 250, chat.GetInt("MAX_MESSAGE_LENGTH", 1000))
+	assert.Equal(t, 9, root.GetInt("MAX_MESSAGE_LENGTH", 1000), "the root view is unprefixed")
+	assert.True(t, chat.Sub("DM_").GetBool("ENABLED", false), "prefixes nest")
+	assert.Equal(t, time.Minute, chat.GetDuration("IDLE_TIMEOUT", time.Minute), "invalid values fall back")
+	assert.Equal(t, "fallback", chat.GetString("MISSING", "fallback"))
+	assert.Equal(t, "ws://db", chat.GetDBURL(), "typed getters are unaffected")
+}
@@ -7,10 +7,15 @@ import (
 )
 
 type client[T any] struct {
-	conn           DBConnection
+	conn DBConnection
+	// executor runs writes and primary reads; readExecutor runs the reads
+	// that may go to a replica. They are the same unless conn is a Router.
 	executor       QueryExecutor[T]
+	readExecutor   QueryExecutor[T]
 	queryTimeout   time.Duration
 	executeTimeout time.Duration
+	// streamBatchSize is the page size of QueryStream.
+	streamBatchSize int
 }
 
 // NewClient creates a new type-safe database client
@@ -35,6 +40,11 @@ func NewClient[T any](conn DBConnection, opts ...ClientOption[T]) (Client[T], er
 		queryTimeout:   conn.GetDBQueryTimeout(),
 		executeTimeout: conn.GetDBExecuteTimeout(),
 	}
+	c.readExecutor = c.executor
+	if router, ok := conn.(Router); ok {
+		c.executor = NewSurrealExecutor[T](router.Primary())
+		c.readExecutor = NewSurrealExecutor[T](readConnection{DBConnection: router.Primary(), router: router})
+	}
 
 	// Apply options
 	for _, opt := range opts {
@@ -46,6 +56,13 @@ func NewClient[T any](conn DBConnection, opts ...ClientOption[T]) (Client[T], er
 
 // Query implements the Client interface
 func (c *client[T]) Query(ctx context.Context, query string, params map[string]any) ([]T, error) {
+	ctx, cancel := getTimeoutFromContext(ctx, c.queryTimeout, ContextKeyQueryTimeout)
+	defer cancel()
+	return c.executorFor(query).Query(ctx, query, params)
+}
+
+// QueryPrimary implements the Client interface
+func (c *client[T]) QueryPrimary(ctx context.Context, query string, params map[string]any) ([]T, error) {
 	ctx, cancel := getTimeoutFromContext(ctx, c.queryTimeout, ContextKeyQueryTimeout)
 	defer cancel()
 	return c.executor.Query(ctx, query, params)
@@ -55,7 +72,16 @@ func (c *client[T]) Query(ctx context.Context, query string, params map[string]a
 func (c *client[T]) QueryOne(ctx context.Context, query string, params map[string]any) (*T, error) {
 	ctx, cancel := getTimeoutFromContext(ctx, c.queryTimeout, ContextKeyQueryTimeout)
 	defer cancel()
-	return c.executor.QueryOne(ctx, query, params)
+	return c.executorFor(query).QueryOne(ctx, query, params)
+}
+
+// executorFor returns the read executor for queries a replica can serve and
+// the primary executor for everything else.
+func (c *client[T]) executorFor(query string) QueryExecutor[T] {
+	if isReadQuery(query) {
+		return c.readExecutor
+	}
+	return c.executor
 }
 
 // Execute implements the Client interface
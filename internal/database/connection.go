@@ -9,9 +9,11 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/retry"
 	"github.com/surrealdb/surrealdb.go"
 	"github.com/surrealdb/surrealdb.go/contrib/rews"
 	"github.com/surrealdb/surrealdb.go/pkg/connection"
@@ -40,7 +42,7 @@ func (c *Connection) initializeREWSConnection(dbURL string) (interface{}, error)
 	)
 
 	// Configure retry behavior
-	rewsConn.Retryer = c.retryer
+	rewsConn.Retryer = c.policy
 
 	// Connect with automatic retry
 	if err := rewsConn.Connect(context.Background()); err != nil {
@@ -51,35 +53,63 @@ func (c *Connection) initializeREWSConnection(dbURL string) (interface{}, error)
 }
 
 // Connection manages a SurrealDB connection with REWS (Reliable WebSocket) support
-type Connection struct { // Implements DBConnection
+type Connection struct { // Implements DBConnection and Router
 	cfg      config.Provider
+	url      string
 	conn     *surrealdb.DB
 	rewsConn interface{} // REWS connection for reliable WebSocket management
-	retryer  rews.Retryer
+	policy   retry.Policy
 	mu       sync.RWMutex
 	healthy  bool
 	done     chan struct{}
+	schemas  schemaCache // recent Tables and TableInfo results
+
+	// namespaces holds the connections pinned to namespaces selected with
+	// WithNamespace.
+	namespaces pinnedConns
+
+	// replicas serve reads routed by Read, in round-robin order.
+	replicas    []*Connection
+	nextReplica atomic.Uint64
 }
 
-// NewConnection creates a new managed database connection
+// retry.Policy doubles as the REWS reconnect strategy.
+var _ rews.Retryer = retry.Policy{}
+
+// NewConnection creates a new managed database connection to the primary at
+// SURREAL_URL, with a read replica for each of SURREAL_REPLICA_URLS.
 func NewConnection(cfg config.Provider) *Connection {
-	retryer := rews.NewExponentialBackoffRetryer()
-	retryer.InitialDelay = 100 * time.Millisecond
-	retryer.MaxDelay = 30 * time.Second
-	retryer.Multiplier = 2.0
-	retryer.MaxRetries = 5
-	retryer.Jitter = true
-	retryer.JitterFactor = 0.25
+	c := newConnection(cfg, cfg.GetDBURL())
+	for _, url := range cfg.GetDBReplicaURLs() {
+		c.replicas = append(c.replicas, newConnection(cfg, url))
+	}
+	return c
+}
 
+func newConnection(cfg config.Provider, url string) *Connection {
 	return &Connection{
-		cfg:     cfg,
-		retryer: retryer,
-		done:    make(chan struct{}),
+		cfg:    cfg,
+		url:    url,
+		policy: retry.Default(),
+		done:   make(chan struct{}),
 	}
 }
 
-// Connect establishes the initial database connection
+// Connect establishes the initial database connection. Replicas that fail
+// to connect are skipped by Read until monitoring reconnects them.
 func (c *Connection) Connect(ctx context.Context) error {
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+	for _, replica := range c.replicas {
+		if err := replica.connect(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to connect to read replica, reads will skip it", "event", "db_replica_connect_failure", "version", "1.0", "error", err, "db_url", redactDBURL(replica.url))
+		}
+	}
+	return nil
+}
+
+func (c *Connection) connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -90,8 +120,31 @@ func (c *Connection) Connect(ctx context.Context) error {
 	return c.reconnect(ctx)
 }
 
-// WithConnection executes a function with a database connection, handling reconnections
+// Primary implements Router.
+func (c *Connection) Primary() DBConnection {
+	return c
+}
+
+// Read implements Router. It returns the next healthy replica in
+// round-robin order, or the primary if there is none.
+func (c *Connection) Read() DBConnection {
+	n := len(c.replicas)
+	start := c.nextReplica.Add(1)
+	for i := range n {
+		if replica := c.replicas[(start+uint64(i))%uint64(n)]; replica.IsHealthy() {
+			return replica
+		}
+	}
+	return c
+}
+
+// WithConnection executes a function with a database connection, handling reconnections.
+// When ctx comes from WithNamespace, fn gets a connection pinned to that namespace.
 func (c *Connection) WithConnection(ctx context.Context, fn func(*surrealdb.DB) error) error {
+	if key, ok := c.scopedNamespace(ctx); ok {
+		return c.withPinned(ctx, key, fn)
+	}
+
 	// Get the current connection
 	conn := c.getConnection()
 	if conn == nil {
@@ -125,7 +178,7 @@ func (c *Connection) WithConnection(ctx context.Context, fn func(*surrealdb.DB)
 		})
 	} else {
 		// Standard connection: attempt manual reconnect with exponential backoff
-		slog.WarnContext(ctx, "Database operation failed, attempting to reconnect with backoff", "event", "db_reconnect_triggered", "version", "1.0", "error", err, "db_url", redactDBURL(c.cfg.GetDBURL()))
+		slog.WarnContext(ctx, "Database operation failed, attempting to reconnect with backoff", "event", "db_reconnect_triggered", "version", "1.0", "error", err, "db_url", redactDBURL(c.url))
 		return c.retryWithBackoff(ctx, func() error {
 			if reconnectErr := c.forceReconnect(ctx); reconnectErr != nil {
 				return fmt.Errorf("reconnection failed: %w (original error: %v)", reconnectErr, err)
@@ -135,21 +188,34 @@ func (c *Connection) WithConnection(ctx context.Context, fn func(*surrealdb.DB)
 	}
 }
 
-// StartMonitoring begins health checks and automatic reconnection
+// StartMonitoring begins health checks and automatic reconnection of the
+// primary and every replica.
 func (c *Connection) StartMonitoring() {
 	go c.monitorConnection()
+	for _, replica := range c.replicas {
+		go replica.monitorConnection()
+	}
 }
 
-// Close shuts down the connection and monitoring
+// Close shuts down the connection, its replicas and monitoring
 func (c *Connection) Close(ctx context.Context) error {
+	var errs []error
+	for _, replica := range c.replicas {
+		errs = append(errs, replica.close(ctx))
+	}
+	return errors.Join(append([]error{c.close(ctx)}, errs...)...)
+}
+
+func (c *Connection) close(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	close(c.done)
+	err := c.closeNamespaces(ctx)
 	if c.conn != nil {
-		return c.conn.Close(ctx)
+		return errors.Join(c.conn.Close(ctx), err)
 	}
-	return nil
+	return err
 }
 
 // DB returns the underlying database connection if it's healthy.
@@ -182,10 +248,10 @@ func (c *Connection) reconnect(ctx context.Context) error {
 		c.conn.Close(ctx)
 	}
 
-	slog.DebugContext(ctx, "Attempting to connect to database", "event", "db_connect_attempt", "version", "1.0", "db_url", redactDBURL(c.cfg.GetDBURL()))
+	slog.DebugContext(ctx, "Attempting to connect to database", "event", "db_connect_attempt", "version", "1.0", "db_url", redactDBURL(c.url))
 
 	// Check if URL indicates WebSocket connection for REWS support
-	dbURL := c.cfg.GetDBURL()
+	dbURL := c.url
 	isWebSocket := strings.HasPrefix(dbURL, "ws://") || strings.HasPrefix(dbURL, "wss://")
 
 	if isWebSocket {
@@ -284,44 +350,11 @@ func (c *Connection) forceReconnect(ctx context.Context) error {
 	return c.reconnect(ctx)
 }
 
-// retryWithBackoff implements retry logic using the REWS Retryer interface
+// retryWithBackoff retries fn using the connection's retry policy.
 func (c *Connection) retryWithBackoff(ctx context.Context, fn func() error) error {
-	var lastErr error
-	attempt := 0
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		err := fn()
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-
-		delay, shouldContinue := c.retryer.NextDelay(attempt, err)
-		if !shouldContinue {
-			break
-		}
-
-		slog.DebugContext(ctx, "Retry attempt failed, waiting before next attempt",
-			"event", "retry_attempt", "version", "1.0",
-			"attempt", attempt+1, "delay_ms", delay.Milliseconds(), "error", err)
-
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
-		}
-
-		attempt++
-	}
-
-	return fmt.Errorf("operation failed after %d attempts: %w", attempt+1, lastErr)
+	return retry.Do(ctx, c.policy, func(context.Context) error {
+		return fn()
+	})
 }
 
 func (c *Connection) monitorConnection() {
@@ -333,11 +366,11 @@ func (c *Connection) monitorConnection() {
 		case <-ticker.C:
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			if err := c.checkHealth(ctx); err != nil {
-				slog.WarnContext(ctx, "Database health check failed, attempting reconnection with backoff", "event", "db_health_check_failure", "version", "1.0", "error", err, "db_url", redactDBURL(c.cfg.GetDBURL()))
+				slog.WarnContext(ctx, "Database health check failed, attempting reconnection with backoff", "event", "db_health_check_failure", "version", "1.0", "error", err, "db_url", redactDBURL(c.url))
 				if reconnectErr := c.retryWithBackoff(ctx, func() error {
 					return c.forceReconnect(ctx)
 				}); reconnectErr != nil {
-					slog.ErrorContext(ctx, "Failed to reconnect to database after health check failure", "event", "db_reconnect_failure", "version", "1.0", "error", reconnectErr, "db_url", redactDBURL(c.cfg.GetDBURL()))
+					slog.ErrorContext(ctx, "Failed to reconnect to database after health check failure", "event", "db_reconnect_failure", "version", "1.0", "error", reconnectErr, "db_url", redactDBURL(c.url))
 				}
 			}
 			cancel()
@@ -364,19 +397,19 @@ func (c *Connection) checkHealth(ctx context.Context) error {
 		// REWS handles connection health internally, but we still check the DB client
 		if _, err := conn.Version(ctx); err != nil {
 			c.healthy = false
-			return fmt.Errorf("REWS database health check failed for %s: %w", redactDBURL(c.cfg.GetDBURL()), err)
+			return fmt.Errorf("REWS database health check failed for %s: %w", redactDBURL(c.url), err)
 		}
 	} else {
 		// Standard connection health check
 		if _, err := conn.Version(ctx); err != nil {
 			c.healthy = false
-			return fmt.Errorf("database health check failed for %s: %w", redactDBURL(c.cfg.GetDBURL()), err)
+			return fmt.Errorf("database health check failed for %s: %w", redactDBURL(c.url), err)
 		}
 	}
 
 	// Sample successful health checks to reduce log volume in production.
 	if rand.Float32() < 0.1 { // Log only 10% of successful health checks.
-		slog.DebugContext(ctx, "Database health check successful", "event", "db_health_check_success", "version", "1.0", "db_url", redactDBURL(c.cfg.GetDBURL()))
+		slog.DebugContext(ctx, "Database health check successful", "event", "db_health_check_success", "version", "1.0", "db_url", redactDBURL(c.url))
 	}
 	c.healthy = true
 	return nil
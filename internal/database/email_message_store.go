@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nfrund/goby/internal/domain"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+const emailMessageTable = "email_message"
+
+// var _ ensures that EmailMessageStore implements the domain.EmailMessageRepository interface at compile time.
+var _ domain.EmailMessageRepository = (*EmailMessageStore)(nil)
+
+// EmailMessageStore persists sent email records and their delivery status.
+type EmailMessageStore struct {
+	client Client[domain.EmailMessage]
+}
+
+// NewEmailMessageStore creates a new EmailMessageStore with the given database client.
+func NewEmailMessageStore(client Client[domain.EmailMessage]) *EmailMessageStore {
+	return &EmailMessageStore{client: client}
+}
+
+// Create inserts a new email record into the database.
+func (s *EmailMessageStore) Create(ctx context.Context, msg *domain.EmailMessage) (*domain.EmailMessage, error) {
+	if msg == nil {
+		return nil, errors.New("email message to create cannot be nil")
+	}
+
+	now := &surrealmodels.CustomDateTime{Time: time.Now().UTC()}
+	data := map[string]any{
+		"recipient":           msg.Recipient,
+		"subject":             msg.Subject,
+		"template":            msg.Template,
+		"status":              msg.Status,
+		"status_reason":       msg.StatusReason,
+		"provider_message_id": msg.ProviderMessageID,
+		"created_at":          now,
+		"updated_at":          now,
+	}
+
+	created, err := s.client.Create(ctx, emailMessageTable, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email message: %w", err)
+	}
+	return created, nil
+}
+
+// FindByID retrieves an email record by its ID.
+func (s *EmailMessageStore) FindByID(ctx context.Context, id string) (*domain.EmailMessage, error) {
+	msg, err := s.client.Select(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	return msg, err
+}
+
+// FindByProviderMessageID retrieves an email record by the provider's message ID.
+func (s *EmailMessageStore) FindByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.EmailMessage, error) {
+	if providerMessageID == "" {
+		return nil, NewDBError(ErrInvalidInput, "provider message ID is required")
+	}
+
+	query := "SELECT * FROM email_message WHERE provider_message_id = $id"
+	msg, err := s.client.QueryOne(ctx, query, map[string]any{"id": providerMessageID})
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, domain.ErrNotFound
+	}
+	return msg, nil
+}
+
+// UpdateStatus sets the delivery status of an email record.
+func (s *EmailMessageStore) UpdateStatus(ctx context.Context, id string, status domain.EmailStatus, reason string) (*domain.EmailMessage, error) {
+	data := map[string]any{
+		"status":        status,
+		"status_reason": reason,
+		"updated_at":    &surrealmodels.CustomDateTime{Time: time.Now().UTC()},
+	}
+	return s.client.Update(ctx, id, data)
+}
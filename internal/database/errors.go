@@ -27,6 +27,9 @@ var (
 
 	// ErrNotConnected is returned when a database operation is attempted before a connection is established.
 	ErrNotConnected = errors.New("database not connected")
+
+	// ErrPermissionDenied is returned when the database user may not run a statement.
+	ErrPermissionDenied = errors.New("permission denied")
 )
 
 // DBError represents a database error with additional context.
@@ -92,7 +95,7 @@ func (e *DBError) Is(target error) bool {
 	}
 
 	switch target {
-	case ErrNotFound, ErrInvalidID, ErrInvalidInput, ErrAlreadyExists, ErrQueryFailed, ErrMultipleResults:
+	case ErrNotFound, ErrInvalidID, ErrInvalidInput, ErrAlreadyExists, ErrQueryFailed, ErrMultipleResults, ErrPermissionDenied:
 		return errors.Is(e.err, target)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nfrund/goby/internal/domain"
@@ -46,6 +47,9 @@ func (s *FileStore) Create(ctx context.Context, file *domain.File) (*domain.File
 		"mime_type":    file.MIMEType,
 		"size":         file.Size,
 		"storage_path": file.StoragePath,
+		"content_hash": file.ContentHash,
+		"width":        file.Width,
+		"height":       file.Height,
 		"created_at":   file.CreatedAt,
 		"updated_at":   file.UpdatedAt,
 	}
@@ -79,6 +83,39 @@ func (s *FileStore) FindByStoragePath(ctx context.Context, storagePath string) (
 	return file, nil
 }
 
+// FindByContentHash retrieves a file owned by userID with the given content hash.
+func (s *FileStore) FindByContentHash(ctx context.Context, userID *surrealmodels.RecordID, contentHash string) (*domain.File, error) {
+	if userID == nil || contentHash == "" {
+		return nil, NewDBError(ErrInvalidInput, "user ID and content hash are required")
+	}
+
+	query := "SELECT * FROM file WHERE user_id = $user AND content_hash = $hash ORDER BY created_at ASC LIMIT 1"
+	vars := map[string]interface{}{"user": userID, "hash": contentHash}
+
+	file, err := s.client.QueryOne(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	return file, nil
+}
+
+// CountByStoragePath returns the number of file records referencing storagePath.
+func (s *FileStore) CountByStoragePath(ctx context.Context, storagePath string) (int64, error) {
+	query := "SELECT id FROM file WHERE storage_path = $path"
+	vars := map[string]interface{}{"path": storagePath}
+
+	files, err := s.client.Query(ctx, query, vars)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count references to storage path: %w", err)
+	}
+
+	return int64(len(files)), nil
+}
+
 // Update updates an existing file record.
 func (s *FileStore) Update(ctx context.Context, file *domain.File) (*domain.File, error) {
 	if file == nil || file.ID == nil || file.ID.String() == "" {
@@ -152,6 +189,16 @@ func (s *FileStore) FindLatestByUser(ctx context.Context, userID *surrealmodels.
 // - When limit <= 0, all matching files are returned (no pagination)
 // - The total count reflects all files for the user, not just the returned slice
 func (s *FileStore) FindByUser(ctx context.Context, userID *surrealmodels.RecordID, limit, offset int) ([]*domain.File, int64, error) {
+	return s.SearchByUser(ctx, userID, domain.FileFilter{}, limit, offset)
+}
+
+// SearchByUser retrieves a user's files matching filter, in the order it
+// requests, with the same pagination and total semantics as FindByUser. The
+// total counts the files matching the filter.
+func (s *FileStore) SearchByUser(ctx context.Context, userID *surrealmodels.RecordID, filter domain.FileFilter, limit, offset int) ([]*domain.File, int64, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, NewDBError(ErrInvalidInput, err.Error())
+	}
 	if userID == nil {
 		return nil, 0, NewDBError(ErrInvalidInput, "user ID is required")
 	}
@@ -167,14 +214,14 @@ func (s *FileStore) FindByUser(ctx context.Context, userID *surrealmodels.Record
 
 	// Use a single query to fetch both the paginated data and the total count.
 	// The outer SELECT handles pagination, and the inner subquery provides the total count.
+	vars := map[string]any{"userID": userID}
+	where, orderBy := fileFilterQuery(filter, vars)
 	query := `
 		SELECT
 			*,
-			(SELECT count() FROM file WHERE user_id = $userID GROUP ALL) AS total
-		FROM file WHERE user_id = $userID
-		ORDER BY created_at DESC
-	`
-	vars := map[string]any{"userID": userID}
+			(SELECT count() FROM file WHERE ` + where + ` GROUP ALL) AS total
+		FROM file WHERE ` + where + `
+		` + orderBy
 
 	// Add pagination only if limit > 0
 	if limit > 0 {
@@ -206,8 +253,8 @@ func (s *FileStore) FindByUser(ctx context.Context, userID *surrealmodels.Record
 	} else {
 		// When offset is beyond total count, we still need to get the total.
 		// Run a separate count query to get the total count.
-		countQuery := "SELECT count() FROM file WHERE user_id = $userID GROUP ALL"
-		countVars := map[string]any{"userID": userID}
+		countQuery := "SELECT count() FROM file WHERE " + where + " GROUP ALL"
+		countVars := vars
 		type countResult struct {
 			Count int64 `json:"count"`
 		}
@@ -232,3 +279,41 @@ func (s *FileStore) FindByUser(ctx context.Context, userID *surrealmodels.Record
 
 	return filePtrs, total, nil
 }
+
+// fileSortColumns maps sort names to columns, so user input never reaches the
+// query text.
+var fileSortColumns = map[domain.FileSort]string{
+	domain.FileSortCreated: "created_at",
+	domain.FileSortName:    "filename",
+	domain.FileSortSize:    "size",
+}
+
+// fileFilterQuery returns the WHERE condition and ORDER BY clause for a
+// user's file listing, adding the filter's values to vars as parameters.
+// filter must be valid.
+func fileFilterQuery(filter domain.FileFilter, vars map[string]any) (where, orderBy string) {
+	filter = filter.Normalized()
+	conds := []string{"user_id = $userID"}
+	if family, ok := strings.CutSuffix(filter.MIMEType, "/*"); ok {
+		conds = append(conds, "string::starts_with(mime_type, $mimePrefix)")
+		vars["mimePrefix"] = family + "/"
+	} else if filter.MIMEType != "" {
+		conds = append(conds, "mime_type = $mimeType")
+		vars["mimeType"] = filter.MIMEType
+	}
+	if filter.Search != "" {
+		conds = append(conds, "string::contains(string::lowercase(filename), $search)")
+		vars["search"] = strings.ToLower(filter.Search)
+	}
+
+	direction := "DESC"
+	if filter.Order == domain.SortAsc {
+		direction = "ASC"
+	}
+	orderBy = "ORDER BY " + fileSortColumns[filter.Sort] + " " + direction
+	if filter.Sort != domain.FileSortCreated {
+		// Break ties newest first so pages stay stable.
+		orderBy += ", created_at DESC"
+	}
+	return strings.Join(conds, " AND "), orderBy
+}
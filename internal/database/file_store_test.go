@@ -270,3 +270,132 @@ func TestFileStore_FindByUser(t *testing.T) {
 		assert.Equal(t, int64(3), total)
 	})
 }
+
+func TestFileFilterQuery(t *testing.T) {
+	vars := map[string]any{}
+	where, orderBy := fileFilterQuery(domain.FileFilter{}, vars)
+	assert.Equal(t, "user_id = $userID", where)
+	assert.Equal(t, "ORDER BY created_at DESC", orderBy)
+	assert.Empty(t, vars)
+
+	vars = map[string]any{}
+	where, orderBy = fileFilterQuery(domain.FileFilter{MIMEType: "Image/*", Search: "Report", Sort: domain.FileSortSize, Order: domain.SortAsc}, vars)
+	assert.Equal(t, "user_id = $userID AND string::starts_with(mime_type, $mimePrefix) AND string::contains(string::lowercase(filename), $search)", where)
+	assert.Equal(t, "ORDER BY size ASC, created_at DESC", orderBy)
+	assert.Equal(t, map[string]any{"mimePrefix": "image/", "search": "report"}, vars)
+
+	vars = map[string]any{}
+	where, orderBy = fileFilterQuery(domain.FileFilter{MIMEType: "image/png", Sort: domain.FileSortName}, vars)
+	assert.Equal(t, "user_id = $userID AND mime_type = $mimeType", where)
+	assert.Equal(t, "ORDER BY filename ASC, created_at DESC", orderBy, "names sort ascending by default")
+	assert.Equal(t, map[string]any{"mimeType": "image/png"}, vars)
+}
+
+func TestFileStore_SearchByUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	store, fileClient, userClient, cleanup := setupFileStoreTest(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	name := "File Search User"
+	testUser := TestUser{
+		User: domain.User{
+			Name:  &name,
+			Email: fmt.Sprintf("searchbyuser-%d@example.com", time.Now().UnixNano()),
+		},
+		Password: "password",
+	}
+	createdUser, err := userClient.Create(ctx, "user", &testUser)
+	require.NoError(t, err, "failed to create test user")
+	t.Cleanup(func() { _ = userClient.Delete(ctx, createdUser.ID.String()) })
+
+	now := time.Now()
+	for i, f := range []struct {
+		name, mime string
+		size       int64
+	}{
+		{"Holiday.png", "image/png", 300},
+		{"report-q1.pdf", "application/pdf", 100},
+		{"avatar.jpg", "image/jpeg", 200},
+		{"Annual Report.pdf", "application/pdf", 400},
+	} {
+		created, err := store.Create(ctx, &domain.File{
+			UserID:      createdUser.ID,
+			Filename:    f.name,
+			MIMEType:    f.mime,
+			Size:        f.size,
+			StoragePath: fmt.Sprintf("user/files/search-%d-%d", i, time.Now().UnixNano()),
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = fileClient.Delete(ctx, created.ID.String()) })
+		_, err = fileClient.Update(ctx, created.ID.String(), map[string]interface{}{
+			"created_at": now.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	filenames := func(files []*domain.File) []string {
+		names := make([]string, len(files))
+		for i, f := range files {
+			names[i] = f.Filename
+		}
+		return names
+	}
+
+	t.Run("exact MIME type", func(t *testing.T) {
+		files, total, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{MIMEType: "image/png"}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Holiday.png"}, filenames(files))
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("MIME family", func(t *testing.T) {
+		files, total, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{MIMEType: "image/*"}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"avatar.jpg", "Holiday.png"}, filenames(files))
+		assert.Equal(t, int64(2), total)
+	})
+
+	t.Run("filename search ignores case", func(t *testing.T) {
+		files, total, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{Search: "REPORT"}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Annual Report.pdf", "report-q1.pdf"}, filenames(files))
+		assert.Equal(t, int64(2), total)
+	})
+
+	t.Run("sort by name", func(t *testing.T) {
+		files, _, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{Sort: domain.FileSortName}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Annual Report.pdf", "Holiday.png", "avatar.jpg", "report-q1.pdf"}, filenames(files))
+	})
+
+	t.Run("sort by size descending", func(t *testing.T) {
+		files, _, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{Sort: domain.FileSortSize, Order: domain.SortDesc}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Annual Report.pdf", "Holiday.png", "avatar.jpg", "report-q1.pdf"}, filenames(files))
+	})
+
+	t.Run("sort by created ascending", func(t *testing.T) {
+		files, _, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{Order: domain.SortAsc}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Holiday.png", "report-q1.pdf", "avatar.jpg", "Annual Report.pdf"}, filenames(files))
+	})
+
+	t.Run("combined filter, sort and pagination", func(t *testing.T) {
+		filter := domain.FileFilter{MIMEType: "application/*", Search: "report", Sort: domain.FileSortSize, Order: domain.SortAsc}
+		files, total, err := store.SearchByUser(ctx, createdUser.ID, filter, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Annual Report.pdf"}, filenames(files))
+		assert.Equal(t, int64(2), total, "total counts every match, not just the page")
+	})
+
+	t.Run("rejects invalid filter", func(t *testing.T) {
+		_, _, err := store.SearchByUser(ctx, createdUser.ID, domain.FileFilter{Sort: "owner"}, 10, 0)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
@@ -22,6 +22,10 @@ const (
 	ActionUpdate LiveQueryAction = "UPDATE"
 	ActionDelete LiveQueryAction = "DELETE"
 	ActionClose  LiveQueryAction = "CLOSE"
+	// ActionSnapshot delivers a row that already matched the query when the
+	// subscription started. It is only sent with WithInitialSnapshot and can
+	// usually be handled like ActionCreate.
+	ActionSnapshot LiveQueryAction = "SNAPSHOT"
 )
 
 // LiveQueryHandler is called when live query data changes
@@ -34,6 +38,30 @@ type LiveQueryFilter struct {
 	Fields []string               // Specific fields to watch (optional)
 }
 
+// SubscribeOption configures a live query subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	initialSnapshot bool
+}
+
+// WithInitialSnapshot delivers the rows that currently match the query, each
+// as an ActionSnapshot notification, before any live change. The live query
+// is started first and its notifications are held until the snapshot has
+// been delivered, so no change is missed: a row changed while the snapshot
+// is read may appear in the snapshot and again as a later UPDATE or DELETE,
+// and handlers should apply changes idempotently by record ID. Subscribe
+// returns once the snapshot has been delivered. With this option the handler
+// is called sequentially, in order, rather than concurrently.
+//
+// The snapshot is read by running the query without LIVE, so it cannot be
+// combined with LIVE SELECT DIFF.
+func WithInitialSnapshot() SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.initialSnapshot = true
+	}
+}
+
 // Subscription represents an active live query subscription
 type Subscription struct {
 	ID     string
@@ -44,10 +72,10 @@ type Subscription struct {
 // LiveQueryService provides real-time data subscriptions via SurrealDB Live Queries
 type LiveQueryService interface {
 	// Subscribe to a table with optional WHERE clause
-	Subscribe(ctx context.Context, table string, filter *LiveQueryFilter, handler LiveQueryHandler) (*Subscription, error)
+	Subscribe(ctx context.Context, table string, filter *LiveQueryFilter, handler LiveQueryHandler, opts ...SubscribeOption) (*Subscription, error)
 
 	// Subscribe with custom SurrealQL query
-	SubscribeQuery(ctx context.Context, query string, params map[string]interface{}, handler LiveQueryHandler) (*Subscription, error)
+	SubscribeQuery(ctx context.Context, query string, params map[string]interface{}, handler LiveQueryHandler, opts ...SubscribeOption) (*Subscription, error)
 
 	// Unsubscribe from updates
 	Unsubscribe(subID string) error
@@ -69,6 +97,9 @@ type subscriptionState struct {
 	query       string
 	params      map[string]interface{}
 	liveQueryID string // SurrealDB live query ID
+	// ordered is set for subscriptions with an initial snapshot; it holds
+	// live notifications until the snapshot has been delivered.
+	ordered *orderedDelivery
 }
 
 // NewSurrealLiveQueryService creates a new live query service
@@ -79,7 +110,7 @@ func NewSurrealLiveQueryService(db DBConnection) *SurrealLiveQueryService {
 }
 
 // Subscribe creates a live query subscription for a table
-func (s *SurrealLiveQueryService) Subscribe(ctx context.Context, table string, filter *LiveQueryFilter, handler LiveQueryHandler) (*Subscription, error) {
+func (s *SurrealLiveQueryService) Subscribe(ctx context.Context, table string, filter *LiveQueryFilter, handler LiveQueryHandler, opts ...SubscribeOption) (*Subscription, error) {
 	if handler == nil {
 		return nil, fmt.Errorf("handler cannot be nil")
 	}
@@ -110,11 +141,11 @@ func (s *SurrealLiveQueryService) Subscribe(ctx context.Context, table string, f
 		params = make(map[string]interface{})
 	}
 
-	return s.subscribeQuery(ctx, table, query, params, handler)
+	return s.subscribeQuery(ctx, table, query, params, handler, opts)
 }
 
 // SubscribeQuery creates a live query subscription with a custom query
-func (s *SurrealLiveQueryService) SubscribeQuery(ctx context.Context, query string, params map[string]interface{}, handler LiveQueryHandler) (*Subscription, error) {
+func (s *SurrealLiveQueryService) SubscribeQuery(ctx context.Context, query string, params map[string]interface{}, handler LiveQueryHandler, opts ...SubscribeOption) (*Subscription, error) {
 	if handler == nil {
 		return nil, fmt.Errorf("handler cannot be nil")
 	}
@@ -136,10 +167,23 @@ func (s *SurrealLiveQueryService) SubscribeQuery(ctx context.Context, query stri
 		params = make(map[string]interface{})
 	}
 
-	return s.subscribeQuery(ctx, table, query, params, handler)
+	return s.subscribeQuery(ctx, table, query, params, handler, opts)
 }
 
-func (s *SurrealLiveQueryService) subscribeQuery(ctx context.Context, table, query string, params map[string]interface{}, handler LiveQueryHandler) (*Subscription, error) {
+func (s *SurrealLiveQueryService) subscribeQuery(ctx context.Context, table, query string, params map[string]interface{}, handler LiveQueryHandler, opts []SubscribeOption) (*Subscription, error) {
+	var options subscribeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var selectQuery string
+	if options.initialSnapshot {
+		var err error
+		if selectQuery, err = snapshotQuery(query); err != nil {
+			return nil, err
+		}
+	}
+
 	subID := uuid.New().String()
 
 	// Create subscription state
@@ -153,6 +197,11 @@ func (s *SurrealLiveQueryService) subscribeQuery(ctx context.Context, table, que
 		query:   query,
 		params:  params,
 	}
+	if options.initialSnapshot {
+		state.ordered = newOrderedDelivery(func(action LiveQueryAction, data interface{}) {
+			callHandler(subCtx, state, action, data)
+		})
+	}
 
 	s.subscriptions.Store(subID, state)
 
@@ -245,6 +294,22 @@ func (s *SurrealLiveQueryService) subscribeQuery(ctx context.Context, table, que
 			}
 		}()
 
+		// With an initial snapshot, read the current rows only now that the
+		// live query is running; its notifications wait in state.ordered.
+		// On failure the caller cancels subCtx, which kills the live query.
+		if state.ordered != nil {
+			rows, err := surrealdb.Query[[]interface{}](ctx, dbConn, selectQuery, params)
+			if err != nil {
+				return fmt.Errorf("failed to read initial snapshot: %w", err)
+			}
+			var snapshot []interface{}
+			if rows != nil && len(*rows) > 0 {
+				snapshot = (*rows)[0].Result
+			}
+			slog.Debug("Delivering live query snapshot", "subID", subID, "rows", len(snapshot))
+			state.ordered.snapshot(snapshot)
+		}
+
 		return nil
 	})
 
@@ -312,18 +377,86 @@ func (s *SurrealLiveQueryService) listenForNotifications(ctx context.Context, st
 
 			slog.Debug("Live query notification received", "subID", state.id, "action", action)
 
+			if state.ordered != nil {
+				// Preserve snapshot-then-live order by delivering in sequence.
+				state.ordered.live(action, notification.Result)
+				continue
+			}
+
 			// Execute handler in a goroutine to avoid blocking the notification listener
-			go func() {
-				defer func() {
-					if r := recover(); r != nil {
-						slog.Error("Panic in live query handler", "subID", state.id, "panic", r)
-					}
-				}()
-
-				state.handler(ctx, action, notification.Result)
-			}()
+			go callHandler(ctx, state, action, notification.Result)
+		}
+	}
+}
+
+// callHandler runs the subscription's handler, recovering from panics.
+func callHandler(ctx context.Context, state *subscriptionState, action LiveQueryAction, data interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Panic in live query handler", "subID", state.id, "panic", r)
 		}
+	}()
+
+	state.handler(ctx, action, data)
+}
+
+// snapshotQuery returns the plain SELECT for a LIVE SELECT query.
+func snapshotQuery(liveQuery string) (string, error) {
+	query := strings.TrimSpace(liveQuery)
+	if !strings.HasPrefix(strings.ToUpper(query), "LIVE ") {
+		return "", fmt.Errorf("query must start with 'LIVE SELECT', got: %s", liveQuery)
+	}
+	query = strings.TrimSpace(query[len("LIVE "):])
+	if fields := strings.Fields(query); len(fields) > 1 && strings.EqualFold(fields[1], "DIFF") {
+		return "", fmt.Errorf("an initial snapshot cannot be used with LIVE SELECT DIFF")
+	}
+	return query, nil
+}
+
+// orderedDelivery holds live notifications until the initial snapshot has
+// been delivered and then passes them on in arrival order.
+type orderedDelivery struct {
+	mu      sync.Mutex
+	ready   bool
+	pending []liveNotification
+	deliver func(action LiveQueryAction, data interface{})
+}
+
+type liveNotification struct {
+	action LiveQueryAction
+	data   interface{}
+}
+
+func newOrderedDelivery(deliver func(action LiveQueryAction, data interface{})) *orderedDelivery {
+	return &orderedDelivery{deliver: deliver}
+}
+
+// live delivers a live notification, or queues it if the snapshot has not
+// been delivered yet.
+func (d *orderedDelivery) live(action LiveQueryAction, data interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.ready {
+		d.pending = append(d.pending, liveNotification{action: action, data: data})
+		return
+	}
+	d.deliver(action, data)
+}
+
+// snapshot delivers rows as ActionSnapshot notifications followed by the
+// live notifications queued meanwhile. The lock is held throughout, so a
+// notification arriving during the flush waits its turn.
+func (d *orderedDelivery) snapshot(rows []interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, row := range rows {
+		d.deliver(ActionSnapshot, row)
+	}
+	for _, n := range d.pending {
+		d.deliver(n.action, n.data)
 	}
+	d.pending = nil
+	d.ready = true
 }
 
 // buildFieldList creates a field list for SELECT queries
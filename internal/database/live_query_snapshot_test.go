@@ -0,0 +1,84 @@
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedDelivery_SnapshotThenLive(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got []liveNotification
+	)
+	d := newOrderedDelivery(func(action LiveQueryAction, data interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, liveNotification{action: action, data: data})
+	})
+
+	// A row changes after the live query starts but before the snapshot is read.
+	d.live(ActionUpdate, "user:1 v2")
+	d.live(ActionCreate, "user:3")
+
+	// The snapshot already reflects the update.
+	d.snapshot([]interface{}{"user:1 v2", "user:2"})
+
+	// Later notifications pass straight through.
+	d.live(ActionDelete, "user:2")
+
+	assert.Equal(t, []liveNotification{
+		{ActionSnapshot, "user:1 v2"},
+		{ActionSnapshot, "user:2"},
+		{ActionUpdate, "user:1 v2"},
+		{ActionCreate, "user:3"},
+		{ActionDelete, "user:2"},
+	}, got)
+}
+
+func TestOrderedDelivery_LiveWaitsForFlush(t *testing.T) {
+	release := make(chan struct{})
+	var got []LiveQueryAction
+	d := newOrderedDelivery(func(action LiveQueryAction, data interface{}) {
+		if action == ActionSnapshot {
+			<-release // hold the snapshot delivery open
+		}
+		got = append(got, action)
+	})
+
+	snapshotDone := make(chan struct{})
+	go func() {
+		d.snapshot([]interface{}{"user:1"})
+		close(snapshotDone)
+	}()
+
+	liveDone := make(chan struct{})
+	go func() {
+		// Wait until the snapshot holds the lock, then deliver a live change.
+		time.Sleep(20 * time.Millisecond)
+		d.live(ActionUpdate, "user:1")
+		close(liveDone)
+	}()
+
+	time.Sleep(40 * time.Millisecond)
+	close(release)
+	<-snapshotDone
+	<-liveDone
+
+	assert.Equal(t, []LiveQueryAction{ActionSnapshot, ActionUpdate}, got)
+}
+
+func TestSnapshotQuery(t *testing.T) {
+	q, err := snapshotQuery("  live select * FROM user WHERE active = true")
+	require.NoError(t, err)
+	assert.Equal(t, "select * FROM user WHERE active = true", q)
+
+	_, err = snapshotQuery("LIVE SELECT DIFF FROM user")
+	assert.Error(t, err)
+
+	_, err = snapshotQuery("SELECT * FROM user")
+	assert.Error(t, err)
+}
@@ -577,3 +577,43 @@ func (suite *LiveQueryTestSuite) TestHandlerPanic() {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func (suite *LiveQueryTestSuite) TestSubscribeWithInitialSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	type GenericRecord struct {
+		ID   *surrealmodels.RecordID `json:"id,omitempty"`
+		Name string                  `json:"name"`
+	}
+	client, err := NewClient[GenericRecord](suite.conn)
+	suite.Require().NoError(err)
+
+	existing, err := client.Create(ctx, "test_table_snapshot", GenericRecord{Name: "existing"})
+	suite.Require().NoError(err)
+	defer client.Delete(ctx, existing.ID.String())
+
+	actions := make(chan LiveQueryAction, 10)
+	handler := func(ctx context.Context, action LiveQueryAction, data interface{}) {
+		actions <- action
+	}
+
+	sub, err := suite.service.Subscribe(ctx, "test_table_snapshot", nil, handler, WithInitialSnapshot())
+	suite.Require().NoError(err)
+	defer suite.service.Unsubscribe(sub.ID)
+
+	// The snapshot is delivered before Subscribe returns.
+	suite.Require().Len(actions, 1)
+	suite.Equal(ActionSnapshot, <-actions)
+
+	created, err := client.Create(ctx, "test_table_snapshot", GenericRecord{Name: "new"})
+	suite.Require().NoError(err)
+	defer client.Delete(ctx, created.ID.String())
+
+	select {
+	case action := <-actions:
+		suite.Equal(ActionCreate, action)
+	case <-time.After(5 * time.Second):
+		suite.Fail("Timeout waiting for CREATE notification")
+	}
+}
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// namespace identifies a SurrealDB namespace and database.
+type namespace struct {
+	ns, db string
+}
+
+func (n namespace) String() string {
+	return n.ns + "/" + n.db
+}
+
+// namespaceContextKey is the context key WithNamespace stores a namespace under.
+type namespaceContextKey struct{}
+
+const (
+	// maxPinnedConns is how many namespace connections a Connection keeps
+	// open before it closes the least recently used idle one.
+	maxPinnedConns = 64
+	// pinnedIdleTimeout is how long a namespace connection may go unused
+	// before it is closed.
+	pinnedIdleTimeout = 10 * time.Minute
+)
+
+// pinnedConn is a connection that stays in one namespace for its lifetime.
+// ready is closed once db or err is set. users and lastUsed are guarded by
+// pinnedConns.mu.
+type pinnedConn struct {
+	ready    chan struct{}
+	db       *surrealdb.DB
+	err      error
+	users    int
+	lastUsed time.Time
+}
+
+// pinnedConns holds a Connection's connections to namespaces other than the
+// configured one, opened on first use. Opening one closes the connections
+// nobody is using that have been idle for pinnedIdleTimeout, and the least
+// recently used ones when max are already open; max defaults to
+// maxPinnedConns.
+type pinnedConns struct {
+	mu     sync.Mutex
+	conns  map[namespace]*pinnedConn
+	max    int
+	closed bool
+}
+
+// WithNamespace returns a context whose database operations run in namespace
+// ns and database db instead of SURREAL_NS and SURREAL_DB, e.g. for a
+// tenant's requests:
+//
+//	ctx, err := conn.WithNamespace(ctx, tenant.Namespace, tenant.Database)
+//	users, err := userClient.Query(ctx, "SELECT * FROM user", nil)
+//
+// A USE statement changes the namespace of the whole session, so it is never
+// issued on the shared connection. Operations in another namespace instead
+// run on a connection pinned to it, opened on first use and closed when it
+// sits idle while other namespaces need connections, so one tenant's
+// namespace can't leak into another's queries. Clients made
+// with NewClient pick the namespace up from the context; replicas pin their
+// own connections when a read is routed to them.
+//
+// WithNamespace opens the primary's pinned connection right away, so a
+// namespace that can't be used is reported here rather than on the first
+// query.
+func (c *Connection) WithNamespace(ctx context.Context, ns, db string) (context.Context, error) {
+	if ns == "" || db == "" {
+		return ctx, NewDBError(ErrInvalidInput, "namespace and database cannot be empty")
+	}
+	key := namespace{ns: ns, db: db}
+	if !c.isConfiguredNamespace(key) {
+		p, err := c.pinned(ctx, key)
+		if err != nil {
+			return ctx, err
+		}
+		c.releasePinned(p)
+	}
+	return context.WithValue(ctx, namespaceContextKey{}, key), nil
+}
+
+// NamespaceFromContext returns the namespace and database set on ctx by
+// WithNamespace. ok is false for contexts that use the configured ones.
+func NamespaceFromContext(ctx context.Context) (ns, db string, ok bool) {
+	key, ok := ctx.Value(namespaceContextKey{}).(namespace)
+	return key.ns, key.db, ok
+}
+
+// scopedNamespace returns the namespace ctx's operations run in when it is
+// not the configured one.
+func (c *Connection) scopedNamespace(ctx context.Context) (namespace, bool) {
+	key, ok := ctx.Value(namespaceContextKey{}).(namespace)
+	if !ok || c.isConfiguredNamespace(key) {
+		return namespace{}, false
+	}
+	return key, true
+}
+
+func (c *Connection) isConfiguredNamespace(key namespace) bool {
+	return key.ns == c.cfg.GetDBNs() && key.db == c.cfg.GetDBDb()
+}
+
+// withPinned runs fn on the connection pinned to key, reopening it with
+// backoff when fn fails with a connection error.
+func (c *Connection) withPinned(ctx context.Context, key namespace, fn func(*surrealdb.DB) error) error {
+	p, err := c.pinned(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() { c.releasePinned(p) }()
+	err = fn(p.db)
+	if err == nil || !isConnectionError(err) {
+		return err
+	}
+
+	slog.WarnContext(ctx, "Namespace connection failed, reopening with backoff", "event", "db_namespace_reconnect_triggered", "version", "1.0",
+		"namespace", key.ns, "database", key.db, "error", err, "db_url", redactDBURL(c.url))
+	return c.retryWithBackoff(ctx, func() error {
+		if p != nil {
+			c.dropPinned(key, p)
+			c.releasePinned(p)
+			p = nil
+		}
+		next, err := c.pinned(ctx, key)
+		if err != nil {
+			return err
+		}
+		p = next
+		return fn(p.db)
+	})
+}
+
+// pinned returns the connection pinned to key, opening it if there is none.
+// Concurrent callers for the same namespace share one dial; callers for other
+// namespaces are not held up by it. The connection is not closed until the
+// caller hands it back with releasePinned.
+func (c *Connection) pinned(ctx context.Context, key namespace) (*pinnedConn, error) {
+	c.namespaces.mu.Lock()
+	if c.namespaces.closed {
+		c.namespaces.mu.Unlock()
+		return nil, NewDBError(ErrNotConnected, "database connection closed")
+	}
+	if c.namespaces.conns == nil {
+		c.namespaces.conns = make(map[namespace]*pinnedConn)
+	}
+	p, ok := c.namespaces.conns[key]
+	if !ok {
+		c.namespaces.evictLocked(time.Now())
+		p = &pinnedConn{ready: make(chan struct{})}
+		c.namespaces.conns[key] = p
+	}
+	p.users++
+	c.namespaces.mu.Unlock()
+
+	if !ok {
+		p.db, p.err = c.dialNamespace(ctx, key)
+		if p.err != nil {
+			c.namespaces.mu.Lock()
+			if c.namespaces.conns[key] == p {
+				delete(c.namespaces.conns, key)
+			}
+			c.namespaces.mu.Unlock()
+		}
+		close(p.ready)
+	}
+
+	select {
+	case <-p.ready:
+		if p.err != nil {
+			c.releasePinned(p)
+			return nil, p.err
+		}
+		return p, nil
+	case <-ctx.Done():
+		c.releasePinned(p)
+		return nil, ctx.Err()
+	}
+}
+
+// releasePinned hands back a connection returned by pinned.
+func (c *Connection) releasePinned(p *pinnedConn) {
+	if p == nil {
+		return
+	}
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+	p.users--
+	p.lastUsed = time.Now()
+}
+
+// dropPinned forgets the connection pinned to key if it is still p, so the
+// next operation opens a new one.
+func (c *Connection) dropPinned(key namespace, p *pinnedConn) {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+	if c.namespaces.conns[key] == p {
+		delete(c.namespaces.conns, key)
+		go p.db.Close(context.Background())
+	}
+}
+
+// evictLocked closes the connections nobody is using that have been idle
+// longer than pinnedIdleTimeout, then the least recently used ones until
+// there is room for one more. Connections in use are never closed, so the
+// limit can be exceeded while they all are. The caller holds n.mu.
+func (n *pinnedConns) evictLocked(now time.Time) {
+	limit := n.max
+	if limit <= 0 {
+		limit = maxPinnedConns
+	}
+	for key, p := range n.conns {
+		if p.users == 0 && now.Sub(p.lastUsed) > pinnedIdleTimeout {
+			n.evict(key, p)
+		}
+	}
+	for len(n.conns) >= limit {
+		var (
+			oldestKey namespace
+			oldest    *pinnedConn
+		)
+		for key, p := range n.conns {
+			if p.users == 0 && (oldest == nil || p.lastUsed.Before(oldest.lastUsed)) {
+				oldestKey, oldest = key, p
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		n.evict(oldestKey, oldest)
+	}
+}
+
+// evict forgets and closes p. The caller holds n.mu.
+func (n *pinnedConns) evict(key namespace, p *pinnedConn) {
+	delete(n.conns, key)
+	if p.db != nil {
+		go p.db.Close(context.Background())
+	}
+}
+
+// dialNamespace opens a connection to the server, selects key's namespace and
+// database and signs in with the configured credentials.
+func (c *Connection) dialNamespace(ctx context.Context, key namespace) (*surrealdb.DB, error) {
+	db, err := surrealdb.FromEndpointURLString(ctx, c.url)
+	if err != nil {
+		return nil, NewDBError(ErrNotConnected, fmt.Sprintf("failed to connect for namespace %s: %v", key, err))
+	}
+	if err := db.Use(ctx, key.ns, key.db); err != nil {
+		db.Close(ctx)
+		return nil, fmt.Errorf("failed to use namespace %s: %w", key, err)
+	}
+	if _, err := db.SignIn(ctx, &surrealdb.Auth{Username: c.cfg.GetDBUser(), Password: c.cfg.GetDBPass()}); err != nil {
+		db.Close(ctx)
+		return nil, fmt.Errorf("failed to sign in for namespace %s: %w", key, err)
+	}
+	slog.DebugContext(ctx, "Namespace connection established", "event", "db_namespace_connect_success", "version", "1.0",
+		"namespace", key.ns, "database", key.db, "db_url", redactDBURL(c.url))
+	return db, nil
+}
+
+// closeNamespaces closes every pinned connection; later operations in another
+// namespace fail with ErrNotConnected.
+func (c *Connection) closeNamespaces(ctx context.Context) error {
+	c.namespaces.mu.Lock()
+	conns := c.namespaces.conns
+	c.namespaces.conns = nil
+	c.namespaces.closed = true
+	c.namespaces.mu.Unlock()
+
+	var errs []error
+	for _, p := range conns {
+		<-p.ready
+		if p.db != nil {
+			errs = append(errs, p.db.Close(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namespaceConfig is a config.Provider with only a namespace and database.
+type namespaceConfig struct {
+	config.Provider
+	ns, db string
+}
+
+func (c namespaceConfig) GetDBNs() string { return c.ns }
+func (c namespaceConfig) GetDBDb() string { return c.db }
+
+func TestConnection_WithNamespaceValidates(t *testing.T) {
+	conn := newConnection(namespaceConfig{ns: "app", db: "main"}, "ws://localhost:8000")
+
+	_, err := conn.WithNamespace(context.Background(), "", "main")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = conn.WithNamespace(context.Background(), "tenant", "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	ctx, err := conn.WithNamespace(context.Background(), "app", "main")
+	require.NoError(t, err, "the configured namespace needs no connection of its own")
+	ns, db, ok := NamespaceFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "app", ns)
+	assert.Equal(t, "main", db)
+	_, scoped := conn.scopedNamespace(ctx)
+	assert.False(t, scoped, "operations stay on the shared connection")
+
+	_, _, ok = NamespaceFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestConnection_WithNamespaceAfterClose(t *testing.T) {
+	conn := newConnection(namespaceConfig{ns: "app", db: "main"}, "ws://localhost:8000")
+	require.NoError(t, conn.Close(context.Background()))
+
+	_, err := conn.WithNamespace(context.Background(), "tenant", "main")
+	assert.ErrorIs(t, err, ErrNotConnected)
+}
+
+func TestConnection_WithNamespaceConcurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	conn, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	client, err := NewClient[map[string]any](conn)
+	require.NoError(t, err)
+
+	suffix := time.Now().UnixNano()
+	tenants := []string{fmt.Sprintf("tenant_a_%d", suffix), fmt.Sprintf("tenant_b_%d", suffix)}
+	defer func() {
+		for _, tenant := range tenants {
+			_ = client.Execute(context.Background(), "REMOVE NAMESPACE IF EXISTS "+tenant, nil)
+		}
+	}()
+
+	const writes = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, len(tenants)*writes*2)
+	for _, tenant := range tenants {
+		tenantCtx, err := conn.WithNamespace(ctx, tenant, "app")
+		require.NoError(t, err)
+		for i := range writes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.Create(tenantCtx, "item", map[string]any{"tenant": tenant, "n": i}); err != nil {
+					errs <- err
+					return
+				}
+				// Reads interleave with the other tenant's writes.
+				items, err := client.Query(tenantCtx, "SELECT * FROM item", nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, item := range items {
+					if item["tenant"] != tenant {
+						errs <- fmt.Errorf("%s read %v from another namespace", tenant, item["tenant"])
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for _, tenant := range tenants {
+		tenantCtx, err := conn.WithNamespace(ctx, tenant, "app")
+		require.NoError(t, err)
+		items, err := client.Query(tenantCtx, "SELECT * FROM item", nil)
+		require.NoError(t, err)
+		assert.Len(t, items, writes, tenant)
+	}
+
+	items, err := client.Query(ctx, "SELECT * FROM item WHERE tenant IN $tenants", map[string]any{"tenants": tenants})
+	require.NoError(t, err)
+	assert.Empty(t, items, "the shared connection stays in the configured namespace")
+}
+
+func TestPinnedConns_EvictsIdleConnections(t *testing.T) {
+	now := time.Now()
+	ready := func(users int, lastUsed time.Time) *pinnedConn {
+		p := &pinnedConn{ready: make(chan struct{}), users: users, lastUsed: lastUsed}
+		close(p.ready)
+		return p
+	}
+	n := pinnedConns{max: 3, conns: map[namespace]*pinnedConn{
+		{ns: "stale", db: "app"}:  ready(0, now.Add(-pinnedIdleTimeout-time.Second)),
+		{ns: "old", db: "app"}:    ready(0, now.Add(-time.Minute)),
+		{ns: "recent", db: "app"}: ready(0, now),
+	}}
+
+	n.evictLocked(now)
+	assert.Len(t, n.conns, 2, "only the connection idle past the timeout goes while there is room")
+	assert.NotContains(t, n.conns, namespace{ns: "stale", db: "app"})
+
+	n.conns[namespace{ns: "busy", db: "app"}] = ready(1, now.Add(-time.Hour))
+	n.evictLocked(now)
+	assert.Len(t, n.conns, 2, "the least recently used idle connection makes room")
+	assert.NotContains(t, n.conns, namespace{ns: "old", db: "app"})
+	assert.Contains(t, n.conns, namespace{ns: "busy", db: "app"}, "connections in use are kept")
+
+	n.conns[namespace{ns: "recent", db: "app"}].users = 1
+	n.conns[namespace{ns: "other", db: "app"}] = ready(1, now)
+	n.evictLocked(now)
+	assert.Len(t, n.conns, 3, "the limit is exceeded rather than closing a connection in use")
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// Router chooses the connection each database operation runs on. Clients
+// created on a DBConnection that is also a Router send single-statement
+// SELECT queries to Read and everything else to Primary. *Connection is a
+// Router; configure replicas with SURREAL_REPLICA_URLS.
+type Router interface {
+	// Primary returns the connection for writes, transactions and reads
+	// that must see the latest writes.
+	Primary() DBConnection
+	// Read returns the connection for the next read. It may be a replica.
+	Read() DBConnection
+}
+
+// readConnection is the DBConnection a client's read executor uses. It asks
+// the router for a connection on every operation, so reads are spread over
+// the replicas instead of pinned to the one chosen when the client was made.
+type readConnection struct {
+	DBConnection // the primary, for the methods that do not run queries
+	router       Router
+}
+
+func (r readConnection) DB() (*surrealdb.DB, error) {
+	return r.router.Read().DB()
+}
+
+func (r readConnection) WithConnection(ctx context.Context, fn func(*surrealdb.DB) error) error {
+	return r.router.Read().WithConnection(ctx, fn)
+}
+
+// writeKeywords are the SurrealQL statements that change data or schema, or
+// otherwise must run on the primary. They may appear inside a SELECT, e.g.
+// SELECT * FROM (CREATE user), so a query containing any of them is not a read.
+var writeKeywords = map[string]struct{}{
+	"CREATE": {}, "UPDATE": {}, "UPSERT": {}, "DELETE": {}, "INSERT": {},
+	"RELATE": {}, "DEFINE": {}, "REMOVE": {}, "ALTER": {}, "REBUILD": {},
+	"LIVE": {}, "KILL": {}, "BEGIN": {}, "COMMIT": {}, "CANCEL": {},
+}
+
+// isReadQuery reports whether query can be served by a replica: a single
+// SELECT statement. Transactions, writes and multi-statement queries are not.
+//
+// The check is conservative. String literals and comments are skipped, but
+// any write keyword elsewhere, even one used as a field name, sends the
+// query to the primary. Custom functions are not inspected, so run a SELECT
+// calling one that writes with Client.QueryPrimary.
+func isReadQuery(query string) bool {
+	q := strings.TrimSpace(query)
+	if len(q) < len("SELECT") || !strings.EqualFold(q[:len("SELECT")], "SELECT") {
+		return false
+	}
+
+	trailing := false // only a final ";" and whitespace may follow a ";"
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		if trailing && !isSpace(c) && c != ';' {
+			return false
+		}
+		switch {
+		case c == '\'' || c == '"':
+			i = skipString(q, i)
+		case c == '-' && strings.HasPrefix(q[i:], "--"), c == '/' && strings.HasPrefix(q[i:], "//"), c == '#':
+			i = skipUntil(q, i, "\n")
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipUntil(q, i+2, "*/")
+		case c == ';':
+			trailing = true
+		case isWordByte(c):
+			start := i
+			for i+1 < len(q) && isWordByte(q[i+1]) {
+				i++
+			}
+			// $params and record IDs (table:id) are not keywords.
+			if start > 0 && (q[start-1] == '$' || q[start-1] == ':') {
+				continue
+			}
+			if _, ok := writeKeywords[strings.ToUpper(q[start:i+1])]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// skipString returns the index of the quote closing the string literal that
+// opens at q[start], or the last index if it is unterminated.
+func skipString(q string, start int) int {
+	quote := q[start]
+	for i := start + 1; i < len(q); i++ {
+		switch q[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(q) - 1
+}
+
+// skipUntil returns the index of the last byte of the first end at or after
+// from, or the last index of q if there is none.
+func skipUntil(q string, from int, end string) int {
+	if n := strings.Index(q[from:], end); n >= 0 {
+		return from + n + len(end) - 1
+	}
+	return len(q) - 1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// countingConn is a DBConnection that counts the operations run on it
+// without touching a database.
+type countingConn struct {
+	DBConnection
+	calls atomic.Int32
+}
+
+func (c *countingConn) WithConnection(ctx context.Context, fn func(*surrealdb.DB) error) error {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *countingConn) GetDBQueryTimeout() time.Duration   { return time.Second }
+func (c *countingConn) GetDBExecuteTimeout() time.Duration { return time.Second }
+
+// mockRouter sends reads to its replicas in turn.
+type mockRouter struct {
+	*countingConn
+	replicas []*countingConn
+	next     int
+}
+
+func (r *mockRouter) Primary() DBConnection { return r.countingConn }
+
+func (r *mockRouter) Read() DBConnection {
+	replica := r.replicas[r.next%len(r.replicas)]
+	r.next++
+	return replica
+}
+
+func TestClient_RoutesReadsToReplicas(t *testing.T) {
+	router := &mockRouter{
+		countingConn: &countingConn{},
+		replicas:     []*countingConn{{}, {}},
+	}
+	client, err := NewClient[map[string]any](router)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Query(ctx, "SELECT * FROM user", nil)
+	require.NoError(t, err)
+	_, err = client.QueryOne(ctx, "select * from user WHERE email = $email", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), router.replicas[0].calls.Load(), "reads are spread round-robin")
+	assert.Equal(t, int32(1), router.replicas[1].calls.Load())
+	assert.Zero(t, router.calls.Load(), "reads do not hit the primary")
+
+	_, _ = client.Create(ctx, "user", map[string]any{"name": "a"})
+	_, _ = client.Update(ctx, "user:1", map[string]any{"name": "b"})
+	_ = client.Delete(ctx, "user:1")
+	_, err = client.Query(ctx, "BEGIN TRANSACTION; SELECT * FROM user; COMMIT TRANSACTION;", nil)
+	require.NoError(t, err)
+	_, err = client.Query(ctx, "SELECT * FROM user; DELETE user:2", nil)
+	require.NoError(t, err)
+	_, err = client.QueryPrimary(ctx, "SELECT * FROM user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), router.calls.Load(), "writes, transactions and QueryPrimary hit the primary")
+	assert.Equal(t, int32(2), router.replicas[0].calls.Load()+router.replicas[1].calls.Load())
+}
+
+func TestConnection_ReadSkipsUnhealthyReplicas(t *testing.T) {
+	primary := newConnection(nil, "ws://primary")
+	healthy := newConnection(nil, "ws://replica-1")
+	healthy.healthy = true
+	unhealthy := newConnection(nil, "ws://replica-2")
+	primary.replicas = []*Connection{healthy, unhealthy}
+
+	for range 4 {
+		assert.Same(t, healthy, primary.Read())
+	}
+
+	healthy.healthy = false
+	assert.Same(t, primary, primary.Read(), "falls back to the primary with no healthy replica")
+	assert.Same(t, primary, primary.Primary())
+}
+
+func TestIsReadQuery(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT * FROM user":                     true,
+		"  select * from user;\n":                true,
+		"SELECT * FROM user; DELETE user:1":      false,
+		"CREATE user CONTENT $data":              false,
+		"BEGIN TRANSACTION; SELECT * FROM user;": false,
+		"LIVE SELECT * FROM user":                false,
+		"SEL":                                    false,
+		// Writes can hide in subqueries.
+		"SELECT * FROM (CREATE user SET name = 'x')":      false,
+		"SELECT *, (UPDATE counter SET n += 1) FROM user": false,
+		"select * from (delete session:old)":              false,
+		// Keywords and semicolons inside literals and comments don't count.
+		"SELECT * FROM user WHERE bio = 'a; b'":              true,
+		`SELECT * FROM user WHERE note = "DELETE me; \" ok"`: true,
+		"SELECT * FROM user -- CREATE later; maybe\n":        true,
+		"SELECT * FROM user /* ; UPDATE */ WHERE active":     true,
+		"SELECT * FROM user WHERE status = $delete":          true,
+		"SELECT * FROM user:create":                          true,
+		"SELECT * FROM user WHERE name = 'x'; CREATE user":   false,
+		// Conservative: a keyword used as a field name goes to the primary.
+		"SELECT update FROM audit": false,
+	} {
+		assert.Equal(t, want, isReadQuery(query), query)
+	}
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// schemaCacheTTL is how long table listings and schemas are reused before
+// SurrealDB is asked again.
+const schemaCacheTTL = 30 * time.Second
+
+var (
+	// tableNamePattern matches table names that are safe to interpolate into
+	// an INFO statement, which does not accept parameters.
+	tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// fieldTypePattern extracts the type from a DEFINE FIELD statement.
+	fieldTypePattern = regexp.MustCompile(`(?i)\bTYPE\s+(.+?)(?:\s+(?:DEFAULT|VALUE|ASSERT|READONLY|PERMISSIONS|COMMENT|REFERENCE)\b|;?\s*$)`)
+)
+
+// TableSchema describes a table and the fields defined on it. Schemaless
+// tables may have no defined fields.
+type TableSchema struct {
+	Name       string        `json:"name"`
+	Schemafull bool          `json:"schemafull"`
+	Fields     []FieldSchema `json:"fields"`
+}
+
+// FieldSchema is a single defined field. Type is SurrealDB's type expression
+// (e.g. "string", "option<datetime>"), or empty when the field is untyped.
+type FieldSchema struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Tables returns the names of the tables defined in the current database,
+// sorted. Results are cached briefly.
+func (c *Connection) Tables(ctx context.Context) ([]string, error) {
+	names, err := cached(&c.schemas, c.schemaCacheKey(ctx, ""), func() ([]string, error) {
+		info, err := c.info(ctx, "INFO FOR DB")
+		if err != nil {
+			return nil, err
+		}
+		return parseTableNames(info), nil
+	})
+	return slices.Clone(names), err
+}
+
+// TableInfo returns the schema of table. It returns ErrNotFound if the table
+// is not defined and ErrPermissionDenied if the database user may not inspect
+// it. Results are cached briefly.
+func (c *Connection) TableInfo(ctx context.Context, table string) (TableSchema, error) {
+	if !tableNamePattern.MatchString(table) {
+		return TableSchema{}, NewDBError(ErrInvalidInput, fmt.Sprintf("invalid table name %q", table))
+	}
+	schema, err := cached(&c.schemas, c.schemaCacheKey(ctx, "table:"+table), func() (TableSchema, error) {
+		// INFO FOR DB carries the DEFINE TABLE statement, which tells us
+		// whether the table exists and whether it is schemafull.
+		dbInfo, err := c.info(ctx, "INFO FOR DB")
+		if err != nil {
+			return TableSchema{}, err
+		}
+		define, ok := stringMap(dbInfo["tables"])[table]
+		if !ok {
+			return TableSchema{}, NewDBError(ErrNotFound, fmt.Sprintf("table %q is not defined", table))
+		}
+
+		tableInfo, err := c.info(ctx, "INFO FOR TABLE "+table)
+		if err != nil {
+			return TableSchema{}, err
+		}
+		return parseTableSchema(table, define, tableInfo), nil
+	})
+	schema.Fields = slices.Clone(schema.Fields)
+	return schema, err
+}
+
+// schemaCacheKey returns the cache key for key in ctx's namespace, so schemas
+// of different namespaces are cached apart.
+func (c *Connection) schemaCacheKey(ctx context.Context, key string) string {
+	if ns, ok := c.scopedNamespace(ctx); ok {
+		return ns.String() + "|" + key
+	}
+	return key
+}
+
+// info runs an INFO statement and returns its result object.
+func (c *Connection) info(ctx context.Context, statement string) (map[string]any, error) {
+	var info map[string]any
+	err := c.WithConnection(ctx, func(db *surrealdb.DB) error {
+		results, err := surrealdb.Query[map[string]any](ctx, db, statement, nil)
+		if err != nil {
+			if isPermissionError(err) {
+				return NewDBError(ErrPermissionDenied, "schema query not permitted").WithQuery(statement)
+			}
+			return NewDBError(err, "schema query failed").WithQuery(statement)
+		}
+		if results != nil && len(*results) > 0 {
+			info = (*results)[0].Result
+		}
+		return nil
+	})
+	return info, err
+}
+
+// isPermissionError reports whether err is SurrealDB refusing the statement
+// to the authenticated user.
+func isPermissionError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "not enough permissions") ||
+		strings.Contains(msg, "iam error") ||
+		strings.Contains(msg, "not allowed")
+}
+
+// parseTableNames returns the sorted table names from an INFO FOR DB result.
+func parseTableNames(info map[string]any) []string {
+	tables := stringMap(info["tables"])
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseTableSchema builds a TableSchema from the table's DEFINE TABLE
+// statement and its INFO FOR TABLE result.
+func parseTableSchema(table, define string, info map[string]any) TableSchema {
+	schema := TableSchema{
+		Name:       table,
+		Schemafull: strings.Contains(strings.ToUpper(define), "SCHEMAFULL"),
+		Fields:     []FieldSchema{},
+	}
+	for name, stmt := range stringMap(info["fields"]) {
+		field := FieldSchema{Name: name}
+		if m := fieldTypePattern.FindStringSubmatch(stmt); m != nil {
+			field.Type = strings.TrimSpace(m[1])
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	sort.Slice(schema.Fields, func(i, j int) bool { return schema.Fields[i].Name < schema.Fields[j].Name })
+	return schema
+}
+
+// stringMap converts an INFO result section, a map of names to DEFINE
+// statements, into a map of strings, skipping anything else.
+func stringMap(v any) map[string]string {
+	out := make(map[string]string)
+	m, ok := v.(map[string]any)
+	if !ok {
+		return out
+	}
+	for k, v := range m {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// schemaCache holds recently fetched schema information.
+type schemaCache struct {
+	mu      sync.Mutex
+	entries map[string]schemaCacheEntry
+	now     func() time.Time
+}
+
+type schemaCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// cached returns the value stored under key, calling load and caching its
+// result when there is none or it has expired. Errors are not cached.
+func cached[T any](c *schemaCache, key string, load func() (T, error)) (T, error) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.value.(T), nil
+	}
+	c.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]schemaCacheEntry)
+	}
+	c.entries[key] = schemaCacheEntry{value: value, expires: now().Add(schemaCacheTTL)}
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTableSchema(t *testing.T) {
+	dbInfo := map[string]any{
+		"tables": map[string]any{
+			"user": "DEFINE TABLE user TYPE NORMAL SCHEMAFULL PERMISSIONS NONE",
+			"file": "DEFINE TABLE file TYPE ANY SCHEMALESS PERMISSIONS NONE",
+		},
+	}
+	assert.Equal(t, []string{"file", "user"}, parseTableNames(dbInfo))
+
+	tableInfo := map[string]any{
+		"fields": map[string]any{
+			"email":      "DEFINE FIELD email ON user TYPE string ASSERT string::is::email($value) PERMISSIONS FULL",
+			"created_at": "DEFINE FIELD created_at ON user TYPE option<datetime> DEFAULT time::now() PERMISSIONS FULL",
+			"tags":       "DEFINE FIELD tags ON user FLEXIBLE TYPE array<string>",
+			"notes":      "DEFINE FIELD notes ON user PERMISSIONS FULL",
+		},
+		"indexes": map[string]any{},
+	}
+	schema := parseTableSchema("user", "DEFINE TABLE user TYPE NORMAL SCHEMAFULL PERMISSIONS NONE", tableInfo)
+	assert.Equal(t, TableSchema{
+		Name:       "user",
+		Schemafull: true,
+		Fields: []FieldSchema{
+			{Name: "created_at", Type: "option<datetime>"},
+			{Name: "email", Type: "string"},
+			{Name: "notes"},
+			{Name: "tags", Type: "array<string>"},
+		},
+	}, schema)
+
+	schemaless := parseTableSchema("file", "DEFINE TABLE file TYPE ANY SCHEMALESS", map[string]any{})
+	assert.False(t, schemaless.Schemafull)
+	assert.Empty(t, schemaless.Fields)
+}
+
+func TestSchemaCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := &schemaCache{now: func() time.Time { return now }}
+	loads := 0
+	load := func() ([]string, error) {
+		loads++
+		return []string{"user"}, nil
+	}
+
+	for range 3 {
+		tables, err := cached(cache, "", load)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user"}, tables)
+	}
+	assert.Equal(t, 1, loads, "results are reused within the TTL")
+
+	now = now.Add(schemaCacheTTL)
+	_, err := cached(cache, "", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads, "expired results are reloaded")
+
+	denied := NewDBError(ErrPermissionDenied, "schema query not permitted")
+	_, err = cached(cache, "table:secret", func() (TableSchema, error) { return TableSchema{}, denied })
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = cached(cache, "table:secret", func() (TableSchema, error) { return TableSchema{Name: "secret"}, nil })
+	assert.NoError(t, err, "errors are not cached")
+}
+
+func TestIsPermissionError(t *testing.T) {
+	assert.True(t, isPermissionError(errors.New("IAM error: Not enough permissions to perform this action")))
+	assert.False(t, isPermissionError(errors.New("connection refused")))
+}
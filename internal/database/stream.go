@@ -0,0 +1,246 @@
+package database
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// defaultStreamBatchSize is how many rows QueryStream fetches per page unless
+// configured with WithStreamBatchSize.
+const defaultStreamBatchSize = 500
+
+var (
+	// startClause matches a START clause, which QueryStream sets itself.
+	startClause = regexp.MustCompile(`(?i)\bSTART\s+(AT\s+)?[$\d]`)
+	// trailingClause matches the clauses SurrealQL only allows after LIMIT
+	// and START, so QueryStream can't append its own.
+	trailingClause = regexp.MustCompile(`(?i)\b(FETCH\s+[a-z_*]|TIMEOUT\s+[$\d]|PARALLEL\s*(;|$|\bTIMEOUT\b|\bEXPLAIN\b)|EXPLAIN(\s+FULL)?\s*;?\s*$)`)
+)
+
+// WithStreamBatchSize sets how many rows QueryStream fetches per page.
+// Non-positive sizes are ignored.
+func WithStreamBatchSize[T any](size int) ClientOption[T] {
+	return func(c *client[T]) {
+		if size > 0 {
+			c.streamBatchSize = size
+		}
+	}
+}
+
+// QueryStream implements the Client interface.
+//
+// The SurrealDB client has no result streaming, so the query is run in pages
+// of the stream batch size and only one page is held in memory at a time.
+// Each page gets the query timeout of its own. Pages are read from the
+// primary, so they don't come from replicas that lag by different amounts.
+//
+// A query without ORDER BY, GROUP BY or SPLIT is paged by record ID: it is
+// ordered by id, and each page after the first only selects the rows whose
+// id is past the last one seen, so no page rescans the rows before it. When
+// the rows carry no id, or the query has its own order, pages are read with
+// LIMIT and START instead.
+func (c *client[T]) QueryStream(ctx context.Context, query string, params map[string]any, fn func(T) error) error {
+	if !isReadQuery(query) {
+		return NewDBError(ErrInvalidInput, "QueryStream needs a single SELECT statement")
+	}
+	if hasLimitClause(query) || startClause.MatchString(query) {
+		return NewDBError(ErrInvalidInput, "QueryStream pages the query itself; remove LIMIT and START")
+	}
+	if trailingClause.MatchString(query) {
+		return NewDBError(ErrInvalidInput, "QueryStream can't page a query with FETCH, TIMEOUT, PARALLEL or EXPLAIN")
+	}
+	if fn == nil {
+		return NewDBError(ErrInvalidInput, "callback cannot be nil")
+	}
+
+	batch := c.streamBatchSize
+	if batch <= 0 {
+		batch = defaultStreamBatchSize
+	}
+	pages := newStreamPages(query)
+	pageParams := make(map[string]any, len(params)+3)
+	maps.Copy(pageParams, params)
+	pageParams["stream_limit"] = batch
+
+	paged := pages.first
+	for start := 0; ; start += batch {
+		pageParams["stream_start"] = start
+		rows, err := c.queryPage(ctx, paged, pageParams)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < batch {
+			return nil
+		}
+
+		paged = pages.offset
+		if pages.keyset != "" {
+			if last, ok := rowID(rows[len(rows)-1]); ok {
+				paged = pages.keyset
+				pageParams["stream_after"] = last
+			} else {
+				// Without ids the rest can only be paged by offset, in the
+				// id order the first page was read in.
+				pages.keyset = ""
+			}
+		}
+	}
+}
+
+// streamPages are the paged forms of a QueryStream query: the first page,
+// the pages after the row ID $stream_after and the pages at offset
+// $stream_start. keyset is empty when the query can't be paged by ID.
+type streamPages struct {
+	first, keyset, offset string
+}
+
+func newStreamPages(query string) streamPages {
+	q := strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	const limit = " LIMIT $stream_limit"
+	const start = " START $stream_start"
+
+	clauses := topLevelClauses(q)
+	_, ordered := clauses["ORDER"]
+	_, grouped := clauses["GROUP"]
+	_, split := clauses["SPLIT"]
+	from, ok := clauses["FROM"]
+	// SurrealDB only orders by fields the query selects.
+	if ordered || grouped || split || !ok || !selectsID(q[len("SELECT"):from]) {
+		return streamPages{first: q + limit + start, offset: q + limit + start}
+	}
+
+	keyset := q + " WHERE id > $stream_after"
+	if where, ok := clauses["WHERE"]; ok {
+		keyset = q[:where] + "WHERE (" + strings.TrimSpace(q[where+len("WHERE"):]) + ") AND id > $stream_after"
+	}
+	const byID = " ORDER BY id"
+	return streamPages{
+		first:  q + byID + limit,
+		keyset: keyset + byID + limit,
+		offset: q + byID + limit + start,
+	}
+}
+
+// topLevelClauses returns the offsets of the FROM, WHERE, ORDER BY, GROUP BY
+// and SPLIT clauses of a single SELECT statement, skipping string literals,
+// comments and anything nested in brackets, such as subqueries.
+func topLevelClauses(q string) map[string]int {
+	clauses := make(map[string]int)
+	depth := 0
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipString(q, i)
+		case c == '-' && strings.HasPrefix(q[i:], "--"), c == '/' && strings.HasPrefix(q[i:], "//"), c == '#':
+			i = skipUntil(q, i, "\n")
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipUntil(q, i+2, "*/")
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case isWordByte(c):
+			start := i
+			for i+1 < len(q) && isWordByte(q[i+1]) {
+				i++
+			}
+			if depth > 0 || start > 0 && (q[start-1] == '$' || q[start-1] == ':') {
+				continue
+			}
+			word := strings.ToUpper(q[start : i+1])
+			switch word {
+			case "FROM", "WHERE", "SPLIT":
+			case "ORDER", "GROUP":
+				// Only ORDER BY and GROUP BY/ALL; a field may be called order.
+				next := strings.ToUpper(strings.TrimLeft(q[i+1:], " \t\r\n"))
+				if !strings.HasPrefix(next, "BY") && !strings.HasPrefix(next, "ALL") {
+					continue
+				}
+			default:
+				continue
+			}
+			if _, seen := clauses[word]; !seen {
+				clauses[word] = start
+			}
+		}
+	}
+	return clauses
+}
+
+// selectsID reports whether the projection of a SELECT selects the id field,
+// as * or by name.
+func selectsID(projection string) bool {
+	fields := strings.Fields(strings.ToUpper(projection))
+	if len(fields) > 0 && fields[0] == "VALUE" {
+		return false
+	}
+	for _, field := range strings.Split(projection, ",") {
+		if field = strings.TrimSpace(field); field == "*" || strings.EqualFold(field, "id") {
+			return true
+		}
+	}
+	return false
+}
+
+// rowID returns the record ID of a result row: the "id" entry of a map or
+// the field of a struct tagged or named id. ok is false when there is none.
+func rowID(row any) (id any, ok bool) {
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v = v.MapIndex(reflect.ValueOf("id").Convert(v.Type().Key()))
+	case reflect.Struct:
+		v = structID(v)
+	default:
+		return nil, false
+	}
+	if !v.IsValid() || v.IsZero() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// structID returns the field of struct v tagged or named id.
+func structID(v reflect.Value) reflect.Value {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		for _, key := range []string{"json", "cbor"} {
+			if name, _, _ := strings.Cut(f.Tag.Get(key), ","); name == "id" {
+				return v.Field(i)
+			}
+		}
+	}
+	if f, ok := t.FieldByName("ID"); ok && f.IsExported() {
+		return v.FieldByIndex(f.Index)
+	}
+	return reflect.Value{}
+}
+
+// queryPage runs one page of a QueryStream under the query timeout.
+func (c *client[T]) queryPage(ctx context.Context, query string, params map[string]any) ([]T, error) {
+	ctx, cancel := getTimeoutFromContext(ctx, c.queryTimeout, ContextKeyQueryTimeout)
+	defer cancel()
+	return c.executor.Query(ctx, query, params)
+}
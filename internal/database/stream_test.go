@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagingExecutor is a QueryExecutor over an in-memory table that serves the
+// LIMIT/START pages QueryStream asks for.
+type pagingExecutor struct {
+	QueryExecutor[int]
+	rows    []int
+	queries []string
+}
+
+func (e *pagingExecutor) Query(ctx context.Context, query string, params map[string]any) ([]int, error) {
+	e.queries = append(e.queries, query)
+	start, limit := params["stream_start"].(int), params["stream_limit"].(int)
+	if start >= len(e.rows) {
+		return nil, nil
+	}
+	return e.rows[start:min(start+limit, len(e.rows))], nil
+}
+
+func TestClient_QueryStream(t *testing.T) {
+	const total = 2345
+	exec := &pagingExecutor{}
+	for i := range total {
+		exec.rows = append(exec.rows, i)
+	}
+	client, err := NewClient(&countingConn{}, WithExecutor[int](exec), WithStreamBatchSize[int](100))
+	require.NoError(t, err)
+
+	t.Run("visits every row once", func(t *testing.T) {
+		exec.queries = nil
+		seen := make(map[int]int)
+		err := client.QueryStream(context.Background(), "SELECT * FROM item ORDER BY n;", nil, func(row int) error {
+			seen[row]++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, seen, total)
+		for row, n := range seen {
+			require.Equal(t, 1, n, "row %d", row)
+		}
+		assert.Len(t, exec.queries, total/100+1)
+		assert.Equal(t, "SELECT * FROM item ORDER BY n LIMIT $stream_limit START $stream_start", exec.queries[0])
+	})
+
+	t.Run("stops at the callback's error", func(t *testing.T) {
+		exec.queries = nil
+		stop := errors.New("enough")
+		visited := 0
+		err := client.QueryStream(context.Background(), "SELECT * FROM item", nil, func(row int) error {
+			visited++
+			if visited == 150 {
+				return stop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 150, visited)
+		assert.Len(t, exec.queries, 2, "no pages are fetched after stopping")
+	})
+
+	t.Run("rejects queries it can't page", func(t *testing.T) {
+		noop := func(int) error { return nil }
+		for _, query := range []string{
+			"SELECT * FROM item LIMIT 10",
+			"SELECT * FROM item START 10",
+			"SELECT * FROM item; DELETE item",
+			"DELETE item",
+			"SELECT * FROM item FETCH owner",
+			"SELECT * FROM item TIMEOUT 5s",
+			"SELECT * FROM item PARALLEL",
+			"SELECT * FROM item EXPLAIN FULL",
+		} {
+			err := client.QueryStream(context.Background(), query, nil, noop)
+			assert.ErrorIs(t, err, ErrInvalidInput, query)
+		}
+		assert.NoError(t, client.QueryStream(context.Background(), "SELECT * FROM item WHERE start > 1", nil, noop))
+	})
+}
+
+// keysetExecutor is a QueryExecutor over an in-memory table of rows with
+// ascending ids that serves the pages after $stream_after.
+type keysetExecutor struct {
+	QueryExecutor[map[string]any]
+	rows    []map[string]any
+	queries []string
+}
+
+func (e *keysetExecutor) Query(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	e.queries = append(e.queries, query)
+	after, _ := params["stream_after"].(int)
+	if !strings.Contains(query, "$stream_after") {
+		after = -1
+	}
+	var page []map[string]any
+	for _, row := range e.rows {
+		if row["id"].(int) > after && len(page) < params["stream_limit"].(int) {
+			page = append(page, row)
+		}
+	}
+	return page, nil
+}
+
+func TestClient_QueryStreamPagesByID(t *testing.T) {
+	const total = 250
+	exec := &keysetExecutor{}
+	for i := range total {
+		exec.rows = append(exec.rows, map[string]any{"id": i})
+	}
+	client, err := NewClient(&countingConn{}, WithExecutor[map[string]any](exec), WithStreamBatchSize[map[string]any](100))
+	require.NoError(t, err)
+
+	visited := 0
+	err = client.QueryStream(context.Background(), "SELECT *, (SELECT * FROM tag WHERE x ORDER BY y) AS tags FROM item WHERE a OR b", nil, func(row map[string]any) error {
+		assert.Equal(t, visited, row["id"])
+		visited++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, total, visited)
+	assert.Equal(t, []string{
+		"SELECT *, (SELECT * FROM tag WHERE x ORDER BY y) AS tags FROM item WHERE a OR b ORDER BY id LIMIT $stream_limit",
+		"SELECT *, (SELECT * FROM tag WHERE x ORDER BY y) AS tags FROM item WHERE (a OR b) AND id > $stream_after ORDER BY id LIMIT $stream_limit",
+		"SELECT *, (SELECT * FROM tag WHERE x ORDER BY y) AS tags FROM item WHERE (a OR b) AND id > $stream_after ORDER BY id LIMIT $stream_limit",
+	}, exec.queries)
+
+	exec.queries = nil
+	stop := errors.New("enough")
+	err = client.QueryStream(context.Background(), "SELECT name FROM item", nil, func(map[string]any) error { return stop })
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, "SELECT name FROM item LIMIT $stream_limit START $stream_start", exec.queries[0],
+		"queries that don't select id are paged by offset")
+}
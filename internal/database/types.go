@@ -50,13 +50,28 @@ type Client[T any] interface {
 	// Query executes a raw query and returns multiple results.
 	// The query can include parameters using the $param syntax.
 	// Returns a slice of type T containing the query results.
+	// A single SELECT statement may be served by a read replica.
 	Query(ctx context.Context, query string, params map[string]any) ([]T, error)
 
+	// QueryPrimary is Query, but always runs on the primary. Use it to read
+	// data just written, which a replica may not have received yet.
+	QueryPrimary(ctx context.Context, query string, params map[string]any) ([]T, error)
+
 	// QueryOne executes a raw query and returns a single result.
 	// Returns (nil, nil) if no results are found.
 	// Returns an error if the query returns more than one result.
 	QueryOne(ctx context.Context, query string, params map[string]any) (*T, error)
 
+	// QueryStream runs a single SELECT statement and calls fn with each
+	// result row, without holding the whole result in memory. Use it for
+	// large results such as reports and exports. The query must not have
+	// LIMIT, START, FETCH, TIMEOUT, PARALLEL or EXPLAIN clauses, as it is
+	// read page by page. Queries selecting id without an order of their own
+	// are paged by record ID; give others an ORDER BY when rows may be
+	// written concurrently. Iteration stops at the first error fn returns,
+	// which QueryStream returns as is.
+	QueryStream(ctx context.Context, query string, params map[string]any, fn func(T) error) error
+
 	// Execute runs a query that doesn't return any rows (e.g., INSERT, UPDATE, DELETE).
 	// Use this for operations where you don't need to process the returned data.
 	Execute(ctx context.Context, query string, params map[string]any) error
@@ -88,10 +103,12 @@ type QueryExecutor[T any] interface {
 // This allows for flexible client configuration using functional options.
 type ClientOption[T any] func(*client[T])
 
-// WithExecutor configures the client to use a custom QueryExecutor.
-// This is useful for testing or for adding middleware to the executor.
+// WithExecutor configures the client to use a custom QueryExecutor for
+// both reads and writes. This is useful for testing or for adding
+// middleware to the executor.
 func WithExecutor[T any](executor QueryExecutor[T]) ClientOption[T] {
 	return func(c *client[T]) {
 		c.executor = executor
+		c.readExecutor = executor
 	}
 }
@@ -1,7 +1,88 @@
 package domain
 
+import (
+	"context"
+
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
 // EmailSender defines the interface for sending emails. This allows for
 // different implementations (e.g., for logging, Resend, Mailgun).
 type EmailSender interface {
 	Send(to, subject, htmlBody string) error
 }
+
+// ProviderEmailSender is implemented by senders that report the ID the email
+// provider assigned to a message, so later delivery webhooks can be matched
+// to it.
+type ProviderEmailSender interface {
+	SendMessage(to, subject, htmlBody string) (providerMessageID string, err error)
+}
+
+// TemplateEmailSender is implemented by senders that record which template
+// produced a message.
+type TemplateEmailSender interface {
+	SendTemplate(template, to, subject, htmlBody string) error
+}
+
+// SendTemplate sends an email through sender, passing the template name along
+// when the sender records it.
+func SendTemplate(sender EmailSender, template, to, subject, htmlBody string) error {
+	if ts, ok := sender.(TemplateEmailSender); ok {
+		return ts.SendTemplate(template, to, subject, htmlBody)
+	}
+	return sender.Send(to, subject, htmlBody)
+}
+
+// EmailStatus is the delivery state of a sent email.
+type EmailStatus string
+
+const (
+	// EmailStatusSent means the provider accepted the message.
+	EmailStatusSent EmailStatus = "sent"
+	// EmailStatusDelayed means the provider is retrying delivery.
+	EmailStatusDelayed EmailStatus = "delayed"
+	// EmailStatusDelivered means the recipient's server accepted the message.
+	EmailStatusDelivered EmailStatus = "delivered"
+	// EmailStatusBounced means the recipient's server rejected the message.
+	EmailStatusBounced EmailStatus = "bounced"
+	// EmailStatusComplained means the recipient marked the message as spam.
+	EmailStatusComplained EmailStatus = "complained"
+	// EmailStatusFailed means the message could not be sent.
+	EmailStatusFailed EmailStatus = "failed"
+)
+
+// Final reports whether no later delivery event should replace the status.
+func (s EmailStatus) Final() bool {
+	return s == EmailStatusBounced || s == EmailStatusComplained || s == EmailStatusFailed
+}
+
+// EmailMessage records a sent email and its delivery status.
+type EmailMessage struct {
+	ID                *surrealmodels.RecordID       `json:"id,omitempty" surrealdb:"id,omitempty"`
+	Recipient         string                        `json:"recipient" surrealdb:"recipient"`
+	Subject           string                        `json:"subject" surrealdb:"subject"`
+	Template          string                        `json:"template,omitempty" surrealdb:"template,omitempty"`
+	Status            EmailStatus                   `json:"status" surrealdb:"status"`
+	StatusReason      string                        `json:"status_reason,omitempty" surrealdb:"status_reason,omitempty"` // Provider's explanation, e.g. the bounce message.
+	ProviderMessageID string                        `json:"provider_message_id,omitempty" surrealdb:"provider_message_id,omitempty"`
+	CreatedAt         *surrealmodels.CustomDateTime `json:"created_at,omitempty" surrealdb:"created_at,omitempty"`
+	UpdatedAt         *surrealmodels.CustomDateTime `json:"updated_at,omitempty" surrealdb:"updated_at,omitempty"`
+}
+
+// EmailMessageRepository defines storage for sent email records.
+type EmailMessageRepository interface {
+	// Create inserts a new email record.
+	Create(ctx context.Context, msg *EmailMessage) (*EmailMessage, error)
+
+	// FindByID retrieves an email record by its ID. Returns ErrNotFound when
+	// there is none.
+	FindByID(ctx context.Context, id string) (*EmailMessage, error)
+
+	// FindByProviderMessageID retrieves the email record the provider knows by
+	// providerMessageID. Returns ErrNotFound when there is none.
+	FindByProviderMessageID(ctx context.Context, providerMessageID string) (*EmailMessage, error)
+
+	// UpdateStatus sets the delivery status of an email record.
+	UpdateStatus(ctx context.Context, id string, status EmailStatus, reason string) (*EmailMessage, error)
+}
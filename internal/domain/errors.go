@@ -3,9 +3,22 @@ package domain
 import "errors"
 
 // Sentinel errors for the domain layer. These provide consistent, checkable
-// errors for common business logic failures.
+// errors for common business logic failures. Handlers may return them, wrapped
+// or not, and the server's error handler maps them to status codes; see
+// handlers.ErrorMapper.
 var (
 	ErrUserAlreadyExists  = errors.New("user with this email already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials provided")
 	ErrNotFound           = errors.New("requested resource not found")
+	ErrUnauthorized       = errors.New("authentication required")
+	ErrForbidden          = errors.New("access denied")
+	ErrInvalidRequest     = errors.New("invalid request data")
+	// ErrVersionConflict reports an update based on a stale version of a
+	// record that someone else has changed since it was read.
+	ErrVersionConflict = errors.New("resource was modified by another request")
+	// ErrQuotaExceeded reports that the user has used up an allowance, such
+	// as their storage quota.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrTooLarge reports a request body or upload above the size limit.
+	ErrTooLarge = errors.New("request entity too large")
 )
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -46,6 +47,9 @@ type File struct {
 	MIMEType    string                        `json:"mime_type" surrealdb:"mime_type" validate:"required"`                 // MIME type of the file. Handler-level validation uses a configurable list.
 	Size        int64                         `json:"size" surrealdb:"size" validate:"gte=0"`                              // Size of the file in bytes. Must be non-negative.
 	StoragePath string                        `json:"storage_path" surrealdb:"storage_path" validate:"required,safepath"`  // The path to the file in the configured storage backend. Must be a safe, relative path.
+	ContentHash string                        `json:"content_hash,omitempty" surrealdb:"content_hash,omitempty"`           // Hex-encoded SHA-256 of the content. Records sharing a blob share the hash.
+	Width       int                           `json:"width,omitempty" surrealdb:"width,omitempty"`                         // Pixel width of an image; zero for other files and unreadable images.
+	Height      int                           `json:"height,omitempty" surrealdb:"height,omitempty"`                       // Pixel height of an image; zero for other files and unreadable images.
 	CreatedAt   *surrealmodels.CustomDateTime `json:"created_at,omitempty" surrealdb:"created_at,omitempty"`               // Timestamp of when the record was created.
 	UpdatedAt   *surrealmodels.CustomDateTime `json:"updated_at,omitempty" surrealdb:"updated_at,omitempty"`               // Timestamp of the last update.
 	DeletedAt   *surrealmodels.CustomDateTime `json:"deleted_at,omitempty" surrealdb:"deleted_at,omitempty"`
@@ -79,8 +83,89 @@ type FileRepository interface {
 	// Returns the list of files and the total count of files for the user.
 	FindByUser(ctx context.Context, userID *surrealmodels.RecordID, limit, offset int) ([]*File, int64, error)
 
+	// SearchByUser is FindByUser with filtering and sorting applied by the
+	// database. A zero FileFilter lists every file, newest first.
+	SearchByUser(ctx context.Context, userID *surrealmodels.RecordID, filter FileFilter, limit, offset int) ([]*File, int64, error)
+
 	// FindByStoragePath retrieves file metadata by its storage path.
 	FindByStoragePath(ctx context.Context, storagePath string) (*File, error)
+
+	// FindByContentHash retrieves a file owned by the given user whose content
+	// has the given SHA-256 hash. Returns ErrNotFound when there is none.
+	FindByContentHash(ctx context.Context, userID *surrealmodels.RecordID, contentHash string) (*File, error)
+
+	// CountByStoragePath returns how many file records reference the blob at
+	// storagePath. Deduplicated uploads share a blob, so it may only be removed
+	// from storage once this reaches zero.
+	CountByStoragePath(ctx context.Context, storagePath string) (int64, error)
+}
+
+// FileSort names the field a file listing is ordered by.
+type FileSort string
+
+const (
+	FileSortCreated FileSort = "created"
+	FileSortName    FileSort = "name"
+	FileSortSize    FileSort = "size"
+)
+
+// SortOrder is the direction of a listing.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// FileFilter narrows and orders a user's file listing.
+type FileFilter struct {
+	// MIMEType matches one type ("image/png") or a family ("image/*").
+	MIMEType string
+	// Search matches filenames containing it, ignoring case.
+	Search string
+	// Sort defaults to FileSortCreated.
+	Sort FileSort
+	// Order defaults to ascending for names and descending otherwise, so
+	// the default listing is newest first.
+	Order SortOrder
+}
+
+// Normalized returns f with the default sort and order filled in.
+func (f FileFilter) Normalized() FileFilter {
+	f.MIMEType = strings.ToLower(strings.TrimSpace(f.MIMEType))
+	f.Search = strings.TrimSpace(f.Search)
+	if f.Sort == "" {
+		f.Sort = FileSortCreated
+	}
+	if f.Order == "" {
+		f.Order = SortDesc
+		if f.Sort == FileSortName {
+			f.Order = SortAsc
+		}
+	}
+	return f
+}
+
+// Validate reports an unknown sort or order, or a malformed MIME type.
+func (f FileFilter) Validate() error {
+	switch f.Sort {
+	case "", FileSortCreated, FileSortName, FileSortSize:
+	default:
+		return fmt.Errorf("unknown sort %q: use created, name or size", f.Sort)
+	}
+	switch f.Order {
+	case "", SortAsc, SortDesc:
+	default:
+		return fmt.Errorf("unknown order %q: use asc or desc", f.Order)
+	}
+	if f.MIMEType != "" {
+		major, minor, ok := strings.Cut(f.MIMEType, "/")
+		if !ok || major == "" || major == "*" || minor == "" || strings.Contains(minor, "/") ||
+			(strings.Contains(minor, "*") && minor != "*") {
+			return fmt.Errorf("invalid MIME type filter %q: use type/subtype or type/*", f.MIMEType)
+		}
+	}
+	return nil
 }
 
 // Pagination constants
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GuestIDPrefix marks user IDs assigned to unauthenticated guests, e.g.
+// "guest:1b4e28ba-2fa1-11d2-883f-0016d3cca427". Registered users are
+// identified by email, so the two never collide.
+const GuestIDPrefix = "guest:"
+
+// NewGuestID returns a fresh, random guest ID.
+func NewGuestID() string {
+	return GuestIDPrefix + uuid.NewString()
+}
+
+// IsGuestID reports whether id belongs to a guest rather than a registered user.
+func IsGuestID(id string) bool {
+	return strings.HasPrefix(id, GuestIDPrefix)
+}
+
+// ValidGuestID reports whether id is a well-formed guest ID as produced by
+// NewGuestID. Use it before trusting an ID supplied by a client.
+func ValidGuestID(id string) bool {
+	rest, ok := strings.CutPrefix(id, GuestIDPrefix)
+	if !ok {
+		return false
+	}
+	_, err := uuid.Parse(rest)
+	return err == nil
+}
@@ -0,0 +1,14 @@
+package domain
+
+// NoticeLevel is the severity of a message shown to a user, whether it is
+// flashed on the next rendered page or pushed to an open page as a toast.
+// Both are styled with the matching "alert-<level>" class.
+type NoticeLevel string
+
+// The notice levels, from least to most severe.
+const (
+	NoticeInfo    NoticeLevel = "info"
+	NoticeSuccess NoticeLevel = "success"
+	NoticeWarning NoticeLevel = "warning"
+	NoticeError   NoticeLevel = "error"
+)
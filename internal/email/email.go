@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 // --- LogSender (for development) ---
@@ -17,13 +19,21 @@ type LogSender struct {
 
 // Send logs the email content to the standard output.
 func (s *LogSender) Send(to, subject, htmlBody string) error {
+	_, err := s.SendMessage(to, subject, htmlBody)
+	return err
+}
+
+// SendMessage logs the email like Send and returns a generated message ID.
+func (s *LogSender) SendMessage(to, subject, htmlBody string) (string, error) {
+	id := "log-" + uuid.NewString()
 	slog.Info("--- Email Sent (Logged) ---")
+	slog.Info("ID", "id", id)
 	slog.Info("From", "address", s.senderAddress)
 	slog.Info("To", "address", to)
 	slog.Info("Subject", "subject", subject)
 	slog.Info("Body (HTML)", "body", htmlBody)
 	slog.Info("---------------------------")
-	return nil
+	return id, nil
 }
 
 // --- ResendSender (for production) ---
@@ -41,8 +51,19 @@ type resendPayload struct {
 	HTML    string `json:"html"`
 }
 
+type resendResponse struct {
+	ID string `json:"id"`
+}
+
 // Send dispatches an email using the Resend API.
 func (s *ResendSender) Send(to, subject, htmlBody string) error {
+	_, err := s.SendMessage(to, subject, htmlBody)
+	return err
+}
+
+// SendMessage dispatches an email like Send and returns the ID Resend
+// assigned to it, which its delivery webhooks refer to.
+func (s *ResendSender) SendMessage(to, subject, htmlBody string) (string, error) {
 	sender := s.senderAddress
 	if sender == "" {
 		sender = "Goby <onboarding@resend.dev>" // Default sender for testing with Resend
@@ -57,12 +78,12 @@ func (s *ResendSender) Send(to, subject, htmlBody string) error {
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal resend payload: %w", err)
+		return "", fmt.Errorf("failed to marshal resend payload: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", "https://api.resend.com/emails", bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("failed to create resend request: %w", err)
+		return "", fmt.Errorf("failed to create resend request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
@@ -71,15 +92,21 @@ func (s *ResendSender) Send(to, subject, htmlBody string) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request to resend: %w", err)
+		return "", fmt.Errorf("failed to send request to resend: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		// In a real app, you'd parse the error body here for more details.
-		return fmt.Errorf("resend API returned an error: status %d", resp.StatusCode)
+		return "", fmt.Errorf("resend API returned an error: status %d", resp.StatusCode)
+	}
+
+	var result resendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		// The email was accepted; only delivery tracking is lost.
+		slog.Warn("Failed to decode resend response", "error", err)
 	}
 
-	slog.Info("Successfully sent email via Resend", "to", to, "subject", subject)
-	return nil
+	slog.Info("Successfully sent email via Resend", "to", to, "subject", subject, "id", result.ID)
+	return result.ID, nil
 }
@@ -0,0 +1,331 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/retry"
+)
+
+// maxFailedEmails bounds how many dead-lettered emails a FailedLog keeps.
+const maxFailedEmails = 100
+
+// dispatcherGroup names the Dispatcher's subscription, so dispatchers on
+// every instance share the queue rather than each sending every email.
+const dispatcherGroup = "email_dispatcher"
+
+// metaKeyAttempts carries the number of send attempts a requeued email has
+// already had.
+const metaKeyAttempts = "email_attempts"
+
+// OutboundEmail is the payload of a TopicOutbound message.
+type OutboundEmail struct {
+	Template string `json:"template,omitempty"`
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"htmlBody"`
+}
+
+// FailedEmail is the payload of a TopicOutboundDLQ message: the email, how
+// many times sending it was attempted, and why the last attempt failed.
+type FailedEmail struct {
+	OutboundEmail
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// DefaultRetryPolicy returns the policy a Dispatcher sends with unless
+// configured otherwise: 1s doubling up to 1m with 25% jitter, for at most
+// five attempts.
+func DefaultRetryPolicy() retry.Policy {
+	return retry.Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2.0,
+		JitterFactor: 0.25,
+		MaxAttempts:  5,
+	}
+}
+
+// --- QueueSender ---
+
+// QueueSender is an EmailSender that queues emails on TopicOutbound instead
+// of sending them, so callers don't wait on the provider or its retries. A
+// Dispatcher must be running to send them. Send only fails when the email
+// can't be queued.
+type QueueSender struct {
+	publisher pubsub.Publisher
+}
+
+// NewQueueSender returns a sender that queues emails through publisher.
+func NewQueueSender(publisher pubsub.Publisher) *QueueSender {
+	return &QueueSender{publisher: publisher}
+}
+
+// Send queues an email without a template name.
+func (q *QueueSender) Send(to, subject, htmlBody string) error {
+	return q.SendTemplate("", to, subject, htmlBody)
+}
+
+// SendTemplate queues an email under the given template name.
+func (q *QueueSender) SendTemplate(template, to, subject, htmlBody string) error {
+	payload, err := json.Marshal(OutboundEmail{Template: template, To: to, Subject: subject, HTMLBody: htmlBody})
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbound email: %w", err)
+	}
+	ctx, cancel := pubsub.WithPublishContext(context.Background(), 0)
+	defer cancel()
+	if err := q.publisher.Publish(ctx, pubsub.Message{Topic: TopicOutbound.Name(), Payload: payload}); err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// --- Dispatcher ---
+
+// Dispatcher sends the emails queued on TopicOutbound, retrying each one
+// according to its retry policy. An email that still fails is published to
+// TopicOutboundDLQ with the number of attempts and the last error, so it is
+// visible rather than lost.
+//
+// Each delivery makes one attempt. A failed email is requeued after the
+// policy's delay instead of being retried in the handler, so one failing
+// email does not hold up the rest of the queue.
+type Dispatcher struct {
+	sender    domain.EmailSender
+	publisher pubsub.Publisher
+	group     *pubsub.SubscriberGroup
+	policy    retry.Policy
+	messages  domain.EmailMessageRepository
+	now       func() time.Time
+
+	mu      sync.Mutex
+	retries map[*time.Timer]pubsub.Message // emails waiting to be requeued
+	stopped bool
+}
+
+// DispatcherOption configures optional Dispatcher behavior.
+type DispatcherOption func(*Dispatcher)
+
+// WithRetryPolicy sets the policy each email is sent with, replacing
+// DefaultRetryPolicy.
+func WithRetryPolicy(policy retry.Policy) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.policy = policy
+	}
+}
+
+// WithTracking records the outcome of each email in messages once its
+// retries are over, like TrackingSender does for direct sends.
+func WithTracking(messages domain.EmailMessageRepository) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.messages = messages
+	}
+}
+
+// NewDispatcher creates a dispatcher that sends through sender; call Start
+// to begin consuming the queue.
+func NewDispatcher(sender domain.EmailSender, pub pubsub.Publisher, sub pubsub.Subscriber, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		sender:    sender,
+		publisher: pub,
+		policy:    DefaultRetryPolicy(),
+		now:       time.Now,
+		retries:   make(map[*time.Timer]pubsub.Message),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.group = pubsub.NewSubscriberGroup(sub, dispatcherGroup)
+	d.group.Add(TopicOutbound.Name(), d.handle)
+	return d
+}
+
+// Start begins sending queued emails until ctx ends or Stop is called. The
+// subscription is named, so on a backend with consumer groups each email is
+// sent by one instance.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.group.Start(pubsub.WithSubscriberName(ctx, dispatcherGroup))
+}
+
+// Stop ends sending, waits for the email in flight, if any, and requeues
+// the emails waiting for a retry right away, so a running dispatcher, or
+// this one after a restart, picks them up.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	err := d.group.Stop(ctx)
+
+	d.mu.Lock()
+	d.stopped = true
+	pending := d.retries
+	d.retries = make(map[*time.Timer]pubsub.Message)
+	for timer := range pending {
+		timer.Stop()
+	}
+	d.mu.Unlock()
+
+	errs := []error{err}
+	for _, msg := range pending {
+		errs = append(errs, d.requeue(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// handle makes one attempt at sending a queued email. A failed email is
+// requeued for a later attempt, or dead-lettered once the policy allows no
+// more.
+func (d *Dispatcher) handle(ctx context.Context, msg pubsub.Message) error {
+	var email OutboundEmail
+	if err := json.Unmarshal(msg.Payload, &email); err != nil {
+		return pubsub.Reject(fmt.Errorf("invalid %s payload: %w", TopicOutbound.Name(), err))
+	}
+	if err := ctx.Err(); err != nil {
+		// Shutting down; leave the email to be redelivered.
+		return err
+	}
+
+	attempts, _ := strconv.Atoi(msg.Metadata[metaKeyAttempts])
+	attempts++
+	providerID, err := sendMessage(d.sender, email.To, email.Subject, email.HTMLBody)
+	if err != nil {
+		if delay, ok := d.policy.NextDelay(attempts-1, err); ok {
+			slog.Warn("Failed to send email, will retry",
+				"to", email.To, "template", email.Template, "attempts", attempts, "retry_in", delay, "error", err)
+			d.scheduleRetry(msg.Payload, attempts, delay)
+			return nil
+		}
+	}
+	if d.messages != nil {
+		recordEmail(d.messages, email.Template, email.To, email.Subject, providerID, err)
+	}
+	if err == nil {
+		return nil
+	}
+
+	slog.Error("Failed to send email, routing to dead-letter topic",
+		"to", email.To, "template", email.Template, "attempts", attempts, "error", err)
+	payload, marshalErr := json.Marshal(FailedEmail{
+		OutboundEmail: email,
+		Attempts:      attempts,
+		Reason:        err.Error(),
+		FailedAt:      d.now(),
+	})
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal failed email: %w", marshalErr)
+	}
+	pubCtx, cancel := pubsub.WithPublishContext(ctx, 0)
+	defer cancel()
+	if err := d.publisher.Publish(pubCtx, pubsub.Message{Topic: TopicOutboundDLQ.Name(), Payload: payload}); err != nil {
+		// Nack, so the email is retried rather than dropped.
+		return fmt.Errorf("failed to dead-letter email: %w", err)
+	}
+	return nil
+}
+
+// scheduleRetry requeues an email after delay, recording the attempts it
+// has had. The requeued message gets a new ID, so deduplication does not
+// mistake it for the delivery that failed.
+func (d *Dispatcher) scheduleRetry(payload []byte, attempts int, delay time.Duration) {
+	msg := pubsub.Message{
+		Topic:    TopicOutbound.Name(),
+		Payload:  payload,
+		Metadata: map[string]string{metaKeyAttempts: strconv.Itoa(attempts)},
+	}
+
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		// Stop has already requeued the others; don't hold this one back.
+		d.requeue(msg)
+		return
+	}
+	// The callback takes mu, so it can't run before timer is recorded.
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		_, pending := d.retries[timer]
+		delete(d.retries, timer)
+		d.mu.Unlock()
+		if pending {
+			d.requeue(msg)
+		}
+	})
+	d.retries[timer] = msg
+	d.mu.Unlock()
+}
+
+// requeue publishes msg back to TopicOutbound.
+func (d *Dispatcher) requeue(msg pubsub.Message) error {
+	ctx, cancel := pubsub.WithPublishContext(context.Background(), 0)
+	defer cancel()
+	if err := d.publisher.Publish(ctx, msg); err != nil {
+		slog.Error("Failed to requeue email for retry; it is lost",
+			"attempts", msg.Metadata[metaKeyAttempts], "error", err)
+		return fmt.Errorf("failed to requeue email: %w", err)
+	}
+	return nil
+}
+
+// --- FailedLog ---
+
+// FailedLog keeps the most recent emails published to TopicOutboundDLQ, for
+// inspection. It holds at most maxFailedEmails in memory; the delivery
+// status of each is also recorded when the Dispatcher tracks messages.
+type FailedLog struct {
+	group *pubsub.SubscriberGroup
+
+	mu     sync.RWMutex
+	emails []FailedEmail
+}
+
+// NewFailedLog creates a log; call Start to begin collecting.
+func NewFailedLog(sub pubsub.Subscriber) *FailedLog {
+	l := &FailedLog{}
+	l.group = pubsub.NewSubscriberGroup(sub, "email_failed_log")
+	l.group.Add(TopicOutboundDLQ.Name(), l.handle)
+	return l
+}
+
+// Start begins collecting dead-lettered emails until ctx ends or Stop is
+// called.
+func (l *FailedLog) Start(ctx context.Context) {
+	l.group.Start(ctx)
+}
+
+// Stop ends collecting.
+func (l *FailedLog) Stop(ctx context.Context) error {
+	return l.group.Stop(ctx)
+}
+
+// Failed returns the dead-lettered emails, newest first.
+func (l *FailedLog) Failed() []FailedEmail {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	failed := make([]FailedEmail, len(l.emails))
+	for i, email := range l.emails {
+		failed[len(l.emails)-1-i] = email
+	}
+	return failed
+}
+
+func (l *FailedLog) handle(ctx context.Context, msg pubsub.Message) error {
+	var failed FailedEmail
+	if err := json.Unmarshal(msg.Payload, &failed); err != nil {
+		return pubsub.Reject(fmt.Errorf("invalid %s payload: %w", TopicOutboundDLQ.Name(), err))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.emails) == maxFailedEmails {
+		l.emails = append(l.emails[:0], l.emails[1:]...)
+	}
+	l.emails = append(l.emails, failed)
+	return nil
+}
@@ -0,0 +1,206 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/retry"
+)
+
+// flakySender fails its first `failures` sends, then succeeds.
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+}
+
+func (s *flakySender) Send(to, subject, htmlBody string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return fmt.Errorf("provider unavailable (attempt %d)", s.attempts)
+	}
+	return nil
+}
+
+func (s *flakySender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// memMessages is an in-memory domain.EmailMessageRepository recording what
+// Create was given.
+type memMessages struct {
+	mu      sync.Mutex
+	created []domain.EmailMessage
+}
+
+func (r *memMessages) Create(ctx context.Context, msg *domain.EmailMessage) (*domain.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, *msg)
+	return msg, nil
+}
+func (r *memMessages) FindByID(ctx context.Context, id string) (*domain.EmailMessage, error) {
+	return nil, domain.ErrNotFound
+}
+func (r *memMessages) FindByProviderMessageID(ctx context.Context, id string) (*domain.EmailMessage, error) {
+	return nil, domain.ErrNotFound
+}
+func (r *memMessages) UpdateStatus(ctx context.Context, id string, status domain.EmailStatus, reason string) (*domain.EmailMessage, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *memMessages) records() []domain.EmailMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.EmailMessage(nil), r.created...)
+}
+
+// startQueue runs a dispatcher sending through sender, with a fast policy of
+// maxAttempts attempts, and a failed log, returning a sender that queues.
+func startQueue(t *testing.T, sender domain.EmailSender, messages domain.EmailMessageRepository, maxAttempts int) (*QueueSender, *FailedLog) {
+	t.Helper()
+	ps := pubsub.NewWatermillBridge()
+	t.Cleanup(func() { ps.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	policy := retry.Policy{InitialDelay: time.Millisecond, MaxAttempts: maxAttempts}
+	dispatcher := NewDispatcher(sender, ps, ps, WithRetryPolicy(policy), WithTracking(messages))
+	failed := NewFailedLog(ps)
+	failed.Start(ctx)
+	dispatcher.Start(ctx)
+	require.Eventually(t, func() bool {
+		return ps.HasSubscribers(TopicOutbound.Name()) && ps.HasSubscribers(TopicOutboundDLQ.Name())
+	}, time.Second, 5*time.Millisecond)
+	return NewQueueSender(ps), failed
+}
+
+func TestDispatcher_DeadLettersAfterRetries(t *testing.T) {
+	const maxAttempts = 3
+	sender := &flakySender{failures: 10}
+	messages := &memMessages{}
+	queue, failed := startQueue(t, sender, messages, maxAttempts)
+
+	require.NoError(t, queue.SendTemplate("password_reset", "user@example.com", "Reset Your Password", "<p>hi</p>"))
+
+	require.Eventually(t, func() bool { return len(failed.Failed()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	got := failed.Failed()[0]
+	assert.Equal(t, maxAttempts, got.Attempts)
+	assert.Equal(t, "provider unavailable (attempt 3)", got.Reason)
+	assert.Equal(t, "password_reset", got.Template)
+	assert.Equal(t, "user@example.com", got.To)
+	assert.Equal(t, "<p>hi</p>", got.HTMLBody, "the dead-lettered email can be resent")
+	assert.False(t, got.FailedAt.IsZero())
+	assert.Equal(t, maxAttempts, sender.count(), "no attempts beyond the policy")
+
+	records := messages.records()
+	require.Len(t, records, 1, "one record per email, not per attempt")
+	assert.Equal(t, domain.EmailStatusFailed, records[0].Status)
+	assert.Equal(t, got.Reason, records[0].StatusReason)
+}
+
+func TestDispatcher_RetriesTransientFailures(t *testing.T) {
+	sender := &flakySender{failures: 2}
+	messages := &memMessages{}
+	queue, failed := startQueue(t, sender, messages, 3)
+
+	require.NoError(t, queue.Send("user@example.com", "Welcome", "<p>hi</p>"))
+
+	require.Eventually(t, func() bool { return len(messages.records()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, domain.EmailStatusSent, messages.records()[0].Status)
+	assert.Equal(t, 3, sender.count())
+	assert.Empty(t, failed.Failed())
+}
+
+// recipientSender fails every send to failing.
+type recipientSender struct {
+	failing string
+}
+
+func (s recipientSender) Send(to, subject, htmlBody string) error {
+	if to == s.failing {
+		return errors.New("mailbox unavailable")
+	}
+	return nil
+}
+
+func TestDispatcher_RetriesWithoutBlockingTheQueue(t *testing.T) {
+	ps := pubsub.NewWatermillBridge()
+	defer ps.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		requeued []pubsub.Message
+	)
+	require.NoError(t, ps.Subscribe(ctx, TopicOutbound.Name(), func(ctx context.Context, msg pubsub.Message) error {
+		if msg.Metadata[metaKeyAttempts] != "" {
+			mu.Lock()
+			requeued = append(requeued, msg)
+			mu.Unlock()
+		}
+		return nil
+	}))
+
+	messages := &memMessages{}
+	policy := retry.Policy{InitialDelay: time.Hour, MaxAttempts: 3}
+	dispatcher := NewDispatcher(recipientSender{failing: "slow@example.com"}, ps, ps, WithRetryPolicy(policy), WithTracking(messages))
+	dispatcher.Start(ctx)
+	require.Eventually(t, func() bool { return ps.SubscriberCounts()[TopicOutbound.Name()] == 2 }, time.Second, 5*time.Millisecond)
+
+	queue := NewQueueSender(ps)
+	require.NoError(t, queue.Send("slow@example.com", "First", "<p>1</p>"))
+	require.NoError(t, queue.Send("fast@example.com", "Second", "<p>2</p>"))
+
+	require.Eventually(t, func() bool { return len(messages.records()) == 1 }, time.Second, 5*time.Millisecond,
+		"the second email is sent while the first waits for its retry")
+	assert.Equal(t, "fast@example.com", messages.records()[0].Recipient)
+
+	require.NoError(t, dispatcher.Stop(context.Background()))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(requeued) == 1
+	}, time.Second, 5*time.Millisecond, "Stop requeues the email waiting for a retry")
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "1", requeued[0].Metadata[metaKeyAttempts])
+	assert.JSONEq(t, `{"to":"slow@example.com","subject":"First","htmlBody":"<p>1</p>"}`, string(requeued[0].Payload))
+}
+
+func TestDispatcher_LeavesEmailWhenShuttingDown(t *testing.T) {
+	d := NewDispatcher(&flakySender{failures: 10}, nil, nil, WithRetryPolicy(retry.Policy{InitialDelay: time.Hour}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := d.handle(ctx, pubsub.Message{Topic: TopicOutbound.Name(), Payload: []byte(`{"to":"user@example.com"}`)})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, pubsub.ActionNack, pubsub.ActionFor(err), "redelivered rather than dead-lettered")
+}
+
+func TestFailedLog_KeepsNewestFirst(t *testing.T) {
+	l := &FailedLog{}
+	for i := range maxFailedEmails + 2 {
+		payload := fmt.Sprintf(`{"to":"user%d@example.com","attempts":1}`, i)
+		require.NoError(t, l.handle(context.Background(), pubsub.Message{Payload: []byte(payload)}))
+	}
+
+	failed := l.Failed()
+	require.Len(t, failed, maxFailedEmails)
+	assert.Equal(t, fmt.Sprintf("user%d@example.com", maxFailedEmails+1), failed[0].To)
+	assert.Equal(t, "user2@example.com", failed[len(failed)-1].To, "the oldest are dropped")
+}
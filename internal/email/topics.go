@@ -0,0 +1,46 @@
+package email
+
+import "github.com/nfrund/goby/internal/topicmgr"
+
+// Framework topics for the outbound email queue.
+var (
+	// TopicOutbound carries emails waiting to be sent by a Dispatcher.
+	TopicOutbound = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "email.outbound",
+		Description: "Emails queued for sending",
+		Pattern:     "email.outbound",
+		Example:     `{"template":"password_reset","to":"user@example.com","subject":"Reset Your Password","htmlBody":"<p>...</p>"}`,
+		Metadata: map[string]interface{}{
+			"event_type":     "command",
+			"payload_fields": []string{"template", "to", "subject", "htmlBody"},
+		},
+	})
+
+	// TopicOutboundDLQ receives emails that could not be sent after every
+	// attempt the retry policy allows, with the reason of the last failure.
+	TopicOutboundDLQ = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "email.outbound.dlq",
+		Description: "Emails that failed to send after all retries",
+		Pattern:     "email.outbound.dlq",
+		Example:     `{"template":"password_reset","to":"user@example.com","subject":"Reset Your Password","htmlBody":"<p>...</p>","attempts":5,"reason":"resend API returned an error: status 503","failedAt":"2024-01-01T00:00:00Z"}`,
+		Metadata: map[string]interface{}{
+			"event_type":     "dead_letter",
+			"payload_fields": []string{"template", "to", "subject", "htmlBody", "attempts", "reason", "failedAt"},
+		},
+	})
+)
+
+// FrameworkTopics returns the email queue topics, for a
+// topicmgr.FrameworkRegistrar.
+func FrameworkTopics() []topicmgr.Topic {
+	return []topicmgr.Topic{
+		TopicOutbound,
+		TopicOutboundDLQ,
+	}
+}
+
+// RegisterTopics registers the email queue topics with the topic manager.
+// Topics that are already registered are skipped.
+func RegisterTopics() error {
+	return topicmgr.NewFrameworkRegistrar().Add("email", FrameworkTopics()...).Register(topicmgr.Default())
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nfrund/goby/internal/domain"
+)
+
+// recordTimeout bounds how long persisting a sent email may take. Send has no
+// context of its own, and a slow database must not hold up the caller.
+const recordTimeout = 5 * time.Second
+
+// TrackingSender wraps an EmailSender and records every message it sends,
+// with the provider's message ID when the sender reports one, so delivery
+// webhooks can update the message's status later.
+type TrackingSender struct {
+	sender   domain.EmailSender
+	messages domain.EmailMessageRepository
+}
+
+// NewTrackingSender returns a sender that delivers through sender and
+// records each message in messages.
+func NewTrackingSender(sender domain.EmailSender, messages domain.EmailMessageRepository) *TrackingSender {
+	return &TrackingSender{sender: sender, messages: messages}
+}
+
+// Send delivers an email and records it without a template name.
+func (t *TrackingSender) Send(to, subject, htmlBody string) error {
+	return t.SendTemplate("", to, subject, htmlBody)
+}
+
+// SendTemplate delivers an email and records it under the given template
+// name. A failure to record the message is logged but does not fail the send,
+// since the email may already be on its way.
+func (t *TrackingSender) SendTemplate(template, to, subject, htmlBody string) error {
+	providerID, err := sendMessage(t.sender, to, subject, htmlBody)
+	recordEmail(t.messages, template, to, subject, providerID, err)
+	return err
+}
+
+// sendMessage delivers an email through sender, returning the provider's
+// message ID when the sender reports one.
+func sendMessage(sender domain.EmailSender, to, subject, htmlBody string) (string, error) {
+	if ps, ok := sender.(domain.ProviderEmailSender); ok {
+		return ps.SendMessage(to, subject, htmlBody)
+	}
+	return "", sender.Send(to, subject, htmlBody)
+}
+
+// recordEmail stores the outcome of a send in messages: sent when sendErr is
+// nil, failed with its message otherwise. Errors are logged, not returned.
+func recordEmail(messages domain.EmailMessageRepository, template, to, subject, providerID string, sendErr error) {
+	msg := &domain.EmailMessage{
+		Recipient:         to,
+		Subject:           subject,
+		Template:          template,
+		Status:            domain.EmailStatusSent,
+		ProviderMessageID: providerID,
+	}
+	if sendErr != nil {
+		msg.Status = domain.EmailStatusFailed
+		msg.StatusReason = sendErr.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if _, err := messages.Create(ctx, msg); err != nil {
+		slog.Error("Failed to record sent email", "to", to, "template", template, "error", err)
+	}
+}
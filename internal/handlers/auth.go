@@ -211,7 +211,7 @@ func (h *AuthHandler) ForgotPasswordPost(c echo.Context) error {
 	if token != "" && h.emailer != nil {
 		resetLink := h.baseURL + "/auth/reset-password?token=" + token
 		htmlBody := fmt.Sprintf(`<p>Click the link below to reset your password:</p><a href="%s">Reset Password</a>`, resetLink)
-		err = h.emailer.Send(email, "Reset Your Password", htmlBody)
+		err = domain.SendTemplate(h.emailer, "password_reset", email, "Reset Your Password", htmlBody)
 		if err != nil {
 			// Log the error but still show a success message to the user.
 			slog.Error("Failed to send password reset email", "error", err, "email", email)
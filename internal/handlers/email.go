@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/email"
+	"github.com/nfrund/goby/internal/middleware"
+)
+
+// webhookTolerance is how far a webhook's timestamp may be from now before
+// it is rejected as a possible replay.
+const webhookTolerance = 5 * time.Minute
+
+// maxWebhookBody bounds the size of a webhook request body.
+const maxWebhookBody = 1 << 20
+
+// emailMessageIDPattern matches an email record ID, with or without its table.
+var emailMessageIDPattern = regexp.MustCompile(`^(email_message:)?[A-Za-z0-9_]+$`)
+
+// webhookEventStatuses maps the provider's delivery event types to statuses.
+var webhookEventStatuses = map[string]domain.EmailStatus{
+	"email.sent":             domain.EmailStatusSent,
+	"email.delivery_delayed": domain.EmailStatusDelayed,
+	"email.delivered":        domain.EmailStatusDelivered,
+	"email.bounced":          domain.EmailStatusBounced,
+	"email.complained":       domain.EmailStatusComplained,
+	"email.failed":           domain.EmailStatusFailed,
+}
+
+// FailedEmailLister lists the emails the outbound queue gave up on, newest
+// first. email.FailedLog implements it.
+type FailedEmailLister interface {
+	Failed() []email.FailedEmail
+}
+
+// EmailHandler ingests email delivery webhooks and reports delivery status.
+type EmailHandler struct {
+	messages      domain.EmailMessageRepository
+	failed        FailedEmailLister
+	webhookSecret []byte
+	now           func() time.Time
+}
+
+// EmailHandlerOption configures optional EmailHandler behavior.
+type EmailHandlerOption func(*EmailHandler)
+
+// WithFailedEmails makes Failed list the emails in failed. Without it, Failed
+// reports that the outbound queue is not configured.
+func WithFailedEmails(failed FailedEmailLister) EmailHandlerOption {
+	return func(h *EmailHandler) {
+		h.failed = failed
+	}
+}
+
+// NewEmailHandler creates a new EmailHandler. webhookSecret is the signing
+// secret from the provider's webhook settings ("whsec_..."); without it the
+// webhook endpoint refuses every request.
+func NewEmailHandler(messages domain.EmailMessageRepository, webhookSecret string, opts ...EmailHandlerOption) *EmailHandler {
+	h := &EmailHandler{messages: messages, now: time.Now}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if webhookSecret != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(webhookSecret, "whsec_"))
+		if err != nil {
+			// Not a base64 secret; use it verbatim.
+			key = []byte(webhookSecret)
+		}
+		h.webhookSecret = key
+	}
+	return h
+}
+
+// emailWebhookEvent is the body of a provider delivery webhook.
+type emailWebhookEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		EmailID string `json:"email_id"`
+		Bounce  struct {
+			Message string `json:"message"`
+		} `json:"bounce"`
+		Failed struct {
+			Reason string `json:"reason"`
+		} `json:"failed"`
+	} `json:"data"`
+}
+
+// Webhook updates an email's delivery status from a signed provider event.
+// Events for unknown emails or of unknown types are acknowledged and ignored
+// so the provider does not keep retrying them.
+func (h *EmailHandler) Webhook(c echo.Context) error {
+	ctx := c.Request().Context()
+	logger := middleware.FromContext(ctx)
+
+	if len(h.webhookSecret) == 0 {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Code:    "WEBHOOK_NOT_CONFIGURED",
+			Message: "email webhooks are not configured",
+		})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBody))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_REQUEST", Message: "failed to read body"})
+	}
+	if err := h.verifySignature(c.Request().Header, body); err != nil {
+		logger.Warn("Rejected email webhook", "error", err)
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Code: "INVALID_SIGNATURE", Message: "invalid webhook signature"})
+	}
+
+	var event emailWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_REQUEST", Message: "invalid webhook payload"})
+	}
+
+	status, ok := webhookEventStatuses[event.Type]
+	if !ok || event.Data.EmailID == "" {
+		logger.Debug("Ignoring email webhook event", "type", event.Type)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	msg, err := h.messages.FindByProviderMessageID(ctx, event.Data.EmailID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Info("Email webhook for unknown message", "type", event.Type, "providerMessageID", event.Data.EmailID)
+			return c.NoContent(http.StatusNoContent)
+		}
+		logger.Error("Failed to look up email for webhook", "error", err)
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "failed to look up email"})
+	}
+
+	// Events can arrive out of order; a bounce must not be overwritten by a
+	// late "delivered" or "delayed".
+	if msg.Status.Final() && !status.Final() {
+		logger.Debug("Ignoring email webhook after final status", "type", event.Type, "status", msg.Status)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	reason := event.Data.Bounce.Message
+	if reason == "" {
+		reason = event.Data.Failed.Reason
+	}
+	if _, err := h.messages.UpdateStatus(ctx, msg.ID.String(), status, reason); err != nil {
+		logger.Error("Failed to update email status", "emailID", msg.ID.String(), "error", err)
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "failed to update email status"})
+	}
+
+	logger.Info("Email status updated", "emailID", msg.ID.String(), "status", status)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// verifySignature checks the webhook's Svix-style signature: an HMAC-SHA256
+// of "id.timestamp.body", sent base64-encoded in a space-separated list of
+// "v1,<signature>" entries.
+func (h *EmailHandler) verifySignature(header http.Header, body []byte) error {
+	id := header.Get("svix-id")
+	timestamp := header.Get("svix-timestamp")
+	signatures := header.Get("svix-signature")
+	if id == "" || timestamp == "" || signatures == "" {
+		return errors.New("missing signature headers")
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	if age := h.now().Sub(time.Unix(sec, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return errors.New("signature timestamp outside tolerance")
+	}
+
+	mac := hmac.New(sha256.New, h.webhookSecret)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, entry := range strings.Fields(signatures) {
+		version, sig, ok := strings.Cut(entry, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(sig)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.New("no matching signature")
+}
+
+// Status returns the delivery status of a sent email to its recipient.
+func (h *EmailHandler) Status(c echo.Context) error {
+	id := c.Param("id")
+	if !emailMessageIDPattern.MatchString(id) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_ID", Message: "invalid email ID"})
+	}
+	if !strings.Contains(id, ":") {
+		id = "email_message:" + id
+	}
+
+	msg, err := h.messages.FindByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "email not found"})
+		}
+		middleware.FromContext(c.Request().Context()).Error("Failed to get email status", "emailID", id, "error", err)
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "failed to get email status"})
+	}
+
+	// Other users get the same response as for a missing email, so IDs
+	// can't be probed.
+	user, ok := c.Get(middleware.UserContextKey).(*domain.User)
+	if !ok || user == nil || !strings.EqualFold(user.Email, msg.Recipient) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "email not found"})
+	}
+
+	return c.JSON(http.StatusOK, NewEmailStatusResponse(msg))
+}
+
+// Failed lists the emails that could not be sent after all retries, with the
+// reason the last attempt failed. The list covers every user's emails, so
+// serve it behind middleware.RequireAdmin.
+func (h *EmailHandler) Failed(c echo.Context) error {
+	if h.failed == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Code:    "QUEUE_NOT_CONFIGURED",
+			Message: "the outbound email queue is not configured",
+		})
+	}
+	failed := h.failed.Failed()
+	resp := make([]*FailedEmailResponse, 0, len(failed))
+	for _, f := range failed {
+		resp = append(resp, NewFailedEmailResponse(f))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,240 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/email"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// memEmailRepo is an in-memory domain.EmailMessageRepository.
+type memEmailRepo struct {
+	mu       sync.Mutex
+	messages []*domain.EmailMessage
+}
+
+func (r *memEmailRepo) Create(ctx context.Context, msg *domain.EmailMessage) (*domain.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := surrealmodels.NewRecordID("email_message", fmt.Sprintf("m%d", len(r.messages)+1))
+	msg.ID = &id
+	r.messages = append(r.messages, msg)
+	return msg, nil
+}
+func (r *memEmailRepo) FindByID(ctx context.Context, id string) (*domain.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.ID.String() == id {
+			return msg, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+func (r *memEmailRepo) FindByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.EmailMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.ProviderMessageID == providerMessageID {
+			return msg, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+func (r *memEmailRepo) UpdateStatus(ctx context.Context, id string, status domain.EmailStatus, reason string) (*domain.EmailMessage, error) {
+	msg, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.Status = status
+	msg.StatusReason = reason
+	return msg, nil
+}
+
+// fakeProvider is a domain.ProviderEmailSender that hands out sequential IDs.
+type fakeProvider struct{ sent int }
+
+func (p *fakeProvider) Send(to, subject, htmlBody string) error {
+	_, err := p.SendMessage(to, subject, htmlBody)
+	return err
+}
+func (p *fakeProvider) SendMessage(to, subject, htmlBody string) (string, error) {
+	p.sent++
+	return fmt.Sprintf("provider-%d", p.sent), nil
+}
+
+const testWebhookSecret = "whsec_dGVzdC1zZWNyZXQ=" // base64("test-secret")
+
+// signedWebhook builds a webhook request signed the way the provider signs it.
+func signedWebhook(t *testing.T, body string, ts time.Time) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	mac.Write([]byte("msg_1." + timestamp + "." + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/email", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("svix-id", "msg_1")
+	req.Header.Set("svix-timestamp", timestamp)
+	req.Header.Set("svix-signature", "v1,bm90LXRoaXMtb25l v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestEmailHandler_BounceWebhook(t *testing.T) {
+	repo := &memEmailRepo{}
+	sender := email.NewTrackingSender(&fakeProvider{}, repo)
+	require.NoError(t, domain.SendTemplate(sender, "password_reset", "user@example.com", "Reset Your Password", "<p>hi</p>"))
+	require.Len(t, repo.messages, 1)
+	sent := repo.messages[0]
+	assert.Equal(t, domain.EmailStatusSent, sent.Status)
+	assert.Equal(t, "password_reset", sent.Template)
+	assert.Equal(t, "provider-1", sent.ProviderMessageID)
+
+	h := handlers.NewEmailHandler(repo, testWebhookSecret)
+	e := echo.New()
+	e.POST("/webhooks/email", h.Webhook)
+	// The status route runs behind Auth; ?as= stands in for the signed-in user.
+	e.GET("/internal/emails/:id/status", h.Status, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if as := c.QueryParam("as"); as != "" {
+				c.Set(middleware.UserContextKey, &domain.User{Email: as})
+			}
+			return next(c)
+		}
+	})
+
+	deliver := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	event := func(kind, extra string) string {
+		return fmt.Sprintf(`{"type":%q,"data":{"email_id":"provider-1"%s}}`, kind, extra)
+	}
+
+	t.Run("rejects unsigned and stale events", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/email", strings.NewReader(event("email.bounced", "")))
+		assert.Equal(t, http.StatusUnauthorized, deliver(req))
+
+		stale := signedWebhook(t, event("email.bounced", ""), time.Now().Add(-time.Hour))
+		assert.Equal(t, http.StatusUnauthorized, deliver(stale))
+
+		tampered := signedWebhook(t, event("email.delivered", ""), time.Now())
+		tampered.Body = http.NoBody
+		assert.Equal(t, http.StatusUnauthorized, deliver(tampered))
+		assert.Equal(t, domain.EmailStatusSent, sent.Status)
+	})
+
+	t.Run("bounce updates the status", func(t *testing.T) {
+		body := event("email.bounced", `,"bounce":{"message":"Mailbox does not exist"}`)
+		assert.Equal(t, http.StatusNoContent, deliver(signedWebhook(t, body, time.Now())))
+
+		req := httptest.NewRequest(http.MethodGet, "/internal/emails/m1/status?as=user@example.com", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp handlers.EmailStatusResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "email_message:m1", resp.ID)
+		assert.Equal(t, domain.EmailStatusBounced, resp.Status)
+		assert.Equal(t, "Mailbox does not exist", resp.StatusReason)
+		assert.Equal(t, "password_reset", resp.Template)
+	})
+
+	t.Run("late delivery does not overwrite a bounce", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, deliver(signedWebhook(t, event("email.delivered", ""), time.Now())))
+		assert.Equal(t, domain.EmailStatusBounced, sent.Status)
+	})
+
+	t.Run("unknown messages are acknowledged", func(t *testing.T) {
+		body := `{"type":"email.bounced","data":{"email_id":"someone-else"}}`
+		assert.Equal(t, http.StatusNoContent, deliver(signedWebhook(t, body, time.Now())))
+	})
+
+	t.Run("status lookups", func(t *testing.T) {
+		for path, code := range map[string]int{
+			"/internal/emails/email_message:m1/status?as=User@example.com": http.StatusOK,
+			"/internal/emails/m404/status?as=user@example.com":             http.StatusNotFound,
+			"/internal/emails/m1;DELETE/status?as=user@example.com":        http.StatusBadRequest,
+			"/internal/emails/m1/status?as=other@example.com":              http.StatusNotFound,
+			"/internal/emails/m1/status":                                   http.StatusNotFound,
+		} {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, code, rec.Code, path)
+		}
+	})
+}
+
+func TestEmailHandler_WebhookRequiresSecret(t *testing.T) {
+	h := handlers.NewEmailHandler(&memEmailRepo{}, "")
+	e := echo.New()
+	e.POST("/webhooks/email", h.Webhook)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, signedWebhook(t, `{"type":"email.bounced"}`, time.Now()))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// failedEmails is a handlers.FailedEmailLister returning a fixed list.
+type failedEmails []email.FailedEmail
+
+func (f failedEmails) Failed() []email.FailedEmail { return f }
+
+func TestEmailHandler_Failed(t *testing.T) {
+	failedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := handlers.NewEmailHandler(&memEmailRepo{}, "", handlers.WithFailedEmails(failedEmails{{
+		OutboundEmail: email.OutboundEmail{Template: "password_reset", To: "user@example.com", Subject: "Reset", HTMLBody: "<a href='/reset?token=secret'>"},
+		Attempts:      5,
+		Reason:        "resend API returned an error: status 503",
+		FailedAt:      failedAt,
+	}}))
+	e := echo.New()
+	e.GET("/internal/emails/failed", h.Failed)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/emails/failed", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret", "bodies are not listed")
+
+	var got []handlers.FailedEmailResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, handlers.FailedEmailResponse{
+		Template: "password_reset",
+		To:       "user@example.com",
+		Subject:  "Reset",
+		Attempts: 5,
+		Reason:   "resend API returned an error: status 503",
+		FailedAt: failedAt,
+	}, got[0])
+
+	t.Run("without a queue", func(t *testing.T) {
+		e := echo.New()
+		e.GET("/internal/emails/failed", handlers.NewEmailHandler(&memEmailRepo{}, "").Failed)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/emails/failed", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/database"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/storage"
+)
+
+// ErrorRule maps errors matching Target, as reported by errors.Is, to a
+// response.
+type ErrorRule struct {
+	Target error
+	Status int
+	// Code is the machine-readable ErrorResponse code, e.g. "NOT_FOUND".
+	Code string
+	// Message is shown to the client. Empty uses Target's text, never the
+	// wrapped error's, so queries and other internals are not leaked.
+	Message string
+}
+
+// ErrorMapper turns errors returned by handlers into ErrorResponse bodies
+// with the right status code, so modules can return domain.ErrNotFound and
+// friends instead of building echo.HTTPErrors themselves.
+type ErrorMapper struct {
+	mu    sync.RWMutex
+	rules []ErrorRule
+}
+
+// NewErrorMapper creates a mapper that knows the shared domain, database and
+// storage errors.
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{rules: []ErrorRule{
+		{Target: domain.ErrUnauthorized, Status: http.StatusUnauthorized, Code: "UNAUTHORIZED"},
+		{Target: domain.ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "INVALID_CREDENTIALS"},
+		{Target: domain.ErrForbidden, Status: http.StatusForbidden, Code: "FORBIDDEN"},
+		{Target: domain.ErrInvalidRequest, Status: http.StatusBadRequest, Code: "INVALID_REQUEST"},
+		{Target: domain.ErrNotFound, Status: http.StatusNotFound, Code: "NOT_FOUND"},
+		{Target: domain.ErrUserAlreadyExists, Status: http.StatusConflict, Code: "ALREADY_EXISTS"},
+		{Target: domain.ErrVersionConflict, Status: http.StatusConflict, Code: "VERSION_CONFLICT"},
+		{Target: domain.ErrQuotaExceeded, Status: http.StatusForbidden, Code: "QUOTA_EXCEEDED"},
+		{Target: domain.ErrTooLarge, Status: http.StatusRequestEntityTooLarge, Code: "TOO_LARGE"},
+		{Target: database.ErrNotFound, Status: http.StatusNotFound, Code: "NOT_FOUND"},
+		{Target: database.ErrInvalidID, Status: http.StatusBadRequest, Code: "INVALID_ID"},
+		{Target: database.ErrInvalidInput, Status: http.StatusBadRequest, Code: "INVALID_REQUEST"},
+		{Target: database.ErrAlreadyExists, Status: http.StatusConflict, Code: "ALREADY_EXISTS"},
+		{Target: database.ErrNotConnected, Status: http.StatusServiceUnavailable, Code: "UNAVAILABLE", Message: "database unavailable"},
+		{Target: storage.ErrUnsafePath, Status: http.StatusBadRequest, Code: "INVALID_PATH"},
+	}}
+}
+
+// Register adds a rule, e.g. for a module's own sentinel error. Rules added
+// later take precedence over earlier ones and the defaults.
+func (m *ErrorMapper) Register(rule ErrorRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule)
+}
+
+// Map returns the status and body for err. An *echo.HTTPError keeps its
+// status and message. ok is false for errors the mapper does not know, which
+// callers should treat as internal errors.
+func (m *ErrorMapper) Map(err error) (status int, resp ErrorResponse, ok bool) {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code, ErrorResponse{Code: statusCode(he.Code), Message: fmt.Sprintf("%v", he.Message)}, true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		rule := m.rules[i]
+		if !errors.Is(err, rule.Target) {
+			continue
+		}
+		msg := rule.Message
+		if msg == "" {
+			msg = rule.Target.Error()
+		}
+		return rule.Status, ErrorResponse{Code: rule.Code, Message: msg}, true
+	}
+	return http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: http.StatusText(http.StatusInternalServerError)}, false
+}
+
+// statusCode derives an ErrorResponse code from an HTTP status, e.g.
+// "NOT_FOUND" for 404.
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "ERROR"
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/database"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMapper_Map(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"unauthorized", domain.ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"invalid credentials", domain.ErrInvalidCredentials, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+		{"invalid request", fmt.Errorf("%w: name is required", domain.ErrInvalidRequest), http.StatusBadRequest, "INVALID_REQUEST"},
+		{"domain not found", domain.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+		{"user exists", domain.ErrUserAlreadyExists, http.StatusConflict, "ALREADY_EXISTS"},
+		{"version conflict", domain.ErrVersionConflict, http.StatusConflict, "VERSION_CONFLICT"},
+		{"quota exceeded", domain.ErrQuotaExceeded, http.StatusForbidden, "QUOTA_EXCEEDED"},
+		{"too large", domain.ErrTooLarge, http.StatusRequestEntityTooLarge, "TOO_LARGE"},
+		{"db not found", database.NewDBError(database.ErrNotFound, "record not found").WithQuery("SELECT * FROM $id"), http.StatusNotFound, "NOT_FOUND"},
+		{"db invalid id", database.ErrInvalidID, http.StatusBadRequest, "INVALID_ID"},
+		{"db invalid input", database.ErrInvalidInput, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"db already exists", database.ErrAlreadyExists, http.StatusConflict, "ALREADY_EXISTS"},
+		{"db not connected", database.ErrNotConnected, http.StatusServiceUnavailable, "UNAVAILABLE"},
+		{"unsafe storage path", storage.ErrUnsafePath, http.StatusBadRequest, "INVALID_PATH"},
+		{"echo http error", echo.NewHTTPError(http.StatusMethodNotAllowed, "nope"), http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{"echo not found", echo.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+	}
+
+	m := handlers.NewErrorMapper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp, ok := m.Map(tt.err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, resp.Code)
+			assert.NotEmpty(t, resp.Message)
+			assert.NotContains(t, resp.Message, "SELECT", "wrapped details must not reach the client")
+		})
+	}
+}
+
+func TestErrorMapper_UnknownErrorsAreInternal(t *testing.T) {
+	status, resp, ok := handlers.NewErrorMapper().Map(errors.New("connection reset"))
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "INTERNAL_ERROR", resp.Code)
+	assert.NotContains(t, resp.Message, "connection reset")
+}
+
+func TestErrorMapper_Register(t *testing.T) {
+	errRoomFull := errors.New("room is full")
+	m := handlers.NewErrorMapper()
+	m.Register(handlers.ErrorRule{Target: errRoomFull, Status: http.StatusConflict, Code: "ROOM_FULL"})
+	m.Register(handlers.ErrorRule{Target: domain.ErrNotFound, Status: http.StatusGone, Code: "GONE", Message: "it is gone"})
+
+	status, resp, ok := m.Map(fmt.Errorf("join: %w", errRoomFull))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, handlers.ErrorResponse{Code: "ROOM_FULL", Message: "room is full"}, resp)
+
+	status, resp, _ = m.Map(domain.ErrNotFound)
+	assert.Equal(t, http.StatusGone, status, "later rules override the defaults")
+	assert.Equal(t, "it is gone", resp.Message)
+}
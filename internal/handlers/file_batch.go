@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultMaxBatchDelete is the most files one batch delete request may name
+// when WithMaxBatchDelete is not used.
+const DefaultMaxBatchDelete = 100
+
+// Per-file outcomes of a batch delete, reported in FileDeleteResult.Error.
+const (
+	BatchDeleteNotFound  = "not_found"
+	BatchDeleteForbidden = "forbidden"
+	BatchDeleteFailed    = "failed"
+)
+
+// WithMaxBatchDelete caps how many files one batch delete request may name.
+// Zero or less keeps DefaultMaxBatchDelete.
+func WithMaxBatchDelete(n int) FileHandlerOption {
+	return func(h *FileHandler) {
+		if n > 0 {
+			h.maxBatchDelete = n
+		}
+	}
+}
+
+// DeleteFiles deletes several of the user's files in one request. Each file
+// is deleted like DeleteFile and independently of the others, so the
+// response reports the outcome per ID instead of failing the whole batch.
+// Repeated IDs are deleted once.
+func (h *FileHandler) DeleteFiles(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var req DeleteFilesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request format.")
+	}
+	if len(req.IDs) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "At least one file ID is required.")
+	}
+	if len(req.IDs) > h.maxBatchDelete {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("A batch may delete at most %d files.", h.maxBatchDelete))
+	}
+
+	ctx := c.Request().Context()
+	resp := DeleteFilesResponse{Results: make([]FileDeleteResult, 0, len(req.IDs))}
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := FileDeleteResult{ID: id}
+		switch err := h.deleteFile(ctx, user, id); {
+		case err == nil:
+			result.Deleted = true
+			resp.Deleted++
+		case errors.Is(err, errFileNotFound):
+			result.Error = BatchDeleteNotFound
+		case errors.Is(err, errFileForbidden):
+			result.Error = BatchDeleteForbidden
+		default:
+			result.Error = BatchDeleteFailed
+		}
+		if !result.Deleted {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -1,17 +1,23 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/domain"
 	"github.com/nfrund/goby/internal/middleware"
+	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/storage"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 )
 
 // FileHandler handles HTTP requests related to files.
@@ -20,21 +26,122 @@ type FileHandler struct {
 	fileRepo         domain.FileRepository
 	maxFileSize      int64
 	allowedMimeTypes map[string]bool
+	// allowedMimePrefixes holds wildcard entries such as "image/*" as the
+	// prefix to match ("image/"). A "*/*" entry allows every type.
+	allowedMimePrefixes []string
+	allowAllMimeTypes   bool
+	filenameMode        storage.SanitizeMode
+	// deduplicate makes an upload whose content the user has already stored
+	// reference the existing blob instead of writing a copy.
+	deduplicate bool
+	// pathTemplate computes the storage key of each upload.
+	pathTemplate *storage.PathTemplate
+	// progressPublisher, when set, receives upload progress events.
+	progressPublisher pubsub.Publisher
+	// maxBatchDelete caps the files one DeleteFiles request may name.
+	maxBatchDelete int
 }
 
+// FileHandlerOption configures optional FileHandler behavior.
+type FileHandlerOption func(*FileHandler)
+
+// WithFilenameSanitization sets how unsafe upload filenames are handled.
+// The default, storage.SanitizeNormalize, strips directory components.
+func WithFilenameSanitization(mode storage.SanitizeMode) FileHandlerOption {
+	return func(h *FileHandler) {
+		h.filenameMode = mode
+	}
+}
+
+// WithDeduplication enables content-addressed deduplication of uploads. When
+// a user uploads content they have already stored, the new file record points
+// at the existing blob, and the blob is only removed from storage once the
+// last record referencing it is deleted. Deduplication is scoped to the
+// uploading user so that one user cannot learn what another has stored.
+func WithDeduplication(enabled bool) FileHandlerOption {
+	return func(h *FileHandler) {
+		h.deduplicate = enabled
+	}
+}
+
+// WithPathTemplate sets the template that computes upload storage keys. The
+// default is storage.DefaultPathTemplate; a nil template keeps the default.
+func WithPathTemplate(tmpl *storage.PathTemplate) FileHandlerOption {
+	return func(h *FileHandler) {
+		if tmpl != nil {
+			h.pathTemplate = tmpl
+		}
+	}
+}
+
+// defaultPathTemplate is parsed once; DefaultPathTemplate is known to be valid.
+var defaultPathTemplate, _ = storage.ParsePathTemplate(storage.DefaultPathTemplate)
+
 // NewFileHandler creates a new FileHandler.
-func NewFileHandler(fileStore storage.Store, fileRepo domain.FileRepository, maxFileSize int64, allowedMimeTypes []string) *FileHandler {
+//
+// allowedMimeTypes may contain exact types ("image/png") and wildcard entries
+// ("image/*", or "*/*" for anything). An empty list allows all types; to deny
+// uploads entirely, don't register the upload route.
+func NewFileHandler(fileStore storage.Store, fileRepo domain.FileRepository, maxFileSize int64, allowedMimeTypes []string, opts ...FileHandlerOption) *FileHandler {
 	mimeTypesMap := make(map[string]bool)
+	var mimePrefixes []string
+	wildcardAll := false
 	for _, mimeType := range allowedMimeTypes {
-		mimeTypesMap[strings.TrimSpace(mimeType)] = true
+		mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+		switch {
+		case mimeType == "":
+			continue
+		case mimeType == "*/*" || mimeType == "*":
+			wildcardAll = true
+		case strings.HasSuffix(mimeType, "/*"):
+			mimePrefixes = append(mimePrefixes, strings.TrimSuffix(mimeType, "*"))
+		default:
+			mimeTypesMap[mimeType] = true
+		}
 	}
+	allowAll := wildcardAll || (len(mimeTypesMap) == 0 && len(mimePrefixes) == 0)
+
+	h := &FileHandler{
+		fileStore:           fileStore,
+		fileRepo:            fileRepo,
+		maxFileSize:         maxFileSize,
+		allowedMimeTypes:    mimeTypesMap,
+		allowedMimePrefixes: mimePrefixes,
+		allowAllMimeTypes:   allowAll,
+		filenameMode:        storage.SanitizeNormalize,
+		pathTemplate:        defaultPathTemplate,
+		maxBatchDelete:      DefaultMaxBatchDelete,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
 
-	return &FileHandler{
-		fileStore:        fileStore,
-		fileRepo:         fileRepo,
-		maxFileSize:      maxFileSize,
-		allowedMimeTypes: mimeTypesMap,
+// AllowsMimeType reports whether an upload with the given Content-Type is
+// permitted. Parameters such as "; charset=utf-8" are ignored and matching is
+// case-insensitive. Exact entries match exactly; "type/*" entries match any
+// subtype of type.
+func (h *FileHandler) AllowsMimeType(mimeType string) bool {
+	if h.allowAllMimeTypes {
+		return true
+	}
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if mimeType == "" {
+		return false
 	}
+	if h.allowedMimeTypes[mimeType] {
+		return true
+	}
+	for _, prefix := range h.allowedMimePrefixes {
+		if strings.HasPrefix(mimeType, prefix) && len(mimeType) > len(prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // getUserFromContext is a helper to retrieve the authenticated user from the context.
@@ -46,7 +153,9 @@ func getUserFromContext(c echo.Context) (*domain.User, error) {
 	return user, nil
 }
 
-// UploadFile handles file uploads from a multipart form.
+// UploadFile handles file uploads from a multipart form. When progress
+// events are enabled, a client that passes ?upload_id=<id> receives
+// websocket.UploadProgress events for that ID while the body arrives.
 func (h *FileHandler) UploadFile(c echo.Context) error {
 	ctx := c.Request().Context()
 	logger := middleware.FromContext(ctx)
@@ -57,11 +166,34 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 		return err
 	}
 
+	// Progress is measured while Bind reads the multipart body.
+	var progress *progressReader
+	if uploadID := c.QueryParam("upload_id"); uploadID != "" && h.progressPublisher != nil {
+		if !uploadIDPattern.MatchString(uploadID) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid upload_id.")
+		}
+		r := c.Request()
+		progress = &progressReader{
+			ReadCloser: r.Body,
+			ctx:        ctx,
+			publisher:  h.progressPublisher,
+			logger:     logger,
+			userID:     user.Email,
+			uploadID:   uploadID,
+			total:      r.ContentLength,
+			interval:   UploadProgressInterval,
+		}
+		r.Body = progress
+	}
+
 	// 1. Bind and Validate the request to our DTO.
 	var req UploadFileRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request format.")
 	}
+	if progress != nil {
+		progress.finish()
+	}
 	if err := c.Validate(&req); err != nil {
 		// The validator will return a user-friendly error.
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -74,25 +206,64 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 	}
 	// 3. Security: Validate MIME type.
 	mimeType := fileHeader.Header.Get("Content-Type")
-	if len(h.allowedMimeTypes) > 0 && !h.allowedMimeTypes[mimeType] {
+	if !h.AllowsMimeType(mimeType) {
 		return c.String(http.StatusUnsupportedMediaType, fmt.Sprintf("File type '%s' is not allowed", mimeType))
 	}
 
+	// 4. Security: Sanitize the filename to prevent path traversal attacks.
+	sanitizedFilename, err := storage.SanitizeFilename(fileHeader.Filename, h.filenameMode)
+	if err != nil {
+		logger.Warn("Rejected unsafe upload filename",
+			slog.String("userID", user.ID.String()),
+			slog.String("filename", fileHeader.Filename))
+		return c.String(http.StatusBadRequest, "Invalid filename")
+	}
+
 	src, err := fileHeader.Open()
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to open uploaded file")
 	}
 	defer src.Close()
 
-	// Create a unique storage path.
-	// Sanitize the filename to prevent path traversal attacks.
-	sanitizedFilename := filepath.Base(fileHeader.Filename)
-	storagePath := filepath.Join("users", user.ID.String(), fmt.Sprintf("%d-%s", time.Now().UnixNano(), sanitizedFilename))
+	// Hash every upload, even with deduplication off, so files stored now can
+	// be matched if it is enabled later.
+	contentHash, err := hashContent(src)
+	if err != nil {
+		logger.Error("Failed to hash uploaded file", slog.String("error", err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to read uploaded file")
+	}
 
-	bytesWritten, err := h.fileStore.Save(ctx, storagePath, src)
+	width, height := imageDimensions(src, mimeType)
+
+	// Compute a unique storage path from the configured template.
+	storagePath, err := h.pathTemplate.Execute(storage.PathData{
+		UserID:      user.ID.String(),
+		FileID:      storage.NewFileID(),
+		Filename:    sanitizedFilename,
+		UploadedAt:  time.Now().UTC(),
+		ContentHash: contentHash,
+	})
 	if err != nil {
-		logger.Error("Failed to save file to storage", slog.String("error", err.Error()))
-		return c.String(http.StatusInternalServerError, "Failed to save file")
+		logger.Warn("Rejected unsafe storage path",
+			slog.String("userID", user.ID.String()),
+			slog.String("error", err.Error()))
+		return c.String(http.StatusBadRequest, "Invalid filename")
+	}
+
+	var bytesWritten int64
+	existing := h.findDuplicate(ctx, user.ID, contentHash)
+	if existing != nil {
+		logger.Info("Deduplicated upload",
+			slog.String("userID", user.ID.String()),
+			slog.String("path", existing.StoragePath))
+		storagePath = existing.StoragePath
+		bytesWritten = existing.Size
+	} else {
+		bytesWritten, err = h.fileStore.Save(ctx, storagePath, src)
+		if err != nil {
+			logger.Error("Failed to save file to storage", slog.String("error", err.Error()))
+			return c.String(http.StatusInternalServerError, "Failed to save file")
+		}
 	}
 
 	// Save metadata to the database.
@@ -102,13 +273,18 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 		MIMEType:    mimeType,
 		Size:        bytesWritten,
 		StoragePath: storagePath,
+		ContentHash: contentHash,
+		Width:       width,
+		Height:      height,
 	}
 
 	createdFile, err := h.fileRepo.Create(ctx, fileMetadata)
 	if err != nil {
 		logger.Error("Failed to save file metadata", slog.String("error", err.Error()))
-		// Attempt to clean up the stored file if metadata saving fails.
-		_ = h.fileStore.Delete(ctx, storagePath)
+		// Clean up the stored file if metadata saving fails. A blob that
+		// other records share, through deduplication or a path template
+		// keyed by content, is kept.
+		h.releaseBlob(ctx, logger, storagePath)
 		return c.String(http.StatusInternalServerError, "Failed to save file metadata")
 	}
 
@@ -120,9 +296,6 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 
 // DeleteFile handles the deletion of a file by its ID.
 func (h *FileHandler) DeleteFile(c echo.Context) error {
-	ctx := c.Request().Context()
-	logger := middleware.FromContext(ctx)
-
 	fileIDParam := c.Param("id")
 	if fileIDParam == "" {
 		return c.String(http.StatusBadRequest, "File ID is required")
@@ -134,37 +307,55 @@ func (h *FileHandler) DeleteFile(c echo.Context) error {
 		return err
 	}
 
+	switch err := h.deleteFile(c.Request().Context(), user, fileIDParam); {
+	case errors.Is(err, errFileNotFound):
+		return c.String(http.StatusNotFound, "File not found")
+	case errors.Is(err, errFileForbidden):
+		return c.String(http.StatusForbidden, "You do not have permission to delete this file")
+	case err != nil:
+		return c.String(http.StatusInternalServerError, "Failed to delete file metadata")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Reasons deleteFile fails, besides storage errors.
+var (
+	errFileNotFound  = errors.New("file not found")
+	errFileForbidden = errors.New("file owned by another user")
+)
+
+// deleteFile deletes one of user's files: its metadata record and, once no
+// other record references it, its blob.
+func (h *FileHandler) deleteFile(ctx context.Context, user *domain.User, fileID string) error {
+	logger := middleware.FromContext(ctx)
+
 	// 1. Get the file metadata to find its storage path and verify ownership.
-	file, err := h.fileRepo.FindByID(ctx, fileIDParam)
+	file, err := h.fileRepo.FindByID(ctx, fileID)
 	if err != nil {
-		logger.Warn("Failed to get file for deletion", slog.String("fileID", fileIDParam), slog.String("error", err.Error()))
-		return c.String(http.StatusNotFound, "File not found")
+		logger.Warn("Failed to get file for deletion", slog.String("fileID", fileID), slog.String("error", err.Error()))
+		return errFileNotFound
 	}
 
 	// 2. Authorization check: Ensure the current user owns the file.
 	if file.UserID == nil || file.UserID.String() != user.ID.String() {
 		logger.Warn("User attempted to delete a file they don't own",
 			slog.String("userID", user.ID.String()),
-			slog.String("fileID", fileIDParam),
+			slog.String("fileID", fileID),
 			slog.String("ownerID", file.UserID.String()))
-		return c.String(http.StatusForbidden, "You do not have permission to delete this file")
+		return errFileForbidden
 	}
 
-	// 3. Delete the physical file from storage.
-	if err := h.fileStore.Delete(ctx, file.StoragePath); err != nil {
-		logger.Error("Failed to delete physical file from storage", slog.String("path", file.StoragePath), slog.String("error", err.Error()))
-		// We continue, to at least remove the database record.
-	}
-
-	// 4. Delete the metadata record from the database.
+	// 3. Delete the metadata record from the database.
 	if err := h.fileRepo.DeleteByID(ctx, file.ID.String()); err != nil {
 		logger.Error("Failed to delete file metadata from database",
 			slog.String("fileID", file.ID.String()),
 			slog.String("error", err.Error()))
-		return c.String(http.StatusInternalServerError, "Failed to delete file metadata")
+		return err
 	}
 
-	return c.NoContent(http.StatusNoContent)
+	// 4. Delete the physical file once no other record references it.
+	h.releaseBlob(ctx, logger, file.StoragePath)
+	return nil
 }
 
 // DownloadFile handles serving a file's content.
@@ -213,6 +404,10 @@ func (h *FileHandler) DownloadFile(c echo.Context) error {
 // ListFiles returns a paginated list of files owned by the authenticated user.
 // Query parameters://   - page: Page number (default: 1)
 //   - page_size: Number of items per page (default: 20, max: 100)
+//   - type: MIME type ("image/png") or family ("image/*")
+//   - q: Case-insensitive filename substring
+//   - sort: created, name or size (default: created)
+//   - order: asc or desc (default: desc, or asc when sorting by name)
 func (h *FileHandler) ListFiles(c echo.Context) error {
 	ctx := c.Request().Context()
 	logger := middleware.FromContext(ctx)
@@ -228,8 +423,18 @@ func (h *FileHandler) ListFiles(c echo.Context) error {
 		logger.Warn("invalid pagination parameters, using defaults", "error", err)
 	}
 
+	filter := domain.FileFilter{
+		MIMEType: c.QueryParam("type"),
+		Search:   c.QueryParam("q"),
+		Sort:     domain.FileSort(c.QueryParam("sort")),
+		Order:    domain.SortOrder(c.QueryParam("order")),
+	}
+	if err := filter.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	// Get paginated files
-	files, total, err := h.fileRepo.FindByUser(ctx, user.ID, params.PageSize, params.Offset())
+	files, total, err := h.fileRepo.SearchByUser(ctx, user.ID, filter, params.PageSize, params.Offset())
 	if err != nil {
 		logger.Error("failed to find files for user", 
 			"user_id", user.ID.String(), 
@@ -255,3 +460,68 @@ func (h *FileHandler) ListFiles(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// hashContent returns the hex-encoded SHA-256 of src and rewinds it so the
+// content can be read again.
+func hashContent(src io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, src); err != nil {
+		return "", err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// findDuplicate returns the user's existing file with the given content hash
+// when deduplication is enabled and its blob is still in storage, or nil.
+func (h *FileHandler) findDuplicate(ctx context.Context, userID *surrealmodels.RecordID, contentHash string) *domain.File {
+	if !h.deduplicate {
+		return nil
+	}
+	logger := middleware.FromContext(ctx)
+
+	existing, err := h.fileRepo.FindByContentHash(ctx, userID, contentHash)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			logger.Warn("Failed to look up duplicate upload, storing a copy", slog.String("error", err.Error()))
+		}
+		return nil
+	}
+	if existing == nil || existing.StoragePath == "" {
+		return nil
+	}
+
+	blob, err := h.fileStore.Get(ctx, existing.StoragePath)
+	if err != nil {
+		logger.Warn("Duplicate upload's blob is missing, storing a copy",
+			slog.String("path", existing.StoragePath),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	_ = blob.Close()
+	return existing
+}
+
+// releaseBlob removes the blob at storagePath once no file record references
+// it. If the references can't be counted the blob is kept, since leaking
+// storage is preferable to deleting content another record still points at.
+func (h *FileHandler) releaseBlob(ctx context.Context, logger *slog.Logger, storagePath string) {
+	refs, err := h.fileRepo.CountByStoragePath(ctx, storagePath)
+	if err != nil {
+		logger.Error("Failed to count references to stored file, keeping it",
+			slog.String("path", storagePath),
+			slog.String("error", err.Error()))
+		return
+	}
+	if refs > 0 {
+		logger.Debug("Stored file is still referenced, keeping it",
+			slog.String("path", storagePath),
+			slog.Int64("references", refs))
+		return
+	}
+	if err := h.fileStore.Delete(ctx, storagePath); err != nil {
+		logger.Error("Failed to delete physical file from storage", slog.String("path", storagePath), slog.String("error", err.Error()))
+	}
+}
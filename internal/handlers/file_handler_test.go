@@ -3,14 +3,21 @@ package handlers_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +27,7 @@ import (
 	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/storage"
 	"github.com/nfrund/goby/internal/testutils"
+	"github.com/nfrund/goby/internal/websocket"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -370,6 +378,39 @@ func TestFileHandler_Upload_Validation(t *testing.T) {
 		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
 		assert.Contains(t, rec.Body.String(), "File size of 2048 bytes exceeds the limit")
 	})
+
+	t.Run("rejects path traversal filename in reject mode", func(t *testing.T) {
+		strictHandler := handlers.NewFileHandler(aferoStore, fileStore, maxSize, allowedTypes,
+			handlers.WithFilenameSanitization(storage.SanitizeReject))
+		e.POST("/upload-strict", strictHandler.UploadFile)
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		// mime/multipart already strips forward-slash directories, so use a
+		// backslash path that survives until the handler's own sanitization.
+		h.Set("Content-Disposition", `form-data; name="file"; filename="..\\..\\etc\\passwd"`)
+		h.Set("Content-Type", "image/png")
+		part, err := writer.CreatePart(h)
+		require.NoError(t, err)
+		_, err = io.WriteString(part, "root:x:0:0")
+		require.NoError(t, err)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/upload-strict", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid filename")
+
+		// Nothing may have been written outside (or inside) the user's directory.
+		exists, err := afero.Exists(memFs, "etc/passwd")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
 }
 
 // TestFileHandler_Authorization verifies that users cannot access files they don't own.
@@ -545,3 +586,598 @@ func TestFileHandler_List(t *testing.T) {
 	assert.Equal(t, "file2.txt", response.Data[0].Filename, "newer file should be first")
 	assert.Equal(t, "file1.txt", response.Data[1].Filename, "older file should be second")
 }
+
+// memFileRepo is a minimal in-memory domain.FileRepository for tests that
+// exercise the upload and delete paths without a database.
+type memFileRepo struct {
+	createErr error // returned by Create when set
+	created   []*domain.File
+	deleted   []string
+	filters   []domain.FileFilter // filters passed to SearchByUser
+}
+
+func (r *memFileRepo) Create(ctx context.Context, file *domain.File) (*domain.File, error) {
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+	file.ID = testutils.NewTestRecordID("file")
+	file.CreatedAt = &surrealmodels.CustomDateTime{Time: time.Now()}
+	r.created = append(r.created, file)
+	return file, nil
+}
+func (r *memFileRepo) Update(ctx context.Context, file *domain.File) (*domain.File, error) {
+	return file, nil
+}
+func (r *memFileRepo) DeleteByID(ctx context.Context, fileID string) error {
+	r.deleted = append(r.deleted, fileID)
+	return nil
+}
+func (r *memFileRepo) FindByID(ctx context.Context, fileID string) (*domain.File, error) {
+	for _, f := range r.live() {
+		if f.ID.String() == fileID {
+			return f, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+func (r *memFileRepo) FindLatestByUser(ctx context.Context, userID *surrealmodels.RecordID) (*domain.File, error) {
+	return nil, domain.ErrNotFound
+}
+func (r *memFileRepo) FindByUser(ctx context.Context, userID *surrealmodels.RecordID, limit, offset int) ([]*domain.File, int64, error) {
+	return nil, 0, nil
+}
+func (r *memFileRepo) SearchByUser(ctx context.Context, userID *surrealmodels.RecordID, filter domain.FileFilter, limit, offset int) ([]*domain.File, int64, error) {
+	r.filters = append(r.filters, filter)
+	files := r.live()
+	return files, int64(len(files)), nil
+}
+func (r *memFileRepo) FindByStoragePath(ctx context.Context, storagePath string) (*domain.File, error) {
+	return nil, domain.ErrNotFound
+}
+func (r *memFileRepo) FindByContentHash(ctx context.Context, userID *surrealmodels.RecordID, contentHash string) (*domain.File, error) {
+	for _, f := range r.live() {
+		if f.UserID.String() == userID.String() && f.ContentHash == contentHash {
+			return f, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+func (r *memFileRepo) CountByStoragePath(ctx context.Context, storagePath string) (int64, error) {
+	var n int64
+	for _, f := range r.live() {
+		if f.StoragePath == storagePath {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// live returns the created records that have not been deleted.
+func (r *memFileRepo) live() []*domain.File {
+	var files []*domain.File
+	for _, f := range r.created {
+		if !slices.Contains(r.deleted, f.ID.String()) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// TestFileHandler_Upload_PathTraversal verifies that a malicious filename is
+// normalized and cannot escape the user's storage directory.
+func TestFileHandler_Upload_PathTraversal(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	aferoStore := storage.NewAferoStore(memFs)
+	repo := &memFileRepo{}
+	user := &domain.User{ID: testutils.NewTestRecordID("user")}
+
+	fileHandler := handlers.NewFileHandler(aferoStore, repo, 1024, []string{"text/plain"})
+	e := echo.New()
+	e.Validator = handlers.NewValidator()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", user)
+			return next(c)
+		}
+	})
+	e.POST("/upload", fileHandler.UploadFile)
+
+	userDir := "users/" + user.ID.String() + "/"
+
+	for _, filename := range []string{`../../etc/passwd`, `..\\..\\etc\\passwd`} {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+		h.Set("Content-Type", "text/plain")
+		part, err := writer.CreatePart(h)
+		require.NoError(t, err)
+		_, err = io.WriteString(part, "root:x:0:0")
+		require.NoError(t, err)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	require.Len(t, repo.created, 2)
+	for _, saved := range repo.created {
+		assert.Equal(t, "passwd", saved.Filename)
+		assert.True(t, strings.HasPrefix(saved.StoragePath, userDir), "storage path %q escaped %q", saved.StoragePath, userDir)
+
+		exists, err := afero.Exists(memFs, saved.StoragePath)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+
+	exists, err := afero.Exists(memFs, "etc/passwd")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// TestFileHandler_AllowsMimeType covers exact and wildcard MIME type matching.
+func TestFileHandler_AllowsMimeType(t *testing.T) {
+	t.Run("wildcard subtype", func(t *testing.T) {
+		h := handlers.NewFileHandler(nil, nil, 0, []string{"image/*"})
+		assert.True(t, h.AllowsMimeType("image/png"))
+		assert.True(t, h.AllowsMimeType("image/jpeg"))
+		assert.True(t, h.AllowsMimeType("IMAGE/PNG"))
+		assert.False(t, h.AllowsMimeType("application/pdf"))
+		assert.False(t, h.AllowsMimeType("image/"))
+		assert.False(t, h.AllowsMimeType("imagex/png"))
+	})
+
+	t.Run("exact entries stay exact", func(t *testing.T) {
+		h := handlers.NewFileHandler(nil, nil, 0, []string{"application/pdf", " image/png "})
+		assert.True(t, h.AllowsMimeType("application/pdf"))
+		assert.True(t, h.AllowsMimeType("image/png"))
+		assert.True(t, h.AllowsMimeType("image/png; charset=binary"))
+		assert.False(t, h.AllowsMimeType("image/jpeg"))
+		assert.False(t, h.AllowsMimeType("application/pdfx"))
+	})
+
+	t.Run("mixed exact and wildcard", func(t *testing.T) {
+		h := handlers.NewFileHandler(nil, nil, 0, []string{"image/*", "application/pdf"})
+		assert.True(t, h.AllowsMimeType("image/gif"))
+		assert.True(t, h.AllowsMimeType("application/pdf"))
+		assert.False(t, h.AllowsMimeType("application/zip"))
+	})
+
+	t.Run("empty list allows all", func(t *testing.T) {
+		for _, allowed := range [][]string{nil, {}, {"", "  "}} {
+			h := handlers.NewFileHandler(nil, nil, 0, allowed)
+			assert.True(t, h.AllowsMimeType("application/x-anything"))
+		}
+	})
+
+	t.Run("star-slash-star allows all", func(t *testing.T) {
+		h := handlers.NewFileHandler(nil, nil, 0, []string{"image/png", "*/*"})
+		assert.True(t, h.AllowsMimeType("application/zip"))
+	})
+
+	t.Run("upload rejects type outside wildcard", func(t *testing.T) {
+		h := handlers.NewFileHandler(storage.NewAferoStore(afero.NewMemMapFs()), &memFileRepo{}, 1024, []string{"image/*"})
+		e := echo.New()
+		e.Validator = handlers.NewValidator()
+		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set("user", &domain.User{ID: testutils.NewTestRecordID("user")})
+				return next(c)
+			}
+		})
+		e.POST("/upload", h.UploadFile)
+
+		upload := func(contentType string) int {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			mh := make(textproto.MIMEHeader)
+			mh.Set("Content-Disposition", `form-data; name="file"; filename="f.bin"`)
+			mh.Set("Content-Type", contentType)
+			part, err := writer.CreatePart(mh)
+			require.NoError(t, err)
+			_, err = io.WriteString(part, "data")
+			require.NoError(t, err)
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusCreated, upload("image/png"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, upload("application/pdf"))
+	})
+}
+
+// TestFileHandler_Deduplication verifies that repeated uploads of the same
+// content share one blob, and that the blob outlives all but the last delete.
+func TestFileHandler_Deduplication(t *testing.T) {
+	newServer := func(opts ...handlers.FileHandlerOption) (*echo.Echo, afero.Fs, *memFileRepo) {
+		memFs := afero.NewMemMapFs()
+		repo := &memFileRepo{}
+		user := &domain.User{ID: testutils.NewTestRecordID("user")}
+
+		fileHandler := handlers.NewFileHandler(storage.NewAferoStore(memFs), repo, 1024, []string{"text/plain"}, opts...)
+		e := echo.New()
+		e.Validator = handlers.NewValidator()
+		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set("user", user)
+				return next(c)
+			}
+		})
+		e.POST("/upload", fileHandler.UploadFile)
+		e.DELETE("/files/:id", fileHandler.DeleteFile)
+		return e, memFs, repo
+	}
+
+	send := func(t *testing.T, e *echo.Echo, filename, content string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+		h.Set("Content-Type", "text/plain")
+		part, err := writer.CreatePart(h)
+		require.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	upload := func(t *testing.T, e *echo.Echo, filename, content string) {
+		rec := send(t, e, filename, content)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	remove := func(t *testing.T, e *echo.Echo, file *domain.File) {
+		req := httptest.NewRequest(http.MethodDelete, "/files/"+file.ID.String(), nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	exists := func(t *testing.T, fs afero.Fs, path string) bool {
+		ok, err := afero.Exists(fs, path)
+		require.NoError(t, err)
+		return ok
+	}
+
+	t.Run("identical uploads share a blob", func(t *testing.T) {
+		e, memFs, repo := newServer(handlers.WithDeduplication(true))
+
+		upload(t, e, "a.txt", "same content")
+		upload(t, e, "b.txt", "same content")
+		upload(t, e, "c.txt", "other content")
+
+		require.Len(t, repo.created, 3)
+		first, second, third := repo.created[0], repo.created[1], repo.created[2]
+		assert.Equal(t, first.StoragePath, second.StoragePath)
+		assert.Equal(t, first.ContentHash, second.ContentHash)
+		assert.Equal(t, "b.txt", second.Filename)
+		assert.Equal(t, int64(len("same content")), second.Size)
+		assert.NotEqual(t, first.StoragePath, third.StoragePath)
+		assert.NotEqual(t, first.ContentHash, third.ContentHash)
+
+		sum := sha256.Sum256([]byte("same content"))
+		assert.Equal(t, hex.EncodeToString(sum[:]), first.ContentHash)
+
+		files, err := afero.ReadDir(memFs, filepath.Dir(first.StoragePath))
+		require.NoError(t, err)
+		assert.Len(t, files, 2, "duplicate content should not be stored twice")
+	})
+
+	t.Run("blob is removed with its last reference", func(t *testing.T) {
+		e, memFs, repo := newServer(handlers.WithDeduplication(true))
+
+		upload(t, e, "a.txt", "same content")
+		upload(t, e, "b.txt", "same content")
+		require.Len(t, repo.created, 2)
+		blob := repo.created[0].StoragePath
+
+		remove(t, e, repo.created[0])
+		assert.True(t, exists(t, memFs, blob), "blob should survive while still referenced")
+
+		remove(t, e, repo.created[1])
+		assert.False(t, exists(t, memFs, blob), "blob should be removed with its last reference")
+	})
+
+	t.Run("uploads are stored separately when disabled", func(t *testing.T) {
+		e, memFs, repo := newServer()
+
+		upload(t, e, "a.txt", "same content")
+		upload(t, e, "b.txt", "same content")
+
+		require.Len(t, repo.created, 2)
+		assert.Equal(t, repo.created[0].ContentHash, repo.created[1].ContentHash)
+		assert.NotEqual(t, repo.created[0].StoragePath, repo.created[1].StoragePath)
+
+		remove(t, e, repo.created[0])
+		assert.False(t, exists(t, memFs, repo.created[0].StoragePath))
+		assert.True(t, exists(t, memFs, repo.created[1].StoragePath))
+	})
+
+	t.Run("failed upload keeps a shared blob", func(t *testing.T) {
+		tmpl, err := storage.ParsePathTemplate("blobs/{{.ContentHash}}")
+		require.NoError(t, err)
+		e, memFs, repo := newServer(handlers.WithPathTemplate(tmpl))
+
+		upload(t, e, "a.txt", "same content")
+		require.Len(t, repo.created, 1)
+		blob := repo.created[0].StoragePath
+
+		repo.createErr = errors.New("database unavailable")
+		rec := send(t, e, "b.txt", "same content")
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.True(t, exists(t, memFs, blob), "a blob another record references must survive")
+	})
+
+	t.Run("missing blob is stored again", func(t *testing.T) {
+		e, memFs, repo := newServer(handlers.WithDeduplication(true))
+
+		upload(t, e, "a.txt", "same content")
+		require.NoError(t, memFs.Remove(repo.created[0].StoragePath))
+
+		upload(t, e, "b.txt", "same content")
+		require.Len(t, repo.created, 2)
+		assert.NotEqual(t, repo.created[0].StoragePath, repo.created[1].StoragePath)
+		assert.True(t, exists(t, memFs, repo.created[1].StoragePath))
+	})
+}
+
+// TestFileHandler_ListFiles_Filters verifies that listing query parameters
+// reach the repository as a FileFilter and that invalid ones are rejected.
+func TestFileHandler_ListFiles_Filters(t *testing.T) {
+	repo := &memFileRepo{}
+	user := &domain.User{ID: testutils.NewTestRecordID("user")}
+	fileHandler := handlers.NewFileHandler(storage.NewAferoStore(afero.NewMemMapFs()), repo, 1024, []string{"text/plain"})
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", user)
+			return next(c)
+		}
+	})
+	e.GET("/files", fileHandler.ListFiles)
+
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files?"+query, nil))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, list("").Code)
+	require.Equal(t, http.StatusOK, list("type=image/*").Code)
+	require.Equal(t, http.StatusOK, list("q=report").Code)
+	require.Equal(t, http.StatusOK, list("sort=size&order=asc").Code)
+	require.Equal(t, http.StatusOK, list("type=application/pdf&q=q1&sort=name&order=desc").Code)
+	assert.Equal(t, []domain.FileFilter{
+		{},
+		{MIMEType: "image/*"},
+		{Search: "report"},
+		{Sort: domain.FileSortSize, Order: domain.SortAsc},
+		{MIMEType: "application/pdf", Search: "q1", Sort: domain.FileSortName, Order: domain.SortDesc},
+	}, repo.filters)
+
+	for _, query := range []string{"sort=owner", "order=up", "type=image", "type=*/*", "type=image/p*g"} {
+		assert.Equal(t, http.StatusBadRequest, list(query).Code, query)
+	}
+	assert.Len(t, repo.filters, 5, "invalid filters never reach the repository")
+}
+
+// TestFileHandler_UploadProgress verifies that uploads with an upload_id
+// report throttled progress to the uploading user's clients.
+func TestFileHandler_UploadProgress(t *testing.T) {
+	aferoStore := storage.NewAferoStore(afero.NewMemMapFs())
+	user := &domain.User{ID: testutils.NewTestRecordID("user"), Email: "uploader@example.com"}
+	publisher := &recordingPublisher{}
+
+	fileHandler := handlers.NewFileHandler(aferoStore, &memFileRepo{}, 0, nil, handlers.WithUploadProgress(publisher))
+	e := echo.New()
+	e.Validator = handlers.NewValidator()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", user)
+			return next(c)
+		}
+	})
+	e.POST("/upload", fileHandler.UploadFile)
+
+	upload := func(query string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "big.bin")
+		require.NoError(t, err)
+		_, err = part.Write(bytes.Repeat([]byte("x"), 1<<20))
+		require.NoError(t, err)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/upload"+query, body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := upload("?upload_id=avatar-1")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// The body is read far faster than the throttle interval, so only the
+	// first and the final event are sent, each to both endpoints.
+	require.Len(t, publisher.messages, 4)
+	for _, msg := range publisher.messages {
+		assert.Equal(t, "uploader@example.com", msg.Metadata["recipient_id"])
+	}
+	assert.Equal(t, websocket.TopicHTMLDirect.Name(), publisher.messages[2].Topic)
+	assert.Equal(t, websocket.TopicDataDirect.Name(), publisher.messages[3].Topic)
+
+	var final websocket.UploadProgress
+	require.NoError(t, json.Unmarshal(publisher.messages[3].Payload, &final))
+	assert.Equal(t, "ws.upload.progress", final.Type)
+	assert.Equal(t, "avatar-1", final.UploadID)
+	assert.True(t, final.Done)
+	assert.Equal(t, 100, final.Percent)
+	assert.Equal(t, final.TotalBytes, final.BytesReceived)
+
+	t.Run("no upload_id sends no progress", func(t *testing.T) {
+		publisher.messages = nil
+		require.Equal(t, http.StatusCreated, upload("").Code)
+		assert.Empty(t, publisher.messages)
+	})
+
+	t.Run("invalid upload_id is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, upload("?upload_id=%3Cscript%3E").Code)
+	})
+}
+
+// TestFileHandler_Thumbnails verifies that listed images carry preview
+// details and serve a scaled thumbnail, while other files carry none.
+func TestFileHandler_Thumbnails(t *testing.T) {
+	repo := &memFileRepo{}
+	user := &domain.User{ID: testutils.NewTestRecordID("user")}
+	fileHandler := handlers.NewFileHandler(storage.NewAferoStore(afero.NewMemMapFs()), repo, 1<<20, nil)
+
+	e := echo.New()
+	e.Validator = handlers.NewValidator()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", user)
+			return next(c)
+		}
+	})
+	e.POST("/upload", fileHandler.UploadFile)
+	e.GET("/app/files", fileHandler.ListFiles)
+	e.GET("/app/files/:id/thumbnail", fileHandler.Thumbnail)
+
+	upload := func(filename, contentType string, content []byte) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+		h.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(h)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 512, 128))))
+	upload("photo.png", "image/png", photo.Bytes())
+	upload("notes.txt", "text/plain", []byte("not an image"))
+	upload("broken.png", "image/png", []byte("not a png either"))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app/files", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listing struct {
+		Data []map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+	require.Len(t, listing.Data, 3)
+
+	photoFile, notes, broken := listing.Data[0], listing.Data[1], listing.Data[2]
+	assert.Equal(t, true, photoFile["has_thumbnail"])
+	assert.Equal(t, "/app/files/"+repo.created[0].ID.String()+"/thumbnail", photoFile["thumbnail_url"])
+	assert.EqualValues(t, 512, photoFile["width"])
+	assert.EqualValues(t, 128, photoFile["height"])
+	for _, file := range []map[string]any{notes, broken} {
+		for _, key := range []string{"has_thumbnail", "thumbnail_url", "width", "height"} {
+			assert.NotContains(t, file, key, "%s has no preview", file["filename"])
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, photoFile["thumbnail_url"].(string), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+	thumb, err := png.DecodeConfig(rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, handlers.ThumbnailSize, thumb.Width, "scaled to fit, keeping the aspect ratio")
+	assert.Equal(t, handlers.ThumbnailSize/4, thumb.Height)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app/files/"+repo.created[1].ID.String()+"/thumbnail", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestFileHandler_DeleteFiles verifies that a batch deletes the requester's
+// files and reports, without aborting, the ones it may not delete.
+func TestFileHandler_DeleteFiles(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	repo := &memFileRepo{}
+	owner := &domain.User{ID: testutils.NewTestRecordID("user")}
+	other := &domain.User{ID: testutils.NewTestRecordID("user")}
+
+	store := func(user *domain.User, path string) *domain.File {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(path), 0644))
+		file, err := repo.Create(context.Background(), &domain.File{UserID: user.ID, Filename: path, StoragePath: path})
+		require.NoError(t, err)
+		return file
+	}
+	mine1, mine2 := store(owner, "mine-1.txt"), store(owner, "mine-2.txt")
+	theirs := store(other, "theirs.txt")
+
+	fileHandler := handlers.NewFileHandler(storage.NewAferoStore(memFs), repo, 0, nil, handlers.WithMaxBatchDelete(4))
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", owner)
+			return next(c)
+		}
+	})
+	e.POST("/files/delete-batch", fileHandler.DeleteFiles)
+
+	deleteBatch := func(ids ...string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(handlers.DeleteFilesRequest{IDs: ids})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/files/delete-batch", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := deleteBatch(mine1.ID.String(), theirs.ID.String(), "file:missing", mine2.ID.String())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp handlers.DeleteFilesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []handlers.FileDeleteResult{
+		{ID: mine1.ID.String(), Deleted: true},
+		{ID: theirs.ID.String(), Error: handlers.BatchDeleteForbidden},
+		{ID: "file:missing", Error: handlers.BatchDeleteNotFound},
+		{ID: mine2.ID.String(), Deleted: true},
+	}, resp.Results)
+	assert.Equal(t, 2, resp.Deleted)
+	assert.Equal(t, 2, resp.Failed)
+
+	assert.ElementsMatch(t, []string{mine1.ID.String(), mine2.ID.String()}, repo.deleted)
+	for path, want := range map[string]bool{"mine-1.txt": false, "mine-2.txt": false, "theirs.txt": true} {
+		exists, err := afero.Exists(memFs, path)
+		require.NoError(t, err)
+		assert.Equal(t, want, exists, path)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, deleteBatch().Code, "an empty batch is rejected")
+	assert.Equal(t, http.StatusBadRequest, deleteBatch("a", "b", "c", "d", "e").Code, "batches over the limit are rejected")
+}
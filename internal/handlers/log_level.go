@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/logging"
+)
+
+// LogLevelGet returns the current minimum log level.
+func LogLevelGet(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{
+		"level": strings.ToLower(logging.Level().String()),
+	})
+}
+
+// LogLevelPut changes the minimum log level at runtime without a restart.
+func LogLevelPut(c echo.Context) error {
+	var req LogLevelRequest
+	if err := c.Bind(&req); err != nil || req.Level == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "level is required",
+		})
+	}
+
+	lvl, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_LOG_LEVEL",
+			Message: "level must be one of debug, info, warn, error",
+		})
+	}
+
+	previous := logging.Level()
+	logging.SetLevel(lvl)
+	slog.Warn("Log level changed at runtime", "from", previous, "to", lvl)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"level": strings.ToLower(lvl.String()),
+	})
+}
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelHandlers(t *testing.T) {
+	original := logging.Level()
+	t.Cleanup(func() { logging.SetLevel(original) })
+	logging.SetLevel(slog.LevelDebug)
+
+	e := echo.New()
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/app/api/admin/log-level", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handlers.LogLevelPut(e.NewContext(req, rec)))
+		return rec
+	}
+
+	t.Run("changes the level", func(t *testing.T) {
+		rec := put(`{"level":"warn"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"level":"warn"}`, rec.Body.String())
+		assert.Equal(t, slog.LevelWarn, logging.Level())
+
+		req := httptest.NewRequest(http.MethodGet, "/app/api/admin/log-level", nil)
+		rec = httptest.NewRecorder()
+		require.NoError(t, handlers.LogLevelGet(e.NewContext(req, rec)))
+		assert.JSONEq(t, `{"level":"warn"}`, rec.Body.String())
+	})
+
+	t.Run("rejects unknown levels", func(t *testing.T) {
+		rec := put(`{"level":"verbose"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "INVALID_LOG_LEVEL")
+		assert.Equal(t, slog.LevelWarn, logging.Level())
+	})
+}
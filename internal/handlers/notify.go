@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/view"
+	"github.com/nfrund/goby/internal/websocket"
+)
+
+// Notifier pushes toast notifications to users' already-loaded pages over the
+// HTML WebSocket. It complements view flash messages, which only reach the
+// next rendered page.
+type Notifier struct {
+	publisher pubsub.Publisher
+}
+
+// NewNotifier creates a new notifier that publishes through publisher.
+func NewNotifier(publisher pubsub.Publisher) *Notifier {
+	return &Notifier{publisher: publisher}
+}
+
+// NotifyUser sends a toast with the given level ("info", "success", "warning"
+// or "error") and text to all of the user's HTML clients.
+func (n *Notifier) NotifyUser(ctx context.Context, userID, level, text string) error {
+	return n.NotifyFlash(ctx, userID, view.FlashMessage{Level: view.FlashLevel(level), Text: text})
+}
+
+// NotifyFlash sends msg as a toast to all of the user's HTML clients. Flash
+// and toast levels are the same, so a message can be flashed for the next
+// page or pushed to open pages without changing its meaning.
+func (n *Notifier) NotifyFlash(ctx context.Context, userID string, msg view.FlashMessage) error {
+	toast, err := websocket.NewToastMessage(userID, websocket.Toast{Level: msg.Level, Text: msg.Text})
+	if err != nil {
+		return err
+	}
+	if err := n.publisher.Publish(ctx, toast); err != nil {
+		return fmt.Errorf("failed to publish toast: %w", err)
+	}
+	return nil
+}
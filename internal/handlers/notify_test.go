@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/view"
+	"github.com/nfrund/goby/internal/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	messages []pubsub.Message
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, msg pubsub.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recordingPublisher) HasSubscribers(topic string) bool { return true }
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestNotifier_NotifyUser(t *testing.T) {
+	publisher := &recordingPublisher{}
+	notifier := handlers.NewNotifier(publisher)
+
+	require.NoError(t, notifier.NotifyUser(context.Background(), "user@example.com", "success", "Export finished"))
+	require.Len(t, publisher.messages, 1)
+
+	msg := publisher.messages[0]
+	assert.Equal(t, websocket.TopicHTMLDirect.Name(), msg.Topic)
+	assert.Equal(t, "user@example.com", msg.Metadata["recipient_id"])
+
+	var toast websocket.Toast
+	require.NoError(t, json.Unmarshal(msg.Payload, &toast))
+	assert.Equal(t, websocket.TopicToast.Name(), toast.Type)
+	assert.Equal(t, websocket.ToastSuccess, toast.Level)
+	assert.Equal(t, "Export finished", toast.Text)
+	assert.Equal(t, websocket.DefaultToastDurationMs, toast.DurationMs)
+}
+
+func TestNotifier_NotifyUser_Invalid(t *testing.T) {
+	publisher := &recordingPublisher{}
+	notifier := handlers.NewNotifier(publisher)
+
+	assert.Error(t, notifier.NotifyUser(context.Background(), "", "info", "hello"), "missing recipient")
+	assert.Error(t, notifier.NotifyUser(context.Background(), "user@example.com", "shout", "hello"), "unknown level")
+	assert.Error(t, notifier.NotifyUser(context.Background(), "user@example.com", "info", ""), "empty text")
+	assert.Empty(t, publisher.messages)
+}
+
+func TestNotifier_NotifyFlash(t *testing.T) {
+	publisher := &recordingPublisher{}
+	notifier := handlers.NewNotifier(publisher)
+
+	msg := view.FlashMessage{Level: view.FlashWarning, Text: "Disk almost full"}
+	require.NoError(t, notifier.NotifyFlash(context.Background(), "user@example.com", msg))
+	require.Len(t, publisher.messages, 1)
+
+	var toast websocket.Toast
+	require.NoError(t, json.Unmarshal(publisher.messages[0].Payload, &toast))
+	assert.Equal(t, websocket.ToastWarning, toast.Level)
+	assert.Equal(t, "Disk almost full", toast.Text)
+}
@@ -105,6 +105,37 @@ func (h *PresenceHandler) GetUserPresence(c echo.Context) error {
 	return c.JSON(http.StatusOK, presence)
 }
 
+// GetMyConnections returns every active connection of the authenticated user,
+// e.g. to show "you're also logged in on another device"
+func (h *PresenceHandler) GetMyConnections(c echo.Context) error {
+	userID, ok := presenceUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "user not authenticated",
+		})
+	}
+
+	if h.presenceService == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": "presence service not available",
+		})
+	}
+
+	connections := h.presenceService.GetUserConnections(userID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user_id":     userID,
+		"connections": connections,
+		"count":       len(connections),
+	})
+}
+
+// DebugSnapshot returns a copy of the presence service's internal state
+// (connections, pending offline events, rate limits, learned patterns and
+// metrics) for operators diagnosing presence problems.
+func (h *PresenceHandler) DebugSnapshot(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.presenceService.DebugSnapshot())
+}
+
 // DebugAddUser manually adds a user for testing (remove in production)
 func (h *PresenceHandler) DebugAddUser(c echo.Context) error {
 	c.Logger().Info("Debug endpoint called")
@@ -143,8 +174,8 @@ func (h *PresenceHandler) HealthCheck(c echo.Context) error {
 
 // Heartbeat handles client heartbeat requests by directly updating presence
 func (h *PresenceHandler) Heartbeat(c echo.Context) error {
-	user, ok := c.Get(middleware.UserContextKey).(*domain.User)
-	if !ok || user == nil {
+	userID, ok := presenceUserID(c)
+	if !ok {
 		return c.JSON(http.StatusUnauthorized, map[string]string{
 			"error": "user not authenticated",
 		})
@@ -178,10 +209,10 @@ func (h *PresenceHandler) Heartbeat(c echo.Context) error {
 	}
 
 	// Directly update presence with client configuration
-	h.presenceService.AddPresenceWithClientConfig(user.Email, clientID, "", clientType, pingIntervalMs, timeoutMultiplier)
+	h.presenceService.AddPresenceWithClientConfig(userID, clientID, "", clientType, pingIntervalMs, timeoutMultiplier)
 
 	c.Logger().Info("Heartbeat received and presence updated",
-		"userID", user.Email,
+		"userID", userID,
 		"clientID", clientID,
 		"clientType", clientType,
 		"pingIntervalMs", pingIntervalMs,
@@ -194,8 +225,8 @@ func (h *PresenceHandler) Heartbeat(c echo.Context) error {
 
 // Offline handles client offline requests by directly updating presence
 func (h *PresenceHandler) Offline(c echo.Context) error {
-	user, ok := c.Get(middleware.UserContextKey).(*domain.User)
-	if !ok || user == nil {
+	userID, ok := presenceUserID(c)
+	if !ok {
 		return c.JSON(http.StatusUnauthorized, map[string]string{
 			"error": "user not authenticated",
 		})
@@ -209,10 +240,10 @@ func (h *PresenceHandler) Offline(c echo.Context) error {
 	}
 
 	// Directly update presence instead of publishing event
-	h.presenceService.RemovePresenceForClient(user.Email, clientID)
+	h.presenceService.RemovePresenceForClient(userID, clientID)
 
 	c.Logger().Info("Offline event received and presence updated",
-		"userID", user.Email,
+		"userID", userID,
 		"clientID", clientID)
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -225,3 +256,12 @@ func (h *PresenceHandler) TestHTML(c echo.Context) error {
 	c.Logger().Info("=== TEST HTML ENDPOINT CALLED ===")
 	return c.HTML(http.StatusOK, `<div>Test HTML Response</div>`)
 }
+
+// presenceUserID returns the presence ID of the caller: the logged-in user's
+// email, or the guest ID on routes that admit guests.
+func presenceUserID(c echo.Context) (string, bool) {
+	if user, ok := c.Get(middleware.UserContextKey).(*domain.User); ok && user != nil {
+		return user.Email, true
+	}
+	return middleware.GuestID(c)
+}
@@ -47,6 +47,11 @@ type UploadFileRequest struct {
 	// Description string `form:"description" validate:"max=500"`
 }
 
+// DeleteFilesRequest is the body of a batch file deletion.
+type DeleteFilesRequest struct {
+	IDs []string `json:"ids"`
+}
+
 // PaginatedResponse represents a paginated API response.
 type PaginatedResponse[T any] struct {
 	Data       []T `json:"data"`
@@ -67,3 +72,13 @@ func NewPaginatedResponse[T any](data []T, total, page, pageSize int) *Paginated
 		TotalPages: totalPages,
 	}
 }
+
+// LogLevelRequest is the body for changing the runtime log level.
+type LogLevelRequest struct {
+	Level string `json:"level" form:"level" validate:"required"`
+}
+
+// TopicEnabledRequest is the body for switching a topic on or off.
+type TopicEnabledRequest struct {
+	Enabled *bool `json:"enabled" form:"enabled" validate:"required"`
+}
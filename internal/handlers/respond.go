@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FormatParam is the query parameter that overrides content negotiation in
+// Respond, e.g. ?format=json.
+const FormatParam = "format"
+
+// Respond writes a 200 response in the format the client asked for: html,
+// rendered with the echo renderer, for browsers and HTMX requests, or data as
+// JSON for API clients. The format comes from ?format=html|json when given,
+// otherwise from the Accept header. HTML is preferred unless JSON is accepted
+// with a higher quality, so plain browser requests and wildcard Accept
+// headers keep getting pages. A nil html always responds with JSON.
+func Respond(c echo.Context, html any, data any) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if html == nil || !wantsHTML(c.Request()) {
+		return c.JSON(http.StatusOK, data)
+	}
+	return c.Render(http.StatusOK, "", html)
+}
+
+// wantsHTML reports whether r should get HTML rather than JSON.
+func wantsHTML(r *http.Request) bool {
+	switch strings.ToLower(r.URL.Query().Get(FormatParam)) {
+	case "html":
+		return true
+	case "json":
+		return false
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		return true
+	}
+
+	accept := r.Header.Get(echo.HeaderAccept)
+	if accept == "" {
+		return true
+	}
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch {
+		case mediaType == echo.MIMETextHTML, mediaType == "application/xhtml+xml":
+			htmlQ = max(htmlQ, q)
+		case mediaType == echo.MIMEApplicationJSON, strings.HasSuffix(mediaType, "+json"):
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return jsonQ == 0 || htmlQ >= jsonQ
+}
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/rendering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespond(t *testing.T) {
+	e := echo.New()
+	e.Renderer = rendering.NewUniversalRenderer()
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "<p>3 items</p>")
+		return err
+	})
+	data := map[string]int{"items": 3}
+
+	respond := func(target, accept string, html any) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set(echo.HeaderAccept, accept)
+		}
+		rec := httptest.NewRecorder()
+		require.NoError(t, handlers.Respond(e.NewContext(req, rec), html, data))
+		return rec
+	}
+	assertHTML := func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+		assert.Equal(t, "<p>3 items</p>", rec.Body.String())
+		assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+	}
+	assertJSON := func(t *testing.T, rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+		assert.JSONEq(t, `{"items":3}`, rec.Body.String())
+		assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+	}
+
+	t.Run("Accept text/html", func(t *testing.T) {
+		assertHTML(t, respond("/items", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", page))
+	})
+	t.Run("Accept application/json", func(t *testing.T) {
+		assertJSON(t, respond("/items", "application/json", page))
+	})
+	t.Run("JSON preferred by quality", func(t *testing.T) {
+		assertJSON(t, respond("/items", "text/html;q=0.5, application/json", page))
+	})
+	t.Run("wildcard and missing Accept get HTML", func(t *testing.T) {
+		assertHTML(t, respond("/items", "*/*", page))
+		assertHTML(t, respond("/items", "", page))
+	})
+	t.Run("format overrides Accept", func(t *testing.T) {
+		assertJSON(t, respond("/items?format=json", "text/html", page))
+		assertHTML(t, respond("/items?format=html", "application/json", page))
+	})
+	t.Run("no component", func(t *testing.T) {
+		assertJSON(t, respond("/items", "text/html", nil))
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/email"
+	"github.com/nfrund/goby/internal/script"
 )
 
 // ErrorResponse is the standard format for API error responses.
@@ -23,11 +25,16 @@ type FileResponse struct {
 	Size        int64     `json:"size"`
 	DownloadURL string    `json:"download_url"`
 	CreatedAt   time.Time `json:"created_at"`
+	// Preview details, set only for images a thumbnail can be made of.
+	HasThumbnail bool   `json:"has_thumbnail,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
 }
 
 // NewFileResponse creates a new FileResponse DTO from a domain.File model.
 func NewFileResponse(file *domain.File) *FileResponse {
-	return &FileResponse{
+	resp := &FileResponse{
 		ID:          file.ID.String(),
 		Filename:    file.Filename,
 		MIMEType:    file.MIMEType,
@@ -35,4 +42,106 @@ func NewFileResponse(file *domain.File) *FileResponse {
 		DownloadURL: fmt.Sprintf("/app/files/%s/download", file.ID.String()),
 		CreatedAt:   file.CreatedAt.Time,
 	}
+	if hasThumbnail(file) {
+		resp.HasThumbnail = true
+		resp.ThumbnailURL = thumbnailURL(file)
+		resp.Width = file.Width
+		resp.Height = file.Height
+	}
+	return resp
+}
+
+// FileDeleteResult is the outcome of deleting one file in a batch. Error is
+// one of the BatchDelete* codes when Deleted is false.
+type FileDeleteResult struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DeleteFilesResponse is the DTO for a batch file deletion.
+type DeleteFilesResponse struct {
+	Results []FileDeleteResult `json:"results"`
+	Deleted int                `json:"deleted"`
+	Failed  int                `json:"failed"`
+}
+
+// EmailStatusResponse is the DTO for a sent email's delivery status.
+type EmailStatusResponse struct {
+	ID                string             `json:"id"`
+	Template          string             `json:"template,omitempty"`
+	Status            domain.EmailStatus `json:"status"`
+	StatusReason      string             `json:"status_reason,omitempty"`
+	ProviderMessageID string             `json:"provider_message_id,omitempty"`
+	UpdatedAt         *time.Time         `json:"updated_at,omitempty"`
+}
+
+// NewEmailStatusResponse creates a new EmailStatusResponse DTO from a domain.EmailMessage model.
+func NewEmailStatusResponse(msg *domain.EmailMessage) *EmailStatusResponse {
+	resp := &EmailStatusResponse{
+		ID:                msg.ID.String(),
+		Template:          msg.Template,
+		Status:            msg.Status,
+		StatusReason:      msg.StatusReason,
+		ProviderMessageID: msg.ProviderMessageID,
+	}
+	if msg.UpdatedAt != nil {
+		resp.UpdatedAt = &msg.UpdatedAt.Time
+	}
+	return resp
+}
+
+// FailedEmailResponse is the DTO for an email the outbound queue gave up on.
+// The body is left out, since it may hold secrets such as reset links.
+type FailedEmailResponse struct {
+	Template string    `json:"template,omitempty"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// NewFailedEmailResponse creates a new FailedEmailResponse DTO from an email.FailedEmail.
+func NewFailedEmailResponse(f email.FailedEmail) *FailedEmailResponse {
+	return &FailedEmailResponse{
+		Template: f.Template,
+		To:       f.To,
+		Subject:  f.Subject,
+		Attempts: f.Attempts,
+		Reason:   f.Reason,
+		FailedAt: f.FailedAt,
+	}
+}
+
+// TopicStateResponse is the DTO for a topic's runtime state.
+type TopicStateResponse struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ScriptResponse is the DTO for a loaded script. Content is only set when a
+// single script is requested.
+type ScriptResponse struct {
+	Module       string    `json:"module"`
+	Name         string    `json:"name"`
+	Language     string    `json:"language"`
+	Source       string    `json:"source"`
+	Checksum     string    `json:"checksum"`
+	Size         int       `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Content      string    `json:"content,omitempty"`
+}
+
+// NewScriptResponse creates a new ScriptResponse DTO from a script.Script, without its content.
+func NewScriptResponse(s *script.Script) *ScriptResponse {
+	return &ScriptResponse{
+		Module:       s.ModuleName,
+		Name:         s.Name,
+		Language:     string(s.Language),
+		Source:       string(s.Source),
+		Checksum:     s.Checksum,
+		Size:         len(s.Content),
+		LastModified: s.LastModified,
+	}
 }
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/script"
+)
+
+// ScriptAdmin is the part of the script engine the script admin endpoints
+// use. *script.Engine implements it.
+type ScriptAdmin interface {
+	GetScriptMetadata() map[string]map[string]script.ScriptMetadata
+	GetScript(moduleName, scriptName string) (*script.Script, error)
+	ReloadScript(moduleName, scriptName string) (*script.Script, error)
+}
+
+// ScriptHandler lets operators inspect and reload scripts at runtime, e.g.
+// in production where the file watcher is disabled.
+type ScriptHandler struct {
+	scripts ScriptAdmin
+}
+
+// NewScriptHandler creates a new ScriptHandler.
+func NewScriptHandler(scripts ScriptAdmin) *ScriptHandler {
+	return &ScriptHandler{scripts: scripts}
+}
+
+// List returns the metadata of every loaded script, sorted by module and name.
+func (h *ScriptHandler) List(c echo.Context) error {
+	var scripts []ScriptResponse
+	for moduleName, moduleScripts := range h.scripts.GetScriptMetadata() {
+		for scriptName, meta := range moduleScripts {
+			scripts = append(scripts, ScriptResponse{
+				Module:       moduleName,
+				Name:         scriptName,
+				Language:     string(meta.Language),
+				Source:       string(meta.Source),
+				Checksum:     meta.Checksum,
+				Size:         meta.Size,
+				LastModified: meta.LastModified,
+			})
+		}
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		if scripts[i].Module != scripts[j].Module {
+			return scripts[i].Module < scripts[j].Module
+		}
+		return scripts[i].Name < scripts[j].Name
+	})
+	if scripts == nil {
+		scripts = []ScriptResponse{}
+	}
+	return c.JSON(http.StatusOK, map[string][]ScriptResponse{"scripts": scripts})
+}
+
+// Get returns a script's metadata and content.
+func (h *ScriptHandler) Get(c echo.Context) error {
+	s, err := h.scripts.GetScript(c.Param("module"), c.Param("name"))
+	if err != nil {
+		return scriptError(c, err)
+	}
+	resp := NewScriptResponse(s)
+	resp.Content = s.Content
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Reload rereads a script from the external scripts directory and returns
+// the version now in use, so the caller can check its checksum.
+func (h *ScriptHandler) Reload(c echo.Context) error {
+	moduleName, scriptName := c.Param("module"), c.Param("name")
+	s, err := h.scripts.ReloadScript(moduleName, scriptName)
+	if err != nil {
+		return scriptError(c, err)
+	}
+	slog.Warn("Script reloaded via admin endpoint",
+		"module", moduleName,
+		"script", scriptName,
+		"checksum", s.Checksum,
+		"language", s.Language,
+		"source", s.Source)
+	return c.JSON(http.StatusOK, NewScriptResponse(s))
+}
+
+// scriptError writes the response for a failed script lookup or reload.
+func scriptError(c echo.Context, err error) error {
+	var scriptErr *script.ScriptError
+	if errors.As(err, &scriptErr) && scriptErr.Type == script.ErrorTypeNotFound {
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "script not found",
+		})
+	}
+	return err
+}
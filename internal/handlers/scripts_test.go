@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptHandler(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "orders", "total.tengo")
+	require.NoError(t, os.MkdirAll(filepath.Dir(scriptPath), 0755))
+	require.NoError(t, os.WriteFile(scriptPath, []byte("result := 1"), 0644))
+
+	engine := script.NewEngine(script.Dependencies{Config: &config.Config{ScriptsDir: dir}})
+	require.NoError(t, engine.Initialize(context.Background(), false))
+	defer engine.Shutdown(context.Background())
+
+	h := handlers.NewScriptHandler(engine)
+	e := echo.New()
+	e.GET("/internal/scripts", h.List)
+	e.GET("/internal/scripts/:module/:name", h.Get)
+	e.POST("/internal/scripts/:module/:name/reload", h.Reload)
+
+	do := func(method, path string) (*httptest.ResponseRecorder, handlers.ScriptResponse) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		var resp handlers.ScriptResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, _ := do(http.MethodGet, "/internal/scripts")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Scripts []handlers.ScriptResponse `json:"scripts"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Scripts, 1)
+	assert.Equal(t, "orders", list.Scripts[0].Module)
+	assert.Equal(t, "total", list.Scripts[0].Name)
+	assert.Empty(t, list.Scripts[0].Content, "the list leaves out content")
+
+	rec, original := do(http.MethodGet, "/internal/scripts/orders/total")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "result := 1", original.Content)
+	assert.Equal(t, "tengo", original.Language)
+
+	require.NoError(t, os.WriteFile(scriptPath, []byte("result := 2"), 0644))
+	rec, reloaded := do(http.MethodPost, "/internal/scripts/orders/total/reload")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, original.Checksum, reloaded.Checksum, "reload returns the new checksum")
+	assert.Equal(t, "external", reloaded.Source)
+
+	rec, current := do(http.MethodGet, "/internal/scripts/orders/total")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "result := 2", current.Content)
+	assert.Equal(t, reloaded.Checksum, current.Checksum)
+
+	rec, _ = do(http.MethodGet, "/internal/scripts/orders/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec, _ = do(http.MethodPost, "/internal/scripts/orders/missing/reload")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/middleware"
+	"golang.org/x/image/draw"
+)
+
+// ThumbnailSize is the largest width or height of a generated thumbnail.
+const ThumbnailSize = 256
+
+// maxThumbnailPixels bounds the images thumbnails are generated for, since
+// decoding holds the whole image in memory (4 bytes per pixel).
+const maxThumbnailPixels = 40_000_000
+
+// thumbnailDecoders are the image formats thumbnails can be made of, by
+// MIME type.
+var thumbnailDecoders = map[string]func(io.Reader) (image.Image, error){
+	"image/png":  png.Decode,
+	"image/jpeg": jpeg.Decode,
+	"image/gif":  gif.Decode,
+}
+
+// thumbnailDecoder returns the decoder for mimeType, ignoring parameters
+// and case, or nil when thumbnails of it aren't supported.
+func thumbnailDecoder(mimeType string) func(io.Reader) (image.Image, error) {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return thumbnailDecoders[strings.ToLower(strings.TrimSpace(mimeType))]
+}
+
+// imageDimensions returns the width and height of an uploaded image, or
+// zeros when mimeType is not an image format thumbnails support or the
+// header can't be read. It rewinds src so the content can be read again.
+func imageDimensions(src io.ReadSeeker, mimeType string) (width, height int) {
+	if thumbnailDecoder(mimeType) == nil {
+		return 0, 0
+	}
+	cfg, _, err := image.DecodeConfig(src)
+	if _, seekErr := src.Seek(0, io.SeekStart); seekErr != nil || err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
+
+// hasThumbnail reports whether Thumbnail can serve a preview of file.
+func hasThumbnail(file *domain.File) bool {
+	return thumbnailDecoder(file.MIMEType) != nil && file.Width > 0 && file.Height > 0 &&
+		int64(file.Width)*int64(file.Height) <= maxThumbnailPixels
+}
+
+// thumbnailURL returns the URL of a file's thumbnail.
+func thumbnailURL(file *domain.File) string {
+	return fmt.Sprintf("/app/files/%s/thumbnail", file.ID.String())
+}
+
+// Thumbnail serves a preview of an image file, scaled to fit within
+// ThumbnailSize pixels. Thumbnails are generated on request; the response
+// may be cached privately since a file's content never changes.
+func (h *FileHandler) Thumbnail(c echo.Context) error {
+	ctx := c.Request().Context()
+	logger := middleware.FromContext(ctx)
+
+	fileIDParam := c.Param("id")
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.fileRepo.FindByID(ctx, fileIDParam)
+	if err != nil {
+		logger.Warn("Failed to get file for thumbnail", slog.String("fileID", fileIDParam), slog.String("error", err.Error()))
+		return c.String(http.StatusNotFound, "File not found")
+	}
+	if file.UserID == nil || file.UserID.String() != user.ID.String() {
+		logger.Warn("User attempted to view a thumbnail of a file they don't own",
+			slog.String("userID", user.ID.String()),
+			slog.String("fileID", fileIDParam),
+			slog.String("ownerID", file.UserID.String()))
+		return c.String(http.StatusForbidden, "You do not have permission to view this file")
+	}
+	if !hasThumbnail(file) {
+		return c.String(http.StatusNotFound, "File has no thumbnail")
+	}
+
+	content, err := h.fileStore.Get(ctx, file.StoragePath)
+	if err != nil {
+		logger.Error("Failed to get physical file from storage", slog.String("path", file.StoragePath), slog.String("error", err.Error()))
+		return c.String(http.StatusInternalServerError, "Could not retrieve file")
+	}
+	defer content.Close()
+
+	thumb, contentType, err := renderThumbnail(content, file.MIMEType)
+	if err != nil {
+		logger.Error("Failed to render thumbnail", slog.String("fileID", fileIDParam), slog.String("error", err.Error()))
+		return c.String(http.StatusInternalServerError, "Could not render thumbnail")
+	}
+	c.Response().Header().Set("Cache-Control", "private, max-age=86400")
+	return c.Blob(http.StatusOK, contentType, thumb)
+}
+
+// renderThumbnail decodes an image and encodes it scaled to fit within
+// ThumbnailSize, as JPEG for photos and PNG otherwise. Images already small
+// enough are re-encoded at their own size.
+func renderThumbnail(src io.Reader, mimeType string) ([]byte, string, error) {
+	img, err := thumbnailDecoder(mimeType)(src)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			width, height = ThumbnailSize, max(1, height*ThumbnailSize/width)
+		} else {
+			width, height = max(1, width*ThumbnailSize/height), ThumbnailSize
+		}
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if strings.Contains(strings.ToLower(mimeType), "jpeg") {
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 80})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buf, scaled)
+	return buf.Bytes(), "image/png", err
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// TopicHandler lets operators switch real-time topics on and off at runtime,
+// as a kill-switch for a noisy or buggy feature that needs no deploy.
+type TopicHandler struct {
+	topics *topicmgr.Manager
+}
+
+// NewTopicHandler creates a new TopicHandler.
+func NewTopicHandler(topics *topicmgr.Manager) *TopicHandler {
+	return &TopicHandler{topics: topics}
+}
+
+// Get returns whether a topic is enabled.
+func (h *TopicHandler) Get(c echo.Context) error {
+	topic, ok := h.topics.Get(c.Param("name"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "topic not found"})
+	}
+	return c.JSON(http.StatusOK, TopicStateResponse{Name: topic.Name(), Enabled: topic.Enabled()})
+}
+
+// SetEnabled switches a topic on or off in this instance.
+func (h *TopicHandler) SetEnabled(c echo.Context) error {
+	var req TopicEnabledRequest
+	if err := c.Bind(&req); err != nil || req.Enabled == nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "enabled is required",
+		})
+	}
+
+	topic, ok := h.topics.Get(c.Param("name"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "topic not found"})
+	}
+	if err := h.topics.SetEnabled(topic.Name(), *req.Enabled); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+	}
+	slog.Warn("Topic toggled at runtime", "topic", topic.Name(), "enabled", *req.Enabled)
+
+	return c.JSON(http.StatusOK, TopicStateResponse{Name: topic.Name(), Enabled: topic.Enabled()})
+}
@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/handlers"
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicHandler(t *testing.T) {
+	manager := topicmgr.NewManager()
+	require.NoError(t, manager.Register(topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:        "wargame.events",
+		Module:      "wargame",
+		Description: "Wargame events",
+		Pattern:     "wargame.events",
+	})))
+	h := handlers.NewTopicHandler(manager)
+	e := echo.New()
+
+	call := func(method, name, body string, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/app/api/admin/topics/"+name, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+		require.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("disables and re-enables a topic", func(t *testing.T) {
+		rec := call(http.MethodPut, "wargame.events", `{"enabled":false}`, h.SetEnabled)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"name":"wargame.events","enabled":false}`, rec.Body.String())
+		assert.False(t, manager.IsEnabled("wargame.events"))
+
+		rec = call(http.MethodGet, "wargame.events", "", h.Get)
+		assert.JSONEq(t, `{"name":"wargame.events","enabled":false}`, rec.Body.String())
+
+		call(http.MethodPut, "wargame.events", `{"enabled":true}`, h.SetEnabled)
+		assert.True(t, manager.IsEnabled("wargame.events"))
+	})
+
+	t.Run("requires enabled", func(t *testing.T) {
+		rec := call(http.MethodPut, "wargame.events", `{}`, h.SetEnabled)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		rec := call(http.MethodPut, "nope", `{"enabled":false}`, h.SetEnabled)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(http.MethodGet, "nope", "", h.Get)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
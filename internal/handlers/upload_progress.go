@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/websocket"
+)
+
+// UploadProgressInterval is the minimum time between two progress events for
+// one upload, so a fast upload doesn't flood the user's sockets.
+const UploadProgressInterval = 250 * time.Millisecond
+
+// uploadIDPattern restricts client-chosen upload IDs to short, inert tokens.
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// WithUploadProgress makes uploads that carry an upload_id query parameter
+// publish websocket.UploadProgress events to the uploading user's clients as
+// the request body arrives. A nil publisher disables progress events.
+func WithUploadProgress(publisher pubsub.Publisher) FileHandlerOption {
+	return func(h *FileHandler) {
+		h.progressPublisher = publisher
+	}
+}
+
+// progressReader counts the bytes read from an upload body and publishes
+// throttled progress events. The final event is sent at EOF or by finish,
+// since multipart parsing may stop before reading the body to its end.
+type progressReader struct {
+	io.ReadCloser
+	ctx       context.Context
+	publisher pubsub.Publisher
+	logger    *slog.Logger
+	userID    string
+	uploadID  string
+	total     int64
+	interval  time.Duration
+
+	received int64
+	lastSent time.Time
+	done     bool
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.received += int64(n)
+	switch {
+	case err == io.EOF || (r.total > 0 && r.received >= r.total):
+		r.finish()
+	case n > 0 && time.Since(r.lastSent) >= r.interval:
+		r.publish()
+	}
+	return n, err
+}
+
+// finish sends the final event, once.
+func (r *progressReader) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.publish()
+}
+
+func (r *progressReader) publish() {
+	r.lastSent = time.Now()
+	progress := websocket.UploadProgress{
+		UploadID:      r.uploadID,
+		BytesReceived: r.received,
+		Done:          r.done,
+	}
+	if r.total > 0 {
+		progress.TotalBytes = r.total
+		progress.Percent = int(min(r.received*100/r.total, 100))
+	}
+
+	msgs, err := websocket.NewUploadProgressMessages(r.userID, progress)
+	if err != nil {
+		r.logger.Warn("Failed to build upload progress", slog.String("error", err.Error()))
+		return
+	}
+	for _, msg := range msgs {
+		// Progress is best effort; a lost event must not fail the upload.
+		if err := r.publisher.Publish(r.ctx, msg); err != nil {
+			r.logger.Debug("Failed to publish upload progress",
+				slog.String("uploadID", r.uploadID),
+				slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package logging
+
+import "log/slog"
+
+// Attribute keys shared by every component, so a user, client or topic can be
+// followed across the WebSocket bridge, presence and pub/sub logs with a
+// single query (e.g. client_id="abc123").
+const (
+	KeyUserID   = "user_id"
+	KeyClientID = "client_id"
+	KeyTopic    = "topic"
+)
+
+// UserID returns the attribute for a user's ID.
+func UserID(id string) slog.Attr {
+	return slog.String(KeyUserID, id)
+}
+
+// ClientID returns the attribute for a WebSocket client (connection) ID.
+func ClientID(id string) slog.Attr {
+	return slog.String(KeyClientID, id)
+}
+
+// Topic returns the attribute for a pub/sub topic name.
+func Topic(name string) slog.Attr {
+	return slog.String(KeyTopic, name)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawCorrelationKey matches a user, client or topic attribute written as a
+// literal key/value pair in a log call instead of via the helpers.
+var rawCorrelationKey = regexp.MustCompile(`"(userID|user_id|userId|clientID|client_id|clientId|topic|recipient)",\s*[A-Za-z_]`)
+
+// TestCorrelationAttrsUseHelpers keeps the bridge and presence service logging
+// user, client and topic attributes through UserID, ClientID and Topic, so
+// their keys stay queryable.
+func TestCorrelationAttrsUseHelpers(t *testing.T) {
+	for _, dir := range []string{"../websocket", "../presence"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		require.NotEmpty(t, files, dir)
+
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			src, err := os.ReadFile(file)
+			require.NoError(t, err)
+			for i, line := range strings.Split(string(src), "\n") {
+				if m := rawCorrelationKey.FindStringSubmatch(line); m != nil {
+					t.Errorf("%s:%d: log attribute %q should use logging.UserID, logging.ClientID or logging.Topic", file, i+1, m[1])
+				}
+			}
+		}
+	}
+}
+
+func TestAttrKeys(t *testing.T) {
+	assert.Equal(t, "user_id", UserID("u1").Key)
+	assert.Equal(t, "client_id", ClientID("c1").Key)
+	assert.Equal(t, "topic", Topic("chat.messages").Key)
+	assert.Equal(t, "c1", ClientID("c1").Value.String())
+}
@@ -1,32 +1,71 @@
 package logging
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"log/slog"
 	"os"
+	"strings"
+
+	"github.com/nfrund/goby/internal/config"
 )
 
+// level is shared by every handler created by New so the minimum level can be
+// changed at runtime with SetLevel.
+var level = new(slog.LevelVar)
+
 // New initializes a new slog logger and sets it as the default.
-// It reads the LOG_FORMAT environment variable to determine the output format.
-// Defaults to "text" for development, can be set to "json" for production.
-func New() {
-	logFormat := os.Getenv("LOG_FORMAT")
-	if logFormat == "" {
-		logFormat = "text" // Default to text for development
+// The output format ("text" or "json") and minimum level come from the
+// LOG_FORMAT and LOG_LEVEL settings. Text output is the default for
+// development; use json for production.
+func New(cfg config.Provider) {
+	lvl, err := ParseLevel(cfg.GetLogLevel())
+	if err != nil {
+		lvl = slog.LevelDebug
+		defer slog.Warn("Invalid LOG_LEVEL, defaulting to debug", "value", cfg.GetLogLevel())
 	}
+	level.Set(lvl)
 
 	var handler slog.Handler
-	switch logFormat {
+	switch strings.ToLower(cfg.GetLogFormat()) {
 	case "json":
 		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelDebug, // Or read from env
+			Level: level,
 		})
 	default:
 		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level:     slog.LevelDebug, // Or read from env
-			AddSource: true,            // Adds source file and line number
+			Level:     level,
+			AddSource: true, // Adds source file and line number
 		})
 	}
 
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 }
+
+// NewSilent discards all log output, including the standard library logger.
+// It is intended for CLIs whose output should not be mixed with logs.
+func NewSilent() {
+	log.SetOutput(io.Discard)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// ParseLevel converts a level name ("debug", "info", "warn", "error") to a slog.Level.
+func ParseLevel(name string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", name)
+	}
+	return lvl, nil
+}
+
+// Level returns the current minimum log level.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the minimum log level of the logger created by New at runtime.
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+)
+
+// RequireAdmin creates a middleware that restricts operator endpoints to the
+// users whose emails are listed in admins. It must run after Auth. Requests
+// from anyone else, including every user when admins is empty, get
+// 403 Forbidden.
+func RequireAdmin(admins []string) echo.MiddlewareFunc {
+	allowed := make(map[string]struct{}, len(admins))
+	for _, email := range admins {
+		allowed[strings.ToLower(strings.TrimSpace(email))] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get(UserContextKey).(*domain.User)
+			if ok && user != nil {
+				if _, ok := allowed[strings.ToLower(user.Email)]; ok {
+					return next(c)
+				}
+			}
+			slog.Warn("Refused non-admin request to an operator endpoint",
+				"method", c.Request().Method,
+				"path", c.Path())
+			return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+
+	serve := func(admins []string, user *domain.User) int {
+		e := echo.New()
+		e.GET("/admin", handler, func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				if user != nil {
+					c.Set(UserContextKey, user)
+				}
+				return next(c)
+			}
+		}, RequireAdmin(admins))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return rec.Code
+	}
+
+	admins := []string{"ops@example.com"}
+
+	t.Run("admits listed users", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(admins, &domain.User{Email: "Ops@Example.com"}))
+	})
+
+	t.Run("refuses other users", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(admins, &domain.User{Email: "user@example.com"}))
+	})
+
+	t.Run("refuses requests without a user", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(admins, nil))
+	})
+
+	t.Run("refuses everyone when no admins are configured", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(nil, &domain.User{Email: "ops@example.com"}))
+	})
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLimit creates a middleware that rejects request bodies larger than
+// limit bytes with 413 Request Entity Too Large. A limit of zero or less
+// means no limit.
+//
+// The body is checked as it is read, so an oversized body is never read in
+// full: a request whose Content-Length exceeds the limit fails on the first
+// read, and one that doesn't declare its length fails once it passes the
+// limit. Handlers see the failure as an error from Bind or from reading the
+// body, and the middleware replaces whatever error they return with 413.
+//
+// The server applies BodyLimit to every route. Applied again to a route or
+// group, it replaces that limit for those routes, so a module can accept
+// larger bodies where it needs to:
+//
+//	g.POST("/import", h.Import, middleware.BodyLimit(50<<20))
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			// An outer BodyLimit already wraps the body; override its limit
+			// and let it report the failure.
+			if body, ok := r.Body.(*limitedBody); ok {
+				body.limit = limit
+				return next(c)
+			}
+			if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+				return next(c)
+			}
+
+			body := &limitedBody{ReadCloser: r.Body, limit: limit, length: r.ContentLength}
+			r.Body = body
+			err := next(c)
+			if body.exceeded && !c.Response().Committed {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("Request body exceeds the limit of %d bytes.", body.limit))
+			}
+			return err
+		}
+	}
+}
+
+// limitedBody fails reads once more than limit bytes have been read, or
+// right away if the declared length is over the limit. A limit of zero or
+// less disables the check.
+type limitedBody struct {
+	io.ReadCloser
+	limit    int64
+	length   int64 // Content-Length, -1 if unknown
+	read     int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.ReadCloser.Read(p)
+	}
+	if b.exceeded || b.length > b.limit {
+		b.exceeded = true
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a longer one.
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		n -= int(b.read - b.limit)
+		b.read = b.limit
+		b.exceeded = true
+		return n, &http.MaxBytesError{Limit: b.limit}
+	}
+	return n, err
+}
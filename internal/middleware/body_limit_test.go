@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	e := echo.New()
+	e.Use(BodyLimit(64))
+
+	var bound map[string]string
+	action := func(c echo.Context) error {
+		bound = nil
+		if err := c.Bind(&bound); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid request format.")
+		}
+		return c.JSON(http.StatusOK, bound)
+	}
+	e.POST("/action", action)
+	e.POST("/import", action, BodyLimit(1024))
+	e.POST("/unlimited", action, BodyLimit(0))
+
+	post := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		var r io.Reader = strings.NewReader(body)
+		if chunked {
+			// Hide the length so the limit is only hit while reading.
+			r = io.MultiReader(r)
+		}
+		req := httptest.NewRequest(http.MethodPost, path, r)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	jsonBody := func(size int) string {
+		return `{"text":"` + strings.Repeat("a", size-len(`{"text":""}`)) + `"}`
+	}
+
+	t.Run("body within the limit", func(t *testing.T) {
+		rec := post("/action", jsonBody(64), false)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, bound["text"], 64-len(`{"text":""}`))
+	})
+
+	t.Run("oversized JSON body is rejected with 413", func(t *testing.T) {
+		rec := post("/action", jsonBody(65), false)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Nil(t, bound)
+	})
+
+	t.Run("oversized body without a length is rejected with 413", func(t *testing.T) {
+		rec := post("/action", jsonBody(4096), true)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("route raises the limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("/import", jsonBody(1024), true).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, post("/import", jsonBody(1025), false).Code)
+	})
+
+	t.Run("route disables the limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("/unlimited", jsonBody(4096), false).Code)
+	})
+}
+
+func TestLimitedBody_StopsReadingPastTheLimit(t *testing.T) {
+	src := strings.NewReader(strings.Repeat("a", 1<<20))
+	body := &limitedBody{ReadCloser: io.NopCloser(src), limit: 100, length: -1}
+
+	data, err := io.ReadAll(body)
+	var maxErr *http.MaxBytesError
+	assert.ErrorAs(t, err, &maxErr)
+	assert.Len(t, data, 100)
+	assert.Greater(t, src.Len(), 1<<20-200, "the rest of the body is left unread")
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+)
+
+// GuestCookieName holds a guest's ID so it stays the same across reconnects
+// and page loads. It is a session cookie: the ID ends with the browser session.
+const GuestCookieName = "guest_id"
+
+// GuestContextKey holds the guest ID of a request OptionalAuth admitted
+// without a logged-in user.
+const GuestContextKey = "guest_id"
+
+// OptionalAuth authenticates the request like Auth when it carries a valid
+// auth token. Otherwise, instead of redirecting to the login page, it admits
+// the request as a guest with a stable ID from the guest cookie, issuing one
+// if needed. Handlers check UserContextKey first and fall back to GuestID.
+func OptionalAuth(store domain.UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cookie, err := c.Cookie("auth_token"); err == nil && cookie.Value != "" {
+				if user, err := store.Authenticate(c.Request().Context(), cookie.Value); err == nil && user != nil {
+					c.Set(UserContextKey, user)
+					return next(c)
+				}
+			}
+
+			c.Set(GuestContextKey, guestID(c))
+			return next(c)
+		}
+	}
+}
+
+// GuestID returns the guest ID OptionalAuth assigned to the request, if the
+// request is from a guest.
+func GuestID(c echo.Context) (string, bool) {
+	id, ok := c.Get(GuestContextKey).(string)
+	return id, ok && id != ""
+}
+
+// guestID returns the ID in the guest cookie, or issues a new one.
+func guestID(c echo.Context) string {
+	if cookie, err := c.Cookie(GuestCookieName); err == nil && domain.ValidGuestID(cookie.Value) {
+		return cookie.Value
+	}
+
+	id := domain.NewGuestID()
+	c.SetCookie(&http.Cookie{
+		Name:     GuestCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
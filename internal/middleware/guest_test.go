@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenStore authenticates a single token; other UserRepository methods are unused.
+type tokenStore struct {
+	domain.UserRepository
+	token string
+	user  *domain.User
+}
+
+func (s tokenStore) Authenticate(_ context.Context, token string) (*domain.User, error) {
+	if token == s.token {
+		return s.user, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func TestOptionalAuth(t *testing.T) {
+	store := tokenStore{token: "good", user: &domain.User{Email: "user@example.com"}}
+
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		if user, ok := c.Get(UserContextKey).(*domain.User); ok {
+			return c.String(http.StatusOK, user.Email)
+		}
+		id, ok := GuestID(c)
+		require.True(t, ok)
+		return c.String(http.StatusOK, id)
+	}, OptionalAuth(store))
+
+	serve := func(cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("valid token authenticates the user", func(t *testing.T) {
+		rec := serve(&http.Cookie{Name: "auth_token", Value: "good"})
+		assert.Equal(t, "user@example.com", rec.Body.String())
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("anonymous request is issued a guest ID", func(t *testing.T) {
+		rec := serve()
+		id := rec.Body.String()
+		assert.True(t, domain.ValidGuestID(id))
+
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, GuestCookieName, cookies[0].Name)
+		assert.Equal(t, id, cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("invalid token falls back to a guest", func(t *testing.T) {
+		rec := serve(&http.Cookie{Name: "auth_token", Value: "bad"})
+		assert.True(t, domain.ValidGuestID(rec.Body.String()))
+	})
+
+	t.Run("guest cookie keeps the ID stable", func(t *testing.T) {
+		id := domain.NewGuestID()
+		rec := serve(&http.Cookie{Name: GuestCookieName, Value: id})
+		assert.Equal(t, id, rec.Body.String())
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("malformed guest cookie is replaced", func(t *testing.T) {
+		rec := serve(&http.Cookie{Name: GuestCookieName, Value: "guest:not-a-uuid"})
+		id := rec.Body.String()
+		assert.NotEqual(t, "guest:not-a-uuid", id)
+		assert.True(t, domain.ValidGuestID(id))
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HealthChecker reports whether a backing dependency (e.g. the database) is
+// currently usable. *database.Connection satisfies this interface.
+type HealthChecker interface {
+	IsHealthy() bool
+}
+
+// RequireHealthy creates a middleware that short-circuits requests with
+// 503 Service Unavailable while the given dependency is unhealthy. It is used
+// to guard database-backed routes when the server runs in degraded mode.
+// A nil checker disables the guard.
+func RequireHealthy(checker HealthChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if checker != nil && !checker.IsHealthy() {
+				c.Response().Header().Set("Retry-After", "5")
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "Service temporarily unavailable")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHealth struct {
+	healthy atomic.Bool
+}
+
+func (f *fakeHealth) IsHealthy() bool { return f.healthy.Load() }
+
+func TestRequireHealthy(t *testing.T) {
+	e := echo.New()
+	checker := &fakeHealth{}
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+	e.GET("/guarded", handler, RequireHealthy(checker))
+	e.GET("/unguarded", handler, RequireHealthy(nil))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("returns 503 while unhealthy", func(t *testing.T) {
+		rec := serve("/guarded")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("passes through once healthy", func(t *testing.T) {
+		checker.healthy.Store(true)
+		rec := serve("/guarded")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("nil checker disables the guard", func(t *testing.T) {
+		rec := serve("/unguarded")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
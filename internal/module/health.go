@@ -0,0 +1,35 @@
+package module
+
+import "context"
+
+// Severity grades a condition a module reports about itself.
+type Severity string
+
+const (
+	// SeverityOK means the module is working normally.
+	SeverityOK Severity = "ok"
+	// SeverityDegraded means the module still serves requests, but worse
+	// than usual, e.g. because an external API is slow. The server stays
+	// ready and reports the condition.
+	SeverityDegraded Severity = "degraded"
+	// SeverityCritical means the module cannot serve requests; the server
+	// reports itself not ready until the condition clears.
+	SeverityCritical Severity = "critical"
+)
+
+// HealthCondition is one condition a module reports to the readiness check.
+type HealthCondition struct {
+	Severity Severity
+	// Message describes the condition for dashboards and operators, e.g.
+	// "payment API latency above 2s".
+	Message string
+}
+
+// HealthReporter is an optional interface for modules that contribute to the
+// server's readiness endpoint. Health is called on every readiness request,
+// so it should return quickly, using state the module tracks rather than
+// probing dependencies; ctx carries the request deadline. Returning no
+// conditions means the module is healthy.
+type HealthReporter interface {
+	Health(ctx context.Context) []HealthCondition
+}
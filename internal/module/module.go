@@ -2,6 +2,10 @@ package module
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/registry"
@@ -33,6 +37,37 @@ type ClientActionRegistrar interface {
 	RegisterClientActions(htmlBridge, dataBridge *websocket.Bridge)
 }
 
+// RoutePrefixer is an optional interface that modules can implement to be
+// mounted at a URL prefix other than their name, under /app. A prefix of "/"
+// mounts the module at /app itself, so a home module can own the root; a
+// prefix such as "/api/v2/inventory" exposes a versioned API.
+type RoutePrefixer interface {
+	RoutePrefix() string
+}
+
+// ErrInvalidRoutePrefix is returned by RoutePrefix for prefixes that can't
+// be mounted.
+var ErrInvalidRoutePrefix = errors.New("invalid route prefix")
+
+// RoutePrefix returns the normalized prefix mod is mounted at: its
+// RoutePrefix if it implements RoutePrefixer, otherwise "/" and its name.
+// Prefixes start with a slash and, except for the root, don't end with one;
+// "." and ".." segments are resolved. Path parameters and wildcards are not
+// allowed.
+func RoutePrefix(mod Module) (string, error) {
+	prefix := "/" + mod.Name()
+	if p, ok := mod.(RoutePrefixer); ok {
+		prefix = strings.TrimSpace(p.RoutePrefix())
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("%w: empty prefix", ErrInvalidRoutePrefix)
+	}
+	if strings.ContainsAny(prefix, ":* \t?#") || strings.Contains(prefix, "//") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRoutePrefix, prefix)
+	}
+	return path.Clean("/" + prefix), nil
+}
+
 // BaseModule provides default no-op implementations for Module methods.
 // Modules can embed this to avoid implementing methods they don't need.
 type BaseModule struct{}
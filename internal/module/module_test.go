@@ -0,0 +1,41 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// namedModule mounts at its name.
+type namedModule struct{ BaseModule }
+
+func (m *namedModule) Name() string { return "inventory" }
+
+// prefixedModule mounts at prefix.
+type prefixedModule struct {
+	namedModule
+	prefix string
+}
+
+func (m *prefixedModule) RoutePrefix() string { return m.prefix }
+
+func TestRoutePrefix(t *testing.T) {
+	for _, tc := range []struct{ prefix, want string }{
+		{"/", "/"},
+		{"api/v2/inventory", "/api/v2/inventory"},
+		{" /stock/ ", "/stock"},
+	} {
+		got, err := RoutePrefix(&prefixedModule{prefix: tc.prefix})
+		assert.NoError(t, err, tc.prefix)
+		assert.Equal(t, tc.want, got, tc.prefix)
+	}
+
+	got, err := RoutePrefix(&namedModule{})
+	assert.NoError(t, err)
+	assert.Equal(t, "/inventory", got, "modules without RoutePrefix mount at their name")
+
+	for _, prefix := range []string{"", "  ", "/items/:id", "/files/*", "/a//b", "/a b"} {
+		_, err := RoutePrefix(&prefixedModule{prefix: prefix})
+		assert.ErrorIs(t, err, ErrInvalidRoutePrefix, prefix)
+	}
+}
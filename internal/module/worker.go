@@ -0,0 +1,124 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Worker is a periodic background task the framework runs for a module, so
+// modules need not manage tickers and goroutines themselves.
+type Worker struct {
+	// Name identifies the worker in logs.
+	Name string
+	// Interval is the time between runs; the first run happens one interval
+	// after the worker starts.
+	Interval time.Duration
+	// Run does one unit of work. Its context ends when the worker is stopped.
+	// Errors and panics are logged and the worker keeps its schedule.
+	Run func(ctx context.Context) error
+}
+
+// WorkerProvider is an optional interface for modules with periodic tasks.
+// The server starts the returned workers once the module has booted and stops
+// them before calling its Shutdown.
+type WorkerProvider interface {
+	Workers() []Worker
+}
+
+// WorkerGroup runs one module's workers and stops them together.
+type WorkerGroup struct {
+	module  string
+	workers []Worker
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorkerGroup creates a group for the named module; call Start to begin
+// running the workers.
+func NewWorkerGroup(module string, workers ...Worker) *WorkerGroup {
+	return &WorkerGroup{
+		module:  module,
+		workers: workers,
+		logger:  slog.Default().With("module", module),
+	}
+}
+
+// Start runs each worker on its interval until ctx ends or Stop is called.
+// Workers with no Run function or a non-positive interval are skipped.
+// Calling Start again while the group is running has no effect.
+func (g *WorkerGroup) Start(ctx context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancel != nil {
+		return
+	}
+
+	ctx, g.cancel = context.WithCancel(ctx)
+	for _, w := range g.workers {
+		if w.Run == nil || w.Interval <= 0 {
+			g.logger.Error("Skipping worker with no task or interval", "worker", w.Name, "interval", w.Interval)
+			continue
+		}
+		g.wg.Add(1)
+		go g.loop(ctx, w)
+	}
+}
+
+// Stop cancels the workers and waits for running tasks to return. If ctx
+// ends first, Stop returns its error and the tasks finish in the background.
+func (g *WorkerGroup) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	cancel := g.cancel
+	g.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers of module %s did not stop: %w", g.module, ctx.Err())
+	}
+}
+
+func (g *WorkerGroup) loop(ctx context.Context, w Worker) {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.run(ctx, w); err != nil {
+				g.logger.Error("Worker failed", "worker", w.Name, "error", err)
+			}
+		}
+	}
+}
+
+// run calls the worker's task once, logging and recovering a panic so one
+// faulty run does not stop the worker or crash the process.
+func (g *WorkerGroup) run(ctx context.Context, w Worker) error {
+	defer func() {
+		if r := recover(); r != nil {
+			g.logger.Error("Recovered panic in worker", "worker", w.Name, "panic", r, "stack_trace", string(debug.Stack()))
+		}
+	}()
+	return w.Run(ctx)
+}
@@ -0,0 +1,97 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerGroup_RunsOnIntervalAndStops(t *testing.T) {
+	var runs atomic.Int32
+	g := NewWorkerGroup("test", Worker{
+		Name:     "counter",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	g.Start(context.Background())
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, g.Stop(context.Background()))
+
+	stopped := runs.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load(), "a stopped worker must not run again")
+}
+
+func TestWorkerGroup_SurvivesErrorsAndPanics(t *testing.T) {
+	var runs atomic.Int32
+	g := NewWorkerGroup("test", Worker{
+		Name:     "flaky",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			switch runs.Add(1) {
+			case 1:
+				return errors.New("temporary failure")
+			case 2:
+				panic("boom")
+			}
+			return nil
+		},
+	})
+	g.Start(context.Background())
+	defer g.Stop(context.Background())
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWorkerGroup_StopWaitsForRunningTask(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	g := NewWorkerGroup("test", Worker{
+		Name:     "slow",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			finished.Store(true)
+			return ctx.Err()
+		},
+	})
+	g.Start(context.Background())
+	<-started
+
+	require.NoError(t, g.Stop(context.Background()))
+	assert.True(t, finished.Load(), "Stop returns only after the task has seen the cancellation")
+}
+
+func TestWorkerGroup_StopTimesOut(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g := NewWorkerGroup("test", Worker{
+		Name:     "stuck",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		},
+	})
+	g.Start(context.Background())
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, g.Stop(ctx), context.DeadlineExceeded)
+}
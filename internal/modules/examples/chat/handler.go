@@ -1,7 +1,6 @@
 package chat
 
 import (
-	"encoding/json"
 	"log/slog"
 	"net/http"
 
@@ -60,19 +59,12 @@ func (h *Handler) MessagePost(c echo.Context) error {
 		User:    user.Email,
 	}
 
-	payload, err := json.Marshal(msg)
-	if err != nil {
-		slog.Error("Failed to marshal chat message", "error", err)
+	// Publish to the chat.messages topic using the typed topic
+	if err := pubsub.PublishJSON(c.Request().Context(), h.publisher, Messages, user.Email, msg); err != nil {
+		slog.Error("Failed to publish chat message", "error", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	// Publish to the chat.messages topic using the typed topic
-	h.publisher.Publish(c.Request().Context(), pubsub.Message{
-		Topic:   Messages.Name(),
-		UserID:  user.Email,
-		Payload: payload,
-	})
-
 	return c.NoContent(http.StatusOK)
 }
 
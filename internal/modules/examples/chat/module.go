@@ -2,10 +2,12 @@ package chat
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
 	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/module"
 	"github.com/nfrund/goby/internal/modules/examples/chat/templates/components"
@@ -37,8 +39,16 @@ type ChatModule struct {
 	renderer        rendering.Renderer
 	topicMgr        *topicmgr.Manager
 	presenceService *presence.Service
+	// maxMessageLength limits chat message content in characters; 0 disables it.
+	maxMessageLength int
+
+	chatSubscriber     *ChatSubscriber
+	presenceSubscriber *PresenceSubscriber
 }
 
+// defaultMaxMessageLength is used when CHAT_MAX_MESSAGE_LENGTH is not set.
+const defaultMaxMessageLength = 1000
+
 // Dependencies holds all the services that the ChatModule requires to operate.
 // This struct is used for constructor injection to make dependencies explicit.
 type Dependencies struct {
@@ -47,16 +57,24 @@ type Dependencies struct {
 	Renderer        rendering.Renderer
 	TopicMgr        *topicmgr.Manager
 	PresenceService *presence.Service
+	// Config is scoped to the CHAT_ env prefix. It is optional.
+	Config config.Provider
 }
 
 // New creates a new instance of the ChatModule, injecting its dependencies.
 func New(deps Dependencies) *ChatModule {
+	maxMessageLength := defaultMaxMessageLength
+	if deps.Config != nil {
+		maxMessageLength = deps.Config.GetInt("MAX_MESSAGE_LENGTH", defaultMaxMessageLength)
+	}
+
 	return &ChatModule{
-		publisher:       deps.Publisher,
-		subscriber:      deps.Subscriber,
-		renderer:        deps.Renderer,
-		topicMgr:        deps.TopicMgr,
-		presenceService: deps.PresenceService,
+		publisher:        deps.Publisher,
+		subscriber:       deps.Subscriber,
+		renderer:         deps.Renderer,
+		topicMgr:         deps.TopicMgr,
+		presenceService:  deps.PresenceService,
+		maxMessageLength: maxMessageLength,
 	}
 }
 
@@ -74,11 +92,18 @@ func (m *ChatModule) Register(reg *registry.Registry) error {
 	return nil
 }
 
-// Shutdown is called on application termination.
+// Shutdown is called on application termination. It stops the module's
+// subscribers and waits for messages they are handling.
 func (m *ChatModule) Shutdown(ctx context.Context) error {
 	slog.Info("Shutting down ChatModule...")
-	// In a real module, you might wait for background workers to finish here.
-	return nil
+	var errs error
+	if m.chatSubscriber != nil {
+		errs = errors.Join(errs, m.chatSubscriber.Stop(ctx))
+	}
+	if m.presenceSubscriber != nil {
+		errs = errors.Join(errs, m.presenceSubscriber.Stop(ctx))
+	}
+	return errs
 }
 
 // Boot sets up the routes and starts background services for the chat module.
@@ -99,13 +124,14 @@ func (m *ChatModule) Boot(ctx context.Context, g *echo.Group, reg *registry.Regi
 	presenceHandler.SetRenderer(components.OnlineUsers)
 
 	// --- Start Background Services ---
-	// Create and start the chat subscriber in a goroutine.
-	chatSubscriber := NewChatSubscriber(m.subscriber, m.publisher, m.renderer)
-	go chatSubscriber.Start(ctx)
+	// Create and start the chat subscriber; Shutdown stops it.
+	m.chatSubscriber = NewChatSubscriber(m.subscriber, m.publisher, m.renderer)
+	m.chatSubscriber.maxMessageLength = m.maxMessageLength
+	m.chatSubscriber.Start(ctx)
 
 	// Create and start the presence subscriber for real-time presence updates
-	presenceSubscriber := NewPresenceSubscriber(m.subscriber, m.publisher, m.renderer)
-	go presenceSubscriber.Start(ctx)
+	m.presenceSubscriber = NewPresenceSubscriber(m.subscriber, m.publisher, m.renderer)
+	m.presenceSubscriber.Start(ctx)
 
 	// --- Register HTTP Handlers ---
 	slog.Info("Booting ChatModule: Setting up routes...")
@@ -19,6 +19,7 @@ type PresenceSubscriber struct {
 	publisher  pubsub.Publisher
 	renderer   rendering.Renderer
 	logger     *slog.Logger
+	group      *pubsub.SubscriberGroup
 }
 
 // NewPresenceSubscriber creates a new presence subscriber
@@ -31,22 +32,22 @@ func NewPresenceSubscriber(subscriber pubsub.Subscriber, publisher pubsub.Publis
 	}
 }
 
-// Start begins listening for presence updates
+// Start begins listening for presence updates. It returns immediately; the
+// subscription runs until ctx is canceled or Stop is called.
 func (ps *PresenceSubscriber) Start(ctx context.Context) {
 	ps.logger.Info("Starting presence subscriber")
 
-	// Subscribe to presence updates using the handler pattern
-	err := ps.subscriber.Subscribe(ctx, presence.TopicUserStatusUpdate.Name(), ps.handlePresenceUpdate)
-	if err != nil {
-		ps.logger.Error("Failed to subscribe to presence updates", "error", err)
-		return
-	}
-
-	ps.logger.Info("Successfully subscribed to presence updates")
+	ps.group = pubsub.NewSubscriberGroup(ps.subscriber, "chat_presence")
+	ps.group.Add(presence.TopicUserStatusUpdate.Name(), ps.handlePresenceUpdate)
+	ps.group.Start(ctx)
+}
 
-	// Keep the subscriber running
-	<-ctx.Done()
-	ps.logger.Info("Presence subscriber shutting down")
+// Stop ends the subscription and waits for an in-flight update to be handled.
+func (ps *PresenceSubscriber) Stop(ctx context.Context) error {
+	if ps.group == nil {
+		return nil
+	}
+	return ps.group.Stop(ctx)
 }
 
 // handlePresenceUpdate processes a presence update and publishes HTML
@@ -54,11 +55,7 @@ func (ps *PresenceSubscriber) handlePresenceUpdate(ctx context.Context, msg pubs
 	ps.logger.Info("Received presence update")
 
 	// Parse the presence update
-	var update struct {
-		Type  string   `json:"type"`
-		Users []string `json:"users"`
-	}
-
+	var update presence.UpdatePayload
 	if err := json.Unmarshal(msg.Payload, &update); err != nil {
 		ps.logger.Error("Failed to unmarshal presence update", "error", err)
 		// Don't return error for malformed messages - just skip them
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/presence"
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,6 +44,10 @@ func (m *mockChatPublisher) Publish(ctx context.Context, msg pubsub.Message) err
 	return nil
 }
 
+func (m *mockChatPublisher) HasSubscribers(topic string) bool {
+	return true
+}
+
 func (m *mockChatPublisher) Close() error {
 	return nil
 }
@@ -80,13 +85,7 @@ func TestPresenceSubscriber_HandlePresenceUpdate(t *testing.T) {
 		Return(expectedHTML, nil)
 
 	// Create test message
-	update := struct {
-		Type  string   `json:"type"`
-		Users []string `json:"users"`
-	}{
-		Type:  "presence_update",
-		Users: []string{"user1", "user2"},
-	}
+	update := presence.NewUpdatePayload([]string{"user1", "user2"})
 
 	payload, err := json.Marshal(update)
 	assert.NoError(t, err)
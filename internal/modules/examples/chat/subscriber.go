@@ -3,8 +3,10 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"time"
+	"unicode/utf8"
 
 	announcerEvents "github.com/nfrund/goby/internal/modules/announcer/events"
 	announcerTopics "github.com/nfrund/goby/internal/modules/announcer/topics"
@@ -23,6 +25,9 @@ type ChatSubscriber struct {
 	subscriber pubsub.Subscriber
 	publisher  pubsub.Publisher
 	renderer   rendering.Renderer
+	// maxMessageLength rejects longer messages when greater than zero.
+	maxMessageLength int
+	group            *pubsub.SubscriberGroup
 }
 
 // NewChatSubscriber creates a new subscriber service for the chat module.
@@ -34,44 +39,37 @@ func NewChatSubscriber(sub pubsub.Subscriber, pub pubsub.Publisher, renderer ren
 	}
 }
 
-// Start begins listening for chat-related messages.
-// This method blocks until the provided context is canceled.
+// Start begins listening for chat-related messages. It returns immediately;
+// the subscriptions run until ctx is canceled or Stop is called.
 func (cs *ChatSubscriber) Start(ctx context.Context) {
 	slog.Info("Starting chat module subscriber")
 
+	cs.group = pubsub.NewSubscriberGroup(cs.subscriber, "chat")
+
 	// Listen for new messages from clients.
 	// These messages originate from clients via the websocket bridge.
-	go func() {
-		err := pubsub.Subscribe(ctx, cs.subscriber, topics.TopicNewMessage, cs.handleChatMessage)
-		if err != nil && err != context.Canceled {
-			slog.Error("Chat message subscriber stopped with error", "error", err)
-		}
-	}()
+	pubsub.AddEvent(cs.group, topics.TopicNewMessage, cs.handleChatMessage)
 
 	// Also listen on the module's own "chat.messages" topic for messages
 	// that might originate from other parts of the system (e.g., an HTTP handler).
 	// Note: This is untyped (raw messages), so we keep the old Subscribe pattern
-	go func() {
-		err := cs.subscriber.Subscribe(ctx, topics.TopicMessages.Name(), cs.handleChatMessageUntyped)
-		if err != nil && err != context.Canceled {
-			slog.Error("Chat message subscriber stopped with error", "error", err)
-		}
-	}()
+	cs.group.Add(topics.TopicMessages.Name(), cs.handleChatMessageUntyped)
+
 	// Listen for new WebSocket connections to send welcome messages and subscribe to direct messages
-	go func() {
-		err := cs.subscriber.Subscribe(ctx, wsTopics.TopicClientReady.Name(), cs.handleClientConnect)
-		if err != nil && err != context.Canceled {
-			slog.Error("Chat client connect subscriber stopped with error", "error", err)
-		}
-	}()
+	cs.group.Add(wsTopics.TopicClientReady.Name(), cs.handleClientConnect)
 
 	// Listen for user creation events from the announcer module
-	go func() {
-		err := pubsub.Subscribe(ctx, cs.subscriber, announcerTopics.TopicUserCreated, cs.handleUserCreated)
-		if err != nil && err != context.Canceled {
-			slog.Error("User created subscriber stopped with error", "error", err)
-		}
-	}()
+	pubsub.AddEvent(cs.group, announcerTopics.TopicUserCreated, cs.handleUserCreated)
+
+	cs.group.Start(ctx)
+}
+
+// Stop ends the subscriptions and waits for in-flight messages to be handled.
+func (cs *ChatSubscriber) Stop(ctx context.Context) error {
+	if cs.group == nil {
+		return nil
+	}
+	return cs.group.Stop(ctx)
 }
 
 // handleClientConnect sends a welcome message to a newly connected client.
@@ -82,28 +80,18 @@ func (cs *ChatSubscriber) handleClientConnect(ctx context.Context, msg pubsub.Me
 	}
 	if err := json.Unmarshal(msg.Payload, &readyEvent); err != nil {
 		slog.Error("Failed to unmarshal system.websocket.ready event", "error", err)
-		return nil // Don't stop the subscriber for a bad message
+		return pubsub.Reject(err) // Malformed payloads will never succeed; drop to the dead-letter topic
 	}
 
 	// Only send a welcome message to HTML clients.
 	if readyEvent.Endpoint == "html" && readyEvent.UserID != "" {
 		welcomeComponent := components.WelcomeMessage("Welcome to the chat, " + readyEvent.UserID + "!")
-		renderedHTML, err := cs.renderer.RenderComponent(ctx, welcomeComponent)
+		err := pubsub.RenderAndBroadcast(ctx, cs.publisher, cs.renderer, welcomeComponent, wsTopics.TopicHTMLDirect,
+			pubsub.WithMetadata("recipient_id", readyEvent.UserID))
 		if err != nil {
-			slog.Error("Failed to render welcome message", "error", err, "userID", readyEvent.UserID)
-			return err
-		}
-
-		// Publish the welcome message to the direct messages topic
-		// Using metadata for recipient ID
-		directMsg := pubsub.Message{
-			Topic:   wsTopics.TopicHTMLDirect.Name(),
-			Payload: renderedHTML,
-			Metadata: map[string]string{
-				"recipient_id": readyEvent.UserID,
-			},
+			slog.Error("Failed to send welcome message", "error", err, "userID", readyEvent.UserID)
 		}
-		return cs.publisher.Publish(ctx, directMsg)
+		return err
 	}
 
 	return nil
@@ -118,44 +106,24 @@ func (cs *ChatSubscriber) handleChatMessage(ctx context.Context, payload events.
 		userID = "unknown"
 	}
 
-	// Render the message component with current timestamp
-	messageComponent := components.ChatMessage(userID, payload.Content, time.Now())
-	renderedHTML, err := cs.renderer.RenderComponent(ctx, messageComponent)
-	if err != nil {
-		slog.Error("Failed to render chat message", "error", err, "userID", userID)
-		return err
+	if err := cs.checkLength(payload.Content); err != nil {
+		slog.Warn("Dropping chat message", "error", err, "userID", userID)
+		return pubsub.Reject(err)
 	}
 
-	// Determine if this is a direct message by checking the topic
-	isDirect := payload.Recipient != ""
-	var recipient string
-	if isDirect {
-		// The recipient is now explicitly in the payload.
-		recipient = payload.Recipient
-	}
-
-	// Determine the target topic based on message type
-	var targetTopicName string
-	if isDirect {
-		targetTopicName = wsTopics.TopicHTMLDirect.Name()
-	} else {
-		targetTopicName = wsTopics.TopicHTMLBroadcast.Name()
-	}
-
-	// Publish the message with appropriate routing
-	pubMsg := pubsub.Message{
-		Topic:   targetTopicName,
-		Payload: renderedHTML, // Send the raw rendered HTML directly
+	// Render the message and route it to the recipient for direct messages,
+	// or to everyone otherwise.
+	messageComponent := components.ChatMessage(userID, payload.Content, time.Now())
+	topic, opts := wsTopics.TopicHTMLBroadcast, []pubsub.MessageOption(nil)
+	if payload.Recipient != "" {
+		topic = wsTopics.TopicHTMLDirect
+		opts = append(opts, pubsub.WithMetadata("recipient_id", payload.Recipient))
 	}
-
-	// Add recipient metadata for direct messages
-	if isDirect {
-		pubMsg.Metadata = map[string]string{
-			"recipient_id": recipient,
-		}
+	if err := pubsub.RenderAndBroadcast(ctx, cs.publisher, cs.renderer, messageComponent, topic, opts...); err != nil {
+		slog.Error("Failed to broadcast chat message", "error", err, "userID", userID)
+		return err
 	}
-
-	return cs.publisher.Publish(ctx, pubMsg)
+	return nil
 }
 
 // handleChatMessageUntyped processes incoming untyped chat messages (for backward compatibility)
@@ -165,7 +133,7 @@ func (cs *ChatSubscriber) handleChatMessageUntyped(ctx context.Context, msg pubs
 
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		slog.Error("Failed to unmarshal chat message", "error", err)
-		return nil // Don't stop the subscriber for a bad message
+		return pubsub.Reject(err) // Malformed payloads will never succeed; drop to the dead-letter topic
 	}
 
 	// Use the user from the payload if available, fallback to the message user ID
@@ -174,44 +142,24 @@ func (cs *ChatSubscriber) handleChatMessageUntyped(ctx context.Context, msg pubs
 		userID = msg.UserID
 	}
 
-	// Render the message component with current timestamp
-	messageComponent := components.ChatMessage(userID, payload.Content, time.Now())
-	renderedHTML, err := cs.renderer.RenderComponent(ctx, messageComponent)
-	if err != nil {
-		slog.Error("Failed to render chat message", "error", err, "userID", userID)
-		return err
-	}
-
-	// Determine if this is a direct message by checking the topic
-	isDirect := payload.Recipient != ""
-	var recipient string
-	if isDirect {
-		// The recipient is now explicitly in the payload.
-		recipient = payload.Recipient
-	}
-
-	// Determine the target topic based on message type
-	var targetTopicName string
-	if isDirect {
-		targetTopicName = wsTopics.TopicHTMLDirect.Name()
-	} else {
-		targetTopicName = wsTopics.TopicHTMLBroadcast.Name()
+	if err := cs.checkLength(payload.Content); err != nil {
+		slog.Warn("Dropping chat message", "error", err, "userID", userID)
+		return pubsub.Reject(err)
 	}
 
-	// Publish the message with appropriate routing
-	pubMsg := pubsub.Message{
-		Topic:   targetTopicName,
-		Payload: renderedHTML, // Send the raw rendered HTML directly
+	// Render the message and route it to the recipient for direct messages,
+	// or to everyone otherwise.
+	messageComponent := components.ChatMessage(userID, payload.Content, time.Now())
+	topic, opts := wsTopics.TopicHTMLBroadcast, []pubsub.MessageOption(nil)
+	if payload.Recipient != "" {
+		topic = wsTopics.TopicHTMLDirect
+		opts = append(opts, pubsub.WithMetadata("recipient_id", payload.Recipient))
 	}
-
-	// Add recipient metadata for direct messages
-	if isDirect {
-		pubMsg.Metadata = map[string]string{
-			"recipient_id": recipient,
-		}
+	if err := pubsub.RenderAndBroadcast(ctx, cs.publisher, cs.renderer, messageComponent, topic, opts...); err != nil {
+		slog.Error("Failed to broadcast chat message", "error", err, "userID", userID)
+		return err
 	}
-
-	return cs.publisher.Publish(ctx, pubMsg)
+	return nil
 }
 
 // handleUserCreated processes user creation events from the announcer module
@@ -241,3 +189,11 @@ func (cs *ChatSubscriber) handleUserCreated(ctx context.Context, eventData annou
 
 	return cs.handleChatMessageUntyped(ctx, announcementMsg)
 }
+
+// checkLength enforces the configured maximum message length in characters.
+func (cs *ChatSubscriber) checkLength(content string) error {
+	if cs.maxMessageLength > 0 && utf8.RuneCountInString(content) > cs.maxMessageLength {
+		return fmt.Errorf("message exceeds maximum length of %d characters", cs.maxMessageLength)
+	}
+	return nil
+}
@@ -16,11 +16,12 @@ var (
 	// TopicMessages represents broadcast messages to all clients
 	// Note: This is for rendered HTML, not typed data
 	TopicMessages = topicmgr.DefineModule(topicmgr.TopicConfig{
-		Name:        "chat.messages",
-		Module:      "chat",
-		Description: "Broadcasts a rendered chat message to all clients",
-		Pattern:     "chat.messages",
-		Example:     "chat.messages",
+		Name:          "chat.messages",
+		Module:        "chat",
+		Description:   "Broadcasts a rendered chat message to all clients",
+		Pattern:       "chat.messages",
+		Example:       "chat.messages",
+		RelatedTopics: []string{"ws.html.broadcast"},
 		Metadata: map[string]interface{}{
 			"routing_type": "broadcast",
 			"content_type": "rendered_html",
@@ -30,11 +31,12 @@ var (
 	// TopicDirectMessage represents direct messages to specific users
 	// Note: This is for rendered HTML, not typed data
 	TopicDirectMessage = topicmgr.DefineModule(topicmgr.TopicConfig{
-		Name:        "chat.direct",
-		Module:      "chat",
-		Description: "Sends a rendered direct message to a specific user",
-		Pattern:     "chat.direct.{userID}",
-		Example:     "chat.direct.user123",
+		Name:          "chat.direct",
+		Module:        "chat",
+		Description:   "Sends a rendered direct message to a specific user",
+		Pattern:       "chat.direct.{userID}",
+		Example:       "chat.direct.user123",
+		RelatedTopics: []string{"ws.html.direct"},
 		Metadata: map[string]interface{}{
 			"routing_type": "direct",
 			"content_type": "rendered_html",
@@ -56,14 +58,7 @@ var (
 // Kept for backward compatibility with module interface.
 func RegisterTopics() error {
 	// Manually register the non-typed topics (Messages and DirectMessage)
-	manager := topicmgr.Default()
-	if err := manager.Register(TopicMessages); err != nil {
-		return err
-	}
-	if err := manager.Register(TopicDirectMessage); err != nil {
-		return err
-	}
-	return nil
+	return topicmgr.Default().RegisterAll(TopicMessages, TopicDirectMessage)
 }
 
 // MustRegisterTopics registers topics and panics on error
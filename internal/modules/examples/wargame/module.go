@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/middleware"
 	"github.com/nfrund/goby/internal/module"
 	"github.com/nfrund/goby/internal/modules/examples/wargame/scripts"
 	"github.com/nfrund/goby/internal/modules/examples/wargame/topics"
@@ -29,6 +31,8 @@ type WargameModule struct {
 	scriptEngine script.ScriptEngine
 	scriptHelper *script.ModuleScriptHelper
 	engine       *Engine
+	// wargameSubscriber is started in Boot and stopped in Shutdown.
+	wargameSubscriber *Subscriber
 }
 
 type Dependencies struct {
@@ -166,14 +170,14 @@ func (m *WargameModule) Register(reg *registry.Registry) error {
 }
 
 func (m *WargameModule) Boot(ctx context.Context, g *echo.Group, reg *registry.Registry) error {
-	// Create and start the subscriber in a goroutine with script support
+	// Create and start the subscriber with script support; Shutdown stops it.
 	var scriptExecutor *script.ScriptExecutor
 	if m.scriptHelper != nil {
 		scriptExecutor = m.scriptHelper.GetExecutor()
 	}
 
-	wargameSubscriber := NewSubscriber(m.subscriber, m.publisher, m.renderer, scriptExecutor, m.GetExposedFunctions())
-	go wargameSubscriber.Start(ctx)
+	m.wargameSubscriber = NewSubscriber(m.subscriber, m.publisher, m.renderer, scriptExecutor, m.GetExposedFunctions())
+	m.wargameSubscriber.Start(ctx)
 
 	// Register HTTP handlers with script integration
 	g.GET("/debug/hit", func(c echo.Context) error {
@@ -206,7 +210,7 @@ func (m *WargameModule) Boot(ctx context.Context, g *echo.Group, reg *registry.R
 
 			// Execute the script
 			output, err := executor.ExecuteEndpointScript(
-				c.Request().Context(),
+				script.WithScriptContext(c.Request().Context(), scriptContextFor(c)),
 				"/debug/hit",
 				httpRequest,
 				m.GetExposedFunctions(),
@@ -246,5 +250,20 @@ func (m *WargameModule) Boot(ctx context.Context, g *echo.Group, reg *registry.R
 
 func (m *WargameModule) Shutdown(ctx context.Context) error {
 	slog.Info("Shutting down WargameModule...")
-	return nil
+	if m.wargameSubscriber == nil {
+		return nil
+	}
+	return m.wargameSubscriber.Stop(ctx)
+}
+
+// scriptContextFor describes the request to scripts as their ctx object.
+func scriptContextFor(c echo.Context) *script.ScriptContext {
+	sc := &script.ScriptContext{
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		Logger:    middleware.FromContext(c.Request().Context()),
+	}
+	if user, ok := c.Get(middleware.UserContextKey).(*domain.User); ok && user.ID != nil {
+		sc.UserID = user.ID.String()
+	}
+	return sc
 }
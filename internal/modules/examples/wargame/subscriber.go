@@ -12,6 +12,7 @@ import (
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/rendering"
 	"github.com/nfrund/goby/internal/script"
+	wsTopics "github.com/nfrund/goby/internal/websocket"
 )
 
 type Subscriber struct {
@@ -20,6 +21,7 @@ type Subscriber struct {
 	renderer       rendering.Renderer
 	scriptExecutor *script.ScriptExecutor
 	exposedFuncs   map[string]interface{}
+	group          *pubsub.SubscriberGroup
 }
 
 func NewSubscriber(sub pubsub.Subscriber, pub pubsub.Publisher, renderer rendering.Renderer, scriptExecutor *script.ScriptExecutor, exposedFuncs map[string]interface{}) *Subscriber {
@@ -32,32 +34,31 @@ func NewSubscriber(sub pubsub.Subscriber, pub pubsub.Publisher, renderer renderi
 	}
 }
 
+// Start begins listening for wargame events. It returns immediately; the
+// subscriptions run until ctx is canceled or Stop is called.
 func (s *Subscriber) Start(ctx context.Context) {
 	slog.Info("Starting wargame module subscriber")
 
+	s.group = pubsub.NewSubscriberGroup(s.subscriber, "wargame")
+
 	// Listen for HTML events
-	go func() {
-		err := pubsub.Subscribe(ctx, s.subscriber, topics.TopicEventDamage, s.handleDamageEvent)
-		if err != nil && err != context.Canceled {
-			slog.Error("Wargame HTML subscriber stopped with error", "error", err)
-		}
-	}()
+	pubsub.AddEvent(s.group, topics.TopicEventDamage, s.handleDamageEvent)
 
 	// Listen for Data events
-	go func() {
-		err := pubsub.Subscribe(ctx, s.subscriber, topics.TopicStateUpdate, s.handleStateUpdateEvent)
-		if err != nil && err != context.Canceled {
-			slog.Error("Wargame Data subscriber stopped with error", "error", err)
-		}
-	}()
+	pubsub.AddEvent(s.group, topics.TopicStateUpdate, s.handleStateUpdateEvent)
 
 	// Listen for player actions
-	go func() {
-		err := pubsub.Subscribe(ctx, s.subscriber, topics.TopicPlayerAction, s.handlePlayerAction)
-		if err != nil && err != context.Canceled {
-			slog.Error("Wargame Actions subscriber stopped with error", "error", err)
-		}
-	}()
+	pubsub.AddEvent(s.group, topics.TopicPlayerAction, s.handlePlayerAction)
+
+	s.group.Start(ctx)
+}
+
+// Stop ends the subscriptions and waits for in-flight events to be handled.
+func (s *Subscriber) Stop(ctx context.Context) error {
+	if s.group == nil {
+		return nil
+	}
+	return s.group.Stop(ctx)
 }
 
 func (s *Subscriber) handleDamageEvent(ctx context.Context, event events.Damage) error {
@@ -93,9 +94,8 @@ func (s *Subscriber) handleDamageEvent(ctx context.Context, event events.Damage)
 
 	// 1. Send HTML version to chat
 	component := components.DamageEvent(event.TargetUnit, event.DamageAmount, event.Attacker, messageID)
-	renderedHTML, err := s.renderer.RenderComponent(ctx, component)
-	if err != nil {
-		slog.Error("Failed to render wargame damage component", "error", err)
+	if err := pubsub.RenderAndBroadcast(ctx, s.publisher, s.renderer, component, wsTopics.TopicHTMLBroadcast); err != nil {
+		slog.Error("Failed to broadcast wargame damage component", "error", err)
 		return err
 	}
 
@@ -106,14 +106,6 @@ func (s *Subscriber) handleDamageEvent(ctx context.Context, event events.Damage)
 		return err
 	}
 
-	// Send both messages using typed topics
-	if err := s.publisher.Publish(ctx, pubsub.Message{
-		Topic:   "ws.html.broadcast", // Will be updated when WebSocket integration is complete
-		Payload: renderedHTML,
-	}); err != nil {
-		return err
-	}
-
 	return s.publisher.Publish(ctx, pubsub.Message{
 		Topic:   "ws.data.broadcast", // Will be updated when WebSocket integration is complete
 		Payload: jsonData,
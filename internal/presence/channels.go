@@ -0,0 +1,130 @@
+package presence
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// Reasons a channel was emptied, reported in ChannelEmptyEvent.
+const (
+	ChannelLeftReason    = "leave"
+	ChannelOfflineReason = "offline"
+	ChannelStaleReason   = "stale"
+)
+
+// ChannelEmptyEvent is the payload of TopicChannelEmpty.
+type ChannelEmptyEvent struct {
+	Channel   string    `json:"channel"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JoinChannel adds an online user to channel, creating the channel if needed.
+// It reports false for users who are not online, so memberships are only
+// held for users whose going offline will remove them again.
+func (s *Service) JoinChannel(userID, channel string) bool {
+	if userID == "" || channel == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, online := s.presences[userID]; !online {
+		return false
+	}
+	members := s.channels[channel]
+	if members == nil {
+		members = make(map[string]struct{})
+		s.channels[channel] = members
+	}
+	members[userID] = struct{}{}
+	return true
+}
+
+// LeaveChannel removes a user from channel. The channel is deleted, and
+// TopicChannelEmpty published, when its last member leaves.
+func (s *Service) LeaveChannel(userID, channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.leaveChannelUnsafe(userID, channel, ChannelLeftReason)
+}
+
+// ChannelMembers returns the sorted users in channel, or nil if it does not
+// exist.
+func (s *Service) ChannelMembers(channel string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := s.channels[channel]
+	if len(members) == 0 {
+		return nil
+	}
+	users := make([]string, 0, len(members))
+	for userID := range members {
+		users = append(users, userID)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Channels returns the sorted names of all channels with members.
+func (s *Service) Channels() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.channels))
+	for channel := range s.channels {
+		names = append(names, channel)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// leaveChannelUnsafe removes userID from channel, deleting the channel once
+// it is empty. Caller must hold mu.
+func (s *Service) leaveChannelUnsafe(userID, channel, reason string) {
+	members, exists := s.channels[channel]
+	if !exists {
+		return
+	}
+	delete(members, userID)
+	if len(members) > 0 {
+		return
+	}
+	delete(s.channels, channel)
+	s.logger.Info("Removed empty presence channel", "channel", channel, "reason", reason, logging.UserID(userID))
+	s.publishChannelEmpty(channel, reason)
+}
+
+// leaveAllChannelsUnsafe removes a user who went offline from every channel.
+// Caller must hold mu.
+func (s *Service) leaveAllChannelsUnsafe(userID, reason string) {
+	for channel, members := range s.channels {
+		if _, member := members[userID]; member {
+			s.leaveChannelUnsafe(userID, channel, reason)
+		}
+	}
+}
+
+// publishChannelEmpty publishes TopicChannelEmpty without holding up the
+// caller, which holds mu.
+func (s *Service) publishChannelEmpty(channel, reason string) {
+	payload, err := json.Marshal(ChannelEmptyEvent{Channel: channel, Reason: reason, Timestamp: s.now()})
+	if err != nil {
+		s.logger.Error("Failed to marshal channel empty event", "channel", channel, "error", err)
+		return
+	}
+	ctx := s.ctx
+	go func() {
+		if err := s.publisher.Publish(ctx, pubsub.Message{Topic: TopicChannelEmpty.Name(), Payload: payload}); err != nil {
+			s.metrics.publishErrors.Add(1)
+			s.logger.Error("Failed to publish channel empty event", "channel", channel, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,38 @@
+package presence
+
+import "time"
+
+// Clock is the service's source of time. It exists so tests can drive the
+// offline debounce, rate limiting and stale cleanup without sleeping; the
+// service uses the real clock unless WithClock is given.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled by Clock.AfterFunc.
+type Timer interface {
+	// Stop cancels the call, reporting false if it already ran or was stopped.
+	Stop() bool
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now().UTC() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock replaces the clock used for timestamps and timers. It is meant
+// for tests; a nil clock is ignored.
+func WithClock(c Clock) Option {
+	return func(s *Service) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
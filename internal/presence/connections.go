@@ -0,0 +1,99 @@
+package presence
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// ConnectionCountEvent is the payload of TopicConnectionCount.
+type ConnectionCountEvent struct {
+	UserID    string    `json:"userID"`
+	Count     int       `json:"count"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ConnectionCount returns the number of open connections (tabs or devices)
+// a user has, or 0 if the user is offline. A user whose last connection
+// closed within the offline debounce has 0 connections but is still online.
+func (s *Service) ConnectionCount(userID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.presences[userID])
+}
+
+// countQueue holds connection count events until the single goroutine that
+// publishes them, in order, gets to them.
+type countQueue struct {
+	mu     sync.Mutex
+	events []ConnectionCountEvent
+	wake   chan struct{} // signalled when events are queued
+}
+
+// publishConnectionCount queues TopicConnectionCount for a user whose
+// connection count changed, without holding up the caller, which holds mu.
+// Events are published in the order they are queued. Once the queue holds
+// publishBufferSize events, a new one replaces the user's queued event,
+// since only the latest count matters. Users hidden by WithUserFilter are
+// left out, as from presence updates.
+func (s *Service) publishConnectionCount(userID string, count int) {
+	if s.hideUser != nil && s.hideUser(userID) {
+		return
+	}
+	event := ConnectionCountEvent{UserID: userID, Count: count, Timestamp: s.now()}
+
+	q := &s.counts
+	q.mu.Lock()
+	if len(q.events) >= s.publishBufferSize {
+		q.events = slices.DeleteFunc(q.events, func(e ConnectionCountEvent) bool {
+			return e.UserID == userID
+		})
+	}
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// startCountPublishing publishes queued connection count events until the
+// service shuts down.
+func (s *Service) startCountPublishing() {
+	q := &s.counts
+	for {
+		select {
+		case <-q.wake:
+		case <-s.ctx.Done():
+			return
+		}
+		q.mu.Lock()
+		events := q.events
+		q.events = nil
+		q.mu.Unlock()
+		for _, event := range events {
+			s.sendConnectionCount(event)
+		}
+	}
+}
+
+// sendConnectionCount publishes one connection count event.
+func (s *Service) sendConnectionCount(event ConnectionCountEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to marshal connection count event", logging.UserID(event.UserID), "error", err)
+		return
+	}
+	ctx, cancel := pubsub.WithPublishContext(s.ctx, 0)
+	defer cancel()
+	msg := pubsub.Message{Topic: TopicConnectionCount.Name(), UserID: event.UserID, Payload: payload}
+	if err := s.publisher.Publish(ctx, msg); err != nil {
+		s.metrics.publishErrors.Add(1)
+		s.logger.Error("Failed to publish connection count event", logging.UserID(event.UserID), "error", err)
+	}
+}
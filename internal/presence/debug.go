@@ -0,0 +1,99 @@
+package presence
+
+import (
+	"sort"
+	"time"
+)
+
+// PresenceDebug is a point-in-time copy of the presence service's internal
+// state, for diagnosing questions such as "why is user X showing offline".
+// It includes users hidden by WithUserFilter.
+type PresenceDebug struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Users maps each online user to their connections, most recent first.
+	Users map[string][]Presence `json:"users"`
+	// PendingOffline maps users whose last connection closed to the time
+	// their offline debounce fires, unless they reconnect first.
+	PendingOffline map[string]time.Time `json:"pending_offline"`
+	// Debounce maps each online user to the offline debounce their last
+	// disconnect would get now, after any SetUserDebounce override and
+	// adaptive shortening.
+	Debounce map[string]time.Duration `json:"debounce"`
+	// RateLimited maps users inside their rate-limit window to the time the
+	// window ends; updates before then are dropped.
+	RateLimited map[string]time.Time `json:"rate_limited"`
+	// Connections is the learned state of every tracked client, by client ID.
+	Connections map[string]ConnectionState `json:"connections"`
+	// Patterns holds the learned activity pattern of each user.
+	Patterns map[string]UserActivityPattern `json:"patterns"`
+	// Metrics are the values GetMetrics reports.
+	Metrics map[string]int64 `json:"metrics"`
+}
+
+// DebugSnapshot returns a copy of the service's state. It holds every lock
+// at once, in the service's usual order, so the parts are consistent with
+// each other; it changes nothing. Metrics are read just before, as
+// GetMetrics takes learningMu itself and a second read lock would deadlock
+// behind a waiting writer.
+func (s *Service) DebugSnapshot() PresenceDebug {
+	metrics := s.GetMetrics()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.debounceMu.Lock()
+	defer s.debounceMu.Unlock()
+	s.learningMu.RLock()
+	defer s.learningMu.RUnlock()
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	now := s.now()
+	snap := PresenceDebug{
+		GeneratedAt:    now,
+		Users:          make(map[string][]Presence, len(s.presences)),
+		PendingOffline: make(map[string]time.Time, len(s.offlineAt)),
+		Debounce:       make(map[string]time.Duration, len(s.presences)),
+		RateLimited:    make(map[string]time.Time),
+		Connections:    make(map[string]ConnectionState, len(s.connectionStates)),
+		Patterns:       make(map[string]UserActivityPattern, len(s.userPatterns)),
+		Metrics:        metrics,
+	}
+
+	for userID, clientPresences := range s.presences {
+		connections := make([]Presence, 0, len(clientPresences))
+		for _, p := range clientPresences {
+			connections = append(connections, p)
+		}
+		sort.Slice(connections, func(i, j int) bool {
+			return connections[i].Timestamp.After(connections[j].Timestamp)
+		})
+		snap.Users[userID] = connections
+		snap.Debounce[userID] = s.offlineDebounceFor(userID, s.predictReconnectionTimeUnsafe(userID))
+	}
+	for userID, at := range s.offlineAt {
+		snap.PendingOffline[userID] = at
+	}
+	for userID, start := range s.rateLimiter {
+		if end := start.Add(rateLimitWindow); end.After(now) {
+			snap.RateLimited[userID] = end
+		}
+	}
+	for clientID, state := range s.connectionStates {
+		snap.Connections[clientID] = copyConnectionState(state)
+	}
+	for userID, pattern := range s.userPatterns {
+		snap.Patterns[userID] = *pattern
+	}
+	return snap
+}
+
+// copyConnectionState copies state, including the time DisconnectTime
+// points to.
+func copyConnectionState(state *ConnectionState) ConnectionState {
+	c := *state
+	if state.DisconnectTime != nil {
+		t := *state.DisconnectTime
+		c.DisconnectTime = &t
+	}
+	return c
+}
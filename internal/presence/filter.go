@@ -0,0 +1,27 @@
+package presence
+
+import "path"
+
+// MatchUsers returns a WithUserFilter predicate that matches user IDs against
+// patterns in path.Match syntax, e.g. "monitor@example.com" or
+// "bot-*@example.com". Malformed and empty patterns never match; a nil
+// predicate is returned when no patterns are given.
+func MatchUsers(patterns []string) func(userID string) bool {
+	var valid []string
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); p != "" && err == nil {
+			valid = append(valid, p)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return func(userID string) bool {
+		for _, p := range valid {
+			if ok, _ := path.Match(p, userID); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,30 @@
+package presence
+
+// UpdateType is the Type of every UpdatePayload.
+const UpdateType = "presence_update"
+
+// UpdatePayloadVersion is the schema version of UpdatePayload. It changes
+// when a field is removed or changes meaning; new optional fields, which
+// clients must ignore when they don't know them, do not change it.
+const UpdatePayloadVersion = 1
+
+// UpdatePayload is the JSON body published on TopicUserStatusUpdate whenever
+// the set of online users changes. It is a contract with frontends and
+// subscribing modules: fields are only added, and optional ones use
+// omitempty so older clients see the same shape.
+type UpdatePayload struct {
+	// Type is always UpdateType.
+	Type string `json:"type"`
+	// Version is the schema version the payload was written with.
+	Version int `json:"version"`
+	// Users are the IDs of all visible online users. It is never null.
+	Users []string `json:"users"`
+}
+
+// NewUpdatePayload returns the current-version payload for users.
+func NewUpdatePayload(users []string) UpdatePayload {
+	if users == nil {
+		users = []string{}
+	}
+	return UpdatePayload{Type: UpdateType, Version: UpdatePayloadVersion, Users: users}
+}
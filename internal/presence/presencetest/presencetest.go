@@ -0,0 +1,226 @@
+// Package presencetest provides a manual clock and a harness for testing the
+// presence service. It is kept out of presence so the testing package is not
+// linked into binaries.
+package presencetest
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/presence"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// ManualClock is a presence.Clock whose time only moves when Advance is
+// called. Timers that come due run synchronously inside Advance.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock   *ManualClock
+	when    time.Time
+	f       func()
+	stopped bool
+}
+
+// NewManualClock returns a ManualClock set to start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to run once the clock has been advanced by d.
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) presence.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and runs the timers that came due.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*manualTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.when.After(c.now):
+			t.stopped = true
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// Harness runs a presence.Service against an in-process WatermillBridge and
+// a ManualClock, so tests can drive the whole connect, debounce, offline and
+// broadcast flow without a WebSocket or real time:
+//
+//	h := presencetest.NewHarness(t, presence.WithOfflineDebounce(5*time.Second))
+//	h.Connect("alice", "tab-1")
+//	h.WaitForOnline("alice")
+//	h.Disconnect("alice", "tab-1")
+//	h.Advance(5 * time.Second)
+//	h.WaitForOnline()
+//
+// Connect and Disconnect are what the heartbeat handlers call for a client.
+// The online-user lists are read back from presence.TopicUserStatusUpdate as
+// a subscriber sees them.
+type Harness struct {
+	Service *presence.Service
+	Bridge  *pubsub.WatermillBridge
+	Clock   *ManualClock
+
+	t       testing.TB
+	mu      sync.Mutex
+	updates []publishedUpdate
+}
+
+// publishedUpdate is an online-user list and its publish sequence number.
+type publishedUpdate struct {
+	seq   uint64
+	users []string
+}
+
+// harnessWait bounds how long WaitForOnline waits for an update to arrive.
+const harnessWait = 2 * time.Second
+
+// NewHarness starts a presence.Service with opts, a fresh in-process bridge
+// and a ManualClock, and shuts them down when the test ends. The clock starts
+// at noon on 2025-01-01; pass presence.WithClock to use another.
+func NewHarness(t testing.TB, opts ...presence.Option) *Harness {
+	t.Helper()
+
+	bridge := pubsub.NewWatermillBridge()
+	clock := NewManualClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	opts = append([]presence.Option{presence.WithClock(clock)}, opts...)
+	h := &Harness{
+		Bridge: bridge,
+		Clock:  clock,
+		t:      t,
+	}
+
+	// Subscribe before the service exists so no update is missed.
+	ctx, cancel := context.WithCancel(context.Background())
+	err := bridge.Subscribe(ctx, presence.TopicUserStatusUpdate.Name(), func(ctx context.Context, msg pubsub.Message) error {
+		var payload presence.UpdatePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			t.Errorf("presencetest.Harness: invalid update payload %q: %v", msg.Payload, err)
+			return nil
+		}
+		_, seq, _ := pubsub.PublishOrder(msg)
+		h.mu.Lock()
+		h.updates = append(h.updates, publishedUpdate{seq: seq, users: payload.Users})
+		h.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		cancel()
+		bridge.Close()
+		t.Fatalf("presencetest.NewHarness: subscribe: %v", err)
+	}
+
+	h.Service = presence.NewService(context.Background(), bridge, bridge, topicmgr.Default(), opts...)
+	t.Cleanup(func() {
+		h.Service.Shutdown()
+		cancel()
+		bridge.Close()
+	})
+	return h
+}
+
+// Connect simulates clientID of userID connecting.
+func (h *Harness) Connect(userID, clientID string) {
+	h.Service.AddPresenceWithClientType(userID, clientID, "presence-harness", "")
+}
+
+// Disconnect simulates clientID of userID disconnecting. The user goes
+// offline once the offline debounce has elapsed on the clock.
+func (h *Harness) Disconnect(userID, clientID string) {
+	h.Service.RemovePresenceForClient(userID, clientID)
+}
+
+// Advance moves the clock forward by d, running any debounce timers that
+// come due.
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// Updates returns the online-user lists received so far, in the order they
+// were published. The bridge may deliver updates out of order, so they are
+// sorted by their publish sequence numbers.
+func (h *Harness) Updates() [][]string {
+	h.mu.Lock()
+	updates := slices.Clone(h.updates)
+	h.mu.Unlock()
+
+	sort.SliceStable(updates, func(i, j int) bool { return updates[i].seq < updates[j].seq })
+	lists := make([][]string, len(updates))
+	for i, u := range updates {
+		lists[i] = u.users
+	}
+	return lists
+}
+
+// WaitForOnline waits until the latest published online-user list holds
+// exactly users, in any order, and returns every list received so far. It
+// fails the test if that doesn't happen within two seconds.
+func (h *Harness) WaitForOnline(users ...string) [][]string {
+	h.t.Helper()
+
+	want := sortedUsers(users)
+	deadline := time.Now().Add(harnessWait)
+	for {
+		updates := h.Updates()
+		if n := len(updates); n > 0 && slices.Equal(sortedUsers(updates[n-1]), want) {
+			return updates
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("presencetest.Harness: online users never became %v; published %v", want, updates)
+			return updates
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func sortedUsers(users []string) []string {
+	sorted := slices.Clone(users)
+	if sorted == nil {
+		sorted = []string{}
+	}
+	slices.Sort(sorted)
+	return sorted
+}
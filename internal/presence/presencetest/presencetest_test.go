@@ -0,0 +1,49 @@
+package presencetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/presence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHarness_ReconnectWithinDebounce(t *testing.T) {
+	h := NewHarness(t, presence.WithOfflineDebounce(5*time.Second))
+
+	h.Connect("alice", "tab-1")
+	h.WaitForOnline("alice")
+
+	// The page reloads: the old connection closes and a new one opens
+	// before the debounce runs out.
+	h.Disconnect("alice", "tab-1")
+	h.Advance(3 * time.Second)
+	h.Connect("alice", "tab-2")
+	h.Advance(10 * time.Second)
+
+	// Bob's arrival is the last update, so every update alice could have
+	// caused has been published before it.
+	h.Connect("bob", "tab-3")
+	updates := h.WaitForOnline("alice", "bob")
+	for _, users := range updates {
+		assert.Contains(t, users, "alice", "alice must never be broadcast as offline: %v", updates)
+	}
+	assert.Equal(t, int64(1), h.Service.GetMetrics()["reconnections"])
+	assert.Equal(t, int64(0), h.Service.GetMetrics()["debounce_timeouts"])
+}
+
+func TestHarness_OfflineAfterDebounce(t *testing.T) {
+	h := NewHarness(t, presence.WithOfflineDebounce(5*time.Second))
+
+	h.Connect("alice", "tab-1")
+	h.WaitForOnline("alice")
+
+	h.Disconnect("alice", "tab-1")
+	h.Advance(4 * time.Second)
+	assert.Contains(t, h.Service.DebugSnapshot().PendingOffline, "alice", "the offline event waits out the debounce")
+
+	h.Advance(time.Second)
+	updates := h.WaitForOnline()
+	assert.Equal(t, []string{"alice"}, updates[0])
+	assert.Equal(t, int64(1), h.Service.GetMetrics()["debounce_timeouts"])
+}
@@ -0,0 +1,96 @@
+package presence
+
+import "time"
+
+// DefaultHistoryRetention is how long connection history and learned
+// patterns are kept for a user who hasn't connected since.
+const DefaultHistoryRetention = 24 * time.Hour
+
+// WithHistoryRetention sets how long the service remembers the connection
+// history and learned patterns of users who are no longer connected. Every
+// cleanup pass purges users whose last connection event is older than d, so
+// the learning maps don't grow with every user ever seen. Zero keeps history
+// forever; negative values are ignored.
+func WithHistoryRetention(d time.Duration) Option {
+	return func(s *Service) {
+		if d >= 0 {
+			s.historyRetention = d
+		}
+	}
+}
+
+// purgeConnectionHistory forgets the connection history, learned patterns
+// and offline connection states of users who are not connected, not waiting
+// out an offline debounce and have had no connection event within the
+// retention window. It returns the number of users purged.
+func (s *Service) purgeConnectionHistory() int {
+	if s.historyRetention <= 0 {
+		return 0
+	}
+	cutoff := s.now().Add(-s.historyRetention)
+
+	active := make(map[string]struct{})
+	s.mu.RLock()
+	for userID := range s.presences {
+		active[userID] = struct{}{}
+	}
+	s.mu.RUnlock()
+	s.debounceMu.Lock()
+	for userID := range s.offlineDebounce {
+		active[userID] = struct{}{}
+	}
+	s.debounceMu.Unlock()
+
+	s.learningMu.Lock()
+	defer s.learningMu.Unlock()
+
+	purged := make(map[string]struct{})
+	expired := func(userID string) bool {
+		if _, ok := active[userID]; ok {
+			return false
+		}
+		return s.lastActivityUnsafe(userID).Before(cutoff)
+	}
+	for userID := range s.connectionHistory {
+		if expired(userID) {
+			purged[userID] = struct{}{}
+		}
+	}
+	for userID := range s.userPatterns {
+		if expired(userID) {
+			purged[userID] = struct{}{}
+		}
+	}
+	for userID := range purged {
+		delete(s.connectionHistory, userID)
+		delete(s.userPatterns, userID)
+	}
+	for clientID, state := range s.connectionStates {
+		if state.Status != ConnectionOffline {
+			continue
+		}
+		if state.LastSeen.Before(cutoff) && expired(state.UserID) {
+			delete(s.connectionStates, clientID)
+		}
+	}
+
+	if len(purged) > 0 {
+		s.logger.Debug("Purged connection history of absent users",
+			"users", len(purged),
+			"retention", s.historyRetention)
+	}
+	return len(purged)
+}
+
+// lastActivityUnsafe returns the time of userID's latest connection event or
+// pattern update. The caller must hold learningMu.
+func (s *Service) lastActivityUnsafe(userID string) time.Time {
+	var last time.Time
+	if history := s.connectionHistory[userID]; len(history) > 0 {
+		last = history[len(history)-1].Timestamp
+	}
+	if pattern := s.userPatterns[userID]; pattern != nil && pattern.LastActivity.After(last) {
+		last = pattern.LastActivity
+	}
+	return last
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/logging"
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/topicmgr"
 )
@@ -44,6 +48,24 @@ const (
 	// Can be overridden using WithOfflineDebounce() option.
 	// Recommended: 3-10 seconds depending on your network conditions and browser quirks.
 	OfflineDebounceDelay = 5 * time.Second
+
+	// MinPingsForCadence is how many heartbeats a client must send before
+	// its stale threshold follows its measured ping cadence rather than
+	// the configured threshold.
+	MinPingsForCadence = 3
+
+	// pingAverageWindow is roughly how many recent heartbeats a client's
+	// AveragePingInterval reflects.
+	pingAverageWindow = 10
+
+	// DefaultGuestStaleThreshold is how long a guest connection lasts without
+	// a heartbeat. Guests have no account to come back to, so they are
+	// dropped much sooner than users.
+	DefaultGuestStaleThreshold = time.Minute
+
+	// DefaultMaxStaleThreshold is the longest a registered user's client may
+	// go without a heartbeat, however slow a cadence it reports.
+	DefaultMaxStaleThreshold = 10 * time.Minute
 )
 
 type Presence struct {
@@ -55,6 +77,8 @@ type Presence struct {
 	UserAgent         string        `json:"user_agent,omitempty"`
 	PingInterval      time.Duration `json:"ping_interval,omitempty"`      // Client's declared ping interval
 	TimeoutMultiplier int           `json:"timeout_multiplier,omitempty"` // Multiplier for timeout calculation
+	Guest             bool          `json:"guest,omitempty"`              // UserID is an ephemeral guest ID
+	Pending           bool          `json:"pending,omitempty"`            // Restored from a snapshot; the client has not reconnected yet
 }
 
 type ConnectionState struct {
@@ -95,51 +119,99 @@ type Service struct {
 	mu        sync.RWMutex
 	presences map[string]map[string]Presence // userID -> clientID -> Presence
 	clients   map[string]string              // clientID -> userID (for disconnect lookup)
+	channels  map[string]map[string]struct{} // channel -> userID set; empty channels are removed
 	publisher pubsub.Publisher
 	logger    *slog.Logger
+	clock     Clock
+
+	// ctx scopes the service's subscriptions and background work. It is
+	// cancelled by Shutdown or when the context passed to NewService is done.
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	// Rate limiting
-	rateLimiter map[string]*time.Timer // userID -> last update timer
+	rateLimiter map[string]time.Time // userID -> start of the current window
 	rateMu      sync.Mutex
 
 	// Cleanup mechanism
 	cleanupTicker  *time.Ticker
 	stopCleanup    chan struct{}
 	staleThreshold time.Duration
+	// guestStaleThreshold replaces staleThreshold for guest connections.
+	guestStaleThreshold time.Duration
+	// maxStaleThreshold caps the threshold learned from a client's cadence.
+	maxStaleThreshold time.Duration
+
+	// snapshots, when set, persists presence every snapshotInterval and on
+	// Shutdown; presence restored from it lasts snapshotGrace unless its
+	// client reconnects.
+	snapshots        SnapshotStore
+	snapshotInterval time.Duration
+	snapshotGrace    time.Duration
+
+	// hideUser, when set, reports users that are tracked but left out of
+	// the online list and presence broadcasts.
+	hideUser func(userID string) bool
 
 	// Debouncing for offline events (to handle page reloads gracefully)
-	offlineDebounce      map[string]*time.Timer // userID -> debounce timer
-	offlineDebounceDelay time.Duration          // configurable delay
+	offlineDebounce      map[string]Timer         // userID -> debounce timer
+	offlineAt            map[string]time.Time     // userID -> when its debounce timer fires
+	offlineDebounceDelay time.Duration            // configurable delay
+	userDebounce         map[string]time.Duration // userID -> delay overriding offlineDebounceDelay
 	debounceMu           sync.Mutex
 
 	// Publishing channel to avoid lock contention during pubsub operations
-	publishCh chan publishRequest
+	publishCh         chan publishRequest
+	publishBufferSize int
+
+	// When publishCh is full the newest snapshot is parked in pending instead
+	// of being dropped. Requests carry a sequence number so the publisher
+	// never sends a snapshot older than one it already published.
+	pendingMu    sync.Mutex
+	pending      *publishRequest
+	publishSeq   uint64
+	publishedSeq atomic.Uint64
+
+	// counts queues connection count events for startCountPublishing.
+	counts countQueue
 
 	// Connection intelligence and learning
 	connectionStates  map[string]*ConnectionState     // clientID -> state
 	userPatterns      map[string]*UserActivityPattern // userID -> patterns
 	connectionHistory map[string][]ConnectionEvent    // userID -> history
 	learningMu        sync.RWMutex
+	// historyRetention is how long history is kept for absent users.
+	historyRetention time.Duration
 
-	// Metrics for monitoring presence tracking
+	// Metrics for monitoring presence tracking. Counters are atomic because
+	// they are updated under different locks (mu, rateMu, debounceMu) or none.
 	metrics struct {
-		totalConnections int64
-		totalUsers       int64
-		disconnections   int64
-		reconnections    int64
-		staleCleanups    int64
-		rateLimitHits    int64
-		debounceTimeouts int64
-		publishErrors    int64
-		adaptiveCleanups int64 // Connections kept alive due to adaptive logic
+		totalConnections atomic.Int64
+		totalUsers       atomic.Int64
+		disconnections   atomic.Int64
+		reconnections    atomic.Int64
+		staleCleanups    atomic.Int64
+		rateLimitHits    atomic.Int64
+		debounceTimeouts atomic.Int64
+		publishErrors    atomic.Int64
+		adaptiveCleanups atomic.Int64 // Connections kept alive due to adaptive logic
+		coalescedUpdates atomic.Int64 // Updates superseded by a newer snapshot
+
+		// recent counts connections, disconnections and reconnections over
+		// the last MetricsWindow.
+		recent eventWindow
 	}
 }
 
 type publishRequest struct {
+	seq         uint64
 	onlineUsers []string
 	done        chan struct{}
 }
 
+// DefaultPublishBufferSize is the default capacity of the presence publish queue.
+const DefaultPublishBufferSize = 100
+
 // Option is a function that configures a Service.
 type Option func(*Service)
 
@@ -150,6 +222,37 @@ func WithStaleThreshold(d time.Duration) Option {
 	}
 }
 
+// WithGuestStaleThreshold sets how long a guest connection may go without a
+// heartbeat before it is cleaned up. Non-positive values are ignored.
+func WithGuestStaleThreshold(d time.Duration) Option {
+	return func(s *Service) {
+		if d > 0 {
+			s.guestStaleThreshold = d
+		}
+	}
+}
+
+// WithMaxStaleThreshold caps how long a client may go without a heartbeat
+// when its threshold follows its own ping cadence and timeout multiplier,
+// which the client controls. Non-positive values are ignored.
+func WithMaxStaleThreshold(d time.Duration) Option {
+	return func(s *Service) {
+		if d > 0 {
+			s.maxStaleThreshold = d
+		}
+	}
+}
+
+// WithUserFilter hides users for which hide returns true, such as service
+// accounts and monitoring bots, from GetOnlineUsers and presence broadcasts.
+// They are still tracked, so GetAllOnlineUsers, GetPresence and
+// GetUserConnections report them.
+func WithUserFilter(hide func(userID string) bool) Option {
+	return func(s *Service) {
+		s.hideUser = hide
+	}
+}
+
 // WithOfflineDebounce sets a custom debounce delay for offline events.
 // This is useful for handling different network conditions or browser behaviors.
 // Set to 0 to disable debouncing (useful for testing).
@@ -159,61 +262,90 @@ func WithOfflineDebounce(d time.Duration) Option {
 	}
 }
 
+// WithPublishBufferSize sets the capacity of the queue of pending presence
+// updates. When it is full, updates are coalesced into the newest snapshot.
+func WithPublishBufferSize(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.publishBufferSize = n
+		}
+	}
+}
+
 // Now returns the current time in UTC
 func Now() time.Time {
 	return time.Now().UTC()
 }
 
+// now returns the current time according to the service's clock.
+func (s *Service) now() time.Time {
+	return s.clock.Now()
+}
+
+// rateLimitWindow allows at most one presence update per user per window.
+const rateLimitWindow = 1 * time.Second
+
 // checkRateLimit prevents too frequent presence updates from the same user
 func (s *Service) checkRateLimit(userID string) bool {
-	const rateLimitWindow = 1 * time.Second // Max 1 update per second per user
 
 	s.rateMu.Lock()
 	defer s.rateMu.Unlock()
 
-	if timer, exists := s.rateLimiter[userID]; exists {
-		// Check if rate limit window has passed
-		select {
-		case <-timer.C:
-			// Timer expired, allow update
-			delete(s.rateLimiter, userID)
-			s.rateLimiter[userID] = time.NewTimer(rateLimitWindow)
-			return true
-		default:
-			// Still within rate limit window
-			s.metrics.rateLimitHits++
-			return false
-		}
+	now := s.now()
+	if start, exists := s.rateLimiter[userID]; exists && now.Sub(start) < rateLimitWindow {
+		// Still within rate limit window
+		s.metrics.rateLimitHits.Add(1)
+		return false
 	}
 
-	// First update for this user
-	s.rateLimiter[userID] = time.NewTimer(rateLimitWindow)
+	// First update for this user, or the previous window has passed
+	s.rateLimiter[userID] = now
 	return true
 }
 
+// clearRateLimit drops the rate limiter window for a user that went offline.
+func (s *Service) clearRateLimit(userID string) {
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	delete(s.rateLimiter, userID)
+}
+
 // NewService creates a new presence service with the provided dependencies.
-func NewService(publisher pubsub.Publisher, subscriber pubsub.Subscriber, topicMgr *topicmgr.Manager, opts ...Option) *Service {
+// Cancelling ctx, typically the application context, tears down the service's
+// subscriptions and stops its cleanup loop, as does Shutdown.
+func NewService(ctx context.Context, publisher pubsub.Publisher, subscriber pubsub.Subscriber, topicMgr *topicmgr.Manager, opts ...Option) *Service {
 	svc := &Service{
 		presences:            make(map[string]map[string]Presence),
 		clients:              make(map[string]string),
+		channels:             make(map[string]map[string]struct{}),
 		publisher:            publisher,
 		logger:               slog.Default().With("service", "presence"),
-		rateLimiter:          make(map[string]*time.Timer),
+		clock:                realClock{},
+		rateLimiter:          make(map[string]time.Time),
 		cleanupTicker:        time.NewTicker(120 * time.Second), // Conservative: cleanup every 2 minutes
 		stopCleanup:          make(chan struct{}),
 		staleThreshold:       180 * time.Second, // Conservative: 3 minute timeout
-		offlineDebounce:      make(map[string]*time.Timer),
+		guestStaleThreshold:  DefaultGuestStaleThreshold,
+		maxStaleThreshold:    DefaultMaxStaleThreshold,
+		offlineDebounce:      make(map[string]Timer),
+		offlineAt:            make(map[string]time.Time),
 		offlineDebounceDelay: OfflineDebounceDelay,
-		publishCh:            make(chan publishRequest, 100), // Buffered channel for publishing
+		userDebounce:         make(map[string]time.Duration),
+		publishBufferSize:    DefaultPublishBufferSize,
 		connectionStates:     make(map[string]*ConnectionState),
 		userPatterns:         make(map[string]*UserActivityPattern),
 		connectionHistory:    make(map[string][]ConnectionEvent),
+		historyRetention:     DefaultHistoryRetention,
 	}
 
 	// Apply functional options
 	for _, opt := range opts {
 		opt(svc)
 	}
+	svc.publishCh = make(chan publishRequest, svc.publishBufferSize) // Buffered channel for publishing
+	svc.ctx, svc.cancel = context.WithCancel(ctx)
+	svc.counts.wake = make(chan struct{}, 1)
 
 	// Register presence framework topics
 	if err := RegisterTopics(); err != nil {
@@ -229,6 +361,13 @@ func NewService(publisher pubsub.Publisher, subscriber pubsub.Subscriber, topicM
 
 	// Start publishing goroutine
 	go svc.startPublishing()
+	go svc.startCountPublishing()
+
+	// Restore presence from before a restart; publishing must be running.
+	if svc.snapshots != nil {
+		svc.restoreSnapshot()
+		go svc.startSnapshots()
+	}
 
 	svc.logger.Info("Presence service initialized")
 	return svc
@@ -246,9 +385,15 @@ func (s *Service) addPresenceWithClientType(userID, clientID, userAgent, clientT
 
 // addPresenceWithClientConfig adds a presence entry with full client configuration
 func (s *Service) addPresenceWithClientConfig(userID, clientID, userAgent, clientType string, pingIntervalMs int, timeoutMultiplier int) {
+	// A partial event would leave an entry that can never be removed.
+	if userID == "" || clientID == "" {
+		s.logger.Warn("Skipping presence connect with missing IDs", logging.UserID(userID), logging.ClientID(clientID))
+		return
+	}
+
 	// Rate limiting check
 	if !s.checkRateLimit(userID) {
-		s.logger.Debug("Rate limit exceeded for user", "user_id", userID)
+		s.logger.Debug("Rate limit exceeded for user", logging.UserID(userID))
 		return
 	}
 
@@ -266,10 +411,12 @@ func (s *Service) addPresenceWithClientConfig(userID, clientID, userAgent, clien
 	if timer, exists := s.offlineDebounce[userID]; exists {
 		timer.Stop()
 		delete(s.offlineDebounce, userID)
+		delete(s.offlineAt, userID)
 		s.logger.Info("Cancelled offline debounce due to reconnection",
-			"user_id", userID,
-			"client_id", clientID)
-		s.metrics.reconnections++
+			logging.UserID(userID),
+			logging.ClientID(clientID))
+		s.metrics.reconnections.Add(1)
+		s.metrics.recent.add(s.now(), windowReconnect)
 	}
 	s.debounceMu.Unlock()
 
@@ -278,37 +425,47 @@ func (s *Service) addPresenceWithClientConfig(userID, clientID, userAgent, clien
 	if isNewUser {
 		s.presences[userID] = make(map[string]Presence)
 		s.logger.Info("User came online",
-			"user_id", userID,
-			"client_id", clientID,
+			logging.UserID(userID),
+			logging.ClientID(clientID),
 			"user_agent", userAgent)
 	} else {
 		s.logger.Debug("Adding additional connection for user",
-			"user_id", userID,
-			"client_id", clientID,
+			logging.UserID(userID),
+			logging.ClientID(clientID),
 			"existing_connections", len(s.presences[userID]),
 			"user_agent", userAgent)
 	}
 
 	// Add this specific client's presence
+	_, reconnecting := s.presences[userID][clientID]
 	s.presences[userID][clientID] = Presence{
 		UserID:            userID,
 		Status:            StatusOnline,
 		ClientID:          clientID,
 		ClientType:        clientType,
-		Timestamp:         Now(),
+		Timestamp:         s.now(),
 		UserAgent:         userAgent,
 		PingInterval:      time.Duration(pingIntervalMs) * time.Millisecond,
 		TimeoutMultiplier: timeoutMultiplier,
+		Guest:             domain.IsGuestID(userID),
+	}
+	s.metrics.totalUsers.Store(int64(len(s.presences)))
+	// A known client ID is a heartbeat, not a new connection.
+	if !reconnecting {
+		s.metrics.totalConnections.Add(1)
+		s.metrics.recent.add(s.now(), windowConnect)
+		s.publishConnectionCount(userID, len(s.presences[userID]))
 	}
-	s.metrics.totalConnections++
-	s.metrics.totalUsers = int64(len(s.presences))
 
 	// Update connection state and learn user patterns
 	s.learningMu.Lock()
-	if connState := s.connectionStates[clientID]; connState != nil {
+	if connState := s.connectionStates[clientID]; connState != nil && reconnecting {
+		// A heartbeat from a connected client - learn its ping cadence
+		s.recordPing(connState)
+	} else if connState != nil {
 		// This is a reconnection - update patterns
 		connState.Status = ConnectionActive
-		connState.LastSeen = Now()
+		connState.LastSeen = s.now()
 		connState.ReconnectCount++
 
 		// Learn from reconnection behavior
@@ -318,8 +475,8 @@ func (s *Service) addPresenceWithClientConfig(userID, clientID, userAgent, clien
 		s.connectionStates[clientID] = &ConnectionState{
 			ClientID:    clientID,
 			UserID:      userID,
-			ConnectedAt: Now(),
-			LastSeen:    Now(),
+			ConnectedAt: s.now(),
+			LastSeen:    s.now(),
 			Status:      ConnectionActive,
 		}
 	}
@@ -336,14 +493,70 @@ func (s *Service) addPresenceWithClientConfig(userID, clientID, userAgent, clien
 	s.publishAsync(onlineUsers)
 }
 
+// recordPing counts a heartbeat on connState and folds the time since the
+// previous one into its AveragePingInterval: the plain mean over the first
+// pingAverageWindow heartbeats, then a moving average weighted towards the
+// most recent ones. The caller must hold learningMu.
+func (s *Service) recordPing(connState *ConnectionState) {
+	now := s.now()
+	interval := now.Sub(connState.LastSeen)
+	connState.LastSeen = now
+	connState.Status = ConnectionActive
+	if interval <= 0 {
+		return
+	}
+	connState.PingCount++
+	weight := min(connState.PingCount, pingAverageWindow)
+	connState.AveragePingInterval += (interval - connState.AveragePingInterval) / time.Duration(weight)
+}
+
+// staleThresholdUnsafe returns how long a client of userID may go without a
+// heartbeat before it is cleaned up. Once the client has sent
+// MinPingsForCadence heartbeats, the threshold is its TimeoutMultiplier times
+// its average ping interval, so a client that pings reliably is judged by its
+// own cadence. Guests never get longer than the guest threshold, and users
+// never longer than the maximum stale threshold. The caller must hold mu.
+func (s *Service) staleThresholdUnsafe(userID string, presence Presence) time.Duration {
+	threshold := s.staleThreshold
+	if domain.IsGuestID(userID) {
+		threshold = s.guestStaleThreshold
+	}
+
+	s.learningMu.RLock()
+	connState := s.connectionStates[presence.ClientID]
+	var cadence time.Duration
+	if connState != nil && connState.PingCount >= MinPingsForCadence {
+		cadence = connState.AveragePingInterval
+	}
+	s.learningMu.RUnlock()
+	if cadence <= 0 {
+		return threshold
+	}
+
+	multiplier := presence.TimeoutMultiplier
+	if multiplier <= 0 {
+		multiplier = StaleThresholdMultiplier
+	}
+	cadence *= time.Duration(multiplier)
+	if domain.IsGuestID(userID) && cadence > threshold {
+		return threshold
+	}
+	return min(cadence, s.maxStaleThreshold)
+}
+
 // removePresenceForClient removes a specific client connection for a user
 func (s *Service) removePresenceForClient(userID, clientID string) {
+	if userID == "" || clientID == "" {
+		s.logger.Warn("Skipping presence disconnect with missing IDs", logging.UserID(userID), logging.ClientID(clientID))
+		return
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	clientPresences, exists := s.presences[userID]
 	if !exists {
-		s.logger.Debug("User not found in presence list", "user_id", userID, "client_id", clientID)
+		s.logger.Debug("User not found in presence list", logging.UserID(userID), logging.ClientID(clientID))
 		return
 	}
 
@@ -351,13 +564,14 @@ func (s *Service) removePresenceForClient(userID, clientID string) {
 	if _, clientExists := clientPresences[clientID]; clientExists {
 		delete(clientPresences, clientID)
 		delete(s.clients, clientID)
-		s.metrics.disconnections++
-		s.metrics.totalConnections--
+		s.metrics.disconnections.Add(1)
+		s.metrics.totalConnections.Add(-1)
+		s.metrics.recent.add(s.now(), windowDisconnect)
 
 		// Update connection state - must be done while holding the main lock
 		// to avoid race conditions with concurrent access
 		if connState := s.connectionStates[clientID]; connState != nil {
-			now := Now()
+			now := s.now()
 			connState.DisconnectTime = &now
 			connState.Status = ConnectionOffline
 			connState.TotalUptime += now.Sub(connState.ConnectedAt)
@@ -367,23 +581,34 @@ func (s *Service) removePresenceForClient(userID, clientID string) {
 		s.trackConnectionEvent(userID, clientID, "disconnect", "client_disconnect")
 
 		s.logger.Info("Client disconnected",
-			"user_id", userID,
-			"client_id", clientID,
+			logging.UserID(userID),
+			logging.ClientID(clientID),
 			"remaining_connections", len(clientPresences))
+		s.publishConnectionCount(userID, len(clientPresences))
 	}
 
 	// If no more clients for this user, debounce the offline event
 	if len(clientPresences) == 0 {
+		predictedReconnect := s.predictReconnectionTime(userID)
+
+		// Cancel any existing debounce timer for this user
+		s.debounceMu.Lock()
+		if timer, exists := s.offlineDebounce[userID]; exists {
+			timer.Stop()
+			delete(s.offlineDebounce, userID) // Clean up immediately
+			delete(s.offlineAt, userID)
+		}
+		debounceDelay := s.offlineDebounceFor(userID, predictedReconnect)
+
 		// If debounce is disabled (0), mark offline immediately
-		if s.offlineDebounceDelay == 0 {
+		if debounceDelay == 0 {
+			s.debounceMu.Unlock()
 			delete(s.presences, userID)
+			s.leaveAllChannelsUnsafe(userID, ChannelOfflineReason)
 			// Clean up rate limiter timer for this user
-			if timer, exists := s.rateLimiter[userID]; exists {
-				timer.Stop()
-				delete(s.rateLimiter, userID)
-			}
+			s.clearRateLimit(userID)
 			s.logger.Info("User went offline immediately (debounce disabled)",
-				"user_id", userID)
+				logging.UserID(userID))
 
 			onlineUsers := s.getOnlineUsersUnsafe()
 			s.publishAsync(onlineUsers)
@@ -391,40 +616,29 @@ func (s *Service) removePresenceForClient(userID, clientID string) {
 		}
 
 		s.logger.Info("User has no more connections, scheduling offline event",
-			"user_id", userID,
-			"debounce_delay", s.offlineDebounceDelay)
-
-		// Cancel any existing debounce timer for this user
-		s.debounceMu.Lock()
-		if timer, exists := s.offlineDebounce[userID]; exists {
-			timer.Stop()
-			delete(s.offlineDebounce, userID) // Clean up immediately
-		}
-
-		// Use adaptive debounce delay based on user's reconnection patterns
-		debounceDelay := s.offlineDebounceDelay
-		if predictedReconnect := s.predictReconnectionTime(userID); predictedReconnect > 0 {
-			// If user typically reconnects quickly, use a shorter debounce
-			// but not shorter than 1 second to avoid being too aggressive
-			if predictedReconnect < debounceDelay && predictedReconnect > time.Second {
-				debounceDelay = predictedReconnect
-				s.logger.Debug("Using adaptive debounce delay",
-					"user_id", userID,
-					"predicted_reconnect", predictedReconnect,
-					"adaptive_delay", debounceDelay)
-			}
-		}
+			logging.UserID(userID),
+			"debounce_delay", debounceDelay)
 
 		// Schedule offline event after a delay (to handle page reloads, double-clicks, etc.)
-		s.offlineDebounce[userID] = time.AfterFunc(debounceDelay, func() {
+		var timer Timer
+		timer = s.clock.AfterFunc(debounceDelay, func() {
+			// Only act if this timer was not cancelled or replaced by a
+			// reconnect. debounceMu is released first because
+			// handleDebouncedOffline takes mu, which is acquired before
+			// debounceMu everywhere else.
 			s.debounceMu.Lock()
-			defer s.debounceMu.Unlock()
-			// Double-check the timer still exists and user is still offline
-			if timer, exists := s.offlineDebounce[userID]; exists && timer.Stop() {
-				delete(s.offlineDebounce, userID)
-				s.handleDebouncedOffline(userID)
+			current, exists := s.offlineDebounce[userID]
+			if !exists || current != timer {
+				s.debounceMu.Unlock()
+				return
 			}
+			delete(s.offlineDebounce, userID)
+			delete(s.offlineAt, userID)
+			s.debounceMu.Unlock()
+			s.handleDebouncedOffline(userID)
 		})
+		s.offlineDebounce[userID] = timer
+		s.offlineAt[userID] = s.now().Add(debounceDelay)
 		s.debounceMu.Unlock()
 
 		// Don't publish update yet - wait for debounce
@@ -436,25 +650,59 @@ func (s *Service) removePresenceForClient(userID, clientID string) {
 	s.publishAsync(onlineUsers)
 }
 
+// SetUserDebounce overrides the offline debounce for one user, e.g. for
+// someone on a flaky mobile network who needs longer than the default to
+// reconnect. The override takes precedence over both the configured delay
+// and the adaptive one learned from the user's reconnections; 0 marks the
+// user offline as soon as their last connection closes. A negative d
+// removes the override. It applies from the user's next disconnect.
+func (s *Service) SetUserDebounce(userID string, d time.Duration) {
+	s.debounceMu.Lock()
+	defer s.debounceMu.Unlock()
+	if d < 0 {
+		delete(s.userDebounce, userID)
+		return
+	}
+	s.userDebounce[userID] = d
+}
+
+// offlineDebounceFor returns how long userID stays online after their last
+// connection closes: their override if they have one, otherwise the
+// configured delay, shortened to predictedReconnect for users who usually
+// reconnect quickly (but never below a second, to avoid being too
+// aggressive). The caller must hold debounceMu.
+func (s *Service) offlineDebounceFor(userID string, predictedReconnect time.Duration) time.Duration {
+	if d, ok := s.userDebounce[userID]; ok {
+		return d
+	}
+	if predictedReconnect > time.Second && predictedReconnect < s.offlineDebounceDelay {
+		return predictedReconnect
+	}
+	return s.offlineDebounceDelay
+}
+
 // handleDebouncedOffline is called after the debounce period to mark a user as offline
 func (s *Service) handleDebouncedOffline(userID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// The publish queue is closed once the service shuts down.
+	if s.ctx.Err() != nil {
+		return
+	}
+
 	// Check if user reconnected during debounce period
 	clientPresences, exists := s.presences[userID]
 	if !exists || len(clientPresences) == 0 {
 		// User is still offline, remove them
 		delete(s.presences, userID)
+		s.leaveAllChannelsUnsafe(userID, ChannelOfflineReason)
 		// Clean up rate limiter timer for this user
-		if timer, exists := s.rateLimiter[userID]; exists {
-			timer.Stop()
-			delete(s.rateLimiter, userID)
-		}
-		s.metrics.debounceTimeouts++
+		s.clearRateLimit(userID)
+		s.metrics.debounceTimeouts.Add(1)
 
 		s.logger.Info("User went offline after debounce period",
-			"user_id", userID)
+			logging.UserID(userID))
 
 		// Publish update asynchronously
 		onlineUsers := s.getOnlineUsersUnsafe()
@@ -462,7 +710,7 @@ func (s *Service) handleDebouncedOffline(userID string) {
 	} else {
 		// User reconnected, cancel offline event
 		s.logger.Info("User reconnected during debounce period, staying online",
-			"user_id", userID,
+			logging.UserID(userID),
 			"connections", len(clientPresences))
 	}
 }
@@ -474,27 +722,29 @@ func (s *Service) removePresence(userID string) {
 
 	clientPresences, exists := s.presences[userID]
 	if !exists {
-		s.logger.Debug("User not found in presence list", "user_id", userID)
+		s.logger.Debug("User not found in presence list", logging.UserID(userID))
 		return
 	}
 
 	// Remove all client connections for this user
 	for clientID := range clientPresences {
 		delete(s.clients, clientID)
-		s.metrics.totalConnections--
+		s.metrics.totalConnections.Add(-1)
+		s.metrics.recent.add(s.now(), windowDisconnect)
 	}
 
 	// Clean up rate limiter timer for this user
-	if timer, exists := s.rateLimiter[userID]; exists {
-		timer.Stop()
-		delete(s.rateLimiter, userID)
-	}
+	s.clearRateLimit(userID)
 
 	// Remove user's presence map
 	delete(s.presences, userID)
+	s.leaveAllChannelsUnsafe(userID, ChannelOfflineReason)
+	if len(clientPresences) > 0 {
+		s.publishConnectionCount(userID, 0)
+	}
 
 	s.logger.Info("User disconnected",
-		"user_id", userID,
+		logging.UserID(userID),
 		"connections_removed", len(clientPresences),
 		"remaining_users", len(s.presences))
 
@@ -527,7 +777,26 @@ func (s *Service) GetPresence(userID string) (Presence, bool) {
 	return mostRecent, true
 }
 
-// GetOnlineUsers returns a list of currently online user IDs
+// GetUserConnections returns the presence entries for every active client of a
+// user, most recently seen first. It returns an empty slice if the user is offline.
+func (s *Service) GetUserConnections(userID string) []Presence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clientPresences := s.presences[userID]
+	connections := make([]Presence, 0, len(clientPresences))
+	for _, p := range clientPresences {
+		connections = append(connections, p)
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].Timestamp.After(connections[j].Timestamp)
+	})
+	return connections
+}
+
+// GetOnlineUsers returns a list of currently online user IDs. Guests are
+// included under their "guest:" IDs; see domain.IsGuestID.
 func (s *Service) GetOnlineUsers() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -535,6 +804,29 @@ func (s *Service) GetOnlineUsers() []string {
 	return s.getOnlineUsersUnsafe()
 }
 
+// GetAllOnlineUsers returns every online user ID, including users hidden by
+// WithUserFilter. It is meant for admin views.
+func (s *Service) GetAllOnlineUsers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.onlineUsersUnsafe(false)
+}
+
+// GetOnlineGuests returns the IDs of the guests that are currently online.
+func (s *Service) GetOnlineGuests() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var guests []string
+	for _, userID := range s.getOnlineUsersUnsafe() {
+		if domain.IsGuestID(userID) {
+			guests = append(guests, userID)
+		}
+	}
+	return guests
+}
+
 // AddPresenceWithClientType adds a presence entry with client type information
 func (s *Service) AddPresenceWithClientType(userID, clientID, userAgent, clientType string) {
 	s.addPresenceWithClientType(userID, clientID, userAgent, clientType)
@@ -558,14 +850,25 @@ func (s *Service) RemovePresenceForClient(userID, clientID string) {
 	s.removePresenceForClient(userID, clientID)
 }
 
-// getOnlineUsersUnsafe returns online users without acquiring lock (internal use)
+// getOnlineUsersUnsafe returns the visible online users without acquiring
+// lock (internal use). This is the list that is broadcast.
 func (s *Service) getOnlineUsersUnsafe() []string {
+	return s.onlineUsersUnsafe(true)
+}
+
+// onlineUsersUnsafe returns online users, leaving out hidden users if
+// visibleOnly is set. The caller must hold s.mu.
+func (s *Service) onlineUsersUnsafe(visibleOnly bool) []string {
 	// A user is online if they have at least one active client
 	result := make([]string, 0, len(s.presences))
 	for userID, clientPresences := range s.presences {
-		if len(clientPresences) > 0 {
-			result = append(result, userID)
+		if len(clientPresences) == 0 {
+			continue
 		}
+		if visibleOnly && s.hideUser != nil && s.hideUser(userID) {
+			continue
+		}
+		result = append(result, userID)
 	}
 
 	return result
@@ -580,35 +883,67 @@ func (s *Service) getTotalConnectionsUnsafe() int {
 	return total
 }
 
-// publishAsync sends a publish request to the background publishing goroutine
+// publishAsync sends a publish request to the background publishing goroutine.
+// If the queue is full the update replaces any pending overflow snapshot, so
+// the latest online-user list is always published and nothing stale is.
 func (s *Service) publishAsync(onlineUsers []string) {
+	s.pendingMu.Lock()
+	s.publishSeq++
 	req := publishRequest{
+		seq:         s.publishSeq,
 		onlineUsers: append([]string(nil), onlineUsers...), // Copy slice
 		done:        make(chan struct{}),
 	}
 	select {
 	case s.publishCh <- req:
+		s.pendingMu.Unlock()
 		// Request sent, wait for completion if needed
 		<-req.done
 	default:
-		s.logger.Warn("Publish channel full, dropping presence update")
+		if s.pending != nil {
+			s.metrics.coalescedUpdates.Add(1)
+		}
+		s.pending = &req
+		s.pendingMu.Unlock()
+		s.logger.Debug("Publish channel full, coalescing presence update", "seq", req.seq)
 	}
 }
 
+// takePending removes and returns the overflow snapshot, if any.
+func (s *Service) takePending() *publishRequest {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	req := s.pending
+	s.pending = nil
+	return req
+}
+
+// publishRequestIfNewer publishes req unless a newer snapshot was already sent.
+func (s *Service) publishRequestIfNewer(req publishRequest) {
+	if req.seq > s.publishedSeq.Load() {
+		s.publishPresenceUpdateWithUsers(req.onlineUsers)
+		s.publishedSeq.Store(req.seq)
+	} else {
+		s.metrics.coalescedUpdates.Add(1)
+	}
+	close(req.done)
+}
+
 // updateUserPatterns learns from user connection behavior
 func (s *Service) updateUserPatterns(userID string, connState *ConnectionState) {
 	// Initialize pattern if it doesn't exist
 	if s.userPatterns[userID] == nil {
 		s.userPatterns[userID] = &UserActivityPattern{
 			UserID:               userID,
-			LastActivity:         Now(),
+			LastActivity:         s.now(),
 			TotalConnections:     1,
 			SuccessfulReconnects: 0,
 		}
 	}
 
 	pattern := s.userPatterns[userID]
-	pattern.LastActivity = Now()
+	pattern.LastActivity = s.now()
 	pattern.TotalConnections++
 
 	// Learn reconnection patterns
@@ -642,9 +977,15 @@ func (s *Service) updateUserPatterns(userID string, connState *ConnectionState)
 // predictReconnectionTime estimates when a user might reconnect based on their patterns
 func (s *Service) predictReconnectionTime(userID string) time.Duration {
 	s.learningMu.RLock()
+	defer s.learningMu.RUnlock()
+	return s.predictReconnectionTimeUnsafe(userID)
+}
+
+// predictReconnectionTimeUnsafe is predictReconnectionTime for callers
+// holding learningMu.
+func (s *Service) predictReconnectionTimeUnsafe(userID string) time.Duration {
 	pattern := s.userPatterns[userID]
 	history := s.connectionHistory[userID]
-	s.learningMu.RUnlock()
 
 	if pattern == nil || pattern.AverageReconnectTime == 0 {
 		return 30 * time.Second // Default fallback
@@ -653,14 +994,14 @@ func (s *Service) predictReconnectionTime(userID string) time.Duration {
 	// Look at recent disconnect patterns
 	var recentDisconnects []time.Time
 	for _, event := range history {
-		if event.EventType == "disconnect" && Now().Sub(event.Timestamp) < 24*time.Hour {
+		if event.EventType == "disconnect" && s.now().Sub(event.Timestamp) < 24*time.Hour {
 			recentDisconnects = append(recentDisconnects, event.Timestamp)
 		}
 	}
 
 	// If user has disconnected recently and typically reconnects quickly, predict reconnection
 	if len(recentDisconnects) > 0 {
-		timeSinceLastDisconnect := Now().Sub(recentDisconnects[len(recentDisconnects)-1])
+		timeSinceLastDisconnect := s.now().Sub(recentDisconnects[len(recentDisconnects)-1])
 		if timeSinceLastDisconnect < pattern.AverageReconnectTime*2 {
 			// User might reconnect soon
 			return pattern.AverageReconnectTime - timeSinceLastDisconnect
@@ -679,7 +1020,7 @@ func (s *Service) trackConnectionEvent(userID, clientID, eventType, reason strin
 		ClientID:  clientID,
 		UserID:    userID,
 		EventType: eventType,
-		Timestamp: Now(),
+		Timestamp: s.now(),
 		Reason:    reason,
 	}
 
@@ -703,29 +1044,21 @@ func (s *Service) publishPresenceUpdateWithUsers(onlineUsers []string) {
 		Topic:   TopicUserStatusUpdate.Name(),
 		Payload: jsonPayload,
 	}
-	err := s.publisher.Publish(context.Background(), jsonMsg)
+	ctx, cancel := pubsub.WithPublishContext(s.ctx, 0)
+	defer cancel()
+	err := s.publisher.Publish(ctx, jsonMsg)
 	if err != nil {
-		s.metrics.publishErrors++
+		s.metrics.publishErrors.Add(1)
 		s.logger.Error("Failed to publish presence update",
 			"error", err,
-			"topic", TopicUserStatusUpdate.Name())
+			logging.Topic(TopicUserStatusUpdate.Name()))
 	} else {
 		s.logger.Info("Successfully published presence update")
 	}
 }
 
 func (s *Service) getCurrentPresenceWithUsers(onlineUsers []string) []byte {
-	// Create a map for the update message
-	update := struct {
-		Type  string   `json:"type"`
-		Users []string `json:"users"`
-	}{
-		Type:  "presence_update",
-		Users: onlineUsers,
-	}
-
-	// Marshal the update to JSON
-	payload, err := json.Marshal(update)
+	payload, err := json.Marshal(NewUpdatePayload(onlineUsers))
 	if err != nil {
 		s.logger.Error("Failed to marshal presence update", "error", err)
 		return nil
@@ -734,9 +1067,14 @@ func (s *Service) getCurrentPresenceWithUsers(onlineUsers []string) []byte {
 	return payload
 }
 
-// SubscribeToPresence subscribes to presence updates for all users
+// SubscribeToPresence subscribes to presence updates for all users. The
+// subscription ends when ctx is done or the service shuts down.
 func (s *Service) SubscribeToPresence(ctx context.Context, handler func(Presence) error, subscriber pubsub.Subscriber) error {
-	return subscriber.Subscribe(ctx, TopicUserStatusUpdate.Name(), func(ctx context.Context, msg pubsub.Message) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	context.AfterFunc(subCtx, func() { stop() })
+
+	err := subscriber.Subscribe(subCtx, TopicUserStatusUpdate.Name(), func(ctx context.Context, msg pubsub.Message) error {
 		var presence Presence
 		if err := json.Unmarshal(msg.Payload, &presence); err != nil {
 			s.logger.Error("Failed to unmarshal presence update", "error", err)
@@ -744,13 +1082,24 @@ func (s *Service) SubscribeToPresence(ctx context.Context, handler func(Presence
 		}
 		return handler(presence)
 	})
+	if err != nil {
+		cancel()
+	}
+	return err
 }
 
 // startPublishing handles publishing presence updates asynchronously to avoid lock contention
 func (s *Service) startPublishing() {
 	for req := range s.publishCh {
-		s.publishPresenceUpdateWithUsers(req.onlineUsers)
-		close(req.done)
+		s.publishRequestIfNewer(req)
+		// A pending snapshot is newer than everything queued before it, so
+		// publishing it now lets the older queued requests be skipped.
+		if pending := s.takePending(); pending != nil {
+			s.publishRequestIfNewer(*pending)
+		}
+	}
+	if pending := s.takePending(); pending != nil {
+		s.publishRequestIfNewer(*pending)
 	}
 }
 
@@ -760,9 +1109,13 @@ func (s *Service) startCleanup() {
 		select {
 		case <-s.cleanupTicker.C:
 			s.cleanupStalePresences()
+			s.purgeConnectionHistory()
 		case <-s.stopCleanup:
 			s.cleanupTicker.Stop()
 			return
+		case <-s.ctx.Done():
+			s.cleanupTicker.Stop()
+			return
 		}
 	}
 }
@@ -777,16 +1130,19 @@ func (s *Service) cleanupStalePresences() {
 
 	// Find and remove stale connections (conservative server-side approach)
 	for userID, clientPresences := range s.presences {
+		before := len(clientPresences)
 		for clientID, presence := range clientPresences {
-			timeSinceLastSeen := Now().Sub(presence.Timestamp)
+			timeSinceLastSeen := s.now().Sub(presence.Timestamp)
+			threshold := s.staleThresholdUnsafe(userID, presence)
 
-			// Conservative: Only remove if significantly past threshold (3 minutes + 30 second buffer)
-			if timeSinceLastSeen > s.staleThreshold+(30*time.Second) {
+			// Conservative: Only remove if significantly past threshold (plus a 30 second buffer)
+			if timeSinceLastSeen > threshold+(30*time.Second) {
 				delete(clientPresences, clientID)
 				delete(s.clients, clientID)
 				totalStaleConnections++
-				s.metrics.staleCleanups++
-				s.metrics.totalConnections--
+				s.metrics.staleCleanups.Add(1)
+				s.metrics.totalConnections.Add(-1)
+				s.metrics.recent.add(s.now(), windowDisconnect)
 
 				// Update connection state
 				s.learningMu.Lock()
@@ -798,22 +1154,24 @@ func (s *Service) cleanupStalePresences() {
 				s.trackConnectionEvent(userID, clientID, "disconnect", "stale_cleanup")
 
 				s.logger.Info("Removed stale connection (conservative cleanup)",
-					"user_id", userID,
-					"client_id", clientID,
+					logging.UserID(userID),
+					logging.ClientID(clientID),
 					"last_seen", presence.Timestamp,
 					"time_since_last_seen", timeSinceLastSeen,
-					"threshold", s.staleThreshold)
+					"threshold", threshold)
 			}
 		}
 
+		if len(clientPresences) != before {
+			s.publishConnectionCount(userID, len(clientPresences))
+		}
+
 		// Remove user if no clients remain
 		if len(clientPresences) == 0 {
 			delete(s.presences, userID)
+			s.leaveAllChannelsUnsafe(userID, ChannelStaleReason)
 			// Clean up rate limiter timer for this user
-			if timer, exists := s.rateLimiter[userID]; exists {
-				timer.Stop()
-				delete(s.rateLimiter, userID)
-			}
+			s.clearRateLimit(userID)
 			staleUsers = append(staleUsers, userID)
 		}
 	}
@@ -867,7 +1225,7 @@ func (s *Service) GetPresenceProbability(userID string) float64 {
 		}
 
 		// Recent activity increases confidence
-		if Now().Sub(pattern.LastActivity) < 30*time.Second {
+		if s.now().Sub(pattern.LastActivity) < 30*time.Second {
 			baseProbability += 0.05
 		}
 	}
@@ -882,26 +1240,68 @@ func (s *Service) GetPresenceProbability(userID string) float64 {
 	return baseProbability
 }
 
-// GetMetrics returns current presence service metrics
+// GetMetrics returns current presence service metrics. Counters are
+// cumulative since startup or the last ResetMetrics, except the
+// *_last_minute ones, which count events within the last MetricsWindow.
+// history_users is the number of users whose connection history is kept.
 func (s *Service) GetMetrics() map[string]int64 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
+	recent := s.metrics.recent.counts(s.now())
+	s.learningMu.RLock()
+	historyUsers := len(s.connectionHistory)
+	s.learningMu.RUnlock()
 	return map[string]int64{
-		"total_connections": s.metrics.totalConnections,
-		"total_users":       s.metrics.totalUsers,
-		"disconnections":    s.metrics.disconnections,
-		"reconnections":     s.metrics.reconnections,
-		"stale_cleanups":    s.metrics.staleCleanups,
-		"rate_limit_hits":   s.metrics.rateLimitHits,
-		"debounce_timeouts": s.metrics.debounceTimeouts,
-		"publish_errors":    s.metrics.publishErrors,
-		"adaptive_cleanups": s.metrics.adaptiveCleanups,
+		"total_connections": s.metrics.totalConnections.Load(),
+		"total_users":       s.metrics.totalUsers.Load(),
+		"disconnections":    s.metrics.disconnections.Load(),
+		"reconnections":     s.metrics.reconnections.Load(),
+		"stale_cleanups":    s.metrics.staleCleanups.Load(),
+		"rate_limit_hits":   s.metrics.rateLimitHits.Load(),
+		"debounce_timeouts": s.metrics.debounceTimeouts.Load(),
+		"publish_errors":    s.metrics.publishErrors.Load(),
+		"adaptive_cleanups": s.metrics.adaptiveCleanups.Load(),
+		"coalesced_updates": s.metrics.coalescedUpdates.Load(),
+		"history_users":     int64(historyUsers),
+
+		"connections_last_minute":    recent[windowConnect],
+		"disconnections_last_minute": recent[windowDisconnect],
+		"reconnections_last_minute":  recent[windowReconnect],
 	}
 }
 
+// ResetMetrics zeroes the cumulative and windowed counters, e.g. between
+// tests sharing a service. total_connections and total_users describe the
+// current state rather than past events, so they are kept.
+func (s *Service) ResetMetrics() {
+	s.metrics.disconnections.Store(0)
+	s.metrics.reconnections.Store(0)
+	s.metrics.staleCleanups.Store(0)
+	s.metrics.rateLimitHits.Store(0)
+	s.metrics.debounceTimeouts.Store(0)
+	s.metrics.publishErrors.Store(0)
+	s.metrics.adaptiveCleanups.Store(0)
+	s.metrics.coalescedUpdates.Store(0)
+	s.metrics.recent.reset()
+}
+
 // Shutdown gracefully stops the presence service
 func (s *Service) Shutdown() {
+	if s.snapshots != nil {
+		s.saveSnapshot()
+	}
+	s.cancel() // End subscriptions made through the service
 	close(s.stopCleanup)
+
+	// Drop pending offline events; a debounce callback already past its
+	// timer check sees the cancelled context once it holds mu.
+	s.debounceMu.Lock()
+	for userID, timer := range s.offlineDebounce {
+		timer.Stop()
+		delete(s.offlineDebounce, userID)
+		delete(s.offlineAt, userID)
+	}
+	s.debounceMu.Unlock()
+
+	s.mu.Lock()
 	close(s.publishCh) // Stop the publishing goroutine
+	s.mu.Unlock()
 }
@@ -2,14 +2,17 @@ package presence
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/nfrund/goby/internal/domain"
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/topicmgr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockPublisher implements pubsub.Publisher for testing
@@ -25,6 +28,10 @@ func (m *mockPublisher) Publish(ctx context.Context, msg pubsub.Message) error {
 	return nil
 }
 
+func (m *mockPublisher) HasSubscribers(topic string) bool {
+	return true
+}
+
 func (m *mockPublisher) Close() error {
 	return nil
 }
@@ -37,6 +44,18 @@ func (m *mockPublisher) getMessages() []pubsub.Message {
 	return result
 }
 
+// statusUpdates returns the presence updates published on
+// TopicUserStatusUpdate, leaving out other presence events.
+func (m *mockPublisher) statusUpdates() []pubsub.Message {
+	var updates []pubsub.Message
+	for _, msg := range m.getMessages() {
+		if msg.Topic == TopicUserStatusUpdate.Name() {
+			updates = append(updates, msg)
+		}
+	}
+	return updates
+}
+
 // mockSubscriber implements pubsub.Subscriber for testing
 type mockSubscriber struct{}
 
@@ -53,7 +72,7 @@ func TestService_AddPresence(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Test adding a user
@@ -73,9 +92,21 @@ func TestService_AddPresence(t *testing.T) {
 	assert.Equal(t, "test-agent", presence.UserAgent)
 
 	// Check that a message was published
-	messages := publisher.getMessages()
+	messages := publisher.statusUpdates()
 	assert.Len(t, messages, 1)
 	assert.Equal(t, TopicUserStatusUpdate.Name(), messages[0].Topic)
+
+	var update UpdatePayload
+	require.NoError(t, json.Unmarshal(messages[0].Payload, &update))
+	assert.Equal(t, NewUpdatePayload([]string{"user1"}), update)
+}
+
+func TestUpdatePayload_JSON(t *testing.T) {
+	payload, err := json.Marshal(NewUpdatePayload(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"presence_update","version":1,"users":[]}`, string(payload))
+
+	assert.Equal(t, UpdatePayloadVersion, TopicUserStatusUpdate.Metadata()["schema_version"])
 }
 
 func TestService_RemovePresence(t *testing.T) {
@@ -83,7 +114,7 @@ func TestService_RemovePresence(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Add a user first
@@ -101,16 +132,32 @@ func TestService_RemovePresence(t *testing.T) {
 	assert.False(t, exists)
 
 	// Check that two messages were published (add + remove)
-	messages := publisher.getMessages()
+	messages := publisher.statusUpdates()
 	assert.Len(t, messages, 2)
 }
 
+func TestService_SkipsEventsWithMissingIDs(t *testing.T) {
+	publisher := &mockPublisher{}
+	service := NewService(context.Background(), publisher, &mockSubscriber{}, topicmgr.Default())
+	defer service.Shutdown()
+
+	service.AddPresenceWithClientType("", "client1", "", "html")
+	service.AddPresenceWithClientType("user1", "", "", "html")
+	assert.Empty(t, service.GetOnlineUsers())
+	assert.Empty(t, publisher.getMessages())
+
+	service.addPresence("user1", "client1", "test-agent")
+	service.RemovePresenceForClient("user1", "")
+	service.RemovePresenceForClient("", "client1")
+	assert.Equal(t, []string{"user1"}, service.GetOnlineUsers(), "a partial disconnect leaves presence intact")
+}
+
 func TestService_ConcurrentAccess(t *testing.T) {
 	publisher := &mockPublisher{}
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Test concurrent adds and removes with unique user/client IDs to avoid conflicts
@@ -161,23 +208,23 @@ func TestService_RateLimit(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// First update should succeed
 	service.addPresence("user1", "client1", "test-agent")
-	messages := publisher.getMessages()
+	messages := publisher.statusUpdates()
 	assert.Len(t, messages, 1)
 
 	// Immediate second update should be rate limited
 	service.addPresence("user1", "client1", "test-agent")
-	messages = publisher.getMessages()
+	messages = publisher.statusUpdates()
 	assert.Len(t, messages, 1) // Still only 1 message
 
 	// Wait for rate limit to expire and try again
 	time.Sleep(1100 * time.Millisecond) // Slightly longer than rate limit window
 	service.addPresence("user1", "client1", "test-agent")
-	messages = publisher.getMessages()
+	messages = publisher.statusUpdates()
 	assert.Len(t, messages, 2) // Now should have 2 messages
 }
 
@@ -186,7 +233,7 @@ func TestService_CleanupStalePresences(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Add a user
@@ -215,7 +262,7 @@ func TestService_GetOnlineUsers_UniqueUsers(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Add same user with different clients (simulating multiple tabs)
@@ -233,7 +280,7 @@ func TestService_MultipleConnections(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Add multiple connections for the same user
@@ -269,12 +316,45 @@ func TestService_MultipleConnections(t *testing.T) {
 	assert.Len(t, users, 0)
 }
 
+func TestService_GetUserConnections(t *testing.T) {
+	publisher := &mockPublisher{}
+	subscriber := &mockSubscriber{}
+	topicMgr := topicmgr.Default()
+
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
+	defer service.Shutdown()
+
+	assert.Empty(t, service.GetUserConnections("user1"))
+
+	service.AddPresenceWithClientType("user1", "desktop-client", "Firefox", "desktop")
+	time.Sleep(2 * time.Millisecond)
+	service.clearRateLimit("user1") // bypass the per-user rate limit
+	service.AddPresenceWithClientType("user1", "phone-client", "Safari iOS", "mobile")
+	service.AddPresenceWithClientType("user2", "other-client", "Chrome", "desktop")
+
+	connections := service.GetUserConnections("user1")
+	assert.Len(t, connections, 2)
+
+	// Most recently seen connection first, with client details preserved.
+	assert.Equal(t, "phone-client", connections[0].ClientID)
+	assert.Equal(t, "mobile", connections[0].ClientType)
+	assert.Equal(t, "Safari iOS", connections[0].UserAgent)
+	assert.Equal(t, "desktop-client", connections[1].ClientID)
+	assert.Equal(t, "desktop", connections[1].ClientType)
+	assert.Equal(t, "Firefox", connections[1].UserAgent)
+
+	// GetPresence still collapses to the most recent connection.
+	presence, exists := service.GetPresence("user1")
+	assert.True(t, exists)
+	assert.Equal(t, "phone-client", presence.ClientID)
+}
+
 func TestService_ReloadScenario(t *testing.T) {
 	publisher := &mockPublisher{}
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Simulate initial connection
@@ -313,7 +393,7 @@ func TestService_Metrics(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr)
+	service := NewService(context.Background(), publisher, subscriber, topicMgr)
 	defer service.Shutdown()
 
 	// Test initial metrics
@@ -345,12 +425,65 @@ func TestService_Metrics(t *testing.T) {
 	assert.Equal(t, int64(1), metrics["disconnections"])
 }
 
+// TestService_ConcurrentMetrics exercises metric updates from every code path
+// (connect, disconnect, rate limiting, debounce) while metrics are being read.
+// Run with -race (make test-race) to detect unsynchronized counter access.
+func TestService_ConcurrentMetrics(t *testing.T) {
+	publisher := &mockPublisher{}
+	subscriber := &mockSubscriber{}
+	topicMgr := topicmgr.Default()
+
+	service := NewService(context.Background(), publisher, subscriber, topicMgr, WithOfflineDebounce(time.Millisecond))
+	defer service.Shutdown()
+
+	const numWorkers = 8
+	const numOperations = 50
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				metrics := service.GetMetrics()
+				assert.GreaterOrEqual(t, metrics["rate_limit_hits"], int64(0))
+			}
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		workers.Add(1)
+		go func(w int) {
+			defer workers.Done()
+			userID := fmt.Sprintf("user%d", w%2) // shared users trigger rate limiting
+			for i := 0; i < numOperations; i++ {
+				clientID := fmt.Sprintf("client%d-%d", w, i)
+				service.AddPresenceWithClientType(userID, clientID, "agent", "desktop")
+				service.RemovePresenceForClient(userID, clientID)
+			}
+		}(w)
+	}
+
+	workers.Wait()
+	time.Sleep(20 * time.Millisecond) // let debounce timers fire
+	close(done)
+	readers.Wait()
+
+	metrics := service.GetMetrics()
+	assert.Greater(t, metrics["rate_limit_hits"], int64(0))
+}
+
 func TestService_DebounceTimeout(t *testing.T) {
 	publisher := &mockPublisher{}
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr, WithOfflineDebounce(100*time.Millisecond))
+	service := NewService(context.Background(), publisher, subscriber, topicMgr, WithOfflineDebounce(100*time.Millisecond))
 	defer service.Shutdown()
 
 	// Add a user
@@ -394,7 +527,7 @@ func TestService_PresenceProbability(t *testing.T) {
 	subscriber := &mockSubscriber{}
 	topicMgr := topicmgr.Default()
 
-	service := NewService(publisher, subscriber, topicMgr, WithOfflineDebounce(100*time.Millisecond))
+	service := NewService(context.Background(), publisher, subscriber, topicMgr, WithOfflineDebounce(100*time.Millisecond))
 	defer service.Shutdown()
 
 	// Test offline user
@@ -412,3 +545,579 @@ func TestService_PresenceProbability(t *testing.T) {
 	assert.Contains(t, metrics, "total_connections")
 	assert.Contains(t, metrics, "total_users")
 }
+
+// gatedPublisher blocks every Publish until release is closed.
+type gatedPublisher struct {
+	mockPublisher
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (g *gatedPublisher) Publish(ctx context.Context, msg pubsub.Message) error {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	return g.mockPublisher.Publish(ctx, msg)
+}
+
+func TestService_PublishCoalescesOverflow(t *testing.T) {
+	publisher := &gatedPublisher{started: make(chan struct{}), release: make(chan struct{})}
+	service := NewService(context.Background(), publisher, &mockSubscriber{}, topicmgr.Default(), WithPublishBufferSize(1))
+	defer service.Shutdown()
+
+	// "a" is picked up and blocks in Publish; "b" fills the queue.
+	go service.publishAsync([]string{"a"})
+	<-publisher.started
+	go service.publishAsync([]string{"b"})
+	assert.Eventually(t, func() bool { return len(service.publishCh) == 1 }, time.Second, time.Millisecond)
+
+	// The queue is full: "c" is parked and then replaced by "d".
+	service.publishAsync([]string{"c"})
+	service.publishAsync([]string{"d"})
+	close(publisher.release)
+
+	assert.Eventually(t, func() bool { return len(publisher.getMessages()) == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond) // make sure the stale "b" is not published afterwards
+
+	messages := publisher.getMessages()
+	assert.Len(t, messages, 2)
+	assert.Contains(t, string(messages[0].Payload), `"a"`)
+	assert.Contains(t, string(messages[1].Payload), `"d"`, "the newest snapshot is published last")
+	assert.Equal(t, int64(2), service.GetMetrics()["coalesced_updates"])
+}
+
+func TestService_SubscriptionsEndOnShutdown(t *testing.T) {
+	topic := TopicUserStatusUpdate.Name()
+	noop := func(Presence) error { return nil }
+
+	t.Run("shutdown", func(t *testing.T) {
+		bridge := pubsub.NewWatermillBridge()
+		defer bridge.Close()
+		service := NewService(context.Background(), bridge, bridge, topicmgr.Default())
+
+		if err := service.SubscribeToPresence(context.Background(), noop, bridge); err != nil {
+			t.Fatalf("SubscribeToPresence: %v", err)
+		}
+		assert.True(t, bridge.HasSubscribers(topic))
+
+		service.Shutdown()
+		assert.Eventually(t, func() bool { return !bridge.HasSubscribers(topic) }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("parent context cancelled", func(t *testing.T) {
+		bridge := pubsub.NewWatermillBridge()
+		defer bridge.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		service := NewService(ctx, bridge, bridge, topicmgr.Default())
+		defer service.Shutdown()
+
+		if err := service.SubscribeToPresence(context.Background(), noop, bridge); err != nil {
+			t.Fatalf("SubscribeToPresence: %v", err)
+		}
+		assert.True(t, bridge.HasSubscribers(topic))
+
+		cancel()
+		assert.Eventually(t, func() bool { return !bridge.HasSubscribers(topic) }, time.Second, 10*time.Millisecond)
+	})
+}
+
+// fakeClock is a Clock whose time only moves when Advance is called. Due
+// timers run synchronously inside Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	when    time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and runs the timers that came due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.when.After(c.now):
+			t.stopped = true
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func TestService_OfflineDebounceWithFakeClock(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(5*time.Second))
+	defer service.Shutdown()
+
+	pendingDebounce := func() bool {
+		service.debounceMu.Lock()
+		defer service.debounceMu.Unlock()
+		_, ok := service.offlineDebounce["user1"]
+		return ok
+	}
+
+	service.addPresence("user1", "client1", "browser")
+	service.removePresenceForClient("user1", "client1")
+	assert.True(t, pendingDebounce())
+
+	clock.Advance(4 * time.Second)
+	assert.True(t, pendingDebounce(), "debounce should still be pending before the delay")
+	assert.Equal(t, int64(0), service.GetMetrics()["debounce_timeouts"])
+
+	// A reconnect inside the window cancels the offline event.
+	service.addPresence("user1", "client2", "browser")
+	assert.False(t, pendingDebounce())
+	assert.Equal(t, int64(1), service.GetMetrics()["reconnections"])
+
+	service.removePresenceForClient("user1", "client2")
+	clock.Advance(5 * time.Second)
+	assert.False(t, pendingDebounce())
+	assert.Equal(t, int64(1), service.GetMetrics()["debounce_timeouts"])
+	_, exists := service.GetPresence("user1")
+	assert.False(t, exists)
+}
+
+func TestService_UserDebounceOverride(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(5*time.Second))
+	defer service.Shutdown()
+
+	// user1 has learned to reconnect within 2s, so the adaptive debounce is
+	// shorter than the global one.
+	service.learningMu.Lock()
+	service.userPatterns["user1"] = &UserActivityPattern{UserID: "user1", AverageReconnectTime: 2 * time.Second}
+	service.learningMu.Unlock()
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("user2", "client2", "browser")
+	assert.Equal(t, map[string]time.Duration{"user1": 2 * time.Second, "user2": 5 * time.Second}, service.DebugSnapshot().Debounce)
+
+	// The override wins over both.
+	service.SetUserDebounce("user1", 30*time.Second)
+	service.SetUserDebounce("user2", 30*time.Second)
+	assert.Equal(t, map[string]time.Duration{"user1": 30 * time.Second, "user2": 30 * time.Second}, service.DebugSnapshot().Debounce)
+
+	service.removePresenceForClient("user1", "client1")
+	assert.Equal(t, clock.Now().Add(30*time.Second), service.DebugSnapshot().PendingOffline["user1"])
+	clock.Advance(29 * time.Second)
+	assert.Contains(t, service.DebugSnapshot().PendingOffline, "user1", "the override outlasts the adaptive and global delays")
+	clock.Advance(time.Second)
+	assert.NotContains(t, service.DebugSnapshot().Users, "user1")
+	assert.Equal(t, int64(1), service.GetMetrics()["debounce_timeouts"])
+
+	// Removing the override restores the global delay.
+	service.SetUserDebounce("user2", -1)
+	assert.Equal(t, 5*time.Second, service.DebugSnapshot().Debounce["user2"])
+}
+
+func TestService_PurgesHistoryOfAbsentUsers(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(0), WithStaleThreshold(48*time.Hour), WithHistoryRetention(24*time.Hour))
+	defer service.Shutdown()
+
+	// user1 leaves for good, user2 stays connected, user3 leaves later.
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("user2", "client2", "browser")
+	service.removePresenceForClient("user1", "client1")
+	clock.Advance(23 * time.Hour)
+	service.addPresence("user3", "client3", "browser")
+	service.removePresenceForClient("user3", "client3")
+	assert.Equal(t, int64(3), service.GetMetrics()["history_users"])
+	service.learningMu.RLock()
+	assert.Equal(t, ConnectionOffline, service.connectionStates["client1"].Status)
+	service.learningMu.RUnlock()
+
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, 1, service.purgeConnectionHistory())
+
+	service.learningMu.RLock()
+	assert.NotContains(t, service.connectionHistory, "user1", "absent past the retention window")
+	assert.NotContains(t, service.userPatterns, "user1")
+	assert.NotContains(t, service.connectionStates, "client1")
+	assert.Contains(t, service.connectionHistory, "user2", "still connected")
+	assert.Contains(t, service.connectionHistory, "user3", "absent within the retention window")
+	service.learningMu.RUnlock()
+	assert.Equal(t, int64(2), service.GetMetrics()["history_users"])
+}
+
+func TestService_StaleCleanupWithFakeClock(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(time.Minute))
+	defer service.Shutdown()
+
+	service.addPresence("user1", "client1", "browser")
+	clock.Advance(time.Minute)
+	service.addPresence("user2", "client2", "browser")
+
+	// user1 is past the threshold plus its 30 second buffer; user2 is not.
+	clock.Advance(31 * time.Second)
+	service.cleanupStalePresences()
+
+	assert.Equal(t, []string{"user2"}, service.GetOnlineUsers())
+	assert.Equal(t, int64(1), service.GetMetrics()["stale_cleanups"])
+}
+
+func TestService_PingCadenceSetsStaleThreshold(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(time.Minute))
+	defer service.Shutdown()
+
+	heartbeat := func(userID, clientID string) {
+		// Heartbeats come faster than the rate-limit window allows.
+		service.clearRateLimit(userID)
+		service.AddPresenceWithClientConfig(userID, clientID, "", "mobile", 0, 2)
+	}
+	state := func(clientID string) ConnectionState {
+		return service.DebugSnapshot().Connections[clientID]
+	}
+
+	// A mobile client pinging every 3 minutes...
+	heartbeat("user1", "slow")
+	heartbeat("user2", "fast")
+	for _, interval := range []time.Duration{2 * time.Minute, 3 * time.Minute, 4 * time.Minute} {
+		clock.Advance(interval)
+		heartbeat("user1", "slow")
+	}
+	slow := state("slow")
+	assert.Equal(t, 3, slow.PingCount)
+	assert.Equal(t, 3*time.Minute, slow.AveragePingInterval)
+	assert.Zero(t, slow.ReconnectCount, "heartbeats are not reconnections")
+
+	// ...outlives the configured threshold, while one that has not yet
+	// established a cadence is cleaned up as before.
+	clock.Advance(5 * time.Minute)
+	service.cleanupStalePresences()
+	assert.Equal(t, []string{"user1"}, service.GetAllOnlineUsers())
+
+	// Past twice its cadence plus the buffer it is stale too.
+	clock.Advance(time.Minute + 31*time.Second)
+	service.cleanupStalePresences()
+	assert.Empty(t, service.GetAllOnlineUsers())
+}
+
+func TestService_MaxStaleThresholdCapsCadence(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(time.Minute), WithMaxStaleThreshold(10*time.Minute))
+	defer service.Shutdown()
+
+	// A client claiming an hourly cadence and a large multiplier...
+	for range MinPingsForCadence + 1 {
+		service.clearRateLimit("user1")
+		service.AddPresenceWithClientConfig("user1", "client1", "", "mobile", 0, 100)
+		clock.Advance(time.Hour)
+	}
+
+	// ...is still dropped past the maximum plus the buffer since its last heartbeat.
+	service.clearRateLimit("user1")
+	service.AddPresenceWithClientConfig("user1", "client1", "", "mobile", 0, 100)
+	clock.Advance(10*time.Minute + 31*time.Second)
+	service.cleanupStalePresences()
+	assert.Empty(t, service.GetAllOnlineUsers())
+}
+
+func TestRecordPing_MovingAverage(t *testing.T) {
+	clock := newFakeClock()
+	service := &Service{clock: clock}
+	state := &ConnectionState{LastSeen: clock.Now()}
+
+	for range pingAverageWindow {
+		clock.Advance(10 * time.Second)
+		service.recordPing(state)
+	}
+	assert.Equal(t, pingAverageWindow, state.PingCount)
+	assert.Equal(t, 10*time.Second, state.AveragePingInterval)
+
+	// Later heartbeats move the average a tenth of the way each.
+	clock.Advance(20 * time.Second)
+	service.recordPing(state)
+	assert.Equal(t, 11*time.Second, state.AveragePingInterval)
+}
+
+func TestService_GuestsUseShorterStaleThreshold(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(5*time.Minute), WithGuestStaleThreshold(time.Minute))
+	defer service.Shutdown()
+
+	guestID := domain.NewGuestID()
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence(guestID, "client2", "browser")
+
+	guest, ok := service.GetPresence(guestID)
+	require.True(t, ok)
+	assert.True(t, guest.Guest)
+	assert.Equal(t, []string{guestID}, service.GetOnlineGuests())
+
+	// The guest is past its threshold plus the 30 second buffer; the user is not.
+	clock.Advance(91 * time.Second)
+	service.cleanupStalePresences()
+
+	assert.Equal(t, []string{"user1"}, service.GetOnlineUsers())
+	assert.Empty(t, service.GetOnlineGuests())
+}
+
+func TestService_UserFilterHidesFromBroadcast(t *testing.T) {
+	publisher := &mockPublisher{}
+	service := NewService(context.Background(), publisher, &mockSubscriber{}, topicmgr.Default(),
+		WithUserFilter(MatchUsers([]string{"bot-*@example.com"})))
+	defer service.Shutdown()
+
+	service.addPresence("bot-monitor@example.com", "client1", "probe")
+	service.addPresence("alice@example.com", "client2", "browser")
+
+	// The bot is tracked...
+	_, tracked := service.GetPresence("bot-monitor@example.com")
+	assert.True(t, tracked)
+	assert.ElementsMatch(t, []string{"bot-monitor@example.com", "alice@example.com"}, service.GetAllOnlineUsers())
+
+	// ...but absent from the public list and every broadcast.
+	assert.Equal(t, []string{"alice@example.com"}, service.GetOnlineUsers())
+	require.Eventually(t, func() bool { return len(publisher.statusUpdates()) == 2 }, time.Second, 5*time.Millisecond)
+	for _, msg := range publisher.statusUpdates() {
+		var update struct {
+			Users []string `json:"users"`
+		}
+		require.NoError(t, json.Unmarshal(msg.Payload, &update))
+		assert.NotContains(t, update.Users, "bot-monitor@example.com")
+	}
+	assert.Contains(t, string(publisher.statusUpdates()[1].Payload), "alice@example.com")
+}
+
+func TestMatchUsers(t *testing.T) {
+	assert.Nil(t, MatchUsers(nil))
+	assert.Nil(t, MatchUsers([]string{"", "[bad"}))
+
+	hide := MatchUsers([]string{"monitor@example.com", "bot-*"})
+	assert.True(t, hide("monitor@example.com"))
+	assert.True(t, hide("bot-1"))
+	assert.False(t, hide("alice@example.com"))
+}
+
+func TestService_DebugSnapshot(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithUserFilter(MatchUsers([]string{"bot"})))
+	defer service.Shutdown()
+
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("bot", "client2", "probe")
+	service.addPresence("user2", "client3", "browser")
+	service.removePresenceForClient("user2", "client3")
+
+	snap := service.DebugSnapshot()
+	assert.Equal(t, clock.Now(), snap.GeneratedAt)
+	assert.Len(t, snap.Users, 3, "hidden users are included")
+	require.Len(t, snap.Users["user1"], 1)
+	assert.Equal(t, "client1", snap.Users["user1"][0].ClientID)
+	assert.Empty(t, snap.Users["user2"])
+	assert.Equal(t, map[string]time.Time{"user2": clock.Now().Add(OfflineDebounceDelay)}, snap.PendingOffline)
+	assert.Contains(t, snap.RateLimited, "user1")
+	assert.Len(t, snap.Connections, 3)
+	assert.Equal(t, int64(1), snap.Metrics["disconnections"])
+
+	// The snapshot is a copy: changing it leaves the service untouched.
+	snap.Users["user1"][0].ClientID = "changed"
+	delete(snap.PendingOffline, "user2")
+	assert.Equal(t, "client1", service.DebugSnapshot().Users["user1"][0].ClientID)
+	assert.Contains(t, service.DebugSnapshot().PendingOffline, "user2")
+
+	// Rate-limit windows that have passed are not reported.
+	clock.Advance(2 * time.Second)
+	assert.Empty(t, service.DebugSnapshot().RateLimited)
+}
+
+func TestService_ChannelCleanup(t *testing.T) {
+	clock := newFakeClock()
+	publisher := &mockPublisher{}
+	service := NewService(context.Background(), publisher, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(5*time.Second))
+	defer service.Shutdown()
+
+	emptied := func() []ChannelEmptyEvent {
+		var events []ChannelEmptyEvent
+		for _, msg := range publisher.getMessages() {
+			if msg.Topic != TopicChannelEmpty.Name() {
+				continue
+			}
+			var event ChannelEmptyEvent
+			require.NoError(t, json.Unmarshal(msg.Payload, &event))
+			events = append(events, event)
+		}
+		return events
+	}
+
+	assert.False(t, service.JoinChannel("user1", "room1"), "offline users cannot join")
+
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("user2", "client2", "browser")
+	require.True(t, service.JoinChannel("user1", "room1"))
+	require.True(t, service.JoinChannel("user2", "room1"))
+
+	// The channel survives until its last member leaves.
+	service.LeaveChannel("user1", "room1")
+	assert.Equal(t, []string{"user2"}, service.ChannelMembers("room1"))
+	service.LeaveChannel("user2", "room1")
+	assert.Empty(t, service.Channels())
+	require.Eventually(t, func() bool { return len(emptied()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, ChannelEmptyEvent{Channel: "room1", Reason: ChannelLeftReason, Timestamp: clock.Now()}, emptied()[0])
+
+	// Rejoining recreates the channel from scratch.
+	require.True(t, service.JoinChannel("user2", "room1"))
+	assert.Equal(t, []string{"user2"}, service.ChannelMembers("room1"))
+
+	// Disconnecting keeps the membership through the offline debounce, so a
+	// reload does not empty the channel.
+	service.removePresenceForClient("user2", "client2")
+	clock.Advance(4 * time.Second)
+	assert.Equal(t, []string{"room1"}, service.Channels())
+
+	clock.Advance(time.Second)
+	assert.Empty(t, service.Channels())
+	require.Eventually(t, func() bool { return len(emptied()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, ChannelOfflineReason, emptied()[1].Reason)
+}
+
+func TestService_WindowedMetrics(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(5*time.Second))
+	defer service.Shutdown()
+
+	lastMinute := func() [3]int64 {
+		m := service.GetMetrics()
+		return [3]int64{m["connections_last_minute"], m["disconnections_last_minute"], m["reconnections_last_minute"]}
+	}
+
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("user2", "client2", "browser")
+	service.addPresence("user2", "client2", "browser") // a heartbeat is not a connection
+	clock.Advance(20 * time.Second)
+	service.removePresenceForClient("user1", "client1")
+	service.addPresence("user1", "client3", "browser") // reconnects within the debounce
+	assert.Equal(t, [3]int64{3, 1, 1}, lastMinute())
+
+	// The first two connections leave the window after a minute.
+	clock.Advance(45 * time.Second)
+	assert.Equal(t, [3]int64{1, 1, 1}, lastMinute())
+	assert.Equal(t, int64(2), service.GetMetrics()["total_connections"], "current connections are unaffected")
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, [3]int64{0, 0, 0}, lastMinute())
+	assert.Equal(t, int64(1), service.GetMetrics()["reconnections"], "cumulative counters keep their totals")
+
+	// Long idle gaps clear the whole ring.
+	service.addPresence("user3", "client4", "browser")
+	clock.Advance(time.Hour)
+	assert.Equal(t, [3]int64{0, 0, 0}, lastMinute())
+}
+
+func TestService_ResetMetrics(t *testing.T) {
+	clock := newFakeClock()
+	service := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(0))
+	defer service.Shutdown()
+
+	service.addPresence("user1", "client1", "browser")
+	service.addPresence("user2", "client2", "browser")
+	service.removePresenceForClient("user2", "client2")
+	require.Equal(t, int64(1), service.GetMetrics()["disconnections"])
+
+	service.ResetMetrics()
+	metrics := service.GetMetrics()
+	assert.Zero(t, metrics["disconnections"])
+	assert.Zero(t, metrics["connections_last_minute"])
+	assert.Zero(t, metrics["disconnections_last_minute"])
+	assert.Equal(t, int64(1), metrics["total_connections"], "the live connection count is kept")
+}
+
+func TestService_ConnectionCount(t *testing.T) {
+	clock := newFakeClock()
+	publisher := &mockPublisher{}
+	service := NewService(context.Background(), publisher, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithOfflineDebounce(5*time.Second))
+	defer service.Shutdown()
+
+	counts := func() []int {
+		var got []int
+		for _, msg := range publisher.getMessages() {
+			if msg.Topic != TopicConnectionCount.Name() {
+				continue
+			}
+			var event ConnectionCountEvent
+			require.NoError(t, json.Unmarshal(msg.Payload, &event))
+			assert.Equal(t, "user1", event.UserID)
+			got = append(got, event.Count)
+		}
+		return got
+	}
+
+	for _, clientID := range []string{"tab1", "tab2", "tab3"} {
+		service.addPresence("user1", clientID, "browser")
+		clock.Advance(time.Second) // past the per-user rate limit
+	}
+	assert.Equal(t, 3, service.ConnectionCount("user1"))
+
+	service.removePresenceForClient("user1", "tab2")
+	assert.Equal(t, 2, service.ConnectionCount("user1"))
+	assert.Contains(t, service.GetOnlineUsers(), "user1")
+	assert.Empty(t, service.DebugSnapshot().PendingOffline, "the offline debounce waits for the last connection")
+
+	// Re-adding an open connection doesn't change the count.
+	service.addPresence("user1", "tab1", "browser")
+	clock.Advance(time.Second)
+
+	service.removePresenceForClient("user1", "tab1")
+	service.removePresenceForClient("user1", "tab3")
+	assert.Zero(t, service.ConnectionCount("user1"))
+	assert.Contains(t, service.DebugSnapshot().PendingOffline, "user1")
+
+	require.Eventually(t, func() bool { return len(counts()) == 6 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 2, 1, 0}, counts(), "counts are published in order")
+}
@@ -0,0 +1,230 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotSaveTimeout bounds a single snapshot write.
+const snapshotSaveTimeout = 5 * time.Second
+
+// Snapshot is the presence state persisted across restarts: every tracked
+// connection at the time it was taken.
+type Snapshot struct {
+	TakenAt     time.Time  `json:"taken_at"`
+	Connections []Presence `json:"connections"`
+}
+
+// SnapshotStore persists presence snapshots. Load returns an empty snapshot
+// when none has been saved.
+type SnapshotStore interface {
+	Save(ctx context.Context, snap Snapshot) error
+	Load(ctx context.Context) (Snapshot, error)
+}
+
+// FileSnapshotStore keeps the snapshot as JSON in a single file, replaced
+// atomically on each save.
+type FileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore returns a store that keeps the snapshot at path.
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+// Save writes snap to a temporary file and renames it over the snapshot, so
+// a crash mid-write leaves the previous snapshot intact.
+func (f *FileSnapshotStore) Save(ctx context.Context, snap Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal presence snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create presence snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write presence snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write presence snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace presence snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads the snapshot, returning an empty one if the file does not exist.
+func (f *FileSnapshotStore) Load(ctx context.Context) (Snapshot, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, nil
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read presence snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse presence snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// WithSnapshots persists presence to store every interval and on Shutdown,
+// and restores the last snapshot when the service starts, so a restart does
+// not briefly show everyone offline. Restored connections are marked Pending
+// and count as online; a heartbeat from the client confirms them, and those
+// not confirmed within grace expire. A non-positive interval saves only on
+// Shutdown; a non-positive grace uses two minutes.
+func WithSnapshots(store SnapshotStore, interval, grace time.Duration) Option {
+	return func(s *Service) {
+		s.snapshots = store
+		s.snapshotInterval = interval
+		s.snapshotGrace = grace
+		if grace <= 0 {
+			s.snapshotGrace = 2 * time.Minute
+		}
+	}
+}
+
+// snapshot copies every tracked connection.
+func (s *Service) snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := Snapshot{TakenAt: s.now()}
+	for _, clientPresences := range s.presences {
+		for _, p := range clientPresences {
+			snap.Connections = append(snap.Connections, p)
+		}
+	}
+	return snap
+}
+
+// saveSnapshot persists the current presence, logging rather than returning
+// failures since it runs in the background and on shutdown.
+func (s *Service) saveSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotSaveTimeout)
+	defer cancel()
+	snap := s.snapshot()
+	if err := s.snapshots.Save(ctx, snap); err != nil {
+		s.logger.Error("Failed to save presence snapshot", "error", err)
+		return
+	}
+	s.logger.Debug("Saved presence snapshot", "connections", len(snap.Connections))
+}
+
+// startSnapshots saves a snapshot every snapshotInterval until the service
+// stops.
+func (s *Service) startSnapshots() {
+	if s.snapshotInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.saveSnapshot()
+		case <-s.stopCleanup:
+			return
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// restoreSnapshot loads the last snapshot as pending presence and schedules
+// the expiry of whatever is not confirmed within the grace window.
+// Connections that would already count as stale are skipped.
+func (s *Service) restoreSnapshot() {
+	ctx, cancel := context.WithTimeout(s.ctx, snapshotSaveTimeout)
+	defer cancel()
+	snap, err := s.snapshots.Load(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load presence snapshot", "error", err)
+		return
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	restored := 0
+	for _, p := range snap.Connections {
+		threshold := s.staleThreshold
+		if p.Guest {
+			threshold = s.guestStaleThreshold
+		}
+		if p.UserID == "" || p.ClientID == "" || now.Sub(p.Timestamp) > threshold {
+			continue
+		}
+		if s.presences[p.UserID] == nil {
+			s.presences[p.UserID] = make(map[string]Presence)
+		}
+		p.Pending = true
+		s.presences[p.UserID][p.ClientID] = p
+		s.clients[p.ClientID] = p.UserID
+		restored++
+	}
+	s.metrics.totalConnections.Add(int64(restored))
+	s.metrics.totalUsers.Store(int64(len(s.presences)))
+	onlineUsers := s.getOnlineUsersUnsafe()
+	s.mu.Unlock()
+
+	if restored == 0 {
+		return
+	}
+	s.logger.Info("Restored presence from snapshot",
+		"connections", restored,
+		"skipped", len(snap.Connections)-restored,
+		"taken_at", snap.TakenAt,
+		"grace", s.snapshotGrace)
+	s.publishAsync(onlineUsers)
+	s.clock.AfterFunc(s.snapshotGrace, s.expirePending)
+}
+
+// expirePending drops the restored connections whose clients did not
+// reconnect within the grace window.
+func (s *Service) expirePending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The publish queue is closed once the service shuts down.
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	expired := 0
+	for userID, clientPresences := range s.presences {
+		before := len(clientPresences)
+		for clientID, p := range clientPresences {
+			if p.Pending {
+				delete(clientPresences, clientID)
+				delete(s.clients, clientID)
+				expired++
+			}
+		}
+		if len(clientPresences) == before {
+			continue
+		}
+		s.publishConnectionCount(userID, len(clientPresences))
+		if len(clientPresences) == 0 {
+			delete(s.presences, userID)
+			s.leaveAllChannelsUnsafe(userID, ChannelOfflineReason)
+		}
+	}
+	if expired == 0 {
+		return
+	}
+	s.metrics.totalConnections.Add(-int64(expired))
+	s.metrics.totalUsers.Store(int64(len(s.presences)))
+	s.logger.Info("Expired restored presence not confirmed by a reconnect", "connections", expired)
+	s.publishAsync(s.getOnlineUsersUnsafe())
+}
@@ -0,0 +1,71 @@
+package presence
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSnapshotStore_RoundTrip(t *testing.T) {
+	store := NewFileSnapshotStore(filepath.Join(t.TempDir(), "presence.json"))
+
+	empty, err := store.Load(context.Background())
+	require.NoError(t, err, "a missing snapshot is not an error")
+	assert.Empty(t, empty.Connections)
+
+	snap := Snapshot{
+		TakenAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Connections: []Presence{{
+			UserID:       "user1",
+			Status:       StatusOnline,
+			ClientID:     "client1",
+			ClientType:   "dashboard",
+			Timestamp:    time.Date(2025, 1, 1, 11, 59, 0, 0, time.UTC),
+			PingInterval: 30 * time.Second,
+		}},
+	}
+	require.NoError(t, store.Save(context.Background(), snap))
+	loaded, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, snap, loaded)
+}
+
+func TestService_RestoresSnapshotUntilGraceExpires(t *testing.T) {
+	clock := newFakeClock()
+	store := NewFileSnapshotStore(filepath.Join(t.TempDir(), "presence.json"))
+
+	before := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(5*time.Minute), WithSnapshots(store, 0, time.Minute))
+	before.addPresence("user1", "client1", "browser")
+	clock.Advance(2 * time.Minute)
+	before.addPresence("user2", "client2", "browser")
+	before.addPresence("user3", "client3", "browser")
+	before.Shutdown()
+
+	// user1's snapshot is older than the stale threshold by the time the
+	// service is back.
+	clock.Advance(4 * time.Minute)
+	after := NewService(context.Background(), &mockPublisher{}, &mockSubscriber{}, topicmgr.Default(),
+		WithClock(clock), WithStaleThreshold(5*time.Minute), WithSnapshots(store, 0, time.Minute),
+		WithOfflineDebounce(0))
+	defer after.Shutdown()
+
+	assert.ElementsMatch(t, []string{"user2", "user3"}, after.GetOnlineUsers(), "stale entries are not restored")
+	p, ok := after.GetPresence("user3")
+	require.True(t, ok)
+	assert.True(t, p.Pending)
+
+	clock.Advance(2 * time.Second)
+	after.addPresence("user3", "client3", "browser")
+	p, _ = after.GetPresence("user3")
+	assert.False(t, p.Pending, "a heartbeat confirms the restored connection")
+
+	// user2's client never reconnects.
+	clock.Advance(time.Minute)
+	assert.ElementsMatch(t, []string{"user3"}, after.GetOnlineUsers(), "unconfirmed entries expire after the grace window")
+}
@@ -37,11 +37,12 @@ var (
 		Name:        "presence.user.status",
 		Description: "Published when a user's presence status changes",
 		Pattern:     "presence.user.status",
-		Example:     `{"userID":"user123","status":"away","message":"In a meeting","timestamp":"2024-01-01T00:00:00Z"}`,
+		Example:     `{"type":"presence_update","version":1,"users":["user123","user456"]}`,
 		Metadata: map[string]interface{}{
-			"event_type": "status_change",
-			"payload_fields": []string{"userID", "status", "message", "timestamp"},
-			"valid_statuses": []string{"online", "away", "busy", "offline"},
+			"event_type":     "status_change",
+			"payload_type":   "presence.UpdatePayload",
+			"schema_version": UpdatePayloadVersion,
+			"payload_fields": []string{"type", "version", "users"},
 		},
 	})
 
@@ -81,28 +82,53 @@ var (
 			"payload_fields": []string{"requestID", "users"},
 		},
 	})
+
+	// TopicConnectionCount is published when the number of connections a user
+	// has open changes
+	TopicConnectionCount = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "presence.connection.count",
+		Description: "Published when the number of connections (tabs or devices) a user has open changes",
+		Pattern:     "presence.connection.count",
+		Example:     `{"userID":"user123","count":2,"timestamp":"2024-01-01T00:00:00Z"}`,
+		Metadata: map[string]interface{}{
+			"event_type":     "presence_change",
+			"payload_fields": []string{"userID", "count", "timestamp"},
+		},
+	})
+
+	// TopicChannelEmpty is published when the last member leaves a channel
+	TopicChannelEmpty = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "presence.channel.empty",
+		Description: "Published when the last user leaves a presence channel and it is removed",
+		Pattern:     "presence.channel.empty",
+		Example:     `{"channel":"game.room42","reason":"offline","timestamp":"2024-01-01T00:00:00Z"}`,
+		Metadata: map[string]interface{}{
+			"event_type":     "channel_lifecycle",
+			"payload_fields": []string{"channel", "reason", "timestamp"},
+			"valid_reasons":  []string{"leave", "offline", "stale"},
+		},
+	})
 )
 
-// RegisterTopics registers all presence framework topics with the topic manager
-func RegisterTopics() error {
-	manager := topicmgr.Default()
-	
-	topics := []topicmgr.Topic{
+// FrameworkTopics returns the presence framework topics, for a
+// topicmgr.FrameworkRegistrar.
+func FrameworkTopics() []topicmgr.Topic {
+	return []topicmgr.Topic{
 		TopicUserOnline,
 		TopicUserOffline,
 		TopicUserStatusUpdate,
 		TopicPresenceHeartbeat,
 		TopicPresenceQuery,
 		TopicPresenceResponse,
+		TopicChannelEmpty,
+		TopicConnectionCount,
 	}
-	
-	for _, topic := range topics {
-		if err := manager.Register(topic); err != nil {
-			return err
-		}
-	}
-	
-	return nil
+}
+
+// RegisterTopics registers all presence framework topics with the topic manager.
+// Topics that are already registered are skipped.
+func RegisterTopics() error {
+	return topicmgr.NewFrameworkRegistrar().Add("presence", FrameworkTopics()...).Register(topicmgr.Default())
 }
 
 // MustRegisterTopics registers all presence framework topics and panics on error
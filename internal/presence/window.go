@@ -0,0 +1,86 @@
+package presence
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricsWindow is the span the windowed presence metrics cover.
+const MetricsWindow = time.Minute
+
+// metricsBucketWidth is the resolution of the windowed metrics: events leave
+// the window in steps of this much.
+const metricsBucketWidth = 10 * time.Second
+
+// windowEvent is a kind of event counted over MetricsWindow.
+type windowEvent int
+
+const (
+	windowConnect windowEvent = iota
+	windowDisconnect
+	windowReconnect
+	windowEventCount
+)
+
+// eventWindow counts events over the last MetricsWindow with a ring of
+// time buckets. The ring advances whenever it is written or read, so counts
+// stay accurate however rarely the cleanup loop runs.
+type eventWindow struct {
+	mu      sync.Mutex
+	buckets [MetricsWindow / metricsBucketWidth][windowEventCount]int64
+	current int       // bucket counting events now
+	start   time.Time // start of the current bucket; zero before the first event
+}
+
+// add counts an event at now.
+func (w *eventWindow) add(now time.Time, event windowEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.advanceLocked(now)
+	w.buckets[w.current][event]++
+}
+
+// counts returns the number of each kind of event within the window ending
+// at now.
+func (w *eventWindow) counts(now time.Time) [windowEventCount]int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.advanceLocked(now)
+	var total [windowEventCount]int64
+	for _, bucket := range w.buckets {
+		for event, n := range bucket {
+			total[event] += n
+		}
+	}
+	return total
+}
+
+// reset forgets every counted event.
+func (w *eventWindow) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buckets = [len(w.buckets)][windowEventCount]int64{}
+	w.current = 0
+	w.start = time.Time{}
+}
+
+// advanceLocked moves the ring forward to the bucket containing now,
+// clearing the buckets it passes. Caller must hold mu.
+func (w *eventWindow) advanceLocked(now time.Time) {
+	if w.start.IsZero() {
+		w.start = now.Truncate(metricsBucketWidth)
+		return
+	}
+	steps := int(now.Sub(w.start) / metricsBucketWidth)
+	if steps <= 0 {
+		return
+	}
+	w.start = w.start.Add(time.Duration(steps) * metricsBucketWidth)
+	for i := 0; i < min(steps, len(w.buckets)); i++ {
+		w.current = (w.current + 1) % len(w.buckets)
+		w.buckets[w.current] = [windowEventCount]int64{}
+	}
+}
@@ -0,0 +1,102 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Handlers signal how a message should be settled through their return value:
+//
+//   - return Ack() (or nil): the message was processed and is acknowledged.
+//   - return Nack(err): a transient failure; the message is negatively
+//     acknowledged so the backend redelivers it.
+//   - return Reject(err): a poison message that will never succeed; it is
+//     acknowledged upstream and forwarded to DeadLetterTopic. A message
+//     rejected by a DeadLetterTopic subscriber is dropped instead.
+//
+// Any other non-nil error is treated as Nack, preserving the original
+// "error means retry" behavior of Subscriber implementations.
+
+// DeadLetterTopic receives messages rejected by handlers via Reject.
+const DeadLetterTopic = "pubsub.deadletter"
+
+// Metadata keys set on messages forwarded to DeadLetterTopic.
+const (
+	MetaKeyDLQOriginalTopic = "dlq_original_topic"
+	MetaKeyDLQReason        = "dlq_reason"
+)
+
+// Action describes how a handled message is settled.
+type Action int
+
+const (
+	// ActionAck acknowledges the message as successfully processed.
+	ActionAck Action = iota
+	// ActionNack negatively acknowledges the message so it is redelivered.
+	ActionNack
+	// ActionReject drops the message to the dead-letter topic without retry.
+	ActionReject
+)
+
+// String returns a human-readable name for the action.
+func (a Action) String() string {
+	switch a {
+	case ActionAck:
+		return "ack"
+	case ActionNack:
+		return "nack"
+	case ActionReject:
+		return "reject"
+	default:
+		return fmt.Sprintf("action(%d)", int(a))
+	}
+}
+
+// HandlerResult is the error returned by Nack and Reject. It carries the
+// settlement action alongside the underlying cause.
+type HandlerResult struct {
+	Action Action
+	Err    error
+}
+
+// Error implements the error interface.
+func (r *HandlerResult) Error() string {
+	if r.Err == nil {
+		return r.Action.String()
+	}
+	return fmt.Sprintf("%s: %v", r.Action, r.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (r *HandlerResult) Unwrap() error {
+	return r.Err
+}
+
+// Ack reports that the message was processed successfully. It returns nil
+// and exists so handlers can state their intent explicitly.
+func Ack() error {
+	return nil
+}
+
+// Nack reports a transient failure; the message will be redelivered.
+func Nack(err error) error {
+	return &HandlerResult{Action: ActionNack, Err: err}
+}
+
+// Reject reports a message that can never be processed (e.g. a malformed
+// payload). It is not redelivered and is forwarded to DeadLetterTopic.
+func Reject(err error) error {
+	return &HandlerResult{Action: ActionReject, Err: err}
+}
+
+// ActionFor maps a handler's return value to its settlement action.
+func ActionFor(err error) Action {
+	if err == nil {
+		return ActionAck
+	}
+	var result *HandlerResult
+	if errors.As(err, &result) {
+		return result.Action
+	}
+	return ActionNack
+}
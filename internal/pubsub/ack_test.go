@@ -0,0 +1,120 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionFor(t *testing.T) {
+	cause := errors.New("boom")
+
+	assert.Equal(t, ActionAck, ActionFor(nil))
+	assert.Equal(t, ActionAck, ActionFor(Ack()))
+	assert.Equal(t, ActionNack, ActionFor(Nack(cause)))
+	assert.Equal(t, ActionReject, ActionFor(Reject(cause)))
+	assert.Equal(t, ActionNack, ActionFor(cause), "plain errors are treated as nack")
+
+	// Wrapped results keep their action and cause.
+	wrapped := errors.Join(errors.New("context"), Reject(cause))
+	assert.Equal(t, ActionReject, ActionFor(wrapped))
+	assert.ErrorIs(t, Reject(cause), cause)
+}
+
+func TestWatermillBridge_AckSemantics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("ack delivers once", func(t *testing.T) {
+		bridge := NewWatermillBridge()
+		defer bridge.Close()
+
+		var calls atomic.Int32
+		require.NoError(t, bridge.Subscribe(ctx, "test.ack", func(ctx context.Context, msg Message) error {
+			calls.Add(1)
+			return Ack()
+		}))
+		require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.ack", Payload: []byte("ok")}))
+
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("nack redelivers", func(t *testing.T) {
+		bridge := NewWatermillBridge()
+		defer bridge.Close()
+
+		var calls atomic.Int32
+		require.NoError(t, bridge.Subscribe(ctx, "test.nack", func(ctx context.Context, msg Message) error {
+			if calls.Add(1) < 3 {
+				return Nack(errors.New("temporarily unavailable"))
+			}
+			return Ack()
+		}))
+		require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.nack", Payload: []byte("retry me")}))
+
+		assert.Eventually(t, func() bool { return calls.Load() == 3 }, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("reject forwards to dead-letter topic", func(t *testing.T) {
+		bridge := NewWatermillBridge()
+		defer bridge.Close()
+
+		dead := make(chan Message, 1)
+		require.NoError(t, bridge.Subscribe(ctx, DeadLetterTopic, func(ctx context.Context, msg Message) error {
+			dead <- msg
+			return nil
+		}))
+
+		var calls atomic.Int32
+		require.NoError(t, bridge.Subscribe(ctx, "test.reject", func(ctx context.Context, msg Message) error {
+			calls.Add(1)
+			return Reject(errors.New("malformed payload"))
+		}))
+		require.NoError(t, bridge.Publish(ctx, Message{
+			Topic:    "test.reject",
+			UserID:   "user123",
+			Payload:  []byte("not json"),
+			Metadata: map[string]string{"request_id": "req-1"},
+		}))
+
+		select {
+		case msg := <-dead:
+			assert.Equal(t, "not json", string(msg.Payload))
+			assert.Equal(t, "user123", msg.UserID)
+			assert.Equal(t, "test.reject", msg.Metadata[MetaKeyDLQOriginalTopic])
+			assert.Contains(t, msg.Metadata[MetaKeyDLQReason], "malformed payload")
+			assert.Equal(t, "req-1", msg.Metadata["request_id"])
+		case <-time.After(time.Second):
+			t.Fatal("rejected message was not forwarded to the dead-letter topic")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1), calls.Load(), "rejected messages must not be redelivered")
+	})
+
+	t.Run("reject on the dead-letter topic is not dead-lettered again", func(t *testing.T) {
+		bridge := NewWatermillBridge()
+		defer bridge.Close()
+
+		var calls atomic.Int32
+		require.NoError(t, bridge.Subscribe(ctx, DeadLetterTopic, func(ctx context.Context, msg Message) error {
+			calls.Add(1)
+			return Reject(errors.New("cannot handle"))
+		}))
+		require.NoError(t, bridge.Subscribe(ctx, "test.reject", func(ctx context.Context, msg Message) error {
+			return Reject(errors.New("malformed payload"))
+		}))
+		require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.reject", Payload: []byte("not json")}))
+
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(1), calls.Load(), "a rejected dead letter must not loop")
+	})
+}
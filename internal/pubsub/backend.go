@@ -0,0 +1,201 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/redis/go-redis/v9"
+)
+
+// Backends selectable with BackendConfig.Name.
+const (
+	// BackendMemory delivers messages in-process with watermill's GoChannel.
+	// It is the default, and only reaches subscribers in the same process.
+	BackendMemory = "memory"
+	// BackendRedis delivers messages through Redis Streams, so subscribers on
+	// every instance connected to the same Redis receive them.
+	BackendRedis = "redis"
+)
+
+// redisStreamMaxLen caps each topic's Redis stream. Subscribers only read
+// new messages, so older entries are kept just long enough for slow readers.
+const redisStreamMaxLen = 10000
+
+// redisStorePrefix namespaces the keys of a Redis backend's shared store.
+const redisStorePrefix = "goby:dedup:"
+
+// BackendConfig selects the transport a WatermillBridge uses.
+type BackendConfig struct {
+	// Name is BackendMemory or BackendRedis; empty means BackendMemory.
+	Name string
+	// RedisURL is the redis:// or rediss:// URL BackendRedis connects to.
+	RedisURL string
+}
+
+// Validate reports an unknown backend or missing backend settings.
+func (c BackendConfig) Validate() error {
+	switch c.Name {
+	case "", BackendMemory:
+		return nil
+	case BackendRedis:
+		if c.RedisURL == "" {
+			return errors.New("redis backend requires a Redis URL")
+		}
+		if _, err := redis.ParseURL(c.RedisURL); err != nil {
+			return fmt.Errorf("invalid Redis URL: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("backend %q must be %q or %q", c.Name, BackendMemory, BackendRedis)
+	}
+}
+
+// Backend is the message transport behind a WatermillBridge. Any watermill
+// publisher and subscriber pair can serve as one; the bridge adds tracing,
+// acknowledgement, deduplication and dead-lettering on top, so they behave
+// the same on every backend.
+type Backend struct {
+	Publisher  message.Publisher
+	Subscriber message.Subscriber
+	// Store is a DedupStore shared by every instance using the backend, or
+	// nil when the backend only reaches this process.
+	Store DedupStore
+	// GroupSubscriber returns a subscriber for the consumer group named
+	// group: each message goes to one of its subscriptions, on whichever
+	// instance. Nil when the backend has no consumer groups, as with the
+	// in-process one, in which case named subscriptions fan out too.
+	GroupSubscriber func(group string) (message.Subscriber, error)
+
+	// closers release what the backend owns beyond its publisher and
+	// subscriber, such as a Redis connection pool.
+	closers []func() error
+}
+
+// NewBackend creates the backend selected by cfg.
+func NewBackend(cfg BackendConfig) (Backend, error) {
+	if err := cfg.Validate(); err != nil {
+		return Backend{}, err
+	}
+	logger := watermill.NewStdLogger(false, false)
+	if cfg.Name != BackendRedis {
+		return NewMemoryBackend(logger), nil
+	}
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return Backend{}, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	backend, err := NewRedisBackend(client, logger)
+	if err != nil {
+		client.Close()
+		return Backend{}, err
+	}
+	backend.closers = append(backend.closers, client.Close)
+	return backend, nil
+}
+
+// NewMemoryBackend returns the in-process GoChannel backend.
+func NewMemoryBackend(logger watermill.LoggerAdapter) Backend {
+	goChannel := gochannel.NewGoChannel(gochannel.Config{}, logger)
+	return Backend{Publisher: goChannel, Subscriber: goChannel}
+}
+
+// NewRedisBackend returns a Redis Streams backend using client, which the
+// caller keeps ownership of. Every unnamed subscription reads the topic's
+// stream on its own (fan-out), matching the in-process backend: all
+// subscribers on all instances receive each message published after they
+// subscribed. Subscriptions named with WithSubscriberName join the Redis
+// consumer group of that name instead, so each message reaches one of them.
+func NewRedisBackend(client redis.UniversalClient, logger watermill.LoggerAdapter) (Backend, error) {
+	pub, err := redisstream.NewPublisher(redisstream.PublisherConfig{
+		Client:        client,
+		DefaultMaxlen: redisStreamMaxLen,
+	}, logger)
+	if err != nil {
+		return Backend{}, fmt.Errorf("failed to create Redis publisher: %w", err)
+	}
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client: client,
+	}, logger)
+	if err != nil {
+		pub.Close()
+		return Backend{}, fmt.Errorf("failed to create Redis subscriber: %w", err)
+	}
+	groups := &redisGroups{client: client, logger: logger, subscribers: make(map[string]message.Subscriber)}
+	return Backend{
+		Publisher:       pub,
+		Subscriber:      sub,
+		Store:           NewRedisDedupStore(client, redisStorePrefix),
+		GroupSubscriber: groups.subscriber,
+		closers:         []func() error{groups.close},
+	}, nil
+}
+
+// redisGroups creates one consumer-group subscriber per group and closes
+// them with the backend.
+type redisGroups struct {
+	client redis.UniversalClient
+	logger watermill.LoggerAdapter
+
+	mu          sync.Mutex
+	subscribers map[string]message.Subscriber
+}
+
+// subscriber returns the subscriber of group. A new group starts with the
+// messages published after it was created, like a fan-out subscription;
+// afterwards it resumes where its members left off, so messages published
+// while none was running are still delivered.
+func (g *redisGroups) subscriber(group string) (message.Subscriber, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if sub, ok := g.subscribers[group]; ok {
+		return sub, nil
+	}
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        g.client,
+		ConsumerGroup: group,
+		OldestId:      "$",
+	}, g.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Redis subscriber for group %q: %w", group, err)
+	}
+	g.subscribers[group] = sub
+	return sub, nil
+}
+
+func (g *redisGroups) close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var errs []error
+	for _, sub := range g.subscribers {
+		errs = append(errs, sub.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// WithBackend makes the bridge use backend instead of the in-process
+// default. The bridge closes the backend when it is closed.
+func WithBackend(backend Backend) BridgeOption {
+	return func(wb *WatermillBridge) {
+		wb.backend = backend
+	}
+}
+
+// close closes the subscriber, the publisher when it is a separate object,
+// and whatever else the backend owns.
+func (b Backend) close() error {
+	errs := []error{b.Subscriber.Close()}
+	if any(b.Publisher) != any(b.Subscriber) {
+		errs = append(errs, b.Publisher.Close())
+	}
+	for _, closeFn := range b.closers {
+		errs = append(errs, closeFn())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,218 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// backendCase creates bridges that share one transport, standing in for
+// separate instances when the backend is networked.
+type backendCase struct {
+	name string
+	// shared reports whether bridges from newBridge see each other's messages.
+	shared bool
+	// newBridge creates a bridge, traced when tracer is not nil.
+	newBridge func(t *testing.T, tracer trace.Tracer) *WatermillBridge
+}
+
+func backendCases(t *testing.T) []backendCase {
+	server := miniredis.RunT(t)
+	return []backendCase{
+		{
+			name: BackendMemory,
+			newBridge: func(t *testing.T, tracer trace.Tracer) *WatermillBridge {
+				return newWatermillBridge(tracer, nil)
+			},
+		},
+		{
+			name:   BackendRedis,
+			shared: true,
+			newBridge: func(t *testing.T, tracer trace.Tracer) *WatermillBridge {
+				client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+				t.Cleanup(func() { client.Close() })
+				backend, err := NewRedisBackend(client, watermill.NopLogger{})
+				require.NoError(t, err)
+				return newWatermillBridge(tracer, []BridgeOption{WithBackend(backend)})
+			},
+		},
+	}
+}
+
+// receive subscribes to topic and returns the channel of handled messages.
+func receive(t *testing.T, ctx context.Context, bridge *WatermillBridge, topic string) <-chan Message {
+	received := make(chan Message, 10)
+	require.NoError(t, bridge.Subscribe(ctx, topic, func(ctx context.Context, msg Message) error {
+		received <- msg
+		return nil
+	}))
+	return received
+}
+
+// publishUntilReceived publishes msg until every subscription has received
+// it, since a networked subscription becomes active shortly after Subscribe
+// returns. It returns the message each subscription received first.
+func publishUntilReceived(t *testing.T, ctx context.Context, bridge *WatermillBridge, msg Message, received ...<-chan Message) []Message {
+	t.Helper()
+	got := make([]Message, len(received))
+	pending := len(received)
+	deadline := time.Now().Add(5 * time.Second)
+	for pending > 0 {
+		require.True(t, time.Now().Before(deadline), "message not delivered to every subscription")
+		require.NoError(t, bridge.Publish(ctx, msg))
+		time.Sleep(50 * time.Millisecond)
+		for i, ch := range received {
+			select {
+			case m := <-ch:
+				if got[i].Topic == "" {
+					got[i] = m
+					pending--
+				}
+			default:
+			}
+		}
+	}
+	return got
+}
+
+func TestBackends_DeliverMessages(t *testing.T) {
+	for _, tc := range backendCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			bridge := tc.newBridge(t, nil)
+			defer bridge.Close()
+
+			received := receive(t, ctx, bridge, "orders.created")
+			msg := Message{
+				Topic:    "orders.created",
+				UserID:   "user-1",
+				Payload:  []byte(`{"id":42}`),
+				Metadata: map[string]string{"request_id": "req-1"},
+			}
+			got := publishUntilReceived(t, ctx, bridge, msg, received)[0]
+
+			assert.Equal(t, msg.Topic, got.Topic)
+			assert.Equal(t, msg.UserID, got.UserID)
+			assert.Equal(t, msg.Payload, got.Payload)
+			assert.Equal(t, "req-1", got.Metadata["request_id"])
+		})
+	}
+}
+
+func TestBackends_SpanInstances(t *testing.T) {
+	for _, tc := range backendCases(t) {
+		if !tc.shared {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			first, second := tc.newBridge(t, nil), tc.newBridge(t, nil)
+			defer first.Close()
+			defer second.Close()
+
+			onFirst := receive(t, ctx, first, "chat.messages")
+			onSecond := receive(t, ctx, second, "chat.messages")
+			msg := Message{Topic: "chat.messages", Payload: []byte("hi")}
+
+			for _, got := range publishUntilReceived(t, ctx, first, msg, onFirst, onSecond) {
+				assert.Equal(t, []byte("hi"), got.Payload, "every instance's subscribers receive the message")
+			}
+		})
+	}
+}
+
+func TestWatermillBridge_NamedSubscriptionsUseGroupSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backend := NewMemoryBackend(watermill.NopLogger{})
+	group := gochannel.NewGoChannel(gochannel.Config{}, watermill.NopLogger{})
+	var groups []string
+	backend.GroupSubscriber = func(name string) (message.Subscriber, error) {
+		groups = append(groups, name)
+		return group, nil
+	}
+	bridge := NewWatermillBridge(WithBackend(backend))
+	defer bridge.Close()
+
+	named := receive(t, WithSubscriberName(ctx, "workers"), bridge, "jobs")
+	unnamed := receive(t, ctx, bridge, "jobs")
+	assert.Equal(t, []string{"workers"}, groups, "only the named subscription joins a group")
+
+	require.NoError(t, group.Publish("jobs", mapToWatermillMessage(Message{Topic: "jobs", Payload: []byte("grouped")})))
+	select {
+	case msg := <-named:
+		assert.Equal(t, []byte("grouped"), msg.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("the named subscription reads from its group")
+	}
+	assert.Empty(t, unnamed)
+}
+
+func TestBackends_TracingLinksPublishAndProcess(t *testing.T) {
+	for _, tc := range backendCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			publisher := tc.newBridge(t, tracer)
+			defer publisher.Close()
+			subscriber := publisher
+			if tc.shared {
+				// Trace across instances, not just within one.
+				subscriber = tc.newBridge(t, tracer)
+				defer subscriber.Close()
+			}
+
+			received := receive(t, ctx, subscriber, "traced")
+			publishUntilReceived(t, ctx, publisher, Message{Topic: "traced", Payload: []byte("x")}, received)
+
+			require.Eventually(t, func() bool {
+				publishes, processes := spansByName(recorder, "pubsub.publish.traced"), spansByName(recorder, "pubsub.process.traced")
+				if len(publishes) == 0 || len(processes) == 0 {
+					return false
+				}
+				last := processes[len(processes)-1]
+				for _, span := range publishes {
+					if span.SpanContext().SpanID() == last.Parent().SpanID() {
+						return span.SpanContext().TraceID() == last.SpanContext().TraceID()
+					}
+				}
+				return false
+			}, 5*time.Second, 10*time.Millisecond, "the process span is a child of the publish span")
+		})
+	}
+}
+
+func spansByName(recorder *tracetest.SpanRecorder, name string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestBackendConfig_Validate(t *testing.T) {
+	assert.NoError(t, BackendConfig{}.Validate())
+	assert.NoError(t, BackendConfig{Name: BackendMemory}.Validate())
+	assert.NoError(t, BackendConfig{Name: BackendRedis, RedisURL: "redis://localhost:6379/0"}.Validate())
+	assert.Error(t, BackendConfig{Name: BackendRedis}.Validate())
+	assert.Error(t, BackendConfig{Name: BackendRedis, RedisURL: "http://localhost"}.Validate())
+	assert.Error(t, BackendConfig{Name: "kafka"}.Validate())
+}
@@ -0,0 +1,30 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPublishTimeout bounds a publish made in a context from
+// WithPublishContext when no timeout is given.
+const DefaultPublishTimeout = 5 * time.Second
+
+// WithPublishContext returns the context to publish a message in on behalf of
+// parent: the HTTP request, WebSocket connection or service the message
+// originates from. Publish with the originating context rather than
+// context.Background(), so the message carries its trace and request values
+// and a publish for a request that was cancelled, or a service that is
+// shutting down, is abandoned instead of blocking.
+//
+// The returned context keeps parent's values, deadline and cancellation, and
+// is additionally bounded by timeout (DefaultPublishTimeout when zero). A nil
+// parent is treated as context.Background(), for code that has none.
+func WithPublishContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	if timeout <= 0 {
+		timeout = DefaultPublishTimeout
+	}
+	return context.WithTimeout(parent, timeout)
+}
@@ -0,0 +1,53 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+func TestPublish_HonorsCancelledContext(t *testing.T) {
+	bridge := NewWatermillBridge()
+	defer bridge.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := receive(t, ctx, bridge, "orders.created")
+
+	pubCtx, pubCancel := context.WithCancel(ctx)
+	pubCancel()
+	err := bridge.Publish(pubCtx, Message{Topic: "orders.created", Payload: []byte("late")})
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, bridge.Publish(ctx, Message{Topic: "orders.created", Payload: []byte("on time")}))
+	select {
+	case msg := <-received:
+		assert.Equal(t, "on time", string(msg.Payload), "the cancelled publish is never delivered")
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+}
+
+func TestWithPublishContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request-1"))
+	ctx, done := WithPublishContext(parent, time.Minute)
+	defer done()
+
+	assert.Equal(t, "request-1", ctx.Value(ctxKey{}), "values are propagated")
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "cancelling the origin cancels the publish")
+
+	ctx, done = WithPublishContext(nil, 0)
+	defer done()
+	deadline, ok = ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(DefaultPublishTimeout), deadline, time.Second)
+}
@@ -0,0 +1,145 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MetaKeyMessageID carries a message's unique ID. Publish assigns one unless
+// the publisher already set it; set it yourself when re-publishing the same
+// logical message should be treated as a duplicate.
+const MetaKeyMessageID = "message_id"
+
+// DedupStore remembers which messages a subscription has already processed.
+// Keys are scoped per subscriber, so a message fanned out to several
+// subscribers is still handled once by each. The in-memory store suits a
+// single instance; a shared implementation (e.g. Redis SET NX with an expiry
+// and DEL) lets subscribers with the same name on several instances, or on
+// one instance after a restart, skip each other's redeliveries.
+type DedupStore interface {
+	// MarkIfAbsent records key for ttl unless it is already recorded and
+	// unexpired, and reports whether it recorded it. The check and the
+	// record must be atomic, so of several concurrent callers only one gets
+	// true.
+	MarkIfAbsent(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unmark forgets key, so the message can be processed again.
+	Unmark(ctx context.Context, key string) error
+}
+
+// MemoryDedupStore is an in-process DedupStore. Expired keys are swept as new
+// ones are marked.
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryDedupStore creates an empty in-memory dedup store.
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+// MarkIfAbsent implements DedupStore.
+func (s *MemoryDedupStore) MarkIfAbsent(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if expiry, ok := s.expires[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.expires[key] = now.Add(ttl)
+	// Sweep at most once per window so marking stays cheap.
+	if now.Sub(s.lastSweep) >= ttl {
+		for k, expiry := range s.expires {
+			if !now.Before(expiry) {
+				delete(s.expires, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	return true, nil
+}
+
+// Unmark implements DedupStore.
+func (s *MemoryDedupStore) Unmark(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, key)
+	return nil
+}
+
+// RedisDedupStore is a DedupStore shared by every instance connected to the
+// same Redis. Keys are stored with SET NX and an expiry, under a prefix.
+type RedisDedupStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisDedupStore creates a store keeping its keys in client under prefix.
+// The caller keeps ownership of client.
+func NewRedisDedupStore(client redis.UniversalClient, prefix string) *RedisDedupStore {
+	return &RedisDedupStore{client: client, prefix: prefix}
+}
+
+// MarkIfAbsent implements DedupStore.
+func (s *RedisDedupStore) MarkIfAbsent(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, 1, ttl).Result()
+}
+
+// Unmark implements DedupStore.
+func (s *RedisDedupStore) Unmark(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// BridgeOption configures a WatermillBridge.
+type BridgeOption func(*WatermillBridge)
+
+// WithDeduplication makes subscriptions skip, and acknowledge, any message
+// whose ID they already processed, or are processing, within window. A
+// message is claimed before its handler runs and released if it is nacked,
+// so concurrent redeliveries are handled once and a nacked message is still
+// retried.
+// A nil store uses a MemoryDedupStore; a non-positive window disables
+// deduplication.
+func WithDeduplication(store DedupStore, window time.Duration) BridgeOption {
+	return func(wb *WatermillBridge) {
+		if window <= 0 {
+			return
+		}
+		if store == nil {
+			store = NewMemoryDedupStore()
+		}
+		wb.dedup = store
+		wb.dedupWindow = window
+	}
+}
+
+// subscriberNameKey is the context key of a subscription's name.
+type subscriberNameKey struct{}
+
+// WithSubscriberName names the subscriptions made with the returned context.
+// On a backend with consumer groups, such as Redis, subscriptions sharing a
+// name form a group and each message is delivered to one of them.
+// Deduplication keys a message by the subscriber's name, topic and message
+// ID, so subscribers sharing a name and a shared DedupStore process each
+// message once between them, even across redeliveries. Give subscribers that must each see every message, like
+// per-instance fan-out, distinct names. Unnamed subscriptions are
+// deduplicated only within their own process and lifetime.
+func WithSubscriberName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, subscriberNameKey{}, name)
+}
+
+// subscriberName returns the name WithSubscriberName gave ctx, if any.
+func subscriberName(ctx context.Context) string {
+	name, _ := ctx.Value(subscriberNameKey{}).(string)
+	return name
+}
+
+// dedupKey scopes a message ID to a single subscriber.
+func dedupKey(subscriber, msgID string) string {
+	return subscriber + "/" + msgID
+}
@@ -0,0 +1,194 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermillBridge_Deduplication(t *testing.T) {
+	bridge := NewWatermillBridge(WithDeduplication(nil, time.Minute))
+	defer bridge.Close()
+	ctx := context.Background()
+
+	var handled, other atomic.Int32
+	require.NoError(t, bridge.Subscribe(ctx, "test.dedup", func(ctx context.Context, msg Message) error {
+		handled.Add(1)
+		return nil
+	}))
+	require.NoError(t, bridge.Subscribe(ctx, "test.dedup", func(ctx context.Context, msg Message) error {
+		other.Add(1)
+		return nil
+	}))
+
+	msg := Message{Topic: "test.dedup", Payload: []byte("hi"), Metadata: map[string]string{MetaKeyMessageID: "m-1"}}
+	require.NoError(t, bridge.Publish(ctx, msg))
+	require.NoError(t, bridge.Publish(ctx, msg))
+	require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.dedup", Payload: []byte("new")}))
+
+	assert.Eventually(t, func() bool { return handled.Load() == 2 && other.Load() == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), handled.Load(), "the duplicate is skipped")
+	assert.Equal(t, int32(2), other.Load(), "each subscription handles the message once")
+}
+
+func TestWatermillBridge_DeduplicationRetriesNacked(t *testing.T) {
+	bridge := NewWatermillBridge(WithDeduplication(NewMemoryDedupStore(), time.Minute))
+	defer bridge.Close()
+	ctx := context.Background()
+
+	var attempts atomic.Int32
+	require.NoError(t, bridge.Subscribe(ctx, "test.dedup.nack", func(ctx context.Context, msg Message) error {
+		if attempts.Add(1) == 1 {
+			return Nack(errors.New("transient"))
+		}
+		return nil
+	}))
+
+	msg := Message{Topic: "test.dedup.nack", Metadata: map[string]string{MetaKeyMessageID: "m-1"}}
+	require.NoError(t, bridge.Publish(ctx, msg))
+	assert.Eventually(t, func() bool { return attempts.Load() == 2 }, time.Second, 10*time.Millisecond, "a nacked message is redelivered")
+
+	require.NoError(t, bridge.Publish(ctx, msg))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestWatermillBridge_DeduplicationSharedStore(t *testing.T) {
+	// Two bridges stand in for two instances, or one instance before and
+	// after a restart, deduplicating against one shared store.
+	store := NewMemoryDedupStore()
+	first := NewWatermillBridge(WithDeduplication(store, time.Minute))
+	defer first.Close()
+	second := NewWatermillBridge(WithDeduplication(store, time.Minute))
+	defer second.Close()
+	ctx := context.Background()
+
+	var workers, fanOut atomic.Int32
+	for _, bridge := range []*WatermillBridge{first, second} {
+		require.NoError(t, bridge.Subscribe(WithSubscriberName(ctx, "billing"), "test.dedup.shared", func(ctx context.Context, msg Message) error {
+			workers.Add(1)
+			return nil
+		}))
+	}
+	for i, bridge := range []*WatermillBridge{first, second} {
+		name := fmt.Sprintf("fan-out-%d", i)
+		require.NoError(t, bridge.Subscribe(WithSubscriberName(ctx, name), "test.dedup.shared", func(ctx context.Context, msg Message) error {
+			fanOut.Add(1)
+			return nil
+		}))
+	}
+
+	// The same message reaches both instances.
+	msg := Message{Topic: "test.dedup.shared", Metadata: map[string]string{MetaKeyMessageID: "m-1"}}
+	require.NoError(t, first.Publish(ctx, msg))
+	assert.Eventually(t, func() bool { return workers.Load() == 1 && fanOut.Load() == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, second.Publish(ctx, msg))
+	assert.Eventually(t, func() bool { return fanOut.Load() == 2 }, time.Second, 10*time.Millisecond, "differently named subscribers each process it")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), workers.Load(), "subscribers sharing a name process it once between them")
+}
+
+func TestMemoryDedupStore_Expiry(t *testing.T) {
+	store := NewMemoryDedupStore()
+	now := time.Unix(1000, 0)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	marked, err := store.MarkIfAbsent(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, marked)
+	marked, _ = store.MarkIfAbsent(ctx, "a", time.Minute)
+	assert.False(t, marked, "a marked key is not marked again")
+
+	now = now.Add(2 * time.Minute)
+	marked, _ = store.MarkIfAbsent(ctx, "a", time.Minute)
+	assert.True(t, marked, "keys expire after the window")
+
+	require.NoError(t, store.Unmark(ctx, "a"))
+	marked, _ = store.MarkIfAbsent(ctx, "a", time.Minute)
+	assert.True(t, marked, "an unmarked key can be marked again")
+
+	store.expires["old"] = now.Add(-time.Second)
+	now = now.Add(2 * time.Minute)
+	store.MarkIfAbsent(ctx, "b", time.Minute)
+	assert.NotContains(t, store.expires, "old", "expired keys are swept")
+}
+
+func TestRedisDedupStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedisDedupStore(client, "test:")
+	ctx := context.Background()
+
+	marked, err := store.MarkIfAbsent(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, marked)
+	assert.True(t, server.Exists("test:a"), "keys are stored under the prefix")
+	marked, err = store.MarkIfAbsent(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, marked, "a marked key is not marked again")
+
+	server.FastForward(2 * time.Minute)
+	marked, _ = store.MarkIfAbsent(ctx, "a", time.Minute)
+	assert.True(t, marked, "keys expire after the window")
+
+	require.NoError(t, store.Unmark(ctx, "a"))
+	marked, _ = store.MarkIfAbsent(ctx, "a", time.Minute)
+	assert.True(t, marked, "an unmarked key can be marked again")
+}
+
+func TestMemoryDedupStore_MarkIfAbsentIsAtomic(t *testing.T) {
+	store := NewMemoryDedupStore()
+	ctx := context.Background()
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if marked, _ := store.MarkIfAbsent(ctx, "m-1", time.Minute); marked {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), wins.Load())
+}
+
+func TestWatermillBridge_DeduplicationConcurrentRedelivery(t *testing.T) {
+	bridge := NewWatermillBridge(WithDeduplication(nil, time.Minute))
+	defer bridge.Close()
+	ctx := context.Background()
+
+	var handled atomic.Int32
+	release := make(chan struct{})
+	for range 2 {
+		// Two subscriptions under one name stand in for a redelivery that
+		// arrives while the first delivery is still being handled.
+		require.NoError(t, bridge.Subscribe(WithSubscriberName(ctx, "worker"), "test.dedup.race", func(ctx context.Context, msg Message) error {
+			handled.Add(1)
+			<-release
+			return nil
+		}))
+	}
+
+	msg := Message{Topic: "test.dedup.race", Metadata: map[string]string{MetaKeyMessageID: "m-1"}}
+	require.NoError(t, bridge.Publish(ctx, msg))
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), handled.Load(), "an in-flight message is not processed twice")
+}
@@ -0,0 +1,31 @@
+package pubsub
+
+import (
+	"errors"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// ErrTopicDisabled is returned by Publish for a topic switched off with
+// topicmgr.Manager.SetEnabled; the message is dropped.
+var ErrTopicDisabled = errors.New("topic is disabled")
+
+// WithTopicManager makes the bridge consult m, instead of
+// topicmgr.Default(), for the topics whose publishes it drops because they
+// are disabled.
+func WithTopicManager(m *topicmgr.Manager) BridgeOption {
+	return func(wb *WatermillBridge) {
+		wb.topics = m
+	}
+}
+
+// topicEnabled reports whether publishes to topic are delivered. Topics
+// switched off with topicmgr.Manager.SetEnabled are not; unregistered topics
+// always are.
+func (wb *WatermillBridge) topicEnabled(topic string) bool {
+	topics := wb.topics
+	if topics == nil {
+		topics = topicmgr.Default()
+	}
+	return topics.IsEnabled(topic)
+}
@@ -0,0 +1,43 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermillBridge_DropsPublishesToDisabledTopics(t *testing.T) {
+	topics := topicmgr.NewManager()
+	require.NoError(t, topics.Register(topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:        "test.flagged",
+		Module:      "test",
+		Description: "Topic behind a feature flag",
+		Pattern:     "test.flagged",
+	})))
+	bridge := NewWatermillBridge(WithTopicManager(topics))
+	defer bridge.Close()
+	ctx := context.Background()
+
+	received := make(chan string, 10)
+	require.NoError(t, bridge.Subscribe(ctx, "test.flagged", func(ctx context.Context, msg Message) error {
+		received <- string(msg.Payload)
+		return nil
+	}))
+
+	require.NoError(t, topics.SetEnabled("test.flagged", false))
+	err := bridge.Publish(ctx, Message{Topic: "test.flagged", Payload: []byte("dropped")})
+	assert.ErrorIs(t, err, ErrTopicDisabled)
+	require.NoError(t, topics.SetEnabled("test.flagged", true))
+	require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.flagged", Payload: []byte("delivered")}))
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, "delivered", payload, "the publish made while disabled never arrives")
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+}
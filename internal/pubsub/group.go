@@ -0,0 +1,169 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// SubscriberGroup runs a set of subscriptions under one context and lets the
+// owner stop them together and wait for in-flight handlers to finish. It
+// replaces starting one goroutine per subscription by hand:
+//
+//	group := pubsub.NewSubscriberGroup(sub, "chat")
+//	group.Add(topics.TopicMessages.Name(), s.handleMessage)
+//	pubsub.AddEvent(group, topics.TopicNewMessage, s.handleNewMessage)
+//	group.Start(ctx)
+//	...
+//	err := group.Stop(shutdownCtx) // in Module.Shutdown
+type SubscriberGroup struct {
+	subscriber Subscriber
+	logger     *slog.Logger
+
+	mu      sync.RWMutex
+	pending []subscription
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped bool
+
+	// running tracks subscription goroutines; inflight tracks handler calls.
+	running  sync.WaitGroup
+	inflight sync.WaitGroup
+}
+
+type subscription struct {
+	topic   string
+	handler Handler
+}
+
+// NewSubscriberGroup creates an empty group. name identifies the owner (usually
+// the module) in log messages.
+func NewSubscriberGroup(sub Subscriber, name string) *SubscriberGroup {
+	return &SubscriberGroup{
+		subscriber: sub,
+		logger:     slog.Default().With("component", "subscriber_group", "group", name),
+	}
+}
+
+// Add registers handler for topic. Subscriptions added before Start begin when
+// the group starts; those added afterwards begin immediately. Adding to a
+// stopped group does nothing.
+func (g *SubscriberGroup) Add(topic string, handler Handler) *SubscriberGroup {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		g.logger.Warn("Ignoring subscription added to a stopped group", "topic", topic)
+		return g
+	}
+	s := subscription{topic: topic, handler: handler}
+	if g.ctx == nil {
+		g.pending = append(g.pending, s)
+		return g
+	}
+	g.run(s)
+	return g
+}
+
+// AddEvent registers a type-safe handler for event, unmarshalling payloads
+// as Subscribe does.
+func AddEvent[T any](g *SubscriberGroup, event Event[T], handler func(context.Context, T) error) *SubscriberGroup {
+	return g.Add(event.Name(), typedHandler(handler))
+}
+
+// Start begins all registered subscriptions under a context derived from ctx.
+// It returns immediately; canceling ctx or calling Stop ends them. Calling
+// Start more than once has no effect.
+func (g *SubscriberGroup) Start(ctx context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ctx != nil || g.stopped {
+		return
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	for _, s := range g.pending {
+		g.run(s)
+	}
+	g.pending = nil
+}
+
+// run starts s in its own goroutine. g.mu must be held.
+func (g *SubscriberGroup) run(s subscription) {
+	ctx := g.ctx
+	handler := g.track(s.handler)
+	g.running.Add(1)
+	go func() {
+		defer g.running.Done()
+		// Subscribe may block for the life of the subscription or return once
+		// it is running in the background; either way, it lasts until ctx ends.
+		if err := g.subscriber.Subscribe(ctx, s.topic, handler); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				g.logger.Error("Subscription stopped with error", "topic", s.topic, "error", err)
+			}
+			return
+		}
+		<-ctx.Done()
+	}()
+}
+
+// track wraps handler so Stop can wait for calls that are in progress.
+// Messages arriving after Stop are nacked so they can be redelivered.
+func (g *SubscriberGroup) track(handler Handler) Handler {
+	return func(ctx context.Context, msg Message) error {
+		g.mu.RLock()
+		if g.stopped {
+			g.mu.RUnlock()
+			return Nack(context.Canceled)
+		}
+		g.inflight.Add(1)
+		g.mu.RUnlock()
+		defer g.inflight.Done()
+		return handler(ctx, msg)
+	}
+}
+
+// Wait blocks until every subscription has ended, which happens once the
+// context passed to Start is canceled or Stop is called, and their running
+// handlers have returned. It returns immediately if the group was never
+// started.
+func (g *SubscriberGroup) Wait() {
+	g.mu.RLock()
+	started := g.ctx != nil
+	g.mu.RUnlock()
+	if !started {
+		return
+	}
+
+	g.running.Wait()
+	// The subscriptions' context is done, so no further handler calls are
+	// admitted; this also keeps inflight.Add from racing inflight.Wait.
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.inflight.Wait()
+}
+
+// Stop cancels the group's subscriptions and waits for them and any running
+// handlers to finish. It returns ctx's error if ctx ends first. Stop is safe
+// to call more than once and on a group that was never started.
+func (g *SubscriberGroup) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	g.stopped = true
+	g.pending = nil
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
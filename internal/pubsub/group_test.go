@@ -0,0 +1,67 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriberGroup(t *testing.T) {
+	bridge := NewWatermillBridge()
+	defer bridge.Close()
+
+	type greeting struct {
+		Name string `json:"name"`
+	}
+	greetings := Event[greeting]{topicName: "test.group.greeting"}
+
+	raw := make(chan string, 1)
+	typed := make(chan string, 1)
+	release := make(chan struct{})
+
+	group := NewSubscriberGroup(bridge, "test")
+	group.Add("test.group.raw", func(ctx context.Context, msg Message) error {
+		raw <- string(msg.Payload)
+		<-release // hold the handler open until the test lets it finish
+		return nil
+	})
+	AddEvent(group, greetings, func(ctx context.Context, g greeting) error {
+		typed <- g.Name
+		return nil
+	})
+	group.Start(context.Background())
+
+	require.Eventually(t, func() bool {
+		return bridge.HasSubscribers("test.group.raw") && bridge.HasSubscribers("test.group.greeting")
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, Publish(context.Background(), bridge, greetings, greeting{Name: "ada"}))
+	assert.Equal(t, "ada", <-typed)
+
+	require.NoError(t, bridge.Publish(context.Background(), Message{Topic: "test.group.raw", Payload: []byte("hi")}))
+	assert.Equal(t, "hi", <-raw)
+
+	// Stop waits for the in-flight raw handler.
+	shortCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, group.Stop(shortCtx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, group.Stop(context.Background()))
+	require.Eventually(t, func() bool {
+		return !bridge.HasSubscribers("test.group.raw") && !bridge.HasSubscribers("test.group.greeting")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSubscriberGroup_StopBeforeStart(t *testing.T) {
+	group := NewSubscriberGroup(NewWatermillBridge(), "test")
+	group.Wait()
+	require.NoError(t, group.Stop(context.Background()))
+
+	group.Add("test.group.late", func(ctx context.Context, msg Message) error { return nil })
+	group.Start(context.Background())
+	group.Wait()
+}
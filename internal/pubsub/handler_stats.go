@@ -0,0 +1,102 @@
+package pubsub
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// handlerStatSamples is how many recent durations per topic feed the P95.
+// A fixed window keeps memory bounded and lets the percentile follow the
+// current load rather than the whole process lifetime.
+const handlerStatSamples = 256
+
+// HandlerStat summarizes the subscriber handler invocations for one topic.
+type HandlerStat struct {
+	// Count is the number of handler invocations.
+	Count uint64
+	// Errors is the number of invocations that returned an error, whether
+	// the message was then nacked or rejected.
+	Errors uint64
+	// TotalLatency is the time spent in the handler across all invocations.
+	TotalLatency time.Duration
+	// AvgLatency is TotalLatency divided by Count.
+	AvgLatency time.Duration
+	// P95Latency is the 95th percentile over the most recent invocations.
+	P95Latency time.Duration
+}
+
+// handlerStats accumulates HandlerStat values per topic.
+type handlerStats struct {
+	mu     sync.Mutex
+	topics map[string]*topicHandlerStats
+}
+
+type topicHandlerStats struct {
+	count   uint64
+	errors  uint64
+	total   time.Duration
+	samples []time.Duration // ring buffer of recent durations
+	next    int
+}
+
+// record adds one handler invocation on topic.
+func (s *handlerStats) record(topic string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.topics == nil {
+		s.topics = make(map[string]*topicHandlerStats)
+	}
+	ts := s.topics[topic]
+	if ts == nil {
+		ts = &topicHandlerStats{}
+		s.topics[topic] = ts
+	}
+	ts.count++
+	if err != nil {
+		ts.errors++
+	}
+	ts.total += d
+	if len(ts.samples) < handlerStatSamples {
+		ts.samples = append(ts.samples, d)
+	} else {
+		ts.samples[ts.next] = d
+		ts.next = (ts.next + 1) % handlerStatSamples
+	}
+}
+
+// snapshot returns the current stats for every topic that has seen a call.
+func (s *handlerStats) snapshot() map[string]HandlerStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]HandlerStat, len(s.topics))
+	for topic, ts := range s.topics {
+		out[topic] = HandlerStat{
+			Count:        ts.count,
+			Errors:       ts.errors,
+			TotalLatency: ts.total,
+			AvgLatency:   ts.total / time.Duration(ts.count),
+			P95Latency:   percentile(ts.samples, 0.95),
+		}
+	}
+	return out
+}
+
+// percentile returns the nearest-rank q-th percentile of samples.
+func percentile(samples []time.Duration, q float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	rank := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// HandlerStats returns per-topic latency and error counts for the handlers
+// of this bridge's subscriptions. Topics appear once a handler has run.
+func (wb *WatermillBridge) HandlerStats() map[string]HandlerStat {
+	return wb.handlerStats.snapshot()
+}
@@ -0,0 +1,57 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// ComponentRenderer renders a UI component to bytes. rendering.Renderer
+// satisfies it.
+type ComponentRenderer interface {
+	RenderComponent(ctx context.Context, component interface{}) ([]byte, error)
+}
+
+// MessageOption adjusts a message built by PublishJSON or RenderAndBroadcast.
+type MessageOption func(*Message)
+
+// WithMetadata sets a metadata entry on the message, e.g. the recipient of a
+// direct message.
+func WithMetadata(key, value string) MessageOption {
+	return func(m *Message) {
+		if m.Metadata == nil {
+			m.Metadata = make(map[string]string)
+		}
+		m.Metadata[key] = value
+	}
+}
+
+// PublishJSON marshals v to JSON and publishes it to topic on behalf of
+// userID. Marshal failures are returned wrapped with the topic name.
+func PublishJSON(ctx context.Context, pub Publisher, topic topicmgr.Topic, userID string, v any, opts ...MessageOption) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", topic.Name(), err)
+	}
+	return publish(ctx, pub, Message{Topic: topic.Name(), UserID: userID, Payload: payload}, opts)
+}
+
+// RenderAndBroadcast renders component and publishes the result to topic,
+// typically a WebSocket broadcast or direct topic. Render failures are
+// returned wrapped with the topic name.
+func RenderAndBroadcast(ctx context.Context, pub Publisher, renderer ComponentRenderer, component any, topic topicmgr.Topic, opts ...MessageOption) error {
+	html, err := renderer.RenderComponent(ctx, component)
+	if err != nil {
+		return fmt.Errorf("render component for %s: %w", topic.Name(), err)
+	}
+	return publish(ctx, pub, Message{Topic: topic.Name(), Payload: html}, opts)
+}
+
+func publish(ctx context.Context, pub Publisher, msg Message, opts []MessageOption) error {
+	for _, opt := range opts {
+		opt(&msg)
+	}
+	return pub.Publish(ctx, msg)
+}
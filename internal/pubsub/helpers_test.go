@@ -0,0 +1,64 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturePublisher records published messages.
+type capturePublisher struct{ msgs []Message }
+
+func (p *capturePublisher) Publish(ctx context.Context, msg Message) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+func (p *capturePublisher) HasSubscribers(topic string) bool { return true }
+func (p *capturePublisher) Close() error                     { return nil }
+
+type stubRenderer struct{ err error }
+
+func (r stubRenderer) RenderComponent(ctx context.Context, component interface{}) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []byte("<p>" + component.(string) + "</p>"), nil
+}
+
+var helperTopic = topicmgr.DefineModule(topicmgr.TopicConfig{Name: "test.helpers", Module: "test"})
+
+func TestPublishJSON(t *testing.T) {
+	pub := &capturePublisher{}
+	err := PublishJSON(context.Background(), pub, helperTopic, "user@example.com",
+		map[string]string{"content": "hi"}, WithMetadata("recipient_id", "bob"))
+	require.NoError(t, err)
+	require.Len(t, pub.msgs, 1)
+	assert.Equal(t, Message{
+		Topic:    "test.helpers",
+		UserID:   "user@example.com",
+		Payload:  []byte(`{"content":"hi"}`),
+		Metadata: map[string]string{"recipient_id": "bob"},
+	}, pub.msgs[0])
+
+	err = PublishJSON(context.Background(), pub, helperTopic, "", make(chan int))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "marshal test.helpers payload")
+	assert.Len(t, pub.msgs, 1, "nothing is published when marshalling fails")
+}
+
+func TestRenderAndBroadcast(t *testing.T) {
+	pub := &capturePublisher{}
+	require.NoError(t, RenderAndBroadcast(context.Background(), pub, stubRenderer{}, "hello", helperTopic))
+	require.Len(t, pub.msgs, 1)
+	assert.Equal(t, "test.helpers", pub.msgs[0].Topic)
+	assert.Equal(t, "<p>hello</p>", string(pub.msgs[0].Payload))
+
+	renderErr := errors.New("boom")
+	err := RenderAndBroadcast(context.Background(), pub, stubRenderer{err: renderErr}, "hello", helperTopic)
+	assert.ErrorIs(t, err, renderErr)
+	assert.Len(t, pub.msgs, 1)
+}
@@ -0,0 +1,63 @@
+package pubsub
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WritePrometheus renders the bridge's active subscriptions and handler
+// stats per topic in the Prometheus text exposition format.
+func (wb *WatermillBridge) WritePrometheus(w io.Writer) error {
+	counts := wb.SubscriberCounts()
+	const name = "goby_pubsub_subscribers"
+	if _, err := fmt.Fprintf(w, "# HELP %s Active subscriptions per Pub/Sub topic.\n# TYPE %s gauge\n", name, name); err != nil {
+		return err
+	}
+	for _, topic := range sortedKeys(counts) {
+		if _, err := fmt.Fprintf(w, "%s{topic=%q} %d\n", name, topic, counts[topic]); err != nil {
+			return err
+		}
+	}
+	return writeHandlerStats(w, wb.HandlerStats())
+}
+
+// writeHandlerStats renders handler latency as a summary with a 0.95
+// quantile, plus a counter of handler errors.
+func writeHandlerStats(w io.Writer, stats map[string]HandlerStat) error {
+	topics := sortedKeys(stats)
+
+	const latency = "goby_pubsub_handler_duration_seconds"
+	if _, err := fmt.Fprintf(w, "# HELP %s Time spent in subscriber handlers per Pub/Sub topic.\n# TYPE %s summary\n", latency, latency); err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		s := stats[topic]
+		if _, err := fmt.Fprintf(w, "%s{topic=%q,quantile=\"0.95\"} %g\n%s_sum{topic=%q} %g\n%s_count{topic=%q} %d\n",
+			latency, topic, s.P95Latency.Seconds(),
+			latency, topic, s.TotalLatency.Seconds(),
+			latency, topic, s.Count); err != nil {
+			return err
+		}
+	}
+
+	const errs = "goby_pubsub_handler_errors_total"
+	if _, err := fmt.Fprintf(w, "# HELP %s Subscriber handler invocations that returned an error.\n# TYPE %s counter\n", errs, errs); err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		if _, err := fmt.Fprintf(w, "%s{topic=%q} %d\n", errs, topic, stats[topic].Errors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermillBridge_SubscriberCounts(t *testing.T) {
+	bridge := NewWatermillBridge()
+	defer bridge.Close()
+
+	assert.False(t, bridge.HasSubscribers("test.counts"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	noop := func(ctx context.Context, msg Message) error { return nil }
+	require.NoError(t, bridge.Subscribe(ctx, "test.counts", noop))
+	require.NoError(t, bridge.Subscribe(ctx, "test.counts", noop))
+	require.NoError(t, bridge.Subscribe(context.Background(), "test.other", noop))
+
+	assert.True(t, bridge.HasSubscribers("test.counts"))
+	assert.False(t, bridge.HasSubscribers("test.count"), "a near-miss topic name has no subscribers")
+	assert.Equal(t, map[string]int{"test.counts": 2, "test.other": 1}, bridge.SubscriberCounts())
+
+	var out strings.Builder
+	require.NoError(t, bridge.WritePrometheus(&out))
+	assert.Contains(t, out.String(), "# TYPE goby_pubsub_subscribers gauge\n")
+	assert.Contains(t, out.String(), "goby_pubsub_subscribers{topic=\"test.counts\"} 2\n")
+	assert.Contains(t, out.String(), "goby_pubsub_subscribers{topic=\"test.other\"} 1\n")
+
+	// Cancelling a subscription's context ends it and drops its count.
+	cancel()
+	assert.Eventually(t, func() bool { return !bridge.HasSubscribers("test.counts") }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"test.other": 1}, bridge.SubscriberCounts())
+}
+
+func TestWatermillBridge_HandlerStats(t *testing.T) {
+	bridge := NewWatermillBridge()
+	defer bridge.Close()
+
+	ctx := context.Background()
+	var calls atomic.Int32
+	require.NoError(t, bridge.Subscribe(ctx, "test.stats", func(ctx context.Context, msg Message) error {
+		time.Sleep(5 * time.Millisecond)
+		if calls.Add(1) == 2 {
+			return Reject(errors.New("bad payload"))
+		}
+		return nil
+	}))
+	assert.Empty(t, bridge.HandlerStats())
+
+	for range 3 {
+		require.NoError(t, bridge.Publish(ctx, Message{Topic: "test.stats", Payload: []byte("x")}))
+	}
+	require.Eventually(t, func() bool {
+		return bridge.HandlerStats()["test.stats"].Count == 3
+	}, time.Second, 10*time.Millisecond)
+
+	stat := bridge.HandlerStats()["test.stats"]
+	assert.Equal(t, uint64(1), stat.Errors)
+	assert.GreaterOrEqual(t, stat.TotalLatency, 15*time.Millisecond)
+	assert.Equal(t, stat.TotalLatency/3, stat.AvgLatency)
+	assert.GreaterOrEqual(t, stat.P95Latency, 5*time.Millisecond)
+
+	var out strings.Builder
+	require.NoError(t, bridge.WritePrometheus(&out))
+	assert.Contains(t, out.String(), "# TYPE goby_pubsub_handler_duration_seconds summary\n")
+	assert.Contains(t, out.String(), "goby_pubsub_handler_duration_seconds_count{topic=\"test.stats\"} 3\n")
+	assert.Contains(t, out.String(), "goby_pubsub_handler_errors_total{topic=\"test.stats\"} 1\n")
+}
+
+func TestPercentile(t *testing.T) {
+	samples := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 95*time.Millisecond, percentile(samples, 0.95))
+	assert.Equal(t, 3*time.Millisecond, percentile([]time.Duration{time.Millisecond, 3 * time.Millisecond}, 0.95))
+	assert.Zero(t, percentile(nil, 0.95))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -64,6 +65,10 @@ func TracingMiddleware(tracer trace.Tracer) func(message.HandlerFunc) message.Ha
 	}
 }
 
+// traceContext propagates spans between publishers and subscribers in W3C
+// traceparent metadata, independent of the global propagator.
+var traceContext = propagation.TraceContext{}
+
 // PublisherTracingMiddleware wraps a publisher with tracing capabilities
 type PublisherTracingMiddleware struct {
 	publisher message.Publisher
@@ -109,8 +114,10 @@ func (p *PublisherTracingMiddleware) Publish(topic string, messages ...*message.
 		}
 		span.SetAttributes(attribute.String("messaging.message_payload_preview", payloadPreview))
 
-		// Update message context with tracing context
+		// Update message context with tracing context, and carry it in the
+		// metadata so subscribers on networked backends can continue the trace.
 		msg.SetContext(spanCtx)
+		traceContext.Inject(spanCtx, propagation.MapCarrier(msg.Metadata))
 	}
 
 	// Publish the messages
@@ -18,11 +18,22 @@ type Message struct {
 }
 
 // Handler defines the function signature for processing a received message.
+// The returned error decides how the message is settled: nil (or Ack())
+// acknowledges it, Nack(err) or any other error requests redelivery, and
+// Reject(err) drops it to DeadLetterTopic. See ack.go.
 type Handler func(ctx context.Context, msg Message) error
 
 // Publisher defines the contract for sending messages to the Pub/Sub system.
 type Publisher interface {
+	// Publish sends msg. ctx should be the context of the request, connection
+	// or service the message originates from (see WithPublishContext); a
+	// publish whose ctx is already done fails with ctx's error. Publishes to
+	// a topic disabled with topicmgr.Manager.SetEnabled are dropped and fail
+	// with ErrTopicDisabled.
 	Publish(ctx context.Context, msg Message) error
+	// HasSubscribers reports whether any active subscription listens on topic.
+	// Publishing to a topic with no subscribers is usually a typo in a topic name.
+	HasSubscribers(topic string) bool
 	Close() error
 }
 
@@ -0,0 +1,45 @@
+package pubsub
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Metadata keys Publish sets so subscribers can restore publish order. The
+// in-process backend hands every message to each subscriber on a goroutine of
+// its own, so messages published in quick succession can arrive reordered.
+const (
+	// MetaKeyPublisherID identifies the bridge that published a message.
+	MetaKeyPublisherID = "publisher_id"
+	// MetaKeyPublishSeq numbers a publisher's messages per topic, starting
+	// at 1. A failed publish leaves a gap.
+	MetaKeyPublishSeq = "publish_seq"
+)
+
+// publishSequence hands out per-topic publish sequence numbers.
+type publishSequence struct {
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+// next returns the sequence number for the next message on topic.
+func (s *publishSequence) next(topic string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		s.last = make(map[string]uint64)
+	}
+	s.last[topic]++
+	return s.last[topic]
+}
+
+// PublishOrder returns the publisher and per-topic sequence number Publish
+// recorded on msg. ok is false for messages without them.
+func PublishOrder(msg Message) (publisherID string, seq uint64, ok bool) {
+	publisherID = msg.Metadata[MetaKeyPublisherID]
+	seq, err := strconv.ParseUint(msg.Metadata[MetaKeyPublishSeq], 10, 64)
+	if publisherID == "" || err != nil || seq == 0 {
+		return "", 0, false
+	}
+	return publisherID, seq, true
+}
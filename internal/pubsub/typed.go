@@ -60,10 +60,12 @@ func NewEvent[T any](name string, description string) Event[T] {
 		Description: description,
 		Pattern:     name, // Use exact topic name as pattern
 		Metadata: map[string]any{
-			"payload_fields": fields,
-			"type_name":      t.Name(),
-			"is_typed":       true,
+			topicmgr.MetaPayloadFields: fields,
+			"type_name":                t.Name(),
+			"is_typed":                 true,
 		},
+		// Record the NewEvent call site rather than this file.
+		DefinedAt: topicmgr.CallerLocation(1),
 	}
 
 	// 3. Register with Topic Manager
@@ -99,14 +101,21 @@ func Publish[T any](ctx context.Context, p Publisher, event Event[T], payload T)
 
 // Subscribe creates a type-safe subscription to an event.
 // The handler receives the unmarshaled payload directly, with automatic error handling.
-// If unmarshaling fails, the error is returned and the subscriber will stop.
+// If unmarshaling fails, the message is rejected to the dead-letter topic since
+// redelivering a malformed payload can never succeed.
 func Subscribe[T any](ctx context.Context, s Subscriber, event Event[T], handler func(context.Context, T) error) error {
-	return s.Subscribe(ctx, event.Name(), func(ctx context.Context, msg Message) error {
+	return s.Subscribe(ctx, event.Name(), typedHandler(handler))
+}
+
+// typedHandler adapts a typed handler to Handler, rejecting payloads that do
+// not unmarshal into T.
+func typedHandler[T any](handler func(context.Context, T) error) Handler {
+	return func(ctx context.Context, msg Message) error {
 		var payload T
 		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-			// Return error to stop subscriber - malformed typed events indicate a bug
-			return err
+			// Malformed typed events indicate a bug in the publisher; retrying won't help.
+			return Reject(err)
 		}
 		return handler(ctx, payload)
-	})
+	}
 }
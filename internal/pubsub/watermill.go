@@ -2,24 +2,51 @@ package pubsub
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/nfrund/goby/internal/topicmgr"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// WatermillBridge implements the Publisher and Subscriber interfaces using watermill's GoChannel.
+// WatermillBridge implements the Publisher and Subscriber interfaces on top of
+// a watermill Backend, in-process GoChannel by default.
 type WatermillBridge struct {
-	pub message.Publisher
-	sub message.Subscriber
+	backend Backend
+	pub     message.Publisher
+	sub     message.Subscriber
 	// Logger for watermill to use
 	logger watermill.LoggerAdapter
 	// Optional tracer for observability
 	tracer trace.Tracer
+
+	// subscribers counts active subscriptions per topic.
+	subsMu      sync.RWMutex
+	subscribers map[string]int
+	subSeq      atomic.Uint64
+
+	// handlerStats times every handler invocation per topic.
+	handlerStats handlerStats
+
+	// id and sequence stamp each published message with its publish order.
+	id       string
+	sequence publishSequence
+
+	// dedup, when set, records processed message IDs for dedupWindow.
+	dedup       DedupStore
+	dedupWindow time.Duration
+
+	// topics decides which topics are disabled; nil means topicmgr.Default().
+	topics *topicmgr.Manager
 }
 
 const (
@@ -28,40 +55,40 @@ const (
 	metaKeyTopic  = "topic"
 )
 
-// NewWatermillBridge initializes an in-memory Pub/Sub system for testing.
-func NewWatermillBridge() *WatermillBridge {
-	logger := watermill.NewStdLogger(false, false)
-	// GoChannel is a simple in-memory pub/sub implementation.
-	goChannel := gochannel.NewGoChannel(
-		gochannel.Config{},
-		logger,
-	)
-
-	return &WatermillBridge{
-		pub:    goChannel,
-		sub:    goChannel,
-		logger: logger,
-	}
+// NewWatermillBridge initializes a Pub/Sub system, in-memory unless
+// WithBackend selects another backend.
+func NewWatermillBridge(opts ...BridgeOption) *WatermillBridge {
+	return newWatermillBridge(nil, opts)
 }
 
-// NewWatermillBridgeWithTracer initializes an in-memory Pub/Sub system with tracing support.
-func NewWatermillBridgeWithTracer(tracer trace.Tracer) *WatermillBridge {
-	logger := watermill.NewStdLogger(false, false)
-	// GoChannel is a simple in-memory pub/sub implementation.
-	goChannel := gochannel.NewGoChannel(
-		gochannel.Config{},
-		logger,
-	)
+// NewWatermillBridgeWithTracer initializes a Pub/Sub system with tracing
+// support. Trace context travels in message metadata, so publish and process
+// spans are linked on every backend, including across instances.
+func NewWatermillBridgeWithTracer(tracer trace.Tracer, opts ...BridgeOption) *WatermillBridge {
+	return newWatermillBridge(tracer, opts)
+}
 
-	// Wrap the publisher with tracing middleware
-	tracedPublisher := NewPublisherTracingMiddleware(goChannel, tracer)
+func newWatermillBridge(tracer trace.Tracer, opts []BridgeOption) *WatermillBridge {
+	wb := &WatermillBridge{
+		id:          watermill.NewShortUUID(),
+		logger:      watermill.NewStdLogger(false, false),
+		tracer:      tracer,
+		subscribers: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(wb)
+	}
+	if wb.backend.Publisher == nil || wb.backend.Subscriber == nil {
+		wb.backend = NewMemoryBackend(wb.logger)
+	}
 
-	return &WatermillBridge{
-		pub:    tracedPublisher,
-		sub:    goChannel,
-		logger: logger,
-		tracer: tracer,
+	wb.pub = wb.backend.Publisher
+	wb.sub = wb.backend.Subscriber
+	if tracer != nil {
+		// Wrap the publisher with tracing middleware
+		wb.pub = NewPublisherTracingMiddleware(wb.pub, tracer)
 	}
+	return wb
 }
 
 // mapToWatermillMessage converts our pubsub.Message to a watermill message.
@@ -76,6 +103,11 @@ func mapToWatermillMessage(msg Message) *message.Message {
 	for k, v := range msg.Metadata {
 		wmMsg.Metadata.Set(k, v)
 	}
+	// Keep a publisher-assigned ID so re-publishes deduplicate; otherwise
+	// the watermill UUID identifies the message.
+	if wmMsg.Metadata.Get(MetaKeyMessageID) == "" {
+		wmMsg.Metadata.Set(MetaKeyMessageID, wmMsg.UUID)
+	}
 
 	return wmMsg
 }
@@ -108,24 +140,60 @@ func mapToPubSubMessage(wmMsg *message.Message) Message {
 
 // Publish implements the Publisher interface.
 func (wb *WatermillBridge) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("publish to %s: %w", msg.Topic, err)
+	}
+	if !wb.topicEnabled(msg.Topic) {
+		slog.Warn("Dropping publish to disabled topic", "topic", msg.Topic)
+		return fmt.Errorf("publish to %s: %w", msg.Topic, ErrTopicDisabled)
+	}
+	if msg.Topic != DeadLetterTopic && !wb.HasSubscribers(msg.Topic) {
+		slog.Debug("Publishing to topic with no subscribers", "topic", msg.Topic)
+	}
 	wmMsg := mapToWatermillMessage(msg)
+	wmMsg.Metadata.Set(MetaKeyPublisherID, wb.id)
+	wmMsg.Metadata.Set(MetaKeyPublishSeq, strconv.FormatUint(wb.sequence.next(msg.Topic), 10))
+	wmMsg.SetContext(ctx)
 	// We use the message's internal topic (msg.Topic) as the watermill topic.
 	return wb.pub.Publish(msg.Topic, wmMsg)
 }
 
 // Subscribe implements the Subscriber interface.
 func (wb *WatermillBridge) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	// Named subscriptions share their messages when the backend supports it.
+	sub := wb.sub
+	if name := subscriberName(ctx); name != "" && wb.backend.GroupSubscriber != nil {
+		groupSub, err := wb.backend.GroupSubscriber(name)
+		if err != nil {
+			return err
+		}
+		sub = groupSub
+	}
 	// The Subscribe method returns a channel of messages.
-	messages, err := wb.sub.Subscribe(ctx, topic)
+	messages, err := sub.Subscribe(ctx, topic)
 	if err != nil {
 		return err
 	}
+	wb.addSubscriber(topic, 1)
+	// Without a name the subscription can't be recognized elsewhere, so its
+	// dedup keys are unique to this bridge.
+	subscriber := topic + "@" + subscriberName(ctx)
+	if subscriberName(ctx) == "" {
+		subscriber = fmt.Sprintf("%s#%s-%d", topic, wb.id, wb.subSeq.Add(1))
+	}
 
 	// Run the message processing in a separate goroutine so that Subscribe is non-blocking.
 	go func() {
+		defer wb.addSubscriber(topic, -1)
 		for wmMsg := range messages {
 			// Convert the watermill message to our internal structure
 			msg := mapToPubSubMessage(wmMsg)
+			dedupKey := wb.dedupKey(subscriber, msg)
+			if !wb.claim(ctx, dedupKey) {
+				slog.Debug("Skipping duplicate message", "topic", topic, "msg_id", msg.Metadata[MetaKeyMessageID])
+				wmMsg.Ack()
+				continue
+			}
 
 			// If we have a tracer, wrap the handler with tracing middleware
 			var wrappedHandler Handler
@@ -135,15 +203,22 @@ func (wb *WatermillBridge) Subscribe(ctx context.Context, topic string, handler
 				wrappedHandler = handler
 			}
 
-			// Process the message using the provided handler
-			if err := wrappedHandler(ctx, msg); err != nil {
+			// Process the message and settle it according to the handler's result.
+			start := time.Now()
+			err := wrappedHandler(ctx, msg)
+			wb.handlerStats.record(topic, time.Since(start), err)
+			switch ActionFor(err) {
+			case ActionAck:
+				wmMsg.Ack()
+			case ActionReject:
+				wb.deadLetter(ctx, msg, err)
+				// Ack upstream so the poison message is not redelivered.
+				wmMsg.Ack()
+			default:
 				slog.Error("Failed to handle message", "topic", topic, "msg_id", wmMsg.UUID, "error", err)
-				// A non-nil return from the handler means we assume the message was NOT processed successfully.
-				// Watermill can be configured to retry, but for the in-memory pub/sub, we acknowledge and log the error.
+				wb.release(ctx, dedupKey)
+				// Nack signals a transient failure; the backend redelivers the message.
 				wmMsg.Nack()
-			} else {
-				// Acknowledge the message to signal successful processing.
-				wmMsg.Ack()
 			}
 		}
 		slog.Debug("Subscription message loop ended", "topic", topic)
@@ -153,9 +228,108 @@ func (wb *WatermillBridge) Subscribe(ctx context.Context, topic string, handler
 	return nil
 }
 
+// HasSubscribers implements the Publisher interface.
+func (wb *WatermillBridge) HasSubscribers(topic string) bool {
+	wb.subsMu.RLock()
+	defer wb.subsMu.RUnlock()
+	return wb.subscribers[topic] > 0
+}
+
+// SubscriberCounts returns the number of active subscriptions per topic.
+func (wb *WatermillBridge) SubscriberCounts() map[string]int {
+	wb.subsMu.RLock()
+	defer wb.subsMu.RUnlock()
+	counts := make(map[string]int, len(wb.subscribers))
+	for topic, n := range wb.subscribers {
+		counts[topic] = n
+	}
+	return counts
+}
+
+// addSubscriber adjusts the subscription count for topic by delta.
+func (wb *WatermillBridge) addSubscriber(topic string, delta int) {
+	wb.subsMu.Lock()
+	defer wb.subsMu.Unlock()
+	if n := wb.subscribers[topic] + delta; n > 0 {
+		wb.subscribers[topic] = n
+	} else {
+		delete(wb.subscribers, topic)
+	}
+}
+
+// dedupKey returns the key under which msg is recorded for subscriber, or
+// "" when deduplication is off or the message carries no ID.
+func (wb *WatermillBridge) dedupKey(subscriber string, msg Message) string {
+	id := msg.Metadata[MetaKeyMessageID]
+	if wb.dedup == nil || id == "" {
+		return ""
+	}
+	return dedupKey(subscriber, id)
+}
+
+// claim records key as being processed and reports whether the caller won
+// it; false means the message is a duplicate. Store errors are logged and
+// the message is processed, since skipping it could lose it.
+func (wb *WatermillBridge) claim(ctx context.Context, key string) bool {
+	if key == "" {
+		return true
+	}
+	claimed, err := wb.dedup.MarkIfAbsent(ctx, key, wb.dedupWindow)
+	if err != nil {
+		slog.Warn("Dedup lookup failed; processing message", "key", key, "error", err)
+		return true
+	}
+	return claimed
+}
+
+// release forgets a claim on key so the redelivered message is processed.
+func (wb *WatermillBridge) release(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := wb.dedup.Unmark(ctx, key); err != nil {
+		slog.Warn("Failed to release message for redelivery", "key", key, "error", err)
+	}
+}
+
+// deadLetter publishes a rejected message to DeadLetterTopic, recording the
+// original topic and rejection reason in its metadata. Messages rejected by
+// a DeadLetterTopic subscriber are dropped rather than dead-lettered again,
+// which would loop forever.
+func (wb *WatermillBridge) deadLetter(ctx context.Context, msg Message, reason error) {
+	if msg.Topic == DeadLetterTopic {
+		slog.Error("Dropping message rejected by a dead-letter handler",
+			"original_topic", msg.Metadata[MetaKeyDLQOriginalTopic], "msg_id", msg.Metadata[MetaKeyMessageID], "error", reason)
+		return
+	}
+	slog.Warn("Rejected message, forwarding to dead-letter topic", "topic", msg.Topic, "msg_id", msg.Metadata[MetaKeyMessageID], "error", reason)
+
+	metadata := make(map[string]string, len(msg.Metadata)+2)
+	for k, v := range msg.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetaKeyDLQOriginalTopic] = msg.Topic
+	if reason != nil {
+		metadata[MetaKeyDLQReason] = reason.Error()
+	}
+
+	dlqMsg := Message{
+		Topic:    DeadLetterTopic,
+		UserID:   msg.UserID,
+		Payload:  msg.Payload,
+		Metadata: metadata,
+	}
+	if err := wb.Publish(ctx, dlqMsg); err != nil {
+		slog.Error("Failed to publish to dead-letter topic", "topic", msg.Topic, "error", err)
+	}
+}
+
 // wrapHandlerWithTracing wraps a handler with tracing capabilities
 func (wb *WatermillBridge) wrapHandlerWithTracing(topic string, handler Handler) Handler {
 	return func(ctx context.Context, msg Message) error {
+		// Continue the publisher's trace, which may come from another instance.
+		ctx = traceContext.Extract(ctx, propagation.MapCarrier(msg.Metadata))
+
 		// Create span for message processing
 		ctx, span := wb.tracer.Start(ctx, "pubsub.process."+topic,
 			trace.WithAttributes(
@@ -186,8 +360,14 @@ func (wb *WatermillBridge) wrapHandlerWithTracing(topic string, handler Handler)
 	}
 }
 
+// SharedStore returns the backend's cross-instance DedupStore, or nil when
+// the backend only reaches this process.
+func (wb *WatermillBridge) SharedStore() DedupStore {
+	return wb.backend.Store
+}
+
 // Close implements the Publisher and Subscriber interface to shut down the bridge.
 func (wb *WatermillBridge) Close() error {
-	// Closing the subscriber will close the gochannel and stop message consumption.
-	return wb.sub.Close()
+	// Closing the subscriber stops message consumption.
+	return wb.backend.close()
 }
@@ -1,7 +1,9 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 
 	"github.com/nfrund/goby/internal/config"
@@ -16,8 +18,15 @@ type Key[T any] string
 type Registry struct {
 	services sync.Map
 	cfg      config.Provider
+
+	hooksMu sync.RWMutex
+	hooks   map[string][]func(old, new any)
 }
 
+// ErrWrongType is returned when a key is used with a different service type
+// than the one registered under it.
+var ErrWrongType = errors.New("registry: service has a different type")
+
 // New creates a new registry with the application's configuration provider.
 func New(cfg config.Provider) *Registry {
 	return &Registry{
@@ -30,9 +39,60 @@ func (r *Registry) Config() config.Provider {
 	return r.cfg
 }
 
-// Set registers a service instance against a type-safe key.
+// Set registers a service instance against a type-safe key. If a service is
+// already registered under the key it is replaced, and the key's OnReplace
+// hooks are called with the old and new values.
 func Set[T any](r *Registry, key Key[T], value T) {
-	r.services.Store(string(key), value)
+	old, replaced := r.services.Swap(string(key), value)
+	if !replaced {
+		return
+	}
+
+	r.hooksMu.RLock()
+	hooks := r.hooks[string(key)]
+	r.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(old, value)
+	}
+}
+
+// Delete removes the service registered under key. Deleting a key that is
+// not registered does nothing. It returns ErrWrongType, and leaves the
+// service in place, if the registered service is not a T. OnReplace hooks
+// are not called. The registered service must be of a comparable type, such
+// as a pointer or an interface holding one.
+func Delete[T any](r *Registry, key Key[T]) error {
+	for {
+		val, ok := r.services.Load(string(key))
+		if !ok {
+			return nil
+		}
+		if _, ok := val.(T); !ok {
+			return fmt.Errorf("%w: cannot delete %T at key %q as %v", ErrWrongType, val, string(key), reflect.TypeFor[T]())
+		}
+		// Only delete the service that was checked; if a concurrent Set
+		// replaced it, check the new one.
+		if r.services.CompareAndDelete(string(key), val) {
+			return nil
+		}
+	}
+}
+
+// OnReplace registers fn to be called whenever Set replaces the service
+// under key, so dependents can switch to the new instance. fn runs
+// synchronously in the goroutine that called Set. If the replaced service
+// was registered with a different type, fn receives the zero value as old.
+func OnReplace[T any](r *Registry, key Key[T], fn func(old, new T)) {
+	r.hooksMu.Lock()
+	defer r.hooksMu.Unlock()
+	if r.hooks == nil {
+		r.hooks = make(map[string][]func(old, new any))
+	}
+	r.hooks[string(key)] = append(r.hooks[string(key)], func(old, new any) {
+		oldT, _ := old.(T)
+		newT, _ := new.(T)
+		fn(oldT, newT)
+	})
 }
 
 // Get retrieves a service from the registry by its type.
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter struct{ greeting string }
+
+func TestSet_ReplacesAndCallsHooks(t *testing.T) {
+	r := New(nil)
+	key := Key[*greeter]("test.greeter")
+
+	var calls [][2]string
+	OnReplace(r, key, func(old, new *greeter) {
+		calls = append(calls, [2]string{old.greeting, new.greeting})
+	})
+
+	Set(r, key, &greeter{"hello"})
+	assert.Empty(t, calls, "hooks only run when a service is replaced")
+
+	Set(r, key, &greeter{"hi"})
+	assert.Equal(t, [][2]string{{"hello", "hi"}}, calls)
+	assert.Equal(t, "hi", MustGet(r, key).greeting)
+}
+
+func TestDelete(t *testing.T) {
+	r := New(nil)
+	key := Key[*greeter]("test.greeter")
+
+	require.NoError(t, Delete(r, key), "deleting a missing key is a no-op")
+
+	Set(r, key, &greeter{"hello"})
+	require.NoError(t, Delete(r, key))
+	_, ok := Get(r, key)
+	assert.False(t, ok)
+
+	// A key with the same name but a different type must not delete the service.
+	Set(r, key, &greeter{"hello"})
+	err := Delete(r, Key[string]("test.greeter"))
+	assert.ErrorIs(t, err, ErrWrongType)
+	_, ok = Get(r, key)
+	assert.True(t, ok)
+}
+
+func TestDelete_ConcurrentSet(t *testing.T) {
+	r := New(nil)
+	key := Key[*greeter]("test.greeter")
+	other := Key[string]("test.greeter")
+
+	// Delete must never remove a service of another type that a concurrent
+	// Set put under the same name.
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		Set(r, key, &greeter{"hello"})
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = Delete(r, key)
+		}()
+		go func() {
+			defer wg.Done()
+			Set(r, other, "replacement")
+		}()
+		wg.Wait()
+		got, ok := Get(r, other)
+		require.True(t, ok, "iteration %d: the replacement was deleted", i)
+		require.Equal(t, "replacement", got)
+	}
+}
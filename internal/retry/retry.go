@@ -0,0 +1,128 @@
+// Package retry runs operations with exponential backoff and jitter.
+//
+//	err := retry.Do(ctx, retry.Default(), func(ctx context.Context) error {
+//		return client.Send(ctx, msg)
+//	})
+//
+// Wrap an error with Permanent to stop retrying early, e.g. for validation
+// failures that will not succeed on a second attempt.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy describes how often and how long to retry an operation.
+type Policy struct {
+	// InitialDelay is the wait before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between attempts. Zero means no cap.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each retry. Values below 1 are
+	// treated as 1 (a constant delay).
+	Multiplier float64
+	// JitterFactor randomizes each delay by up to ±JitterFactor of its
+	// value so that many clients do not retry in lockstep. It is clamped to
+	// [0, 1]; zero disables jitter.
+	JitterFactor float64
+	// MaxAttempts is the total number of attempts, including the first.
+	// Zero or less retries until the context ends.
+	MaxAttempts int
+}
+
+// Default returns the policy used for database operations: 100ms doubling
+// up to 30s with 25% jitter, for at most six attempts.
+func Default() Policy {
+	return Policy{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2.0,
+		JitterFactor: 0.25,
+		MaxAttempts:  6,
+	}
+}
+
+// Delay returns the wait before retry number retry (0 for the first retry),
+// including jitter.
+func (p Policy) Delay(retry int) time.Duration {
+	mult := max(p.Multiplier, 1)
+	delay := float64(p.InitialDelay) * math.Pow(mult, float64(retry))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if jitter := min(max(p.JitterFactor, 0), 1); jitter > 0 {
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// NextDelay reports the wait before retry number retry and whether another
+// attempt is allowed. Together with Reset it satisfies the SurrealDB rews
+// Retryer interface, so one policy can drive both reconnects and Do.
+func (p Policy) NextDelay(retry int, _ error) (time.Duration, bool) {
+	if p.MaxAttempts > 0 && retry+1 >= p.MaxAttempts {
+		return 0, false
+	}
+	return p.Delay(retry), true
+}
+
+// Reset is a no-op; a Policy holds no per-operation state.
+func (p Policy) Reset() {}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying. Do returns it, unwrapped,
+// without waiting for further attempts. Permanent(nil) returns nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error, the policy runs
+// out of attempts, or ctx ends. When ctx ends during a wait, Do returns
+// ctx.Err(); otherwise the last error from fn is wrapped with the number of
+// attempts made.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+
+		delay, ok := p.NextDelay(attempt, err)
+		if !ok {
+			return fmt.Errorf("operation failed after %d attempts: %w", attempt+1, err)
+		}
+
+		slog.DebugContext(ctx, "Retry attempt failed, waiting before next attempt",
+			"event", "retry_attempt", "version", "1.0",
+			"attempt", attempt+1, "delay_ms", delay.Milliseconds(), "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_DelayJitterBounds(t *testing.T) {
+	p := Policy{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		Multiplier:   2,
+		JitterFactor: 0.25,
+	}
+	for retry, base := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		base *= time.Millisecond
+		lo := time.Duration(float64(base) * 0.75)
+		hi := time.Duration(float64(base) * 1.25)
+		for range 200 {
+			d := p.Delay(retry)
+			require.GreaterOrEqual(t, d, lo, "retry %d", retry)
+			require.LessOrEqual(t, d, hi, "retry %d", retry)
+		}
+	}
+
+	p.JitterFactor = 0
+	assert.Equal(t, 400*time.Millisecond, p.Delay(2))
+}
+
+func TestPolicy_NextDelayStopsAtMaxAttempts(t *testing.T) {
+	p := Policy{InitialDelay: time.Millisecond, MaxAttempts: 3}
+	_, ok := p.NextDelay(0, nil)
+	assert.True(t, ok)
+	_, ok = p.NextDelay(1, nil)
+	assert.True(t, ok)
+	_, ok = p.NextDelay(2, nil)
+	assert.False(t, ok)
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{InitialDelay: time.Millisecond, Multiplier: 2, MaxAttempts: 3}
+	boom := errors.New("boom")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), p, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return boom
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), p, func(context.Context) error {
+			calls++
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+		assert.Contains(t, err.Error(), "after 3 attempts")
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("stops on permanent error", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), p, func(context.Context) error {
+			calls++
+			return Permanent(boom)
+		})
+		assert.Equal(t, boom, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestDo_ContextCanceledMidRetry(t *testing.T) {
+	p := Policy{InitialDelay: time.Hour} // would wait forever without cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, p, func(context.Context) error {
+			calls++
+			return errors.New("unavailable")
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	case <-time.After(time.Second):
+		t.Fatal("Do did not return after the context was canceled")
+	}
+}
@@ -439,6 +439,12 @@ func (cae *ContextAwareEngine) ExecuteWithContext(ctx context.Context, req Enhan
 	if req.Input != nil {
 		enhancedInput.Message = req.Input.Message
 		enhancedInput.HTTPRequest = req.Input.HTTPRequest
+		enhancedInput.ScriptContext = req.Input.ScriptContext
+	}
+	if enhancedInput.ScriptContext == nil {
+		if _, ok := ScriptContextFrom(ctx); !ok {
+			enhancedInput.ScriptContext = &ScriptContext{UserID: req.UserID, RequestID: req.RequestID}
+		}
 	}
 
 	// Create enhanced execution request
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/nfrund/goby/internal/config"
 )
@@ -17,6 +18,9 @@ type Engine struct {
 	config         config.Provider
 	securityLimits SecurityLimits
 	errorReporter  *ErrorReporter
+
+	// ready is set once WarmUp has loaded and compiled every script.
+	ready atomic.Bool
 }
 
 // Dependencies holds all the services that the Engine requires to operate
@@ -24,10 +28,16 @@ type Dependencies struct {
 	Config config.Provider
 }
 
-// NewEngine creates a new script engine with the given dependencies
+// NewEngine creates a new script engine with the given dependencies. External
+// scripts are read from the configured scripts directory.
 func NewEngine(deps Dependencies) *Engine {
+	scriptsDir := DefaultScriptsDir
+	if deps.Config != nil {
+		scriptsDir = deps.Config.GetScriptsDir()
+	}
+
 	return &Engine{
-		registry:       NewRegistry(),
+		registry:       NewRegistryWithDir(scriptsDir),
 		factory:        NewFactory(),
 		config:         deps.Config,
 		securityLimits: GetDefaultSecurityLimits(),
@@ -156,6 +166,17 @@ func (e *Engine) GetScript(moduleName, scriptName string) (*Script, error) {
 	return e.registry.GetScript(moduleName, scriptName)
 }
 
+// ReloadScript rereads a script's external file and returns the script now in
+// use, so callers can confirm the new checksum took effect. The registry swaps
+// the script under its lock, so executions see either the old or the new
+// version. A script without an external file keeps its embedded version.
+func (e *Engine) ReloadScript(moduleName, scriptName string) (*Script, error) {
+	if err := e.registry.ReloadScript(moduleName, scriptName); err != nil {
+		return nil, err
+	}
+	return e.registry.GetScript(moduleName, scriptName)
+}
+
 // ExtractDefaultScripts writes embedded scripts to filesystem
 func (e *Engine) ExtractDefaultScripts(targetDir string) error {
 	slog.Info("Extracting default scripts", "target_dir", targetDir)
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nfrund/goby/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,24 +15,74 @@ import (
 // MockConfig implements config.Provider for testing
 type MockConfig struct{}
 
-func (m *MockConfig) GetServerAddr() string                                 { return ":8080" }
-func (m *MockConfig) GetDBURL() string                                      { return "" }
-func (m *MockConfig) GetDBNs() string                                       { return "" }
-func (m *MockConfig) GetDBDb() string                                       { return "" }
-func (m *MockConfig) GetDBUser() string                                     { return "" }
-func (m *MockConfig) GetDBPass() string                                     { return "" }
-func (m *MockConfig) GetEmailProvider() string                              { return "mock" }
-func (m *MockConfig) GetEmailAPIKey() string                                { return "" }
-func (m *MockConfig) GetEmailSender() string                                { return "test@example.com" }
-func (m *MockConfig) GetAppBaseURL() string                                 { return "http://localhost:8080" }
-func (m *MockConfig) GetSessionSecret() string                              { return "test-secret" }
-func (m *MockConfig) GetDBQueryTimeout() time.Duration                      { return 5 * time.Second }
-func (m *MockConfig) GetDBExecuteTimeout() time.Duration                    { return 10 * time.Second }
-func (m *MockConfig) GetStorageBackend() string                             { return "mem" }
-func (m *MockConfig) GetStoragePath() string                                { return "/tmp" }
-func (m *MockConfig) GetMaxFileSize() int64                                 { return 1024 * 1024 }
-func (m *MockConfig) GetAllowedMimeTypes() []string                         { return []string{"text/plain"} }
-func (m *MockConfig) GetModuleConfig(moduleName string) (interface{}, bool) { return nil, false }
+func (m *MockConfig) GetServerAddr() string                                        { return ":8080" }
+func (m *MockConfig) GetServerReadTimeout() time.Duration                          { return 30 * time.Second }
+func (m *MockConfig) GetServerReadHeaderTimeout() time.Duration                    { return 5 * time.Second }
+func (m *MockConfig) GetServerWriteTimeout() time.Duration                         { return 30 * time.Second }
+func (m *MockConfig) GetServerIdleTimeout() time.Duration                          { return 120 * time.Second }
+func (m *MockConfig) GetServerMaxBodySize() int64                                  { return 1024 * 1024 }
+func (m *MockConfig) GetDBURL() string                                             { return "" }
+func (m *MockConfig) GetDBReplicaURLs() []string                                   { return nil }
+func (m *MockConfig) GetDBNs() string                                              { return "" }
+func (m *MockConfig) GetDBDb() string                                              { return "" }
+func (m *MockConfig) GetDBUser() string                                            { return "" }
+func (m *MockConfig) GetDBPass() string                                            { return "" }
+func (m *MockConfig) GetEmailProvider() string                                     { return "mock" }
+func (m *MockConfig) GetEmailAPIKey() string                                       { return "" }
+func (m *MockConfig) GetEmailSender() string                                       { return "test@example.com" }
+func (m *MockConfig) GetEmailWebhookSecret() string                                { return "" }
+func (m *MockConfig) GetAppBaseURL() string                                        { return "http://localhost:8080" }
+func (m *MockConfig) GetSessionSecret() string                                     { return "test-secret" }
+func (m *MockConfig) GetAdminEmails() []string                                     { return nil }
+func (m *MockConfig) GetDBQueryTimeout() time.Duration                             { return 5 * time.Second }
+func (m *MockConfig) GetDBExecuteTimeout() time.Duration                           { return 10 * time.Second }
+func (m *MockConfig) GetStorageBackend() string                                    { return "mem" }
+func (m *MockConfig) GetStoragePath() string                                       { return "/tmp" }
+func (m *MockConfig) GetMaxFileSize() int64                                        { return 1024 * 1024 }
+func (m *MockConfig) GetAllowedMimeTypes() []string                                { return []string{"text/plain"} }
+func (m *MockConfig) GetStorageFilenamePolicy() string                             { return "normalize" }
+func (m *MockConfig) GetStorageDeduplicate() bool                                  { return false }
+func (m *MockConfig) GetStoragePathTemplate() string                               { return "" }
+func (m *MockConfig) GetStorageMaxBatchDelete() int                                { return 100 }
+func (m *MockConfig) GetDBAllowDegradedStart() bool                                { return false }
+func (m *MockConfig) GetModuleBootStrict() bool                                    { return false }
+func (m *MockConfig) GetTopicMaxLength() int                                       { return 100 }
+func (m *MockConfig) GetTopicMaxSegments() int                                     { return 8 }
+func (m *MockConfig) GetTopicCaseSensitive() bool                                  { return false }
+func (m *MockConfig) GetTopicAutoNamespace() bool                                  { return false }
+func (m *MockConfig) GetWebSocketHistorySize() int                                 { return 50 }
+func (m *MockConfig) GetLogFormat() string                                         { return "text" }
+func (m *MockConfig) GetLogLevel() string                                          { return "debug" }
+func (m *MockConfig) GetWebSocketClientRateLimit() float64                         { return 20 }
+func (m *MockConfig) GetPresencePublishBufferSize() int                            { return 100 }
+func (m *MockConfig) GetPubSubDedupWindow() time.Duration                          { return 0 }
+func (m *MockConfig) GetPubSubBackend() string                                     { return "" }
+func (m *MockConfig) GetPubSubRedisURL() string                                    { return "" }
+func (m *MockConfig) GetScriptsDir() string                                        { return "scripts" }
+func (m *MockConfig) GetWebSocketClientRateBurst() int                             { return 40 }
+func (m *MockConfig) GetWebSocketSendBufferSize() int                              { return 256 }
+func (m *MockConfig) GetWebSocketBroadcastWorkers() int                            { return 0 }
+func (m *MockConfig) GetWebSocketWriteTimeout() time.Duration                      { return 10 * time.Second }
+func (m *MockConfig) GetWebSocketMaxSubscriptions() int                            { return 100 }
+func (m *MockConfig) GetWebSocketCompression() string                              { return "" }
+func (m *MockConfig) GetWebSocketCompressionThreshold() int                        { return 0 }
+func (m *MockConfig) GetWebSocketReadLimit() int64                                 { return 512 }
+func (m *MockConfig) GetWebSocketGuestEndpoints() []string                         { return nil }
+func (m *MockConfig) GetWebSocketOrderedDelivery() bool                            { return false }
+func (m *MockConfig) GetWebSocketMaxConnections() int                              { return 0 }
+func (m *MockConfig) GetPresenceGuestStaleThreshold() time.Duration                { return time.Minute }
+func (m *MockConfig) GetPresenceMaxStaleThreshold() time.Duration                  { return 10 * time.Minute }
+func (m *MockConfig) GetPresenceHiddenUsers() []string                             { return nil }
+func (m *MockConfig) GetPresenceSnapshotPath() string                              { return "" }
+func (m *MockConfig) GetPresenceSnapshotInterval() time.Duration                   { return 30 * time.Second }
+func (m *MockConfig) GetPresenceSnapshotGrace() time.Duration                      { return 2 * time.Minute }
+func (m *MockConfig) GetPresenceHistoryRetention() time.Duration                   { return 24 * time.Hour }
+func (m *MockConfig) GetModuleConfig(moduleName string) (interface{}, bool)        { return nil, false }
+func (m *MockConfig) GetString(key, fallback string) string                        { return fallback }
+func (m *MockConfig) GetInt(key string, fallback int) int                          { return fallback }
+func (m *MockConfig) GetBool(key string, fallback bool) bool                       { return fallback }
+func (m *MockConfig) GetDuration(key string, fallback time.Duration) time.Duration { return fallback }
+func (m *MockConfig) Sub(prefix string) config.Provider                            { return m }
 
 func TestEngine_Initialize(t *testing.T) {
 	cfg := &MockConfig{}
@@ -64,6 +115,37 @@ func TestEngine_RegisterEmbeddedProvider(t *testing.T) {
 	assert.Equal(t, "result := 42", script.Content)
 }
 
+func TestEngine_WarmUp(t *testing.T) {
+	cfg := &MockConfig{}
+	engine := NewEngine(Dependencies{Config: cfg})
+	require.NoError(t, engine.Initialize(context.Background(), false))
+	engine.RegisterEmbeddedProvider(&MockEmbeddedScriptProvider{
+		moduleName: "test_module",
+		scripts: map[string]string{
+			"calculator": "result := a + b",
+			"answer":     "result := 42",
+		},
+	})
+	assert.False(t, engine.Ready(), "not ready before warm-up")
+
+	require.NoError(t, engine.WarmUp(context.Background()))
+	assert.True(t, engine.Ready())
+	assert.ElementsMatch(t, []string{"calculator", "answer"}, engine.registry.ListScripts()["test_module"])
+}
+
+func TestEngine_WarmUpCancelled(t *testing.T) {
+	engine := NewEngine(Dependencies{Config: &MockConfig{}})
+	engine.RegisterEmbeddedProvider(&MockEmbeddedScriptProvider{
+		moduleName: "test_module",
+		scripts:    map[string]string{"answer": "result := 42"},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, engine.WarmUp(ctx), context.Canceled)
+	assert.False(t, engine.Ready())
+}
+
 func TestEngine_Execute(t *testing.T) {
 	cfg := &MockConfig{}
 	engine := NewEngine(Dependencies{Config: cfg})
@@ -2,6 +2,7 @@ package script
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -229,3 +230,30 @@ func TestRegistry_NoExternalScriptsDirectory(t *testing.T) {
 	scripts := registry.ListScripts()
 	assert.Empty(t, scripts)
 }
+
+func TestRegistry_ScriptsDirResolvedAtCreation(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join("custom", "test_module"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("custom", "test_module", "calculator.tengo"), []byte("x := 1"), 0644))
+
+	registry := NewRegistryWithDir("custom")
+	assert.True(t, filepath.IsAbs(registry.ScriptsDir()))
+
+	// Changing the working directory afterwards must not break loading.
+	require.NoError(t, os.Chdir(originalDir))
+	require.NoError(t, registry.LoadExternalScripts())
+
+	script, err := registry.GetScript("test_module", "calculator")
+	require.NoError(t, err)
+	assert.Equal(t, SourceExternal, script.Source)
+
+	module, name, err := registry.parseScriptPath(filepath.Join(registry.ScriptsDir(), "test_module", "calculator.tengo"))
+	require.NoError(t, err)
+	assert.Equal(t, "test_module", module)
+	assert.Equal(t, "calculator", name)
+}
@@ -93,7 +93,7 @@ func (e *Extractor) initializeScriptEngine() (script.ScriptEngine, func(), error
 		Subscriber:      ps,
 		Renderer:        renderer,
 		TopicMgr:        topicManager,
-		PresenceService: presence.NewService(ps, ps, topicManager),
+		PresenceService: presence.NewService(context.Background(), ps, ps, topicManager),
 		// ScriptEngine will be set after initialization
 	}
 
@@ -162,6 +162,10 @@ func (n *noopPubSub) Publish(ctx context.Context, msg pubsub.Message) error {
 	return nil 
 }
 
+func (n *noopPubSub) HasSubscribers(topic string) bool {
+	return false
+}
+
 func (n *noopPubSub) Subscribe(ctx context.Context, topic string, handler pubsub.Handler) error {
 	return nil
 }
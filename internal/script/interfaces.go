@@ -15,6 +15,13 @@ type ScriptEngine interface {
 	// ExtractDefaultScripts writes embedded scripts to filesystem
 	ExtractDefaultScripts(targetDir string) error
 
+	// WarmUp loads and compiles every embedded and external script ahead of
+	// the first execution
+	WarmUp(ctx context.Context) error
+
+	// Ready reports whether WarmUp has completed
+	Ready() bool
+
 	// Shutdown gracefully stops the engine and cleans up resources
 	Shutdown(ctx context.Context) error
 }
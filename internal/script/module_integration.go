@@ -32,6 +32,10 @@ type ModuleScriptConfig struct {
 
 	// Whether to auto-extract embedded scripts on startup
 	AutoExtract bool
+
+	// Result caching for scripts without side effects; off unless scripts
+	// are listed
+	Cache ResultCacheConfig
 }
 
 // ScriptExecutor provides helper methods for modules to execute scripts
@@ -39,6 +43,7 @@ type ScriptExecutor struct {
 	engine     ScriptEngine
 	moduleName string
 	config     *ModuleScriptConfig
+	cache      *resultCache
 }
 
 // NewScriptExecutor creates a new script executor for a module
@@ -47,6 +52,7 @@ func NewScriptExecutor(engine ScriptEngine, moduleName string, config *ModuleScr
 		engine:     engine,
 		moduleName: moduleName,
 		config:     config,
+		cache:      newResultCache(config.Cache),
 	}
 }
 
@@ -78,7 +84,7 @@ func (se *ScriptExecutor) ExecuteMessageHandler(ctx context.Context, topic strin
 		SecurityLimits: se.config.DefaultLimits,
 	}
 
-	output, err := se.engine.Execute(ctx, req)
+	output, err := se.execute(ctx, req)
 	if err != nil {
 		slog.Error("Script execution failed for message handler",
 			"module", se.moduleName,
@@ -126,7 +132,7 @@ func (se *ScriptExecutor) ExecuteEndpointScript(ctx context.Context, endpoint st
 		SecurityLimits: se.config.DefaultLimits,
 	}
 
-	output, err := se.engine.Execute(ctx, req)
+	output, err := se.execute(ctx, req)
 	if err != nil {
 		slog.Error("Script execution failed for endpoint handler",
 			"module", se.moduleName,
@@ -163,7 +169,7 @@ func (se *ScriptExecutor) ExecuteScript(ctx context.Context, scriptName string,
 		SecurityLimits: se.config.DefaultLimits,
 	}
 
-	output, err := se.engine.Execute(ctx, req)
+	output, err := se.execute(ctx, req)
 	if err != nil {
 		slog.Error("Script execution failed",
 			"module", se.moduleName,
@@ -182,6 +188,33 @@ func (se *ScriptExecutor) ExecuteScript(ctx context.Context, scriptName string,
 	return output, nil
 }
 
+// execute runs req, reusing a cached result when the script is cacheable and
+// has already run with the same input.
+func (se *ScriptExecutor) execute(ctx context.Context, req ExecutionRequest) (*ScriptOutput, error) {
+	if !se.cache.cacheable(req.ScriptName) {
+		return se.engine.Execute(ctx, req)
+	}
+	script, err := se.engine.GetScript(req.ModuleName, req.ScriptName)
+	if err != nil {
+		// Let Execute report the missing script.
+		return se.engine.Execute(ctx, req)
+	}
+	key, ok := resultCacheKey(ctx, script, req.Input)
+	if !ok {
+		return se.engine.Execute(ctx, req)
+	}
+	if output, hit := se.cache.get(script, key); hit {
+		slog.Debug("Script result served from cache", "module", req.ModuleName, "script", req.ScriptName)
+		return output, nil
+	}
+
+	output, err := se.engine.Execute(ctx, req)
+	if err == nil && output != nil && output.Error == nil {
+		se.cache.put(script, key, output)
+	}
+	return output, err
+}
+
 // GetDefaultModuleScriptConfig returns a default configuration for modules
 func GetDefaultModuleScriptConfig() *ModuleScriptConfig {
 	return &ModuleScriptConfig{
@@ -22,6 +22,7 @@ type Registry struct {
 	scriptCache       map[string]*Script // cache by module/script key
 	watcher           *fsnotify.Watcher
 	watcherActive     bool
+	scriptsDir        string // absolute path of the external scripts directory
 }
 
 // EmbeddedScriptProvider defines the interface for modules to provide embedded scripts
@@ -33,15 +34,41 @@ type EmbeddedScriptProvider interface {
 	GetModuleName() string
 }
 
-// NewRegistry creates a new script registry
+// DefaultScriptsDir is the external scripts directory used by NewRegistry.
+const DefaultScriptsDir = "scripts"
+
+// NewRegistry creates a new script registry that loads external scripts from
+// DefaultScriptsDir in the current working directory.
 func NewRegistry() *Registry {
+	return NewRegistryWithDir(DefaultScriptsDir)
+}
+
+// NewRegistryWithDir creates a new script registry that loads and watches
+// external scripts in dir. A relative dir is resolved against the working
+// directory now, so later changes to it do not affect the registry.
+func NewRegistryWithDir(dir string) *Registry {
+	if dir == "" {
+		dir = DefaultScriptsDir
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		slog.Warn("Could not resolve scripts directory, using it as given", "path", dir, "error", err)
+		absDir = filepath.Clean(dir)
+	}
+
 	return &Registry{
 		scripts:           make(map[string]map[string]*Script),
 		embeddedProviders: make(map[string]EmbeddedScriptProvider),
 		scriptCache:       make(map[string]*Script),
+		scriptsDir:        absDir,
 	}
 }
 
+// ScriptsDir returns the absolute path of the external scripts directory.
+func (r *Registry) ScriptsDir() string {
+	return r.scriptsDir
+}
+
 // RegisterEmbeddedProvider registers a provider for embedded scripts
 func (r *Registry) RegisterEmbeddedProvider(provider EmbeddedScriptProvider) {
 	r.mu.Lock()
@@ -182,7 +209,7 @@ func (r *Registry) StartWatcher(ctx context.Context, enableHotReload bool) error
 	}
 
 	// Check if scripts directory exists
-	scriptsDir := "scripts"
+	scriptsDir := r.scriptsDir
 	if _, err := os.Stat(scriptsDir); os.IsNotExist(err) {
 		slog.Debug("Scripts directory does not exist, skipping watcher setup", "path", scriptsDir)
 		return nil
@@ -374,9 +401,14 @@ func (r *Registry) isScriptFile(filePath string) bool {
 
 // parseScriptPath extracts module name and script name from file path
 func (r *Registry) parseScriptPath(filePath string) (moduleName, scriptName string, err error) {
-	// Convert to relative path from scripts directory
-	scriptsDir := "scripts"
-	relPath, err := filepath.Rel(scriptsDir, filePath)
+	// Convert to relative path from scripts directory. Watcher events carry
+	// absolute paths because the watched directories are absolute.
+	if !filepath.IsAbs(filePath) {
+		if filePath, err = filepath.Abs(filePath); err != nil {
+			return "", "", err
+		}
+	}
+	relPath, err := filepath.Rel(r.scriptsDir, filePath)
 	if err != nil {
 		return "", "", err
 	}
@@ -424,7 +456,7 @@ func (r *Registry) loadExternalScript(moduleName, scriptName string) (*Script, e
 
 	for _, ext := range extensions {
 		filename := scriptName + ext
-		scriptPath := filepath.Join("scripts", moduleName, filename)
+		scriptPath := filepath.Join(r.scriptsDir, moduleName, filename)
 
 		// Check if file exists
 		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
@@ -485,16 +517,11 @@ func (r *Registry) LoadExternalScripts() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	scriptsDir := "scripts"
+	scriptsDir := r.scriptsDir
 
 	// Check if scripts directory exists
 	if _, err := os.Stat(scriptsDir); os.IsNotExist(err) {
-		// Get current working directory for debugging
-		if cwd, err := os.Getwd(); err == nil {
-			slog.Info("Scripts directory does not exist", "path", scriptsDir, "cwd", cwd, "full_path", filepath.Join(cwd, scriptsDir))
-		} else {
-			slog.Debug("Scripts directory does not exist", "path", scriptsDir)
-		}
+		slog.Info("Scripts directory does not exist", "path", scriptsDir)
 		return nil // Not an error, just no external scripts
 	}
 
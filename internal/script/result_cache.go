@@ -0,0 +1,223 @@
+package script
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// Defaults for ResultCacheConfig.
+const (
+	DefaultResultCacheTTL        = 5 * time.Minute
+	DefaultResultCacheMaxEntries = 1000
+)
+
+// ResultCacheConfig enables caching of results for scripts that are pure
+// functions of their input, such as validation or formatting. A cached result
+// is reused when the same script, at the same checksum, is run with the same
+// context, message, HTTP request and user ID. Exposed functions and the
+// request ID are not part of the key, and a cache hit does not run the
+// script, so ctx.log calls are not repeated. Every hit gets its own copy of
+// the result's maps and slices, so callers may modify it.
+type ResultCacheConfig struct {
+	// Scripts lists the names of the scripts whose results may be cached.
+	// Scripts with side effects, such as publishing or writing data, must
+	// not be listed.
+	Scripts []string
+	// TTL is how long a result is reused. Zero uses DefaultResultCacheTTL.
+	TTL time.Duration
+	// MaxEntries caps the number of cached results, evicting the least
+	// recently used first. Zero uses DefaultResultCacheMaxEntries.
+	MaxEntries int
+}
+
+// resultCache is an LRU cache of successful script outputs.
+type resultCache struct {
+	ttl        time.Duration
+	maxEntries int
+	scripts    map[string]bool
+	now        func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	lru       *list.List        // front is most recently used
+	checksums map[string]string // script ID -> checksum of cached entries
+}
+
+type resultCacheEntry struct {
+	key      string
+	scriptID string
+	output   ScriptOutput
+	expires  time.Time
+}
+
+// newResultCache returns a cache for cfg, or nil if no scripts are cacheable.
+func newResultCache(cfg ResultCacheConfig) *resultCache {
+	if len(cfg.Scripts) == 0 {
+		return nil
+	}
+	c := &resultCache{
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		scripts:    make(map[string]bool, len(cfg.Scripts)),
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		checksums:  make(map[string]string),
+	}
+	if c.ttl <= 0 {
+		c.ttl = DefaultResultCacheTTL
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = DefaultResultCacheMaxEntries
+	}
+	for _, name := range cfg.Scripts {
+		c.scripts[name] = true
+	}
+	return c
+}
+
+// cacheable reports whether results of scriptName may be cached.
+func (c *resultCache) cacheable(scriptName string) bool {
+	return c != nil && c.scripts[scriptName]
+}
+
+// get returns a deep copy of the cached output for key, if it has not expired.
+func (c *resultCache) get(script *Script, key string) (*ScriptOutput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidateUnsafe(script)
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*resultCacheEntry)
+	if c.now().After(entry.expires) {
+		c.removeUnsafe(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	output := cloneOutput(entry.output)
+	return &output, true
+}
+
+// put caches output under key, evicting the least recently used entries
+// beyond the size cap.
+func (c *resultCache) put(script *Script, key string, output *ScriptOutput) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidateUnsafe(script)
+	entry := &resultCacheEntry{key: key, scriptID: scriptID(script), output: cloneOutput(*output), expires: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.removeUnsafe(c.lru.Back())
+	}
+}
+
+// invalidateUnsafe drops the cached results of script when its checksum has
+// changed since they were stored, e.g. after a hot reload. Caller must hold
+// mu.
+func (c *resultCache) invalidateUnsafe(script *Script) {
+	id := scriptID(script)
+	if checksum, ok := c.checksums[id]; ok && checksum == script.Checksum {
+		return
+	}
+	c.checksums[id] = script.Checksum
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*resultCacheEntry).scriptID == id {
+			c.removeUnsafe(elem)
+		}
+		elem = next
+	}
+}
+
+// removeUnsafe removes elem from the cache. Caller must hold mu.
+func (c *resultCache) removeUnsafe(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*resultCacheEntry).key)
+}
+
+// len returns the number of cached results.
+func (c *resultCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// cloneOutput copies output deeply enough that callers may modify the copy
+// without changing the cached entry.
+func cloneOutput(output ScriptOutput) ScriptOutput {
+	output.Result = cloneResult(output.Result)
+	output.Logs = slices.Clone(output.Logs)
+	return output
+}
+
+// cloneResult deep-copies the maps and slices a script result is built from.
+// Tengo results convert to these types and scalars, which need no copying.
+func cloneResult(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			out[k] = cloneResult(elem)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = cloneResult(elem)
+		}
+		return out
+	case map[string]string:
+		return maps.Clone(v)
+	case []string:
+		return slices.Clone(v)
+	case []byte:
+		return slices.Clone(v)
+	default:
+		return v
+	}
+}
+
+func scriptID(script *Script) string {
+	return script.ModuleName + "\x00" + script.Name
+}
+
+// resultCacheKey derives the cache key for running script with input. It
+// reports false when the input cannot be marshaled, e.g. because the context
+// holds a function, in which case the result is not cached.
+func resultCacheKey(ctx context.Context, script *Script, input *ScriptInput) (string, bool) {
+	keyed := struct {
+		Context     map[string]interface{} `json:"context,omitempty"`
+		Message     *pubsub.Message        `json:"message,omitempty"`
+		HTTPRequest *HTTPRequestData       `json:"http_request,omitempty"`
+		UserID      string                 `json:"user_id,omitempty"`
+	}{UserID: resolveScriptContext(ctx, input).UserID}
+	if input != nil {
+		keyed.Context = input.Context
+		keyed.Message = input.Message
+		keyed.HTTPRequest = input.HTTPRequest
+	}
+	data, err := json.Marshal(keyed)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return scriptID(script) + "\x00" + script.Checksum + "\x00" + hex.EncodeToString(sum[:]), true
+}
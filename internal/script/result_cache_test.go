@@ -0,0 +1,126 @@
+package script
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEngine returns the number of executions so far as each result.
+type countingEngine struct {
+	ScriptEngine
+	script Script
+	runs   int
+}
+
+func (e *countingEngine) GetScript(moduleName, scriptName string) (*Script, error) {
+	script := e.script
+	script.ModuleName, script.Name = moduleName, scriptName
+	return &script, nil
+}
+
+func (e *countingEngine) Execute(ctx context.Context, req ExecutionRequest) (*ScriptOutput, error) {
+	e.runs++
+	return &ScriptOutput{Result: e.runs}, nil
+}
+
+func newCachingExecutor(engine ScriptEngine) *ScriptExecutor {
+	config := GetDefaultModuleScriptConfig()
+	config.Cache = ResultCacheConfig{Scripts: []string{"format"}}
+	return NewScriptExecutor(engine, "test_module", config)
+}
+
+func TestScriptExecutor_CacheHitSkipsExecution(t *testing.T) {
+	engine := &countingEngine{script: Script{Checksum: "v1"}}
+	executor := newCachingExecutor(engine)
+	ctx := context.Background()
+	input := map[string]interface{}{"value": "hello"}
+
+	first, err := executor.ExecuteScript(ctx, "format", input, nil)
+	require.NoError(t, err)
+	second, err := executor.ExecuteScript(ctx, "format", input, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, engine.runs, "identical input must be served from the cache")
+	assert.Equal(t, first.Result, second.Result)
+
+	_, err = executor.ExecuteScript(ctx, "format", map[string]interface{}{"value": "other"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, engine.runs, "different input must run the script")
+
+	_, err = executor.ExecuteScript(WithScriptContext(ctx, &ScriptContext{UserID: "user1"}), "format", input, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, engine.runs, "a different user must run the script")
+}
+
+func TestScriptExecutor_CacheInvalidatedOnChecksumChange(t *testing.T) {
+	engine := &countingEngine{script: Script{Checksum: "v1"}}
+	executor := newCachingExecutor(engine)
+	ctx := context.Background()
+
+	_, err := executor.ExecuteScript(ctx, "format", nil, nil)
+	require.NoError(t, err)
+	engine.script.Checksum = "v2"
+	output, err := executor.ExecuteScript(ctx, "format", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, engine.runs)
+	assert.Equal(t, 2, output.Result)
+	assert.Equal(t, 1, executor.cache.len(), "results for the old checksum are dropped")
+}
+
+func TestScriptExecutor_UncachedScriptsAlwaysRun(t *testing.T) {
+	engine := &countingEngine{script: Script{Checksum: "v1"}}
+	executor := newCachingExecutor(engine)
+
+	for i := 0; i < 2; i++ {
+		_, err := executor.ExecuteScript(context.Background(), "notify", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, engine.runs)
+}
+
+func TestResultCache_ExpiresAndEvicts(t *testing.T) {
+	now := time.Now()
+	cache := newResultCache(ResultCacheConfig{Scripts: []string{"format"}, TTL: time.Minute, MaxEntries: 2})
+	cache.now = func() time.Time { return now }
+	script := &Script{ModuleName: "test_module", Name: "format", Checksum: "v1"}
+
+	cache.put(script, "a", &ScriptOutput{Result: "a"})
+	cache.put(script, "b", &ScriptOutput{Result: "b"})
+	_, ok := cache.get(script, "a")
+	require.True(t, ok)
+	cache.put(script, "c", &ScriptOutput{Result: "c"})
+
+	_, ok = cache.get(script, "b")
+	assert.False(t, ok, "the least recently used entry is evicted")
+	_, ok = cache.get(script, "a")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.get(script, "c")
+	assert.False(t, ok, "expired entries are not served")
+}
+
+func TestResultCache_ReturnsDeepCopies(t *testing.T) {
+	cache := newResultCache(ResultCacheConfig{Scripts: []string{"format"}})
+	script := &Script{ModuleName: "test_module", Name: "format", Checksum: "v1"}
+
+	result := map[string]interface{}{"tags": []interface{}{"a"}, "nested": map[string]interface{}{"n": 1}}
+	output := &ScriptOutput{Result: result, Logs: []string{"ran"}}
+	cache.put(script, "k", output)
+	result["tags"].([]interface{})[0] = "changed by the producer"
+	output.Logs[0] = "changed"
+
+	got, ok := cache.get(script, "k")
+	require.True(t, ok)
+	got.Result.(map[string]interface{})["nested"].(map[string]interface{})["n"] = 2
+	got.Logs = append(got.Logs[:0], "changed by a caller")
+
+	again, ok := cache.get(script, "k")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"tags": []interface{}{"a"}, "nested": map[string]interface{}{"n": 1}}, again.Result)
+	assert.Equal(t, []string{"ran"}, again.Logs)
+}
@@ -0,0 +1,94 @@
+package script
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/d5/tengo/v2"
+)
+
+// ScriptContext describes who and what a script is running for. Scripts read
+// it through the read-only ctx object:
+//
+//	if ctx.userID == "" { result := "anonymous" }
+//	ctx.log("processing request", "order", order_id)
+//
+// Set it on ScriptInput, or attach it to the Go context with
+// WithScriptContext so it reaches scripts run deeper in the call chain.
+type ScriptContext struct {
+	// UserID is the authenticated user's ID, or empty for anonymous calls.
+	UserID string
+	// RequestID identifies the HTTP request that triggered the script.
+	RequestID string
+	// Logger receives the script's ctx.log calls. Nil uses slog.Default().
+	Logger *slog.Logger
+}
+
+type scriptContextKey struct{}
+
+// WithScriptContext returns a copy of ctx carrying sc. Executions whose
+// ScriptInput has no ScriptContext fall back to this one.
+func WithScriptContext(ctx context.Context, sc *ScriptContext) context.Context {
+	return context.WithValue(ctx, scriptContextKey{}, sc)
+}
+
+// ScriptContextFrom returns the ScriptContext attached to ctx, if any.
+func ScriptContextFrom(ctx context.Context) (*ScriptContext, bool) {
+	sc, ok := ctx.Value(scriptContextKey{}).(*ScriptContext)
+	return sc, ok && sc != nil
+}
+
+// resolveScriptContext picks the input's ScriptContext, then ctx's, and
+// otherwise an empty one so scripts can always rely on ctx existing.
+func resolveScriptContext(ctx context.Context, input *ScriptInput) *ScriptContext {
+	if input != nil && input.ScriptContext != nil {
+		return input.ScriptContext
+	}
+	if sc, ok := ScriptContextFrom(ctx); ok {
+		return sc
+	}
+	return &ScriptContext{}
+}
+
+// tengoObject exposes sc to Tengo as an immutable map, so assignments such
+// as ctx.userID = "x" fail at runtime.
+func (sc *ScriptContext) tengoObject(script *Script) *tengo.ImmutableMap {
+	logger := sc.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With(
+		"module", script.ModuleName,
+		"script", script.Name,
+		"user_id", sc.UserID,
+		"request_id", sc.RequestID,
+	)
+
+	return &tengo.ImmutableMap{Value: map[string]tengo.Object{
+		"userID":    &tengo.String{Value: sc.UserID},
+		"requestID": &tengo.String{Value: sc.RequestID},
+		"log": &tengo.UserFunction{
+			Name: "log",
+			Value: func(args ...tengo.Object) (tengo.Object, error) {
+				if len(args) == 0 {
+					return nil, tengo.ErrWrongNumArguments
+				}
+				// Extra arguments are key/value pairs, as with slog.
+				attrs := make([]any, 0, len(args)-1)
+				for _, arg := range args[1:] {
+					attrs = append(attrs, tengo.ToInterface(arg))
+				}
+				logger.Info(scriptString(args[0]), attrs...)
+				return tengo.UndefinedValue, nil
+			},
+		},
+	}}
+}
+
+// scriptString returns a string argument without the quotes String() adds.
+func scriptString(o tengo.Object) string {
+	if s, ok := tengo.ToString(o); ok {
+		return s
+	}
+	return o.String()
+}
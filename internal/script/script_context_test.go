@@ -0,0 +1,63 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func executeTengo(t *testing.T, ctx context.Context, content string, input *ScriptInput) (*ScriptOutput, error) {
+	t.Helper()
+	engine := NewTengoEngine()
+	compiled, err := engine.Compile(&Script{ModuleName: "test", Name: "ctx", Language: LanguageTengo, Content: content})
+	require.NoError(t, err)
+	return engine.Execute(ctx, compiled, input)
+}
+
+func TestScriptContext_ReadFromScript(t *testing.T) {
+	input := &ScriptInput{ScriptContext: &ScriptContext{UserID: "user:42", RequestID: "req-1"}}
+
+	output, err := executeTengo(t, context.Background(), `result := ctx.userID + " " + ctx.requestID`, input)
+	require.NoError(t, err)
+	assert.Equal(t, "user:42 req-1", output.Result)
+}
+
+func TestScriptContext_FromGoContext(t *testing.T) {
+	ctx := WithScriptContext(context.Background(), &ScriptContext{UserID: "user:7"})
+
+	output, err := executeTengo(t, ctx, `result := ctx.userID`, &ScriptInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "user:7", output.Result)
+
+	// Without any ScriptContext, ctx still exists with empty values.
+	output, err = executeTengo(t, context.Background(), `result := ctx.userID`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", output.Result)
+}
+
+func TestScriptContext_ReadOnly(t *testing.T) {
+	input := &ScriptInput{ScriptContext: &ScriptContext{UserID: "user:42"}}
+
+	_, err := executeTengo(t, context.Background(), `ctx.userID = "admin"`, input)
+	assert.Error(t, err)
+	assert.Equal(t, "user:42", input.ScriptContext.UserID)
+}
+
+func TestScriptContext_Log(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	input := &ScriptInput{ScriptContext: &ScriptContext{UserID: "user:42", RequestID: "req-1", Logger: logger}}
+
+	_, err := executeTengo(t, context.Background(), `ctx.log("hit processed", "damage", 12)`, input)
+	require.NoError(t, err)
+
+	line := buf.String()
+	assert.Contains(t, line, `msg="hit processed"`)
+	assert.Contains(t, line, "user_id=user:42")
+	assert.Contains(t, line, "request_id=req-1")
+	assert.Contains(t, line, "damage=12")
+}
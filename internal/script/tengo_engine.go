@@ -88,6 +88,18 @@ func (e *TengoEngine) Execute(ctx context.Context, compiled *CompiledScript, inp
 		)
 	}
 
+	// Expose the caller's context as the read-only ctx object
+	scriptCtx := resolveScriptContext(ctx, input)
+	if err := tengoScript.Add("ctx", scriptCtx.tengoObject(compiled.Script)); err != nil {
+		return nil, NewScriptError(
+			ErrorTypeExecution,
+			compiled.Script.ModuleName,
+			compiled.Script.Name,
+			"failed to set script context",
+			err,
+		)
+	}
+
 	// Now compile the script with variables set
 	tengoCompiled, err := tengoScript.Compile()
 	if err != nil {
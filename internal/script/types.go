@@ -69,6 +69,11 @@ type ScriptInput struct {
 
 	// Available functions exposed to the script
 	Functions map[string]interface{}
+
+	// ScriptContext identifies the caller and is exposed to the script as
+	// the read-only ctx object. When nil, the one attached to the execution's
+	// context.Context is used, if any.
+	ScriptContext *ScriptContext
 }
 
 // HTTPRequestData contains HTTP request information for scripts
@@ -0,0 +1,63 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// WarmUp loads every embedded and external script and compiles each one, so
+// the first request that runs a script doesn't pay for it, then marks the
+// engine ready. Call it at startup once modules have registered their
+// embedded script providers.
+//
+// Scripts that fail to compile are reported in the returned error but don't
+// keep the engine from becoming ready: executing them fails as it would have
+// anyway, and hot reload can still fix them. Only a failure to load scripts
+// at all, or ctx ending, leaves the engine not ready.
+func (e *Engine) WarmUp(ctx context.Context) error {
+	if err := e.registry.LoadScripts(); err != nil {
+		return err
+	}
+
+	var errs []error
+	compiled := 0
+	for moduleName, scriptNames := range e.registry.ListScripts() {
+		for _, scriptName := range scriptNames {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := e.compile(moduleName, scriptName); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			compiled++
+		}
+	}
+
+	e.ready.Store(true)
+	slog.Info("Script engine warmed up", "compiled", compiled, "failed", len(errs))
+	return errors.Join(errs...)
+}
+
+// Ready reports whether WarmUp has completed, so the readiness endpoint can
+// hold traffic back until scripts are usable.
+func (e *Engine) Ready() bool {
+	return e.ready.Load()
+}
+
+// compile compiles one registered script with its language engine.
+func (e *Engine) compile(moduleName, scriptName string) error {
+	script, err := e.registry.GetScript(moduleName, scriptName)
+	if err != nil {
+		return err
+	}
+	langEngine, err := e.factory.CreateEngine(script.Language)
+	if err != nil {
+		return NewScriptError(ErrorTypeCompilation, moduleName, scriptName, "failed to create language engine", err)
+	}
+	if _, err := langEngine.Compile(script); err != nil {
+		return err
+	}
+	return nil
+}
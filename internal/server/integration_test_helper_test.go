@@ -120,7 +120,7 @@ func setupIntegrationTest(t *testing.T) (*server.Server, *httptest.Server, func(
 	liveQueryService := database.NewSurrealLiveQueryService(dbConn)
 
 	// Create PresenceService
-	presenceServiceInstance := presence.NewService(ps, ps, topicManager)
+	presenceServiceInstance := presence.NewService(context.Background(), ps, ps, topicManager)
 	registry.Set(reg, "core.presence.Service", presenceServiceInstance)
 
 	// Create ScriptEngine
@@ -143,7 +143,9 @@ func setupIntegrationTest(t *testing.T) (*server.Server, *httptest.Server, func(
 		FileRepository:   fileRepo,
 	}
 	modules := app.NewModules(moduleDeps)
-	s.InitModules(context.Background(), modules, reg)
+	if err := s.InitModules(context.Background(), modules, reg); err != nil {
+		t.Logf("Module initialization reported errors: %v", err)
+	}
 
 	// Initialize test emailer
 	emailer, err = email.NewEmailService(cfg)
@@ -192,8 +194,8 @@ func setupIntegrationTest(t *testing.T) (*server.Server, *httptest.Server, func(
 	// already simulates an authenticated user, which is sufficient for these tests.
 	wsGroup := s.E.Group("/ws")
 	// wsGroup.Use(appmiddleware.Auth(s.UserStore)) // This is handled by the test-specific middleware above
-	wsGroup.GET("/html", s.HTMLBridge.Handler())
-	wsGroup.GET("/data", s.DataBridge.Handler())
+	wsGroup.GET("/html", s.HTMLBridge.Handler(), server.LongLived)
+	wsGroup.GET("/data", s.DataBridge.Handler(), server.LongLived)
 
 	// Start the WebSocket bridges so they subscribe to pub/sub topics.
 	// This is crucial for the tests to receive messages.
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/module"
+	"github.com/nfrund/goby/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubModule records which startup hooks ran and optionally panics in one.
+type stubModule struct {
+	module.BaseModule
+	name       string
+	panicIn    string
+	registered bool
+	booted     bool
+}
+
+func (m *stubModule) Name() string { return m.name }
+
+func (m *stubModule) Register(reg *registry.Registry) error {
+	if m.panicIn == "register" {
+		panic("register exploded")
+	}
+	m.registered = true
+	return nil
+}
+
+func (m *stubModule) Boot(ctx context.Context, router *echo.Group, reg *registry.Registry) error {
+	if m.panicIn == "boot" {
+		panic("boot exploded")
+	}
+	m.booted = true
+	return nil
+}
+
+func TestInitModules_LenientSkipsPanickingModules(t *testing.T) {
+	s := &Server{E: echo.New(), Cfg: &config.Config{}}
+	first := &stubModule{name: "first"}
+	badRegister := &stubModule{name: "bad-register", panicIn: "register"}
+	badBoot := &stubModule{name: "bad-boot", panicIn: "boot"}
+	last := &stubModule{name: "last"}
+
+	err := s.InitModules(context.Background(), []module.Module{first, badRegister, badBoot, last}, registry.New(s.Cfg))
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrModulePanic)
+	assert.Contains(t, err.Error(), "module bad-register: register: module panicked: register exploded")
+	assert.Contains(t, err.Error(), "module bad-boot: boot: module panicked: boot exploded")
+
+	assert.True(t, first.booted)
+	assert.True(t, last.booted)
+	assert.True(t, badBoot.registered)
+	assert.False(t, badRegister.booted, "a module that panicked is not booted")
+}
+
+func TestInitModules_StrictAbortsOnFirstFailure(t *testing.T) {
+	s := &Server{E: echo.New(), Cfg: &config.Config{ModuleBootStrict: true}}
+	bad := &stubModule{name: "bad", panicIn: "boot"}
+	other := &stubModule{name: "other"}
+	failing := &failingModule{name: "failing"}
+
+	err := s.InitModules(context.Background(), []module.Module{bad, other}, registry.New(s.Cfg))
+	assert.ErrorIs(t, err, ErrModulePanic)
+	assert.False(t, other.booted, "strict mode stops at the first failure")
+
+	next := &stubModule{name: "next"}
+	err = s.InitModules(context.Background(), []module.Module{failing, next}, registry.New(s.Cfg))
+	assert.ErrorIs(t, err, errRegister)
+	assert.False(t, next.registered)
+}
+
+var errRegister = errors.New("cannot register")
+
+type failingModule struct {
+	module.BaseModule
+	name   string
+	booted bool
+}
+
+func (m *failingModule) Name() string                          { return m.name }
+func (m *failingModule) Register(reg *registry.Registry) error { return errRegister }
+
+func (m *failingModule) Boot(ctx context.Context, router *echo.Group, reg *registry.Registry) error {
+	m.booted = true
+	return nil
+}
+
+func TestInitModules_SkipsBootAfterRegisterError(t *testing.T) {
+	s := &Server{E: echo.New(), Cfg: &config.Config{}}
+	failing := &failingModule{name: "failing"}
+	other := &stubModule{name: "other"}
+
+	err := s.InitModules(context.Background(), []module.Module{failing, other}, registry.New(s.Cfg))
+	assert.ErrorIs(t, err, errRegister)
+	assert.False(t, failing.booted, "a module whose Register failed is not booted")
+	assert.True(t, other.booted)
+	assert.NotContains(t, s.booted, module.Module(failing))
+}
+
+// workerModule declares one periodic worker and records its shutdown.
+type workerModule struct {
+	module.BaseModule
+	runs     atomic.Int32
+	shutdown bool
+}
+
+func (m *workerModule) Name() string { return "worker" }
+
+func (m *workerModule) Workers() []module.Worker {
+	return []module.Worker{{
+		Name:     "tick",
+		Interval: 5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			m.runs.Add(1)
+			return nil
+		},
+	}}
+}
+
+func (m *workerModule) Shutdown(ctx context.Context) error {
+	m.shutdown = true
+	return nil
+}
+
+func TestInitModules_RunsModuleWorkersUntilShutdown(t *testing.T) {
+	s := &Server{E: echo.New(), Cfg: &config.Config{}}
+	mod := &workerModule{}
+
+	require.NoError(t, s.InitModules(context.Background(), []module.Module{mod}, registry.New(s.Cfg)))
+	require.Eventually(t, func() bool { return mod.runs.Load() >= 2 }, time.Second, time.Millisecond)
+
+	require.NoError(t, s.ShutdownModules(context.Background()))
+	assert.True(t, mod.shutdown)
+	stopped := mod.runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, mod.runs.Load(), "workers stop before the module shuts down")
+}
+
+// routeModule serves GET / under its route prefix, answering with its name.
+type routeModule struct {
+	module.BaseModule
+	name, prefix string
+}
+
+func (m *routeModule) Name() string        { return m.name }
+func (m *routeModule) RoutePrefix() string { return m.prefix }
+
+func (m *routeModule) Boot(ctx context.Context, router *echo.Group, reg *registry.Registry) error {
+	router.GET("/", func(c echo.Context) error { return c.String(http.StatusOK, m.name) })
+	return nil
+}
+
+func TestInitModules_MountsAtRoutePrefix(t *testing.T) {
+	s := &Server{E: echo.New(), Cfg: &config.Config{}}
+	home := &routeModule{name: "home", prefix: "/"}
+	inventory := &routeModule{name: "inventory", prefix: "api/v2/inventory/"}
+	invalid := &routeModule{name: "invalid", prefix: "/items/:id"}
+	plain := &stubModule{name: "plain"}
+
+	err := s.InitModules(context.Background(), []module.Module{home, inventory, invalid, plain}, registry.New(s.Cfg))
+	assert.ErrorIs(t, err, module.ErrInvalidRoutePrefix)
+
+	var paths []string
+	for _, route := range s.E.Routes() {
+		if route.Method == http.MethodGet {
+			paths = append(paths, route.Path)
+		}
+	}
+	assert.Contains(t, paths, "/app/")
+	assert.Contains(t, paths, "/app/api/v2/inventory/")
+	assert.NotContains(t, paths, "/app/items/:id/")
+	assert.True(t, plain.booted, "modules without a prefix mount at their name")
+
+	clash := &routeModule{name: "inventory-v2", prefix: "/api/v2/inventory"}
+	err = s.InitModules(context.Background(), []module.Module{inventory, clash}, registry.New(s.Cfg))
+	assert.ErrorContains(t, err, `module inventory-v2: mount: route prefix "/api/v2/inventory" is already used by module inventory`)
+	assert.Equal(t, []module.Module{inventory}, s.booted)
+
+	nested := &routeModule{name: "inventory-items", prefix: "/api/v2/inventory/./items"}
+	parent := &routeModule{name: "api", prefix: "/api/v2"}
+	files := &routeModule{name: "files", prefix: "/files/shared"}
+	presence := &routeModule{name: "presence", prefix: "/api"}
+	err = s.InitModules(context.Background(), []module.Module{home, inventory, nested, parent, files, presence}, registry.New(s.Cfg))
+	assert.ErrorContains(t, err, `module inventory-items: mount: route prefix "/api/v2/inventory/items" overlaps prefix "/api/v2/inventory" of module inventory`)
+	assert.ErrorContains(t, err, `module api: mount: route prefix "/api/v2" overlaps prefix "/api/v2/inventory" of module inventory`)
+	assert.ErrorContains(t, err, `module files: mount: route prefix "/files/shared" overlaps the core routes at /app/files`)
+	assert.ErrorContains(t, err, `module presence: mount: route prefix "/api" overlaps the core routes at /app/api/presence`)
+	assert.Equal(t, []module.Module{home, inventory}, s.booted, "only the root may contain other prefixes")
+}
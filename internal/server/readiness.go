@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/module"
+)
+
+// Readiness states reported by the /ready endpoint.
+const (
+	ReadinessReady    = "ready"
+	ReadinessDegraded = "degraded"
+	ReadinessNotReady = "not_ready"
+)
+
+// readinessTimeout bounds how long module health reporters may take.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck is one non-OK condition in a ReadinessReport. Module is
+// "database" or "scripts" for the server's own checks.
+type ReadinessCheck struct {
+	Module   string          `json:"module"`
+	Severity module.Severity `json:"severity"`
+	Message  string          `json:"message"`
+}
+
+// ReadinessReport is the /ready response body.
+type ReadinessReport struct {
+	Status string           `json:"status"`
+	Checks []ReadinessCheck `json:"checks,omitempty"`
+}
+
+// Ready reports whether the server should receive traffic.
+func (r ReadinessReport) Ready() bool {
+	return r.Status != ReadinessNotReady
+}
+
+// Readiness combines the database and script engine checks with the
+// conditions reported by booted modules that implement
+// module.HealthReporter. Only critical conditions make the server not ready;
+// degraded ones are reported while it keeps serving.
+func (s *Server) Readiness(ctx context.Context) ReadinessReport {
+	var checks []ReadinessCheck
+	if s.DBHealth != nil && !s.DBHealth.IsHealthy() {
+		checks = append(checks, ReadinessCheck{Module: "database", Severity: module.SeverityCritical, Message: "database unavailable"})
+	}
+	if s.ScriptEngine != nil && !s.ScriptEngine.Ready() {
+		checks = append(checks, ReadinessCheck{Module: "scripts", Severity: module.SeverityCritical, Message: "scripts not warmed up"})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	for _, mod := range s.booted {
+		reporter, ok := mod.(module.HealthReporter)
+		if !ok {
+			continue
+		}
+		for _, cond := range moduleHealth(ctx, mod, reporter) {
+			if cond.Severity == module.SeverityOK || cond.Severity == "" {
+				continue
+			}
+			checks = append(checks, ReadinessCheck{Module: mod.Name(), Severity: cond.Severity, Message: cond.Message})
+		}
+	}
+
+	report := ReadinessReport{Status: ReadinessReady, Checks: checks}
+	for _, check := range checks {
+		if check.Severity == module.SeverityCritical {
+			report.Status = ReadinessNotReady
+			break
+		}
+		report.Status = ReadinessDegraded
+	}
+	return report
+}
+
+// moduleHealth calls a module's health reporter, treating a panic as a
+// critical condition.
+func moduleHealth(ctx context.Context, mod module.Module, reporter module.HealthReporter) (conds []module.HealthCondition) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Recovered panic in module health check", "module", mod.Name(), "panic", r)
+			conds = []module.HealthCondition{{Severity: module.SeverityCritical, Message: fmt.Sprintf("health check panicked: %v", r)}}
+		}
+	}()
+	return reporter.Health(ctx)
+}
+
+// readyHandler serves the readiness report: 200 when ready or degraded, 503
+// when not ready.
+func (s *Server) readyHandler(c echo.Context) error {
+	report := s.Readiness(c.Request().Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
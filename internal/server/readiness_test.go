@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
+	"github.com/nfrund/goby/internal/module"
+	"github.com/nfrund/goby/internal/registry"
+	"github.com/nfrund/goby/internal/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthModule reports fixed health conditions.
+type healthModule struct {
+	module.BaseModule
+	name  string
+	conds []module.HealthCondition
+}
+
+func (m *healthModule) Name() string { return m.name }
+
+func (m *healthModule) Health(ctx context.Context) []module.HealthCondition { return m.conds }
+
+type fakeHealth bool
+
+func (h fakeHealth) IsHealthy() bool { return bool(h) }
+
+// warmingScripts is a script engine that hasn't finished warming up.
+type warmingScripts struct {
+	script.ScriptEngine
+}
+
+func (warmingScripts) Ready() bool { return false }
+
+func TestReadiness(t *testing.T) {
+	degraded := &healthModule{name: "payments", conds: []module.HealthCondition{
+		{Severity: module.SeverityOK, Message: "cache warm"},
+		{Severity: module.SeverityDegraded, Message: "external API latency high"},
+	}}
+	critical := &healthModule{name: "search", conds: []module.HealthCondition{
+		{Severity: module.SeverityCritical, Message: "index unavailable"},
+	}}
+
+	ready := func(t *testing.T, db fakeHealth, mods ...module.Module) (int, ReadinessReport) {
+		s := &Server{E: echo.New(), Cfg: &config.Config{}, DBHealth: db}
+		require.NoError(t, s.InitModules(context.Background(), mods, registry.New(s.Cfg)))
+		s.E.GET("/ready", s.readyHandler)
+
+		rec := httptest.NewRecorder()
+		s.E.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var report ReadinessReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		return rec.Code, report
+	}
+
+	t.Run("ready with no conditions", func(t *testing.T) {
+		code, report := ready(t, true, &healthModule{name: "quiet"})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, ReadinessReport{Status: ReadinessReady}, report)
+	})
+
+	t.Run("degraded but ready", func(t *testing.T) {
+		code, report := ready(t, true, degraded, &stubModule{name: "plain"})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, ReadinessDegraded, report.Status)
+		assert.Equal(t, []ReadinessCheck{
+			{Module: "payments", Severity: module.SeverityDegraded, Message: "external API latency high"},
+		}, report.Checks)
+	})
+
+	t.Run("critical module is not ready", func(t *testing.T) {
+		code, report := ready(t, true, degraded, critical)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, ReadinessNotReady, report.Status)
+		assert.Len(t, report.Checks, 2)
+	})
+
+	t.Run("scripts not warmed up is not ready", func(t *testing.T) {
+		s := &Server{E: echo.New(), Cfg: &config.Config{}, DBHealth: fakeHealth(true), ScriptEngine: warmingScripts{}}
+		report := s.Readiness(context.Background())
+		assert.Equal(t, ReadinessNotReady, report.Status)
+		assert.Equal(t, []ReadinessCheck{
+			{Module: "scripts", Severity: module.SeverityCritical, Message: "scripts not warmed up"},
+		}, report.Checks)
+	})
+
+	t.Run("database down is not ready", func(t *testing.T) {
+		code, report := ready(t, false)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, []ReadinessCheck{
+			{Module: "database", Severity: module.SeverityCritical, Message: "database unavailable"},
+		}, report.Checks)
+	})
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -8,6 +9,8 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/handlers"
 	"github.com/nfrund/goby/internal/middleware" // Your custom middleware
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/nfrund/goby/internal/websocket"
 )
 
 // RegisterRoutes sets up all the application routes.
@@ -16,6 +19,8 @@ func (s *Server) RegisterRoutes() {
 	rateLimiter := middleware.RateLimiter()
 	// The auth middleware needs the userStore, which is now a dependency of the server.
 	authMiddleware := middleware.Auth(s.UserStore)
+	// Operator endpoints are further limited to the users in ADMIN_EMAILS.
+	requireAdmin := middleware.RequireAdmin(s.Cfg.GetAdminEmails())
 
 	// Instantiate handlers that have dependencies directly within the routing setup.
 	// This co-locates handler creation with its routes and keeps the Server struct clean.
@@ -25,12 +30,34 @@ func (s *Server) RegisterRoutes() {
 	public := s.E.Group("")
 	public.GET("/", handlers.HomeGet)
 	public.GET("/about", handlers.AboutGet)
+	// Liveness: the process is up and serving requests.
 	public.GET("/health", func(c echo.Context) error {
 		return c.String(http.StatusOK, "OK")
 	})
+	// Readiness: the database is usable and no module reports a critical
+	// condition. Degraded modules are listed but keep the server ready.
+	public.GET("/ready", s.readyHandler)
+
+	// Prometheus metrics in the text exposition format.
+	public.GET("/metrics", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		if err := websocket.WritePrometheus(c.Response(), s.HTMLBridge, s.DataBridge); err != nil {
+			return err
+		}
+		if pm, ok := s.PubSub.(interface{ WritePrometheus(io.Writer) error }); ok {
+			return pm.WritePrometheus(c.Response())
+		}
+		return nil
+	})
+
+	// DB-backed routes are guarded so they fail fast with 503 while the
+	// database is unavailable (e.g. when started in degraded mode).
+	requireDB := middleware.RequireHealthy(s.DBHealth)
 
 	// Auth routes
 	auth := s.E.Group("/auth")
+	auth.Use(requireDB)
 	// Redirect both /auth and /auth/ to the login page for convenience.
 	redirectLogin := func(c echo.Context) error {
 		return c.Redirect(http.StatusTemporaryRedirect, "/auth/login")
@@ -48,13 +75,56 @@ func (s *Server) RegisterRoutes() {
 	auth.GET("/reset-password", authHandler.ResetPasswordGetHandler)
 	auth.POST("/reset-password", authHandler.ResetPasswordPostHandler)
 
+	// Email delivery tracking: provider webhooks are authenticated by their
+	// signature, the status lookup and failed email list by the user session.
+	// Status lookups only answer for emails sent to the signed-in user, and
+	// the failed list, which names every recipient, is for admins only.
+	if s.EmailHandler != nil {
+		s.E.POST("/webhooks/email", s.EmailHandler.Webhook, requireDB)
+		internal := s.E.Group("/internal")
+		internal.Use(requireDB, authMiddleware)
+		internal.GET("/emails/:id/status", s.EmailHandler.Status)
+		internal.GET("/emails/failed", s.EmailHandler.Failed, requireAdmin)
+	}
+	// A route on its own rather than in the /internal group, which only
+	// exists when email tracking is enabled. It lists every user's
+	// connections, so only admins may see it.
+	s.E.GET("/internal/presence/debug", s.PresenceHandler.DebugSnapshot, requireDB, authMiddleware, requireAdmin)
+	// Script inspection and reload, for when the file watcher is disabled.
+	// Script source and reloads are for admins only.
+	if admin, ok := s.ScriptEngine.(handlers.ScriptAdmin); ok {
+		scripts := handlers.NewScriptHandler(admin)
+		s.E.GET("/internal/scripts", scripts.List, requireDB, authMiddleware, requireAdmin)
+		s.E.GET("/internal/scripts/:module/:name", scripts.Get, requireDB, authMiddleware, requireAdmin)
+		s.E.POST("/internal/scripts/:module/:name/reload", scripts.Reload, requireDB, authMiddleware, requireAdmin)
+	}
+
 	// Protected routes (require authentication)
 	protected := s.E.Group("/app")
-	protected.Use(authMiddleware)
+	protected.Use(requireDB, authMiddleware)
 
-	// Standard routes
-	protected.GET("/ws/html", s.HTMLBridge.Handler())
-	protected.GET("/ws/data", s.DataBridge.Handler())
+	// Standard routes. WebSocket connections are long-lived and manage
+	// their own deadlines, so they are exempt from the server timeouts.
+	// Bridges that admit guests authenticate optionally instead, as route
+	// middleware because a second /app group would clash with this one.
+	guestAuth := middleware.OptionalAuth(s.UserStore)
+	guestsAllowed := false
+	for endpoint, bridge := range map[string]*websocket.Bridge{"html": s.HTMLBridge, "data": s.DataBridge} {
+		// Each bridge is also served as server-sent events, for clients
+		// behind proxies that block WebSocket upgrades.
+		routes := map[string]echo.HandlerFunc{
+			"/ws/" + endpoint:  bridge.Handler(),
+			"/sse/" + endpoint: bridge.EventStreamHandler(),
+		}
+		for path, handler := range routes {
+			if bridge.AllowsGuests() {
+				guestsAllowed = true
+				s.E.GET("/app"+path, handler, requireDB, guestAuth, LongLived)
+			} else {
+				protected.GET(path, handler, LongLived)
+			}
+		}
+	}
 
 	// Debug: Check if presence handler is available
 	if s.PresenceHandler == nil {
@@ -66,10 +136,26 @@ func (s *Server) RegisterRoutes() {
 	// Presence API routes (JSON only - modules handle their own HTML)
 	slog.Info("Registering presence routes")
 	protected.GET("/api/presence", s.PresenceHandler.GetPresence)
+	protected.GET("/api/presence/me/connections", s.PresenceHandler.GetMyConnections)
 	protected.GET("/api/presence/:userID", s.PresenceHandler.GetUserPresence)
 	protected.GET("/api/presence/health", s.PresenceHandler.HealthCheck)
-	protected.POST("/api/presence/heartbeat", s.PresenceHandler.Heartbeat)
-	protected.POST("/api/presence/offline", s.PresenceHandler.Offline)
+	// Guests connected over a bridge need to report their own presence too.
+	if guestsAllowed {
+		s.E.POST("/app/api/presence/heartbeat", s.PresenceHandler.Heartbeat, requireDB, guestAuth)
+		s.E.POST("/app/api/presence/offline", s.PresenceHandler.Offline, requireDB, guestAuth)
+	} else {
+		protected.POST("/api/presence/heartbeat", s.PresenceHandler.Heartbeat)
+		protected.POST("/api/presence/offline", s.PresenceHandler.Offline)
+	}
+
+	// Runtime log level
+	protected.GET("/api/admin/log-level", handlers.LogLevelGet, requireAdmin)
+	protected.PUT("/api/admin/log-level", handlers.LogLevelPut, requireAdmin)
+
+	// Runtime topic kill-switches
+	topicAdmin := handlers.NewTopicHandler(topicmgr.Default())
+	protected.GET("/api/admin/topics/:name", topicAdmin.Get, requireAdmin)
+	protected.PUT("/api/admin/topics/:name/enabled", topicAdmin.SetEnabled, requireAdmin)
 
 	// Debug endpoints (only in development)
 
@@ -94,7 +180,23 @@ func (s *Server) RegisterRoutes() {
 	// The FileHandler is constructed in main.go and passed to the server.
 	filesGroup := protected.Group("/files") // e.g., /app/files
 	filesGroup.GET("", s.FileHandler.ListFiles)
-	filesGroup.POST("/upload", s.FileHandler.UploadFile)
+	filesGroup.POST("/upload", s.FileHandler.UploadFile, middleware.BodyLimit(uploadBodyLimit(s.Cfg.GetMaxFileSize())))
 	filesGroup.DELETE("/:id", s.FileHandler.DeleteFile)
+	filesGroup.POST("/delete-batch", s.FileHandler.DeleteFiles)
 	filesGroup.GET("/:id/download", s.FileHandler.DownloadFile)
+	filesGroup.GET("/:id/thumbnail", s.FileHandler.Thumbnail)
+}
+
+// uploadMultipartOverhead is the room left in an upload's body limit for the
+// multipart boundaries, headers and form fields around the file.
+const uploadMultipartOverhead = 64 << 10
+
+// uploadBodyLimit returns the body limit of the upload route: enough for a
+// file of maxFileSize bytes, or no limit when file sizes are unlimited, so
+// the file handler's own size check is the one uploads hit.
+func uploadBodyLimit(maxFileSize int64) int64 {
+	if maxFileSize <= 0 {
+		return 0
+	}
+	return maxFileSize + uploadMultipartOverhead
 }
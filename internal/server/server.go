@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
@@ -36,11 +37,20 @@ type Server struct {
 	Renderer        rendering.Renderer
 	FileHandler     *handlers.FileHandler
 	PresenceHandler *handlers.PresenceHandler
+	EmailHandler    *handlers.EmailHandler
 	HTMLBridge      *websocket.Bridge
 	DataBridge      *websocket.Bridge
 	ScriptEngine    script.ScriptEngine
+	// DBHealth reports database availability for readiness checks and for
+	// guarding DB-backed routes. It may be nil.
+	DBHealth appmiddleware.HealthChecker
+	// ErrorMapper turns errors returned by handlers into JSON error
+	// responses. Modules may Register rules for their own sentinel errors.
+	ErrorMapper *handlers.ErrorMapper
 
 	modules []module.Module
+	booted  []module.Module
+	workers map[module.Module]*module.WorkerGroup
 	PubSub  pubsub.Publisher
 }
 
@@ -57,11 +67,14 @@ type Dependencies struct {
 	DataBridge      *websocket.Bridge
 	FileHandler     *handlers.FileHandler
 	PresenceHandler *handlers.PresenceHandler
-	ScriptEngine    script.ScriptEngine
+	// EmailHandler is optional; when set, email webhook and status routes are registered.
+	EmailHandler *handlers.EmailHandler
+	ScriptEngine script.ScriptEngine
+	// DBHealth is optional; when set, DB-backed routes return 503 while it is unhealthy.
+	DBHealth appmiddleware.HealthChecker
 }
 
-func setupErrorHandling(e *echo.Echo) {
-	// 1. Recover Middleware: CRITICAL for Panics
+func setupErrorHandling(e *echo.Echo, mapper *handlers.ErrorMapper) {
 	// 1. Recover Middleware: CRITICAL for Panics.
 	// This catches any panic that occurs during request handling, prevents the Go app
 	// from crashing, and logs the full stack trace to your console.
@@ -69,18 +82,19 @@ func setupErrorHandling(e *echo.Echo) {
 
 	// 2. Custom HTTP Error Handler: CRITICAL for Unhandled Errors
 	// This intercepts errors returned by handlers (e.g., 'return err') or by Echo's internal systems.
+	// Echo HTTPErrors and the shared typed errors (domain.ErrNotFound,
+	// database.ErrNotFound, ...) become their status codes; anything else is
+	// an unexpected internal error.
 	e.HTTPErrorHandler = func(err error, c echo.Context) {
 		if c.Response().Committed {
 			return // Cannot write headers after the response is committed.
 		}
 
-		// Try to cast the error to a standard Echo HTTPError
-		he, ok := err.(*echo.HTTPError)
+		status, errResp, ok := mapper.Map(err)
 		if !ok {
 			// Get the request-scoped logger.
 			logger := appmiddleware.FromContext(c.Request().Context())
 
-			// If it's not an Echo HTTPError, it's an unexpected internal error.
 			logger.Error("Internal Server Error (Unhandled)",
 				"error", err.Error(),
 				"method", c.Request().Method,
@@ -88,33 +102,29 @@ func setupErrorHandling(e *echo.Echo) {
 				"remote_ip", c.RealIP(),
 				"stack_trace", string(debug.Stack()),
 			)
-			// Ensure we still return a standard 500 response
-			he = &echo.HTTPError{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
 		}
 
 		// Log all 5xx errors returned by handlers as errors, and 4xx as warnings.
-		if he.Code >= 500 {
+		if status >= 500 {
 			slog.Error("HTTP Error",
-				"status", he.Code,
-				"message", he.Message,
+				"status", status,
+				"code", errResp.Code,
+				"message", errResp.Message,
 				"path", c.Path(),
 				"method", c.Request().Method,
 			)
-		} else if he.Code >= 400 {
+		} else if status >= 400 {
 			slog.Warn("Client Error",
-				"status", he.Code,
-				"message", he.Message,
+				"status", status,
+				"code", errResp.Code,
+				"message", errResp.Message,
+				"error", err,
 				"path", c.Path(),
 				"method", c.Request().Method,
 			)
 		}
 
-		// Respond to the client (we'll just use JSON for errors for simplicity)
-		errResp := handlers.ErrorResponse{
-			Code:    http.StatusText(he.Code), // A simple default code
-			Message: fmt.Sprintf("%v", he.Message),
-		}
-		c.JSON(he.Code, errResp)
+		c.JSON(status, errResp)
 	}
 }
 
@@ -123,7 +133,16 @@ func New(deps Dependencies) (*Server, error) {
 	// The echo instance is now created in main.go and passed in as a dependency.
 	// This allows us to configure it before the server is created.
 	e := deps.Echo
-	setupErrorHandling(e)
+	errorMapper := handlers.NewErrorMapper()
+	setupErrorHandling(e, errorMapper)
+
+	// Bound how long clients may take to send requests and receive
+	// responses. WebSocket routes opt out with LongLived.
+	timeouts := TimeoutsFromConfig(deps.Config)
+	if err := timeouts.Validate(); err != nil {
+		return nil, err
+	}
+	timeouts.Apply(e.Server)
 
 	// Register the custom validator.
 	e.Validator = handlers.NewValidator()
@@ -147,7 +166,10 @@ func New(deps Dependencies) (*Server, error) {
 		DataBridge:      deps.DataBridge,
 		FileHandler:     deps.FileHandler,
 		PresenceHandler: deps.PresenceHandler,
+		EmailHandler:    deps.EmailHandler,
 		ScriptEngine:    deps.ScriptEngine,
+		DBHealth:        deps.DBHealth,
+		ErrorMapper:     errorMapper,
 	}
 
 	// Configure and use session middleware
@@ -168,6 +190,10 @@ func New(deps Dependencies) (*Server, error) {
 	// Add security headers middleware for production hardening.
 	s.E.Use(middleware.Secure())
 
+	// Reject oversized request bodies with 413 before they are read in full.
+	// Routes can raise the limit with their own appmiddleware.BodyLimit.
+	s.E.Use(appmiddleware.BodyLimit(s.Cfg.GetServerMaxBodySize()))
+
 	// Serve static files from disk or embedded FS based on APP_STATIC.
 	if os.Getenv("APP_STATIC") == "embed" {
 		slog.Info("Serving embedded static assets")
@@ -184,6 +210,10 @@ func New(deps Dependencies) (*Server, error) {
 	return s, nil
 }
 
+// ErrModulePanic marks a module failure caused by a panic in one of its
+// startup hooks.
+var ErrModulePanic = errors.New("module panicked")
+
 // InitModules runs the two-phase startup for all registered application modules.
 //
 // The process is as follows:
@@ -192,8 +222,41 @@ func New(deps Dependencies) (*Server, error) {
 //  2. Boot Phase: Each module performs its startup logic, such as starting
 //     background workers and registering HTTP routes. During this phase, a module
 //     can safely resolve services that were registered by other modules in the first phase.
-func (s *Server) InitModules(ctx context.Context, modules []module.Module, reg *registry.Registry) {
+//     Once a module has booted, the workers it declares through
+//     module.WorkerProvider start running.
+//
+// Each module's routes are mounted under /app at module.RoutePrefix, its name
+// unless it implements module.RoutePrefixer. A module whose prefix is invalid,
+// overlaps the prefix of an earlier module, such as /a and /a/b, or overlaps
+// one of the core routes in reservedRoutePrefixes fails to boot. Only the
+// root prefix "/" may contain the others.
+//
+// A panic in a module's hooks is recovered and reported as an error wrapping
+// ErrModulePanic. A module that fails, or panics in, one phase is skipped for
+// the remaining ones, so a module whose Register failed is never booted.
+// By default a module that fails either phase is logged and skipped so the
+// rest of the application still starts, and the failures are returned joined
+// together. With MODULE_BOOT_STRICT set, InitModules stops at the first
+// failure and returns it, and the caller should abort startup.
+func (s *Server) InitModules(ctx context.Context, modules []module.Module, reg *registry.Registry) error {
 	s.modules = modules
+	s.booted = nil
+	strict := s.Cfg.GetModuleBootStrict()
+
+	var errs error
+	failed := make(map[module.Module]bool)
+	// fail records a module failure and reports whether startup should stop.
+	fail := func(mod module.Module, phase string, err error) bool {
+		failed[mod] = true
+		if strict {
+			slog.Error("Module failed, aborting startup (MODULE_BOOT_STRICT)", "module", mod.Name(), "phase", phase, "error", err)
+			errs = fmt.Errorf("module %s: %s: %w", mod.Name(), phase, err)
+			return true
+		}
+		slog.Error("Module failed, skipping it", "module", mod.Name(), "phase", phase, "error", err)
+		errs = errors.Join(errs, fmt.Errorf("module %s: %s: %w", mod.Name(), phase, err))
+		return false
+	}
 
 	// --- Phase 0: Register Client Actions ---
 	// Allow modules to register their client-callable WebSocket actions.
@@ -201,30 +264,147 @@ func (s *Server) InitModules(ctx context.Context, modules []module.Module, reg *
 	// before any module starts its background services.
 	for _, mod := range modules {
 		if registrar, ok := mod.(module.ClientActionRegistrar); ok {
-			registrar.RegisterClientActions(s.HTMLBridge, s.DataBridge)
+			err := callModule(mod, func() error {
+				registrar.RegisterClientActions(s.HTMLBridge, s.DataBridge)
+				return nil
+			})
+			if err != nil && fail(mod, "register client actions", err) {
+				return errs
+			}
 		}
 	}
 
 	// --- Phase 1: Register Module-Provided Services ---
 	for _, mod := range modules {
-		if err := mod.Register(reg); err != nil {
-			slog.Error("Failed to register module", "module", mod.Name(), "error", err)
-			// In a real app, you might want to os.Exit(1) here.
+		if failed[mod] {
+			continue
+		}
+		if err := callModule(mod, func() error { return mod.Register(reg) }); err != nil && fail(mod, "register", err) {
+			return errs
 		}
 	}
 
 	// --- Phase 2: Boot all modules ---
 	// Now that all services are registered, modules can safely resolve dependencies.
 	protected := s.E.Group("/app")
-	protected.Use(appmiddleware.Auth(s.UserStore)) // Auth middleware for all module routes
+	protected.Use(appmiddleware.RequireHealthy(s.DBHealth)) // 503 while the database is unavailable
+	protected.Use(appmiddleware.Auth(s.UserStore))          // Auth middleware for all module routes
 
+	mounted := make(map[string]string) // route prefix -> module name
 	for _, mod := range modules {
+		if failed[mod] {
+			continue
+		}
 		// Create a dedicated sub-group for each module under the /app prefix.
-		group := protected.Group("/" + mod.Name())
-		if err := mod.Boot(ctx, group, reg); err != nil {
-			slog.Error("Failed to boot module", "module", mod.Name(), "error", err)
+		prefix, err := module.RoutePrefix(mod)
+		if err == nil {
+			err = checkRoutePrefix(prefix, mounted)
+		}
+		if err != nil {
+			if fail(mod, "mount", err) {
+				return errs
+			}
+			continue
+		}
+		mounted[prefix] = mod.Name()
+		if prefix == "/" {
+			prefix = ""
+		}
+		group := protected.Group(prefix)
+		if err := callModule(mod, func() error { return mod.Boot(ctx, group, reg) }); err != nil {
+			if fail(mod, "boot", err) {
+				return errs
+			}
+			continue
+		}
+		s.booted = append(s.booted, mod)
+		if err := s.startWorkers(ctx, mod); err != nil && fail(mod, "start workers", err) {
+			return errs
+		}
+	}
+	return errs
+}
+
+// reservedRoutePrefixes are the core routes under /app that modules can't
+// be mounted over.
+var reservedRoutePrefixes = []string{"/ws", "/sse", "/files", "/api/presence", "/api/admin"}
+
+// checkRoutePrefix reports an error if prefix overlaps a reserved prefix or
+// one in mounted, which maps the prefixes already taken to their modules.
+func checkRoutePrefix(prefix string, mounted map[string]string) error {
+	for _, reserved := range reservedRoutePrefixes {
+		if routePrefixesOverlap(prefix, reserved) {
+			return fmt.Errorf("route prefix %q overlaps the core routes at /app%s", prefix, reserved)
 		}
 	}
+	for other, owner := range mounted {
+		if prefix == other {
+			return fmt.Errorf("route prefix %q is already used by module %s", prefix, owner)
+		}
+		if routePrefixesOverlap(prefix, other) {
+			return fmt.Errorf("route prefix %q overlaps prefix %q of module %s", prefix, other, owner)
+		}
+	}
+	return nil
+}
+
+// routePrefixesOverlap reports whether a and b are the same prefix or one is
+// nested in the other. The root prefix only overlaps itself.
+func routePrefixesOverlap(a, b string) bool {
+	if a == "/" || b == "/" {
+		return a == b
+	}
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// startWorkers runs the periodic tasks of a booted module that implements
+// module.WorkerProvider.
+func (s *Server) startWorkers(ctx context.Context, mod module.Module) error {
+	provider, ok := mod.(module.WorkerProvider)
+	if !ok {
+		return nil
+	}
+	var workers []module.Worker
+	if err := callModule(mod, func() error { workers = provider.Workers(); return nil }); err != nil {
+		return err
+	}
+	if len(workers) == 0 {
+		return nil
+	}
+
+	group := module.NewWorkerGroup(mod.Name(), workers...)
+	group.Start(ctx)
+	if s.workers == nil {
+		s.workers = make(map[module.Module]*module.WorkerGroup)
+	}
+	s.workers[mod] = group
+	slog.Info("Started module workers", "module", mod.Name(), "count", len(workers))
+	return nil
+}
+
+// ShutdownModules stops each module's workers and then calls its Shutdown,
+// returning every failure joined together.
+func (s *Server) ShutdownModules(ctx context.Context) error {
+	var errs error
+	for _, mod := range s.modules {
+		if group, ok := s.workers[mod]; ok {
+			errs = errors.Join(errs, group.Stop(ctx))
+		}
+		errs = errors.Join(errs, mod.Shutdown(ctx))
+	}
+	return errs
+}
+
+// callModule runs one of mod's startup hooks, turning a panic into an error
+// wrapping ErrModulePanic so one faulty module cannot crash startup.
+func callModule(mod module.Module, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Recovered panic in module startup", "module", mod.Name(), "panic", r, "stack_trace", string(debug.Stack()))
+			err = fmt.Errorf("%w: %v", ErrModulePanic, r)
+		}
+	}()
+	return fn()
 }
 
 // GetScriptEngine returns the script engine for use by modules
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/handlers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,7 +32,7 @@ func TestHTTPErrorHandler_WithStackTrace(t *testing.T) {
 	defer slog.SetDefault(originalLogger)
 
 	// 2. Set up the error handler we want to test
-	setupErrorHandling(e)
+	setupErrorHandling(e, handlers.NewErrorMapper())
 
 	// 3. Define a route that will always produce an unhandled error
 	e.GET("/test-unhandled-error", func(c echo.Context) error {
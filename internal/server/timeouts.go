@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/config"
+)
+
+// Timeouts are the HTTP server's connection timeouts. A zero value disables
+// the corresponding timeout.
+//
+// WebSocket connections outlive any sensible WriteTimeout, so upgrade routes
+// are wrapped with LongLived, which clears the connection's deadlines before
+// the upgrade. From then on the WebSocket bridge enforces its own per-write
+// deadline (WS_WRITE_TIMEOUT) and pings clients to detect dead peers, so
+// IdleTimeout and ReadTimeout do not apply to an upgraded connection either.
+type Timeouts struct {
+	// Read bounds reading an entire request, including the body. Uploads
+	// must finish within it.
+	Read time.Duration
+	// ReadHeader bounds reading request headers, which is what stops
+	// slowloris-style clients from holding connections open.
+	ReadHeader time.Duration
+	// Write bounds writing a response, measured from the end of the
+	// request headers.
+	Write time.Duration
+	// Idle bounds how long a keep-alive connection waits for its next request.
+	Idle time.Duration
+}
+
+// TimeoutsFromConfig reads the SERVER_*_TIMEOUT settings.
+func TimeoutsFromConfig(cfg config.Provider) Timeouts {
+	return Timeouts{
+		Read:       cfg.GetServerReadTimeout(),
+		ReadHeader: cfg.GetServerReadHeaderTimeout(),
+		Write:      cfg.GetServerWriteTimeout(),
+		Idle:       cfg.GetServerIdleTimeout(),
+	}
+}
+
+// Validate reports negative timeouts and a header timeout longer than the
+// whole-request timeout, which would never take effect.
+func (t Timeouts) Validate() error {
+	for name, d := range map[string]time.Duration{
+		"SERVER_READ_TIMEOUT":        t.Read,
+		"SERVER_READ_HEADER_TIMEOUT": t.ReadHeader,
+		"SERVER_WRITE_TIMEOUT":       t.Write,
+		"SERVER_IDLE_TIMEOUT":        t.Idle,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", name, d)
+		}
+	}
+	if t.Read > 0 && t.ReadHeader > t.Read {
+		return fmt.Errorf("SERVER_READ_HEADER_TIMEOUT (%s) must not exceed SERVER_READ_TIMEOUT (%s)", t.ReadHeader, t.Read)
+	}
+	return nil
+}
+
+// Apply sets the timeouts on srv.
+func (t Timeouts) Apply(srv *http.Server) {
+	srv.ReadTimeout = t.Read
+	srv.ReadHeaderTimeout = t.ReadHeader
+	srv.WriteTimeout = t.Write
+	srv.IdleTimeout = t.Idle
+}
+
+// LongLived exempts a route from the server's read and write timeouts by
+// clearing the connection deadlines before the handler runs. Use it only for
+// connections that enforce their own deadlines, such as WebSocket upgrades.
+func LongLived(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rc := http.NewResponseController(c.Response())
+		if err := rc.SetReadDeadline(time.Time{}); err != nil {
+			slog.Debug("Could not clear read deadline", "path", c.Path(), "error", err)
+		}
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			slog.Debug("Could not clear write deadline", "path", c.Path(), "error", err)
+		}
+		return next(c)
+	}
+}
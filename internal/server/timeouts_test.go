@@ -0,0 +1,44 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeouts_Validate(t *testing.T) {
+	assert.NoError(t, Timeouts{Read: 30 * time.Second, ReadHeader: 5 * time.Second}.Validate())
+	assert.NoError(t, Timeouts{ReadHeader: 5 * time.Second}.Validate(), "zero disables the read timeout")
+	assert.ErrorContains(t, Timeouts{Write: -time.Second}.Validate(), "SERVER_WRITE_TIMEOUT")
+	assert.ErrorContains(t, Timeouts{Read: time.Second, ReadHeader: 2 * time.Second}.Validate(), "must not exceed")
+}
+
+func TestLongLived_ExemptsRouteFromWriteTimeout(t *testing.T) {
+	e := echo.New()
+	slow := func(c echo.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	}
+	e.GET("/slow", slow)
+	e.GET("/long", slow, LongLived)
+
+	srv := httptest.NewUnstartedServer(e)
+	Timeouts{Write: 50 * time.Millisecond}.Apply(srv.Config)
+	srv.Start()
+	defer srv.Close()
+
+	_, err := http.Get(srv.URL + "/slow")
+	assert.Error(t, err, "the write timeout cuts off a slow response")
+
+	resp, err := http.Get(srv.URL + "/long")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "done", string(body))
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrUnsafePath is returned when a filename or storage path could escape its
+// intended directory (e.g. via "..", an absolute path, or a null byte).
+var ErrUnsafePath = errors.New("unsafe storage path")
+
+// SanitizeMode controls how user-supplied filenames are sanitized.
+type SanitizeMode int
+
+const (
+	// SanitizeNormalize strips directory components and unsafe characters,
+	// keeping only the final path element (e.g. "../../etc/passwd" -> "passwd").
+	SanitizeNormalize SanitizeMode = iota
+	// SanitizeReject refuses any filename that is not already a plain, safe name.
+	SanitizeReject
+)
+
+// ParseSanitizeMode converts a configuration value ("normalize", "reject" or
+// its alias "strict") into a SanitizeMode. An empty value selects
+// SanitizeNormalize; any other value is an error.
+func ParseSanitizeMode(value string) (SanitizeMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "normalize":
+		return SanitizeNormalize, nil
+	case "reject", "strict":
+		return SanitizeReject, nil
+	default:
+		return SanitizeNormalize, fmt.Errorf("unknown filename sanitize mode %q: must be 'normalize', 'reject' or 'strict'", value)
+	}
+}
+
+// String returns the configuration name of the mode.
+func (m SanitizeMode) String() string {
+	if m == SanitizeReject {
+		return "reject"
+	}
+	return "normalize"
+}
+
+// SanitizeFilename returns a filename that is safe to use as a single path
+// element inside a storage directory. In SanitizeReject mode any filename
+// containing separators, "..", or null bytes results in ErrUnsafePath.
+func SanitizeFilename(name string, mode SanitizeMode) (string, error) {
+	if mode == SanitizeReject {
+		if name == "" || name == "." || name == ".." ||
+			strings.ContainsAny(name, "/\\\x00") {
+			return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+		}
+		return name, nil
+	}
+
+	// Normalize: drop null bytes, treat backslashes as separators so Windows
+	// style paths are handled on every OS, then keep only the last element.
+	cleaned := strings.ReplaceAll(name, "\x00", "")
+	cleaned = strings.ReplaceAll(cleaned, "\\", "/")
+	cleaned = path.Base(path.Clean("/" + cleaned))
+	if cleaned == "/" || cleaned == "." || cleaned == ".." || cleaned == "" {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return cleaned, nil
+}
+
+// ValidatePath ensures a storage key is a clean, relative path that cannot
+// escape the root of the store.
+func ValidatePath(p string) error {
+	if p == "" || strings.ContainsAny(p, "\\\x00") || path.IsAbs(p) {
+		return fmt.Errorf("%w: %q", ErrUnsafePath, p)
+	}
+	if path.Clean(p) != p {
+		return fmt.Errorf("%w: %q", ErrUnsafePath, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q", ErrUnsafePath, p)
+		}
+	}
+	return nil
+}
+
+// JoinPath joins a base directory and a filename into a storage key and
+// verifies that the result stays inside base.
+func JoinPath(base, filename string) (string, error) {
+	joined := path.Join(base, filename)
+	if err := ValidatePath(joined); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(joined, path.Clean(base)+"/") {
+		return "", fmt.Errorf("%w: %q escapes %q", ErrUnsafePath, filename, base)
+	}
+	return joined, nil
+}
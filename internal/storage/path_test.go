@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	t.Run("normalize strips traversal", func(t *testing.T) {
+		cases := map[string]string{
+			"report.pdf":               "report.pdf",
+			"../../etc/passwd":         "passwd",
+			"/etc/passwd":              "passwd",
+			"..\\..\\windows\\win.ini": "win.ini",
+			"evil\x00.png":             "evil.png",
+		}
+		for input, want := range cases {
+			got, err := SanitizeFilename(input, SanitizeNormalize)
+			require.NoError(t, err, input)
+			assert.Equal(t, want, got, input)
+		}
+	})
+
+	t.Run("normalize rejects names with nothing left", func(t *testing.T) {
+		for _, input := range []string{"", "..", "../..", "/"} {
+			_, err := SanitizeFilename(input, SanitizeNormalize)
+			assert.ErrorIs(t, err, ErrUnsafePath, input)
+		}
+	})
+
+	t.Run("reject mode refuses unsafe names", func(t *testing.T) {
+		for _, input := range []string{"../../etc/passwd", "/etc/passwd", "a\\b", "a\x00b", ".."} {
+			_, err := SanitizeFilename(input, SanitizeReject)
+			assert.ErrorIs(t, err, ErrUnsafePath, input)
+		}
+		got, err := SanitizeFilename("report.pdf", SanitizeReject)
+		require.NoError(t, err)
+		assert.Equal(t, "report.pdf", got)
+	})
+}
+
+func TestParseSanitizeMode(t *testing.T) {
+	cases := map[string]SanitizeMode{
+		"":          SanitizeNormalize,
+		"normalize": SanitizeNormalize,
+		"reject":    SanitizeReject,
+		" Strict ":  SanitizeReject,
+	}
+	for input, want := range cases {
+		got, err := ParseSanitizeMode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseSanitizeMode("lenient")
+	assert.ErrorContains(t, err, "lenient")
+}
+
+func TestJoinPath(t *testing.T) {
+	p, err := JoinPath("users/u1", "1-report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "users/u1/1-report.pdf", p)
+
+	_, err = JoinPath("users/u1", "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrUnsafePath)
+
+	_, err = JoinPath("users/u1", "../u2/secret.txt")
+	assert.ErrorIs(t, err, ErrUnsafePath)
+}
+
+func TestAferoStore_RejectsUnsafePaths(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	store := NewAferoStore(memFs)
+	ctx := context.Background()
+
+	for _, p := range []string{"../escape.txt", "/abs/file.txt", "users/../../x", "a\x00b"} {
+		_, err := store.Save(ctx, p, bytes.NewReader([]byte("x")))
+		assert.ErrorIs(t, err, ErrUnsafePath, p)
+
+		_, err = store.Get(ctx, p)
+		assert.ErrorIs(t, err, ErrUnsafePath, p)
+
+		assert.ErrorIs(t, store.Delete(ctx, p), ErrUnsafePath, p)
+	}
+}
@@ -21,6 +21,9 @@ func NewAferoStore(fs afero.Fs) Store {
 
 // Save writes the content of the reader to the given path in the in-memory filesystem.
 func (s *aferoStore) Save(ctx context.Context, path string, reader io.Reader) (int64, error) {
+	if err := ValidatePath(path); err != nil {
+		return 0, err
+	}
 	if err := s.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return 0, err
 	}
@@ -34,10 +37,16 @@ func (s *aferoStore) Save(ctx context.Context, path string, reader io.Reader) (i
 
 // Delete removes a file from the in-memory filesystem.
 func (s *aferoStore) Delete(ctx context.Context, path string) error {
+	if err := ValidatePath(path); err != nil {
+		return err
+	}
 	return s.fs.Remove(path)
 }
 
 // Get opens a file from the in-memory filesystem for reading.
 func (s *aferoStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
 	return s.fs.OpenFile(path, os.O_RDONLY, 0)
 }
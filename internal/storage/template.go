@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPathTemplate is the storage key layout used when none is configured:
+// one directory per user, with the upload time making each key unique.
+const DefaultPathTemplate = "users/{{.UserID}}/{{.UploadedAt.UnixNano}}-{{.Filename}}"
+
+// PathData holds the values available to a path template.
+type PathData struct {
+	// UserID is the uploading user's record ID (e.g. "user:abc123").
+	UserID string
+	// FileID is a random 16-character hex ID unique to this upload.
+	FileID string
+	// Filename is the sanitized upload filename.
+	Filename string
+	// UploadedAt is the time of the upload in UTC.
+	UploadedAt time.Time
+	// ContentHash is the hex SHA-256 of the file's content.
+	ContentHash string
+}
+
+// NewFileID returns a random ID for PathData.FileID.
+func NewFileID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// PathTemplate computes storage keys for uploads from a text/template, e.g.
+//
+//	users/{{.UserID}}/{{.FileID}}-{{.Filename}}
+//	{{slice .ContentHash 0 2}}/{{.ContentHash}}
+//	{{.UploadedAt.Format "2006/01/02"}}/{{.FileID}}-{{.Filename}}
+type PathTemplate struct {
+	tmpl *template.Template
+}
+
+// ParsePathTemplate parses and validates a path template. An empty text uses
+// DefaultPathTemplate. The template is rendered with sample data to catch
+// unknown fields, unsafe results, and templates that would give two uploads
+// of the same filename the same key.
+func ParsePathTemplate(text string) (*PathTemplate, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultPathTemplate
+	}
+	tmpl, err := template.New("storage_path").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage path template: %w", err)
+	}
+	pt := &PathTemplate{tmpl: tmpl}
+
+	first := PathData{
+		UserID:      "user:sample",
+		FileID:      "0123456789abcdef",
+		Filename:    "report.pdf",
+		UploadedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ContentHash: strings.Repeat("ab", 32),
+	}
+	second := first
+	second.FileID = "fedcba9876543210"
+	second.UploadedAt = first.UploadedAt.Add(time.Second)
+	second.ContentHash = strings.Repeat("cd", 32)
+
+	a, err := pt.Execute(first)
+	if err != nil {
+		return nil, err
+	}
+	b, err := pt.Execute(second)
+	if err != nil {
+		return nil, err
+	}
+	if a == b {
+		return nil, fmt.Errorf("storage path template %q must use .FileID, .UploadedAt or .ContentHash so uploads do not overwrite each other", text)
+	}
+	return pt, nil
+}
+
+// Execute renders the storage key for an upload. The result is rejected with
+// ErrUnsafePath if it is absolute or contains a ".." element, so values such
+// as a crafted UserID cannot move a key into another directory.
+func (p *PathTemplate) Execute(data PathData) (string, error) {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("storage path template: %w", err)
+	}
+	rendered := sb.String()
+	if rendered == "" || path.IsAbs(rendered) || slices.Contains(strings.Split(rendered, "/"), "..") {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, rendered)
+	}
+	key := path.Clean(rendered)
+	if err := ValidatePath(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathTemplate(t *testing.T) {
+	uploadedAt := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	data := PathData{
+		UserID:      "user:abc",
+		FileID:      "00112233aabbccdd",
+		Filename:    "report.pdf",
+		UploadedAt:  uploadedAt,
+		ContentHash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+
+	cases := map[string]string{
+		"": fmt.Sprintf("users/user:abc/%d-report.pdf", uploadedAt.UnixNano()),
+		"{{slice .ContentHash 0 2}}/{{.ContentHash}}":                   "e3/e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		`{{.UploadedAt.Format "2006/01/02"}}/{{.FileID}}-{{.Filename}}`: "2025/03/14/00112233aabbccdd-report.pdf",
+		"files//{{.FileID}}": "files/00112233aabbccdd",
+	}
+	for text, want := range cases {
+		tmpl, err := ParsePathTemplate(text)
+		require.NoError(t, err, text)
+		got, err := tmpl.Execute(data)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, got, text)
+	}
+}
+
+func TestParsePathTemplate_Rejects(t *testing.T) {
+	for text, reason := range map[string]string{
+		"users/{{.UserID":                 "invalid storage path template",
+		"users/{{.Missing}}/{{.FileID}}":  "can't evaluate field Missing",
+		"/abs/{{.FileID}}":                "unsafe storage path",
+		"../{{.FileID}}":                  "unsafe storage path",
+		"users/{{.UserID}}/{{.Filename}}": "must use .FileID, .UploadedAt or .ContentHash",
+		"users/../../{{.FileID}}":         "unsafe storage path",
+	} {
+		_, err := ParsePathTemplate(text)
+		require.Error(t, err, text)
+		assert.Contains(t, err.Error(), reason, text)
+	}
+}
+
+func TestPathTemplate_ExecuteRejectsTraversalInData(t *testing.T) {
+	tmpl, err := ParsePathTemplate("{{.UserID}}/{{.FileID}}")
+	require.NoError(t, err)
+
+	_, err = tmpl.Execute(PathData{UserID: "../..", FileID: "x"})
+	assert.ErrorIs(t, err, ErrUnsafePath)
+
+	_, err = tmpl.Execute(PathData{UserID: "a/..", FileID: "x"})
+	assert.ErrorIs(t, err, ErrUnsafePath)
+}
@@ -39,8 +39,8 @@ func ConfigForTests(t *testing.T) config.Provider {
 		t.Setenv(key, value)
 	}
 
-	logging.New()
-
 	// 4. Now that the environment is set, create the config.
-	return config.New()
+	cfg := config.New()
+	logging.New(cfg)
+	return cfg
 }
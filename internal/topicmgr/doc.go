@@ -27,13 +27,17 @@
 // Module topics are defined by application modules:
 //
 //	var NewMessage = topicmgr.DefineModule(topicmgr.TopicConfig{
-//		Name:        "client.chat.message.new",
-//		Module:      "chat",
-//		Description: "A new chat message sent by a client",
-//		Pattern:     "client.chat.message.new",
-//		Example:     `{"action":"client.chat.message.new","payload":{"content":"Hello!"}}`,
+//		Name:               "client.chat.message.new",
+//		Module:             "chat",
+//		Description:        "A new chat message sent by a client",
+//		Pattern:            "client.chat.message.new",
+//		Example:            `{"action":"client.chat.message.new","payload":{"content":"Hello!"}}`,
+//		AllowClientPublish: true,
 //	})
 //
+// Topics are server-only by default: the WebSocket bridge rejects client
+// publishes unless the topic is registered with AllowClientPublish set.
+//
 // Topics are registered with the manager:
 //
 //	manager := topicmgr.Default()
@@ -42,9 +46,37 @@
 //		log.Fatal(err)
 //	}
 //
+// Modules registering several topics should use RegisterAll, which registers
+// all of them or none and reports every invalid or colliding topic at once:
+//
+//	err := manager.RegisterAll(NewMessage, MessageDeleted)
+//
+// Default is shared by the whole process. New creates an isolated manager,
+// and WithManager makes DefineFramework or DefineModule register the topic
+// with a specific one. In tests, topicmgrtest.New returns an isolated
+// manager seeded with topics and restores the global registry when the test
+// ends:
+//
+//	manager := topicmgrtest.New(t, NewMessage, MessageDeleted)
+//
 // Topics can be discovered and listed:
 //
 //	allTopics := manager.List()
 //	chatTopics := manager.ListByModule("chat")
 //	frameworkTopics := manager.ListFrameworkTopics()
+//
+// Topics may declare the topics they lead to with RelatedTopics. ExportGraph
+// writes these relationships as a Graphviz DOT diagram, and DanglingRelations
+// reports references to topics that are not registered:
+//
+//	err := manager.ExportGraph(os.Stdout)
+//
+// Topic names are case-sensitive in the registry and always lowercase;
+// ValidateTopicName rejects other forms with ErrNonCanonicalName. Lookups are
+// more forgiving: Get and the WebSocket bridge pass names through Normalize,
+// which trims whitespace, collapses repeated dots and, under the default
+// CaseFold policy, lowercases, so "Client.Chat.Message" resolves like
+// "client.chat.message". SetCasePolicy(CaseStrict) requires exact case:
+//
+//	manager.SetCasePolicy(topicmgr.CaseStrict)
 package topicmgr
\ No newline at end of file
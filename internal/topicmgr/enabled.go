@@ -0,0 +1,56 @@
+package topicmgr
+
+import "fmt"
+
+// enabler is implemented by topics whose enabled state can be toggled.
+type enabler interface {
+	setEnabled(enabled bool)
+}
+
+// Enabled reports whether the topic is switched on
+func (t *TypedTopic) Enabled() bool {
+	return !t.disabled.Load()
+}
+
+func (t *TypedTopic) setEnabled(enabled bool) {
+	t.disabled.Store(!enabled)
+}
+
+// SetEnabled switches the named topic on or off at runtime, as a kill-switch
+// for a noisy or buggy real-time feature. While a topic is disabled, the
+// WebSocket bridges refuse client publishes and subscriptions to it and
+// pubsub drops publishes to it. The state is held in memory: it applies to
+// this process only and is reset by a restart.
+func (m *Manager) SetEnabled(name string, enabled bool) error {
+	topic, exists := m.Get(name)
+	if !exists {
+		return &TopicError{
+			Type:    ErrorTopicNotFound,
+			Topic:   name,
+			Message: fmt.Sprintf("topic not found: %s", name),
+		}
+	}
+	e, ok := topic.(enabler)
+	if !ok {
+		return fmt.Errorf("topic %s can't be toggled", name)
+	}
+	e.setEnabled(enabled)
+	return nil
+}
+
+// IsEnabled reports whether the named topic is switched on. Unregistered
+// topics have no switch and are always enabled.
+func (m *Manager) IsEnabled(name string) bool {
+	topic, exists := m.Get(name)
+	return !exists || topic.Enabled()
+}
+
+// SetEnabled switches a topic in the default manager on or off
+func SetEnabled(name string, enabled bool) error {
+	return Default().SetEnabled(name, enabled)
+}
+
+// IsEnabled reports whether a topic in the default manager is switched on
+func IsEnabled(name string) bool {
+	return Default().IsEnabled(name)
+}
@@ -0,0 +1,38 @@
+package topicmgr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SetEnabled(t *testing.T) {
+	m := NewManager()
+	topic := testModuleTopic("test.flagged")
+	require.NoError(t, m.Register(topic))
+	assert.True(t, topic.Enabled(), "topics start enabled")
+
+	require.NoError(t, m.SetEnabled("test.flagged", false))
+	assert.False(t, topic.Enabled())
+	assert.False(t, m.IsEnabled("test.flagged"))
+
+	require.NoError(t, m.SetEnabled("test.flagged", true))
+	assert.True(t, m.IsEnabled("test.flagged"))
+
+	var topicErr *TopicError
+	require.True(t, errors.As(m.SetEnabled("test.missing", false), &topicErr))
+	assert.Equal(t, ErrorTopicNotFound, topicErr.Type)
+	assert.True(t, m.IsEnabled("test.missing"), "unregistered topics have no switch")
+}
+
+func TestDefine_Disabled(t *testing.T) {
+	topic := DefineModule(TopicConfig{
+		Name:     "test.dark_launch",
+		Module:   "test",
+		Pattern:  "test.dark_launch",
+		Disabled: true,
+	})
+	assert.False(t, topic.Enabled())
+}
@@ -0,0 +1,106 @@
+package topicmgr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FrameworkRegistrar collects the framework topics of the core packages so
+// they can be registered together at startup, in the order they were added:
+//
+//	err := topicmgr.NewFrameworkRegistrar().
+//		Add("websocket", websocket.FrameworkTopics()...).
+//		Add("presence", presence.FrameworkTopics()...).
+//		Register(topicmgr.Default())
+//
+// Registration is idempotent: topics a manager already holds are skipped, so
+// running it again, or after a package registered its own topics, is not an
+// error. A different topic under a taken name is, as are two sources
+// defining the same name and topics that aren't framework topics.
+type FrameworkRegistrar struct {
+	sources []frameworkSource
+}
+
+type frameworkSource struct {
+	name   string
+	topics []Topic
+}
+
+// contribution records which source first contributed a topic name.
+type contribution struct {
+	source string
+	topic  Topic
+}
+
+// NewFrameworkRegistrar returns an empty FrameworkRegistrar.
+func NewFrameworkRegistrar() *FrameworkRegistrar {
+	return &FrameworkRegistrar{}
+}
+
+// Add contributes the framework topics of the package named source, which
+// appears in collision errors.
+func (r *FrameworkRegistrar) Add(source string, topics ...Topic) *FrameworkRegistrar {
+	r.sources = append(r.sources, frameworkSource{name: source, topics: topics})
+	return r
+}
+
+// Topics returns every contributed topic in the order it was added.
+func (r *FrameworkRegistrar) Topics() []Topic {
+	var topics []Topic
+	for _, src := range r.sources {
+		topics = append(topics, src.topics...)
+	}
+	return topics
+}
+
+// Register registers the contributed topics with m atomically: if any topic
+// is invalid or collides, nothing is registered and the returned error lists
+// every problem.
+func (r *FrameworkRegistrar) Register(m *Manager) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	var pending []Topic
+	owners := make(map[string]contribution)
+	for _, src := range r.sources {
+		for _, topic := range src.topics {
+			if topic == nil {
+				errs = append(errs, fmt.Errorf("%s: cannot register nil topic", src.name))
+				continue
+			}
+			name := topic.Name()
+			if topic.Scope() != ScopeFramework {
+				errs = append(errs, &TopicError{
+					Type:    ErrorValidationFailed,
+					Topic:   name,
+					Module:  topic.Module(),
+					Message: fmt.Sprintf("%s contributed a %s topic, not a framework topic%s", src.name, topic.Scope(), definedAtSuffix(topic)),
+				})
+				continue
+			}
+			if first, ok := owners[name]; ok {
+				if first.topic != topic {
+					errs = append(errs, &TopicError{
+						Type:    ErrorDuplicateRegistration,
+						Topic:   name,
+						Message: fmt.Sprintf("framework topic %s is contributed by both %s and %s%s", name, first.source, src.name, collisionLocations(topic, first.topic)),
+					})
+				}
+				continue
+			}
+			owners[name] = contribution{source: src.name, topic: topic}
+			if existing, ok := m.registry.Get(name); ok && existing == topic {
+				continue // already registered
+			}
+			pending = append(pending, topic)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return m.registry.registerAll(pending, m.validator.ValidateDefinition)
+}
@@ -0,0 +1,74 @@
+package topicmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrameworkTopic(name string) Topic {
+	return DefineFramework(TopicConfig{
+		Name:        name,
+		Description: "Test topic " + name,
+		Pattern:     name,
+	})
+}
+
+func TestFrameworkRegistrar(t *testing.T) {
+	// Two core packages, each contributing its own framework topics.
+	wsTopics := []Topic{testFrameworkTopic("ws.test.broadcast"), testFrameworkTopic("ws.test.direct")}
+	presenceTopics := []Topic{testFrameworkTopic("presence.test.online")}
+	newRegistrar := func() *FrameworkRegistrar {
+		return NewFrameworkRegistrar().
+			Add("websocket", wsTopics...).
+			Add("presence", presenceTopics...)
+	}
+
+	t.Run("registers topics from every package", func(t *testing.T) {
+		m := NewManager()
+		require.NoError(t, newRegistrar().Register(m))
+
+		assert.Len(t, m.List(), 3)
+		for _, topic := range newRegistrar().Topics() {
+			registered, ok := m.Get(topic.Name())
+			require.True(t, ok, topic.Name())
+			assert.Same(t, topic, registered)
+		}
+	})
+
+	t.Run("re-running is idempotent", func(t *testing.T) {
+		m := NewManager()
+		require.NoError(t, m.Register(presenceTopics[0]), "a package registered its own topic first")
+		require.NoError(t, newRegistrar().Register(m))
+		require.NoError(t, newRegistrar().Register(m))
+
+		assert.Len(t, m.List(), 3)
+	})
+
+	t.Run("same name from two packages collides", func(t *testing.T) {
+		m := NewManager()
+		err := newRegistrar().Add("email", testFrameworkTopic("ws.test.direct")).Register(m)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contributed by both websocket and email")
+		assert.Empty(t, m.List(), "nothing is registered")
+	})
+
+	t.Run("different topic under a registered name collides", func(t *testing.T) {
+		m := NewManager()
+		require.NoError(t, m.Register(testFrameworkTopic("ws.test.broadcast")))
+
+		err := newRegistrar().Register(m)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already registered")
+		assert.Len(t, m.List(), 1)
+	})
+
+	t.Run("module topics are rejected", func(t *testing.T) {
+		m := NewManager()
+		err := newRegistrar().Add("chat", testModuleTopic("test.message")).Register(m)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a framework topic")
+		assert.Empty(t, m.List())
+	})
+}
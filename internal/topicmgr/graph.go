@@ -0,0 +1,117 @@
+package topicmgr
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// TopicRelation is a declared edge from a topic to one of its RelatedTopics
+type TopicRelation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DanglingRelations returns relations whose target topic is not registered
+func (m *Manager) DanglingRelations() []TopicRelation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var dangling []TopicRelation
+	for _, rel := range relations(m.registry.List()) {
+		if _, ok := m.registry.Get(rel.To); !ok {
+			dangling = append(dangling, rel)
+		}
+	}
+	return dangling
+}
+
+// ExportGraph writes a Graphviz DOT diagram of all registered topics, grouped
+// into one cluster per module (framework topics share a cluster), with an
+// edge for every declared related topic. Related topics that are not
+// registered are drawn dashed and logged as warnings.
+func (m *Manager) ExportGraph(w io.Writer) error {
+	m.mu.RLock()
+	topics := m.registry.List()
+	m.mu.RUnlock()
+
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name() < topics[j].Name() })
+
+	groups := make(map[string][]Topic)
+	for _, topic := range topics {
+		group := topic.Module()
+		if topic.Scope() == ScopeFramework || group == "" {
+			group = string(ScopeFramework)
+		}
+		groups[group] = append(groups[group], topic)
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	registered := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		registered[topic.Name()] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph topics {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, style=rounded];\n")
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n\tsubgraph %q {\n", "cluster_"+name)
+		fmt.Fprintf(&b, "\t\tlabel=%q;\n", name)
+		for _, topic := range groups[name] {
+			attrs := ""
+			if topic.AllowClientPublish() {
+				attrs = " [peripheries=2]"
+			}
+			fmt.Fprintf(&b, "\t\t%q%s;\n", topic.Name(), attrs)
+		}
+		b.WriteString("\t}\n")
+	}
+
+	edges := relations(topics)
+	if len(edges) > 0 {
+		b.WriteString("\n")
+	}
+	missing := make(map[string]bool)
+	for _, rel := range edges {
+		if registered[rel.To] {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", rel.From, rel.To)
+			continue
+		}
+		slog.Warn("Topic declares an unregistered related topic", "topic", rel.From, "related", rel.To)
+		fmt.Fprintf(&b, "\t%q -> %q [style=dashed, color=red];\n", rel.From, rel.To)
+		if !missing[rel.To] {
+			missing[rel.To] = true
+			fmt.Fprintf(&b, "\t%q [style=dashed, color=red];\n", rel.To)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// relations lists the declared edges of topics in a stable order
+func relations(topics []Topic) []TopicRelation {
+	var rels []TopicRelation
+	for _, topic := range topics {
+		for _, related := range topic.RelatedTopics() {
+			rels = append(rels, TopicRelation{From: topic.Name(), To: related})
+		}
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		if rels[i].From != rels[j].From {
+			return rels[i].From < rels[j].From
+		}
+		return rels[i].To < rels[j].To
+	})
+	return rels
+}
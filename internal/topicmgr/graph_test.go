@@ -0,0 +1,49 @@
+package topicmgr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ExportGraph(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.RegisterAll(
+		DefineFramework(TopicConfig{
+			Name:        "ws.broadcast",
+			Description: "Framework broadcast",
+			Pattern:     "ws.broadcast",
+		}),
+		DefineModule(TopicConfig{
+			Name:               "game.action",
+			Module:             "game",
+			Description:        "Client action",
+			Pattern:            "game.action",
+			AllowClientPublish: true,
+			RelatedTopics:      []string{"game.state", "game.missing"},
+		}),
+		DefineModule(TopicConfig{
+			Name:          "game.state",
+			Module:        "game",
+			Description:   "State update",
+			Pattern:       "game.state",
+			RelatedTopics: []string{"ws.broadcast"},
+		}),
+	))
+
+	var out strings.Builder
+	require.NoError(t, m.ExportGraph(&out))
+	dot := out.String()
+
+	assert.True(t, strings.HasPrefix(dot, "digraph topics {"))
+	assert.Contains(t, dot, `subgraph "cluster_framework"`)
+	assert.Contains(t, dot, `subgraph "cluster_game"`)
+	assert.Contains(t, dot, `"game.action" [peripheries=2];`)
+	assert.Contains(t, dot, `"game.action" -> "game.state";`)
+	assert.Contains(t, dot, `"game.state" -> "ws.broadcast";`)
+	assert.Contains(t, dot, `"game.action" -> "game.missing" [style=dashed, color=red];`)
+
+	assert.Equal(t, []TopicRelation{{From: "game.action", To: "game.missing"}}, m.DanglingRelations())
+}
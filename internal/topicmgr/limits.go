@@ -0,0 +1,62 @@
+package topicmgr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNameTooLong is returned for topic names longer than NameLimits.MaxLength.
+	ErrNameTooLong = errors.New("topic name too long")
+	// ErrTooManySegments is returned for topic names with more dot-separated
+	// segments than NameLimits.MaxSegments.
+	ErrTooManySegments = errors.New("topic name has too many segments")
+)
+
+// NameLimits bounds the size of topic names. A zero field disables that limit.
+type NameLimits struct {
+	// MaxLength is the maximum name length in bytes.
+	MaxLength int
+	// MaxSegments is the maximum number of dot-separated segments.
+	MaxSegments int
+}
+
+// DefaultNameLimits are the limits a new Manager starts with.
+var DefaultNameLimits = NameLimits{MaxLength: 100, MaxSegments: 8}
+
+// Check reports which limit name exceeds, wrapping ErrNameTooLong or
+// ErrTooManySegments.
+func (l NameLimits) Check(name string) error {
+	if l.MaxLength > 0 && len(name) > l.MaxLength {
+		return fmt.Errorf("%w: %d characters exceeds the maximum of %d", ErrNameTooLong, len(name), l.MaxLength)
+	}
+	if l.MaxSegments > 0 {
+		if segments := strings.Count(name, ".") + 1; segments > l.MaxSegments {
+			return fmt.Errorf("%w: %d segments exceeds the maximum of %d", ErrTooManySegments, segments, l.MaxSegments)
+		}
+	}
+	return nil
+}
+
+// SetNameLimits changes the limits enforced by ValidateTopicName, topic
+// registration and CheckNameLimits.
+func (m *Manager) SetNameLimits(limits NameLimits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validator.limits = limits
+}
+
+// NameLimits returns the limits currently enforced on topic names.
+func (m *Manager) NameLimits() NameLimits {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.validator.limits
+}
+
+// CheckNameLimits checks only the size limits, not the naming convention. It
+// suits names built at runtime, such as the topic.channel names WebSocket
+// clients subscribe to.
+func (m *Manager) CheckNameLimits(name string) error {
+	return m.NameLimits().Check(name)
+}
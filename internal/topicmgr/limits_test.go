@@ -0,0 +1,37 @@
+package topicmgr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTopicName_Limits(t *testing.T) {
+	m := NewManager()
+	m.SetNameLimits(NameLimits{MaxLength: 20, MaxSegments: 3})
+
+	atLength := "a" + strings.Repeat("b", 19)
+	require.Len(t, atLength, 20)
+	assert.NoError(t, m.ValidateTopicName(atLength))
+	err := m.ValidateTopicName(atLength + "c")
+	assert.ErrorIs(t, err, ErrNameTooLong)
+	assert.Contains(t, err.Error(), "21 characters exceeds the maximum of 20")
+
+	assert.NoError(t, m.ValidateTopicName("a.b.c"))
+	err = m.ValidateTopicName("a.b.c.d")
+	assert.ErrorIs(t, err, ErrTooManySegments)
+	assert.Contains(t, err.Error(), "4 segments exceeds the maximum of 3")
+
+	m.SetNameLimits(NameLimits{})
+	assert.NoError(t, m.ValidateTopicName(strings.Repeat("a.", 200)+"a"), "zero disables the limits")
+}
+
+func TestManager_DefaultNameLimits(t *testing.T) {
+	m := NewManager()
+	assert.Equal(t, DefaultNameLimits, m.NameLimits())
+	assert.ErrorIs(t, m.ValidateTopicName(strings.Repeat("a", 101)), ErrNameTooLong)
+	assert.ErrorIs(t, m.CheckNameLimits("a.b.c.d.e.f.g.h.i"), ErrTooManySegments)
+	assert.NoError(t, m.CheckNameLimits("Chat.Room-1"), "CheckNameLimits ignores the naming convention")
+}
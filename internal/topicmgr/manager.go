@@ -2,6 +2,9 @@ package topicmgr
 
 import (
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -74,13 +77,36 @@ func (m *Manager) GetStartTime() time.Time {
 	return m.startTime
 }
 
-// DefineFramework creates a new typed topic for framework services
-func DefineFramework(config TopicConfig) Topic {
-	// Ensure scope is set to framework
-	config.Scope = ScopeFramework
-	config.Module = "" // Framework topics don't have a module
+// New creates an isolated manager with an empty registry. Unlike Default,
+// nothing registered with it is visible elsewhere, so tests and tools can
+// register topics without affecting each other.
+func New() *Manager {
+	return NewManager()
+}
+
+// DefineOption configures DefineFramework and DefineModule.
+type DefineOption func(*defineOptions)
 
-	return &TypedTopic{
+type defineOptions struct {
+	manager *Manager
+}
+
+// WithManager registers the defined topic with m. Like MustRegister, it
+// panics if the topic is invalid or its name is taken, since topics are
+// usually defined at package level.
+func WithManager(m *Manager) DefineOption {
+	return func(o *defineOptions) {
+		o.manager = m
+	}
+}
+
+// define builds the topic for config and registers it as opts request.
+func define(config TopicConfig, opts []DefineOption) Topic {
+	var o defineOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	topic := &TypedTopic{
 		name:        config.Name,
 		module:      config.Module,
 		description: config.Description,
@@ -88,23 +114,61 @@ func DefineFramework(config TopicConfig) Topic {
 		example:     config.Example,
 		metadata:    config.Metadata,
 		scope:       config.Scope,
+
+		allowClientPublish: config.AllowClientPublish,
+		relatedTopics:      append([]string(nil), config.RelatedTopics...),
+		definedAt:          config.DefinedAt,
 	}
+	topic.disabled.Store(config.Disabled)
+	if o.manager != nil {
+		o.manager.MustRegister(topic)
+	}
+	return topic
+}
+
+// DefineFramework creates a new typed topic for framework services
+func DefineFramework(config TopicConfig, opts ...DefineOption) Topic {
+	// Ensure scope is set to framework
+	config.Scope = ScopeFramework
+	config.Module = "" // Framework topics don't have a module
+	config.DefinedAt = definedAt(config)
+
+	return define(config, opts)
 }
 
 // DefineModule creates a new typed topic for modules
-func DefineModule(config TopicConfig) Topic {
+func DefineModule(config TopicConfig, opts ...DefineOption) Topic {
 	// Ensure scope is set to module
 	config.Scope = ScopeModule
+	config.DefinedAt = definedAt(config)
 
-	return &TypedTopic{
-		name:        config.Name,
-		module:      config.Module,
-		description: config.Description,
-		pattern:     config.Pattern,
-		example:     config.Example,
-		metadata:    config.Metadata,
-		scope:       config.Scope,
+	return define(config, opts)
+}
+
+// definedAt returns the configured source location, or the location of the
+// code that called DefineFramework or DefineModule.
+func definedAt(config TopicConfig) string {
+	if config.DefinedAt != "" {
+		return config.DefinedAt
+	}
+	return CallerLocation(2)
+}
+
+// CallerLocation returns the "file.go:line" of the function skip frames above
+// its caller, as runtime.Caller counts them, or "" if unavailable. The path
+// keeps its last three elements (e.g. "chat/topics/topics.go"), enough to
+// tell modules' identically named files apart. Helpers that wrap
+// DefineModule use it to fill in TopicConfig.DefinedAt.
+func CallerLocation(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(file), "/")
+	if len(parts) > 3 {
+		parts = parts[len(parts)-3:]
 	}
+	return fmt.Sprintf("%s:%d", strings.Join(parts, "/"), line)
 }
 
 // Register adds a topic to the central registry
@@ -112,26 +176,40 @@ func (m *Manager) Register(topic Topic) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Validate the topic before registration
-	if err := m.validator.ValidateDefinition(topic); err != nil {
-		return &TopicError{
-			Type:    ErrorValidationFailed,
-			Topic:   topic.Name(),
-			Module:  topic.Module(),
-			Message: "topic validation failed",
-			Cause:   err,
+	return m.registerNamespacedUnsafe([]Topic{topic}, func() error {
+		// Validate the topic before registration
+		if err := m.validator.ValidateDefinition(topic); err != nil {
+			return &TopicError{
+				Type:    ErrorValidationFailed,
+				Topic:   topic.Name(),
+				Module:  topic.Module(),
+				Message: "topic validation failed" + definedAtSuffix(topic),
+				Cause:   err,
+			}
 		}
-	}
 
-	return m.registry.Register(topic)
+		return m.registry.Register(topic)
+	})
 }
 
-// Get retrieves a topic by name (for backward compatibility)
+// RegisterAll validates all topics and registers them atomically: if any topic
+// is invalid or collides with a registered topic (or another topic in the
+// batch), nothing is registered and the returned error lists every problem.
+func (m *Manager) RegisterAll(topics ...Topic) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.registerNamespacedUnsafe(topics, func() error {
+		return m.registry.registerAll(topics, m.validator.ValidateDefinition)
+	})
+}
+
+// Get retrieves a topic by name, normalized according to the CasePolicy
 func (m *Manager) Get(name string) (Topic, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.registry.Get(name)
+	return m.registry.Get(NormalizeName(name, m.validator.casePolicy))
 }
 
 // List returns all registered topics
@@ -171,7 +249,9 @@ func (m *Manager) Validate(topic Topic, context string) error {
 	return m.validator.ValidateUsage(topic, context)
 }
 
-// ValidateTopicName checks if a topic name is valid without creating a topic
+// ValidateTopicName checks if a topic name is valid without creating a topic.
+// Names must already be in canonical form: lowercase, without surrounding
+// whitespace or empty segments. Other forms fail with ErrNonCanonicalName.
 func (m *Manager) ValidateTopicName(name string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -185,19 +265,21 @@ func (m *Manager) ValidateAndRegister(topic Topic) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// First validate
-	if err := m.validator.ValidateDefinition(topic); err != nil {
-		return &TopicError{
-			Type:    ErrorValidationFailed,
-			Topic:   topic.Name(),
-			Module:  topic.Module(),
-			Message: "topic validation failed during registration",
-			Cause:   err,
+	return m.registerNamespacedUnsafe([]Topic{topic}, func() error {
+		// First validate
+		if err := m.validator.ValidateDefinition(topic); err != nil {
+			return &TopicError{
+				Type:    ErrorValidationFailed,
+				Topic:   topic.Name(),
+				Module:  topic.Module(),
+				Message: "topic validation failed during registration" + definedAtSuffix(topic),
+				Cause:   err,
+			}
 		}
-	}
 
-	// Then register
-	return m.registry.Register(topic)
+		// Then register
+		return m.registry.Register(topic)
+	})
 }
 
 // ValidateConfiguration validates a topic configuration before creating a topic
@@ -230,12 +312,22 @@ func (m *Manager) CheckTopicExists(name string) bool {
 	return exists
 }
 
+// AllowsClientPublish reports whether WebSocket clients may publish to the
+// named topic. Unregistered topics are never client-publishable.
+func (m *Manager) AllowsClientPublish(name string) bool {
+	topic, exists := m.Get(name)
+	if !exists {
+		return false
+	}
+	return topic.AllowClientPublish()
+}
+
 // ValidateTopicAccess checks if a topic can be accessed from a given context
 func (m *Manager) ValidateTopicAccess(topicName, module, context string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	topic, exists := m.registry.Get(topicName)
+	topic, exists := m.registry.Get(NormalizeName(topicName, m.validator.casePolicy))
 	if !exists {
 		return &TopicError{
 			Type:    ErrorTopicNotFound,
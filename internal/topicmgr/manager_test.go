@@ -0,0 +1,98 @@
+package topicmgr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testModuleTopic(name string) Topic {
+	return DefineModule(TopicConfig{
+		Name:        name,
+		Module:      "test",
+		Description: "Test topic " + name,
+		Pattern:     name,
+	})
+}
+
+func TestManager_RegisterAll(t *testing.T) {
+	t.Run("registers every topic", func(t *testing.T) {
+		m := NewManager()
+		require.NoError(t, m.RegisterAll(testModuleTopic("test.one"), testModuleTopic("test.two")))
+		assert.True(t, m.CheckTopicExists("test.one"))
+		assert.True(t, m.CheckTopicExists("test.two"))
+	})
+
+	t.Run("is all-or-nothing and reports every problem", func(t *testing.T) {
+		m := NewManager()
+		require.NoError(t, m.Register(testModuleTopic("test.existing")))
+
+		err := m.RegisterAll(
+			testModuleTopic("test.fresh"),
+			testModuleTopic("test.existing"), // collides with the registry
+			testModuleTopic("Bad Name"),      // fails validation
+			testModuleTopic("test.twice"),
+			testModuleTopic("test.twice"), // collides within the batch
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "topic already registered: test.existing")
+		assert.Contains(t, err.Error(), "topic validation failed: Bad Name")
+		assert.Contains(t, err.Error(), "topic listed more than once: test.twice")
+
+		var topicErr *TopicError
+		assert.True(t, errors.As(err, &topicErr))
+
+		assert.False(t, m.CheckTopicExists("test.fresh"), "valid topics must not be registered when the batch fails")
+		assert.False(t, m.CheckTopicExists("test.twice"))
+	})
+}
+
+func TestDefine_RecordsSourceLocation(t *testing.T) {
+	first := DefineModule(TopicConfig{Name: "test.located", Module: "test", Description: "First", Pattern: "test.located"})
+	assert.Regexp(t, `^internal/topicmgr/manager_test\.go:\d+$`, first.DefinedAt())
+
+	second := DefineModule(TopicConfig{Name: "test.located", Module: "test", Description: "Second", Pattern: "test.located", DefinedAt: "chat/topics/topics.go:7"})
+	assert.Equal(t, "chat/topics/topics.go:7", second.DefinedAt(), "an explicit location wins")
+
+	m := NewManager()
+	require.NoError(t, m.Register(first))
+	err := m.Register(second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defined at chat/topics/topics.go:7, previously defined at "+first.DefinedAt())
+
+	err = m.Register(DefineModule(TopicConfig{Name: "test.undocumented", Module: "test", Pattern: "test.undocumented"}))
+	require.Error(t, err)
+	assert.Regexp(t, `topic validation failed \(defined at internal/topicmgr/manager_test\.go:\d+\)`, err.Error())
+}
+
+func TestNew_IsIsolated(t *testing.T) {
+	first, second := New(), New()
+	require.NoError(t, first.Register(testModuleTopic("isolated.topic")))
+
+	assert.True(t, first.CheckTopicExists("isolated.topic"))
+	assert.False(t, second.CheckTopicExists("isolated.topic"), "managers share no topics")
+	assert.False(t, Default().CheckTopicExists("isolated.topic"), "the global manager is untouched")
+	assert.Empty(t, second.List())
+	assert.Empty(t, second.ListModules())
+}
+
+func TestDefine_WithManager(t *testing.T) {
+	m := New()
+	module := DefineModule(TopicConfig{Name: "orders.created", Module: "orders", Description: "An order was placed", Pattern: "orders.created"}, WithManager(m))
+	framework := DefineFramework(TopicConfig{Name: "server.ready", Description: "The server is ready", Pattern: "server.ready"}, WithManager(m))
+
+	got, ok := m.Get("orders.created")
+	require.True(t, ok)
+	assert.Same(t, module, got)
+	assert.Contains(t, got.DefinedAt(), "manager_test.go", "the caller is still recorded")
+	_, ok = m.Get("server.ready")
+	assert.True(t, ok)
+	assert.Equal(t, ScopeFramework, framework.Scope())
+	assert.False(t, Default().CheckTopicExists("orders.created"))
+
+	assert.Panics(t, func() {
+		DefineModule(TopicConfig{Name: "orders.created", Module: "orders", Description: "Again", Pattern: "orders.created"}, WithManager(m))
+	}, "a name taken in the target manager panics like MustRegister")
+}
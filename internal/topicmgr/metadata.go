@@ -0,0 +1,49 @@
+package topicmgr
+
+// Metadata keys with a shared meaning across topics. Read them with the
+// matching Topic accessors rather than asserting on Metadata() directly.
+const (
+	// MetaPayloadFields lists the fields a topic's payload carries.
+	MetaPayloadFields = "payload_fields"
+	// MetaEventType classifies the event, e.g. "lifecycle" or "state_change".
+	MetaEventType = "event_type"
+	// MetaSource names who publishes the topic, e.g. "client" or "server".
+	MetaSource = "source"
+)
+
+// PayloadFields returns the payload_fields metadata, or nil if it is absent
+// or not a list of strings. A []any from decoded JSON is accepted as long as
+// every element is a string.
+func (t *TypedTopic) PayloadFields() []string {
+	switch v := t.metadata[MetaPayloadFields].(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []any:
+		fields := make([]string, 0, len(v))
+		for _, f := range v {
+			s, ok := f.(string)
+			if !ok {
+				return nil
+			}
+			fields = append(fields, s)
+		}
+		return fields
+	}
+	return nil
+}
+
+// EventType returns the event_type metadata, or "" if it is absent or not a
+// string.
+func (t *TypedTopic) EventType() string {
+	return t.metadataString(MetaEventType)
+}
+
+// Source returns the source metadata, or "" if it is absent or not a string.
+func (t *TypedTopic) Source() string {
+	return t.metadataString(MetaSource)
+}
+
+func (t *TypedTopic) metadataString(key string) string {
+	s, _ := t.metadata[key].(string)
+	return s
+}
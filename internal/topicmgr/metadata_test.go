@@ -0,0 +1,53 @@
+package topicmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicMetadataAccessors(t *testing.T) {
+	define := func(metadata map[string]interface{}) Topic {
+		return DefineModule(TopicConfig{Name: "test.meta", Module: "test", Metadata: metadata})
+	}
+
+	t.Run("present", func(t *testing.T) {
+		topic := define(map[string]interface{}{
+			MetaPayloadFields: []string{"userID", "timestamp"},
+			MetaEventType:     "lifecycle",
+			MetaSource:        "client",
+		})
+		assert.Equal(t, []string{"userID", "timestamp"}, topic.PayloadFields())
+		assert.Equal(t, "lifecycle", topic.EventType())
+		assert.Equal(t, "client", topic.Source())
+
+		topic.PayloadFields()[0] = "changed"
+		assert.Equal(t, "userID", topic.PayloadFields()[0], "callers get a copy")
+	})
+
+	t.Run("decoded JSON list", func(t *testing.T) {
+		topic := define(map[string]interface{}{MetaPayloadFields: []any{"a", "b"}})
+		assert.Equal(t, []string{"a", "b"}, topic.PayloadFields())
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		topic := define(nil)
+		assert.Nil(t, topic.PayloadFields())
+		assert.Empty(t, topic.EventType())
+		assert.Empty(t, topic.Source())
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		topic := define(map[string]interface{}{
+			MetaPayloadFields: "userID,timestamp",
+			MetaEventType:     42,
+			MetaSource:        []string{"client"},
+		})
+		assert.Nil(t, topic.PayloadFields())
+		assert.Empty(t, topic.EventType())
+		assert.Empty(t, topic.Source())
+
+		mixed := define(map[string]interface{}{MetaPayloadFields: []any{"a", 1}})
+		assert.Nil(t, mixed.PayloadFields())
+	})
+}
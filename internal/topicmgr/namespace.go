@@ -0,0 +1,92 @@
+package topicmgr
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// SetAutoNamespace turns auto-namespacing on or off. When on, registering a
+// module topic whose name doesn't start with "<module>." renames it to
+// "<module>.<name>", and its pattern likewise, so a chat topic defined as
+// "message.sent" is registered, published and subscribed to as
+// "chat.message.sent". Each rename is logged so authors can update their
+// names. Framework topics are never renamed.
+//
+// Topics are usually defined at package level, before the manager is
+// configured, so the rename happens at registration rather than in
+// DefineModule. Code that keeps a Topic sees the new name once it is
+// registered; a topic that fails to register keeps its name.
+func (m *Manager) SetAutoNamespace(on bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validator.autoNamespace = on
+}
+
+// AutoNamespace reports whether auto-namespacing is on.
+func (m *Manager) AutoNamespace() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.validator.autoNamespace
+}
+
+// NamespacedName returns name prefixed with "<module>." unless it already
+// starts with it. Names without a module are returned as they are.
+func NamespacedName(module, name string) string {
+	if module == "" || name == module || strings.HasPrefix(name, module+".") {
+		return name
+	}
+	return module + "." + name
+}
+
+// namespaceRename records a topic renamed by auto-namespacing, so the rename
+// can be undone.
+type namespaceRename struct {
+	topic         *TypedTopic
+	name, pattern string
+}
+
+// registerNamespacedUnsafe auto-namespaces topics if it is on, then calls
+// register. If register fails, the topics get their names back, so a topic
+// that fails validation or collides keeps the name its caller gave it. The
+// caller must hold m.mu.
+func (m *Manager) registerNamespacedUnsafe(topics []Topic, register func() error) error {
+	var renamed []namespaceRename
+	for _, topic := range topics {
+		if r, ok := m.autoNamespaceUnsafe(topic); ok {
+			renamed = append(renamed, r)
+		}
+	}
+	if err := register(); err != nil {
+		for _, r := range renamed {
+			r.topic.name, r.topic.pattern = r.name, r.pattern
+		}
+		return err
+	}
+	for _, r := range renamed {
+		slog.Info("Auto-namespaced module topic", "module", r.topic.module, "name", r.name, "topic", r.topic.name, "defined_at", r.topic.definedAt)
+	}
+	return nil
+}
+
+// autoNamespaceUnsafe applies auto-namespacing to topic if it is on and
+// reports its previous name if it was renamed. Only TypedTopics, as returned
+// by DefineModule, can be renamed. The caller must hold m.mu.
+func (m *Manager) autoNamespaceUnsafe(topic Topic) (namespaceRename, bool) {
+	if !m.validator.autoNamespace {
+		return namespaceRename{}, false
+	}
+	t, ok := topic.(*TypedTopic)
+	if !ok || t == nil || t.scope != ScopeModule || t.module == "" {
+		return namespaceRename{}, false
+	}
+	name := NamespacedName(t.module, t.name)
+	if name == t.name {
+		return namespaceRename{}, false
+	}
+	previous := namespaceRename{topic: t, name: t.name, pattern: t.pattern}
+	t.name = name
+	if t.pattern != "" {
+		t.pattern = NamespacedName(t.module, t.pattern)
+	}
+	return previous, true
+}
@@ -0,0 +1,120 @@
+package topicmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacedName(t *testing.T) {
+	assert.Equal(t, "chat.message.sent", NamespacedName("chat", "message.sent"))
+	assert.Equal(t, "chat.message.sent", NamespacedName("chat", "chat.message.sent"))
+	assert.Equal(t, "chat.chatroom.join", NamespacedName("chat", "chatroom.join"), "a shared prefix is not the module segment")
+	assert.Equal(t, "system.ready", NamespacedName("", "system.ready"))
+}
+
+func TestManager_AutoNamespace(t *testing.T) {
+	newManager := func() *Manager {
+		m := NewManager()
+		m.SetAutoNamespace(true)
+		return m
+	}
+
+	t.Run("unprefixed module topic is renamed", func(t *testing.T) {
+		m := newManager()
+		topic := DefineModule(TopicConfig{
+			Name:        "message.sent",
+			Module:      "chat",
+			Description: "Chat message sent",
+			Pattern:     "message.sent",
+		})
+		require.NoError(t, m.Register(topic))
+
+		assert.Equal(t, "chat.message.sent", topic.Name())
+		assert.Equal(t, "chat.message.sent", topic.Pattern())
+		_, ok := m.Get("chat.message.sent")
+		assert.True(t, ok)
+		_, ok = m.Get("message.sent")
+		assert.False(t, ok)
+	})
+
+	t.Run("already prefixed module topic is unchanged", func(t *testing.T) {
+		m := newManager()
+		topic := DefineModule(TopicConfig{
+			Name:        "chat.message.sent",
+			Module:      "chat",
+			Description: "Chat message sent",
+			Pattern:     "chat.message.sent",
+		})
+		require.NoError(t, m.RegisterAll(topic))
+
+		assert.Equal(t, "chat.message.sent", topic.Name())
+		_, ok := m.Get("chat.message.sent")
+		assert.True(t, ok)
+	})
+
+	t.Run("topic that fails to register keeps its name", func(t *testing.T) {
+		m := newManager()
+		require.NoError(t, m.Register(DefineModule(TopicConfig{
+			Name:        "chat.message.sent",
+			Module:      "chat",
+			Description: "Chat message sent",
+			Pattern:     "chat.message.sent",
+		})))
+
+		duplicate := DefineModule(TopicConfig{
+			Name:        "message.sent",
+			Module:      "chat",
+			Description: "Chat message sent again",
+			Pattern:     "message.sent",
+		})
+		require.Error(t, m.Register(duplicate))
+		assert.Equal(t, "message.sent", duplicate.Name())
+		assert.Equal(t, "message.sent", duplicate.Pattern())
+
+		invalid := DefineModule(TopicConfig{Name: "message.edited", Module: "chat", Pattern: "message.edited"})
+		require.Error(t, m.RegisterAll(invalid), "topics need a description")
+		assert.Equal(t, "message.edited", invalid.Name())
+		require.Error(t, m.ValidateAndRegister(invalid))
+		assert.Equal(t, "message.edited", invalid.Name())
+	})
+
+	t.Run("framework topics are exempt", func(t *testing.T) {
+		m := newManager()
+		topic := DefineFramework(TopicConfig{
+			Name:        "presence.user.online",
+			Description: "User came online",
+			Pattern:     "presence.user.online",
+		})
+		require.NoError(t, m.Register(topic))
+
+		assert.Equal(t, "presence.user.online", topic.Name())
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		m := NewManager()
+		assert.False(t, m.AutoNamespace())
+		topic := DefineModule(TopicConfig{
+			Name:        "message.sent",
+			Module:      "chat",
+			Description: "Chat message sent",
+			Pattern:     "message.sent",
+		})
+		require.NoError(t, m.Register(topic))
+
+		assert.Equal(t, "message.sent", topic.Name())
+	})
+
+	t.Run("WithManager renames on definition", func(t *testing.T) {
+		m := newManager()
+		topic := DefineModule(TopicConfig{
+			Name:        "room.joined",
+			Module:      "chat",
+			Description: "User joined a room",
+			Pattern:     "room.joined",
+		}, WithManager(m))
+
+		assert.Equal(t, "chat.room.joined", topic.Name())
+	})
+}
@@ -0,0 +1,75 @@
+package topicmgr
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNonCanonicalName is returned for topic names that differ from their
+// normalized form, e.g. "Chat.Message" or "chat..message".
+var ErrNonCanonicalName = errors.New("topic name is not in canonical form")
+
+// CasePolicy decides whether topic lookups ignore case.
+type CasePolicy int
+
+const (
+	// CaseFold lowercases names before lookup, so "Client.Chat.Message"
+	// resolves to "client.chat.message". It is the default.
+	CaseFold CasePolicy = iota
+	// CaseStrict keeps the case of names, so only exact matches resolve.
+	CaseStrict
+)
+
+// String returns the policy's name.
+func (p CasePolicy) String() string {
+	if p == CaseStrict {
+		return "strict"
+	}
+	return "fold"
+}
+
+// NormalizeName trims surrounding whitespace and dots, collapses repeated dot
+// separators and, under CaseFold, lowercases name.
+func NormalizeName(name string, policy CasePolicy) string {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, "..") || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		segments := strings.Split(name, ".")
+		kept := segments[:0]
+		for _, s := range segments {
+			if s != "" {
+				kept = append(kept, s)
+			}
+		}
+		name = strings.Join(kept, ".")
+	}
+	if policy == CaseFold {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// SetCasePolicy changes how Get, Normalize and the other lookups treat case.
+// Registered names are always lowercase; the policy only affects lookups.
+func (m *Manager) SetCasePolicy(policy CasePolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validator.casePolicy = policy
+}
+
+// CasePolicy returns the policy lookups currently use.
+func (m *Manager) CasePolicy() CasePolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.validator.casePolicy
+}
+
+// Normalize returns name as the manager looks it up, following its
+// CasePolicy.
+func (m *Manager) Normalize(name string) string {
+	return NormalizeName(name, m.CasePolicy())
+}
+
+// Normalize normalizes name using the default manager.
+func Normalize(name string) string {
+	return Default().Normalize(name)
+}
@@ -0,0 +1,66 @@
+package topicmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		fold   string
+		strict string
+	}{
+		{"canonical name is unchanged", "client.chat.message", "client.chat.message", "client.chat.message"},
+		{"mixed case", "Client.Chat.Message", "client.chat.message", "Client.Chat.Message"},
+		{"surrounding whitespace", "  chat.message\t", "chat.message", "chat.message"},
+		{"repeated separators", "chat..message...sent", "chat.message.sent", "chat.message.sent"},
+		{"leading and trailing dots", ".chat.message.", "chat.message", "chat.message"},
+		{"all at once", " .Chat..Message. ", "chat.message", "Chat.Message"},
+		{"empty", "", "", ""},
+		{"only separators", " ... ", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fold, NormalizeName(tt.in, CaseFold))
+			assert.Equal(t, tt.strict, NormalizeName(tt.in, CaseStrict))
+		})
+	}
+}
+
+func TestManager_GetNormalizesNames(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.Register(testModuleTopic("client.chat.message")))
+
+	for _, name := range []string{"client.chat.message", "Client.Chat.Message", " client..chat.message "} {
+		topic, ok := m.Get(name)
+		require.True(t, ok, "lookup of %q", name)
+		assert.Equal(t, "client.chat.message", topic.Name())
+	}
+	assert.NoError(t, m.ValidateTopicAccess("CLIENT.CHAT.MESSAGE", "test", "test"))
+
+	m.SetCasePolicy(CaseStrict)
+	assert.Equal(t, CaseStrict, m.CasePolicy())
+	_, ok := m.Get("Client.Chat.Message")
+	assert.False(t, ok, "strict policy requires exact case")
+	_, ok = m.Get(" client..chat.message ")
+	assert.True(t, ok, "strict policy still trims and collapses separators")
+}
+
+func TestValidateTopicName_RequiresCanonicalForm(t *testing.T) {
+	m := NewManager()
+
+	assert.NoError(t, m.ValidateTopicName("chat.message"))
+	for _, name := range []string{"Chat.Message", "chat..message", " chat.message", "chat.message."} {
+		err := m.ValidateTopicName(name)
+		assert.ErrorIs(t, err, ErrNonCanonicalName, name)
+		assert.Contains(t, err.Error(), `use "chat.message"`)
+	}
+
+	err := m.Register(testModuleTopic("Chat.Message"))
+	assert.ErrorIs(t, err, ErrNonCanonicalName, "registration points at the canonical name")
+	assert.False(t, m.CheckTopicExists("chat.message"))
+}
@@ -0,0 +1,79 @@
+package topicmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PayloadError describes a payload that does not match its topic's
+// payload_fields.
+type PayloadError struct {
+	Topic string
+	// NotObject is set when the payload is not a JSON object at all.
+	NotObject bool
+	// Unknown lists the payload's fields the topic does not declare.
+	Unknown []string
+	// Missing lists the declared fields the payload lacks. Only
+	// CheckPayloadStrict reports them.
+	Missing []string
+}
+
+func (e *PayloadError) Error() string {
+	if e.NotObject {
+		return fmt.Sprintf("payload for %s is not a JSON object", e.Topic)
+	}
+	var problems []string
+	if len(e.Unknown) > 0 {
+		problems = append(problems, "unknown fields "+strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing fields "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("payload for %s has %s", e.Topic, strings.Join(problems, " and "))
+}
+
+// CheckPayload validates payload against the topic's payload_fields. Topics
+// without payload_fields accept any payload. Otherwise the payload must be a
+// JSON object carrying only declared fields; declared fields may be absent,
+// since payloads commonly omit empty values. It returns a *PayloadError.
+func CheckPayload(topic Topic, payload []byte) error {
+	return checkPayload(topic, payload, false)
+}
+
+// CheckPayloadStrict is CheckPayload, but every declared field must also be
+// present.
+func CheckPayloadStrict(topic Topic, payload []byte) error {
+	return checkPayload(topic, payload, true)
+}
+
+func checkPayload(topic Topic, payload []byte, requireAll bool) error {
+	declared := topic.PayloadFields()
+	if len(declared) == 0 {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil || fields == nil {
+		return &PayloadError{Topic: topic.Name(), NotObject: true}
+	}
+
+	perr := &PayloadError{Topic: topic.Name()}
+	for name := range fields {
+		if !slices.Contains(declared, name) {
+			perr.Unknown = append(perr.Unknown, name)
+		}
+	}
+	slices.Sort(perr.Unknown)
+	if requireAll {
+		for _, name := range declared {
+			if _, ok := fields[name]; !ok {
+				perr.Missing = append(perr.Missing, name)
+			}
+		}
+	}
+	if len(perr.Unknown) > 0 || len(perr.Missing) > 0 {
+		return perr
+	}
+	return nil
+}
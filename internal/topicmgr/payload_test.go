@@ -0,0 +1,35 @@
+package topicmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPayload(t *testing.T) {
+	topic := DefineModule(TopicConfig{
+		Name:     "scores.update",
+		Module:   "scores",
+		Pattern:  "scores.update",
+		Metadata: map[string]interface{}{MetaPayloadFields: []string{"home", "away"}},
+	})
+
+	assert.NoError(t, CheckPayload(topic, []byte(`{"home":1,"away":2}`)))
+	assert.NoError(t, CheckPayload(topic, []byte(`{"home":1}`)), "declared fields may be omitted")
+
+	var perr *PayloadError
+	require.ErrorAs(t, CheckPayload(topic, []byte(`{"home":1,"score":3,"bonus":1}`)), &perr)
+	assert.Equal(t, []string{"bonus", "score"}, perr.Unknown)
+	assert.EqualError(t, perr, "payload for scores.update has unknown fields bonus, score")
+
+	require.ErrorAs(t, CheckPayload(topic, []byte(`[1,2]`)), &perr)
+	assert.True(t, perr.NotObject)
+	assert.Error(t, CheckPayload(topic, []byte(`null`)))
+
+	require.ErrorAs(t, CheckPayloadStrict(topic, []byte(`{"home":1}`)), &perr)
+	assert.Equal(t, []string{"away"}, perr.Missing)
+
+	untyped := DefineModule(TopicConfig{Name: "scores.raw", Module: "scores", Pattern: "scores.raw"})
+	assert.NoError(t, CheckPayload(untyped, []byte(`anything`)), "topics without payload_fields accept any payload")
+}
@@ -1,7 +1,9 @@
 package topicmgr
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 )
@@ -19,6 +21,21 @@ func NewRegistry() *Registry {
 	}
 }
 
+// Snapshot records the registry's entries and returns a function that puts
+// them back, undoing any registrations made in between. topicmgrtest uses it
+// to isolate tests that register with the default manager.
+func (r *Registry) Snapshot() (restore func()) {
+	r.mu.RLock()
+	saved := maps.Clone(r.entries)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.entries = maps.Clone(saved)
+	}
+}
+
 // Register adds a topic to the registry
 func (r *Registry) Register(topic Topic) error {
 	r.mu.Lock()
@@ -41,12 +58,12 @@ func (r *Registry) Register(topic Topic) error {
 	}
 
 	// Check for duplicate registration
-	if _, exists := r.entries[name]; exists {
+	if existing, exists := r.entries[name]; exists {
 		return &TopicError{
 			Type:    ErrorDuplicateRegistration,
 			Topic:   name,
 			Module:  topic.Module(),
-			Message: fmt.Sprintf("topic already registered: %s", name),
+			Message: fmt.Sprintf("topic already registered: %s%s", name, collisionLocations(topic, existing.Topic)),
 		}
 	}
 
@@ -62,6 +79,91 @@ func (r *Registry) Register(topic Topic) error {
 	return nil
 }
 
+// registerAll adds every topic to the registry, or none of them. Each topic is
+// checked with validate and for collisions, both within the batch and with
+// already registered topics. All problems are reported in a single joined error.
+func (r *Registry) registerAll(topics []Topic, validate func(Topic) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	seen := make(map[string]Topic, len(topics))
+	for _, topic := range topics {
+		if topic == nil {
+			errs = append(errs, &TopicError{
+				Type:    ErrorValidationFailed,
+				Message: "cannot register nil topic",
+			})
+			continue
+		}
+
+		name := topic.Name()
+		if err := validate(topic); err != nil {
+			errs = append(errs, &TopicError{
+				Type:    ErrorValidationFailed,
+				Topic:   name,
+				Module:  topic.Module(),
+				Message: fmt.Sprintf("topic validation failed: %s%s", name, definedAtSuffix(topic)),
+				Cause:   err,
+			})
+		}
+
+		if existing, exists := r.entries[name]; exists {
+			errs = append(errs, &TopicError{
+				Type:    ErrorDuplicateRegistration,
+				Topic:   name,
+				Module:  topic.Module(),
+				Message: fmt.Sprintf("topic already registered: %s%s", name, collisionLocations(topic, existing.Topic)),
+			})
+		} else if first, ok := seen[name]; ok {
+			errs = append(errs, &TopicError{
+				Type:    ErrorDuplicateRegistration,
+				Topic:   name,
+				Module:  topic.Module(),
+				Message: fmt.Sprintf("topic listed more than once: %s%s", name, collisionLocations(topic, first)),
+			})
+		} else {
+			seen[name] = topic
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	now := time.Now()
+	for _, topic := range topics {
+		r.entries[topic.Name()] = &RegistryEntry{
+			Topic:        topic,
+			RegisteredAt: now,
+			Module:       topic.Module(),
+		}
+	}
+	return nil
+}
+
+// definedAtSuffix formats where topic was defined for an error message.
+func definedAtSuffix(topic Topic) string {
+	if loc := topic.DefinedAt(); loc != "" {
+		return " (defined at " + loc + ")"
+	}
+	return ""
+}
+
+// collisionLocations formats where both sides of a name collision were
+// defined for an error message.
+func collisionLocations(topic, existing Topic) string {
+	loc, prev := topic.DefinedAt(), existing.DefinedAt()
+	switch {
+	case loc != "" && prev != "":
+		return fmt.Sprintf(" (defined at %s, previously defined at %s)", loc, prev)
+	case prev != "":
+		return " (previously defined at " + prev + ")"
+	default:
+		return definedAtSuffix(topic)
+	}
+}
+
 // Get retrieves a topic by name
 func (r *Registry) Get(name string) (Topic, bool) {
 	r.mu.RLock()
@@ -0,0 +1,25 @@
+// Package topicmgrtest provides topic managers for tests. It is kept out of
+// topicmgr so the testing package is not linked into binaries.
+package topicmgrtest
+
+import (
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// New returns an isolated manager with topics registered, for tests that
+// exercise registration. Code under test may still reach the global manager
+// through topicmgr.Default or the package-level functions, so its registry
+// is restored to its current contents when the test ends.
+func New(t testing.TB, topics ...topicmgr.Topic) *topicmgr.Manager {
+	t.Helper()
+
+	t.Cleanup(topicmgr.Default().GetRegistry().Snapshot())
+
+	m := topicmgr.New()
+	if err := m.RegisterAll(topics...); err != nil {
+		t.Fatalf("topicmgrtest.New: %v", err)
+	}
+	return m
+}
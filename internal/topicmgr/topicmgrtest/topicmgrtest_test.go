@@ -0,0 +1,38 @@
+package topicmgrtest
+
+import (
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moduleTopic defines a topic without registering it globally.
+func moduleTopic(name string) topicmgr.Topic {
+	return topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:        name,
+		Module:      "test",
+		Description: "Test topic " + name,
+		Pattern:     name,
+	}, topicmgr.WithManager(topicmgr.New()))
+}
+
+func TestNew(t *testing.T) {
+	seeded := moduleTopic("seeded.topic")
+	before := topicmgr.Default().Count()
+
+	t.Run("seeds an isolated manager", func(t *testing.T) {
+		m := New(t, seeded)
+		assert.True(t, m.CheckTopicExists("seeded.topic"))
+		assert.Equal(t, 1, m.Count())
+
+		// Code under test that registers globally.
+		require.NoError(t, topicmgr.Register(moduleTopic("leaked.topic")))
+		assert.True(t, topicmgr.Default().CheckTopicExists("leaked.topic"))
+	})
+
+	assert.False(t, topicmgr.Default().CheckTopicExists("leaked.topic"), "the global registry is restored after the test")
+	assert.False(t, topicmgr.Default().CheckTopicExists("seeded.topic"))
+	assert.Equal(t, before, topicmgr.Default().Count())
+}
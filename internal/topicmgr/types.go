@@ -1,6 +1,7 @@
 package topicmgr
 
 import (
+	"sync/atomic"
 	"time"
 )
 
@@ -24,8 +25,30 @@ type Topic interface {
 	// Metadata returns additional topic information
 	Metadata() map[string]interface{}
 
+	// PayloadFields, EventType and Source read the common metadata keys
+	// (see MetaPayloadFields), returning zero values when a key is absent
+	// or holds the wrong type
+	PayloadFields() []string
+	EventType() string
+	Source() string
+
 	// Scope returns whether this is a framework or module topic
 	Scope() TopicScope
+
+	// AllowClientPublish reports whether WebSocket clients may publish to this topic
+	AllowClientPublish() bool
+
+	// RelatedTopics returns the names of topics this topic leads to, e.g. the
+	// state update published in response to a client action
+	RelatedTopics() []string
+
+	// DefinedAt returns the source location ("chat/topics/topics.go:18")
+	// where the topic was defined, or an empty string if unknown
+	DefinedAt() string
+
+	// Enabled reports whether the topic is switched on. Publishes to a
+	// disabled topic are dropped; see Manager.SetEnabled
+	Enabled() bool
 }
 
 // TypedTopic provides compile-time safety for topic usage
@@ -37,6 +60,15 @@ type TypedTopic struct {
 	example     string
 	metadata    map[string]interface{}
 	scope       TopicScope
+
+	allowClientPublish bool
+
+	relatedTopics []string
+
+	definedAt string
+
+	// disabled is toggled at runtime by Manager.SetEnabled
+	disabled atomic.Bool
 }
 
 // Compile-time interface compliance check
@@ -51,6 +83,23 @@ type TopicConfig struct {
 	Pattern     string                 `json:"pattern"`     // Routing pattern
 	Example     string                 `json:"example"`     // Usage example
 	Metadata    map[string]interface{} `json:"metadata"`    // Additional data
+
+	// AllowClientPublish permits WebSocket clients to publish to this topic.
+	// Defaults to false: only the server may emit the topic.
+	AllowClientPublish bool `json:"allow_client_publish"`
+
+	// RelatedTopics names topics that are published as a consequence of this
+	// one. It is documentation only and drives the topic graph export.
+	RelatedTopics []string `json:"related_topics,omitempty"`
+
+	// DefinedAt overrides the source location recorded for the topic. It is
+	// for helpers that define topics on their caller's behalf; leave it empty
+	// to record the caller of DefineFramework or DefineModule.
+	DefinedAt string `json:"defined_at,omitempty"`
+
+	// Disabled defines the topic switched off, e.g. for a feature that is
+	// turned on at runtime with Manager.SetEnabled.
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // TopicScope defines whether a topic belongs to framework or module level
@@ -147,6 +196,21 @@ func (t *TypedTopic) Scope() TopicScope {
 	return t.scope
 }
 
+// AllowClientPublish reports whether WebSocket clients may publish to this topic
+func (t *TypedTopic) AllowClientPublish() bool {
+	return t.allowClientPublish
+}
+
+// RelatedTopics returns the names of topics this topic leads to
+func (t *TypedTopic) RelatedTopics() []string {
+	return append([]string(nil), t.relatedTopics...)
+}
+
+// DefinedAt returns the source location where the topic was defined
+func (t *TypedTopic) DefinedAt() string {
+	return t.definedAt
+}
+
 // String returns the topic name for easy debugging
 func (t *TypedTopic) String() string {
 	return t.name
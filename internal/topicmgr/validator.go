@@ -10,6 +10,12 @@ import (
 type Validator struct {
 	// namePattern defines valid topic name patterns
 	namePattern *regexp.Regexp
+	// limits bounds name length and segment count
+	limits NameLimits
+	// casePolicy decides whether lookups ignore case
+	casePolicy CasePolicy
+	// autoNamespace prefixes module topic names with their module on registration
+	autoNamespace bool
 }
 
 // NewValidator creates a new topic validator
@@ -20,6 +26,7 @@ func NewValidator() *Validator {
 
 	return &Validator{
 		namePattern: namePattern,
+		limits:      DefaultNameLimits,
 	}
 }
 
@@ -44,6 +51,17 @@ func (v *Validator) ValidateDefinition(topic Topic) error {
 		return fmt.Errorf("topic pattern cannot be empty")
 	}
 
+	// Validate related topic names; whether they exist is only known once all
+	// topics are registered, see Manager.DanglingRelations
+	for _, related := range topic.RelatedTopics() {
+		if related == topic.Name() {
+			return fmt.Errorf("topic cannot list itself as a related topic")
+		}
+		if err := v.validateName(related); err != nil {
+			return fmt.Errorf("invalid related topic %q: %w", related, err)
+		}
+	}
+
 	// Validate scope-specific rules
 	switch topic.Scope() {
 	case ScopeFramework:
@@ -89,8 +107,14 @@ func (v *Validator) validateName(name string) error {
 		return fmt.Errorf("name cannot be empty")
 	}
 
-	if len(name) > 100 {
-		return fmt.Errorf("name too long (max 100 characters)")
+	if err := v.limits.Check(name); err != nil {
+		return err
+	}
+
+	// Names are case-sensitive in the registry and always lowercase; point
+	// callers at the canonical form rather than rejecting it outright
+	if canonical := NormalizeName(name, CaseFold); canonical != name && v.namePattern.MatchString(canonical) {
+		return fmt.Errorf("%w: use %q", ErrNonCanonicalName, canonical)
 	}
 
 	if !v.namePattern.MatchString(name) {
@@ -122,6 +146,7 @@ func (v *Validator) validateFrameworkTopic(topic Topic) error {
 		"presence.",  // Presence service topics
 		"auth.",      // Authentication topics
 		"server.",    // Server lifecycle topics
+		"email.",     // Outbound email queue topics
 	}
 
 	hasValidPrefix := false
@@ -1,8 +1,10 @@
 package view
 
 import (
+	"slices"
+
 	"github.com/labstack/echo/v4"
-	"github.com/nfrund/goby/web/src/templates/partials"
+	"github.com/nfrund/goby/internal/domain"
 
 	// FIX: Import the session package to use the session.Get() helper
 	"github.com/labstack/echo-contrib/session"
@@ -12,18 +14,55 @@ import (
 const (
 	// Using a generic name for the session store, assuming middleware setup
 	flashSessionName = "flash-session"
-	flashSuccessKey  = "flash_success"
-	flashErrorKey    = "flash_error"
-	flashFormEmail   = "form_email"
+	// flashKeyPrefix is followed by the level, e.g. "flash_success".
+	flashKeyPrefix = "flash_"
+	flashFormEmail = "form_email"
+)
+
+// FlashLevel is the severity of a flash message. It is the same type as the
+// WebSocket toast level, domain.NoticeLevel, so a level means the same thing
+// whether the message is shown on the next page load or pushed to an open
+// page as a toast.
+type FlashLevel = domain.NoticeLevel
+
+// The flash message levels, in the order they are displayed.
+const (
+	FlashInfo    = domain.NoticeInfo
+	FlashSuccess = domain.NoticeSuccess
+	FlashWarning = domain.NoticeWarning
+	FlashError   = domain.NoticeError
 )
 
+// flashLevels lists every level in display order.
+var flashLevels = []FlashLevel{FlashInfo, FlashSuccess, FlashWarning, FlashError}
+
+// FlashMessage is a single message shown to the user on the next rendered page.
+type FlashMessage struct {
+	Level FlashLevel
+	Text  string
+}
+
+// FlashMessages is the list of messages passed to layouts for rendering.
+type FlashMessages []FlashMessage
+
+// Texts returns the text of each message with the given level.
+func (f FlashMessages) Texts(level FlashLevel) []string {
+	var texts []string
+	for _, m := range f {
+		if m.Level == level {
+			texts = append(texts, m.Text)
+		}
+	}
+	return texts
+}
+
 // FlashData holds all flash messages for the view.
 type FlashData struct {
-	Messages  partials.FlashData
+	Messages  FlashMessages
 	FormEmail string
 }
 
-// GetFlashData retrieves all flash messages (success, error, and form data) from the session.
+// GetFlashData retrieves all flash messages (by level, and form data) from the session.
 // It returns a single FlashData struct containing all retrieved messages.
 // CRITICAL: This function consumes the flash messages (they are deleted after retrieval).
 func GetFlashData(c echo.Context) FlashData {
@@ -37,24 +76,16 @@ func GetFlashData(c echo.Context) FlashData {
 	data := FlashData{}
 	needsSave := false
 
-	// 2. Retrieve Success messages and cast them to the correct type (string).
+	// 2. Retrieve the messages for each level and cast them to the correct type (string).
 	// sess.Flashes() retrieves the messages and simultaneously clears them from the session map.
-	successVal := sess.Flashes(flashSuccessKey)
-	if len(successVal) > 0 {
-		for _, val := range successVal {
-			if s, ok := val.(string); ok {
-				data.Messages.Success = append(data.Messages.Success, s)
-			}
+	for _, level := range flashLevels {
+		vals := sess.Flashes(flashKeyPrefix + string(level))
+		if len(vals) == 0 {
+			continue
 		}
-		needsSave = true
-	}
-
-	// 3. Retrieve Error messages and cast them to the correct type (string).
-	errorVal := sess.Flashes(flashErrorKey)
-	if len(errorVal) > 0 {
-		for _, val := range errorVal {
+		for _, val := range vals {
 			if s, ok := val.(string); ok {
-				data.Messages.Error = append(data.Messages.Error, s)
+				data.Messages = append(data.Messages, FlashMessage{Level: level, Text: s})
 			}
 		}
 		needsSave = true
@@ -79,28 +110,32 @@ func GetFlashData(c echo.Context) FlashData {
 	return data
 }
 
-// SetFlashSuccess adds a success message to the session for the next request.
-func SetFlashSuccess(c echo.Context, message string) {
-	// FIX: Use session.Get()
+// AddFlash adds a message with the given level to the session for the next
+// request. Unknown levels are stored as FlashInfo. Call SaveFlashes once all
+// messages have been added.
+func AddFlash(c echo.Context, level FlashLevel, text string) {
+	if !slices.Contains(flashLevels, level) {
+		c.Logger().Warnf("Unknown flash level %q, using %q", level, FlashInfo)
+		level = FlashInfo
+	}
+
 	sess, err := session.Get(flashSessionName, c)
 	if err != nil {
-		c.Logger().Errorf("Failed to get session for flash success: %v", err)
+		c.Logger().Errorf("Failed to get session for flash %s: %v", level, err)
 		return
 	}
 
-	sess.AddFlash(message, flashSuccessKey)
+	sess.AddFlash(text, flashKeyPrefix+string(level))
+}
+
+// SetFlashSuccess adds a success message to the session for the next request.
+func SetFlashSuccess(c echo.Context, message string) {
+	AddFlash(c, FlashSuccess, message)
 }
 
 // SetFlashError adds an error message to the session for the next request.
 func SetFlashError(c echo.Context, message string) {
-	// FIX: Use session.Get()
-	sess, err := session.Get(flashSessionName, c)
-	if err != nil {
-		c.Logger().Errorf("Failed to get session for flash error: %v", err)
-		return
-	}
-
-	sess.AddFlash(message, flashErrorKey)
+	AddFlash(c, FlashError, message)
 }
 
 // SetFlashFormData adds form-related data to the session without saving it.
@@ -1,6 +1,8 @@
 package view_test
 
 import (
+	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +11,7 @@ import (
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/view"
+	"github.com/nfrund/goby/web/src/templates/partials"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,13 +51,11 @@ func TestFlashMessages(t *testing.T) {
 		flashes := view.GetFlashData(c)
 
 		// Assert against the struct fields
-		assert.NotEmpty(t, flashes.Messages.Success)
-		assert.Equal(t, "It worked!", flashes.Messages.Success[0])
-		assert.Empty(t, flashes.Messages.Error)
+		assert.Equal(t, view.FlashMessages{{Level: view.FlashSuccess, Text: "It worked!"}}, flashes.Messages)
 
 		// Get flashes again to ensure they are cleared
 		flashesAfterRead := view.GetFlashData(c)
-		assert.Empty(t, flashesAfterRead.Messages.Success, "Flashes should be cleared after being read")
+		assert.Empty(t, flashesAfterRead.Messages, "Flashes should be cleared after being read")
 	})
 
 	t.Run("Set and Get Error Flash", func(t *testing.T) {
@@ -69,16 +70,48 @@ func TestFlashMessages(t *testing.T) {
 		flashes := view.GetFlashData(c)
 
 		// Assert against the struct fields
-		assert.NotEmpty(t, flashes.Messages.Error)
-		assert.Equal(t, "It failed!", flashes.Messages.Error[0])
-		assert.Empty(t, flashes.Messages.Success)
+		assert.Equal(t, []string{"It failed!"}, flashes.Messages.Texts(view.FlashError))
+		assert.Empty(t, flashes.Messages.Texts(view.FlashSuccess))
 	})
 
 	t.Run("GetFlashes with no flashes set", func(t *testing.T) {
 		c, _ := setupTestContext()
 
 		flashes := view.GetFlashData(c)
-		assert.Empty(t, flashes.Messages.Success, "Success flashes should be empty")
-		assert.Empty(t, flashes.Messages.Error, "Error flashes should be empty")
+		assert.Empty(t, flashes.Messages, "Flashes should be empty")
 	})
+
+	t.Run("AddFlash keeps levels and clears on read", func(t *testing.T) {
+		c, _ := setupTestContext()
+
+		view.AddFlash(c, view.FlashError, "Upload failed")
+		view.AddFlash(c, view.FlashInfo, "Maintenance tonight")
+		view.AddFlash(c, view.FlashWarning, "Quota at 90%")
+		view.AddFlash(c, view.FlashLevel("shout"), "Unknown level")
+		require.NoError(t, view.SaveFlashes(c))
+
+		flashes := view.GetFlashData(c)
+		assert.Equal(t, view.FlashMessages{
+			{Level: view.FlashInfo, Text: "Maintenance tonight"},
+			{Level: view.FlashInfo, Text: "Unknown level"},
+			{Level: view.FlashWarning, Text: "Quota at 90%"},
+			{Level: view.FlashError, Text: "Upload failed"},
+		}, flashes.Messages, "messages are grouped by level in display order")
+
+		assert.Empty(t, view.GetFlashData(c).Messages, "Flashes should be cleared after being read")
+	})
+}
+
+func TestFlashMessagesPartial(t *testing.T) {
+	var buf bytes.Buffer
+	err := partials.FlashMessages(view.FlashMessages{
+		{Level: view.FlashWarning, Text: "Quota at 90%"},
+		{Level: view.FlashError, Text: "<b>failed</b>"},
+	}).Render(context.Background(), &buf)
+	require.NoError(t, err)
+
+	html := buf.String()
+	assert.Contains(t, html, "alert-warning")
+	assert.Contains(t, html, "alert-error")
+	assert.Contains(t, html, "&lt;b&gt;failed&lt;/b&gt;", "text is escaped")
 }
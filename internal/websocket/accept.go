@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"fmt"
+
+	"github.com/coder/websocket"
+)
+
+// Compression selects whether and how a bridge negotiates permessage-deflate
+// with clients.
+type Compression string
+
+const (
+	// CompressionDisabled never compresses. It is the default.
+	CompressionDisabled Compression = "disabled"
+	// CompressionNoContextTakeover compresses each message on its own with a
+	// pooled compressor, so idle connections hold no compression state.
+	CompressionNoContextTakeover Compression = "no_context_takeover"
+	// CompressionContextTakeover keeps a compressor and its 32 KiB window per
+	// connection, which compresses small, repetitive messages far better at
+	// the cost of that memory on every connection.
+	CompressionContextTakeover Compression = "context_takeover"
+)
+
+// Bounds for the largest message a client may send.
+const (
+	defaultReadLimit = maxMessageSize
+	maxReadLimit     = 1 << 20
+)
+
+// mode maps c to the library's compression mode; the empty string is
+// CompressionDisabled.
+func (c Compression) mode() (websocket.CompressionMode, error) {
+	switch c {
+	case "", CompressionDisabled:
+		return websocket.CompressionDisabled, nil
+	case CompressionNoContextTakeover:
+		return websocket.CompressionNoContextTakeover, nil
+	case CompressionContextTakeover:
+		return websocket.CompressionContextTakeover, nil
+	default:
+		return websocket.CompressionDisabled, fmt.Errorf("compression %q must be %q, %q or %q",
+			string(c), CompressionDisabled, CompressionNoContextTakeover, CompressionContextTakeover)
+	}
+}
+
+// acceptSettings are the per-connection options a bridge applies when it
+// upgrades a request.
+type acceptSettings struct {
+	compression          websocket.CompressionMode
+	compressionThreshold int
+	readLimit            int64
+}
+
+// newAcceptSettings resolves deps' accept options, using the defaults for
+// unset or invalid values.
+func newAcceptSettings(deps BridgeDependencies) acceptSettings {
+	s := acceptSettings{readLimit: defaultReadLimit}
+	if mode, err := deps.Compression.mode(); err == nil {
+		s.compression = mode
+	}
+	if deps.CompressionThreshold > 0 {
+		s.compressionThreshold = deps.CompressionThreshold
+	}
+	if deps.ReadLimit > 0 && deps.ReadLimit <= maxReadLimit {
+		s.readLimit = deps.ReadLimit
+	}
+	return s
+}
+
+// validateAccept reports invalid accept options in deps.
+func validateAccept(deps BridgeDependencies) []error {
+	var errs []error
+	if _, err := deps.Compression.mode(); err != nil {
+		errs = append(errs, err)
+	}
+	if deps.CompressionThreshold < 0 {
+		errs = append(errs, fmt.Errorf("compression threshold %d must not be negative", deps.CompressionThreshold))
+	}
+	if deps.ReadLimit < 0 || deps.ReadLimit > maxReadLimit {
+		errs = append(errs, fmt.Errorf("read limit %d must be between 0 and %d", deps.ReadLimit, maxReadLimit))
+	}
+	return errs
+}
+
+// options returns the AcceptOptions for a new connection. A zero threshold
+// leaves the library default: 512 bytes without context takeover and 128
+// bytes with it.
+func (s acceptSettings) options() *websocket.AcceptOptions {
+	return &websocket.AcceptOptions{
+		// In production, you should verify the origin of the request against a list of
+		// allowed origins to prevent cross-site WebSocket hijacking.
+		InsecureSkipVerify:   true, // TODO: Replace with a proper origin check in production.
+		CompressionMode:      s.compression,
+		CompressionThreshold: s.compressionThreshold,
+	}
+}
@@ -0,0 +1,76 @@
+package websocket_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ws "github.com/nfrund/goby/internal/websocket"
+)
+
+func TestBridgeDependencies_ValidateAcceptOptions(t *testing.T) {
+	assert.NoError(t, ws.BridgeDependencies{
+		Compression:          ws.CompressionContextTakeover,
+		CompressionThreshold: 256,
+		ReadLimit:            4096,
+	}.Validate())
+
+	err := ws.BridgeDependencies{Compression: "gzip", CompressionThreshold: -1, ReadLimit: 2 << 20}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `compression "gzip" must be`)
+	assert.Contains(t, err.Error(), "compression threshold -1 must not be negative")
+	assert.Contains(t, err.Error(), "read limit 2097152 must be between 0 and 1048576")
+}
+
+func TestBridge_AcceptOptions(t *testing.T) {
+	serve := func(t *testing.T, deps ws.BridgeDependencies) string {
+		ps := newMockPubSub()
+		deps.Publisher, deps.Subscriber, deps.ReadyTopic = ps, ps, newMockTopic("ws.ready")
+		bridge := ws.NewBridge("html", deps)
+		e := echo.New()
+		addAuthMiddleware(e)
+		e.GET("/ws/html", bridge.Handler())
+		server := httptest.NewServer(e)
+		t.Cleanup(server.Close)
+		return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/html"
+	}
+	dialOpts := &websocket.DialOptions{CompressionMode: websocket.CompressionNoContextTakeover}
+
+	t.Run("compression is off by default", func(t *testing.T) {
+		conn, resp, err := websocket.Dial(context.Background(), serve(t, ws.BridgeDependencies{}), dialOpts)
+		require.NoError(t, err)
+		defer conn.CloseNow()
+		assert.Empty(t, resp.Header.Get("Sec-WebSocket-Extensions"))
+	})
+
+	t.Run("negotiates configured compression", func(t *testing.T) {
+		url := serve(t, ws.BridgeDependencies{Compression: ws.CompressionNoContextTakeover})
+		conn, resp, err := websocket.Dial(context.Background(), url, dialOpts)
+		require.NoError(t, err)
+		defer conn.CloseNow()
+		assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	})
+
+	t.Run("closes connections that exceed the read limit", func(t *testing.T) {
+		conn, _, err := websocket.Dial(context.Background(), serve(t, ws.BridgeDependencies{ReadLimit: 64}), nil)
+		require.NoError(t, err)
+		defer conn.CloseNow()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(strings.Repeat("x", 65))))
+		for {
+			if _, _, err = conn.Read(ctx); err != nil {
+				break
+			}
+		}
+		assert.Equal(t, websocket.StatusMessageTooBig, websocket.CloseStatus(err))
+	})
+}
@@ -3,16 +3,19 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/coder/websocket"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/logging"
 	"github.com/nfrund/goby/internal/middleware"
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/topicmgr"
@@ -28,12 +31,23 @@ const (
 )
 
 const (
-	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 512
 )
 
+// Defaults and limits for the per-client outbound queue and write deadline.
+const (
+	defaultSendBufferSize = 256
+	maxSendBufferSize     = 65536
+	defaultWriteTimeout   = 10 * time.Second
+	minWriteTimeout       = time.Second
+
+	// defaultMaxSubscriptions is the number of topics a client may be
+	// subscribed to at once when no limit is configured.
+	defaultMaxSubscriptions = 100
+)
+
 // Bridge handles WebSocket connections for a specific endpoint ("html" or "data").
 type Bridge struct {
 	endpoint     string
@@ -41,11 +55,38 @@ type Bridge struct {
 	subscriber   pubsub.Subscriber
 	topicManager *topicmgr.Manager
 	readyTopic   topicmgr.Topic
+	goneTopic    topicmgr.Topic
 	clients      *ClientManager
 	topics       *topicManager
 	whitelist    *clientWhitelist
-	cancel       context.CancelFunc
-	wg           sync.WaitGroup
+	subscribable *topicFilter
+	history      *messageHistory
+	enableCBOR   bool
+	allowGuests  bool
+	metrics      *bridgeMetrics
+	newClientID  ClientIDGenerator
+	clientIDs    *clientIDRegistry
+	userIDOf     UserIDFunc
+	accept       acceptSettings
+	channels     channelRegistry
+	connLimit    *ConnectionLimiter
+	deliveries   pubsub.DedupStore
+
+	clientRateLimit float64
+	clientRateBurst int
+	sendBufferSize  int
+	writeTimeout    time.Duration
+	fanOutWorkers   int
+	gzipAll         bool
+	gzipMinSize     int
+	maxSubs         int
+	orderedDelivery bool
+	fallbackGrace   time.Duration
+	// sharedLifecycle also publishes lifecycle events to the shared topics.
+	sharedLifecycle bool
+	ctx             context.Context // set by Start; scopes lifecycle publishes
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
 }
 
 // BridgeDependencies contains all dependencies required by the Bridge.
@@ -53,18 +94,154 @@ type BridgeDependencies struct {
 	Publisher    pubsub.Publisher
 	Subscriber   pubsub.Subscriber
 	TopicManager *topicmgr.Manager
-	ReadyTopic   topicmgr.Topic
+	// ReadyTopic and DisconnectedTopic receive the bridge's client lifecycle
+	// events. Nil uses TopicClientReady and TopicClientDisconnected, which
+	// every bridge shares; give each bridge its own, for example from
+	// ClientLifecycleTopics, to subscribe to one endpoint's events.
+	ReadyTopic        topicmgr.Topic
+	DisconnectedTopic topicmgr.Topic
+	// SharedLifecycle also publishes the lifecycle events to
+	// TopicClientReady and TopicClientDisconnected when ReadyTopic and
+	// DisconnectedTopic are the bridge's own, so subscribers of the shared
+	// topics keep receiving them.
+	SharedLifecycle bool
+	// HistorySize is the number of messages retained per user for each topic
+	// enabled with EnableHistory. Zero uses the default of 50.
+	HistorySize int
+	// EnableCBOR lets data endpoint clients negotiate CBOR frames instead of
+	// JSON. It has no effect on the HTML endpoint.
+	EnableCBOR bool
+	// ClientIDGenerator creates IDs for new connections. Nil uses random UUIDs.
+	ClientIDGenerator ClientIDGenerator
+	// UserID derives the ID a logged-in user is routed and tracked by. Nil
+	// uses UserIDByEmail; UserIDByRecordID keeps emails out of metadata and
+	// logs. Publishers of direct messages must address users by the same ID.
+	UserID UserIDFunc
+	// ClientRateLimit is the sustained number of messages per second each
+	// client may send, with bursts up to ClientRateBurst. Zero uses the
+	// defaults of 20/s and 40; a negative limit disables rate limiting.
+	ClientRateLimit float64
+	ClientRateBurst int
+	// SendBufferSize is the number of outbound messages queued per client
+	// before further messages to it are dropped. Zero uses the default of 256.
+	// Every connection allocates its own queue, so worst-case memory is
+	// roughly SendBufferSize x message size x connections; at 256 queued
+	// 4 KiB fragments that is about 1 MiB per slow client.
+	SendBufferSize int
+	// WriteTimeout bounds each write and ping to a client. Zero uses the
+	// default of 10s; raise it for clients on slow links.
+	WriteTimeout time.Duration
+	// BroadcastWorkers is the most goroutines one broadcast is delivered
+	// by, each handling at least 256 clients. Zero uses GOMAXPROCS; 1
+	// delivers every broadcast from the subscriber's goroutine.
+	BroadcastWorkers int
+	// CompressLargePayloads makes the data bridge gzip the payload of every
+	// broadcast and direct message of at least CompressThreshold bytes
+	// (default 8 KiB) before it is sent or buffered for replay; messages
+	// marked with WithCompression are compressed whatever their size.
+	// WebSocket clients that connect with ?compress=gzip receive compressed
+	// payloads as binary frames starting with the gzip magic bytes, which
+	// they must decompress; other clients, including event-stream clients,
+	// receive them decompressed. The html bridge ignores it.
+	CompressLargePayloads bool
+	CompressThreshold     int
+	// SubscribeAllow and SubscribeDeny restrict the topics clients may
+	// subscribe to, directly or through a data channel, using path.Match
+	// patterns such as "chat.*". Deny patterns take precedence; an empty
+	// allow list permits any topic that is not denied. Refused
+	// subscriptions get an error frame.
+	SubscribeAllow []string
+	SubscribeDeny  []string
+	// SubscribeLimit caps how many topics one connection may be subscribed
+	// to at once; further subscriptions get an error frame. Zero uses the
+	// default of 100; a negative value removes the cap.
+	SubscribeLimit int
+	// DefaultActions seeds the whitelist of client actions in place of
+	// DefaultClientWhitelist; modules add theirs with AllowAction.
+	DefaultActions []string
+	// AllowGuests admits connections without a logged-in user, identified by
+	// the guest ID middleware.OptionalAuth assigns. The route must use
+	// OptionalAuth instead of Auth for guests to get this far.
+	AllowGuests bool
+	// Compression selects permessage-deflate negotiation; empty disables it.
+	// CompressionThreshold is the smallest message compressed, zero using the
+	// library default of 512 bytes (128 with context takeover).
+	//
+	// Per-connection memory, beyond the send queue above, is roughly:
+	//   - 8 KiB for the read and write buffers net/http hands over on upgrade;
+	//     these are fixed by net/http and cannot be tuned here,
+	//   - up to ReadLimit for the message being read,
+	//   - with CompressionContextTakeover, a 32 KiB window per direction plus
+	//     a deflate compressor of several hundred KiB, held for the life of
+	//     the connection. CompressionNoContextTakeover borrows a pooled
+	//     compressor only while writing, trading that memory for CPU and a
+	//     worse ratio on small messages.
+	//
+	// At 10,000 connections context takeover therefore costs gigabytes, so
+	// prefer no context takeover, or none, for large connection counts.
+	Compression          Compression
+	CompressionThreshold int
+	// ReadLimit is the largest message, in bytes, a client may send; larger
+	// messages close the connection. Zero uses the default of 512 bytes; the
+	// maximum is 1 MiB.
+	ReadLimit int64
+	// OrderedDelivery delivers messages to clients in the order they were
+	// published. The in-process pub/sub hands messages over concurrently,
+	// so a burst for one user can otherwise arrive reordered, which breaks
+	// sequential DOM updates. A message published after a missing one waits
+	// up to 100ms for it; leave this off unless order matters.
+	OrderedDelivery bool
+	// ConnectionLimiter caps the connections open at once across every
+	// bridge sharing it; upgrades beyond the cap get 503 Service Unavailable.
+	// Nil admits every connection.
+	ConnectionLimiter *ConnectionLimiter
+	// DeliveryStore is shared by the bridges for this endpoint on every
+	// instance. When direct messages fan out to several instances, those
+	// that deliver an undelivered-fallback message record it there, and the
+	// rest wait UndeliveredGrace (zero uses 2s) before claiming the fallback,
+	// so it is published once and only when no instance delivered. Nil
+	// decides on this instance alone, which suits a single instance.
+	DeliveryStore    pubsub.DedupStore
+	UndeliveredGrace time.Duration
+}
+
+// Validate reports settings NewBridge would reject. Zero values are valid
+// and select the defaults.
+func (d BridgeDependencies) Validate() error {
+	var errs []error
+	if d.SendBufferSize < 0 || d.SendBufferSize > maxSendBufferSize {
+		errs = append(errs, fmt.Errorf("send buffer size %d must be between 0 and %d", d.SendBufferSize, maxSendBufferSize))
+	}
+	if d.BroadcastWorkers < 0 {
+		errs = append(errs, fmt.Errorf("broadcast workers %d must not be negative", d.BroadcastWorkers))
+	}
+	if d.UndeliveredGrace < 0 {
+		errs = append(errs, fmt.Errorf("undelivered grace %s must not be negative", d.UndeliveredGrace))
+	}
+	if d.WriteTimeout != 0 && d.WriteTimeout < minWriteTimeout {
+		errs = append(errs, fmt.Errorf("write timeout %s must be at least %s", d.WriteTimeout, minWriteTimeout))
+	}
+	if err := checkPatterns(d.SubscribeAllow); err != nil {
+		errs = append(errs, fmt.Errorf("subscribe allow list: %w", err))
+	}
+	if err := checkPatterns(d.SubscribeDeny); err != nil {
+		errs = append(errs, fmt.Errorf("subscribe deny list: %w", err))
+	}
+	errs = append(errs, validateAccept(d)...)
+	return errors.Join(errs...)
 }
 
 // topicManager manages topic subscriptions for clients
 type topicManager struct {
 	sync.RWMutex
 	subscriptions map[string]map[string]struct{} // topic -> clientID -> struct{}
+	byClient      map[string]map[string]struct{} // clientID -> topic -> struct{}
 }
 
 func newTopicManager() *topicManager {
 	return &topicManager{
 		subscriptions: make(map[string]map[string]struct{}),
+		byClient:      make(map[string]map[string]struct{}),
 	}
 }
 
@@ -79,27 +256,121 @@ type SubscribeMessage struct {
 
 // NewBridge creates a new WebSocket bridge for a specific endpoint.
 func NewBridge(endpoint string, deps BridgeDependencies) *Bridge {
+	newClientID := deps.ClientIDGenerator
+	if newClientID == nil {
+		newClientID = defaultClientIDGenerator
+	}
+	userIDOf := deps.UserID
+	if userIDOf == nil {
+		userIDOf = UserIDByEmail
+	}
+
+	rateLimit, rateBurst := deps.ClientRateLimit, deps.ClientRateBurst
+	if rateLimit == 0 {
+		rateLimit = defaultClientRateLimit
+	}
+	if rateBurst <= 0 {
+		rateBurst = max(defaultClientRateBurst, int(rateLimit))
+	}
+
+	if err := deps.Validate(); err != nil {
+		slog.Warn("Invalid WebSocket bridge settings; using defaults", "endpoint", endpoint, "error", err)
+	}
+	sendBufferSize := deps.SendBufferSize
+	if sendBufferSize <= 0 || sendBufferSize > maxSendBufferSize {
+		sendBufferSize = defaultSendBufferSize
+	}
+	writeTimeout := deps.WriteTimeout
+	if writeTimeout < minWriteTimeout {
+		writeTimeout = defaultWriteTimeout
+	}
+	fanOutWorkers := deps.BroadcastWorkers
+	if fanOutWorkers <= 0 {
+		fanOutWorkers = runtime.GOMAXPROCS(0)
+	}
+	gzipMinSize := deps.CompressThreshold
+	if gzipMinSize <= 0 {
+		gzipMinSize = defaultCompressThreshold
+	}
+	fallbackGrace := deps.UndeliveredGrace
+	if fallbackGrace <= 0 {
+		fallbackGrace = defaultUndeliveredGrace
+	}
+	maxSubs := deps.SubscribeLimit
+	if maxSubs == 0 {
+		maxSubs = defaultMaxSubscriptions
+	}
+	readyTopic, goneTopic := deps.ReadyTopic, deps.DisconnectedTopic
+	if readyTopic == nil {
+		readyTopic = TopicClientReady
+	}
+	if goneTopic == nil {
+		goneTopic = TopicClientDisconnected
+	}
+	whitelist := DefaultClientWhitelist()
+	if deps.DefaultActions != nil {
+		whitelist = NewClientWhitelist(deps.DefaultActions...)
+	}
+
 	return &Bridge{
 		endpoint:     endpoint,
 		publisher:    deps.Publisher,
 		subscriber:   deps.Subscriber,
 		topicManager: deps.TopicManager,
-		readyTopic:   deps.ReadyTopic,
+		readyTopic:   readyTopic,
+		goneTopic:    goneTopic,
 		clients:      NewClientManager(),
 		topics:       newTopicManager(),
-		whitelist:    DefaultClientWhitelist(),
+		whitelist:    whitelist,
+		subscribable: newTopicFilter(deps.SubscribeAllow, deps.SubscribeDeny),
+		history:      newMessageHistory(deps.HistorySize),
+		enableCBOR:   deps.EnableCBOR && endpoint == "data",
+		allowGuests:  deps.AllowGuests,
+		metrics:      newBridgeMetrics(),
+		newClientID:  newClientID,
+		clientIDs:    newClientIDRegistry(),
+		userIDOf:     userIDOf,
+		accept:       newAcceptSettings(deps),
+		connLimit:    deps.ConnectionLimiter,
+		deliveries:   deps.DeliveryStore,
+
+		clientRateLimit: rateLimit,
+		clientRateBurst: rateBurst,
+		sendBufferSize:  sendBufferSize,
+		writeTimeout:    writeTimeout,
+		fanOutWorkers:   fanOutWorkers,
+		gzipAll:         deps.CompressLargePayloads,
+		gzipMinSize:     gzipMinSize,
+		maxSubs:         maxSubs,
+		orderedDelivery: deps.OrderedDelivery,
+		fallbackGrace:   fallbackGrace,
+		sharedLifecycle: deps.SharedLifecycle,
 	}
 }
 
+// EnableHistory opts a logical topic into the per-user replay buffer.
+// Messages published to this bridge with Metadata[MetaKeyHistoryTopic] set to
+// topic are assigned a sequence number, delivered in a HistoryFrame carrying
+// it and retained so that clients reconnecting with ?last_seq=N, or with
+// ?resume=1 and their previous ?client_id=, receive what they missed.
+// A user's messages are kept until they have had no connected client for
+// five minutes. Only enable this for small, important topics; memory grows
+// with recent users x topics x HistorySize.
+func (b *Bridge) EnableHistory(topic string) {
+	b.history.enable(topic)
+}
+
 // AllowAction adds an action to the whitelist of allowed client actions.
 // This can be used by modules to register their allowed actions during initialization.
-// Returns an error if the action is invalid or already exists.
-func (b *Bridge) AllowAction(action string) error {
+// Options attach metadata such as a description or a per-action rate limit.
+// Allowing an action again is not an error; its options are applied on top
+// of the ones it already has. Returns an error if the action is invalid.
+func (b *Bridge) AllowAction(action string, opts ...ActionOption) error {
 	if b.whitelist == nil {
 		b.whitelist = NewClientWhitelist()
 	}
 
-	err := b.whitelist.AddAction(action)
+	err := b.whitelist.AddAction(action, opts...)
 	if err != nil && err != ErrActionAlreadyExists {
 		slog.Error("Failed to add action to whitelist",
 			"action", action,
@@ -110,12 +381,22 @@ func (b *Bridge) AllowAction(action string) error {
 	return nil
 }
 
+// WhitelistInfo returns the actions clients may send, with their metadata,
+// in the order they were allowed.
+func (b *Bridge) WhitelistInfo() []ActionInfo {
+	if b.whitelist == nil {
+		return nil
+	}
+	return b.whitelist.List()
+}
+
 // Start begins the bridge's message handling loop, subscribing to relevant pub/sub topics.
 // Returns an error if any subscription fails.
 func (b *Bridge) Start(ctx context.Context) error {
 	// Create a cancellable context for this bridge
 	var bridgeCtx context.Context
 	bridgeCtx, b.cancel = context.WithCancel(ctx)
+	b.ctx = bridgeCtx
 
 	// Get the topics for this endpoint
 	broadcastTopic, directTopic, err := b.getEndpointTopics()
@@ -124,17 +405,17 @@ func (b *Bridge) Start(ctx context.Context) error {
 	}
 
 	// Subscribe to broadcast messages for this endpoint
-	if err := b.subscriber.Subscribe(bridgeCtx, broadcastTopic.Name(), b.handleBroadcast); err != nil {
+	if err := b.subscriber.Subscribe(bridgeCtx, broadcastTopic.Name(), b.inOrder(bridgeCtx, b.handleBroadcast)); err != nil {
 		slog.Error("FATAL: Failed to subscribe to broadcast topic",
-			"topic", broadcastTopic.Name(),
+			logging.Topic(broadcastTopic.Name()),
 			"error", err)
 		return fmt.Errorf("failed to subscribe to broadcast topic %s: %w", broadcastTopic.Name(), err)
 	}
 
 	// Subscribe to the direct messages topic
-	if err := b.subscriber.Subscribe(bridgeCtx, directTopic.Name(), b.handleDirectMessage); err != nil {
+	if err := b.subscriber.Subscribe(bridgeCtx, directTopic.Name(), b.inOrder(bridgeCtx, b.handleDirectMessage)); err != nil {
 		slog.Error("FATAL: Failed to subscribe to direct topic",
-			"topic", directTopic.Name(),
+			logging.Topic(directTopic.Name()),
 			"error", err)
 		return fmt.Errorf("failed to subscribe to direct topic %s: %w", directTopic.Name(), err)
 	}
@@ -144,58 +425,96 @@ func (b *Bridge) Start(ctx context.Context) error {
 		"broadcast_topic", broadcastTopic.Name(),
 		"direct_topic", directTopic.Name())
 
+	if err := b.subscribeChannels(bridgeCtx); err != nil {
+		slog.Error("FATAL: Failed to subscribe to data channel topics", "error", err)
+		return err
+	}
+
 	return nil
 }
 
 func (b *Bridge) handleBroadcast(ctx context.Context, msg pubsub.Message) error {
-	clients := b.clients.GetAll()
-	for _, client := range clients {
+	if topic, ok := b.history.topicFor(msg); ok {
+		b.history.mu.Lock()
+		defer b.history.mu.Unlock()
+		seq := b.history.recordLocked(broadcastHistoryKey, topic, &msg, time.Now())
+		b.compressPayload(&msg)
+		b.fanOut(b.clients.GetAll(), func(client *Client) {
+			client.sendSequenced(msg.Payload, seq)
+		})
+		return nil
+	}
+
+	b.compressPayload(&msg)
+	b.fanOut(b.clients.GetAll(), func(client *Client) {
 		// SendMessage handles its own error logging
 		client.SendMessage(msg.Payload)
-	}
+	})
 	return nil
 }
 
 // handleDirectMessage processes direct messages for specific clients
-// The recipient ID should be specified in the message metadata as "recipient_id"
+// The recipient ID should be specified in the message metadata as "recipient_id",
+// or a single connection as MetaKeyRecipientClientID
 func (b *Bridge) handleDirectMessage(ctx context.Context, msg pubsub.Message) error {
 	// Get recipient ID from metadata
-	recipientID, exists := msg.Metadata["recipient_id"]
-	if !exists || recipientID == "" {
+	recipientID := msg.Metadata["recipient_id"]
+	clientID := msg.Metadata[MetaKeyRecipientClientID]
+	if recipientID == "" && clientID == "" {
 		slog.Warn("Direct message missing recipient_id in metadata",
-			"topic", msg.Topic,
+			logging.Topic(msg.Topic),
 			"metadata", msg.Metadata,
 		)
 		return nil
 	}
 
-	// Get all active clients for this recipient
-	clients := b.clients.GetByUser(recipientID)
-	if len(clients) == 0 {
-		slog.Debug("No active clients found for recipient",
-			"recipient", recipientID,
-			"endpoint", b.endpoint,
-		)
+	if clientID != "" {
+		b.compressPayload(&msg)
+		b.sendToClient(ctx, msg, clientID, recipientID)
 		return nil
 	}
 
+	if b.deliverToUser(&msg, recipientID) > 0 {
+		b.recordDelivered(ctx, msg)
+		return nil
+	}
+
+	slog.Debug("No active clients received the direct message",
+		logging.UserID(recipientID),
+		"endpoint", b.endpoint,
+	)
+	if wantsUndeliveredFallback(msg) {
+		b.fallBack(ctx, msg, recipientID)
+	}
+	return nil
+}
+
+// deliverToUser sends msg to each of recipientID's clients on this endpoint,
+// buffering it for replay first when its topic has history, and returns how
+// many clients it was sent to.
+func (b *Bridge) deliverToUser(msg *pubsub.Message, recipientID string) int {
+	// Buffer opted-in messages, even if the recipient is currently offline.
+	var seq uint64
+	if topic, ok := b.history.topicFor(*msg); ok {
+		b.history.mu.Lock()
+		defer b.history.mu.Unlock()
+		seq = b.history.recordLocked(recipientID, topic, msg, time.Now())
+	}
+	b.compressPayload(msg)
+
 	// Forward the message to all of the recipient's clients for this endpoint
 	var sentTo int
-	for _, client := range clients {
+	for _, client := range b.clients.GetByUser(recipientID) {
 		if client.Endpoint == b.endpoint {
-			client.SendMessage(msg.Payload)
+			if seq > 0 {
+				client.sendSequenced(msg.Payload, seq)
+			} else {
+				client.SendMessage(msg.Payload)
+			}
 			sentTo++
 		}
 	}
-
-	if sentTo == 0 {
-		slog.Debug("No active clients received the direct message",
-			"recipientID", recipientID,
-			"endpoint", b.endpoint,
-		)
-	}
-
-	return nil
+	return sentTo
 }
 
 // getEndpointTopics returns the broadcast and direct topics for the bridge's endpoint.
@@ -257,50 +576,61 @@ func (b *Bridge) Shutdown(ctx context.Context) {
 
 func (b *Bridge) Handler() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		user, ok := c.Get(middleware.UserContextKey).(*domain.User)
-		if !ok || user == nil {
+		userID, ok := b.connectionUserID(c)
+		if !ok {
 			slog.Error("Bridge.serve: Could not get user from context for WebSocket connection")
 			return c.String(http.StatusUnauthorized, "User not authenticated")
 		}
 
-		conn, err := websocket.Accept(c.Response(), c.Request(), &websocket.AcceptOptions{
-			// In production, you should verify the origin of the request against a list of
-			// allowed origins to prevent cross-site WebSocket hijacking.
-			InsecureSkipVerify: true, // TODO: Replace with a proper origin check in production.
-		})
+		if !b.connLimit.acquire() {
+			return b.refuseConnection(c, userID)
+		}
+
+		acceptOpts := b.accept.options()
+		if b.enableCBOR {
+			acceptOpts.Subprotocols = []string{SubprotocolCBOR, SubprotocolJSON}
+		}
+
+		// Clients may ask to keep the ID of a previous connection so that
+		// presence and other per-connection state survives a reconnect.
+		clientID := b.assignClientID(userID, c.QueryParam("client_id"))
+		c.Response().Header().Set(HeaderClientID, clientID)
+
+		conn, err := websocket.Accept(c.Response(), c.Request(), acceptOpts)
 		if err != nil {
-			slog.Error("Failed to upgrade connection to WebSocket", "error", err, "userID", user.Email)
+			b.connLimit.release()
+			b.clientIDs.release(clientID, time.Now())
+			slog.Error("Failed to upgrade connection to WebSocket", "error", err, logging.UserID(userID))
 			return fmt.Errorf("failed to upgrade connection to WebSocket: %w", err)
 		}
+		conn.SetReadLimit(b.accept.readLimit)
 
 		client := &Client{
-			ID:       uuid.New().String(),
-			UserID:   user.Email,
+			ID:       clientID,
+			UserID:   userID,
 			Conn:     conn,
-			Send:     make(chan []byte, 256),
+			Send:     make(chan []byte, b.sendBufferSize),
 			Endpoint: b.endpoint,
+			encoding: EncodingJSON,
+
+			connectedAt: time.Now(),
+			limiter:     b.newClientLimiter(),
+			dropped:     &b.metrics.dropped,
 		}
+		if b.enableCBOR {
+			client.encoding = negotiateEncoding(conn.Subprotocol(), c.Request())
+		}
+		if b.gzipAll && b.endpoint == "data" {
+			client.acceptGzip = c.QueryParam("compress") == ContentEncodingGzip
+		}
+
+		b.recordConnect()
 
-		// Register the client
-		b.clients.Add(client)
+		// Register the client and replay any buffered messages it missed.
+		b.attachClient(client, c.QueryParam("last_seq"), c.QueryParam("resume"))
 
 		// Publish a "ready" event to the message bus so other modules can react.
-		// This is done in a goroutine to avoid blocking the connection handler.
-		go func() {
-			payload, _ := json.Marshal(map[string]any{
-				"userID":   client.UserID,
-				"clientID": client.ID,
-				"endpoint": client.Endpoint,
-			})
-			readyMsg := pubsub.Message{
-				Topic:   b.readyTopic.Name(),
-				UserID:  client.UserID,
-				Payload: payload,
-			}
-			if err := b.publisher.Publish(context.Background(), readyMsg); err != nil {
-				slog.Error("Failed to publish websocket ready event", "error", err, "userID", client.UserID, "clientID", client.ID)
-			}
-		}()
+		go b.publishReady(client)
 
 		// Start the read and write pumps
 		b.wg.Add(2)
@@ -311,55 +641,172 @@ func (b *Bridge) Handler() echo.HandlerFunc {
 	}
 }
 
-// readPump pumps messages from the WebSocket connection to the bridge's incoming channel.
-func (b *Bridge) readPump(client *Client) {
-	defer func() {
-		b.clients.Remove(client.ID)
-		client.Close() // Safely close the client's channel.
-
-		// Publish client disconnected event
-		go func() {
-			payload, _ := json.Marshal(map[string]any{
-				"userID":   client.UserID,
-				"clientID": client.ID,
-				"endpoint": client.Endpoint,
-				"reason":   "connection_closed",
-			})
+// AllowsGuests reports whether the bridge admits guest connections, so
+// routes can authenticate its endpoint with middleware.OptionalAuth.
+func (b *Bridge) AllowsGuests() bool {
+	return b.allowGuests
+}
+
+// connectionUserID identifies the user behind an upgrade request: the
+// logged-in user's ID as the bridge's UserIDFunc derives it or, if the bridge
+// admits guests, the guest ID.
+func (b *Bridge) connectionUserID(c echo.Context) (string, bool) {
+	if user, ok := c.Get(middleware.UserContextKey).(*domain.User); ok && user != nil {
+		if id := b.userIDOf(user); id != "" {
+			return id, true
+		}
+		slog.Warn("Logged-in user has no WebSocket user ID", "endpoint", b.endpoint)
+	}
+	if b.allowGuests {
+		return middleware.GuestID(c)
+	}
+	return "", false
+}
+
+// attachClient registers the client and, when requested, replays buffered
+// messages newer than the client's resume point. lastSeq is an explicit
+// sequence supplied by the client; resume ("1"/"true") uses the sequence
+// recorded when the previous connection with the client's ID closed.
+func (b *Bridge) attachClient(client *Client, lastSeq, resume string) {
+	b.history.mu.Lock()
+	defer b.history.mu.Unlock()
+
+	b.clients.Add(client)
+	b.history.attachLocked(client.UserID)
+
+	from, replay := uint64(0), false
+	if lastSeq != "" {
+		if n, err := strconv.ParseUint(lastSeq, 10, 64); err == nil {
+			from, replay = n, true
+		} else {
+			slog.Warn("Ignoring invalid last_seq", logging.ClientID(client.ID), "last_seq", lastSeq)
+		}
+	} else if ok, _ := strconv.ParseBool(resume); ok {
+		from, replay = b.history.takeResumeLocked(client.ID, client.UserID, time.Now())
+	}
+
+	if !replay {
+		client.lastSeq.Store(b.history.seq)
+		return
+	}
+
+	client.lastSeq.Store(from)
+	missed := b.history.sinceLocked(client.UserID, from)
+	for _, entry := range missed {
+		client.sendSequenced(entry.payload, entry.seq)
+	}
+	// Later sequences belong to other users, so a client that got all it
+	// missed is up to date.
+	if !client.seqGap.Load() {
+		client.lastSeq.Store(b.history.seq)
+	}
+	if len(missed) > 0 {
+		slog.Info("Replayed buffered messages to reconnecting client",
+			logging.ClientID(client.ID),
+			logging.UserID(client.UserID),
+			"from_seq", from,
+			"count", len(missed),
+			"complete", !client.seqGap.Load())
+	}
+}
+
+// publishReady publishes the bridge's ready event for a new client so other
+// modules can react. It blocks on the publish, so call it in a goroutine.
+func (b *Bridge) publishReady(client *Client) {
+	payload, _ := json.Marshal(map[string]any{
+		"userID":   client.UserID,
+		"clientID": client.ID,
+		"endpoint": client.Endpoint,
+	})
+	for _, topic := range b.lifecycleTopics(b.readyTopic, TopicClientReady) {
+		readyMsg := pubsub.Message{
+			Topic:   topic,
+			UserID:  client.UserID,
+			Payload: payload,
+		}
+		ctx, cancel := b.publishContext()
+		err := b.publisher.Publish(ctx, readyMsg)
+		cancel()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("Failed to publish websocket ready event", "error", err, logging.Topic(topic), logging.UserID(client.UserID), logging.ClientID(client.ID))
+		}
+	}
+}
+
+// lifecycleTopics returns the topics a lifecycle event goes to: the bridge's
+// own topic and, with SharedLifecycle, the shared one if it differs.
+func (b *Bridge) lifecycleTopics(own, shared topicmgr.Topic) []string {
+	if b.sharedLifecycle && own.Name() != shared.Name() {
+		return []string{own.Name(), shared.Name()}
+	}
+	return []string{own.Name()}
+}
+
+// detachClient unregisters a client whose connection ended, records its
+// resume point and publishes the disconnected event.
+func (b *Bridge) detachClient(client *Client) {
+	b.clients.Remove(client.ID)
+	b.unsubscribeAll(client.ID)
+	client.Close() // Safely close the client's channel.
+	b.history.markDisconnected(client.ID, client.UserID, client.lastSeq.Load(), time.Now())
+	b.recordDisconnect(client)
+	b.connLimit.release()
+	b.clientIDs.release(client.ID, time.Now())
+
+	// Publish client disconnected event
+	go func() {
+		payload, _ := json.Marshal(map[string]any{
+			"userID":   client.UserID,
+			"clientID": client.ID,
+			"endpoint": client.Endpoint,
+			"reason":   "connection_closed",
+		})
+		for _, topic := range b.lifecycleTopics(b.goneTopic, TopicClientDisconnected) {
 			disconnectMsg := pubsub.Message{
-				Topic:   TopicClientDisconnected.Name(),
+				Topic:   topic,
 				UserID:  client.UserID,
 				Payload: payload,
 			}
-			if err := b.publisher.Publish(context.Background(), disconnectMsg); err != nil {
-				slog.Error("Failed to publish websocket disconnect event", "error", err, "userID", client.UserID, "clientID", client.ID)
+			ctx, cancel := b.publishContext()
+			err := b.publisher.Publish(ctx, disconnectMsg)
+			cancel()
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("Failed to publish websocket disconnect event", "error", err, logging.Topic(topic), logging.UserID(client.UserID), logging.ClientID(client.ID))
 			}
-		}()
+		}
+	}()
+}
 
+// readPump pumps messages from the WebSocket connection to the bridge's incoming channel.
+func (b *Bridge) readPump(client *Client) {
+	defer func() {
+		b.detachClient(client)
 		b.wg.Done()
-		slog.Info("Client disconnected", "clientID", client.ID, "userID", client.UserID, "endpoint", b.endpoint)
+		slog.Info("Client disconnected", logging.ClientID(client.ID), logging.UserID(client.UserID), "endpoint", b.endpoint)
 	}()
 
-	// The coder/websocket library does not have SetReadLimit, so we check manually.
-	// It does, however, automatically handle pong messages to update the read deadline.
+	// Handler sets the connection's read limit, so oversized messages end the
+	// read with ErrMessageTooBig and the library closes the connection.
 	for {
-		_, message, err := client.Conn.Read(context.Background())
+		msgType, message, err := client.Conn.Read(context.Background())
 		if err != nil {
 			if websocket.CloseStatus(err) == websocket.StatusNormalClosure ||
 				websocket.CloseStatus(err) == websocket.StatusGoingAway {
-				slog.Debug("WebSocket closed normally by client", "clientID", client.ID)
+				slog.Debug("WebSocket closed normally by client", logging.ClientID(client.ID))
+			} else if errors.Is(err, websocket.ErrMessageTooBig) {
+				slog.Warn("Message too large, closing connection",
+					logging.ClientID(client.ID),
+					"max", b.accept.readLimit)
 			} else {
-				slog.Error("Unexpected WebSocket read error", "clientID", client.ID, "error", err)
+				slog.Error("Unexpected WebSocket read error", logging.ClientID(client.ID), "error", err)
 			}
 			break
 		}
 
-		// Check message size since we can't set a read limit directly
-		if len(message) > maxMessageSize {
-			slog.Warn("Message too large, closing connection",
-				"clientID", client.ID,
-				"size", len(message),
-				"max", maxMessageSize)
-			return
+		message, err = decodeFrame(client.encoding, msgType, message)
+		if err != nil {
+			slog.Warn("Received undecodable frame from client", logging.ClientID(client.ID), "error", err)
+			continue
 		}
 
 		b.handleIncoming(client, message)
@@ -367,6 +814,10 @@ func (b *Bridge) readPump(client *Client) {
 }
 
 func (b *Bridge) handleIncoming(client *Client, rawMsg []byte) {
+	if !b.allowIncoming(client) {
+		return
+	}
+
 	// Try to parse as a subscription message first
 	var subMsg SubscribeMessage
 	if err := json.Unmarshal(rawMsg, &subMsg); err == nil && (subMsg.Action == "subscribe" || subMsg.Action == "unsubscribe") {
@@ -374,6 +825,12 @@ func (b *Bridge) handleIncoming(client *Client, rawMsg []byte) {
 		return
 	}
 
+	var chanMsg ChannelMessage
+	if err := json.Unmarshal(rawMsg, &chanMsg); err == nil && (chanMsg.Action == "subscribe_channel" || chanMsg.Action == "unsubscribe_channel") {
+		b.handleChannelMessage(client, chanMsg)
+		return
+	}
+
 	// Handle regular messages
 	var msg struct {
 		Action  string          `json:"action"`
@@ -382,45 +839,102 @@ func (b *Bridge) handleIncoming(client *Client, rawMsg []byte) {
 	}
 
 	if err := json.Unmarshal(rawMsg, &msg); err != nil {
-		slog.Warn("Received invalid message from client", "clientID", client.ID, "error", err)
+		slog.Warn("Received invalid message from client", logging.ClientID(client.ID), "error", err)
 		return // Ignore malformed messages
 	}
 
 	if msg.Action == "" {
-		slog.Warn("Incoming message missing 'action' field", "clientID", client.ID)
+		slog.Warn("Incoming message missing 'action' field", logging.ClientID(client.ID))
 		return
 	}
 
 	// Check if the action is whitelisted
-	if !b.whitelist.IsAllowed(msg.Action) {
+	action, ok := b.whitelist.Info(msg.Action)
+	if !ok {
 		slog.Warn("Client attempted to use non-whitelisted action",
-			"clientID", client.ID,
+			logging.ClientID(client.ID),
 			"action", msg.Action)
 		return
 	}
+	if !b.admitAction(client, action) {
+		return
+	}
 
 	// If a topic is not specified in the message, use the action as the topic.
 	// This provides backward compatibility and a sensible default.
 	if msg.Topic == "" {
 		msg.Topic = msg.Action
 	}
+	msg.Topic = b.normalizeTopic(msg.Topic)
+
+	// Only topics defined with AllowClientPublish may be emitted by clients.
+	if b.topicManager == nil || !b.topicManager.AllowsClientPublish(msg.Topic) {
+		slog.Warn("Client attempted to publish to a server-only topic",
+			logging.ClientID(client.ID),
+			logging.Topic(msg.Topic))
+		return
+	}
+
+	if !b.topicEnabled(client, msg.Topic) {
+		return
+	}
 
 	// Verify the client is subscribed to the topic
 	if !b.isClientSubscribed(client.ID, msg.Topic) {
 		slog.Warn("Client attempted to publish to unsubscribed topic",
-			"clientID", client.ID,
-			"topic", msg.Topic)
+			logging.ClientID(client.ID),
+			logging.Topic(msg.Topic))
 		return
 	}
 
-	b.publisher.Publish(context.Background(), pubsub.Message{
+	// Validate the payload here, at the trust boundary, so subscribers
+	// receive only payloads that match the topic's schema.
+	if topic, ok := b.topicManager.Get(msg.Topic); ok {
+		if err := topicmgr.CheckPayload(topic, msg.Payload); err != nil {
+			slog.Warn("Client published a payload that does not match the topic schema",
+				logging.ClientID(client.ID),
+				logging.Topic(msg.Topic),
+				"error", err)
+			b.sendError(client, ErrorCodeInvalidPayload, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := b.publishContext()
+	defer cancel()
+	if err := b.publisher.Publish(ctx, pubsub.Message{
 		Topic:   msg.Topic,
 		Payload: msg.Payload,
 		UserID:  client.UserID,
-	})
+	}); err != nil {
+		slog.Error("Failed to publish client message", "error", err, logging.ClientID(client.ID), logging.Topic(msg.Topic))
+	}
+}
+
+// lifecyclePublishTimeout bounds the publishes the bridge makes on behalf of
+// its clients, so a slow Pub/Sub backend can't hold up a connection or
+// shutdown.
+const lifecyclePublishTimeout = 2 * time.Second
+
+// publishContext returns the context for a publish on behalf of a client. It
+// derives from the bridge's context, so publishes are abandoned once the
+// bridge shuts down.
+func (b *Bridge) publishContext() (context.Context, context.CancelFunc) {
+	return pubsub.WithPublishContext(b.ctx, lifecyclePublishTimeout)
 }
 
 func (b *Bridge) writePump(client *Client) {
+	// The manager nils client.Send when it closes it, so read it once under
+	// the client's lock; receiving from the closed channel ends the loop.
+	client.mu.RLock()
+	send := client.Send
+	client.mu.RUnlock()
+	if send == nil {
+		client.Conn.Close(websocket.StatusNormalClosure, "client removed")
+		b.wg.Done()
+		return
+	}
+
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
@@ -430,27 +944,39 @@ func (b *Bridge) writePump(client *Client) {
 
 	for {
 		select {
-		case message, ok := <-client.Send:
+		case message, ok := <-send:
 			if !ok {
 				// The manager closed the channel.
 				client.Conn.Close(websocket.StatusNormalClosure, "channel closed")
 				return
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), writeWait)
-			err := client.Conn.Write(ctx, websocket.MessageText, message)
+			message, err := clientPayload(client, message)
+			if err != nil {
+				slog.Warn("Dropping undecodable message for client", logging.ClientID(client.ID), "error", err)
+				continue
+			}
+			msgType, frame, err := encodeFrame(client.encoding, message)
+			if err != nil {
+				slog.Warn("Failed to encode message for client", logging.ClientID(client.ID), "error", err)
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), b.writeTimeout)
+			err = client.Conn.Write(ctx, msgType, frame)
 			cancel()
 			if err != nil {
-				slog.Warn("WebSocket write error", "clientID", client.ID, "error", err)
+				slog.Warn("WebSocket write error", logging.ClientID(client.ID), "error", err)
 				return
 			}
+			b.recordMessageSize(len(frame))
 
 		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+			ctx, cancel := context.WithTimeout(context.Background(), b.writeTimeout)
 			err := client.Conn.Ping(ctx)
 			cancel()
 			if err != nil {
-				slog.Warn("WebSocket ping error", "clientID", client.ID, "error", err)
+				slog.Warn("WebSocket ping error", logging.ClientID(client.ID), "error", err)
 				return
 			}
 		}
@@ -459,48 +985,151 @@ func (b *Bridge) writePump(client *Client) {
 
 // handleSubscription manages topic subscriptions for a client
 func (b *Bridge) handleSubscription(client *Client, msg SubscribeMessage) {
-	topic := msg.Topic
+	// Only the topic part is normalized; channels are chosen by clients and
+	// may be case-sensitive IDs.
+	baseTopic := b.normalizeTopic(msg.Topic)
+	topic := baseTopic
 	if msg.Payload.Channel != "" {
 		topic = fmt.Sprintf("%s.%s", topic, msg.Payload.Channel)
 	}
 
+	// Clients choose the channel, so bound the combined name before it is
+	// used as a map key or logged.
+	limits := topicmgr.DefaultNameLimits
+	if b.topicManager != nil {
+		limits = b.topicManager.NameLimits()
+	}
+	if err := limits.Check(topic); err != nil {
+		slog.Warn("Client sent an oversized subscription topic",
+			logging.ClientID(client.ID),
+			"error", err)
+		b.sendError(client, ErrorCodeInvalidTopic, "Topic name is too long or has too many segments.")
+		return
+	}
+
 	switch msg.Action {
 	case "subscribe":
-		b.subscribeClient(client.ID, topic)
+		if !b.subscribable.allows(topic) {
+			slog.Warn("Client attempted to subscribe to a topic not allowed on this endpoint",
+				logging.ClientID(client.ID),
+				logging.Topic(topic),
+				"endpoint", b.endpoint)
+			b.sendError(client, ErrorCodeTopicNotAllowed, fmt.Sprintf("Subscribing to %q is not allowed here.", topic))
+			return
+		}
+		if !b.topicEnabled(client, baseTopic) {
+			return
+		}
+		if !b.subscribeClient(client.ID, topic) {
+			slog.Warn("Client reached its subscription limit",
+				logging.ClientID(client.ID),
+				logging.UserID(client.UserID),
+				logging.Topic(topic),
+				"limit", b.maxSubs)
+			b.sendError(client, ErrorCodeSubscriptionLimit, fmt.Sprintf("You can subscribe to at most %d topics.", b.maxSubs))
+			return
+		}
 		slog.Info("Client subscribed to topic",
-			"clientID", client.ID,
-			"topic", topic)
+			logging.ClientID(client.ID),
+			logging.Topic(topic))
 
 	case "unsubscribe":
 		b.unsubscribeClient(client.ID, topic)
 		slog.Info("Client unsubscribed from topic",
-			"clientID", client.ID,
-			"topic", topic)
+			logging.ClientID(client.ID),
+			logging.Topic(topic))
 	}
 }
 
-// subscribeClient adds a client to a topic
-func (b *Bridge) subscribeClient(clientID, topic string) {
+// normalizeTopic returns topic as the topic manager looks it up, so clients
+// may send "Chat.Message" for "chat.message".
+func (b *Bridge) normalizeTopic(topic string) string {
+	if b.topicManager == nil {
+		return topicmgr.NormalizeName(topic, topicmgr.CaseFold)
+	}
+	return b.topicManager.Normalize(topic)
+}
+
+// ErrorCodeInvalidPayload is the ErrorFrame code for client publishes whose
+// payload does not match the topic's payload_fields.
+const ErrorCodeInvalidPayload = "invalid_payload"
+
+// ErrorCodeTopicDisabled is the ErrorFrame code for publishes and
+// subscriptions refused because the topic is switched off with
+// topicmgr.Manager.SetEnabled.
+const ErrorCodeTopicDisabled = "topic_disabled"
+
+// topicEnabled reports whether topic is switched on, sending client an error
+// frame if it is not.
+func (b *Bridge) topicEnabled(client *Client, topic string) bool {
+	if b.topicManager == nil || b.topicManager.IsEnabled(topic) {
+		return true
+	}
+	slog.Warn("Client used a disabled topic", logging.ClientID(client.ID), logging.Topic(topic))
+	b.sendError(client, ErrorCodeTopicDisabled, fmt.Sprintf("Topic %q is disabled.", topic))
+	return false
+}
+
+// ErrorCodeSubscriptionLimit is the ErrorFrame code for subscriptions refused
+// because the client is at its SubscribeLimit.
+const ErrorCodeSubscriptionLimit = "subscription_limit"
+
+// subscribeClient adds a client to a topic. It reports false, without
+// subscribing, if the client is already at its subscription limit.
+func (b *Bridge) subscribeClient(clientID, topic string) bool {
 	b.topics.Lock()
 	defer b.topics.Unlock()
 
+	topics := b.topics.byClient[clientID]
+	if _, subscribed := topics[topic]; subscribed {
+		return true
+	}
+	if b.maxSubs > 0 && len(topics) >= b.maxSubs {
+		return false
+	}
+	if topics == nil {
+		topics = make(map[string]struct{})
+		b.topics.byClient[clientID] = topics
+	}
+	topics[topic] = struct{}{}
+
 	if _, exists := b.topics.subscriptions[topic]; !exists {
 		b.topics.subscriptions[topic] = make(map[string]struct{})
 	}
 	b.topics.subscriptions[topic][clientID] = struct{}{}
+	return true
 }
 
 // unsubscribeClient removes a client from a topic
 func (b *Bridge) unsubscribeClient(clientID, topic string) {
 	b.topics.Lock()
 	defer b.topics.Unlock()
+	b.unsubscribeLocked(clientID, topic)
+}
 
+// unsubscribeAll removes a disconnected client from every topic.
+func (b *Bridge) unsubscribeAll(clientID string) {
+	b.topics.Lock()
+	defer b.topics.Unlock()
+	for topic := range b.topics.byClient[clientID] {
+		b.unsubscribeLocked(clientID, topic)
+	}
+}
+
+// unsubscribeLocked removes a client from a topic. b.topics must be locked.
+func (b *Bridge) unsubscribeLocked(clientID, topic string) {
 	if subscribers, exists := b.topics.subscriptions[topic]; exists {
 		delete(subscribers, clientID)
 		if len(subscribers) == 0 {
 			delete(b.topics.subscriptions, topic)
 		}
 	}
+	if topics, exists := b.topics.byClient[clientID]; exists {
+		delete(topics, topic)
+		if len(topics) == 0 {
+			delete(b.topics.byClient, clientID)
+		}
+	}
 }
 
 // isClientSubscribed checks if a client is subscribed to a topic
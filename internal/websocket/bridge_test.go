@@ -3,6 +3,7 @@ package websocket_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -15,8 +16,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	surrealmodels "github.com/surrealdb/surrealdb.go/pkg/models"
 
 	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/middleware"
 
 	"github.com/nfrund/goby/internal/pubsub"
 	"github.com/nfrund/goby/internal/topicmgr"
@@ -92,6 +95,12 @@ func (m *mockPubSub) Subscribe(ctx context.Context, topic string, handler pubsub
 	return nil
 }
 
+func (m *mockPubSub) HasSubscribers(topic string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.handlers[topic]) > 0
+}
+
 func (m *mockPubSub) Close() error { return nil }
 
 func (m *mockPubSub) getMessages(topic string) []pubsub.Message {
@@ -146,14 +155,54 @@ func (m *mockTopic) Metadata() map[string]interface{} {
 	}
 }
 
+func (m *mockTopic) PayloadFields() []string {
+	return nil
+}
+
+func (m *mockTopic) EventType() string {
+	return ""
+}
+
+func (m *mockTopic) Source() string {
+	return ""
+}
+
 func (m *mockTopic) Scope() topicmgr.TopicScope {
 	return m.scope
 }
 
+func (m *mockTopic) AllowClientPublish() bool {
+	return false
+}
+
+func (m *mockTopic) RelatedTopics() []string {
+	return nil
+}
+
+func (m *mockTopic) DefinedAt() string {
+	return ""
+}
+
+func (m *mockTopic) Enabled() bool {
+	return true
+}
+
+// clientTopic defines a module topic that WebSocket clients may publish to.
+func clientTopic(name string) topicmgr.Topic {
+	return topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:               name,
+		Module:             "test",
+		Description:        "Client-publishable test topic: " + name,
+		Pattern:            name,
+		AllowClientPublish: true,
+	})
+}
+
 // testFixture holds all the components needed for testing the bridge.
 type testFixture struct {
 	bridge *ws.Bridge
 	ps     *mockPubSub
+	topics *topicmgr.Manager
 	server *httptest.Server
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -177,6 +226,17 @@ func setupTestFixture(t *testing.T) (*testFixture, func()) {
 	require.NoError(t, topicManager.Register(wsTopics.TopicHTMLBroadcast))
 	require.NoError(t, topicManager.Register(wsTopics.TopicHTMLDirect))
 	require.NoError(t, topicManager.Register(readyTopic))
+	for _, name := range []string{"test.topic", "alternate.topic", "valid.topic"} {
+		require.NoError(t, topicManager.Register(clientTopic(name)))
+	}
+	require.NoError(t, topicManager.Register(topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:               "typed.topic",
+		Module:             "test",
+		Description:        "Client-publishable topic with a payload schema",
+		Pattern:            "typed.topic",
+		Metadata:           map[string]interface{}{topicmgr.MetaPayloadFields: []string{"key"}},
+		AllowClientPublish: true,
+	})))
 
 	bridge := ws.NewBridge("html", ws.BridgeDependencies{
 		Publisher:    ps,
@@ -204,6 +264,7 @@ func setupTestFixture(t *testing.T) (*testFixture, func()) {
 	fixture := &testFixture{
 		bridge: bridge,
 		ps:     ps,
+		topics: topicManager,
 		server: server,
 		ctx:    ctx,
 		cancel: cancel,
@@ -270,6 +331,81 @@ func TestBridge_Subscription(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestBridge_RejectsServerOnlyTopics(t *testing.T) {
+	fixture, cleanup := setupTestFixture(t)
+	require.NoError(t, fixture.bridge.AllowAction("test.action"))
+	defer cleanup()
+
+	conn := connectTestClient(t, fixture.server)
+
+	// ws.html.broadcast is registered but not client-publishable; unknown.topic
+	// is not registered at all.
+	for _, topic := range []string{wsTopics.TopicHTMLBroadcast.Name(), "unknown.topic", "test.topic"} {
+		require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"subscribe","topic":"`+topic+`"}`)))
+		require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"`+topic+`","payload":{}}`)))
+	}
+
+	// The client-publishable topic goes through, proving the earlier writes were processed.
+	require.Eventually(t, func() bool {
+		return len(fixture.ps.getMessages("test.topic")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, fixture.ps.getMessages(wsTopics.TopicHTMLBroadcast.Name()))
+	assert.Empty(t, fixture.ps.getMessages("unknown.topic"))
+}
+
+func TestBridge_RejectsPayloadsViolatingSchema(t *testing.T) {
+	fixture, cleanup := setupTestFixture(t)
+	require.NoError(t, fixture.bridge.AllowAction("test.action"))
+	defer cleanup()
+
+	conn := connectTestClient(t, fixture.server)
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"subscribe","topic":"typed.topic"}`)))
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"typed.topic","payload":{"key":"v","admin":true}}`)))
+
+	var toast ws.Toast
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &toast))
+	assert.Equal(t, ws.ToastError, toast.Level)
+	assert.Contains(t, toast.Text, "unknown fields admin")
+
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"typed.topic","payload":{"key":"v"}}`)))
+	require.Eventually(t, func() bool {
+		return len(fixture.ps.getMessages("typed.topic")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"key":"v"}`, string(fixture.ps.getMessages("typed.topic")[0].Payload), "only the valid payload is forwarded")
+}
+
+func TestBridge_DisabledTopicBlocksPublishing(t *testing.T) {
+	fixture, cleanup := setupTestFixture(t)
+	require.NoError(t, fixture.bridge.AllowAction("test.action"))
+	defer cleanup()
+
+	conn := connectTestClient(t, fixture.server)
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"subscribe","topic":"test.topic"}`)))
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"test.topic","payload":{"n":1}}`)))
+	require.Eventually(t, func() bool {
+		return len(fixture.ps.getMessages("test.topic")) == 1
+	}, time.Second, 10*time.Millisecond, "subscribed while enabled")
+
+	require.NoError(t, fixture.topics.SetEnabled("test.topic", false))
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"test.topic","payload":{"n":2}}`)))
+
+	var toast ws.Toast
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &toast))
+	assert.Equal(t, ws.ToastError, toast.Level)
+	assert.Contains(t, toast.Text, `"test.topic" is disabled`)
+
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"subscribe","topic":"test.topic","payload":{"channel":"room1"}}`)))
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &toast))
+	assert.Contains(t, toast.Text, "disabled", "subscriptions are refused too")
+
+	require.NoError(t, fixture.topics.SetEnabled("test.topic", true))
+	require.NoError(t, conn.Write(fixture.ctx, websocket.MessageText, []byte(`{"action":"test.action","topic":"test.topic","payload":{"n":3}}`)))
+	require.Eventually(t, func() bool {
+		return len(fixture.ps.getMessages("test.topic")) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"n":3}`, string(fixture.ps.getMessages("test.topic")[1].Payload), "the publish made while disabled is not forwarded")
+}
+
 func TestBridge_InvalidMessage(t *testing.T) {
 	fixture, cleanup := setupTestFixture(t)
 	conn := connectTestClient(t, fixture.server)
@@ -349,3 +485,242 @@ func TestBridge_ConcurrentSubscriptions(t *testing.T) {
 		}, 100*time.Millisecond, 10*time.Millisecond, "expected %d messages, got %d", numClients/2, atomic.LoadInt32(&msgCount))
 	})
 }
+
+func connectTestClientWithQuery(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/html?" + query
+	conn, _, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close(websocket.StatusNormalClosure, "test complete")
+	})
+	return conn
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func publishDirect(t *testing.T, ps *mockPubSub, payload, historyTopic string) {
+	t.Helper()
+	metadata := map[string]string{"recipient_id": "test@example.com"}
+	if historyTopic != "" {
+		metadata[ws.MetaKeyHistoryTopic] = historyTopic
+	}
+	require.NoError(t, ps.Publish(context.Background(), pubsub.Message{
+		Topic:    wsTopics.TopicHTMLDirect.Name(),
+		Payload:  []byte(payload),
+		Metadata: metadata,
+	}))
+}
+
+// readHistory reads a history frame and returns its data as text.
+func readHistory(t *testing.T, conn *websocket.Conn) (string, uint64) {
+	t.Helper()
+	var frame ws.HistoryFrame
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &frame))
+	assert.Equal(t, "ws.history", frame.Type)
+	assert.Equal(t, "alerts", frame.Topic)
+	var data string
+	require.NoError(t, json.Unmarshal(frame.Data, &data))
+	return data, frame.Seq
+}
+
+func TestBridge_HistoryReplay(t *testing.T) {
+	fixture, cleanup := setupTestFixture(t)
+	defer cleanup()
+	fixture.bridge.EnableHistory("alerts")
+
+	// Messages sent while the user is offline: only the opted-in topic is
+	// buffered. The mock delivers each publish on its own goroutine, so wait
+	// for each to be recorded before the next to keep their order.
+	for _, msg := range []struct{ payload, topic string }{
+		{"missed-1", "alerts"},
+		{"not-buffered", ""},
+		{"missed-2", "alerts"},
+	} {
+		publishDirect(t, fixture.ps, msg.payload, msg.topic)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	conn := connectTestClientWithQuery(t, fixture.server, "last_seq=0")
+	data, seq := readHistory(t, conn)
+	assert.Equal(t, "missed-1", data)
+	assert.Equal(t, uint64(1), seq)
+	data, seq = readHistory(t, conn)
+	assert.Equal(t, "missed-2", data)
+	assert.Equal(t, uint64(2), seq)
+
+	// Only messages after the provided sequence are replayed.
+	conn2 := connectTestClientWithQuery(t, fixture.server, "last_seq=1")
+	data, _ = readHistory(t, conn2)
+	assert.Equal(t, "missed-2", data)
+}
+
+func TestBridge_HistoryResume(t *testing.T) {
+	fixture, cleanup := setupTestFixture(t)
+	defer cleanup()
+	fixture.bridge.EnableHistory("alerts")
+
+	wsURL := "ws" + strings.TrimPrefix(fixture.server.URL, "http") + "/ws/html"
+	conn, resp, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	clientID := resp.Header.Get(ws.HeaderClientID)
+	publishDirect(t, fixture.ps, "live-1", "alerts")
+	data, _ := readHistory(t, conn)
+	assert.Equal(t, "live-1", data)
+
+	// Disconnect and wait for the bridge to record the resume point.
+	conn.Close(websocket.StatusNormalClosure, "going away")
+	time.Sleep(50 * time.Millisecond)
+
+	publishDirect(t, fixture.ps, "while-away", "alerts")
+	time.Sleep(20 * time.Millisecond)
+
+	resumed := connectTestClientWithQuery(t, fixture.server, "resume=1&client_id="+clientID)
+	data, _ = readHistory(t, resumed)
+	assert.Equal(t, "while-away", data, "only messages missed since disconnect are replayed")
+
+	publishDirect(t, fixture.ps, "live-2", "alerts")
+	data, _ = readHistory(t, resumed)
+	assert.Equal(t, "live-2", data)
+}
+
+func TestBridge_StableClientIDs(t *testing.T) {
+	ps := newMockPubSub()
+	bridge := ws.NewBridge("html", ws.BridgeDependencies{
+		Publisher:         ps,
+		Subscriber:        ps,
+		ReadyTopic:        newMockTopic("ws.ready"),
+		ClientIDGenerator: func() string { return "client-1" },
+	})
+
+	e := echo.New()
+	addAuthMiddleware(e)
+	e.GET("/ws/html", bridge.Handler())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	dial := func(query string) (*websocket.Conn, string) {
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/html" + query
+		conn, resp, err := websocket.Dial(context.Background(), wsURL, nil)
+		require.NoError(t, err)
+		return conn, resp.Header.Get(ws.HeaderClientID)
+	}
+
+	conn, id := dial("")
+	assert.Equal(t, "client-1", id)
+	conn.Close(websocket.StatusNormalClosure, "reconnecting")
+
+	// The same ID can be reclaimed once the first connection is gone.
+	require.Eventually(t, func() bool {
+		conn, id = dial("?client_id=client-1")
+		if id != "client-1" {
+			conn.Close(websocket.StatusNormalClosure, "retry")
+			return false
+		}
+		return true
+	}, time.Second, 20*time.Millisecond)
+	defer conn.Close(websocket.StatusNormalClosure, "test complete")
+}
+
+func TestBridge_GuestConnections(t *testing.T) {
+	guestID := domain.NewGuestID()
+	for _, tc := range []struct {
+		name       string
+		allow      bool
+		wantStatus int
+	}{
+		{"admitted when allowed", true, http.StatusSwitchingProtocols},
+		{"refused by default", false, http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := newMockPubSub()
+			bridge := ws.NewBridge("html", ws.BridgeDependencies{
+				Publisher:   ps,
+				Subscriber:  ps,
+				ReadyTopic:  newMockTopic("ws.ready"),
+				AllowGuests: tc.allow,
+			})
+			assert.Equal(t, tc.allow, bridge.AllowsGuests())
+
+			e := echo.New()
+			// Simulate middleware.OptionalAuth admitting an anonymous request.
+			e.GET("/ws/html", bridge.Handler(), func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set(middleware.GuestContextKey, guestID)
+					return next(c)
+				}
+			})
+			server := httptest.NewServer(e)
+			defer server.Close()
+
+			wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/html"
+			conn, resp, err := websocket.Dial(context.Background(), wsURL, nil)
+			require.NotNil(t, resp)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.allow {
+				require.NoError(t, err)
+				conn.Close(websocket.StatusNormalClosure, "test complete")
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBridge_UserIDByRecordID(t *testing.T) {
+	ps := newMockPubSub()
+	ttm := NewTestTopicManager(t)
+	defer ttm.Cleanup()
+	readyTopic := newMockTopic("ws.ready")
+	require.NoError(t, ttm.Manager().RegisterAll(wsTopics.TopicHTMLBroadcast, wsTopics.TopicHTMLDirect, readyTopic))
+
+	bridge := ws.NewBridge("html", ws.BridgeDependencies{
+		Publisher:    ps,
+		Subscriber:   ps,
+		TopicManager: ttm.Manager(),
+		ReadyTopic:   readyTopic,
+		UserID:       ws.UserIDByRecordID,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bridge.Start(ctx))
+
+	recordID := surrealmodels.NewRecordID("user", "opaque1")
+	user := &domain.User{ID: &recordID, Email: "private@example.com"}
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(middleware.UserContextKey, user)
+			return next(c)
+		}
+	})
+	e.GET("/ws/html", bridge.Handler())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn := connectTestClient(t, server)
+	require.Eventually(t, func() bool {
+		return len(ps.getMessages(readyTopic.Name())) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, recordID.String(), ps.getMessages(readyTopic.Name())[0].UserID,
+		"presence sees the record ID, not the email")
+
+	direct := func(recipient, payload string) {
+		require.NoError(t, ps.Publish(ctx, pubsub.Message{
+			Topic:    wsTopics.TopicHTMLDirect.Name(),
+			Payload:  []byte(payload),
+			Metadata: map[string]string{"recipient_id": recipient},
+		}))
+	}
+	direct(user.Email, "by-email")
+	time.Sleep(20 * time.Millisecond) // the email is not a known recipient
+	direct(recordID.String(), "by-record-id")
+	assert.Equal(t, "by-record-id", readText(t, conn), "direct messages route by record ID only")
+}
@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// broadcastAll is the payload of a TopicBroadcastAll message. Either part may
+// be empty, in which case that endpoint's clients get nothing.
+type broadcastAll struct {
+	HTML []byte `json:"html,omitempty"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// NewBroadcastAllMessage builds the TopicBroadcastAll message that delivers
+// htmlPayload to every HTML client and dataPayload to every data client.
+func NewBroadcastAllMessage(htmlPayload, dataPayload []byte) (pubsub.Message, error) {
+	if len(htmlPayload) == 0 && len(dataPayload) == 0 {
+		return pubsub.Message{}, errors.New("broadcast needs an HTML or a data payload")
+	}
+	payload, err := json.Marshal(broadcastAll{HTML: htmlPayload, Data: dataPayload})
+	if err != nil {
+		return pubsub.Message{}, fmt.Errorf("failed to marshal broadcast: %w", err)
+	}
+	return pubsub.Message{Topic: TopicBroadcastAll.Name(), Payload: payload}, nil
+}
+
+// BroadcastAll sends htmlPayload to all HTML clients and dataPayload to all
+// data clients with a single publish. A BroadcastFanout must be running to
+// deliver it.
+func BroadcastAll(ctx context.Context, pub pubsub.Publisher, htmlPayload, dataPayload []byte) error {
+	msg, err := NewBroadcastAllMessage(htmlPayload, dataPayload)
+	if err != nil {
+		return err
+	}
+	return pub.Publish(ctx, msg)
+}
+
+// BroadcastFanout republishes TopicBroadcastAll messages to the HTML and data
+// bridges' broadcast topics, so modules need not know about either bridge.
+type BroadcastFanout struct {
+	publisher pubsub.Publisher
+	group     *pubsub.SubscriberGroup
+
+	mu sync.Mutex
+	// published holds, for messages nacked after a partial failure, the
+	// topics already republished, so the redelivery only publishes the rest.
+	published map[string]publishedParts
+}
+
+// publishedParts records which topics a message was republished to.
+type publishedParts struct {
+	topics map[string]bool
+	at     time.Time
+}
+
+// publishedRetention bounds how long a partly republished message is
+// remembered; a redelivery later than this publishes every part again.
+const publishedRetention = 10 * time.Minute
+
+// NewBroadcastFanout creates a fan-out; call Start to begin relaying.
+func NewBroadcastFanout(pub pubsub.Publisher, sub pubsub.Subscriber) *BroadcastFanout {
+	f := &BroadcastFanout{publisher: pub, published: make(map[string]publishedParts)}
+	f.group = pubsub.NewSubscriberGroup(sub, "ws_broadcast_fanout")
+	f.group.Add(TopicBroadcastAll.Name(), f.handle)
+	return f
+}
+
+// Start begins relaying until ctx ends or Stop is called.
+func (f *BroadcastFanout) Start(ctx context.Context) {
+	f.group.Start(ctx)
+}
+
+// Stop ends relaying and waits for in-flight messages to be republished.
+func (f *BroadcastFanout) Stop(ctx context.Context) error {
+	return f.group.Stop(ctx)
+}
+
+// handle splits a TopicBroadcastAll message into one broadcast per endpoint.
+// Metadata is copied to both, so options such as history apply to each. If
+// a part fails the message is nacked, and its redelivery skips the parts
+// that were already published.
+func (f *BroadcastFanout) handle(ctx context.Context, msg pubsub.Message) error {
+	var payload broadcastAll
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return pubsub.Reject(fmt.Errorf("invalid %s payload: %w", TopicBroadcastAll.Name(), err))
+	}
+
+	id := msg.Metadata[pubsub.MetaKeyMessageID]
+	done := f.takePublished(id)
+	var errs []error
+	for _, part := range []struct {
+		topic   string
+		payload []byte
+	}{
+		{TopicHTMLBroadcast.Name(), payload.HTML},
+		{TopicDataBroadcast.Name(), payload.Data},
+	} {
+		if len(part.payload) == 0 || done[part.topic] {
+			continue
+		}
+		out := pubsub.Message{
+			Topic:    part.topic,
+			UserID:   msg.UserID,
+			Payload:  part.payload,
+			Metadata: maps.Clone(msg.Metadata),
+		}
+		if err := f.publisher.Publish(ctx, out); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish to %s: %w", part.topic, err))
+			continue
+		}
+		done[part.topic] = true
+	}
+	if len(errs) > 0 {
+		f.keepPublished(id, done)
+	}
+	return errors.Join(errs...)
+}
+
+// takePublished returns and forgets the topics message id was already
+// republished to.
+func (f *BroadcastFanout) takePublished(id string) map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts, ok := f.published[id]
+	if !ok || id == "" {
+		return make(map[string]bool)
+	}
+	delete(f.published, id)
+	return parts.topics
+}
+
+// keepPublished remembers the topics message id was republished to until
+// it is redelivered, dropping records older than publishedRetention.
+func (f *BroadcastFanout) keepPublished(id string, topics map[string]bool) {
+	if id == "" || len(topics) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for key, parts := range f.published {
+		if now.Sub(parts.at) > publishedRetention {
+			delete(f.published, key)
+		}
+	}
+	f.published[id] = publishedParts{topics: topics, at: now}
+}
@@ -0,0 +1,157 @@
+package websocket_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/pubsub"
+	ws "github.com/nfrund/goby/internal/websocket"
+	wsTopics "github.com/nfrund/goby/internal/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastFanout_ReachesBothBridges(t *testing.T) {
+	ps := newMockPubSub()
+	testTopicManager := NewTestTopicManager(t)
+	defer testTopicManager.Cleanup()
+	topicManager := testTopicManager.Manager()
+	readyTopic := newMockTopic("ws.ready")
+	require.NoError(t, topicManager.Register(wsTopics.TopicHTMLBroadcast))
+	require.NoError(t, topicManager.Register(wsTopics.TopicHTMLDirect))
+	require.NoError(t, topicManager.Register(wsTopics.TopicDataBroadcast))
+	require.NoError(t, topicManager.Register(wsTopics.TopicDataDirect))
+	require.NoError(t, topicManager.Register(readyTopic))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := echo.New()
+	addAuthMiddleware(e)
+	for _, endpoint := range []string{"html", "data"} {
+		bridge := ws.NewBridge(endpoint, ws.BridgeDependencies{
+			Publisher:    ps,
+			Subscriber:   ps,
+			TopicManager: topicManager,
+			ReadyTopic:   readyTopic,
+		})
+		require.NoError(t, bridge.Start(ctx))
+		e.GET("/ws/"+endpoint, bridge.Handler())
+	}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	fanout := ws.NewBroadcastFanout(ps, ps)
+	fanout.Start(ctx)
+	defer fanout.Stop(context.Background())
+	require.Eventually(t, func() bool {
+		return ps.HasSubscribers(wsTopics.TopicBroadcastAll.Name())
+	}, time.Second, 10*time.Millisecond)
+
+	htmlConn := dialEndpoint(t, server, "html")
+	dataConn := dialEndpoint(t, server, "data")
+
+	require.NoError(t, ws.BroadcastAll(context.Background(), ps, []byte("<p>hello</p>"), []byte(`{"hello":true}`)))
+
+	assert.Equal(t, "<p>hello</p>", readText(t, htmlConn))
+	assert.JSONEq(t, `{"hello":true}`, readText(t, dataConn))
+	assert.Len(t, ps.getMessages(wsTopics.TopicHTMLBroadcast.Name()), 1)
+	assert.Len(t, ps.getMessages(wsTopics.TopicDataBroadcast.Name()), 1)
+}
+
+func TestNewBroadcastAllMessage(t *testing.T) {
+	_, err := ws.NewBroadcastAllMessage(nil, nil)
+	assert.Error(t, err)
+
+	msg, err := ws.NewBroadcastAllMessage([]byte("<p>only html</p>"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, wsTopics.TopicBroadcastAll.Name(), msg.Topic)
+}
+
+func TestBroadcastFanout_SkipsEmptyPart(t *testing.T) {
+	ps := newMockPubSub()
+	fanout := ws.NewBroadcastFanout(ps, ps)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fanout.Start(ctx)
+	require.Eventually(t, func() bool {
+		return ps.HasSubscribers(wsTopics.TopicBroadcastAll.Name())
+	}, time.Second, 10*time.Millisecond)
+
+	msg, err := ws.NewBroadcastAllMessage(nil, []byte(`{"n":1}`))
+	require.NoError(t, err)
+	msg.Metadata = map[string]string{ws.MetaKeyHistoryTopic: "scores"}
+	require.NoError(t, ps.Publish(context.Background(), msg))
+
+	require.Eventually(t, func() bool {
+		return len(ps.getMessages(wsTopics.TopicDataBroadcast.Name())) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, ps.getMessages(wsTopics.TopicHTMLBroadcast.Name()))
+	assert.Equal(t, "scores", ps.getMessages(wsTopics.TopicDataBroadcast.Name())[0].Metadata[ws.MetaKeyHistoryTopic])
+
+	require.NoError(t, fanout.Stop(context.Background()))
+}
+
+// failOncePublisher fails the first publish to topic.
+type failOncePublisher struct {
+	pubsub.Publisher
+	topic  string
+	failed atomic.Bool
+}
+
+func (p *failOncePublisher) Publish(ctx context.Context, msg pubsub.Message) error {
+	if msg.Topic == p.topic && p.failed.CompareAndSwap(false, true) {
+		return errors.New("transient failure")
+	}
+	return p.Publisher.Publish(ctx, msg)
+}
+
+func TestBroadcastFanout_RetriesOnlyFailedPart(t *testing.T) {
+	bridge := pubsub.NewWatermillBridge()
+	defer bridge.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var htmlCount, dataCount atomic.Int32
+	require.NoError(t, bridge.Subscribe(ctx, wsTopics.TopicHTMLBroadcast.Name(), func(context.Context, pubsub.Message) error {
+		htmlCount.Add(1)
+		return nil
+	}))
+	require.NoError(t, bridge.Subscribe(ctx, wsTopics.TopicDataBroadcast.Name(), func(context.Context, pubsub.Message) error {
+		dataCount.Add(1)
+		return nil
+	}))
+
+	pub := &failOncePublisher{Publisher: bridge, topic: wsTopics.TopicDataBroadcast.Name()}
+	fanout := ws.NewBroadcastFanout(pub, bridge)
+	fanout.Start(ctx)
+	defer fanout.Stop(context.Background())
+	require.Eventually(t, func() bool {
+		return bridge.HasSubscribers(wsTopics.TopicBroadcastAll.Name())
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ws.BroadcastAll(ctx, bridge, []byte("<p>hi</p>"), []byte(`{"hi":true}`)))
+	require.Eventually(t, func() bool { return dataCount.Load() == 1 }, 5*time.Second, 10*time.Millisecond,
+		"the nacked message is redelivered and its failed part published")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), htmlCount.Load(), "the part published before the failure is not sent again")
+}
+
+func dialEndpoint(t *testing.T, server *httptest.Server, endpoint string) *websocket.Conn {
+	t.Helper()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + endpoint
+	conn, _, err := websocket.Dial(context.Background(), wsURL, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Cookie": []string{"session=fake-session-for-testing"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "test complete") })
+	return conn
+}
@@ -3,35 +3,98 @@ package websocket
 import (
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/coder/websocket"
+	"github.com/nfrund/goby/internal/logging"
+	"golang.org/x/time/rate"
 )
 
 // Client represents a single connected WebSocket client.
 type Client struct {
 	ID       string
 	UserID   string
-	Conn     *websocket.Conn
+	Conn     *websocket.Conn // nil for event-stream clients
 	Send     chan []byte
 	Endpoint string // "html" or "data"
 	mu       sync.RWMutex
+	// lastSeq is the sequence of the last history-buffered message queued
+	// for this client with none dropped before it; it becomes the client's
+	// resume point on disconnect. seqGap is set once a history-buffered
+	// message to the client is dropped, which freezes lastSeq.
+	lastSeq atomic.Uint64
+	seqGap  atomic.Bool
+	// encoding is the wire format negotiated for this connection.
+	encoding Encoding
+	// acceptGzip is set for data clients that connected with
+	// ?compress=gzip; others get compressed payloads decompressed.
+	acceptGzip bool
+	// connectedAt is when the connection was accepted, for lifetime metrics.
+	connectedAt time.Time
+	// limiter throttles inbound messages; nil disables rate limiting.
+	limiter *rate.Limiter
+	// rateLimited is set while messages are being dropped by limiter. It is
+	// only accessed from the read pump.
+	rateLimited bool
+	// actionLimiters holds the limiters of actions with their own rate
+	// limit, created on first use. It is only accessed from the read pump.
+	actionLimiters map[string]*actionLimiter
+	// dropping is set while messages to the client are being dropped
+	// because its queue is full; dropped, when set, counts them.
+	dropping atomic.Bool
+	dropped  *atomic.Uint64
 }
 
 // SendMessage safely sends a message to the client's send channel.
 // It uses a read lock to ensure the channel is not closed concurrently.
+// SendMessage never blocks: the message is dropped if the client's queue is
+// full or the client is being closed. A client that stops reading is
+// disconnected once a write to it exceeds the bridge's write timeout.
 func (c *Client) SendMessage(msg []byte) {
-	c.mu.RLock()
+	c.trySend(msg)
+}
+
+// trySend queues msg like SendMessage and reports whether it was queued.
+func (c *Client) trySend(msg []byte) bool {
+	// Only Close takes the write lock, so a client that can't be read-locked
+	// is going away; waiting for it would hold up the rest of a broadcast.
+	if !c.mu.TryRLock() {
+		return false
+	}
 	defer c.mu.RUnlock()
 
 	// If the channel is nil, it means the client is disconnected.
 	if c.Send == nil {
-		return
+		return false
 	}
 
 	select {
 	case c.Send <- msg:
+		c.dropping.Store(false)
+		return true
 	default:
-		slog.Warn("Client send channel full, dropping message", "clientID", c.ID)
+		if c.dropped != nil {
+			c.dropped.Add(1)
+		}
+		// Log the first drop of a run rather than every message.
+		if !c.dropping.Swap(true) {
+			slog.Warn("Client send channel full, dropping messages", logging.ClientID(c.ID))
+		}
+		return false
+	}
+}
+
+// sendSequenced sends a history-buffered message and advances the client's
+// resume point to seq once it is queued. After a drop the resume point stays
+// before the dropped message, so resuming replays it and everything after.
+func (c *Client) sendSequenced(msg []byte, seq uint64) {
+	if !c.trySend(msg) {
+		c.seqGap.Store(true)
+		return
+	}
+	if !c.seqGap.Load() && seq > c.lastSeq.Load() {
+		c.lastSeq.Store(seq)
 	}
 }
 
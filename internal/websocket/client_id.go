@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nfrund/goby/internal/logging"
+)
+
+// ClientIDGenerator returns a new, unique client ID. Inject one through
+// BridgeDependencies to get predictable IDs in tests.
+type ClientIDGenerator func() string
+
+// HeaderClientID is the handshake response header carrying the ID assigned to
+// a connection. Clients that want a stable ID send it back as ?client_id= when
+// they reconnect.
+const HeaderClientID = "X-Goby-Client-ID"
+
+// clientIDReclaimWindow is how long a released ID stays reserved for the user
+// that held it before anyone may claim it again.
+const clientIDReclaimWindow = 5 * time.Minute
+
+// clientIDPattern limits requested IDs to URL- and log-safe characters.
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// defaultClientIDGenerator produces random UUIDs.
+func defaultClientIDGenerator() string {
+	return uuid.New().String()
+}
+
+// clientIDOwner records who holds a client ID. releasedAt is zero while the
+// ID is attached to a live connection.
+type clientIDOwner struct {
+	userID     string
+	releasedAt time.Time
+}
+
+// clientIDRegistry tracks which user owns each client ID so that a
+// reconnecting client can reclaim its previous ID while other users cannot.
+type clientIDRegistry struct {
+	mu        sync.Mutex
+	owners    map[string]clientIDOwner
+	lastSweep time.Time
+}
+
+func newClientIDRegistry() *clientIDRegistry {
+	return &clientIDRegistry{owners: make(map[string]clientIDOwner)}
+}
+
+// claim attaches id to userID. It fails if the ID is held by a live
+// connection, or was released by another user within the reclaim window.
+func (r *clientIDRegistry) claim(id, userID string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweepLocked(now)
+
+	if owner, ok := r.owners[id]; ok {
+		if owner.releasedAt.IsZero() {
+			return false
+		}
+		if owner.userID != userID && now.Sub(owner.releasedAt) < clientIDReclaimWindow {
+			return false
+		}
+	}
+	r.owners[id] = clientIDOwner{userID: userID}
+	return true
+}
+
+// release marks id as free to be reclaimed by its owner.
+func (r *clientIDRegistry) release(id string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if owner, ok := r.owners[id]; ok {
+		owner.releasedAt = now
+		r.owners[id] = owner
+	}
+}
+
+// sweepLocked drops expired reservations at most once per reclaim window.
+func (r *clientIDRegistry) sweepLocked(now time.Time) {
+	if now.Sub(r.lastSweep) < clientIDReclaimWindow {
+		return
+	}
+	r.lastSweep = now
+	for id, owner := range r.owners {
+		if !owner.releasedAt.IsZero() && now.Sub(owner.releasedAt) >= clientIDReclaimWindow {
+			delete(r.owners, id)
+		}
+	}
+}
+
+// assignClientID returns the ID for a new connection. A valid requested ID is
+// honored when the user may claim it; otherwise a fresh ID is generated.
+func (b *Bridge) assignClientID(userID, requested string) string {
+	now := time.Now()
+	if requested != "" {
+		if clientIDPattern.MatchString(requested) && b.clientIDs.claim(requested, userID, now) {
+			return requested
+		}
+		slog.Warn("Requested client ID unavailable, assigning a new one",
+			logging.UserID(userID),
+			"requested", requested)
+	}
+
+	// Fall back to random IDs if an injected generator keeps colliding.
+	generate := b.newClientID
+	for attempt := 0; ; attempt++ {
+		if attempt == 3 {
+			slog.Error("Client ID generator returned IDs already in use, falling back to UUIDs")
+			generate = defaultClientIDGenerator
+		}
+		if id := generate(); b.clientIDs.claim(id, userID, now) {
+			return id
+		}
+	}
+}
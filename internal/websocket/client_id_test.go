@@ -0,0 +1,35 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIDRegistry_Claim(t *testing.T) {
+	r := newClientIDRegistry()
+	now := time.Now()
+
+	assert.True(t, r.claim("tab-1", "alice", now))
+	assert.False(t, r.claim("tab-1", "alice", now), "a live ID cannot be claimed twice")
+
+	r.release("tab-1", now)
+	assert.False(t, r.claim("tab-1", "bob", now.Add(time.Minute)), "reserved for the previous owner")
+	assert.True(t, r.claim("tab-1", "alice", now.Add(time.Minute)), "the owner reclaims it on reconnect")
+
+	r.release("tab-1", now)
+	assert.True(t, r.claim("tab-1", "bob", now.Add(clientIDReclaimWindow)), "reservations expire")
+}
+
+func TestBridge_AssignClientID(t *testing.T) {
+	b := NewBridge("html", BridgeDependencies{
+		ClientIDGenerator: func() string { return "fixed" },
+	})
+
+	assert.Equal(t, "fixed", b.assignClientID("alice", ""))
+	assert.NotEqual(t, "fixed", b.assignClientID("alice", ""), "a colliding generator falls back to UUIDs")
+	assert.Equal(t, "mine", b.assignClientID("alice", "mine"))
+	assert.NotEqual(t, "mine", b.assignClientID("alice", "mine"), "taken IDs are replaced")
+	assert.NotEqual(t, "bad id!", b.assignClientID("alice", "bad id!"), "invalid IDs are replaced")
+}
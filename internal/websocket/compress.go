@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+const (
+	// MetaKeyCompress asks a bridge with CompressLargePayloads to gzip a
+	// message's payload even when it is below the compression threshold.
+	// Bridges without CompressLargePayloads ignore it. Set it with
+	// WithCompression.
+	MetaKeyCompress = "compress"
+	// MetaKeyContentEncoding is set to ContentEncodingGzip on messages whose
+	// payload the bridge compressed, including history replays and
+	// republished undelivered messages.
+	MetaKeyContentEncoding = "content_encoding"
+	// ContentEncodingGzip marks a gzip-compressed payload.
+	ContentEncodingGzip = "gzip"
+
+	// defaultCompressThreshold is the smallest payload compressed unless
+	// BridgeDependencies.CompressThreshold says otherwise.
+	defaultCompressThreshold = 8 << 10
+)
+
+// gzipMagic starts every gzip stream. JSON, HTML and CBOR payloads never
+// start with it, so clients can tell compressed frames apart.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+// WithCompression returns msg with MetaKeyCompress set, so a bridge that
+// compresses large payloads also gzips this one, e.g. for a mid-sized,
+// repetitive HTML table.
+func WithCompression(msg pubsub.Message) pubsub.Message {
+	metadata := make(map[string]string, len(msg.Metadata)+1)
+	maps.Copy(metadata, msg.Metadata)
+	metadata[MetaKeyCompress] = "true"
+	msg.Metadata = metadata
+	return msg
+}
+
+// compressPayload gzips msg's payload on a data bridge with
+// CompressLargePayloads, when the payload is at least the bridge's threshold
+// or msg has MetaKeyCompress set, marking it with MetaKeyContentEncoding.
+// Payloads that don't shrink are left as they are. HTML clients have no way
+// to decompress payloads, so the html bridge never compresses them.
+func (b *Bridge) compressPayload(msg *pubsub.Message) {
+	if !b.gzipAll || b.endpoint != "data" || isGzipped(msg.Payload) {
+		return
+	}
+	if len(msg.Payload) < b.gzipMinSize && msg.Metadata[MetaKeyCompress] != "true" {
+		return
+	}
+
+	compressed, err := gzipPayload(msg.Payload)
+	if err != nil {
+		slog.Warn("Failed to compress payload, sending it uncompressed", logging.Topic(msg.Topic), "error", err)
+		return
+	}
+	if len(compressed) >= len(msg.Payload) {
+		return
+	}
+	metadata := make(map[string]string, len(msg.Metadata)+1)
+	maps.Copy(metadata, msg.Metadata)
+	metadata[MetaKeyContentEncoding] = ContentEncodingGzip
+	msg.Metadata = metadata
+	msg.Payload = compressed
+}
+
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isGzipped reports whether payload is a gzip stream.
+func isGzipped(payload []byte) bool {
+	return bytes.HasPrefix(payload, gzipMagic)
+}
+
+// clientPayload returns message as client receives it: decompressed unless
+// the client asked for gzip payloads.
+func clientPayload(client *Client, message []byte) ([]byte, error) {
+	if client.acceptGzip || !isGzipped(message) {
+		return message, nil
+	}
+	return gunzipPayload(message)
+}
+
+// gunzipPayload decompresses a payload compressed by compressPayload.
+func gunzipPayload(payload []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip payload: %w", err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	return data, nil
+}
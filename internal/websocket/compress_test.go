@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBridge_CompressesLargePayloads(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{CompressLargePayloads: true})
+	clients := addClients(b, 2, 2)
+	client := clients[0]
+	client.acceptGzip = true
+	large := []byte(`{"rows":"` + strings.Repeat("<tr><td>row</td></tr>", 1000) + `"}`)
+	small := []byte(`{"rows":"<tr><td>row</td></tr>"}`)
+
+	require.NoError(t, b.handleBroadcast(context.Background(), pubsub.Message{Payload: large}))
+	require.NoError(t, b.handleBroadcast(context.Background(), pubsub.Message{Payload: small}))
+
+	sent := <-client.Send
+	assert.Less(t, len(sent), len(large)/10)
+	msgType, frame, err := encodeFrame(client.encoding, sent)
+	require.NoError(t, err)
+	assert.Equal(t, websocket.MessageBinary, msgType)
+	decompressed, err := gunzipPayload(frame)
+	require.NoError(t, err)
+	assert.Equal(t, large, decompressed, "the payload round-trips")
+	plain, err := clientPayload(clients[1], <-clients[1].Send)
+	require.NoError(t, err)
+	assert.Equal(t, large, plain, "clients that didn't ask for gzip get the payload decompressed")
+
+	sent = <-client.Send
+	assert.Equal(t, small, sent, "payloads below the threshold are sent as they are")
+	<-clients[1].Send
+	msgType, _, err = encodeFrame(client.encoding, sent)
+	require.NoError(t, err)
+	assert.Equal(t, websocket.MessageText, msgType)
+}
+
+func TestBridge_CompressPayloadMarksMessage(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{CompressLargePayloads: true, CompressThreshold: 1024})
+	payload := []byte(strings.Repeat("<p>hello</p>", 20))
+
+	msg := pubsub.Message{Payload: payload, Metadata: map[string]string{"k": "v"}}
+	b.compressPayload(&msg)
+	assert.Equal(t, payload, msg.Payload, "payloads below the threshold are left alone")
+
+	for _, other := range []*Bridge{
+		NewBridge("data", BridgeDependencies{CompressThreshold: 64}),
+		NewBridge("html", BridgeDependencies{CompressLargePayloads: true, CompressThreshold: 64}),
+	} {
+		msg := WithCompression(pubsub.Message{Payload: payload})
+		other.compressPayload(&msg)
+		assert.Equal(t, payload, msg.Payload, "%s bridge without CompressLargePayloads, or html bridge", other.endpoint)
+	}
+
+	original := WithCompression(msg)
+	msg = original
+	b.compressPayload(&msg)
+	assert.True(t, isGzipped(msg.Payload))
+	assert.Equal(t, ContentEncodingGzip, msg.Metadata[MetaKeyContentEncoding])
+	assert.Equal(t, "v", msg.Metadata["k"])
+	assert.NotContains(t, original.Metadata, MetaKeyContentEncoding, "the published message is not modified")
+
+	compressed := msg.Payload
+	b.compressPayload(&msg)
+	assert.Equal(t, compressed, msg.Payload, "payloads are compressed once")
+}
@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/logging"
+)
+
+// ConnectionLimiter caps the number of connections, WebSocket and event
+// stream alike, open at once across every bridge it is shared with. It is a
+// server-wide safety valve against connection floods exhausting file
+// descriptors and memory: connections beyond the cap are refused with 503
+// Service Unavailable until clients disconnect.
+//
+// A nil *ConnectionLimiter admits every connection.
+type ConnectionLimiter struct {
+	max       int64
+	active    atomic.Int64
+	rejected  atomic.Uint64
+	saturated atomic.Bool
+}
+
+// NewConnectionLimiter returns a limiter admitting up to max connections.
+// Zero or less means no limit, for which it returns nil.
+func NewConnectionLimiter(max int) *ConnectionLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &ConnectionLimiter{max: int64(max)}
+}
+
+// refuseConnection answers a connection the bridge's ConnectionLimiter
+// refused with 503 Service Unavailable and counts it.
+func (b *Bridge) refuseConnection(c echo.Context, userID string) error {
+	b.metrics.connectionsRejected.Add(1)
+	slog.Debug("Refusing connection over the connection limit", "endpoint", b.endpoint, logging.UserID(userID))
+	c.Response().Header().Set("Retry-After", connectionLimitRetryAfter)
+	return c.String(http.StatusServiceUnavailable, ConnectionLimitReason)
+}
+
+// Max returns the connection cap, or 0 if there is none.
+func (l *ConnectionLimiter) Max() int {
+	if l == nil {
+		return 0
+	}
+	return int(l.max)
+}
+
+// Active returns the number of connections currently admitted.
+func (l *ConnectionLimiter) Active() int {
+	if l == nil {
+		return 0
+	}
+	return int(l.active.Load())
+}
+
+// Rejected returns the number of upgrades refused since startup.
+func (l *ConnectionLimiter) Rejected() uint64 {
+	if l == nil {
+		return 0
+	}
+	return l.rejected.Load()
+}
+
+// acquire admits a connection if the cap allows it. Each successful acquire
+// must be paired with a release when the connection ends.
+func (l *ConnectionLimiter) acquire() bool {
+	if l == nil {
+		return true
+	}
+	for {
+		n := l.active.Load()
+		if n >= l.max {
+			l.rejected.Add(1)
+			// Log once per episode rather than for every refused upgrade.
+			if l.saturated.CompareAndSwap(false, true) {
+				slog.Warn("WebSocket connection limit reached; refusing new connections", "limit", l.max)
+			}
+			return false
+		}
+		if l.active.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// release frees the slot of a connection that ended, or of an admitted
+// upgrade that failed.
+func (l *ConnectionLimiter) release() {
+	if l == nil {
+		return
+	}
+	if l.active.Add(-1) < l.max && l.saturated.CompareAndSwap(true, false) {
+		slog.Info("WebSocket connections below the limit again", "limit", l.max)
+	}
+}
+
+// ConnectionLimitReason is the body of the 503 response to an upgrade
+// refused by a ConnectionLimiter.
+const ConnectionLimitReason = "Server connection limit reached, try again later"
+
+// connectionLimitRetryAfter is the Retry-After, in seconds, sent with it.
+const connectionLimitRetryAfter = "5"
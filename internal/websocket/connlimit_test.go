@@ -0,0 +1,85 @@
+package websocket_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ws "github.com/nfrund/goby/internal/websocket"
+)
+
+func TestBridge_ConnectionLimit(t *testing.T) {
+	ps := newMockPubSub()
+	limiter := ws.NewConnectionLimiter(2)
+	e := echo.New()
+	addAuthMiddleware(e)
+	var bridges []*ws.Bridge
+	for _, endpoint := range []string{"html", "data"} {
+		b := ws.NewBridge(endpoint, ws.BridgeDependencies{
+			Publisher:         ps,
+			Subscriber:        ps,
+			ReadyTopic:        newMockTopic("ws.ready"),
+			ConnectionLimiter: limiter,
+		})
+		e.GET("/ws/"+endpoint, b.Handler())
+		bridges = append(bridges, b)
+	}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	dial := func(endpoint string) (*websocket.Conn, *http.Response, error) {
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + endpoint
+		return websocket.Dial(context.Background(), wsURL, nil)
+	}
+
+	// The cap is shared: one connection on each endpoint fills it.
+	html, _, err := dial("html")
+	require.NoError(t, err)
+	data, _, err := dial("data")
+	require.NoError(t, err)
+	defer data.Close(websocket.StatusNormalClosure, "test complete")
+	assert.Equal(t, 2, limiter.Active())
+
+	for _, endpoint := range []string{"html", "data"} {
+		_, resp, err := dial(endpoint)
+		require.Error(t, err, "upgrade beyond the cap on %s", endpoint)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, ws.ConnectionLimitReason, string(body))
+	}
+	assert.Equal(t, uint64(2), limiter.Rejected())
+	assert.Equal(t, uint64(1), bridges[0].Metrics().ConnectionsRejected)
+	assert.Equal(t, 2, bridges[1].Metrics().ConnectionLimit)
+
+	// A disconnect frees a slot for a new connection.
+	html.Close(websocket.StatusNormalClosure, "leaving")
+	var again *websocket.Conn
+	require.Eventually(t, func() bool {
+		conn, _, err := dial("html")
+		if err != nil {
+			return false
+		}
+		again = conn
+		return true
+	}, time.Second, 20*time.Millisecond)
+	defer again.Close(websocket.StatusNormalClosure, "test complete")
+	assert.Equal(t, 2, limiter.Active())
+}
+
+func TestNewConnectionLimiter_NoLimit(t *testing.T) {
+	var limiter *ws.ConnectionLimiter = ws.NewConnectionLimiter(0)
+	assert.Nil(t, limiter)
+	assert.Equal(t, 0, limiter.Max())
+	assert.Equal(t, 0, limiter.Active())
+}
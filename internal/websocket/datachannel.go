@@ -0,0 +1,226 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/topicmgr"
+)
+
+// ChannelUpdateType is the type of the frames that carry data channel updates.
+const ChannelUpdateType = "ws.channel.update"
+
+// ErrorCodeUnknownChannel is the ErrorFrame code for channel subscriptions
+// naming a channel the bridge doesn't have.
+const ErrorCodeUnknownChannel = "unknown_channel"
+
+// channelNamePattern restricts channel names to short, lowercase tokens.
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// DataChannel is a named, typed feed on the data endpoint, such as a live
+// scoreboard. Clients subscribe to it by name:
+//
+//	{"action":"subscribe_channel","channel":"scoreboard"}
+//
+// and receive every update published to Topic as a ChannelUpdate:
+//
+//	{"type":"ws.channel.update","channel":"scoreboard","version":1,"data":{"home":2,"away":1}}
+//
+// The topic's payload_fields metadata is the channel's schema: an update must
+// be a JSON object with exactly those fields, or it is dropped.
+type DataChannel struct {
+	// Name is what clients subscribe to.
+	Name string
+	// Topic carries the channel's updates and defines their fields.
+	Topic topicmgr.Topic
+	// Version is the schema version sent with each update. Zero uses the
+	// topic's schema_version metadata, or 1 without it.
+	Version int
+}
+
+// ChannelUpdate is the frame a data channel update is delivered in.
+type ChannelUpdate struct {
+	Type    string          `json:"type"`
+	Channel string          `json:"channel"`
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ChannelMessage subscribes a client to a data channel
+// ("subscribe_channel") or unsubscribes it ("unsubscribe_channel").
+type ChannelMessage struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+}
+
+// channelRegistry holds a bridge's data channels by name.
+type channelRegistry struct {
+	mu      sync.RWMutex
+	byName  map[string]*DataChannel
+	started bool // set by Start; no channels are added after it
+}
+
+func (r *channelRegistry) get(name string) (*DataChannel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ch, ok := r.byName[name]
+	return ch, ok
+}
+
+func (r *channelRegistry) all() []*DataChannel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channels := make([]*DataChannel, 0, len(r.byName))
+	for _, ch := range r.byName {
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// RegisterChannel adds a data channel to the data endpoint. Channels must be
+// registered before Start, which subscribes to their topics, and the topic
+// must declare its payload_fields.
+func (b *Bridge) RegisterChannel(ch DataChannel) error {
+	if b.endpoint != "data" {
+		return fmt.Errorf("data channels are only available on the data endpoint, not %q", b.endpoint)
+	}
+	if !channelNamePattern.MatchString(ch.Name) {
+		return fmt.Errorf("invalid channel name %q: use up to 64 lowercase letters, digits, '.', '_' or '-'", ch.Name)
+	}
+	if ch.Topic == nil {
+		return fmt.Errorf("channel %q has no topic", ch.Name)
+	}
+	if len(ch.Topic.PayloadFields()) == 0 {
+		return fmt.Errorf("channel %q: topic %s declares no payload_fields", ch.Name, ch.Topic.Name())
+	}
+	if ch.Version == 0 {
+		ch.Version = 1
+		if v, ok := ch.Topic.Metadata()["schema_version"].(int); ok && v > 0 {
+			ch.Version = v
+		}
+	}
+
+	b.channels.mu.Lock()
+	defer b.channels.mu.Unlock()
+	if b.channels.started {
+		return errors.New("data channels must be registered before the bridge starts")
+	}
+	if _, exists := b.channels.byName[ch.Name]; exists {
+		return fmt.Errorf("channel %q is already registered", ch.Name)
+	}
+	if b.channels.byName == nil {
+		b.channels.byName = make(map[string]*DataChannel)
+	}
+	b.channels.byName[ch.Name] = &ch
+	return nil
+}
+
+// ValidatePayload reports whether payload matches the channel's schema: a
+// JSON object with exactly the topic's payload_fields.
+func (ch *DataChannel) ValidatePayload(payload []byte) error {
+	if err := topicmgr.CheckPayloadStrict(ch.Topic, payload); err != nil {
+		return fmt.Errorf("channel %q: %w", ch.Name, err)
+	}
+	return nil
+}
+
+// subscribeChannels subscribes to the topics of the registered channels.
+func (b *Bridge) subscribeChannels(ctx context.Context) error {
+	b.channels.mu.Lock()
+	b.channels.started = true
+	b.channels.mu.Unlock()
+
+	for _, ch := range b.channels.all() {
+		if err := b.subscriber.Subscribe(ctx, ch.Topic.Name(), b.inOrder(ctx, b.channelHandler(ch))); err != nil {
+			return fmt.Errorf("failed to subscribe to channel %s topic %s: %w", ch.Name, ch.Topic.Name(), err)
+		}
+	}
+	return nil
+}
+
+// channelHandler delivers updates published to a channel's topic to the
+// clients subscribed to the channel.
+func (b *Bridge) channelHandler(ch *DataChannel) pubsub.Handler {
+	key := channelSubscriptionKey(ch.Name)
+	return func(ctx context.Context, msg pubsub.Message) error {
+		if err := ch.ValidatePayload(msg.Payload); err != nil {
+			slog.Warn("Dropping data channel update that does not match its schema",
+				logging.Topic(msg.Topic), "error", err)
+			return nil
+		}
+		frame, err := json.Marshal(ChannelUpdate{
+			Type:    ChannelUpdateType,
+			Channel: ch.Name,
+			Version: ch.Version,
+			Data:    json.RawMessage(bytes.TrimSpace(msg.Payload)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal channel update: %w", err)
+		}
+
+		b.topics.RLock()
+		subscribers := make([]string, 0, len(b.topics.subscriptions[key]))
+		for clientID := range b.topics.subscriptions[key] {
+			subscribers = append(subscribers, clientID)
+		}
+		b.topics.RUnlock()
+
+		for _, clientID := range subscribers {
+			if client, ok := b.clients.Get(clientID); ok {
+				client.SendMessage(frame)
+			}
+		}
+		return nil
+	}
+}
+
+// handleChannelMessage subscribes a client to a data channel or
+// unsubscribes it.
+func (b *Bridge) handleChannelMessage(client *Client, msg ChannelMessage) {
+	channel, ok := b.channels.get(msg.Channel)
+	if !ok {
+		slog.Warn("Client named an unknown data channel",
+			logging.ClientID(client.ID),
+			"channel", msg.Channel)
+		b.sendError(client, ErrorCodeUnknownChannel, fmt.Sprintf("There is no channel %q.", msg.Channel))
+		return
+	}
+	key := channelSubscriptionKey(msg.Channel)
+
+	switch msg.Action {
+	case "subscribe_channel":
+		if !b.subscribable.allows(channel.Topic.Name()) {
+			slog.Warn("Client attempted to subscribe to a data channel not allowed on this endpoint",
+				logging.ClientID(client.ID),
+				logging.Topic(channel.Topic.Name()),
+				"channel", msg.Channel)
+			b.sendError(client, ErrorCodeTopicNotAllowed, fmt.Sprintf("Subscribing to channel %q is not allowed here.", msg.Channel))
+			return
+		}
+		if !b.topicEnabled(client, channel.Topic.Name()) {
+			return
+		}
+		if !b.subscribeClient(client.ID, key) {
+			b.sendError(client, ErrorCodeSubscriptionLimit, fmt.Sprintf("You can subscribe to at most %d topics.", b.maxSubs))
+			return
+		}
+		slog.Info("Client subscribed to data channel", logging.ClientID(client.ID), "channel", msg.Channel)
+	case "unsubscribe_channel":
+		b.unsubscribeClient(client.ID, key)
+		slog.Info("Client unsubscribed from data channel", logging.ClientID(client.ID), "channel", msg.Channel)
+	}
+}
+
+// channelSubscriptionKey is the subscription a client holds for a channel.
+// Topic names can't contain ':', so it never collides with a topic.
+func channelSubscriptionKey(name string) string {
+	return "channel:" + name
+}
@@ -0,0 +1,96 @@
+package websocket_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/nfrund/goby/internal/topicmgr"
+	ws "github.com/nfrund/goby/internal/websocket"
+)
+
+var scoreboardTopic = topicmgr.DefineModule(topicmgr.TopicConfig{
+	Name:        "test.scoreboard",
+	Module:      "test",
+	Description: "Live score updates",
+	Pattern:     "test.scoreboard",
+	Metadata:    map[string]interface{}{topicmgr.MetaPayloadFields: []string{"home", "away"}},
+})
+
+func TestBridge_RegisterChannel(t *testing.T) {
+	ps := newMockPubSub()
+	data := ws.NewBridge("data", ws.BridgeDependencies{Publisher: ps, Subscriber: ps})
+	html := ws.NewBridge("html", ws.BridgeDependencies{Publisher: ps, Subscriber: ps})
+
+	require.NoError(t, data.RegisterChannel(ws.DataChannel{Name: "scoreboard", Topic: scoreboardTopic}))
+	assert.Error(t, data.RegisterChannel(ws.DataChannel{Name: "scoreboard", Topic: scoreboardTopic}), "names are unique")
+	assert.Error(t, data.RegisterChannel(ws.DataChannel{Name: "Bad Name", Topic: scoreboardTopic}))
+	assert.Error(t, data.RegisterChannel(ws.DataChannel{Name: "untyped", Topic: newMockTopic("test.untyped")}),
+		"the topic must declare its payload fields")
+	assert.Error(t, html.RegisterChannel(ws.DataChannel{Name: "scoreboard", Topic: scoreboardTopic}),
+		"channels belong to the data endpoint")
+}
+
+func TestBridge_DataChannelUpdates(t *testing.T) {
+	ps := newMockPubSub()
+	ttm := NewTestTopicManager(t)
+	defer ttm.Cleanup()
+	readyTopic := newMockTopic("ws.ready")
+	require.NoError(t, ttm.Manager().RegisterAll(ws.TopicDataBroadcast, ws.TopicDataDirect, readyTopic, scoreboardTopic))
+
+	bridge := ws.NewBridge("data", ws.BridgeDependencies{
+		Publisher:    ps,
+		Subscriber:   ps,
+		TopicManager: ttm.Manager(),
+		ReadyTopic:   readyTopic,
+	})
+	require.NoError(t, bridge.RegisterChannel(ws.DataChannel{Name: "scoreboard", Topic: scoreboardTopic}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, bridge.Start(ctx))
+	assert.Error(t, bridge.RegisterChannel(ws.DataChannel{Name: "late", Topic: scoreboardTopic}),
+		"channels are registered before Start")
+
+	e := echo.New()
+	addAuthMiddleware(e)
+	e.GET("/ws/data", bridge.Handler())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws/data", &websocket.DialOptions{
+		HTTPHeader: http.Header{"Cookie": []string{"session=fake-session-for-testing"}},
+	})
+	require.NoError(t, err)
+	defer conn.Close(websocket.StatusNormalClosure, "test complete")
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"action":"subscribe_channel","channel":"scoreboard"}`)))
+	// Messages are handled in order, so once the unknown channel is refused
+	// the subscription is in place.
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"action":"subscribe_channel","channel":"weather"}`)))
+	var refusal ws.ErrorFrame
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &refusal))
+	assert.Equal(t, ws.ErrorCodeUnknownChannel, refusal.Code)
+
+	publish := func(payload string) {
+		require.NoError(t, ps.Publish(ctx, pubsub.Message{Topic: scoreboardTopic.Name(), Payload: []byte(payload)}))
+	}
+	publish(`{"home":1}`)
+	publish(`{"home":1,"away":0,"referee":"x"}`)
+	publish(`{"home":2,"away":1}`)
+
+	var update ws.ChannelUpdate
+	require.NoError(t, json.Unmarshal([]byte(readText(t, conn)), &update), "updates that don't match the schema are dropped")
+	assert.Equal(t, ws.ChannelUpdateType, update.Type)
+	assert.Equal(t, "scoreboard", update.Channel)
+	assert.Equal(t, 1, update.Version)
+	assert.JSONEq(t, `{"home":2,"away":1}`, string(update.Data))
+}
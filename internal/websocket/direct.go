@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// MetaKeyRecipientClientID addresses a direct message to one connection,
+// identified by its Client.ID, instead of every connection of the user in
+// "recipient_id", e.g. to answer only the tab that made a request. When both
+// are set, the client must belong to that user. Set it with ToClient.
+const MetaKeyRecipientClientID = "recipient_client_id"
+
+// ToClient returns msg addressed to the connection with ID clientID.
+func ToClient(msg pubsub.Message, clientID string) pubsub.Message {
+	metadata := make(map[string]string, len(msg.Metadata)+1)
+	maps.Copy(metadata, msg.Metadata)
+	metadata[MetaKeyRecipientClientID] = clientID
+	msg.Metadata = metadata
+	return msg
+}
+
+// sendToClient delivers a direct message to the single connection clientID.
+// Such messages are not buffered for replay, since history is kept per user
+// and would replay them to the user's other connections. If the connection
+// isn't here, the message goes to the undelivered fallback when it opted in
+// and no other instance delivers it.
+func (b *Bridge) sendToClient(ctx context.Context, msg pubsub.Message, clientID, recipientID string) {
+	client, ok := b.clients.Get(clientID)
+	if ok && client.Endpoint == b.endpoint {
+		if recipientID == "" || client.UserID == recipientID {
+			client.SendMessage(msg.Payload)
+			b.recordDelivered(ctx, msg)
+			return
+		}
+		slog.Warn("Direct message recipient client belongs to another user",
+			logging.Topic(msg.Topic),
+			logging.ClientID(clientID),
+			logging.UserID(recipientID),
+		)
+		return
+	}
+
+	slog.Debug("Recipient client of the direct message is not connected",
+		logging.ClientID(clientID),
+		"endpoint", b.endpoint,
+	)
+	if wantsUndeliveredFallback(msg) {
+		b.fallBack(ctx, msg, recipientID)
+	}
+}
@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBridge_DirectMessageToClient(t *testing.T) {
+	pub := &recordingPublisher{}
+	b := NewBridge("html", BridgeDependencies{Publisher: pub})
+	tabs := make([]*Client, 3)
+	for i, id := range []string{"tab1", "tab2", "tab3"} {
+		tabs[i] = &Client{ID: id, UserID: "alice", Send: make(chan []byte, 4), Endpoint: "html", dropped: &b.metrics.dropped}
+		b.clients.Add(tabs[i])
+	}
+	received := func(c *Client) int { return len(c.Send) }
+
+	t.Run("only the addressed client receives it", func(t *testing.T) {
+		msg := ToClient(pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte("<p>done</p>"),
+			Metadata: map[string]string{"recipient_id": "alice"},
+		}, "tab2")
+		require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+
+		assert.Equal(t, 0, received(tabs[0]))
+		assert.Equal(t, 1, received(tabs[1]))
+		assert.Equal(t, 0, received(tabs[2]))
+		assert.Equal(t, []byte("<p>done</p>"), <-tabs[1].Send)
+	})
+
+	t.Run("recipient_id alone reaches every client of the user", func(t *testing.T) {
+		msg := pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte("<p>all</p>"),
+			Metadata: map[string]string{"recipient_id": "alice"},
+		}
+		require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+
+		for _, tab := range tabs {
+			assert.Equal(t, []byte("<p>all</p>"), <-tab.Send)
+		}
+	})
+
+	t.Run("client of another user is not sent to", func(t *testing.T) {
+		msg := ToClient(pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte("<p>secret</p>"),
+			Metadata: map[string]string{"recipient_id": "bob"},
+		}, "tab1")
+		require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+
+		assert.Equal(t, 0, received(tabs[0]))
+	})
+
+	t.Run("unknown client falls back to undelivered", func(t *testing.T) {
+		msg := WithUndeliveredFallback(ToClient(pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte("<p>gone</p>"),
+			Metadata: map[string]string{"recipient_id": "alice"},
+		}, "tab9"))
+		require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+
+		require.Len(t, pub.messages, 1)
+		assert.Equal(t, TopicDirectUndelivered.Name(), pub.messages[0].Topic)
+		assert.Equal(t, "tab9", pub.messages[0].Metadata[MetaKeyRecipientClientID])
+	})
+}
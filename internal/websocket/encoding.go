@@ -0,0 +1,92 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+)
+
+// Encoding is the wire format of frames exchanged with a WebSocket client.
+type Encoding string
+
+const (
+	// EncodingJSON sends payloads unchanged as text frames. This is the default.
+	EncodingJSON Encoding = "json"
+	// EncodingCBOR transcodes JSON payloads to CBOR and sends them as binary
+	// frames. Inbound binary frames are decoded from CBOR back to JSON.
+	EncodingCBOR Encoding = "cbor"
+)
+
+// Subprotocols offered by the data endpoint when CBOR is enabled. Clients may
+// negotiate an encoding with Sec-WebSocket-Protocol or with ?encoding=cbor.
+const (
+	SubprotocolJSON = "goby.json"
+	SubprotocolCBOR = "goby.cbor"
+)
+
+// cborCodec is shared with the database layer's wire format.
+var cborCodec = surrealcbor.New()
+
+// negotiateEncoding picks the encoding for a new connection. The negotiated
+// subprotocol wins over the query parameter; anything unrecognized is JSON.
+func negotiateEncoding(subprotocol string, r *http.Request) Encoding {
+	switch subprotocol {
+	case SubprotocolCBOR:
+		return EncodingCBOR
+	case SubprotocolJSON:
+		return EncodingJSON
+	}
+	if Encoding(r.URL.Query().Get("encoding")) == EncodingCBOR {
+		return EncodingCBOR
+	}
+	return EncodingJSON
+}
+
+// encodeFrame converts an outbound payload to the client's encoding.
+// Payloads that are not valid JSON are sent to CBOR clients as a byte string.
+// Gzipped payloads are sent as they are, as binary frames, in either
+// encoding.
+func encodeFrame(enc Encoding, payload []byte) (websocket.MessageType, []byte, error) {
+	if isGzipped(payload) {
+		return websocket.MessageBinary, payload, nil
+	}
+	if enc != EncodingCBOR {
+		return websocket.MessageText, payload, nil
+	}
+
+	var value any = payload
+	if json.Valid(payload) {
+		var decoded any
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			return 0, nil, fmt.Errorf("failed to decode JSON payload: %w", err)
+		}
+		value = decoded
+	}
+
+	data, err := cborCodec.Marshal(value)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode CBOR frame: %w", err)
+	}
+	return websocket.MessageBinary, data, nil
+}
+
+// decodeFrame converts an inbound frame to the JSON the bridge handles.
+// Text frames are always treated as JSON so clients can mix formats.
+func decodeFrame(enc Encoding, msgType websocket.MessageType, frame []byte) ([]byte, error) {
+	if enc != EncodingCBOR || msgType != websocket.MessageBinary {
+		return frame, nil
+	}
+
+	var value any
+	if err := cborCodec.Unmarshal(frame, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode CBOR frame: %w", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CBOR frame to JSON: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	plain := httptest.NewRequest("GET", "/ws/data", nil)
+	query := httptest.NewRequest("GET", "/ws/data?encoding=cbor", nil)
+
+	assert.Equal(t, EncodingJSON, negotiateEncoding("", plain))
+	assert.Equal(t, EncodingCBOR, negotiateEncoding("", query))
+	assert.Equal(t, EncodingCBOR, negotiateEncoding(SubprotocolCBOR, plain))
+	assert.Equal(t, EncodingJSON, negotiateEncoding(SubprotocolJSON, query), "subprotocol wins over query")
+}
+
+func TestEncodeDecodeFrame(t *testing.T) {
+	payload := []byte(`{"action":"game.move","payload":{"x":1,"tags":["a","b"],"ok":true}}`)
+
+	t.Run("json passes through as text", func(t *testing.T) {
+		msgType, frame, err := encodeFrame(EncodingJSON, payload)
+		require.NoError(t, err)
+		assert.Equal(t, websocket.MessageText, msgType)
+		assert.Equal(t, payload, frame)
+
+		decoded, err := decodeFrame(EncodingJSON, websocket.MessageBinary, frame)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+	})
+
+	t.Run("cbor round trips", func(t *testing.T) {
+		msgType, frame, err := encodeFrame(EncodingCBOR, payload)
+		require.NoError(t, err)
+		assert.Equal(t, websocket.MessageBinary, msgType)
+		assert.NotEqual(t, payload, frame)
+
+		decoded, err := decodeFrame(EncodingCBOR, msgType, frame)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(payload), string(decoded))
+	})
+
+	t.Run("cbor clients may still send text frames", func(t *testing.T) {
+		decoded, err := decodeFrame(EncodingCBOR, websocket.MessageText, payload)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+	})
+
+	t.Run("invalid cbor is rejected", func(t *testing.T) {
+		_, err := decodeFrame(EncodingCBOR, websocket.MessageBinary, []byte{0xff, 0x00})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,37 @@
+package websocket
+
+import "sync"
+
+// fanOutChunk is the fewest clients a broadcast goroutine is given. Smaller
+// broadcasts are delivered inline, where starting goroutines would cost more
+// than it saves.
+const fanOutChunk = 256
+
+// fanOut calls send for every client, split over at most b.fanOutWorkers
+// goroutines, and returns once all are done. Each client is handled by a
+// single goroutine, so a client still receives a broadcast's messages in
+// order; send must not block, or the clients after it in the same chunk
+// wait.
+func (b *Bridge) fanOut(clients []*Client, send func(*Client)) {
+	workers := min(b.fanOutWorkers, (len(clients)+fanOutChunk-1)/fanOutChunk)
+	if workers <= 1 {
+		for _, client := range clients {
+			send(client)
+		}
+		return
+	}
+
+	chunk := (len(clients) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(clients); start += chunk {
+		part := clients[start:min(start+chunk, len(clients))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, client := range part {
+				send(client)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addClients registers n clients on b with send queues of the given size.
+func addClients(b *Bridge, n, queue int) []*Client {
+	clients := make([]*Client, n)
+	for i := range clients {
+		clients[i] = &Client{
+			ID:       fmt.Sprintf("c%d", i),
+			UserID:   fmt.Sprintf("user%d", i),
+			Send:     make(chan []byte, queue),
+			Endpoint: b.endpoint,
+			dropped:  &b.metrics.dropped,
+		}
+		b.clients.Add(clients[i])
+	}
+	return clients
+}
+
+func TestBridge_BroadcastNotHeldUpByStuckClients(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{BroadcastWorkers: 4})
+	clients := addClients(b, 4*fanOutChunk, 1)
+
+	// One client is mid-close and another has a full queue.
+	closing, full := clients[0], clients[len(clients)/2]
+	closing.mu.Lock()
+	defer closing.mu.Unlock()
+	full.Send <- []byte("backlog")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, b.handleBroadcast(context.Background(), pubsub.Message{Payload: []byte("hello")}))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("broadcast blocked on a stuck client")
+	}
+
+	for _, client := range clients {
+		if client == closing || client == full {
+			continue
+		}
+		require.Len(t, client.Send, 1, client.ID)
+		assert.Equal(t, "hello", string(<-client.Send))
+	}
+	assert.Equal(t, "backlog", string(<-full.Send))
+	assert.Empty(t, full.Send)
+	assert.Equal(t, uint64(1), b.Metrics().DroppedMessages)
+}
+
+func TestBridge_FanOutCoversEveryClientOnce(t *testing.T) {
+	for _, n := range []int{0, 1, fanOutChunk, 3*fanOutChunk + 7} {
+		b := NewBridge("data", BridgeDependencies{BroadcastWorkers: 4})
+		clients := addClients(b, n, 1)
+
+		b.fanOut(clients, func(client *Client) { client.SendMessage([]byte("x")) })
+		for _, client := range clients {
+			assert.Len(t, client.Send, 1, "%d clients: %s", n, client.ID)
+		}
+	}
+}
+
+// BenchmarkBridge_Broadcast measures how long one broadcast takes to reach
+// every client's queue, delivered serially and over GOMAXPROCS goroutines.
+// The parallel fan-out only wins with more than one CPU.
+func BenchmarkBridge_Broadcast(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		for _, workers := range []int{1, 0} {
+			name := fmt.Sprintf("clients=%d/serial", n)
+			if workers == 0 {
+				name = fmt.Sprintf("clients=%d/parallel", n)
+			}
+			b.Run(name, func(b *testing.B) {
+				bridge := NewBridge("data", BridgeDependencies{BroadcastWorkers: workers})
+				clients := addClients(bridge, n, defaultSendBufferSize)
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				for _, client := range clients {
+					go func() {
+						for {
+							select {
+							case <-client.Send:
+							case <-ctx.Done():
+								return
+							}
+						}
+					}()
+				}
+				msg := pubsub.Message{Payload: []byte(`{"type":"tick"}`)}
+
+				b.ResetTimer()
+				for range b.N {
+					_ = bridge.handleBroadcast(ctx, msg)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+const (
+	// MetaKeyHistoryTopic marks a message as belonging to a logical topic whose
+	// recent messages should be retained for replay to reconnecting clients.
+	// Only topics enabled with Bridge.EnableHistory are buffered.
+	MetaKeyHistoryTopic = "history_topic"
+	// MetaKeySequence carries the monotonic sequence number the bridge assigns
+	// to buffered messages.
+	MetaKeySequence = "seq"
+
+	// defaultHistorySize is the number of messages retained per user and topic
+	// when no size is configured.
+	defaultHistorySize = 50
+
+	// broadcastHistoryKey is the buffer owner for broadcast messages, which are
+	// replayed to every reconnecting user.
+	broadcastHistoryKey = ""
+
+	// historyFrameType is the type of the envelope history-buffered messages
+	// are delivered in.
+	historyFrameType = "ws.history"
+
+	// resumeWindow is how long a disconnected client's resume point is kept.
+	// It matches the window in which only its user can reclaim the client ID
+	// the point is stored under.
+	resumeWindow = clientIDReclaimWindow
+)
+
+// HistoryFrame is the envelope history-buffered messages are delivered in,
+// live and on replay:
+//
+//	{"type":"ws.history","topic":"alerts","seq":42,"data":{"text":"Disk full"}}
+//
+// Data is the published payload: embedded as is when it is JSON, otherwise,
+// such as for HTML fragments, as a JSON string. Clients keep the highest Seq
+// they received and send it back as ?last_seq= when they reconnect.
+type HistoryFrame struct {
+	Type  string          `json:"type"`
+	Topic string          `json:"topic"`
+	Seq   uint64          `json:"seq"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// newHistoryFrame wraps payload in a HistoryFrame.
+func newHistoryFrame(topic string, seq uint64, payload []byte) []byte {
+	data := json.RawMessage(payload)
+	if !json.Valid(payload) {
+		data, _ = json.Marshal(string(payload))
+	}
+	frame, _ := json.Marshal(HistoryFrame{
+		Type:  historyFrameType,
+		Topic: topic,
+		Seq:   seq,
+		Data:  data,
+	})
+	return frame
+}
+
+// historyEntry is a single buffered message.
+type historyEntry struct {
+	seq     uint64
+	payload []byte
+}
+
+// historyRing is a fixed-size ring buffer of entries, oldest first.
+type historyRing struct {
+	entries []historyEntry
+	start   int
+	count   int
+}
+
+func newHistoryRing(size int) *historyRing {
+	return &historyRing{entries: make([]historyEntry, size)}
+}
+
+func (r *historyRing) push(e historyEntry) {
+	size := len(r.entries)
+	if r.count < size {
+		r.entries[(r.start+r.count)%size] = e
+		r.count++
+		return
+	}
+	// Buffer full: overwrite the oldest entry.
+	r.entries[r.start] = e
+	r.start = (r.start + 1) % size
+}
+
+func (r *historyRing) after(seq uint64, out []historyEntry) []historyEntry {
+	for i := 0; i < r.count; i++ {
+		e := r.entries[(r.start+i)%len(r.entries)]
+		if e.seq > seq {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// resumePoint is the last sequence delivered to a client before it
+// disconnected.
+type resumePoint struct {
+	userID         string
+	seq            uint64
+	disconnectedAt time.Time
+}
+
+// messageHistory retains the last N messages per user and opted-in topic so
+// that reconnecting clients can catch up on what they missed. A user's
+// buffers are dropped once they have had no connected client for the resume
+// window, so memory is bounded by the users seen recently.
+//
+// A single mutex serializes recording+delivery against client attach+replay,
+// which guarantees a reconnecting client sees each buffered message exactly
+// once and in sequence order.
+type messageHistory struct {
+	mu        sync.Mutex
+	size      int
+	topics    map[string]struct{}
+	seq       uint64
+	buffers   map[string]map[string]*historyRing // userID -> topic -> ring
+	connected map[string]int                     // userID -> attached clients
+	idleSince map[string]time.Time               // userID -> time its last client left
+	resume    map[string]resumePoint             // clientID -> resume point
+	lastSweep time.Time
+}
+
+func newMessageHistory(size int) *messageHistory {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &messageHistory{
+		size:      size,
+		topics:    make(map[string]struct{}),
+		buffers:   make(map[string]map[string]*historyRing),
+		connected: make(map[string]int),
+		idleSince: make(map[string]time.Time),
+		resume:    make(map[string]resumePoint),
+	}
+}
+
+// enable opts a logical topic into buffering.
+func (h *messageHistory) enable(topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topics[topic] = struct{}{}
+}
+
+// topicFor returns the history topic of msg if buffering is enabled for it.
+func (h *messageHistory) topicFor(msg pubsub.Message) (string, bool) {
+	topic := msg.Metadata[MetaKeyHistoryTopic]
+	if topic == "" {
+		return "", false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.topics[topic]
+	return topic, ok
+}
+
+// recordLocked assigns the next sequence to msg, stamps it into the message
+// metadata, wraps the payload in a HistoryFrame and stores the frame for
+// owner. The caller must hold h.mu.
+func (h *messageHistory) recordLocked(owner, topic string, msg *pubsub.Message, now time.Time) uint64 {
+	h.sweepLocked(now)
+	h.seq++
+	seq := h.seq
+
+	metadata := make(map[string]string, len(msg.Metadata)+1)
+	for k, v := range msg.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetaKeySequence] = strconv.FormatUint(seq, 10)
+	msg.Metadata = metadata
+	msg.Payload = newHistoryFrame(topic, seq, msg.Payload)
+
+	topics, ok := h.buffers[owner]
+	if !ok {
+		topics = make(map[string]*historyRing)
+		h.buffers[owner] = topics
+		// Messages for a user with no connected client are kept for one
+		// resume window, as if they had just disconnected.
+		if owner != broadcastHistoryKey && h.connected[owner] == 0 {
+			h.idleSince[owner] = now
+		}
+	}
+	ring, ok := topics[topic]
+	if !ok {
+		ring = newHistoryRing(h.size)
+		topics[topic] = ring
+	}
+	ring.push(historyEntry{seq: seq, payload: msg.Payload})
+	return seq
+}
+
+// sinceLocked returns the buffered messages for userID (including broadcasts)
+// with a sequence greater than seq, ordered by sequence. The caller must hold h.mu.
+func (h *messageHistory) sinceLocked(userID string, seq uint64) []historyEntry {
+	var out []historyEntry
+	for _, owner := range []string{userID, broadcastHistoryKey} {
+		for _, ring := range h.buffers[owner] {
+			out = ring.after(seq, out)
+		}
+		if userID == broadcastHistoryKey {
+			break
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].seq < out[j].seq })
+	return out
+}
+
+// attachLocked counts a newly connected client of userID, which keeps the
+// user's buffers from being evicted. The caller must hold h.mu.
+func (h *messageHistory) attachLocked(userID string) {
+	h.connected[userID]++
+	delete(h.idleSince, userID)
+}
+
+// markDisconnected remembers the last sequence delivered to a client so that
+// a later connection reclaiming its ID can resume from that point. When it
+// was the user's last client, the user's buffers start to idle.
+func (h *messageHistory) markDisconnected(clientID, userID string, seq uint64, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweepLocked(now)
+	h.resume[clientID] = resumePoint{userID: userID, seq: seq, disconnectedAt: now}
+	if h.connected[userID] > 1 {
+		h.connected[userID]--
+		return
+	}
+	delete(h.connected, userID)
+	h.idleSince[userID] = now
+}
+
+// takeResumeLocked returns and forgets the resume point stored for clientID,
+// if userID left it within the resume window. The caller must hold h.mu.
+func (h *messageHistory) takeResumeLocked(clientID, userID string, now time.Time) (uint64, bool) {
+	point, ok := h.resume[clientID]
+	if !ok {
+		return 0, false
+	}
+	delete(h.resume, clientID)
+	if point.userID != userID || now.Sub(point.disconnectedAt) >= resumeWindow {
+		return 0, false
+	}
+	return point.seq, true
+}
+
+// sweepLocked drops expired resume points, and the buffers of users that
+// have been idle for the resume window, at most once per resume window. The
+// caller must hold h.mu.
+func (h *messageHistory) sweepLocked(now time.Time) {
+	if now.Sub(h.lastSweep) < resumeWindow {
+		return
+	}
+	h.lastSweep = now
+	for id, point := range h.resume {
+		if now.Sub(point.disconnectedAt) >= resumeWindow {
+			delete(h.resume, id)
+		}
+	}
+	for userID, since := range h.idleSince {
+		if now.Sub(since) >= resumeWindow {
+			delete(h.buffers, userID)
+			delete(h.idleSince, userID)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryRing_KeepsLastN(t *testing.T) {
+	ring := newHistoryRing(3)
+	for seq := uint64(1); seq <= 5; seq++ {
+		ring.push(historyEntry{seq: seq})
+	}
+
+	entries := ring.after(0, nil)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []uint64{3, 4, 5}, []uint64{entries[0].seq, entries[1].seq, entries[2].seq})
+
+	entries = ring.after(4, nil)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(5), entries[0].seq)
+}
+
+func TestMessageHistory_RecordAndSince(t *testing.T) {
+	h := newMessageHistory(2)
+	h.enable("alerts")
+
+	msg := pubsub.Message{Payload: []byte("a"), Metadata: map[string]string{MetaKeyHistoryTopic: "alerts"}}
+	topic, ok := h.topicFor(msg)
+	require.True(t, ok)
+
+	now := time.Now()
+	h.mu.Lock()
+	seq1 := h.recordLocked("alice", topic, &msg, now)
+	broadcast := pubsub.Message{Payload: []byte("b")}
+	seq2 := h.recordLocked(broadcastHistoryKey, topic, &broadcast, now)
+	other := pubsub.Message{Payload: []byte("c")}
+	h.recordLocked("bob", topic, &other, now)
+	entries := h.sinceLocked("alice", 0)
+	h.mu.Unlock()
+
+	assert.Equal(t, "1", msg.Metadata[MetaKeySequence], "sequence is stamped into metadata")
+	assert.JSONEq(t, `{"type":"ws.history","topic":"alerts","seq":1,"data":"a"}`, string(msg.Payload))
+	require.Len(t, entries, 2, "alice sees her own and broadcast messages, not bob's")
+	assert.Equal(t, seq1, entries[0].seq)
+	assert.Equal(t, seq2, entries[1].seq)
+
+	_, ok = h.topicFor(pubsub.Message{Metadata: map[string]string{MetaKeyHistoryTopic: "chatter"}})
+	assert.False(t, ok, "topics must be opted in")
+	_, ok = h.topicFor(pubsub.Message{})
+	assert.False(t, ok)
+}
+
+func TestMessageHistory_ResumePoints(t *testing.T) {
+	h := newMessageHistory(2)
+	now := time.Now()
+	h.markDisconnected("tab-1", "alice", 3, now)
+	h.markDisconnected("tab-2", "alice", 7, now)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, ok := h.takeResumeLocked("tab-1", "bob", now)
+	assert.False(t, ok, "another user cannot resume from alice's point")
+
+	seq, ok := h.takeResumeLocked("tab-2", "alice", now)
+	require.True(t, ok, "each client keeps its own resume point")
+	assert.Equal(t, uint64(7), seq)
+	_, ok = h.takeResumeLocked("tab-2", "alice", now)
+	assert.False(t, ok, "a resume point is used once")
+
+	h.resume["tab-3"] = resumePoint{userID: "alice", seq: 1, disconnectedAt: now}
+	_, ok = h.takeResumeLocked("tab-3", "alice", now.Add(resumeWindow))
+	assert.False(t, ok, "resume points expire")
+
+	h.resume["tab-4"] = resumePoint{userID: "alice", seq: 1, disconnectedAt: now}
+	h.sweepLocked(now.Add(2 * resumeWindow))
+	assert.Empty(t, h.resume, "expired resume points are swept")
+}
+
+func TestMessageHistory_EvictsIdleUsers(t *testing.T) {
+	h := newMessageHistory(2)
+	now := time.Now()
+	record := func(owner string, at time.Time) {
+		msg := pubsub.Message{Payload: []byte("x")}
+		h.mu.Lock()
+		h.recordLocked(owner, "alerts", &msg, at)
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	h.attachLocked("alice")
+	h.attachLocked("alice")
+	h.mu.Unlock()
+	record("alice", now)
+	record("bob", now) // bob is offline
+	record(broadcastHistoryKey, now)
+
+	h.markDisconnected("tab-1", "alice", 3, now)
+	record("carol", now.Add(resumeWindow)) // sweeps
+	assert.Contains(t, h.buffers, "alice", "a user with a connected client is kept")
+	assert.NotContains(t, h.buffers, "bob", "messages for an offline user expire")
+	assert.Contains(t, h.buffers, broadcastHistoryKey, "broadcasts are never evicted")
+
+	h.markDisconnected("tab-2", "alice", 3, now.Add(resumeWindow*3/2))
+	record("carol", now.Add(2*resumeWindow))
+	assert.Contains(t, h.buffers, "alice", "alice has a resume window after her last client leaves")
+
+	h.mu.Lock()
+	h.attachLocked("carol")
+	h.mu.Unlock()
+	record("dave", now.Add(3*resumeWindow))
+	assert.NotContains(t, h.buffers, "alice")
+	assert.Contains(t, h.buffers, "carol")
+	assert.Empty(t, h.resume)
+}
+
+func TestBridge_ResumeAfterFullSendBuffer(t *testing.T) {
+	b := NewBridge("html", BridgeDependencies{Publisher: &recordingPublisher{}})
+	b.EnableHistory("alerts")
+
+	send := func(text string) {
+		t.Helper()
+		require.NoError(t, b.handleDirectMessage(context.Background(), pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte(text),
+			Metadata: map[string]string{"recipient_id": "alice", MetaKeyHistoryTopic: "alerts"},
+		}))
+	}
+	frameData := func(frame []byte) string {
+		t.Helper()
+		var f HistoryFrame
+		require.NoError(t, json.Unmarshal(frame, &f))
+		var data string
+		require.NoError(t, json.Unmarshal(f.Data, &data))
+		return data
+	}
+
+	// The client's queue holds two messages; the third and fourth are dropped.
+	client := &Client{ID: "tab-1", UserID: "alice", Endpoint: "html", Send: make(chan []byte, 2)}
+	b.attachClient(client, "", "")
+	for _, text := range []string{"m1", "m2", "m3", "m4"} {
+		send(text)
+	}
+	assert.Equal(t, "m1", frameData(<-client.Send))
+	assert.Equal(t, "m2", frameData(<-client.Send))
+	assert.Equal(t, uint64(2), client.lastSeq.Load(), "dropped messages don't advance the resume point")
+
+	b.detachClient(client)
+
+	resumed := &Client{ID: "tab-1", UserID: "alice", Endpoint: "html", Send: make(chan []byte, 1)}
+	b.attachClient(resumed, "", "true")
+	assert.Equal(t, "m3", frameData(<-resumed.Send))
+	assert.True(t, resumed.seqGap.Load(), "m4 did not fit in the queue either")
+	assert.Equal(t, uint64(3), resumed.lastSeq.Load(), "the resume point stops at the last queued replay")
+
+	b.detachClient(resumed)
+
+	again := &Client{ID: "tab-1", UserID: "alice", Endpoint: "html", Send: make(chan []byte, 4)}
+	b.attachClient(again, "", "true")
+	assert.Equal(t, "m4", frameData(<-again.Send))
+	assert.Equal(t, uint64(4), again.lastSeq.Load())
+}
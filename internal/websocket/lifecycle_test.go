@@ -0,0 +1,105 @@
+package websocket_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ws "github.com/nfrund/goby/internal/websocket"
+)
+
+func TestBridge_EndpointLifecycleTopics(t *testing.T) {
+	ps := newMockPubSub()
+	e := echo.New()
+	addAuthMiddleware(e)
+	for _, endpoint := range []string{"html", "data"} {
+		ready, disconnected := ws.ClientLifecycleTopics(endpoint)
+		b := ws.NewBridge(endpoint, ws.BridgeDependencies{
+			Publisher:         ps,
+			Subscriber:        ps,
+			ReadyTopic:        ready,
+			DisconnectedTopic: disconnected,
+		})
+		e.GET("/ws/"+endpoint, b.Handler())
+	}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	for _, endpoint := range []string{"html", "data"} {
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + endpoint
+		conn, _, err := websocket.Dial(context.Background(), wsURL, nil)
+		require.NoError(t, err)
+		conn.Close(websocket.StatusNormalClosure, "leaving")
+	}
+
+	for _, endpoint := range []string{"html", "data"} {
+		ready, disconnected := ws.ClientLifecycleTopics(endpoint)
+		require.Eventually(t, func() bool {
+			return len(ps.getMessages(ready.Name())) == 1 && len(ps.getMessages(disconnected.Name())) == 1
+		}, time.Second, 10*time.Millisecond, "lifecycle events of %s", endpoint)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(ps.getMessages(disconnected.Name())[0].Payload, &payload))
+		assert.Equal(t, endpoint, payload["endpoint"])
+	}
+	assert.Equal(t, "ws.html.client.disconnected", ws.TopicHTMLClientDisconnected.Name())
+	assert.Equal(t, "ws.data.client.disconnected", ws.TopicDataClientDisconnected.Name())
+	assert.Empty(t, ps.getMessages(ws.TopicClientReady.Name()), "the shared topics are not used")
+	assert.Empty(t, ps.getMessages(ws.TopicClientDisconnected.Name()))
+}
+
+func TestBridge_DefaultLifecycleTopics(t *testing.T) {
+	ps := newMockPubSub()
+	b := ws.NewBridge("data", ws.BridgeDependencies{Publisher: ps, Subscriber: ps})
+	e := echo.New()
+	addAuthMiddleware(e)
+	e.GET("/ws/data", b.Handler())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/ws/data", nil)
+	require.NoError(t, err)
+	conn.Close(websocket.StatusNormalClosure, "leaving")
+
+	require.Eventually(t, func() bool {
+		return len(ps.getMessages(ws.TopicClientReady.Name())) == 1 && len(ps.getMessages(ws.TopicClientDisconnected.Name())) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBridge_SharedLifecycleAlsoPublishesToSharedTopics(t *testing.T) {
+	ps := newMockPubSub()
+	ready, disconnected := ws.ClientLifecycleTopics("html")
+	b := ws.NewBridge("html", ws.BridgeDependencies{
+		Publisher:         ps,
+		Subscriber:        ps,
+		ReadyTopic:        ready,
+		DisconnectedTopic: disconnected,
+		SharedLifecycle:   true,
+	})
+	e := echo.New()
+	addAuthMiddleware(e)
+	e.GET("/ws/html", b.Handler())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/ws/html", nil)
+	require.NoError(t, err)
+	conn.Close(websocket.StatusNormalClosure, "leaving")
+
+	require.Eventually(t, func() bool {
+		for _, topic := range []string{ready.Name(), disconnected.Name(), ws.TopicClientReady.Name(), ws.TopicClientDisconnected.Name()} {
+			if len(ps.getMessages(topic)) != 1 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
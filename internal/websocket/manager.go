@@ -62,6 +62,15 @@ func (m *ClientManager) Remove(clientID string) {
 	}
 }
 
+// Get returns the connected client with the given ID.
+func (m *ClientManager) Get(clientID string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	client, ok := m.clients[clientID]
+	return client, ok
+}
+
 // GetByUser returns all clients for a given user ID.
 func (m *ClientManager) GetByUser(userID string) []*Client {
 	m.mu.RLock()
@@ -97,6 +106,10 @@ func (m *ClientManager) CloseAll() {
 	defer m.mu.Unlock()
 
 	for _, client := range m.clients {
-		client.Conn.Close(websocket.StatusGoingAway, "Server is shutting down")
+		// Event-stream clients have no connection; their handlers end when
+		// the bridge's context is cancelled.
+		if client.Conn != nil {
+			client.Conn.Close(websocket.StatusGoingAway, "Server is shutting down")
+		}
 	}
 }
@@ -0,0 +1,246 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Bucket upper bounds for the bridge histograms. They are fixed so that
+// recording an observation is a handful of atomic adds with no allocation.
+var (
+	// connectionDurationBuckets are in seconds, from one second to one day.
+	connectionDurationBuckets = []float64{1, 5, 30, 60, 300, 900, 1800, 3600, 14400, 86400}
+	// messageSizeBuckets are in bytes, from 64 B to 1 MiB.
+	messageSizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+)
+
+// histogram is a lock-free, pre-bucketed histogram. counts holds one slot per
+// bound plus a final +Inf slot; counts are not cumulative until snapshotted.
+type histogram struct {
+	bounds  []float64
+	counts  []atomic.Uint64
+	count   atomic.Uint64
+	sumBits atomic.Uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// observe records a single value.
+func (h *histogram) observe(v float64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sumBits.Load()
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// HistogramBucket is a cumulative bucket: Count observations were <= UpperBound.
+type HistogramBucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// HistogramSnapshot is a point-in-time copy of a histogram. The +Inf bucket
+// is implied by Count.
+type HistogramSnapshot struct {
+	Buckets []HistogramBucket `json:"buckets"`
+	Count   uint64            `json:"count"`
+	Sum     float64           `json:"sum"`
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{Buckets: make([]HistogramBucket, len(h.bounds))}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		s.Buckets[i] = HistogramBucket{UpperBound: bound, Count: cumulative}
+	}
+	// Count covers the +Inf bucket. It is read after the buckets so it is
+	// never smaller than the last cumulative bucket.
+	s.Count = max(h.count.Load(), cumulative)
+	s.Sum = math.Float64frombits(h.sumBits.Load())
+	return s
+}
+
+// bridgeMetrics holds the counters recorded by a bridge's pumps.
+type bridgeMetrics struct {
+	connectionsTotal    atomic.Uint64
+	connectionsRejected atomic.Uint64
+	rateLimited         atomic.Uint64
+	dropped             atomic.Uint64
+	connectionDuration  *histogram
+	messageSize         *histogram
+}
+
+func newBridgeMetrics() *bridgeMetrics {
+	return &bridgeMetrics{
+		connectionDuration: newHistogram(connectionDurationBuckets),
+		messageSize:        newHistogram(messageSizeBuckets),
+	}
+}
+
+// BridgeMetrics is a snapshot of a bridge's connection and message metrics.
+type BridgeMetrics struct {
+	Endpoint string `json:"endpoint"`
+	// ActiveConnections is the number of currently connected clients.
+	ActiveConnections int `json:"active_connections"`
+	// ConnectionsTotal counts every accepted connection since startup.
+	ConnectionsTotal uint64 `json:"connections_total"`
+	// ConnectionsRejected counts connections refused because the shared
+	// ConnectionLimiter was at its cap.
+	ConnectionsRejected uint64 `json:"connections_rejected"`
+	// ConnectionLimit is the shared connection cap, 0 if there is none.
+	ConnectionLimit int `json:"connection_limit"`
+	// RateLimitedMessages counts inbound messages dropped by the per-client
+	// rate limiter.
+	RateLimitedMessages uint64 `json:"rate_limited_messages"`
+	// DroppedMessages counts outbound messages dropped because a client's
+	// send queue was full.
+	DroppedMessages uint64 `json:"dropped_messages"`
+	// ConnectionDuration is the lifetime of closed connections in seconds.
+	ConnectionDuration HistogramSnapshot `json:"connection_duration_seconds"`
+	// MessageSize is the size in bytes of frames written to clients.
+	MessageSize HistogramSnapshot `json:"message_size_bytes"`
+	// SendBufferSize and WriteTimeout are the effective per-client queue
+	// capacity and write deadline.
+	SendBufferSize int     `json:"send_buffer_size"`
+	WriteTimeout   float64 `json:"write_timeout_seconds"`
+}
+
+// Metrics returns a snapshot of the bridge's metrics.
+func (b *Bridge) Metrics() BridgeMetrics {
+	return BridgeMetrics{
+		Endpoint:            b.endpoint,
+		ActiveConnections:   len(b.clients.GetAll()),
+		ConnectionsTotal:    b.metrics.connectionsTotal.Load(),
+		ConnectionsRejected: b.metrics.connectionsRejected.Load(),
+		ConnectionLimit:     b.connLimit.Max(),
+		RateLimitedMessages: b.metrics.rateLimited.Load(),
+		DroppedMessages:     b.metrics.dropped.Load(),
+		ConnectionDuration:  b.metrics.connectionDuration.snapshot(),
+		MessageSize:         b.metrics.messageSize.snapshot(),
+		SendBufferSize:      b.sendBufferSize,
+		WriteTimeout:        b.writeTimeout.Seconds(),
+	}
+}
+
+// recordConnect counts a newly accepted connection.
+func (b *Bridge) recordConnect() {
+	b.metrics.connectionsTotal.Add(1)
+}
+
+// recordDisconnect records how long a connection lived.
+func (b *Bridge) recordDisconnect(client *Client) {
+	if client.connectedAt.IsZero() {
+		return
+	}
+	b.metrics.connectionDuration.observe(time.Since(client.connectedAt).Seconds())
+}
+
+// recordMessageSize records the size of an outbound frame.
+func (b *Bridge) recordMessageSize(n int) {
+	b.metrics.messageSize.observe(float64(n))
+}
+
+// WritePrometheus renders the metrics of the given bridges in the Prometheus
+// text exposition format, labelled by endpoint.
+func WritePrometheus(w io.Writer, bridges ...*Bridge) error {
+	snapshots := make([]BridgeMetrics, 0, len(bridges))
+	for _, b := range bridges {
+		if b != nil {
+			snapshots = append(snapshots, b.Metrics())
+		}
+	}
+
+	pw := &promWriter{w: w}
+	pw.family("goby_websocket_active_connections", "gauge", "Currently connected WebSocket clients.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_active_connections", m.Endpoint, "", float64(m.ActiveConnections))
+	}
+	pw.family("goby_websocket_connections_total", "counter", "WebSocket connections accepted since startup.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_connections_total", m.Endpoint, "", float64(m.ConnectionsTotal))
+	}
+	pw.family("goby_websocket_connections_rejected_total", "counter", "Connections refused because the server-wide connection limit was reached.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_connections_rejected_total", m.Endpoint, "", float64(m.ConnectionsRejected))
+	}
+	pw.family("goby_websocket_rate_limited_messages_total", "counter", "Inbound client messages dropped by the rate limiter.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_rate_limited_messages_total", m.Endpoint, "", float64(m.RateLimitedMessages))
+	}
+	pw.family("goby_websocket_dropped_messages_total", "counter", "Outbound messages dropped because a client's send queue was full.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_dropped_messages_total", m.Endpoint, "", float64(m.DroppedMessages))
+	}
+	pw.family("goby_websocket_connection_duration_seconds", "histogram", "Lifetime of closed WebSocket connections.")
+	for _, m := range snapshots {
+		pw.histogram("goby_websocket_connection_duration_seconds", m.Endpoint, m.ConnectionDuration)
+	}
+	pw.family("goby_websocket_message_size_bytes", "histogram", "Size of frames written to WebSocket clients.")
+	for _, m := range snapshots {
+		pw.histogram("goby_websocket_message_size_bytes", m.Endpoint, m.MessageSize)
+	}
+	pw.family("goby_websocket_send_buffer_size", "gauge", "Outbound messages queued per client before messages are dropped.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_send_buffer_size", m.Endpoint, "", float64(m.SendBufferSize))
+	}
+	pw.family("goby_websocket_write_timeout_seconds", "gauge", "Deadline for each write to a WebSocket client.")
+	for _, m := range snapshots {
+		pw.sample("goby_websocket_write_timeout_seconds", m.Endpoint, "", m.WriteTimeout)
+	}
+	return pw.err
+}
+
+// promWriter writes exposition lines and keeps the first write error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) family(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) sample(name, endpoint, le string, v float64) {
+	if le != "" {
+		p.printf("%s{endpoint=%q,le=%q} %s\n", name, endpoint, le, formatFloat(v))
+		return
+	}
+	p.printf("%s{endpoint=%q} %s\n", name, endpoint, formatFloat(v))
+}
+
+func (p *promWriter) histogram(name, endpoint string, h HistogramSnapshot) {
+	for _, bucket := range h.Buckets {
+		p.sample(name+"_bucket", endpoint, formatFloat(bucket.UpperBound), float64(bucket.Count))
+	}
+	p.sample(name+"_bucket", endpoint, "+Inf", float64(h.Count))
+	p.sample(name+"_sum", endpoint, "", h.Sum)
+	p.sample(name+"_count", endpoint, "", float64(h.Count))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,65 @@
+package websocket
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram_Snapshot(t *testing.T) {
+	h := newHistogram([]float64{10, 100})
+	for _, v := range []float64{1, 10, 50, 500} {
+		h.observe(v)
+	}
+
+	s := h.snapshot()
+	assert.Equal(t, []HistogramBucket{{UpperBound: 10, Count: 2}, {UpperBound: 100, Count: 3}}, s.Buckets)
+	assert.Equal(t, uint64(4), s.Count)
+	assert.Equal(t, 561.0, s.Sum)
+}
+
+func TestWritePrometheus(t *testing.T) {
+	b := NewBridge("html", BridgeDependencies{})
+	b.recordConnect()
+	b.recordMessageSize(200)
+	b.recordDisconnect(&Client{connectedAt: time.Now().Add(-2 * time.Second)})
+
+	var out strings.Builder
+	require.NoError(t, WritePrometheus(&out, b, nil))
+	text := out.String()
+
+	assert.Contains(t, text, "# TYPE goby_websocket_message_size_bytes histogram")
+	assert.Contains(t, text, `goby_websocket_connections_total{endpoint="html"} 1`)
+	assert.Contains(t, text, `goby_websocket_connections_rejected_total{endpoint="html"} 0`)
+	assert.Contains(t, text, `goby_websocket_message_size_bytes_bucket{endpoint="html",le="64"} 0`)
+	assert.Contains(t, text, `goby_websocket_message_size_bytes_bucket{endpoint="html",le="256"} 1`)
+	assert.Contains(t, text, `goby_websocket_message_size_bytes_bucket{endpoint="html",le="+Inf"} 1`)
+	assert.Contains(t, text, `goby_websocket_connection_duration_seconds_bucket{endpoint="html",le="1"} 0`)
+	assert.Contains(t, text, `goby_websocket_connection_duration_seconds_bucket{endpoint="html",le="5"} 1`)
+	assert.Contains(t, text, `goby_websocket_connection_duration_seconds_count{endpoint="html"} 1`)
+	assert.Contains(t, text, `goby_websocket_send_buffer_size{endpoint="html"} 256`)
+	assert.Contains(t, text, `goby_websocket_write_timeout_seconds{endpoint="html"} 10`)
+}
+
+func TestNewBridge_SendBufferAndWriteTimeout(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{SendBufferSize: 1024, WriteTimeout: 30 * time.Second})
+	m := b.Metrics()
+	assert.Equal(t, 1024, m.SendBufferSize)
+	assert.Equal(t, 30.0, m.WriteTimeout)
+
+	invalid := BridgeDependencies{SendBufferSize: -1, WriteTimeout: 10 * time.Millisecond}
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "send buffer size -1")
+	assert.Contains(t, err.Error(), "write timeout 10ms")
+
+	// Invalid settings fall back to the defaults rather than breaking clients.
+	m = NewBridge("data", invalid).Metrics()
+	assert.Equal(t, defaultSendBufferSize, m.SendBufferSize)
+	assert.Equal(t, defaultWriteTimeout.Seconds(), m.WriteTimeout)
+
+	assert.NoError(t, BridgeDependencies{}.Validate(), "zero values select the defaults")
+}
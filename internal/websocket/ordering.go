@@ -0,0 +1,199 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// reorderWindow is how long ordered delivery holds a message while an
+// earlier one from the same publisher is still missing. A message that never
+// arrives, e.g. because its publish failed, delays the ones after it by this
+// much.
+const reorderWindow = 100 * time.Millisecond
+
+// streamIdleTimeout is how long a publisher's stream is kept with nothing
+// pending and no new messages. A publisher that comes back later costs one
+// window, like one joined mid-stream.
+const streamIdleTimeout = 5 * time.Minute
+
+// inOrder returns handler unchanged unless ordered delivery is enabled, in
+// which case messages reach it in the order they were published. A message
+// handled on arrival settles with the handler's error; one held back for an
+// earlier message is acknowledged when it is buffered, so its handler's
+// error can only be logged.
+func (b *Bridge) inOrder(ctx context.Context, handler pubsub.Handler) pubsub.Handler {
+	if !b.orderedDelivery {
+		return handler
+	}
+	r := newResequencer(reorderWindow, func(msg pubsub.Message) error {
+		return handler(ctx, msg)
+	})
+	return func(ctx context.Context, msg pubsub.Message) error {
+		return r.add(msg)
+	}
+}
+
+// resequencer restores the publish order of one subscription's messages
+// using the sequence numbers pubsub.Publish records, so a user's fragments
+// are applied in the order they were sent.
+type resequencer struct {
+	window  time.Duration
+	deliver func(pubsub.Message) error
+	now     func() time.Time
+
+	mu        sync.Mutex
+	streams   map[string]*publishStream // publisher ID -> stream
+	lastSweep time.Time
+}
+
+// publishStream tracks the messages of one publisher. Its own lock orders
+// deliveries, so publishers don't wait on each other.
+type publishStream struct {
+	id       string
+	lastUsed time.Time // guarded by the resequencer's mu
+
+	mu      sync.Mutex
+	next    uint64 // sequence number expected next
+	pending map[uint64]pubsub.Message
+	timer   *time.Timer // skips a missing message after the window
+	// timerGen identifies the current timer, so one that fired while it was
+	// being stopped does not skip ahead early.
+	timerGen uint64
+}
+
+func newResequencer(window time.Duration, deliver func(pubsub.Message) error) *resequencer {
+	return &resequencer{
+		window:  window,
+		deliver: deliver,
+		now:     time.Now,
+		streams: make(map[string]*publishStream),
+	}
+}
+
+// add delivers msg once every earlier message from its publisher has been
+// delivered, or has been missing for the window. Messages without a publish
+// sequence, and late ones whose turn was skipped, are delivered immediately.
+// It returns the handler's error for msg when msg is delivered right away.
+func (r *resequencer) add(msg pubsub.Message) error {
+	publisherID, seq, ok := pubsub.PublishOrder(msg)
+	if !ok {
+		return r.deliver(msg)
+	}
+
+	s := r.stream(publisherID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case seq < s.next:
+		return r.deliver(msg)
+	case seq == s.next:
+		err := r.deliver(msg)
+		s.next++
+		r.drainLocked(s)
+		return err
+	default:
+		s.pending[seq] = msg
+		if s.timer == nil {
+			r.startTimerLocked(s)
+		}
+		return nil
+	}
+}
+
+// stream returns the stream of publisherID, creating it if needed, and
+// evicts streams that have been idle for streamIdleTimeout.
+func (r *resequencer) stream(publisherID string) *publishStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastSweep) >= streamIdleTimeout {
+		r.sweepLocked(now)
+	}
+	s := r.streams[publisherID]
+	if s == nil {
+		// Sequences start at 1. Joining a publisher mid-stream, e.g. one on
+		// another instance, costs one window before its messages flow.
+		s = &publishStream{id: publisherID, next: 1, pending: make(map[uint64]pubsub.Message)}
+		r.streams[publisherID] = s
+	}
+	s.lastUsed = now
+	return s
+}
+
+// sweepLocked drops idle streams with nothing pending. Streams busy
+// delivering are skipped rather than waited for. Caller must hold mu.
+func (r *resequencer) sweepLocked(now time.Time) {
+	for id, s := range r.streams {
+		if now.Sub(s.lastUsed) < streamIdleTimeout || !s.mu.TryLock() {
+			continue
+		}
+		if len(s.pending) == 0 {
+			delete(r.streams, id)
+		}
+		s.mu.Unlock()
+	}
+	r.lastSweep = now
+}
+
+// skip gives up on the missing message of a publisher's stream and delivers
+// what is pending from the oldest sequence on.
+func (r *resequencer) skip(s *publishStream, gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timerGen != gen {
+		return
+	}
+	s.timer = nil
+	if len(s.pending) == 0 {
+		return
+	}
+	oldest := uint64(0)
+	for seq := range s.pending {
+		if oldest == 0 || seq < oldest {
+			oldest = seq
+		}
+	}
+	slog.Debug("Skipping missing messages in ordered delivery", "publisher", s.id, "from", s.next, "to", oldest-1)
+	s.next = oldest
+	r.drainLocked(s)
+	if len(s.pending) > 0 {
+		r.startTimerLocked(s)
+	}
+}
+
+// startTimerLocked schedules a skip of the stream's missing message. Caller
+// must hold s.mu.
+func (r *resequencer) startTimerLocked(s *publishStream) {
+	s.timerGen++
+	gen := s.timerGen
+	s.timer = time.AfterFunc(r.window, func() { r.skip(s, gen) })
+}
+
+// drainLocked delivers pending messages that are now in sequence. They were
+// acknowledged when buffered, so handler errors are logged. Caller must hold
+// s.mu.
+func (r *resequencer) drainLocked(s *publishStream) {
+	for {
+		msg, ok := s.pending[s.next]
+		if !ok {
+			break
+		}
+		delete(s.pending, s.next)
+		if err := r.deliver(msg); err != nil {
+			slog.Error("Failed to deliver ordered message", logging.Topic(msg.Topic), "error", err)
+		}
+		s.next++
+	}
+	if len(s.pending) == 0 && s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+		s.timerGen++
+	}
+}
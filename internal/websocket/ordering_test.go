@@ -0,0 +1,182 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// sequenced returns a message carrying the publish order a publisher with
+// the given ID records.
+func sequenced(publisherID string, seq uint64) pubsub.Message {
+	return pubsub.Message{
+		Topic:   "ws.html.direct",
+		Payload: []byte(strconv.FormatUint(seq, 10)),
+		Metadata: map[string]string{
+			pubsub.MetaKeyPublisherID: publisherID,
+			pubsub.MetaKeyPublishSeq:  strconv.FormatUint(seq, 10),
+		},
+	}
+}
+
+// recorder collects the payloads a resequencer delivers.
+type recorder struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (r *recorder) deliver(msg pubsub.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads = append(r.payloads, string(msg.Payload))
+	return nil
+}
+
+func (r *recorder) got() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.payloads...)
+}
+
+func TestResequencer_RestoresPublishOrder(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(time.Minute, rec.deliver)
+
+	r.add(sequenced("a", 1))
+	r.add(sequenced("a", 3))
+	r.add(sequenced("a", 4))
+	assert.Equal(t, []string{"1"}, rec.got(), "later messages wait for the missing one")
+
+	r.add(sequenced("a", 2))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, rec.got())
+}
+
+func TestResequencer_PublishersAreIndependent(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(time.Minute, rec.deliver)
+
+	r.add(sequenced("a", 2))
+	r.add(sequenced("b", 1))
+	r.add(sequenced("b", 2))
+	assert.Equal(t, []string{"1", "2"}, rec.got(), "a gap from one publisher does not hold back another")
+}
+
+func TestResequencer_SkipsMissingMessageAfterWindow(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(20*time.Millisecond, rec.deliver)
+
+	r.add(sequenced("a", 1))
+	r.add(sequenced("a", 3))
+	r.add(sequenced("a", 5))
+	require.Eventually(t, func() bool {
+		return len(rec.got()) == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"1", "3", "5"}, rec.got())
+
+	// A message arriving after its turn was skipped is still delivered.
+	r.add(sequenced("a", 2))
+	r.add(sequenced("a", 6))
+	assert.Equal(t, []string{"1", "3", "5", "2", "6"}, rec.got())
+}
+
+func TestResequencer_JoinsStreamAfterWindow(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(20*time.Millisecond, rec.deliver)
+
+	// Messages from a publisher that started before the subscription.
+	r.add(sequenced("a", 41))
+	r.add(sequenced("a", 40))
+	require.Eventually(t, func() bool {
+		return len(rec.got()) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"40", "41"}, rec.got())
+
+	r.add(sequenced("a", 42))
+	assert.Equal(t, []string{"40", "41", "42"}, rec.got(), "the stream flows once joined")
+}
+
+func TestResequencer_DeliversUnsequencedMessagesImmediately(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(time.Minute, rec.deliver)
+
+	r.add(sequenced("a", 1))
+	r.add(sequenced("a", 3))
+	r.add(pubsub.Message{Topic: "ws.html.direct", Payload: []byte("plain")})
+	assert.Equal(t, []string{"1", "plain"}, rec.got())
+}
+
+func TestResequencer_ReturnsHandlerError(t *testing.T) {
+	failure := errors.New("handler failed")
+	var failed []string
+	r := newResequencer(time.Minute, func(msg pubsub.Message) error {
+		failed = append(failed, string(msg.Payload))
+		return failure
+	})
+
+	assert.ErrorIs(t, r.add(sequenced("a", 1)), failure, "a message delivered on arrival settles with its error")
+	assert.NoError(t, r.add(sequenced("a", 3)), "a buffered message is acknowledged")
+	assert.ErrorIs(t, r.add(sequenced("a", 2)), failure)
+	assert.Equal(t, []string{"1", "2", "3"}, failed)
+	assert.ErrorIs(t, r.add(pubsub.Message{Payload: []byte("plain")}), failure)
+}
+
+func TestResequencer_EvictsIdleStreams(t *testing.T) {
+	rec := &recorder{}
+	r := newResequencer(time.Minute, rec.deliver)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.add(sequenced("idle", 1))
+	r.add(sequenced("waiting", 2))
+	now = now.Add(streamIdleTimeout)
+	r.add(sequenced("active", 1))
+
+	assert.NotContains(t, r.streams, "idle", "an idle stream is evicted")
+	assert.Contains(t, r.streams, "waiting", "a stream with pending messages is kept")
+	assert.Contains(t, r.streams, "active")
+}
+
+func TestBridge_InOrder(t *testing.T) {
+	const count = 200
+	ps := pubsub.NewWatermillBridge()
+	defer ps.Close()
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	handler := func(ctx context.Context, msg pubsub.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, string(msg.Payload))
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &Bridge{orderedDelivery: true}
+	require.NoError(t, ps.Subscribe(ctx, "ordering.test", b.inOrder(ctx, handler)))
+
+	want := make([]string, count)
+	for i := range want {
+		want[i] = fmt.Sprintf("fragment-%d", i)
+		require.NoError(t, ps.Publish(ctx, pubsub.Message{Topic: "ordering.test", Payload: []byte(want[i])}))
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == count
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, want, received, "a burst is delivered in publish order")
+}
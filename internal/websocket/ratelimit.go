@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/nfrund/goby/internal/logging"
+	"golang.org/x/time/rate"
+)
+
+// Defaults for inbound client message rate limiting.
+const (
+	defaultClientRateLimit = 20 // messages per second
+	defaultClientRateBurst = 40
+)
+
+// ErrorFrame is sent to data clients when the bridge refuses a message.
+//
+//	{"type":"ws.error","code":"rate_limited","message":"..."}
+type ErrorFrame struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorCodeRateLimited is the ErrorFrame code for messages dropped because the
+// client exceeded its inbound rate limit.
+const ErrorCodeRateLimited = "rate_limited"
+
+// newClientLimiter returns the token bucket for a new connection, or nil when
+// rate limiting is disabled.
+func (b *Bridge) newClientLimiter() *rate.Limiter {
+	if b.clientRateLimit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(b.clientRateLimit), b.clientRateBurst)
+}
+
+// allowIncoming reports whether the client may send another message. The
+// first message dropped in a run of over-limit messages triggers an error
+// frame, so a flooding client does not also flood its own send channel.
+func (b *Bridge) allowIncoming(client *Client) bool {
+	if client.limiter == nil || client.limiter.Allow() {
+		client.rateLimited = false
+		return true
+	}
+
+	b.metrics.rateLimited.Add(1)
+	if client.rateLimited {
+		return false
+	}
+	client.rateLimited = true
+
+	slog.Warn("Client exceeded inbound message rate, dropping messages",
+		logging.ClientID(client.ID),
+		logging.UserID(client.UserID),
+		"endpoint", b.endpoint)
+	b.sendError(client, ErrorCodeRateLimited, "Too many messages, slow down.")
+	return false
+}
+
+// ErrorCodeSubscriptionRequired is the ErrorFrame code for actions refused
+// because the client is not subscribed to the action's RequiredSubscription.
+const ErrorCodeSubscriptionRequired = "subscription_required"
+
+// actionLimiter is a client's token bucket for one action.
+type actionLimiter struct {
+	limiter *rate.Limiter
+	// limited is set while messages are being dropped by limiter.
+	limited bool
+}
+
+// admitAction applies the action's required subscription and per-action
+// rate limit. Like allowIncoming, only the first message dropped in a run
+// triggers an error frame. It is only called from the read pump.
+func (b *Bridge) admitAction(client *Client, action ActionInfo) bool {
+	if action.RequiredSubscription != "" && !b.isClientSubscribed(client.ID, b.normalizeTopic(action.RequiredSubscription)) {
+		slog.Warn("Client sent an action without its required subscription",
+			logging.ClientID(client.ID),
+			"action", action.Action,
+			logging.Topic(action.RequiredSubscription))
+		b.sendError(client, ErrorCodeSubscriptionRequired,
+			fmt.Sprintf("Subscribe to %q before sending %q.", action.RequiredSubscription, action.Action))
+		return false
+	}
+	if action.RateLimit <= 0 {
+		return true
+	}
+
+	if client.actionLimiters == nil {
+		client.actionLimiters = make(map[string]*actionLimiter)
+	}
+	l, ok := client.actionLimiters[action.Action]
+	// The action's limit changes when it is allowed again with new options.
+	if !ok || l.limiter.Limit() != rate.Limit(action.RateLimit) || l.limiter.Burst() != action.RateBurst {
+		l = &actionLimiter{limiter: rate.NewLimiter(rate.Limit(action.RateLimit), action.RateBurst)}
+		client.actionLimiters[action.Action] = l
+	}
+	if l.limiter.Allow() {
+		l.limited = false
+		return true
+	}
+
+	b.metrics.rateLimited.Add(1)
+	if l.limited {
+		return false
+	}
+	l.limited = true
+
+	slog.Warn("Client exceeded action rate, dropping messages",
+		logging.ClientID(client.ID),
+		logging.UserID(client.UserID),
+		"action", action.Action,
+		"endpoint", b.endpoint)
+	b.sendError(client, ErrorCodeRateLimited, fmt.Sprintf("Too many %q messages, slow down.", action.Action))
+	return false
+}
+
+// sendError tells the client a message was refused. HTML clients receive a
+// toast; data clients receive an ErrorFrame.
+func (b *Bridge) sendError(client *Client, code, message string) {
+	var frame any = ErrorFrame{Type: "ws.error", Code: code, Message: message}
+	if b.endpoint == "html" {
+		frame = Toast{Type: TopicToast.Name(), Level: ToastError, Text: message}
+	}
+
+	data, err := json.Marshal(frame)
+	if err != nil {
+		slog.Error("Failed to marshal error frame", logging.ClientID(client.ID), "error", err)
+		return
+	}
+	client.SendMessage(data)
+}
@@ -0,0 +1,66 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBridge_RateLimitsIncomingMessages(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: 0.001, ClientRateBurst: 2})
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8), limiter: b.newClientLimiter()}
+
+	for range 5 {
+		b.handleIncoming(client, []byte(`{}`))
+	}
+
+	assert.Equal(t, uint64(3), b.Metrics().RateLimitedMessages)
+	require.Len(t, client.Send, 1, "only the first dropped message triggers an error frame")
+
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorFrame{Type: "ws.error", Code: ErrorCodeRateLimited, Message: "Too many messages, slow down."}, frame)
+}
+
+func TestBridge_RateLimitDisabled(t *testing.T) {
+	b := NewBridge("html", BridgeDependencies{ClientRateLimit: -1})
+	assert.Nil(t, b.newClientLimiter())
+}
+
+func TestBridge_RateLimitsActions(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1})
+	require.NoError(t, b.AllowAction("game.move", WithActionRateLimit(0.001, 2)))
+	require.NoError(t, b.AllowAction("chat.message"))
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	for range 5 {
+		b.handleIncoming(client, []byte(`{"action":"game.move"}`))
+	}
+	for range 5 {
+		b.handleIncoming(client, []byte(`{"action":"chat.message"}`))
+	}
+
+	assert.Equal(t, uint64(3), b.Metrics().RateLimitedMessages, "only game.move is limited")
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorFrame{Type: "ws.error", Code: ErrorCodeRateLimited, Message: `Too many "game.move" messages, slow down.`}, frame)
+}
+
+func TestBridge_ActionRequiresSubscription(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1})
+	require.NoError(t, b.AllowAction("game.move", WithRequiredSubscription("game.lobby")))
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	b.handleIncoming(client, []byte(`{"action":"game.move"}`))
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorCodeSubscriptionRequired, frame.Code)
+
+	require.True(t, b.subscribeClient(client.ID, "game.lobby"))
+	b.handleIncoming(client, []byte(`{"action":"game.move"}`))
+	assert.Empty(t, client.Send)
+}
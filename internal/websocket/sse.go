@@ -0,0 +1,169 @@
+package websocket
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nfrund/goby/internal/logging"
+)
+
+// keepAliveComment is written to idle event streams so proxies don't time
+// them out; EventSource ignores comment lines.
+var keepAliveComment = []byte(": ping\n\n")
+
+// EventStreamHandler returns an echo.HandlerFunc that serves the bridge's
+// messages as server-sent events (text/event-stream), for clients behind
+// proxies that don't pass WebSocket upgrades.
+//
+// An event-stream client is registered with the bridge like a WebSocket
+// connection: it receives the endpoint's broadcasts and the direct messages
+// addressed to its user, one "message" event per message, and the bridge
+// publishes the same ready and disconnected events for it. The stream is
+// one-way, so clients choose their subscriptions up front with repeated
+// topic and channel query parameters:
+//
+//	GET /app/sse/data?topic=chat.messages&channel=scoreboard
+//
+// Refused subscriptions arrive as error frames, as they would over a
+// WebSocket. client_id, last_seq and resume work as on the WebSocket
+// endpoint, and the assigned client ID is returned in HeaderClientID for
+// presence heartbeats.
+func (b *Bridge) EventStreamHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, ok := b.connectionUserID(c)
+		if !ok {
+			slog.Error("Bridge.serveEvents: Could not get user from context for event stream")
+			return c.String(http.StatusUnauthorized, "User not authenticated")
+		}
+
+		if !b.connLimit.acquire() {
+			return b.refuseConnection(c, userID)
+		}
+
+		clientID := b.assignClientID(userID, c.QueryParam("client_id"))
+		client := &Client{
+			ID:       clientID,
+			UserID:   userID,
+			Send:     make(chan []byte, b.sendBufferSize),
+			Endpoint: b.endpoint,
+			encoding: EncodingJSON,
+
+			connectedAt: time.Now(),
+			dropped:     &b.metrics.dropped,
+		}
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set("Connection", "keep-alive")
+		res.Header().Set("X-Accel-Buffering", "no") // Disable nginx response buffering.
+		res.Header().Set(HeaderClientID, clientID)
+		res.WriteHeader(http.StatusOK)
+		res.Flush()
+
+		b.recordConnect()
+		b.attachClient(client, c.QueryParam("last_seq"), c.QueryParam("resume"))
+		b.wg.Add(1)
+		defer func() {
+			b.detachClient(client)
+			b.wg.Done()
+			slog.Info("Event stream client disconnected", logging.ClientID(client.ID), logging.UserID(client.UserID), "endpoint", b.endpoint)
+		}()
+
+		query := c.QueryParams()
+		for _, topic := range query["topic"] {
+			b.handleSubscription(client, SubscribeMessage{Action: "subscribe", Topic: topic})
+		}
+		for _, channel := range query["channel"] {
+			b.handleChannelMessage(client, ChannelMessage{Action: "subscribe_channel", Channel: channel})
+		}
+
+		go b.publishReady(client)
+
+		b.eventPump(c, client)
+		return nil
+	}
+}
+
+// eventPump writes the client's messages to the event stream until the
+// request ends, a write fails or the bridge shuts down.
+func (b *Bridge) eventPump(c echo.Context, client *Client) {
+	client.mu.RLock()
+	send := client.Send
+	client.mu.RUnlock()
+
+	var shutdown <-chan struct{}
+	if b.ctx != nil {
+		shutdown = b.ctx.Done()
+	}
+
+	res := c.Response()
+	rc := http.NewResponseController(res.Writer)
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	write := func(frame []byte) bool {
+		// Not every ResponseWriter supports deadlines; write without one then.
+		_ = rc.SetWriteDeadline(time.Now().Add(b.writeTimeout))
+		if _, err := res.Write(frame); err != nil {
+			slog.Warn("Event stream write error", logging.ClientID(client.ID), "error", err)
+			return false
+		}
+		res.Flush()
+		return true
+	}
+
+	for {
+		select {
+		case message, ok := <-send:
+			if !ok {
+				return
+			}
+			if isGzipped(message) {
+				// The event stream is text; send what the payload compressed.
+				data, err := gunzipPayload(message)
+				if err != nil {
+					slog.Warn("Dropping undecodable message for event stream client", logging.ClientID(client.ID), "error", err)
+					continue
+				}
+				message = data
+			}
+			frame := encodeEvent(message)
+			if !write(frame) {
+				return
+			}
+			b.recordMessageSize(len(frame))
+
+		case <-ticker.C:
+			if !write(keepAliveComment) {
+				return
+			}
+
+		case <-c.Request().Context().Done():
+			return
+
+		case <-shutdown:
+			return
+		}
+	}
+}
+
+// encodeEvent frames a message as a server-sent event, one data line per
+// line of the message.
+func encodeEvent(message []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(message) + 16)
+	for line := range bytes.Lines(message) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimRight(line, "\r\n"))
+		buf.WriteByte('\n')
+	}
+	if len(message) == 0 {
+		buf.WriteString("data: \n")
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,101 @@
+package websocket_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	ws "github.com/nfrund/goby/internal/websocket"
+)
+
+// openEventStream connects to the fixture bridge's event stream and returns
+// a reader over its data lines.
+func openEventStream(t *testing.T, f *testFixture, query string) (*http.Response, <-chan string) {
+	t.Helper()
+	e := echo.New()
+	addAuthMiddleware(e)
+	e.GET("/sse/html", f.bridge.EventStreamHandler())
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/sse/html"+query, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	data := make(chan string, 16)
+	go func() {
+		defer close(data)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				data <- line
+			}
+		}
+	}()
+	return resp, data
+}
+
+func nextEvent(t *testing.T, data <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-data:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return ""
+	}
+}
+
+func TestBridge_EventStreamReceivesPublishedMessages(t *testing.T) {
+	f, cleanup := setupTestFixture(t)
+	defer cleanup()
+
+	resp, data := openEventStream(t, f, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.NotEmpty(t, resp.Header.Get(ws.HeaderClientID))
+	require.Eventually(t, func() bool { return len(f.ps.getMessages("ws.ready")) == 1 },
+		time.Second, 5*time.Millisecond, "event-stream clients announce themselves like WebSocket clients")
+
+	require.NoError(t, f.ps.Publish(f.ctx, pubsub.Message{
+		Topic:   ws.TopicHTMLBroadcast.Name(),
+		Payload: []byte(`<div id="news">hello</div>`),
+	}))
+	assert.Equal(t, `<div id="news">hello</div>`, nextEvent(t, data))
+
+	require.NoError(t, f.ps.Publish(f.ctx, pubsub.Message{
+		Topic:    ws.TopicHTMLDirect.Name(),
+		Payload:  []byte("<p>for you</p>\n<p>two lines</p>"),
+		Metadata: map[string]string{"recipient_id": "test@example.com"},
+	}))
+	assert.Equal(t, "<p>for you</p>", nextEvent(t, data))
+	assert.Equal(t, "<p>two lines</p>", nextEvent(t, data))
+
+	resp.Body.Close()
+	require.Eventually(t, func() bool { return len(f.ps.getMessages(ws.TopicClientDisconnected.Name())) == 1 },
+		time.Second, 5*time.Millisecond)
+}
+
+func TestBridge_EventStreamSubscriptions(t *testing.T) {
+	f, cleanup := setupTestFixture(t)
+	defer cleanup()
+	require.NoError(t, f.topics.SetEnabled("alternate.topic", false))
+
+	_, data := openEventStream(t, f, "?topic=test.topic&topic=alternate.topic")
+
+	assert.Contains(t, nextEvent(t, data), `Topic \"alternate.topic\" is disabled.`,
+		"refused subscriptions arrive as error frames")
+}
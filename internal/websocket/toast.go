@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nfrund/goby/internal/domain"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// ToastLevel is the severity of a toast notification. The frontend uses it to
+// pick the toast's styling. It is the same type as view.FlashLevel.
+type ToastLevel = domain.NoticeLevel
+
+const (
+	ToastInfo    = domain.NoticeInfo
+	ToastSuccess = domain.NoticeSuccess
+	ToastWarning = domain.NoticeWarning
+	ToastError   = domain.NoticeError
+)
+
+// DefaultToastDurationMs is how long a toast stays visible when no duration is given.
+const DefaultToastDurationMs = 5000
+
+// Toast is the payload schema for TopicToast notifications. It is sent as
+// JSON to the user's HTML WebSocket clients:
+//
+//	{"type":"ws.toast","level":"success","text":"Export finished","duration_ms":5000}
+//
+// Type is always "ws.toast" and distinguishes toasts from HTML fragments.
+// Text is plain text and must not be interpreted as HTML by the frontend.
+// DurationMs is how long the toast is shown; zero means the frontend default.
+type Toast struct {
+	Type       string     `json:"type"`
+	Level      ToastLevel `json:"level"`
+	Text       string     `json:"text"`
+	DurationMs int        `json:"duration_ms,omitempty"`
+}
+
+// Validate checks that the toast has a known level and non-empty text.
+func (t Toast) Validate() error {
+	switch t.Level {
+	case ToastInfo, ToastSuccess, ToastWarning, ToastError:
+	default:
+		return fmt.Errorf("invalid toast level %q", t.Level)
+	}
+	if t.Text == "" {
+		return errors.New("toast text cannot be empty")
+	}
+	if t.DurationMs < 0 {
+		return errors.New("toast duration cannot be negative")
+	}
+	return nil
+}
+
+// NewToastMessage builds the TopicHTMLDirect message that delivers toast to
+// all of userID's HTML clients.
+func NewToastMessage(userID string, toast Toast) (pubsub.Message, error) {
+	if userID == "" {
+		return pubsub.Message{}, errors.New("toast recipient cannot be empty")
+	}
+	toast.Type = TopicToast.Name()
+	if toast.DurationMs == 0 {
+		toast.DurationMs = DefaultToastDurationMs
+	}
+	if err := toast.Validate(); err != nil {
+		return pubsub.Message{}, err
+	}
+
+	payload, err := json.Marshal(toast)
+	if err != nil {
+		return pubsub.Message{}, fmt.Errorf("failed to marshal toast: %w", err)
+	}
+
+	return pubsub.Message{
+		Topic:   TopicHTMLDirect.Name(),
+		UserID:  userID,
+		Payload: payload,
+		Metadata: map[string]string{
+			"recipient_id": userID,
+		},
+	}, nil
+}
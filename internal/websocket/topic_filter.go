@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrorCodeTopicNotAllowed is the ErrorFrame code for subscriptions refused by
+// the bridge's topic filter.
+const ErrorCodeTopicNotAllowed = "topic_not_allowed"
+
+// ErrorCodeInvalidTopic is the ErrorFrame code for subscriptions whose topic
+// name exceeds the topic manager's name limits.
+const ErrorCodeInvalidTopic = "invalid_topic"
+
+// topicFilter decides which topics clients of a bridge may subscribe to.
+// Patterns use path.Match syntax, where "*" also matches dots, so "ws.data.*"
+// covers every topic under ws.data. A deny match always wins; an empty allow
+// list permits every topic that is not denied.
+type topicFilter struct {
+	allowAll bool
+	allow    []string
+	deny     []string
+}
+
+// newTopicFilter builds a filter, skipping malformed patterns. A non-empty
+// allow list stays restrictive even if none of its patterns are valid.
+func newTopicFilter(allow, deny []string) *topicFilter {
+	return &topicFilter{
+		allowAll: len(allow) == 0,
+		allow:    validPatterns(allow),
+		deny:     validPatterns(deny),
+	}
+}
+
+// allows reports whether clients may subscribe to topic.
+func (f *topicFilter) allows(topic string) bool {
+	if matchAny(f.deny, topic) {
+		return false
+	}
+	return f.allowAll || matchAny(f.allow, topic)
+}
+
+func matchAny(patterns []string, topic string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, topic); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPatterns reports empty or malformed patterns.
+func checkPatterns(patterns []string) error {
+	var errs []error
+	for _, p := range patterns {
+		if p == "" {
+			errs = append(errs, errors.New("topic pattern cannot be empty"))
+		} else if _, err := path.Match(p, ""); err != nil {
+			errs = append(errs, fmt.Errorf("topic pattern %q: %w", p, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validPatterns drops the patterns checkPatterns would reject.
+func validPatterns(patterns []string) []string {
+	valid := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if checkPatterns([]string{p}) == nil {
+			valid = append(valid, p)
+		}
+	}
+	return valid
+}
@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nfrund/goby/internal/topicmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicFilter(t *testing.T) {
+	f := newTopicFilter([]string{"ws.data.*", "game.*"}, []string{"game.admin.*"})
+	assert.True(t, f.allows("ws.data.broadcast"))
+	assert.True(t, f.allows("game.state.room1"))
+	assert.False(t, f.allows("game.admin.kick"), "deny wins over allow")
+	assert.False(t, f.allows("ws.html.broadcast"))
+
+	open := newTopicFilter(nil, []string{"ws.html.*"})
+	assert.True(t, open.allows("chat.messages"))
+	assert.False(t, open.allows("ws.html.direct"))
+
+	broken := newTopicFilter([]string{"["}, nil)
+	assert.False(t, broken.allows("chat.messages"), "an invalid allow list must not open every topic")
+}
+
+func TestBridgeDependencies_ValidateTopicPatterns(t *testing.T) {
+	err := BridgeDependencies{SubscribeAllow: []string{"ws.data.*", "["}, SubscribeDeny: []string{""}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `subscribe allow list: topic pattern "["`)
+	assert.Contains(t, err.Error(), "subscribe deny list: topic pattern cannot be empty")
+}
+
+func TestBridge_RejectsDisallowedSubscription(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1, SubscribeDeny: []string{"ws.html.*"}})
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":"ws.html.broadcast"}`))
+	assert.False(t, b.isClientSubscribed("c1", "ws.html.broadcast"))
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorCodeTopicNotAllowed, frame.Code)
+
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":"ws.data.broadcast"}`))
+	assert.True(t, b.isClientSubscribed("c1", "ws.data.broadcast"))
+	assert.Empty(t, client.Send)
+}
+
+func TestBridge_RejectsDisallowedChannel(t *testing.T) {
+	topic := topicmgr.DefineModule(topicmgr.TopicConfig{
+		Name:        "test.filtered.board",
+		Module:      "test",
+		Description: "Scores behind a deny pattern",
+		Pattern:     "test.filtered.board",
+		Metadata:    map[string]interface{}{topicmgr.MetaPayloadFields: []string{"home", "away"}},
+	})
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1, SubscribeDeny: []string{"test.filtered.*"}})
+	require.NoError(t, b.RegisterChannel(DataChannel{Name: "board", Topic: topic}))
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	b.handleChannelMessage(client, ChannelMessage{Action: "subscribe_channel", Channel: "board"})
+	assert.False(t, b.isClientSubscribed("c1", channelSubscriptionKey("board")))
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorCodeTopicNotAllowed, frame.Code)
+}
+
+func TestBridge_RejectsOversizedSubscriptionTopic(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1})
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	channel := strings.Repeat("x", topicmgr.DefaultNameLimits.MaxLength)
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":"chat","payload":{"channel":"`+channel+`"}}`))
+	assert.False(t, b.isClientSubscribed("c1", "chat."+channel))
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorCodeInvalidTopic, frame.Code)
+
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":"chat","payload":{"channel":"room1"}}`))
+	assert.True(t, b.isClientSubscribed("c1", "chat.room1"))
+}
+
+func TestBridge_SubscribeLimit(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1, SubscribeLimit: 2})
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+	subscribe := func(action, topic string) {
+		b.handleIncoming(client, []byte(`{"action":"`+action+`","topic":"`+topic+`"}`))
+	}
+
+	subscribe("subscribe", "a")
+	subscribe("subscribe", "b")
+	subscribe("subscribe", "a") // already subscribed, does not count twice
+	assert.Empty(t, client.Send)
+
+	subscribe("subscribe", "c")
+	assert.False(t, b.isClientSubscribed("c1", "c"))
+	require.Len(t, client.Send, 1)
+	var frame ErrorFrame
+	require.NoError(t, json.Unmarshal(<-client.Send, &frame))
+	assert.Equal(t, ErrorCodeSubscriptionLimit, frame.Code)
+
+	// Unsubscribing frees a slot.
+	subscribe("unsubscribe", "a")
+	subscribe("subscribe", "c")
+	assert.True(t, b.isClientSubscribed("c1", "c"))
+	assert.Empty(t, client.Send)
+
+	// Disconnecting releases every subscription.
+	b.unsubscribeAll("c1")
+	assert.False(t, b.isClientSubscribed("c1", "b"))
+	assert.Empty(t, b.topics.subscriptions)
+	assert.Empty(t, b.topics.byClient)
+}
+
+func TestBridge_NormalizesSubscriptionTopic(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{ClientRateLimit: -1})
+	client := &Client{ID: "c1", UserID: "alice", Send: make(chan []byte, 8)}
+
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":" Client..Chat.Message "}`))
+	assert.True(t, b.isClientSubscribed("c1", "client.chat.message"))
+
+	b.handleIncoming(client, []byte(`{"action":"subscribe","topic":"Chat.Room","payload":{"channel":"ABC"}}`))
+	assert.True(t, b.isClientSubscribed("c1", "chat.room.ABC"), "the channel keeps its case")
+
+	b.handleIncoming(client, []byte(`{"action":"unsubscribe","topic":"CLIENT.CHAT.MESSAGE"}`))
+	assert.False(t, b.isClientSubscribed("c1", "client.chat.message"))
+	assert.Empty(t, client.Send)
+}
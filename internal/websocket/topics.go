@@ -1,8 +1,6 @@
 package websocket
 
 import (
-	"strings"
-
 	"github.com/nfrund/goby/internal/topicmgr"
 )
 
@@ -62,6 +60,73 @@ var (
 		},
 	})
 
+	// TopicBroadcastAll carries one message for every connected client,
+	// with separate HTML and data payloads. BroadcastFanout republishes it to
+	// TopicHTMLBroadcast and TopicDataBroadcast; publish it with BroadcastAll.
+	TopicBroadcastAll = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "ws.broadcast.all",
+		Description: "Broadcast to all HTML and Data WebSocket clients, fanned out to both bridges",
+		Pattern:     "ws.broadcast.all",
+		Example:     `{"html":"<base64 HTML>","data":"<base64 JSON>"}`,
+		Metadata: map[string]interface{}{
+			"endpoint_type":  "all",
+			"routing_type":   "broadcast",
+			"delivered_via":  []string{"ws.html.broadcast", "ws.data.broadcast"},
+			"payload_fields": []string{"html", "data"},
+		},
+	})
+
+	// TopicToast identifies transient notifications (toasts) pushed to a user's
+	// HTML clients. Toasts are delivered over TopicHTMLDirect; this topic name is
+	// carried in the payload's "type" field so the frontend can tell them apart
+	// from HTML fragments. See Toast for the payload schema.
+	TopicToast = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "ws.toast",
+		Description: "Transient notification pushed to a specific user's HTML WebSocket clients",
+		Pattern:     "ws.toast",
+		Example:     `{"type":"ws.toast","level":"success","text":"Export finished","duration_ms":5000}`,
+		Metadata: map[string]interface{}{
+			"endpoint_type":  "html",
+			"routing_type":   "direct",
+			"delivered_via":  "ws.html.direct",
+			"payload_fields": []string{"type", "level", "text", "duration_ms"},
+			"valid_levels":   []string{"info", "success", "warning", "error"},
+		},
+	})
+
+	// TopicUploadProgress identifies file upload progress events pushed to the
+	// uploading user's clients while the request body arrives. They are
+	// delivered over TopicHTMLDirect and TopicDataDirect, throttled by the
+	// sender; see UploadProgress for the payload schema.
+	TopicUploadProgress = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "ws.upload.progress",
+		Description: "File upload progress pushed to the uploading user's WebSocket clients",
+		Pattern:     "ws.upload.progress",
+		Example:     `{"type":"ws.upload.progress","upload_id":"avatar-1","bytes_received":524288,"total_bytes":1048576,"percent":50}`,
+		Metadata: map[string]interface{}{
+			"endpoint_type":  "all",
+			"routing_type":   "direct",
+			"delivered_via":  []string{"ws.html.direct", "ws.data.direct"},
+			"payload_fields": []string{"type", "upload_id", "bytes_received", "total_bytes", "percent", "done"},
+		},
+	})
+
+	// TopicDirectUndelivered receives direct messages that none of the
+	// recipient's clients received, when the sender opted in with
+	// MetaKeyUndeliveredFallback. Modules subscribe to deliver them another
+	// way, e.g. by email or a persistent inbox. With a DeliveryStore, each
+	// message is published here once across instances.
+	TopicDirectUndelivered = topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        "ws.direct.undelivered",
+		Description: "Direct WebSocket messages that reached no active client of the recipient",
+		Pattern:     "ws.direct.undelivered",
+		Example:     "ws.direct.undelivered",
+		Metadata: map[string]interface{}{
+			"routing_type": "fallback",
+			"requires":     []string{"recipient_id", "original_topic", "endpoint"},
+		},
+	})
+
 	// TopicClientReady is published when a new WebSocket client successfully connects and is ready
 	TopicClientReady = topicmgr.DefineFramework(topicmgr.TopicConfig{
 		Name:        "ws.client.ready",
@@ -85,37 +150,98 @@ var (
 			"payload_fields": []string{"endpoint", "userID", "connectionID", "reason"},
 		},
 	})
+
+	// TopicHTMLClientReady and TopicDataClientReady are endpoint-specific
+	// alternatives to TopicClientReady, for bridges configured with them
+	// through BridgeDependencies.ReadyTopic.
+	TopicHTMLClientReady = defineClientReady("html")
+	TopicDataClientReady = defineClientReady("data")
+
+	// TopicHTMLClientDisconnected and TopicDataClientDisconnected are
+	// endpoint-specific alternatives to TopicClientDisconnected, for bridges
+	// configured with them through BridgeDependencies.DisconnectedTopic.
+	TopicHTMLClientDisconnected = defineClientDisconnected("html")
+	TopicDataClientDisconnected = defineClientDisconnected("data")
 )
 
-// RegisterTopics registers all WebSocket framework topics with the default topic manager
-// This function is idempotent - it will not fail if topics are already registered
-func RegisterTopics() error {
-	return RegisterTopicsWithManager(topicmgr.Default())
+// defineClientReady defines the ready topic of a single endpoint.
+func defineClientReady(endpoint string) topicmgr.Topic {
+	name := "ws." + endpoint + ".client.ready"
+	return topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        name,
+		Description: "Published when a new " + endpoint + " WebSocket client successfully connects and is ready",
+		Pattern:     name,
+		Example:     `{"endpoint":"` + endpoint + `","userID":"user123","connectionID":"conn456"}`,
+		Metadata: map[string]interface{}{
+			"endpoint_type":  endpoint,
+			"event_type":     "lifecycle",
+			"payload_fields": []string{"endpoint", "userID", "connectionID"},
+		},
+	})
 }
 
-// RegisterTopicsWithManager registers all WebSocket framework topics with the specified topic manager
-// This function is idempotent - it will not fail if topics are already registered
-func RegisterTopicsWithManager(manager *topicmgr.Manager) error {
-	topics := []topicmgr.Topic{
+// defineClientDisconnected defines the disconnect topic of a single endpoint.
+func defineClientDisconnected(endpoint string) topicmgr.Topic {
+	name := "ws." + endpoint + ".client.disconnected"
+	return topicmgr.DefineFramework(topicmgr.TopicConfig{
+		Name:        name,
+		Description: "Published when a " + endpoint + " WebSocket client disconnects",
+		Pattern:     name,
+		Example:     `{"endpoint":"` + endpoint + `","userID":"user123","connectionID":"conn456","reason":"client_closed"}`,
+		Metadata: map[string]interface{}{
+			"endpoint_type":  endpoint,
+			"event_type":     "lifecycle",
+			"payload_fields": []string{"endpoint", "userID", "connectionID", "reason"},
+		},
+	})
+}
+
+// ClientLifecycleTopics returns the endpoint-specific ready and disconnect
+// topics of endpoint ("html" or "data"), for a bridge whose lifecycle events
+// should be routed apart from the other endpoint's. Other endpoints get the
+// shared TopicClientReady and TopicClientDisconnected.
+func ClientLifecycleTopics(endpoint string) (ready, disconnected topicmgr.Topic) {
+	switch endpoint {
+	case "html":
+		return TopicHTMLClientReady, TopicHTMLClientDisconnected
+	case "data":
+		return TopicDataClientReady, TopicDataClientDisconnected
+	default:
+		return TopicClientReady, TopicClientDisconnected
+	}
+}
+
+// FrameworkTopics returns the WebSocket framework topics, for a
+// topicmgr.FrameworkRegistrar.
+func FrameworkTopics() []topicmgr.Topic {
+	return []topicmgr.Topic{
 		TopicHTMLBroadcast,
 		TopicHTMLDirect,
 		TopicDataBroadcast,
 		TopicDataDirect,
+		TopicBroadcastAll,
+		TopicToast,
+		TopicUploadProgress,
+		TopicDirectUndelivered,
 		TopicClientReady,
 		TopicClientDisconnected,
+		TopicHTMLClientReady,
+		TopicDataClientReady,
+		TopicHTMLClientDisconnected,
+		TopicDataClientDisconnected,
 	}
+}
 
-	for _, topic := range topics {
-		if err := manager.Register(topic); err != nil {
-			// Check if this is a "already registered" error, which we can ignore
-			if strings.Contains(err.Error(), "already registered") {
-				continue // Skip already registered topics
-			}
-			return err // Return other errors
-		}
-	}
+// RegisterTopics registers all WebSocket framework topics with the default topic manager
+// This function is idempotent - it will not fail if topics are already registered
+func RegisterTopics() error {
+	return RegisterTopicsWithManager(topicmgr.Default())
+}
 
-	return nil
+// RegisterTopicsWithManager registers all WebSocket framework topics with the specified topic manager
+// This function is idempotent - it will not fail if topics are already registered
+func RegisterTopicsWithManager(manager *topicmgr.Manager) error {
+	return topicmgr.NewFrameworkRegistrar().Add("websocket", FrameworkTopics()...).Register(manager)
 }
 
 // MustRegisterTopics registers all WebSocket framework topics and panics on error
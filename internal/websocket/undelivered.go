@@ -0,0 +1,158 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/nfrund/goby/internal/logging"
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+const (
+	// MetaKeyUndeliveredFallback opts a direct message into the undelivered
+	// fallback: set it to "true" and, if none of the recipient's clients on
+	// the endpoint receive the message, the bridge republishes it to
+	// TopicDirectUndelivered. Messages without it are dropped, which suits
+	// ephemeral updates.
+	MetaKeyUndeliveredFallback = "undelivered_fallback"
+	// MetaKeyOriginalTopic carries the topic an undelivered message was
+	// originally published to.
+	MetaKeyOriginalTopic = "original_topic"
+	// MetaKeyEndpoint carries the endpoint ("html" or "data") that could not
+	// deliver a message.
+	MetaKeyEndpoint = "endpoint"
+)
+
+// defaultUndeliveredGrace is how long an instance that could not deliver a
+// direct message waits for another instance to report delivering it.
+const defaultUndeliveredGrace = 2 * time.Second
+
+// WithUndeliveredFallback returns msg with MetaKeyUndeliveredFallback set.
+func WithUndeliveredFallback(msg pubsub.Message) pubsub.Message {
+	metadata := make(map[string]string, len(msg.Metadata)+1)
+	maps.Copy(metadata, msg.Metadata)
+	metadata[MetaKeyUndeliveredFallback] = "true"
+	msg.Metadata = metadata
+	return msg
+}
+
+func wantsUndeliveredFallback(msg pubsub.Message) bool {
+	return msg.Metadata[MetaKeyUndeliveredFallback] == "true"
+}
+
+// deliveryKey identifies msg's delivery on this endpoint in the delivery
+// store. Messages without an ID can't be matched across instances.
+func (b *Bridge) deliveryKey(msg pubsub.Message) (string, bool) {
+	id := msg.Metadata[pubsub.MetaKeyMessageID]
+	if id == "" {
+		return "", false
+	}
+	return "ws.delivery/" + b.endpoint + "/" + id, true
+}
+
+// recordDelivered notes in the delivery store that this instance delivered
+// an opted-in direct message, so no instance falls back for it.
+func (b *Bridge) recordDelivered(ctx context.Context, msg pubsub.Message) {
+	if b.deliveries == nil || !wantsUndeliveredFallback(msg) {
+		return
+	}
+	key, ok := b.deliveryKey(msg)
+	if !ok {
+		return
+	}
+	if _, err := b.deliveries.MarkIfAbsent(ctx, key, b.deliveryTTL()); err != nil {
+		slog.Warn("Failed to record direct message delivery",
+			logging.Topic(msg.Topic),
+			"endpoint", b.endpoint,
+			"error", err)
+	}
+}
+
+// fallBack publishes a direct message this instance could not deliver to
+// TopicDirectUndelivered. Every instance receives direct messages, so with
+// a delivery store it first waits out the grace period and then claims the
+// message's delivery key: the claim fails if an instance delivered it, and
+// only one of the instances that didn't wins it. Without a store, or for
+// messages without an ID, the decision is local.
+func (b *Bridge) fallBack(ctx context.Context, msg pubsub.Message, recipientID string) {
+	key, ok := b.deliveryKey(msg)
+	if b.deliveries == nil || !ok {
+		b.publishUndelivered(ctx, msg, recipientID)
+		return
+	}
+
+	var shutdown <-chan struct{}
+	if b.ctx != nil {
+		shutdown = b.ctx.Done()
+	}
+	ctx = context.WithoutCancel(ctx)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		timer := time.NewTimer(b.fallbackGrace)
+		defer timer.Stop()
+		// On shutdown decide right away rather than lose the message.
+		select {
+		case <-timer.C:
+		case <-shutdown:
+		}
+
+		claimed, err := b.deliveries.MarkIfAbsent(ctx, key, b.deliveryTTL())
+		if err != nil {
+			slog.Warn("Failed to claim undelivered direct message; publishing the fallback anyway",
+				logging.UserID(recipientID),
+				logging.Topic(msg.Topic),
+				"endpoint", b.endpoint,
+				"error", err)
+		} else if !claimed {
+			slog.Debug("Direct message was delivered, or is falling back, on another instance",
+				logging.UserID(recipientID),
+				logging.Topic(msg.Topic),
+				"endpoint", b.endpoint)
+			return
+		}
+		b.publishUndelivered(ctx, msg, recipientID)
+	}()
+}
+
+// deliveryTTL is how long delivery records are kept: long enough to outlast
+// the grace period on every instance.
+func (b *Bridge) deliveryTTL() time.Duration {
+	return b.fallbackGrace + time.Minute
+}
+
+// publishUndelivered republishes a direct message nobody received to
+// TopicDirectUndelivered, keeping its payload and metadata and recording
+// where it was headed.
+func (b *Bridge) publishUndelivered(ctx context.Context, msg pubsub.Message, recipientID string) {
+	if b.publisher == nil {
+		return
+	}
+	metadata := make(map[string]string, len(msg.Metadata)+3)
+	maps.Copy(metadata, msg.Metadata)
+	delete(metadata, MetaKeyUndeliveredFallback)
+	metadata["recipient_id"] = recipientID
+	metadata[MetaKeyOriginalTopic] = msg.Topic
+	metadata[MetaKeyEndpoint] = b.endpoint
+
+	err := b.publisher.Publish(ctx, pubsub.Message{
+		Topic:    TopicDirectUndelivered.Name(),
+		UserID:   msg.UserID,
+		Payload:  msg.Payload,
+		Metadata: metadata,
+	})
+	if err != nil {
+		slog.Error("Failed to publish undelivered direct message",
+			logging.UserID(recipientID),
+			logging.Topic(msg.Topic),
+			"endpoint", b.endpoint,
+			"error", err)
+		return
+	}
+	slog.Debug("Published undelivered direct message for fallback delivery",
+		logging.UserID(recipientID),
+		logging.Topic(msg.Topic),
+		"endpoint", b.endpoint)
+}
@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nfrund/goby/internal/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []pubsub.Message
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, msg pubsub.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recordingPublisher) HasSubscribers(topic string) bool { return false }
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestBridge_UndeliveredDirectMessageFallback(t *testing.T) {
+	pub := &recordingPublisher{}
+	b := NewBridge("html", BridgeDependencies{Publisher: pub})
+
+	ephemeral := pubsub.Message{
+		Topic:    TopicHTMLDirect.Name(),
+		Payload:  []byte("typing..."),
+		Metadata: map[string]string{"recipient_id": "bob"},
+	}
+	require.NoError(t, b.handleDirectMessage(context.Background(), ephemeral))
+	assert.Empty(t, pub.messages, "messages without the opt-in are dropped")
+
+	important := WithUndeliveredFallback(pubsub.Message{
+		Topic:    TopicHTMLDirect.Name(),
+		UserID:   "alice",
+		Payload:  []byte("<div>Invoice ready</div>"),
+		Metadata: map[string]string{"recipient_id": "bob", "kind": "invoice"},
+	})
+	require.NoError(t, b.handleDirectMessage(context.Background(), important))
+
+	require.Len(t, pub.messages, 1)
+	got := pub.messages[0]
+	assert.Equal(t, TopicDirectUndelivered.Name(), got.Topic)
+	assert.Equal(t, "alice", got.UserID)
+	assert.Equal(t, important.Payload, got.Payload)
+	assert.Equal(t, map[string]string{
+		"recipient_id":       "bob",
+		"kind":               "invoice",
+		MetaKeyOriginalTopic: TopicHTMLDirect.Name(),
+		MetaKeyEndpoint:      "html",
+	}, got.Metadata)
+}
+
+func TestBridge_DeliveredDirectMessageSkipsFallback(t *testing.T) {
+	pub := &recordingPublisher{}
+	b := NewBridge("html", BridgeDependencies{Publisher: pub})
+	client := &Client{ID: "c1", UserID: "bob", Endpoint: "html", Send: make(chan []byte, 1)}
+	b.clients.Add(client)
+
+	msg := WithUndeliveredFallback(pubsub.Message{
+		Topic:    TopicHTMLDirect.Name(),
+		Payload:  []byte("hello"),
+		Metadata: map[string]string{"recipient_id": "bob"},
+	})
+	require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+	assert.Equal(t, []byte("hello"), <-client.Send)
+	assert.Empty(t, pub.messages)
+}
+
+func TestBridge_UndeliveredFallbackAcrossInstances(t *testing.T) {
+	// Three bridges sharing a delivery store stand in for three instances,
+	// each receiving every direct message.
+	store := pubsub.NewMemoryDedupStore()
+	pub := &recordingPublisher{}
+	var bridges []*Bridge
+	for range 3 {
+		bridges = append(bridges, NewBridge("html", BridgeDependencies{
+			Publisher:        pub,
+			DeliveryStore:    store,
+			UndeliveredGrace: 20 * time.Millisecond,
+		}))
+	}
+	deliver := func(id string) {
+		msg := WithUndeliveredFallback(pubsub.Message{
+			Topic:    TopicHTMLDirect.Name(),
+			Payload:  []byte("hello"),
+			Metadata: map[string]string{"recipient_id": "bob", pubsub.MetaKeyMessageID: id},
+		})
+		for _, b := range bridges {
+			require.NoError(t, b.handleDirectMessage(context.Background(), msg))
+		}
+		for _, b := range bridges {
+			b.wg.Wait()
+		}
+	}
+
+	deliver("m-1")
+	require.Len(t, pub.messages, 1, "one instance publishes the fallback")
+	assert.Equal(t, TopicDirectUndelivered.Name(), pub.messages[0].Topic)
+
+	client := &Client{ID: "c1", UserID: "bob", Endpoint: "html", Send: make(chan []byte, 1)}
+	bridges[1].clients.Add(client)
+	deliver("m-2")
+	assert.Equal(t, []byte("hello"), <-client.Send)
+	assert.Len(t, pub.messages, 1, "no fallback when another instance delivered")
+}
@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nfrund/goby/internal/pubsub"
+)
+
+// UploadProgress is the payload schema for TopicUploadProgress events. It is
+// sent as JSON to the uploading user's HTML and data clients:
+//
+//	{"type":"ws.upload.progress","upload_id":"avatar-1","bytes_received":524288,"total_bytes":1048576,"percent":50}
+//
+// Type is always "ws.upload.progress". TotalBytes and Percent are zero when
+// the request did not declare its length. Done is set on the final event.
+type UploadProgress struct {
+	Type          string `json:"type"`
+	UploadID      string `json:"upload_id"`
+	BytesReceived int64  `json:"bytes_received"`
+	TotalBytes    int64  `json:"total_bytes,omitempty"`
+	Percent       int    `json:"percent"`
+	Done          bool   `json:"done,omitempty"`
+}
+
+// NewUploadProgressMessages builds the TopicHTMLDirect and TopicDataDirect
+// messages that deliver progress to all of userID's clients.
+func NewUploadProgressMessages(userID string, progress UploadProgress) ([]pubsub.Message, error) {
+	if userID == "" {
+		return nil, errors.New("upload progress recipient cannot be empty")
+	}
+	if progress.UploadID == "" {
+		return nil, errors.New("upload progress needs an upload ID")
+	}
+	progress.Type = TopicUploadProgress.Name()
+
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal upload progress: %w", err)
+	}
+
+	msgs := make([]pubsub.Message, 0, 2)
+	for _, topic := range []string{TopicHTMLDirect.Name(), TopicDataDirect.Name()} {
+		msgs = append(msgs, pubsub.Message{
+			Topic:    topic,
+			UserID:   userID,
+			Payload:  payload,
+			Metadata: map[string]string{"recipient_id": userID},
+		})
+	}
+	return msgs, nil
+}
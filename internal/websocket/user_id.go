@@ -0,0 +1,24 @@
+package websocket
+
+import "github.com/nfrund/goby/internal/domain"
+
+// UserIDFunc returns the ID a logged-in user's connections are known by, or
+// "" if the user can't be identified. Presence, direct-message routing
+// (Metadata["recipient_id"]) and logs all use this ID, so services publishing
+// to a user must derive it the same way.
+type UserIDFunc func(*domain.User) string
+
+// UserIDByEmail identifies users by email address. It is the default.
+func UserIDByEmail(user *domain.User) string {
+	return user.Email
+}
+
+// UserIDByRecordID identifies users by their opaque record ID (e.g.
+// "user:abc123"). It keeps email addresses out of pub/sub metadata and logs,
+// and works for users who have none.
+func UserIDByRecordID(user *domain.User) string {
+	if user.ID == nil {
+		return ""
+	}
+	return user.ID.String()
+}
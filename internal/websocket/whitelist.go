@@ -3,10 +3,60 @@ package websocket
 import (
 	"errors"
 	"log/slog"
+	"math"
 	"slices"
 	"sync"
 )
 
+// ActionInfo describes a whitelisted client action. Entries seeded as
+// defaults, by DefaultClientWhitelist or BridgeDependencies.DefaultActions,
+// have Default set; actions added by modules do not.
+type ActionInfo struct {
+	Action      string `json:"action"`
+	Description string `json:"description,omitempty"`
+	// RateLimit is the sustained number of messages per second each client
+	// may send with this action, with bursts up to RateBurst. It applies on
+	// top of the connection's own limit; zero leaves only that limit.
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty"`
+	// RequiredSubscription is a topic the client must be subscribed to
+	// before it may send this action.
+	RequiredSubscription string `json:"required_subscription,omitempty"`
+	Default              bool   `json:"default"`
+}
+
+// ActionOption sets optional metadata on a whitelisted action.
+type ActionOption func(*ActionInfo)
+
+// WithActionDescription documents what the action does, e.g. for generated
+// client protocol docs.
+func WithActionDescription(description string) ActionOption {
+	return func(info *ActionInfo) {
+		info.Description = description
+	}
+}
+
+// WithActionRateLimit limits each client to perSecond messages with the
+// action, with bursts up to burst. A burst below one uses perSecond,
+// rounded up.
+func WithActionRateLimit(perSecond float64, burst int) ActionOption {
+	return func(info *ActionInfo) {
+		info.RateLimit = perSecond
+		info.RateBurst = burst
+		if burst < 1 && perSecond > 0 {
+			info.RateBurst = int(math.Ceil(perSecond))
+		}
+	}
+}
+
+// WithRequiredSubscription only accepts the action from clients subscribed
+// to topic.
+func WithRequiredSubscription(topic string) ActionOption {
+	return func(info *ActionInfo) {
+		info.RequiredSubscription = topic
+	}
+}
+
 var (
 	// ErrActionAlreadyExists is returned when trying to add a duplicate action
 	ErrActionAlreadyExists = errors.New("action already exists in whitelist")
@@ -18,20 +68,24 @@ var (
 type clientWhitelist struct {
 	mu             sync.RWMutex
 	allowedActions []string
+	info           map[string]ActionInfo
 }
 
 // NewClientWhitelist creates a new whitelist with the given allowed actions
 func NewClientWhitelist(allowedActions ...string) *clientWhitelist {
 	// Filter out any empty actions
 	validActions := make([]string, 0, len(allowedActions))
+	info := make(map[string]ActionInfo, len(allowedActions))
 	for _, action := range allowedActions {
-		if action != "" {
+		if _, seen := info[action]; action != "" && !seen {
 			validActions = append(validActions, action)
+			info[action] = ActionInfo{Action: action, Default: true}
 		}
 	}
 
 	return &clientWhitelist{
 		allowedActions: validActions,
+		info:           info,
 	}
 }
 
@@ -47,9 +101,31 @@ func (w *clientWhitelist) IsAllowed(action string) bool {
 	return slices.Contains(w.allowedActions, action)
 }
 
+// Info returns the metadata of an allowed action.
+func (w *clientWhitelist) Info(action string) (ActionInfo, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	info, ok := w.info[action]
+	return info, ok
+}
+
+// List returns the metadata of every allowed action, in the order they were
+// added.
+func (w *clientWhitelist) List() []ActionInfo {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	list := make([]ActionInfo, 0, len(w.allowedActions))
+	for _, action := range w.allowedActions {
+		list = append(list, w.info[action])
+	}
+	return list
+}
+
 // AddAction adds an action to the whitelist in a thread-safe manner
-// Returns an error if the action is empty or already exists
-func (w *clientWhitelist) AddAction(action string) error {
+// Returns an error if the action is empty or already exists. The options
+// given for an action that already exists are applied on top of its current
+// ones, so re-registering it with a new limit takes effect.
+func (w *clientWhitelist) AddAction(action string, opts ...ActionOption) error {
 	if action == "" {
 		slog.Warn("attempted to add empty action to whitelist")
 		return ErrInvalidAction
@@ -59,10 +135,27 @@ func (w *clientWhitelist) AddAction(action string) error {
 	defer w.mu.Unlock()
 
 	if slices.Contains(w.allowedActions, action) {
-		slog.Debug("action already in whitelist", "action", action)
+		if len(opts) > 0 {
+			info := w.info[action]
+			for _, opt := range opts {
+				opt(&info)
+			}
+			w.info[action] = info
+			slog.Info("updated options of whitelisted action", "action", action)
+		} else {
+			slog.Debug("action already in whitelist", "action", action)
+		}
 		return ErrActionAlreadyExists
 	}
 
+	info := ActionInfo{Action: action}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	if w.info == nil {
+		w.info = make(map[string]ActionInfo)
+	}
+	w.info[action] = info
 	w.allowedActions = append(w.allowedActions, action)
 	slog.Info("added action to whitelist", "action", action)
 	return nil
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClientWhitelist_IsAllowed(t *testing.T) {
@@ -137,3 +138,29 @@ func TestClientWhitelist_ConcurrentAccess(t *testing.T) {
 	assert.True(t, wl.IsAllowed(action), "action %s should be in whitelist", action)
 	}
 }
+
+func TestBridge_WhitelistInfo(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{DefaultActions: []string{"ping"}})
+	require.NoError(t, b.AllowAction("game.move",
+		WithActionDescription("Moves a unit"),
+		WithActionRateLimit(2.5, 0),
+		WithRequiredSubscription("game.lobby")))
+
+	assert.Equal(t, []ActionInfo{
+		{Action: "ping", Default: true},
+		{Action: "game.move", Description: "Moves a unit", RateLimit: 2.5, RateBurst: 3, RequiredSubscription: "game.lobby"},
+	}, b.WhitelistInfo())
+}
+
+func TestBridge_AllowActionAgainUpdatesOptions(t *testing.T) {
+	b := NewBridge("data", BridgeDependencies{DefaultActions: []string{"ping"}})
+	require.NoError(t, b.AllowAction("game.move", WithActionDescription("Moves a unit"), WithActionRateLimit(1, 1)))
+	require.NoError(t, b.AllowAction("game.move", WithActionRateLimit(10, 20)))
+	require.NoError(t, b.AllowAction("game.move"), "allowing an action again without options keeps them")
+	require.NoError(t, b.AllowAction("ping", WithActionRateLimit(5, 5)))
+
+	assert.Equal(t, []ActionInfo{
+		{Action: "ping", RateLimit: 5, RateBurst: 5, Default: true},
+		{Action: "game.move", Description: "Moves a unit", RateLimit: 10, RateBurst: 20},
+	}, b.WhitelistInfo())
+}
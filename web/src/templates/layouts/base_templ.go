@@ -8,11 +8,14 @@ package layouts
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/nfrund/goby/web/src/templates/partials"
+import (
+	"github.com/nfrund/goby/internal/view"
+	"github.com/nfrund/goby/web/src/templates/partials"
+)
 
 // Base defines the main document structure.
 // It accepts a 'title' string, the 'flashes' data, and a 'children' component (which is the page content).
-func Base(title string, flashes partials.FlashData, children templ.Component) templ.Component {
+func Base(title string, flashes view.FlashMessages, children templ.Component) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -40,13 +43,13 @@ func Base(title string, flashes partials.FlashData, children templ.Component) te
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(CalculateTitle(title))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/src/templates/layouts/base.templ`, Line: 14, Col: 33}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/src/templates/layouts/base.templ`, Line: 17, Col: 33}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><!-- Use SVG for modern browsers, with an ICO fallback --><link rel=\"icon\" type=\"image/svg+xml\" href=\"/static/img/logo.svg\"><link rel=\"alternate icon\" href=\"/static/img/favicon.ico\"><link rel=\"stylesheet\" href=\"/static/css/style.css\"><script src=\"/static/js/htmx.min.js\"></script><script src=\"/static/js/ws.js\"></script><script defer src=\"/static/js/alpine.min.js\"></script><script src=\"/static/js/heartbeat.js\"></script></head><body>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><!-- Use SVG for modern browsers, with an ICO fallback --><link rel=\"icon\" type=\"image/svg+xml\" href=\"/static/img/logo.svg\"><link rel=\"alternate icon\" href=\"/static/img/favicon.ico\"><link rel=\"stylesheet\" href=\"/static/css/style.css\"><script src=\"/static/js/htmx.min.js\"></script><script src=\"/static/js/ws.js\"></script><script defer src=\"/static/js/alpine.min.js\"></script><script src=\"/static/js/heartbeat.js\"></script><script src=\"/static/js/toast.js\"></script><script src=\"/static/js/upload_progress.js\"></script></head><body>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
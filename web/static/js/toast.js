@@ -0,0 +1,53 @@
+// Toast notifications pushed over the HTML WebSocket.
+//
+// The server sends toasts to a user's HTML clients as JSON on ws.html.direct:
+//   {"type":"ws.toast","level":"success","text":"Export finished","duration_ms":5000}
+// They are rendered into the #flash-messages container alongside the
+// regular flash messages and are not passed on to htmx for swapping.
+document.addEventListener("htmx:wsBeforeMessage", function (event) {
+  const raw = event.detail && event.detail.message;
+  if (typeof raw !== "string" || raw.charAt(0) !== "{") {
+    return;
+  }
+
+  let toast;
+  try {
+    toast = JSON.parse(raw);
+  } catch (e) {
+    return;
+  }
+  if (!toast || toast.type !== "ws.toast") {
+    return;
+  }
+
+  // Stop htmx from treating the JSON as an HTML fragment.
+  event.preventDefault();
+
+  const levels = {
+    info: "alert-info",
+    success: "alert-success",
+    warning: "alert-warning",
+    error: "alert-error",
+  };
+
+  let container = document.getElementById("flash-messages");
+  if (!container) {
+    container = document.createElement("div");
+    container.id = "flash-messages";
+    container.className = "fixed top-5 right-5 z-50 flex flex-col items-end space-y-2";
+    document.body.appendChild(container);
+  }
+
+  const el = document.createElement("div");
+  el.className = "alert " + (levels[toast.level] || levels.info) + " shadow-lg w-auto max-w-md";
+  el.setAttribute("role", "alert");
+  const text = document.createElement("span");
+  // Toast text is plain text, never HTML.
+  text.textContent = toast.text || "";
+  el.appendChild(text);
+  container.appendChild(el);
+
+  setTimeout(function () {
+    el.remove();
+  }, toast.duration_ms > 0 ? toast.duration_ms : 5000);
+});
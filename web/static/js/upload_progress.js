@@ -0,0 +1,44 @@
+// File upload progress pushed over the HTML WebSocket.
+//
+// Uploads posted with ?upload_id=<id> report progress to the uploading user's
+// HTML clients as JSON on ws.html.direct, at most every 250ms:
+//   {"type":"ws.upload.progress","upload_id":"avatar-1","bytes_received":524288,"total_bytes":1048576,"percent":50}
+// Any <progress data-upload-id="<id>"> element is updated, and a
+// "goby:upload-progress" event carrying the payload is dispatched on document
+// for custom rendering. The JSON is not passed on to htmx for swapping.
+document.addEventListener("htmx:wsBeforeMessage", function (event) {
+  const raw = event.detail && event.detail.message;
+  if (typeof raw !== "string" || raw.charAt(0) !== "{") {
+    return;
+  }
+
+  let progress;
+  try {
+    progress = JSON.parse(raw);
+  } catch (e) {
+    return;
+  }
+  if (!progress || progress.type !== "ws.upload.progress") {
+    return;
+  }
+
+  // Stop htmx from treating the JSON as an HTML fragment.
+  event.preventDefault();
+
+  document.querySelectorAll("progress[data-upload-id]").forEach(function (el) {
+    if (el.dataset.uploadId !== progress.upload_id) {
+      return;
+    }
+    el.max = 100;
+    if (progress.total_bytes > 0) {
+      el.value = progress.percent;
+    } else if (progress.done) {
+      el.value = 100;
+    } else {
+      // Unknown length: show an indeterminate bar.
+      el.removeAttribute("value");
+    }
+  });
+
+  document.dispatchEvent(new CustomEvent("goby:upload-progress", { detail: progress }));
+});